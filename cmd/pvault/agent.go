@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/config"
+	"github.com/lovincyrus/personal-vault/internal/proc"
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// agentDialTimeout bounds connecting to the session agent and sending it a
+// request. Waiting for the answer is unbounded: it may be a prompt.
+const agentDialTimeout = 2 * time.Second
+
+// maxAgentRequest bounds one request line.
+const maxAgentRequest = 4 << 10
+
+// errNoSession is returned when no agent holds a session token: the vault
+// isn't unlocked, or was locked since.
+var errNoSession = errors.New("vault is not unlocked (no session agent)")
+
+// agentRequest is the one JSON line a client writes to the session agent.
+type agentRequest struct {
+	Op      string `json:"op"`                // get, set, or clear
+	Command string `json:"command,omitempty"` // the CLI command asking, for get
+	Token   string `json:"token,omitempty"`   // for set
+}
+
+// agentResponse is the one JSON line the agent writes back.
+type agentResponse struct {
+	Token string `json:"token,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// sessionAgent keeps the CLI's session token in memory, instead of in a
+// file anyone who can read it can copy, and hands it to each CLI command
+// that asks over a Unix socket only its owner can reach, as ssh-agent does
+// keys. With session.confirm on, each handout is first put to the authorizer.
+type sessionAgent struct {
+	mu    sync.Mutex
+	token string
+
+	valid      func(token string) bool // nil accepts any token
+	audit      func(store.AuditEntry)  // nil when there is no vault to log to
+	confirm    bool
+	authorizer vault.Authorizer
+	timeout    time.Duration
+	onClear    func() // called after each clear is answered, if set
+}
+
+// newSessionAgent returns an agent holding token (which may be empty until
+// 'pvault unlock' sets one) that, with session.confirm on, asks a, waiting
+// up to timeout, before each handout of the token.
+func newSessionAgent(cfg *config.Config, token string, a vault.Authorizer, timeout time.Duration) *sessionAgent {
+	agent := &sessionAgent{token: token, authorizer: a, timeout: timeout}
+	switch cfg.Value("session.confirm") {
+	case "true":
+		agent.confirm = true
+	case "false", "":
+	default:
+		fatal("session.confirm must be true or false")
+	}
+	if agent.confirm && a == nil {
+		fatal("session.confirm needs an authorizer to ask: serve --approve, authorize.url, or authorize.cmd")
+	}
+	return agent
+}
+
+// listen serves the agent on a Unix socket at path, private to its owner;
+// a stale socket is replaced.
+func (a *sessionAgent) listen(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go a.handle(c)
+		}
+	}()
+	return ln, nil
+}
+
+func (a *sessionAgent) handle(c net.Conn) {
+	defer c.Close()
+	// The socket's mode keeps other users out; check the kernel's word
+	// for it where it gives one.
+	if uid, err := proc.PeerUID(c); err == nil && uid != os.Getuid() {
+		return
+	} else if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return
+	}
+	c.SetReadDeadline(time.Now().Add(agentDialTimeout))
+	var req agentRequest
+	if err := json.NewDecoder(io.LimitReader(c, maxAgentRequest)).Decode(&req); err != nil {
+		return
+	}
+	c.SetReadDeadline(time.Time{})
+	json.NewEncoder(c).Encode(a.answer(req))
+	if req.Op == "clear" && a.onClear != nil {
+		a.onClear()
+	}
+}
+
+func (a *sessionAgent) answer(req agentRequest) agentResponse {
+	switch req.Op {
+	case "get":
+		a.mu.Lock()
+		token := a.token
+		a.mu.Unlock()
+		if token == "" {
+			return agentResponse{Error: errNoSession.Error()}
+		}
+		// Command is the client's own word for what it is, so it only
+		// informs the question; it can't decide whether one is asked.
+		if a.confirm {
+			if err := a.ask(req.Command); err != nil {
+				return agentResponse{Error: err.Error()}
+			}
+		}
+		return agentResponse{Token: token}
+	case "set":
+		if req.Token == "" || a.valid != nil && !a.valid(req.Token) {
+			return agentResponse{Error: "not a session of this vault"}
+		}
+		a.mu.Lock()
+		a.token = req.Token
+		a.mu.Unlock()
+	case "clear":
+		a.mu.Lock()
+		a.token = ""
+		a.mu.Unlock()
+	default:
+		return agentResponse{Error: fmt.Sprintf("unknown op %q", req.Op)}
+	}
+	return agentResponse{}
+}
+
+// ask puts a handout of the session token to the authorizer, naming the
+// command the client says it is. The session token reaches every field, so
+// it is asked about as a critical read of all of them.
+func (a *sessionAgent) ask(command string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	decision, err := a.authorizer.Authorize(ctx, vault.AuthorizationRequest{
+		Consumer:    "cli",
+		Action:      "session",
+		Fields:      []string{"*"},
+		Sensitivity: "critical",
+		Purpose:     "pvault " + command,
+		Time:        time.Now().UTC(),
+	})
+	if err == nil && decision == vault.DecisionAllow {
+		return nil
+	}
+	if a.audit != nil {
+		a.audit(store.AuditEntry{Consumer: "cli", Scope: "*", Action: "denied", Purpose: "session_confirm: " + command})
+	}
+	return fmt.Errorf("'pvault %s' was not approved", command)
+}
+
+// agentPath is the session agent's socket.
+func agentPath() string {
+	return filepath.Join(vaultDir(), "agent.sock")
+}
+
+// agentCall sends the session agent one request and returns its answer.
+func agentCall(req agentRequest) (agentResponse, error) {
+	var resp agentResponse
+	c, err := net.DialTimeout("unix", agentPath(), agentDialTimeout)
+	if err != nil {
+		return resp, err
+	}
+	defer c.Close()
+	c.SetWriteDeadline(time.Now().Add(agentDialTimeout))
+	if err := json.NewEncoder(c).Encode(req); err != nil {
+		return resp, err
+	}
+	if err := json.NewDecoder(bufio.NewReader(c)).Decode(&resp); err != nil {
+		return resp, fmt.Errorf("session agent: %w", err)
+	}
+	return resp, nil
+}
+
+// sessionToken is the agent's answer for this command, asked for once so
+// that a confirmation prompt comes up at most once.
+var sessionToken struct {
+	asked bool
+	token string
+	err   error
+}
+
+// readSessionToken asks the session agent for the session token on behalf
+// of the running command.
+func readSessionToken() (string, error) {
+	if sessionToken.asked {
+		return sessionToken.token, sessionToken.err
+	}
+	sessionToken.asked = true
+	resp, err := agentCall(agentRequest{Op: "get", Command: os.Args[1]})
+	switch {
+	case err != nil, resp.Error == errNoSession.Error():
+		sessionToken.err = errNoSession
+	case resp.Error != "":
+		sessionToken.err = errors.New(resp.Error)
+	default:
+		sessionToken.token = resp.Token
+	}
+	return sessionToken.token, sessionToken.err
+}
+
+// writeSessionToken hands a new session token to the agent, starting one
+// if none is running, as when the server is on another machine.
+func writeSessionToken(token string) error {
+	sessionToken.asked, sessionToken.token, sessionToken.err = true, token, nil
+	resp, err := agentCall(agentRequest{Op: "set", Token: token})
+	if err != nil {
+		return startAgent(token)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// clearSessionToken has the agent forget the session token.
+func clearSessionToken() {
+	sessionToken.asked, sessionToken.token, sessionToken.err = true, "", errNoSession
+	agentCall(agentRequest{Op: "clear"})
+}
+
+// startAgent runs 'pvault agent' in the background holding token, and waits
+// for its socket to answer.
+func startAgent(token string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, "agent")
+	cmd.Env = append(os.Environ(), "VAULT_DIR="+vaultDir())
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	proc.Detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdin, token)
+	stdin.Close()
+	cmd.Process.Release()
+
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if c, err := net.Dial("unix", agentPath()); err == nil {
+			c.Close()
+			return nil
+		}
+	}
+	return errors.New("session agent did not start")
+}
+
+// cmdAgent holds a session token read from stdin for a server this machine
+// isn't running, such as one on another host, until 'pvault lock' clears
+// it. 'pvault unlock' starts it; a local server is its own agent.
+func cmdAgent() {
+	token, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	token = strings.TrimSpace(token)
+	if err != nil && token == "" {
+		fatal("agent: reading the session token from stdin: %v", err)
+	}
+	cfg := cliConfig()
+	a, timeout := authorizerFromConfig(cfg)
+	agent := newSessionAgent(cfg, token, a, timeout)
+	done := make(chan struct{})
+	var once sync.Once
+	agent.onClear = func() { once.Do(func() { close(done) }) }
+	ln, err := agent.listen(agentPath())
+	if err != nil {
+		fatal("agent: %v", err)
+	}
+	<-done
+	ln.Close()
+	os.Remove(agentPath())
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// approveFieldsShown is how many field IDs a prompt lists before summing up
+// the rest.
+const approveFieldsShown = 5
+
+// terminalAuthorizer asks the owner at the terminal running 'pvault serve
+// --approve', one request at a time, and reads the answer back from stdin;
+// anything but yes denies.
+type terminalAuthorizer struct {
+	mu    sync.Mutex // one prompt at a time
+	out   io.Writer
+	lines chan string // closed when stdin is
+}
+
+func newTerminalAuthorizer(in io.Reader, out io.Writer) *terminalAuthorizer {
+	a := &terminalAuthorizer{out: out, lines: make(chan string)}
+	go func() {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			a.lines <- scanner.Text()
+		}
+		close(a.lines)
+	}()
+	return a
+}
+
+func (a *terminalAuthorizer) Authorize(ctx context.Context, r vault.AuthorizationRequest) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// The read may have run out of time waiting for an earlier prompt.
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	a.drain()
+
+	fmt.Fprintln(a.out)
+	if r.NewConsumer {
+		fmt.Fprintln(a.out, msg("approve.new_consumer", r.Consumer))
+	}
+	fmt.Fprintln(a.out, msg("approve.request", r.Consumer, r.Action, approveFields(r.Fields), r.Sensitivity))
+	if r.Purpose != "" {
+		fmt.Fprintln(a.out, msg("approve.purpose", r.Purpose))
+	}
+	fmt.Fprintf(a.out, "%s ", msg("approve.prompt"))
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(a.out)
+		fmt.Fprintln(a.out, msg("approve.timed_out"))
+		return "", ctx.Err()
+	case line, ok := <-a.lines:
+		if !ok {
+			return "", errors.New("stdin closed")
+		}
+		if affirmative(line) {
+			fmt.Fprintln(a.out, msg("approve.allowed"))
+			return vault.DecisionAllow, nil
+		}
+		fmt.Fprintln(a.out, msg("approve.denied"))
+		return vault.DecisionDeny, nil
+	}
+}
+
+// drain discards lines typed while no prompt was showing, so they don't
+// answer the next one.
+func (a *terminalAuthorizer) drain() {
+	for {
+		select {
+		case _, ok := <-a.lines:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// approveFields lists the first few field IDs and counts the rest.
+func approveFields(ids []string) string {
+	if len(ids) <= approveFieldsShown {
+		return strings.Join(ids, ", ")
+	}
+	return strings.Join(ids[:approveFieldsShown], ", ") + " " + msg("approve.more", len(ids)-approveFieldsShown)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const aclUsage = "usage: pvault acl [list | add <id> --allow|--deny <consumer> | remove <id> <consumer>]"
+
+func cmdACL() {
+	args := os.Args[2:]
+	switch {
+	case len(args) == 0 || (len(args) == 1 && args[0] == "list"):
+		listACLs()
+	case len(args) == 4 && args[0] == "add" && (args[2] == "--allow" || args[2] == "--deny"):
+		effect := args[2][2:]
+		resp, err := apiRequest("PUT", "/vault/acls/"+args[1], map[string]string{"consumer": args[3], "effect": effect})
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("acl."+effect, args[3], args[1]))
+	case len(args) == 3 && args[0] == "remove":
+		resp, err := apiRequest("DELETE", "/vault/acls/"+args[1]+"?"+url.Values{"consumer": {args[2]}}.Encode(), nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("acl.removed", args[2], args[1]))
+	default:
+		fatal(aclUsage)
+	}
+}
+
+func listACLs() {
+	resp, err := apiRequest("GET", "/vault/acls", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		ACLs []vault.FieldACL `json:"acls"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	if len(result.ACLs) == 0 {
+		fmt.Println(msg("acl.empty"))
+		return
+	}
+	for _, a := range result.ACLs {
+		var lists []string
+		if len(a.Allow) > 0 {
+			lists = append(lists, msg("acl.allow_list", strings.Join(a.Allow, ", ")))
+		}
+		if len(a.Deny) > 0 {
+			lists = append(lists, msg("acl.deny_list", strings.Join(a.Deny, ", ")))
+		}
+		fmt.Printf("%-32s %s\n", a.ID, strings.Join(lists, "; "))
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+	"golang.org/x/term"
+)
+
+// cmdAdd walks through the recommended fields of a category that aren't
+// stored yet, prompting for each. Critical fields are read without echo.
+func cmdAdd() {
+	if len(os.Args) < 3 {
+		fatal("usage: pvault add <category>\n  example: pvault add identity")
+	}
+	name := os.Args[2]
+	schema := vault.LocalizedSchema(cliLang())
+	var cat *vault.SchemaCategory
+	var names []string
+	for i := range schema.Categories {
+		if c := &schema.Categories[i]; len(c.Fields) > 0 {
+			names = append(names, c.Name)
+			if c.Name == name {
+				cat = c
+			}
+		}
+	}
+	if cat == nil {
+		fatal("%s", msg("add.unknown", name, strings.Join(names, ", ")))
+	}
+
+	resp, err := apiRequest("GET", "/vault/fields", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var stored []vault.FieldInfo
+	if err := apiResult(resp, &stored); err != nil {
+		fatal("%v", err)
+	}
+	filled := make(map[string]bool, len(stored))
+	for _, f := range stored {
+		filled[f.ID] = true
+	}
+	var todo []vault.SchemaField
+	for _, f := range cat.Fields {
+		if !filled[f.ID] {
+			todo = append(todo, f)
+		}
+	}
+	if len(todo) == 0 {
+		fmt.Println(msg("add.complete", cat.Name))
+		return
+	}
+
+	fmt.Printf("%s — %s\n", cat.Name, cat.Description)
+	fmt.Println(msg("add.intro", len(todo), len(cat.Fields)-len(todo)))
+	reader := bufio.NewReader(os.Stdin)
+	saved := 0
+	for _, f := range todo {
+		label := fmt.Sprintf("  %s (%s, %s): ", f.Description, f.ID, f.Sensitivity)
+		var value string
+		if f.Sensitivity == "critical" && term.IsTerminal(int(os.Stdin.Fd())) {
+			value, err = promptPassword(label)
+			if err != nil {
+				fatal("reading %s: %v", f.ID, err)
+			}
+		} else {
+			fmt.Print(label)
+			line, err := reader.ReadString('\n')
+			if err != nil && line == "" {
+				break // stdin closed
+			}
+			value = line
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		resp, err := apiRequest("PUT", "/vault/fields/"+f.ID, map[string]string{"value": value})
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var result struct {
+			Normalized string `json:"normalized,omitempty"`
+		}
+		if err := apiResult(resp, &result); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s\n", msg("add.failed", f.ID, err))
+			continue
+		}
+		if result.Normalized != "" {
+			fmt.Println("  " + msg("field.normalized", result.Normalized))
+		}
+		saved++
+	}
+	fmt.Println(msg("add.done", saved))
+}
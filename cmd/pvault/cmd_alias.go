@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+func cmdAlias() {
+	args := os.Args[2:]
+	switch {
+	case len(args) == 0:
+		listAliases()
+	case len(args) == 2 && args[0] == "--delete":
+		resp, err := apiRequest("DELETE", "/vault/aliases/"+args[1], nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var result map[string]string
+		if err := apiResult(resp, &result); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("alias.deleted", args[1]))
+	case len(args) == 2:
+		resp, err := apiRequest("PUT", "/vault/aliases/"+args[0], map[string]string{"target": args[1]})
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var result map[string]string
+		if err := apiResult(resp, &result); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("alias.set", args[0], args[1]))
+	default:
+		fatal("usage: pvault alias [<alias> <target> | --delete <alias>]")
+	}
+}
+
+func listAliases() {
+	resp, err := apiRequest("GET", "/vault/aliases", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		Aliases []vault.FieldAlias `json:"aliases"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	if len(result.Aliases) == 0 {
+		fmt.Println(msg("alias.empty"))
+		return
+	}
+	for _, a := range result.Aliases {
+		fmt.Printf("%-32s → %s\n", a.Alias, a.Target)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func cmdAppendOnly() {
+	args := os.Args[2:]
+	switch {
+	case len(args) == 0:
+		listAppendOnly()
+	case len(args) == 2 && args[0] == "--remove":
+		resp, err := apiRequest("DELETE", "/vault/append-only/"+args[1], nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("appendonly.removed", args[1]))
+	case len(args) == 1:
+		resp, err := apiRequest("PUT", "/vault/append-only/"+args[0], nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("appendonly.set", args[0]))
+	default:
+		fatal("usage: pvault append-only [<category> | --remove <category>]")
+	}
+}
+
+func listAppendOnly() {
+	resp, err := apiRequest("GET", "/vault/append-only", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var categories []string
+	if err := apiResult(resp, &categories); err != nil {
+		fatal("%v", err)
+	}
+	if len(categories) == 0 {
+		fmt.Println(msg("appendonly.empty"))
+		return
+	}
+	for _, c := range categories {
+		fmt.Println(c)
+	}
+}
@@ -2,11 +2,24 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/lovincyrus/personal-vault/internal/store"
 )
 
+const auditUsage = "usage: pvault audit [--fields]"
+
+// cmdAudit prints recent audit entries. Reads show how many fields and bytes
+// they sent; --fields lists the field IDs under each.
 func cmdAudit() {
+	showFields := false
+	for _, arg := range os.Args[2:] {
+		if arg != "--fields" {
+			fatal(auditUsage)
+		}
+		showFields = true
+	}
+
 	resp, err := apiRequest("GET", "/vault/audit?limit=20", nil)
 	if err != nil {
 		fatal("request failed: %v", err)
@@ -27,8 +40,21 @@ func cmdAudit() {
 		if e.Purpose != "" {
 			purpose = fmt.Sprintf(" (%s)", e.Purpose)
 		}
-		fmt.Printf("%-20s %-10s %-8s %s%s\n",
+		reqID := ""
+		if e.RequestID != "" {
+			reqID = " [req " + e.RequestID + "]"
+		}
+		sent := ""
+		if len(e.Fields) > 0 || e.Bytes > 0 {
+			sent = fmt.Sprintf(" → %d fields, %d bytes", len(e.Fields), e.Bytes)
+		}
+		fmt.Printf("%-20s %-10s %-8s %s%s%s%s\n",
 			e.CreatedAt.Format("2006-01-02 15:04:05"),
-			e.Consumer, e.Action, e.Scope, purpose)
+			e.Consumer, e.Action, e.Scope, purpose, reqID, sent)
+		if showFields {
+			for _, id := range e.Fields {
+				fmt.Printf("%-20s   %s\n", "", id)
+			}
+		}
 	}
 }
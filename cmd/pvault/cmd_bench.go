@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/bench"
+)
+
+const benchUsage = "usage: pvault bench [--run <name>]\n  example: pvault bench --run GetContext"
+
+// cmdBench measures unlock, reads, writes, and token checks on this machine,
+// each against a throwaway vault, and exits non-zero if any is over budget.
+func cmdBench() {
+	run := ""
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--run" && i+1 < len(args):
+			run = args[i+1]
+			i++
+		default:
+			fatal(benchUsage)
+		}
+	}
+
+	var suite []bench.Benchmark
+	for _, bm := range bench.Suite() {
+		if strings.HasPrefix(bm.Name, run) {
+			suite = append(suite, bm)
+		}
+	}
+	if len(suite) == 0 {
+		fatal(msg("bench.none", run))
+	}
+
+	fmt.Println(msg("bench.start", runtime.GOOS, runtime.GOARCH, runtime.NumCPU()))
+	over := 0
+	for _, bm := range suite {
+		r, err := bm.Measure()
+		if err != nil {
+			fatal("%v", err)
+		}
+		mark := "✓"
+		if r.OverBudget() {
+			mark = "✗"
+			over++
+		}
+		fmt.Println(msg("bench.row", mark, r.Name, roundDuration(r.PerOp), r.Budget))
+	}
+	if over > 0 {
+		fmt.Println(msg("bench.summary", over))
+		os.Exit(1)
+	}
+	fmt.Println(msg("bench.summary_ok"))
+}
+
+// roundDuration keeps about three significant digits of d.
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	case d >= time.Microsecond:
+		return d.Round(10 * time.Nanosecond)
+	}
+	return d
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"golang.org/x/term"
+)
+
+const bootstrapUsage = "usage: pvault bootstrap --format terraform|ansible [--fields <scope>] [-o <file>]"
+
+// cmdBootstrap writes vault fields for infrastructure-as-code on this
+// machine: the JSON object Terraform's external data source reads, or an
+// Ansible vars file. Terraform passes its query on stdin, and a "fields"
+// key there overrides --fields.
+func cmdBootstrap() {
+	var format, fields, out string
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		if i+1 >= len(args) {
+			fatal(bootstrapUsage)
+		}
+		switch args[i] {
+		case "--format":
+			format = args[i+1]
+		case "--fields":
+			fields = args[i+1]
+		case "-o", "--output":
+			out = args[i+1]
+		default:
+			fatal(bootstrapUsage)
+		}
+		i++
+	}
+	if format == "" {
+		fatal(bootstrapUsage)
+	}
+	if format == "terraform" && !term.IsTerminal(int(os.Stdin.Fd())) {
+		var query struct {
+			Fields string `json:"fields"`
+		}
+		if data, _ := io.ReadAll(os.Stdin); len(bytes.TrimSpace(data)) > 0 {
+			if err := json.Unmarshal(data, &query); err != nil {
+				fatal("reading Terraform query: %v", err)
+			}
+		}
+		if query.Fields != "" {
+			fields = query.Fields
+		}
+	}
+
+	path := "/vault/bootstrap/" + url.PathEscape(format)
+	if fields != "" {
+		path += "?fields=" + url.QueryEscape(fields)
+	}
+	resp, err := apiRequest("GET", path, nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	if out == "" {
+		os.Stdout.Write(body)
+		return
+	}
+	if err := os.WriteFile(out, body, 0600); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Fprintln(os.Stderr, msg("bootstrap.written", out))
+}
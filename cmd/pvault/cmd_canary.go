@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const canaryUsage = "usage: pvault canary [list | create <id> [--value <v>] [--sensitivity <tier>] [--revoke] | delete <id>]"
+
+func cmdCanary() {
+	args := os.Args[2:]
+	if len(args) == 0 || args[0] == "list" {
+		listCanaries()
+		return
+	}
+	switch {
+	case args[0] == "create" && len(args) >= 2:
+		createCanary(args[1], args[2:])
+	case args[0] == "delete" && len(args) == 2:
+		resp, err := apiRequest("DELETE", "/vault/canaries/"+args[1], nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var result map[string]string
+		if err := apiResult(resp, &result); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("canary.deleted", args[1]))
+	default:
+		fatal(canaryUsage)
+	}
+}
+
+func createCanary(id string, args []string) {
+	req := map[string]any{}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--value", "--sensitivity":
+			if i+1 >= len(args) {
+				fatal(canaryUsage)
+			}
+			req[args[i][2:]] = args[i+1]
+			i++
+		case "--revoke":
+			req["revoke"] = true
+		default:
+			fatal(canaryUsage)
+		}
+	}
+	resp, err := apiRequest("PUT", "/vault/canaries/"+id, req)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		Value  string `json:"value"`
+		Revoke bool   `json:"revoke"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("canary.created", id, result.Value))
+	if result.Revoke {
+		fmt.Println(msg("canary.revoke"))
+	}
+	fmt.Println(msg("canary.hint"))
+}
+
+func listCanaries() {
+	resp, err := apiRequest("GET", "/vault/canaries", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		Canaries []vault.Canary `json:"canaries"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	if len(result.Canaries) == 0 {
+		fmt.Println(msg("canary.empty"))
+		return
+	}
+	for _, c := range result.Canaries {
+		action := msg("canary.alert_only")
+		if c.Revoke {
+			action = msg("canary.alert_revoke")
+		}
+		fmt.Printf("%-32s %s  %s\n", c.ID, c.CreatedAt.Local().Format("2006-01-02"), action)
+	}
+}
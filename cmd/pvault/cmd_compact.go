@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// cmdCompact rewrites the vault database without the free space deleted
+// fields, history, and audit entries leave behind. A running server does it
+// for its session; otherwise the database is opened here, which needs no
+// password.
+func cmdCompact() {
+	var res vault.CompactResult
+	if portHasVault() {
+		resp, err := apiRequest("POST", "/vault/compact", nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, &res); err != nil {
+			fatal("%v", err)
+		}
+	} else {
+		dir := vaultDir()
+		if !fileExists(filepath.Join(dir, "vault.db")) && !fileExists(filepath.Join(dir, "vault.db.enc")) {
+			fatal("%s", msg("offline.no_vault", dir))
+		}
+		v, err := vault.Open(dir)
+		if err != nil {
+			fatal("open vault: %v", err)
+		}
+		defer v.Close()
+		if res, err = v.Compact(0); err != nil {
+			fatal("%v", err)
+		}
+	}
+
+	if !res.Compacted {
+		fmt.Println(msg("compact.nothing"))
+		return
+	}
+	fmt.Println(msg("compact.done", formatSize(res.Before), formatSize(res.After), formatSize(max(res.Before-res.After, 0))))
+}
+
+// formatSize renders a byte count in the largest unit that keeps it at 1 or
+// more, e.g. 3.2 MB.
+func formatSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGT"[exp])
+}
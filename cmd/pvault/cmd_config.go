@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/config"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const configUsage = "usage: pvault config [list | get <key> | set <key> <value> | unset <key>]\n" +
+	"       pvault config export [<file>]\n" +
+	"       pvault config import [--reissue-tokens] <file|->"
+
+func cmdConfig() {
+	args := os.Args[2:]
+	cfg := cliConfig()
+	switch {
+	case len(args) == 0 || (len(args) == 1 && args[0] == "list"):
+		for _, k := range config.Keys {
+			value, source := cfg.Resolve(k.Name, "")
+			if value == "" {
+				value = "-"
+			}
+			fmt.Printf("%-22s %-32s %-8s %s\n", k.Name, value, source, k.Doc)
+		}
+	case len(args) == 2 && args[0] == "get":
+		if _, ok := config.Lookup(args[1]); !ok {
+			fatal("%s", msg("config.unknown", args[1]))
+		}
+		fmt.Println(cfg.Value(args[1]))
+	case len(args) == 3 && args[0] == "set":
+		if err := cfg.Set(args[1], args[2]); err != nil {
+			fatal("%v", err)
+		}
+		saveConfig(cfg, args[1])
+	case len(args) == 2 && args[0] == "unset":
+		if err := cfg.Unset(args[1]); err != nil {
+			fatal("%v", err)
+		}
+		saveConfig(cfg, args[1])
+	case len(args) <= 2 && args[0] == "export":
+		path := ""
+		if len(args) == 2 {
+			path = args[1]
+		}
+		exportConfig(cfg, path)
+	case args[0] == "import":
+		reissue, path := false, ""
+		for _, arg := range args[1:] {
+			switch {
+			case arg == "--reissue-tokens":
+				reissue = true
+			case path == "":
+				path = arg
+			default:
+				fatal(configUsage)
+			}
+		}
+		if path == "" {
+			fatal(configUsage)
+		}
+		importConfig(cfg, path, reissue)
+	default:
+		fatal(configUsage)
+	}
+}
+
+// configBundle is what 'pvault config export' writes: the settings in the
+// config file and, when the server is running, the vault's wiring.
+type configBundle struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Settings   map[string]string `json:"settings,omitempty"`
+	Vault      *vault.Wiring     `json:"vault,omitempty"`
+}
+
+// exportConfig writes the bundle to path (mode 0600), or to stdout.
+func exportConfig(cfg *config.Config, path string) {
+	b := configBundle{Version: 1, ExportedAt: time.Now().UTC(), Settings: make(map[string]string)}
+	for _, k := range config.Keys {
+		if v, ok := cfg.Get(k.Name); ok {
+			b.Settings[k.Name] = v
+		}
+	}
+	if portHasVault() {
+		resp, err := apiRequest("GET", "/vault/wiring", nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		b.Vault = &vault.Wiring{}
+		if err := apiResult(resp, b.Vault); err != nil {
+			fatal("%v", err)
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, msg("config.export_settings_only"))
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		fatal("%v", err)
+	}
+	data = append(data, '\n')
+	if path == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		fatal("%v", err)
+	}
+	consumers, tokens := 0, 0
+	if b.Vault != nil {
+		consumers, tokens = len(b.Vault.Consumers), len(b.Vault.Tokens)
+	}
+	fmt.Println(msg("config.exported", len(b.Settings), consumers, tokens, path))
+}
+
+// importConfig applies a bundle from 'pvault config export': its wiring to
+// the running vault, then its settings to the config file. The settings
+// are checked first but set last, so the import talks to the server the
+// current settings point at.
+func importConfig(cfg *config.Config, path string, reissue bool) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fatal("%v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	var b configBundle
+	if err := json.NewDecoder(in).Decode(&b); err != nil {
+		fatal("%s: %v", path, err)
+	}
+	if b.Version != 1 {
+		fatal("%s: unsupported version %d", path, b.Version)
+	}
+	for name, value := range b.Settings {
+		k, ok := config.Lookup(name)
+		if !ok {
+			fatal("%s: %s", path, msg("config.unknown", name))
+		}
+		if err := k.Validate(value); err != nil {
+			fatal("%s: %v", path, err)
+		}
+	}
+
+	if b.Vault != nil {
+		if !portHasVault() {
+			fatal("%s", msg("config.import_needs_server"))
+		}
+		endpoint := "/vault/wiring"
+		if reissue {
+			endpoint += "?reissue_tokens=true"
+		}
+		resp, err := apiRequest("POST", endpoint, b.Vault)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var res vault.WiringResult
+		if err := apiResult(resp, &res); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("config.imported_wiring", res.Consumers, res.ACLs, res.Templates, res.Aliases, res.AppendOnly))
+		for _, t := range res.Reissued {
+			fmt.Println(msg("config.reissued", t.Consumer, t.OldPrefix, t.Token))
+		}
+		if res.SkippedTokens > 0 {
+			fmt.Println(msg("config.tokens_skipped", res.SkippedTokens))
+		}
+	}
+
+	if len(b.Settings) > 0 {
+		for name, value := range b.Settings {
+			cfg.Set(name, value)
+		}
+		if err := cfg.Save(); err != nil {
+			fatal("write config: %v", err)
+		}
+		fmt.Println(msg("config.imported_settings", len(b.Settings), config.Path(vaultDir())))
+	}
+}
+
+// saveConfig writes the file and warns when the environment will override
+// the setting just changed.
+func saveConfig(cfg *config.Config, name string) {
+	if err := cfg.Save(); err != nil {
+		fatal("write config: %v", err)
+	}
+	fmt.Println(msg("config.saved", name, config.Path(vaultDir())))
+	if _, source := cfg.Resolve(name, ""); source == config.SourceEnv {
+		k, _ := config.Lookup(name)
+		fmt.Fprintln(os.Stderr, msg("config.env_override", k.Env))
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const consumerUsage = "usage: pvault consumer [list | trust <name> untrusted|standard|trusted | reset <name> | signing-key <name>]"
+
+func cmdConsumer() {
+	args := os.Args[2:]
+	switch {
+	case len(args) == 0 || (len(args) == 1 && args[0] == "list"):
+		listConsumers()
+	case len(args) == 3 && args[0] == "trust":
+		resp, err := apiRequest("PUT", "/vault/consumers/"+args[1], map[string]string{"trust": args[2]})
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("consumer.trust", args[1], args[2], vault.TrustMaxSensitivity(args[2])))
+	case len(args) == 2 && args[0] == "reset":
+		resp, err := apiRequest("DELETE", "/vault/consumers/"+args[1], nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("consumer.reset", args[1]))
+	case len(args) == 2 && args[0] == "signing-key":
+		resp, err := apiRequest("GET", "/vault/consumers/"+args[1]+"/signing-key", nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var result struct {
+			Key string `json:"key"`
+		}
+		if err := apiResult(resp, &result); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(result.Key)
+	default:
+		fatal(consumerUsage)
+	}
+}
+
+func listConsumers() {
+	resp, err := apiRequest("GET", "/vault/consumers", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		Consumers []vault.Consumer `json:"consumers"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	if len(result.Consumers) == 0 {
+		fmt.Println(msg("consumer.empty"))
+		return
+	}
+	for _, c := range result.Consumers {
+		line := fmt.Sprintf("%-24s %-10s %s", c.Name, c.Trust, msg("consumer.max", c.MaxSensitivity))
+		if !c.Registered {
+			line += "  " + msg("consumer.default")
+		}
+		fmt.Println(line)
+	}
+}
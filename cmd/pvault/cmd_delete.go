@@ -20,5 +20,5 @@ func cmdDelete() {
 	if err := apiResult(resp, &result); err != nil {
 		fatal("%v", err)
 	}
-	fmt.Printf("Deleted %s\n", id)
+	fmt.Println(msg("field.deleted", id))
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const (
+	demoUsage = "usage: pvault demo [--seed <n>] [--dir <path>]\n  example: pvault demo --seed 42"
+
+	// demoPassword unlocks every demo vault; it guards made-up data only.
+	demoPassword = "pvault-demo"
+
+	// demoPort keeps a demo server off the port the real vault uses.
+	demoPort = "7299"
+)
+
+// cmdDemo creates a vault of made-up data in a new directory, never the
+// user's own, for trying pvault or an agent against realistic fields.
+func cmdDemo() {
+	seed := rand.Int64N(1000000)
+	dir := ""
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--seed" && i+1 < len(args):
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				fatal(demoUsage)
+			}
+			seed = n
+			i++
+		case args[i] == "--dir" && i+1 < len(args):
+			dir = args[i+1]
+			i++
+		default:
+			fatal(demoUsage)
+		}
+	}
+	if dir == "" {
+		d, err := os.MkdirTemp("", "pvault-demo-")
+		if err != nil {
+			fatal("%v", err)
+		}
+		dir = d
+	}
+
+	sk, err := vault.Init(dir, demoPassword)
+	if err != nil {
+		fatal("%v", err)
+	}
+	v, err := vault.Open(dir)
+	if err != nil {
+		fatal("%v", err)
+	}
+	defer v.Close()
+	if _, err := v.Unlock(demoPassword, sk); err != nil {
+		fatal("%v", err)
+	}
+	n, err := v.LoadDemo(seed)
+	if err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("demo.done", n, seed, dir))
+	fmt.Println(msg("demo.next", dir, demoPort, demoPassword))
+}
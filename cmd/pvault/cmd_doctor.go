@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/keystore"
+	"github.com/lovincyrus/personal-vault/internal/proc"
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// maxClockSkew is how far the server clock may drift from this machine's
+// before token times stop meaning what they say.
+const maxClockSkew = 2 * time.Minute
+
+// doctor prints check results and counts problems.
+type doctor struct {
+	problems int
+}
+
+func (d *doctor) ok(text string) {
+	fmt.Println("✓ " + text)
+}
+
+func (d *doctor) warn(text, fix string) {
+	fmt.Println("! " + text)
+	if fix != "" {
+		fmt.Println("  → " + fix)
+	}
+}
+
+func (d *doctor) fail(text, fix string) {
+	d.problems++
+	fmt.Println("✗ " + text)
+	if fix != "" {
+		fmt.Println("  → " + fix)
+	}
+}
+
+func cmdDoctor() {
+	d := &doctor{}
+	if d.checkFiles() {
+		d.checkDatabase()
+		d.checkServer()
+	}
+
+	if d.problems > 0 {
+		fmt.Println(msg("doctor.summary", d.problems))
+		os.Exit(1)
+	}
+	fmt.Println(msg("doctor.summary_ok"))
+}
+
+// checkFiles checks that the vault directory and secret key are private,
+// and that no old session file leaves a token in plain text. It returns
+// false if there is no vault directory to check.
+func (d *doctor) checkFiles() bool {
+	dir := vaultDir()
+	info, err := os.Stat(dir)
+	if err != nil {
+		d.fail(msg("doctor.no_dir", dir), msg("doctor.fix.init"))
+		return false
+	}
+	d.checkMode(dir, info, "700")
+
+	d.checkSecretKey(dir)
+	if fileExists(sessionPath()) {
+		d.fail(msg("doctor.session_file"), msg("doctor.fix.rm", sessionPath()))
+	}
+	return true
+}
+
+// checkSecretKey reports how the secret key is stored, flags it if it is
+// unencrypted, and checks that a keychain entry can still be read. A key
+// file whose device is unplugged is only a warning: that is how it is meant
+// to be kept.
+func (d *doctor) checkSecretKey(dir string) {
+	mode := keystore.Detect(dir)
+	path := keystore.Path(dir, mode)
+	switch mode {
+	case keystore.File:
+		if info, err := os.Stat(path); err == nil {
+			d.checkMode(path, info, "600")
+		}
+		d.warn(msg("doctor.secret_plaintext", path), msg("doctor.fix.migrate_secret"))
+		return
+	case keystore.Keychain:
+		if _, err := keystore.Load(dir, nil); err != nil {
+			d.fail(msg("doctor.secret_unreadable", keyModeName(mode), err), msg("doctor.fix.secret_lost", path))
+			return
+		}
+	case keystore.PIN:
+		if info, err := os.Stat(path); err == nil {
+			d.checkMode(path, info, "600")
+		}
+	case keystore.Keyfile:
+		keyfile, _ := keystore.KeyfilePath(dir)
+		info, err := os.Stat(keyfile)
+		if err != nil {
+			d.warn(msg("doctor.keyfile_missing", keyfile), msg("doctor.fix.keyfile_mount"))
+			return
+		}
+		d.checkMode(keyfile, info, "600")
+	}
+	d.ok(msg("doctor.secret_stored", keyModeName(mode)))
+}
+
+func (d *doctor) checkMode(path string, info os.FileInfo, want string) {
+	if runtime.GOOS == "windows" {
+		// Access is governed by ACLs, which the mode bits don't reflect.
+		return
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		d.fail(msg("doctor.mode", path, uint32(perm)), msg("doctor.fix.chmod", want, path))
+		return
+	}
+	d.ok(msg("doctor.private", path))
+}
+
+// checkDatabase runs SQLite's integrity check, or for an encrypted database
+// confirms the file parses; its contents need the key ('pvault verify').
+func (d *doctor) checkDatabase() {
+	dir := vaultDir()
+	if path := filepath.Join(dir, "vault.db.enc"); fileExists(path) {
+		if _, err := store.OpenEncrypted(path); err != nil {
+			d.fail(msg("doctor.db_unreadable", err), msg("doctor.fix.restore"))
+			return
+		}
+		d.ok(msg("doctor.enc_ok"))
+		return
+	}
+	path := filepath.Join(dir, "vault.db")
+	if !fileExists(path) {
+		d.fail(msg("doctor.no_db", dir), msg("doctor.fix.init"))
+		return
+	}
+	db, err := store.Open(path)
+	if err != nil {
+		d.fail(msg("doctor.db_unreadable", err), msg("doctor.fix.restore"))
+		return
+	}
+	defer db.Close()
+	problems, err := db.IntegrityCheck()
+	switch {
+	case err != nil:
+		d.fail(msg("doctor.db_unreadable", err), msg("doctor.fix.restore"))
+	case len(problems) > 0:
+		d.fail(msg("doctor.db_corrupt", problems[0]), msg("doctor.fix.restore"))
+	default:
+		d.ok(msg("doctor.db_ok"))
+	}
+}
+
+// checkServer looks for a stale PID file or session, something other than
+// the vault on its port, and clock skew against the server and its tokens.
+func (d *doctor) checkServer() {
+	addr := serverAddr()
+	running := portHasVault()
+
+	if pid, err := readPID(); err == nil {
+		switch {
+		case !proc.Alive(pid):
+			d.fail(msg("doctor.stale_pid", pid), msg("doctor.fix.rm", pidPath()))
+		case !running:
+			d.fail(msg("doctor.pid_not_serving", pid), msg("doctor.fix.lock"))
+		}
+	}
+
+	if !running {
+		if u, err := url.Parse(addr); err == nil {
+			if conn, err := net.DialTimeout("tcp", u.Host, time.Second); err == nil {
+				conn.Close()
+				d.fail(msg("doctor.port_conflict", addr), msg("doctor.fix.port"))
+				return
+			}
+		}
+		d.warn(msg("doctor.server_down", addr), "")
+		return
+	}
+	d.ok(msg("doctor.server_ok", addr))
+
+	resp, err := apiRequest("GET", "/vault/tokens/service", nil)
+	if err != nil {
+		return
+	}
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		if skew := time.Since(date); skew > maxClockSkew || skew < -maxClockSkew {
+			d.fail(msg("doctor.clock_skew", skew.Round(time.Second)), msg("doctor.fix.clock"))
+		}
+	}
+
+	if _, err := readSessionToken(); err != nil {
+		resp.Body.Close()
+		d.warn(msg("doctor.no_session"), msg("doctor.fix.unlock"))
+		return
+	}
+
+	var tokens []struct {
+		Consumer  string `json:"consumer"`
+		ExpiresAt string `json:"expires_at"`
+		CreatedAt string `json:"created_at"`
+	}
+	switch err := apiResult(resp, &tokens); {
+	case resp.StatusCode == http.StatusUnauthorized:
+		d.fail(msg("doctor.stale_session"), msg("doctor.fix.unlock"))
+		return
+	case resp.StatusCode == http.StatusForbidden:
+		d.warn(msg("doctor.locked"), msg("doctor.fix.unlock"))
+		return
+	case err != nil:
+		d.fail(err.Error(), "")
+		return
+	}
+
+	now := time.Now()
+	consistent := true
+	for _, t := range tokens {
+		created, err1 := time.Parse(time.RFC3339, t.CreatedAt)
+		expires, err2 := time.Parse(time.RFC3339, t.ExpiresAt)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		switch {
+		case created.After(now.Add(maxClockSkew)):
+			consistent = false
+			d.fail(msg("doctor.token_future", t.Consumer, created.Local().Format("2006-01-02 15:04")), msg("doctor.fix.clock"))
+		case expires.Before(now):
+			// The server still accepts a token this machine considers expired.
+			consistent = false
+			d.fail(msg("doctor.token_expired", t.Consumer, expires.Local().Format("2006-01-02 15:04")), msg("doctor.fix.clock"))
+		case expires.Before(now.Add(24 * time.Hour)):
+			d.warn(msg("doctor.token_expiring", t.Consumer, expires.Local().Format("2006-01-02 15:04")), msg("doctor.fix.token"))
+		}
+	}
+	if consistent {
+		d.ok(msg("doctor.clock_ok"))
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const emergencyUsage = `usage: pvault emergency setup <contact> --recipient <age1…|ssh-…> [--fields scope] [--wait 72h]
+       pvault emergency list
+       pvault emergency deny <contact>
+       pvault emergency remove <contact>
+       pvault emergency request <contact> --code <code>
+       pvault emergency release <contact> --code <code> [-o file]`
+
+func cmdEmergency() {
+	if len(os.Args) < 3 {
+		fatal(emergencyUsage)
+	}
+	switch os.Args[2] {
+	case "list":
+		listEmergencyContacts()
+		return
+	case "setup", "deny", "remove", "request", "release":
+	default:
+		fatal(emergencyUsage)
+	}
+	if len(os.Args) < 4 {
+		fatal(emergencyUsage)
+	}
+	contact := os.Args[3]
+
+	opts := map[string]string{}
+	for i := 4; i < len(os.Args); i++ {
+		if i+1 >= len(os.Args) {
+			fatal(emergencyUsage)
+		}
+		switch os.Args[i] {
+		case "--recipient", "--fields", "--wait", "--code":
+			opts[os.Args[i]] = os.Args[i+1]
+		case "-o", "--output":
+			opts["-o"] = os.Args[i+1]
+		default:
+			fatal(emergencyUsage)
+		}
+		i++
+	}
+
+	switch os.Args[2] {
+	case "setup":
+		setupEmergencyContact(contact, opts["--recipient"], opts["--fields"], opts["--wait"])
+	case "deny":
+		emergencyAction("POST", "/vault/emergency/"+contact+"/deny", nil)
+		fmt.Println(msg("emergency.denied", contact))
+	case "remove":
+		emergencyAction("DELETE", "/vault/emergency/"+contact, nil)
+		fmt.Println(msg("emergency.removed", contact))
+	case "request":
+		result := emergencyAction("POST", "/vault/emergency/request", emergencyCode(contact, opts["--code"]))
+		fmt.Println(msg("emergency.requested", result["release_at"]))
+	case "release":
+		result := emergencyAction("POST", "/vault/emergency/release", emergencyCode(contact, opts["--code"]))
+		if out := opts["-o"]; out == "" {
+			fmt.Print(result["bundle"])
+		} else if err := os.WriteFile(out, []byte(result["bundle"]), 0600); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Fprintln(os.Stderr, msg("emergency.released"))
+	}
+}
+
+func emergencyCode(contact, code string) map[string]string {
+	if code == "" {
+		fatal(emergencyUsage)
+	}
+	return map[string]string{"contact": contact, "code": code}
+}
+
+func emergencyAction(method, path string, body any) map[string]string {
+	resp, err := apiRequest(method, path, body)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result map[string]string
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	return result
+}
+
+func setupEmergencyContact(contact, recipient, scope, wait string) {
+	if recipient == "" {
+		fatal(emergencyUsage)
+	}
+	if wait != "" {
+		if _, err := time.ParseDuration(wait); err != nil {
+			fatal("invalid --wait: %v", err)
+		}
+	}
+	sealed, n := sealEscrow([]string{recipient}, scope)
+
+	result := emergencyAction("PUT", "/vault/emergency/"+contact, map[string]string{
+		"recipient": recipient,
+		"wait":      wait,
+		"bundle":    string(sealed),
+	})
+	fmt.Println(msg("emergency.setup", n, contact, result["wait"]))
+	fmt.Println()
+	fmt.Println("  " + result["code"])
+	fmt.Println()
+	fmt.Println(msg("emergency.code_hint"))
+}
+
+func listEmergencyContacts() {
+	resp, err := apiRequest("GET", "/vault/emergency", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var contacts []struct {
+		Contact   string `json:"contact"`
+		Recipient string `json:"recipient"`
+		Wait      string `json:"wait"`
+		Status    string `json:"status"`
+		ReleaseAt string `json:"release_at"`
+	}
+	if err := apiResult(resp, &contacts); err != nil {
+		fatal("%v", err)
+	}
+	if len(contacts) == 0 {
+		fmt.Println(msg("emergency.empty"))
+		return
+	}
+	for _, c := range contacts {
+		state := c.Status
+		switch {
+		case c.ReleaseAt != "":
+			state = msg("emergency.pending", c.ReleaseAt)
+		case state == "":
+			state = "-"
+		}
+		recipient := c.Recipient
+		if len(recipient) > 24 {
+			recipient = recipient[:24] + "…"
+		}
+		fmt.Printf("%-20s %-26s wait %-10s %s\n", c.Contact, recipient, c.Wait, state)
+	}
+}
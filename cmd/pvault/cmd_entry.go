@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const entryUsage = `usage: pvault entry list <id> [--reveal]
+       pvault entry add <id> <value> [--label <label>] [--primary] [--sensitivity <tier>]
+       pvault entry remove <id> <label | position>`
+
+func cmdEntry() {
+	if len(os.Args) < 4 {
+		fatal(entryUsage)
+	}
+	id := os.Args[3]
+	switch os.Args[2] {
+	case "list":
+		listEntries(id)
+	case "add":
+		addEntry(id, os.Args[4:])
+	case "remove":
+		if len(os.Args) != 5 {
+			fatal(entryUsage)
+		}
+		resp, err := apiRequest("DELETE", "/vault/entries/"+id+"?entry="+url.QueryEscape(os.Args[4]), nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("entry.removed", os.Args[4], id))
+	default:
+		fatal(entryUsage)
+	}
+}
+
+func addEntry(id string, args []string) {
+	var body struct {
+		vault.ValueEntry
+		Sensitivity string `json:"sensitivity,omitempty"`
+	}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--label", "--sensitivity":
+			if i+1 >= len(args) {
+				fatal("%s needs a value", arg)
+			}
+			if arg == "--label" {
+				body.Label = args[i+1]
+			} else {
+				body.Sensitivity = args[i+1]
+			}
+			i++
+		case "--primary":
+			body.Primary = true
+		default:
+			if body.Value != "" || strings.HasPrefix(arg, "--") {
+				fatal(entryUsage)
+			}
+			body.Value = arg
+		}
+	}
+	if body.Value == "" {
+		fatal(entryUsage)
+	}
+	resp, err := apiRequest("POST", "/vault/entries/"+id, body)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("entry.added", id))
+}
+
+// listEntries prints a field's entries, numbered as 'entry remove' takes
+// them and masked as 'get' masks them. A single value is listed as the one
+// entry.
+func listEntries(id string) {
+	resp, err := apiRequest("GET", revealPath("/vault/fields/"+id), nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var field vault.FieldInfo
+	if err := apiResult(resp, &field); err != nil {
+		fatal("%v", err)
+	}
+	shown, masked := shownField(&field)
+	if masked {
+		defer fmt.Fprintln(os.Stderr, msg("get.masked"))
+	}
+	entries := shown.Entries
+	if len(entries) == 0 {
+		entries = []vault.ValueEntry{{Value: shown.Value, Primary: true}}
+	}
+	for i, e := range entries {
+		var tags []string
+		if e.Label != "" {
+			tags = append(tags, e.Label)
+		}
+		if e.Primary {
+			tags = append(tags, "primary")
+		}
+		if len(tags) > 0 {
+			fmt.Printf("%d. %s [%s]\n", i+1, e.Value, strings.Join(tags, ", "))
+		} else {
+			fmt.Printf("%d. %s\n", i+1, e.Value)
+		}
+	}
+}
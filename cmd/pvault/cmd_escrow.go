@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const escrowUsage = "usage: pvault escrow export --recipient <age1…|ssh-…> [--recipient …] [--fields scope] [-o file]"
+
+func cmdEscrow() {
+	if len(os.Args) < 3 || os.Args[2] != "export" {
+		fatal(escrowUsage)
+	}
+
+	var recipients []string
+	var scope, out string
+	for i := 3; i < len(os.Args); i++ {
+		if i+1 >= len(os.Args) {
+			fatal(escrowUsage)
+		}
+		switch os.Args[i] {
+		case "--recipient":
+			recipients = append(recipients, os.Args[i+1])
+		case "--fields":
+			scope = os.Args[i+1]
+		case "-o", "--output":
+			out = os.Args[i+1]
+		default:
+			fatal(escrowUsage)
+		}
+		i++
+	}
+	if len(recipients) == 0 {
+		fatal(escrowUsage)
+	}
+	sealed, n := sealEscrow(recipients, scope)
+
+	if out == "" {
+		os.Stdout.Write(sealed)
+	} else if err := os.WriteFile(out, sealed, 0600); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Fprintln(os.Stderr, msg("escrow.done", n, len(recipients)))
+}
+
+// sealEscrow fetches the fields selected by scope (critical fields if empty)
+// and seals them to recipients. It also returns the number of fields sealed.
+func sealEscrow(recipients []string, scope string) ([]byte, int) {
+	for _, r := range recipients {
+		if _, err := crypto.ParseRecipient(r); err != nil {
+			fatal("%v", err)
+		}
+	}
+
+	resp, err := apiRequest("GET", "/vault/context", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var all vault.ContextBundle
+	if err := apiResult(resp, &all); err != nil {
+		fatal("%v", err)
+	}
+
+	selected := vault.SelectEscrow(&all, scope)
+	n := 0
+	for _, fields := range selected.Categories {
+		n += len(fields)
+	}
+	if n == 0 {
+		fatal("%s", msg("escrow.none"))
+	}
+
+	// The same format as 'pvault export', so the contact can decrypt it and
+	// 'pvault import' the result into a vault of their own.
+	plaintext, err := json.MarshalIndent(selected, "", "  ")
+	if err != nil {
+		fatal("%v", err)
+	}
+	sealed, err := crypto.SealToRecipients(plaintext, recipients)
+	if err != nil {
+		fatal("%v", err)
+	}
+	return sealed, n
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const expiringUsage = "usage: pvault expiring [--days <n>]"
+
+// cmdExpiring lists passports, visas, and other expiry fields coming up.
+func cmdExpiring() {
+	days := ""
+	switch args := os.Args[2:]; {
+	case len(args) == 0:
+	case len(args) == 2 && args[0] == "--days":
+		if _, err := strconv.Atoi(args[1]); err != nil {
+			fatal(expiringUsage)
+		}
+		days = args[1]
+	default:
+		fatal(expiringUsage)
+	}
+	path := "/vault/expiring"
+	if days != "" {
+		path += "?days=" + days
+	}
+	resp, err := apiRequest("GET", path, nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		Days   int                   `json:"days"`
+		Fields []vault.ExpiringField `json:"fields"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	if len(result.Fields) == 0 {
+		fmt.Println(msg("expiring.none", result.Days))
+		return
+	}
+	for _, f := range result.Fields {
+		var when string
+		switch {
+		case f.DaysLeft < 0:
+			when = msg("expiring.expired", -f.DaysLeft)
+		case f.DaysLeft == 0:
+			when = msg("expiring.today")
+		default:
+			when = msg("expiring.in", f.DaysLeft)
+		}
+		fmt.Printf("  %-35s %s  %s\n", f.ID, f.Expires, when)
+	}
+}
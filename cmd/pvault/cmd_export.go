@@ -3,25 +3,96 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
+const exportUsage = "usage: pvault export [--since <rfc3339>] | [--csv] [--scope <pattern>[,<pattern>...]]"
+
 func cmdExport() {
-	resp, err := apiRequest("GET", "/vault/context", nil)
-	if err != nil {
-		fatal("request failed: %v", err)
+	var since, scope string
+	asCSV := false
+	for i := 2; i < len(os.Args); i++ {
+		switch arg := os.Args[i]; arg {
+		case "--since":
+			if i+1 >= len(os.Args) {
+				fatal("--since needs a value")
+			}
+			since = os.Args[i+1]
+			i++
+		case "--scope":
+			if i+1 >= len(os.Args) {
+				fatal("--scope needs a value")
+			}
+			scope = os.Args[i+1]
+			i++
+		case "--csv":
+			asCSV = true
+		default:
+			fatal(exportUsage)
+		}
+	}
+	if since != "" && (asCSV || scope != "") {
+		fatal(exportUsage)
+	}
+	if scope != "" {
+		for _, p := range strings.Split(scope, ",") {
+			if err := vault.ValidateScopePattern(strings.TrimSpace(p)); err != nil {
+				fatal("--scope: %v", err)
+			}
+		}
 	}
 
-	var ctx vault.ContextBundle
-	if err := apiResult(resp, &ctx); err != nil {
-		fatal("%v", err)
+	var out any
+	if since != "" {
+		if _, err := time.Parse(time.RFC3339, since); err != nil {
+			fatal("--since must be an RFC 3339 time, like 2026-01-02T15:04:05Z")
+		}
+		resp, err := apiRequest("GET", "/vault/export?since="+url.QueryEscape(since), nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var changes vault.Changes
+		if err := apiResult(resp, &changes); err != nil {
+			fatal("%v", err)
+		}
+		out = changes
+	} else {
+		resp, err := apiRequest("GET", "/vault/context", nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var ctx vault.ContextBundle
+		if err := apiResult(resp, &ctx); err != nil {
+			fatal("%v", err)
+		}
+		if scope != "" {
+			for cat, fields := range ctx.Categories {
+				ctx.Categories[cat] = slices.DeleteFunc(fields, func(f vault.FieldInfo) bool {
+					return !vault.ScopeAllows(scope, f.ID)
+				})
+				if len(ctx.Categories[cat]) == 0 {
+					delete(ctx.Categories, cat)
+				}
+			}
+		}
+		if asCSV {
+			if err := vault.WriteCSV(os.Stdout, &ctx); err != nil {
+				fatal("%v", err)
+			}
+			return
+		}
+		out = ctx
 	}
 
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
-	if err := enc.Encode(ctx); err != nil {
+	if err := enc.Encode(out); err != nil {
 		fmt.Fprintf(os.Stderr, "Error encoding: %v\n", err)
 	}
 }
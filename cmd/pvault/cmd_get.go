@@ -1,19 +1,130 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/lovincyrus/personal-vault/internal/vault"
+	"golang.org/x/term"
 )
 
+const getUsage = "usage: pvault get [--signed] [--reveal] [--template <text> | --field value|sensitivity|updated_at] <id>\n  example: pvault get identity.full_name"
+
+// getSelectors are what --field can print, each on one line but the value,
+// which is printed as stored once printField has masked it.
+var getSelectors = map[string]func(*vault.FieldInfo) string{
+	"value":       func(f *vault.FieldInfo) string { return f.Value },
+	"sensitivity": func(f *vault.FieldInfo) string { return f.Sensitivity },
+	"updated_at":  func(f *vault.FieldInfo) string { return f.UpdatedAt.UTC().Format(time.RFC3339) },
+}
+
+// getTemplateFuncs quote a value for where it's going, so one with a
+// newline or a quote in it stays one token.
+var getTemplateFuncs = template.FuncMap{
+	"quote":      strconv.Quote,
+	"shellquote": shellQuote,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
 func cmdGet() {
-	if len(os.Args) < 3 {
-		fatal("usage: pvault get <id>\n  example: pvault get identity.full_name")
+	signed := false
+	var tmplText, selector string
+	var args []string
+	rest := os.Args[2:]
+	for i := 0; i < len(rest); i++ {
+		switch arg := rest[i]; arg {
+		case "--signed":
+			signed = true
+		case "--template", "--field":
+			if i+1 >= len(rest) {
+				fatal("%s", getUsage)
+			}
+			i++
+			if arg == "--template" {
+				tmplText = rest[i]
+			} else {
+				selector = rest[i]
+			}
+		default:
+			args = append(args, arg)
+		}
+	}
+	if len(args) < 1 {
+		fatal("%s", getUsage)
+	}
+	id := args[0]
+	if signed && offline {
+		fatal("--signed needs a server: signatures are made per consumer")
+	}
+	if tmplText != "" && selector != "" {
+		fatal("use --template or --field, not both")
+	}
+	if selector != "" && getSelectors[selector] == nil {
+		fatal("--field must be value, sensitivity, or updated_at")
+	}
+	if selector != "" && signed {
+		fatal("--field prints one attribute; use --template to include the signature")
+	}
+	// Parse before reading, so a typo doesn't cost a read or an approval.
+	var tmpl *template.Template
+	if tmplText != "" {
+		var err error
+		if tmpl, err = template.New("get").Funcs(getTemplateFuncs).Parse(tmplText); err != nil {
+			fatal("--template: %v", err)
+		}
+	}
+
+	if offline {
+		v := openOffline(false)
+		if !strings.Contains(id, ".") {
+			fields, err := v.List()
+			if err != nil {
+				closeOffline(v)
+				fatal("%v", err)
+			}
+			id = resolveFieldID(id, fields)
+		}
+		field, err := v.Get(id)
+		if field != nil {
+			logOfflineReveal(v, id, []vault.FieldInfo{*field})
+		}
+		closeOffline(v)
+		if err != nil {
+			fatal("%v", err)
+		}
+		if field == nil {
+			fatal("field not found: %s", id)
+		}
+		printField(field, tmpl, selector)
+		return
+	}
+
+	if !strings.Contains(id, ".") {
+		resp, err := apiRequest("GET", "/vault/fields", nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var fields []vault.FieldInfo
+		if err := apiResult(resp, &fields); err != nil {
+			fatal("%v", err)
+		}
+		id = resolveFieldID(id, fields)
 	}
-	id := os.Args[2]
 
-	resp, err := apiRequest("GET", "/vault/fields/"+id, nil)
+	path := "/vault/fields/" + id
+	if signed {
+		path += "?signed=true"
+	}
+	resp, err := apiRequest("GET", revealPath(path), nil)
 	if err != nil {
 		fatal("request failed: %v", err)
 	}
@@ -22,5 +133,83 @@ func cmdGet() {
 	if err := apiResult(resp, &field); err != nil {
 		fatal("%v", err)
 	}
-	fmt.Println(field.Value)
+	if signed && tmpl == nil {
+		// The value and its signature, for passing on to a system that
+		// checks them.
+		shown, masked := shownField(&field)
+		out, _ := json.MarshalIndent(map[string]any{"id": field.ID, "value": shown.Value, "signature": field.Signature}, "", "  ")
+		fmt.Println(string(out))
+		if masked {
+			fmt.Fprintln(os.Stderr, msg("get.masked"))
+		}
+		return
+	}
+	printField(&field, tmpl, selector)
+}
+
+// printField prints what --template or --field asked for, or else the
+// value. A template's output gets a final newline if it lacks one. Each
+// sees the value masked as its sensitivity and type call for (see
+// maskedValue); at a terminal, the value alone is also indented.
+func printField(field *vault.FieldInfo, tmpl *template.Template, selector string) {
+	shown, masked := shownField(field)
+	if masked {
+		defer fmt.Fprintln(os.Stderr, msg("get.masked"))
+	}
+	switch {
+	case tmpl != nil:
+		var b strings.Builder
+		if err := tmpl.Execute(&b, &shown); err != nil {
+			fatal("--template: %v", err)
+		}
+		out := b.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		fmt.Print(out)
+	case selector != "":
+		fmt.Println(getSelectors[selector](&shown))
+	case stdoutIsTerminal():
+		fmt.Println(prettyValue(&shown, shown.Value))
+	default:
+		fmt.Println(shown.Value)
+	}
+}
+
+// shellQuote quotes s for a POSIX shell: single quotes keep everything,
+// newlines included, except single quotes themselves.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// resolveFieldID finds the stored field a bare name like "email" means,
+// asking which one when several match and stdin is a terminal. The resolved
+// ID goes to stderr, so stdout stays just the value.
+func resolveFieldID(query string, fields []vault.FieldInfo) string {
+	ids := make([]string, len(fields))
+	for i, f := range fields {
+		ids[i] = f.ID
+	}
+	matches := vault.MatchFieldIDs(query, ids)
+	switch {
+	case len(matches) == 0:
+		fatal("%s", msg("get.no_match", query))
+	case len(matches) == 1:
+		fmt.Fprintln(os.Stderr, msg("get.resolved", matches[0]))
+		return matches[0]
+	case !term.IsTerminal(int(os.Stdin.Fd())):
+		fatal("%s", msg("get.ambiguous", query, strings.Join(matches, ", ")))
+	}
+
+	fmt.Fprintln(os.Stderr, msg("get.choose", query))
+	for i, id := range matches {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, id)
+	}
+	fmt.Fprintf(os.Stderr, "%s ", msg("get.choice", len(matches)))
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(matches) {
+		fatal("%s", msg("get.no_choice"))
+	}
+	return matches[n-1]
 }
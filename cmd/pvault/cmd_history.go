@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+func cmdHistory() {
+	if len(os.Args) < 3 {
+		fatal("usage: pvault history <id>")
+	}
+	id := os.Args[2]
+
+	resp, err := apiRequest("GET", "/vault/history/"+id, nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+
+	var result struct {
+		History []vault.FieldRevision `json:"history"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+
+	if len(result.History) == 0 {
+		fmt.Println(msg("history.empty"))
+		return
+	}
+	for _, h := range result.History {
+		fmt.Printf("v%-4d %-12s %s  %s\n", h.Version, h.Reason, h.CreatedAt.Local().Format("2006-01-02 15:04"), h.Value)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+func cmdImport() {
+	strategy := vault.MergeKeepExisting
+	dryRun, asCSV, yes := false, false, false
+	var path string
+	for i := 2; i < len(os.Args); i++ {
+		switch arg := os.Args[i]; arg {
+		case "--csv":
+			asCSV = true
+		case "--yes":
+			yes = true
+		case "--merge-strategy":
+			if i+1 >= len(os.Args) {
+				fatal("--merge-strategy needs a value")
+			}
+			strategy = os.Args[i+1]
+			i++
+		case "--dry-run":
+			dryRun = true
+		default:
+			path = arg
+		}
+	}
+	if path == "" {
+		fatal("usage: pvault import [--merge-strategy keep-newest|keep-existing|interactive] [--dry-run] <file|->\n" +
+			"       pvault import --csv [--dry-run] [--yes] <file|->")
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fatal("%v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	if asCSV {
+		importCSV(in, dryRun, yes || path == "-")
+		return
+	}
+
+	// A plain export is a ContextBundle; an incremental one ('export
+	// --since') also lists deletions.
+	var incoming struct {
+		vault.ContextBundle
+		Deleted []vault.DeletedField `json:"deleted"`
+	}
+	if err := json.NewDecoder(in).Decode(&incoming); err != nil {
+		fatal("read import file: %v", err)
+	}
+
+	resp, err := apiRequest("GET", revealPath("/vault/context"), nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var existing vault.ContextBundle
+	if err := apiResult(resp, &existing); err != nil {
+		fatal("%v", err)
+	}
+
+	plan, err := vault.PlanImport(&existing, &incoming.ContextBundle, incoming.Deleted, strategy)
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	// Report before writing anything.
+	fmt.Println(msg("import.summary", len(plan.Added), plan.Unchanged, len(plan.Conflicts)))
+	reader := bufio.NewReader(os.Stdin)
+	anyMasked := false
+	for i := range plan.Conflicts {
+		c := &plan.Conflicts[i]
+		fmt.Println(msg("import.conflict", c.ID,
+			c.Existing.Version, c.Existing.UpdatedAt.Local().Format("2006-01-02 15:04"),
+			c.Incoming.Version, c.Incoming.UpdatedAt.Local().Format("2006-01-02 15:04")))
+		if c.Resolution == "" {
+			if dryRun || path == "-" {
+				// No answer to give: leave the vault value.
+				c.Resolution = vault.ResolveKeep
+			} else {
+				existing, masked := previewValue(c.Existing, c.Incoming)
+				incoming, _ := previewValue(c.Incoming, c.Existing)
+				fmt.Println("  " + msg("import.vault_value", existing))
+				fmt.Println("  " + msg("import.file_value", incoming))
+				anyMasked = noteMasked(masked, anyMasked)
+				fmt.Printf("  %s ", msg("import.prompt"))
+				line, _ := reader.ReadString('\n')
+				c.Resolution = vault.ResolveKeep
+				if affirmative(line) {
+					c.Resolution = vault.ResolveImport
+				}
+			}
+		}
+		if c.Resolution == vault.ResolveImport {
+			fmt.Println("  " + msg("import.take"))
+		} else {
+			fmt.Println("  " + msg("import.keep"))
+		}
+	}
+
+	if len(plan.Deletions) > 0 {
+		fmt.Println(msg("import.deletions", len(plan.Deletions)))
+	}
+	for i := range plan.Deletions {
+		d := &plan.Deletions[i]
+		fmt.Println(msg("import.deletion", d.ID, d.DeletedAt.Local().Format("2006-01-02 15:04"),
+			d.Existing.Version, d.Existing.UpdatedAt.Local().Format("2006-01-02 15:04")))
+		if d.Resolution == "" {
+			d.Resolution = vault.ResolveKeep
+			if !dryRun && path != "-" {
+				existing, masked := previewValue(d.Existing, d.Existing)
+				fmt.Println("  " + msg("import.vault_value", existing))
+				anyMasked = noteMasked(masked, anyMasked)
+				fmt.Printf("  %s ", msg("import.delete_prompt"))
+				line, _ := reader.ReadString('\n')
+				if affirmative(line) {
+					d.Resolution = vault.ResolveImport
+				}
+			}
+		}
+		if d.Resolution == vault.ResolveImport {
+			fmt.Println("  " + msg("import.delete"))
+		} else {
+			fmt.Println("  " + msg("import.keep"))
+		}
+	}
+
+	ops := plan.Ops()
+	if dryRun {
+		fmt.Println(msg("import.dry_run"))
+		return
+	}
+	if len(ops) == 0 {
+		fmt.Println(msg("import.nothing"))
+		return
+	}
+	resp, err = apiRequest("POST", "/vault/transactions", map[string]any{"ops": ops})
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result map[string]any
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	deletes := 0
+	for _, op := range ops {
+		if op.Op == vault.TxDelete {
+			deletes++
+		}
+	}
+	if len(ops) > deletes {
+		fmt.Println(msg("import.done", len(ops)-deletes))
+	}
+	if deletes > 0 {
+		fmt.Println(msg("import.deleted", deletes))
+	}
+}
+
+// importCSV applies a spreadsheet edited from 'pvault export --csv': it
+// validates every row, shows what would change, and asks before writing
+// unless yes is set. The changes are applied as one transaction.
+func importCSV(in io.Reader, dryRun, yes bool) {
+	rows, err := vault.ReadCSV(in)
+	if err != nil {
+		fatal("read CSV: %v", err)
+	}
+	resp, err := apiRequest("GET", revealPath("/vault/context"), nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var existing vault.ContextBundle
+	if err := apiResult(resp, &existing); err != nil {
+		fatal("%v", err)
+	}
+
+	plan := vault.PlanCSV(&existing, rows)
+	fmt.Println(msg("import.csv_summary", len(plan.Added), len(plan.Changed), plan.Unchanged))
+	anyMasked := false
+	for _, f := range plan.Added {
+		shown := f
+		if shown.Sensitivity == "" {
+			shown.Sensitivity = vault.DefaultSensitivity(f.ID)
+		}
+		value, masked := previewValue(shown, shown)
+		anyMasked = anyMasked || masked
+		fmt.Printf("  + %s = %s%s\n", f.ID, value, tierNote(f.Sensitivity))
+	}
+	for _, c := range plan.Changed {
+		if c.New.Value != c.Old.Value {
+			oldValue, m1 := previewValue(c.Old, c.New)
+			newValue, m2 := previewValue(c.New, c.Old)
+			anyMasked = anyMasked || m1 || m2
+			fmt.Printf("  ~ %s: %s → %s\n", c.New.ID, oldValue, newValue)
+		}
+		if c.New.Sensitivity != "" && c.New.Sensitivity != c.Old.Sensitivity {
+			fmt.Printf("  ~ %s: [%s] → [%s]\n", c.New.ID, c.Old.Sensitivity, c.New.Sensitivity)
+		}
+	}
+
+	if anyMasked {
+		fmt.Fprintln(os.Stderr, msg("get.masked"))
+	}
+
+	ops := plan.Ops()
+	if dryRun {
+		fmt.Println(msg("import.dry_run"))
+		return
+	}
+	if len(ops) == 0 {
+		fmt.Println(msg("import.nothing"))
+		return
+	}
+	if !yes {
+		fmt.Printf("%s ", msg("import.csv_prompt"))
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !affirmative(line) {
+			fmt.Println(msg("import.csv_cancelled"))
+			return
+		}
+	}
+	resp, err = apiRequest("POST", "/vault/transactions", map[string]any{"ops": ops})
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("import.done", len(ops)))
+}
+
+// previewValue is f's value as a preview shows it: masked like get masks
+// it, or like the value it stands against if that one is masked.
+func previewValue(f, against vault.FieldInfo) (string, bool) {
+	if vault.MaskedByDefault(against) {
+		f.Sensitivity = "critical"
+	}
+	return maskedValue(&f)
+}
+
+// noteMasked says how to see masked values the first time a preview masks
+// one, and reports whether one has been.
+func noteMasked(masked, before bool) bool {
+	if masked && !before {
+		fmt.Fprintln(os.Stderr, "  "+msg("get.masked"))
+	}
+	return masked || before
+}
+
+// tierNote marks a sensitivity other than the default in a preview line.
+func tierNote(tier string) string {
+	if tier == "" {
+		return ""
+	}
+	return " [" + tier + "]"
+}
@@ -2,41 +2,140 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/lovincyrus/personal-vault/internal/keystore"
 	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
 func cmdInit() {
 	dir := vaultDir()
 
-	pw, err := promptPassword("Profile password: ")
+	opts := vault.InitOptions{NoSecretKeyFile: true}
+	keyMode := defaultKeyMode()
+	var keyOpts keystore.Options
+	args := os.Args[2:]
+	for i, arg := range args {
+		switch arg {
+		case "--encrypt-db":
+			opts.EncryptDatabase = true
+		case "--blind-index":
+			opts.BlindIndex = true
+		case "--encrypt-audit":
+			opts.EncryptAudit = true
+		case "--secret-key":
+			if i+1 < len(args) {
+				var err error
+				if keyMode, err = keystore.ParseMode(args[i+1]); err != nil {
+					fatal("%v", err)
+				}
+			}
+		case "--keyfile":
+			if i+1 < len(args) {
+				keyMode = keystore.Keyfile
+				keyOpts.Keyfile = keyfileArg(args[i+1])
+			}
+		}
+	}
+	if keyMode == keystore.Keychain && !keystore.KeychainAvailable() {
+		fatal("%v", keystore.ErrNoKeychain)
+	}
+	if keyMode == keystore.Keyfile && keyOpts.Keyfile == "" {
+		fatal("%s", msg("keyfile.need_path"))
+	}
+
+	pw, err := promptPassword(msg("prompt.password"))
 	if err != nil {
 		fatal("reading password: %v", err)
 	}
 	if len(pw) < 8 {
-		fatal("password must be at least 8 characters")
+		fatal("%s", msg("password.too_short"))
 	}
 
-	confirm, err := promptPassword("Confirm password: ")
+	confirm, err := promptPassword(msg("prompt.confirm_password"))
 	if err != nil {
 		fatal("reading confirmation: %v", err)
 	}
 	if pw != confirm {
-		fatal("passwords do not match")
+		fatal("%s", msg("password.mismatch"))
+	}
+
+	if keyMode == keystore.PIN {
+		keyOpts.PIN = promptNewPIN()
 	}
 
-	sk, err := vault.Init(dir, pw)
+	sk, err := vault.InitWithOptions(dir, pw, opts)
 	if err != nil {
 		fatal("%v", err)
 	}
+	keyMode = saveNewSecretKey(dir, sk, keyMode, keyOpts)
 
-	fmt.Println("Vault initialized successfully.")
+	fmt.Println(msg("init.done"))
 	fmt.Println()
-	fmt.Println("Your secret key (save this somewhere safe):")
+	fmt.Println(msg("init.secret_key"))
 	fmt.Printf("  %s\n", sk)
 	fmt.Println()
-	fmt.Printf("Secret key also saved to: %s\n", secretKeyPath())
-	fmt.Printf("Vault database: %s/vault.db\n", dir)
+	printKeyStorage(dir, keyMode)
+	if opts.EncryptDatabase {
+		fmt.Printf("Vault database: %s/vault.db.enc (fully encrypted)\n", dir)
+	} else {
+		fmt.Printf("Vault database: %s/vault.db\n", dir)
+	}
 	fmt.Println()
-	fmt.Println("Next: run 'pvault unlock' to start using your vault.")
+	fmt.Println(msg("init.next"))
+}
+
+// defaultKeyMode is where a new vault's secret key goes: the OS keychain if
+// there is one, else an unencrypted file, which 'pvault doctor' flags.
+func defaultKeyMode() keystore.Mode {
+	if keystore.KeychainAvailable() {
+		return keystore.Keychain
+	}
+	return keystore.File
+}
+
+// keyfileArg makes a --keyfile path absolute and checks that its device is
+// mounted before the user is asked for anything.
+func keyfileArg(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		fatal("%v", err)
+	}
+	if !fileExists(filepath.Dir(abs)) {
+		fatal("%s", msg("keyfile.no_device", filepath.Dir(abs)))
+	}
+	return abs
+}
+
+// saveNewSecretKey stores a new vault's secret key in mode, falling back to
+// an unencrypted file if that fails, since the vault can't be opened without
+// the key. It returns the mode used.
+func saveNewSecretKey(dir, sk string, mode keystore.Mode, opts keystore.Options) keystore.Mode {
+	if err := keystore.Save(dir, mode, sk, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", msg("error"), err)
+		mode = keystore.File
+		if err := keystore.Save(dir, mode, sk, keystore.Options{}); err != nil {
+			fatal("write secret key: %v", err)
+		}
+	}
+	return mode
+}
+
+// printKeyStorage tells the user where a new vault's secret key went.
+func printKeyStorage(dir string, mode keystore.Mode) {
+	switch mode {
+	case keystore.File:
+		fmt.Println(msg("init.secret_saved", keystore.Path(dir, mode)))
+		fmt.Println(msg("init.secret_plaintext"))
+	case keystore.Keychain:
+		fmt.Println(msg("init.secret_keychain"))
+	case keystore.PIN:
+		fmt.Println(msg("init.secret_pin", keystore.Path(dir, mode)))
+	case keystore.Keyfile:
+		path, _ := keystore.KeyfilePath(dir)
+		fmt.Println(msg("init.secret_keyfile", path))
+	case keystore.Manual:
+		fmt.Println(msg("init.secret_manual"))
+	}
 }
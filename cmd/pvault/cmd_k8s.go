@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const k8sUsage = "usage: pvault k8s sync --map <field>=<secret>/<key> [--map ...] [--namespace <ns>] [--context <ctx>] [--dry-run]"
+
+var (
+	// k8sNameRe is a DNS subdomain, what Kubernetes allows for a Secret name.
+	k8sNameRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+	// k8sKeyRe is what Kubernetes allows for a key in a Secret's data.
+	k8sKeyRe = regexp.MustCompile(`^[-._a-zA-Z0-9]{1,253}$`)
+)
+
+// localContexts are name prefixes of kubectl contexts that local cluster
+// tools create. A context pointing at a loopback API server also counts.
+var localContexts = []string{"kind-", "k3d-", "minikube", "docker-desktop", "rancher-desktop", "orbstack", "colima"}
+
+// k8sMapping copies one vault field into one key of a Secret.
+type k8sMapping struct {
+	Field, Secret, Key string
+}
+
+func cmdK8s() {
+	if len(os.Args) < 3 || os.Args[2] != "sync" {
+		fatal(k8sUsage)
+	}
+	namespace, kubeContext := "default", ""
+	dryRun := false
+	var mappings []k8sMapping
+	args := os.Args[3:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--dry-run":
+			dryRun = true
+			continue
+		case "--namespace", "-n", "--context", "--map":
+		default:
+			fatal(k8sUsage)
+		}
+		if i+1 >= len(args) {
+			fatal(k8sUsage)
+		}
+		switch args[i] {
+		case "--namespace", "-n":
+			namespace = args[i+1]
+		case "--context":
+			kubeContext = args[i+1]
+		case "--map":
+			m, err := parseK8sMapping(args[i+1])
+			if err != nil {
+				fatal("%v", err)
+			}
+			if slices.ContainsFunc(mappings, func(o k8sMapping) bool { return o.Secret == m.Secret && o.Key == m.Key }) {
+				fatal("--map: %s/%s is mapped twice", m.Secret, m.Key)
+			}
+			mappings = append(mappings, m)
+		}
+		i++
+	}
+	if len(mappings) == 0 {
+		fatal(k8sUsage)
+	}
+	if !k8sNameRe.MatchString(namespace) {
+		fatal("invalid namespace %q", namespace)
+	}
+
+	kc := kubectl{context: kubeContext}
+	kc.requireLocal()
+
+	// Desired data per Secret, read from the vault up front so a missing
+	// field changes nothing.
+	desired := make(map[string]map[string]string)
+	for _, m := range mappings {
+		resp, err := apiRequest("GET", "/vault/fields/"+m.Field, nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		var field vault.FieldInfo
+		if err := apiResult(resp, &field); err != nil {
+			fatal("%s: %v", m.Field, err)
+		}
+		if desired[m.Secret] == nil {
+			desired[m.Secret] = make(map[string]string)
+		}
+		desired[m.Secret][m.Key] = field.Value
+	}
+
+	created, updated := 0, 0
+	for _, name := range slices.Sorted(maps.Keys(desired)) {
+		secret := kc.getSecret(namespace, name)
+		data, _ := secret["data"].(map[string]any)
+		changed := false
+		for _, m := range mappings {
+			if m.Secret != name {
+				continue
+			}
+			want := desired[name][m.Key]
+			current, ok := data[m.Key].(string)
+			switch {
+			case secret == nil || !ok:
+				fmt.Println(msg("k8s.add", namespace, name, m.Key, m.Field))
+				changed = true
+			case current != base64.StdEncoding.EncodeToString([]byte(want)):
+				fmt.Println(msg("k8s.drift", namespace, name, m.Key, m.Field))
+				changed = true
+			default:
+				fmt.Println(msg("k8s.same", namespace, name, m.Key))
+			}
+		}
+		if !changed || dryRun {
+			continue
+		}
+		if secret == nil {
+			kc.createSecret(namespace, name, desired[name])
+			created++
+		} else {
+			kc.updateSecret(secret, desired[name])
+			updated++
+		}
+	}
+	if dryRun {
+		fmt.Println(msg("k8s.dry_run"))
+		return
+	}
+	fmt.Println(msg("k8s.done", created, updated))
+}
+
+// parseK8sMapping parses field=secret/key.
+func parseK8sMapping(s string) (k8sMapping, error) {
+	field, target, ok := strings.Cut(s, "=")
+	secret, key, ok2 := strings.Cut(target, "/")
+	if !ok || !ok2 {
+		return k8sMapping{}, fmt.Errorf("--map %q: want <field>=<secret>/<key>", s)
+	}
+	if err := vault.ValidateFieldID(field); err != nil {
+		return k8sMapping{}, fmt.Errorf("--map %q: %v", s, err)
+	}
+	if !k8sNameRe.MatchString(secret) {
+		return k8sMapping{}, fmt.Errorf("--map %q: invalid Secret name %q", s, secret)
+	}
+	if !k8sKeyRe.MatchString(key) {
+		return k8sMapping{}, fmt.Errorf("--map %q: invalid Secret key %q", s, key)
+	}
+	return k8sMapping{Field: field, Secret: secret, Key: key}, nil
+}
+
+// kubectl runs kubectl against one context. Secret values only ever go
+// over stdin, never on the command line, where other users could see them.
+type kubectl struct {
+	context string
+}
+
+func (k kubectl) run(stdin []byte, args ...string) []byte {
+	path := os.Getenv("KUBECTL")
+	if path == "" {
+		path = "kubectl"
+	}
+	if k.context != "" {
+		args = append([]string{"--context", k.context}, args...)
+	}
+	cmd := exec.Command(path, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			fatal("kubectl %s: %s", args[0], msg)
+		}
+		fatal("kubectl: %v", err)
+	}
+	return out
+}
+
+// requireLocal refuses to go on unless the context is a local development
+// cluster, so a stray kubeconfig can't send vault data to a shared one.
+func (k kubectl) requireLocal() {
+	name := k.context
+	if name == "" {
+		name = strings.TrimSpace(string(k.run(nil, "config", "current-context")))
+	}
+	server := strings.TrimSpace(string(k.run(nil, "config", "view", "--minify", "-o", "jsonpath={.clusters[0].cluster.server}")))
+	for _, prefix := range localContexts {
+		if strings.HasPrefix(name, prefix) {
+			return
+		}
+	}
+	if u, err := url.Parse(server); err == nil {
+		if ip := net.ParseIP(u.Hostname()); (ip != nil && ip.IsLoopback()) || u.Hostname() == "localhost" {
+			return
+		}
+	}
+	fatal("%s", msg("k8s.not_local", name, server))
+}
+
+// getSecret returns the Secret as kubectl prints it, or nil if it doesn't
+// exist.
+func (k kubectl) getSecret(namespace, name string) map[string]any {
+	out := k.run(nil, "get", "secret", name, "-n", namespace, "-o", "json", "--ignore-not-found")
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil
+	}
+	var secret map[string]any
+	if err := json.Unmarshal(out, &secret); err != nil {
+		fatal("kubectl get secret %s: %v", name, err)
+	}
+	return secret
+}
+
+func (k kubectl) createSecret(namespace, name string, data map[string]string) {
+	encoded := make(map[string]string, len(data))
+	for key, value := range data {
+		encoded[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	manifest, _ := json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]string{"app.kubernetes.io/managed-by": "pvault"},
+		},
+		"data": encoded,
+	})
+	k.run(manifest, "create", "-f", "-")
+}
+
+// updateSecret writes data into an existing Secret with kubectl replace,
+// which keeps keys pvault doesn't manage and fails rather than overwrite a
+// change made since it was read. (kubectl apply would copy the values into
+// its last-applied annotation.)
+func (k kubectl) updateSecret(secret map[string]any, data map[string]string) {
+	existing, _ := secret["data"].(map[string]any)
+	if existing == nil {
+		existing = make(map[string]any)
+	}
+	for key, value := range data {
+		existing[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	secret["data"] = existing
+	manifest, _ := json.Marshal(secret)
+	k.run(manifest, "replace", "-f", "-")
+}
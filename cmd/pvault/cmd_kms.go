@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/kms"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const kmsUsage = "usage: pvault kms [status | enroll hashicorp|aws|gcp --key <key> [--address <url>] [--mount <path>] [--region <r>] | remove]"
+
+// kmsTimeout bounds a call to the KMS.
+const kmsTimeout = 30 * time.Second
+
+func cmdKMS() {
+	args := os.Args[2:]
+	if len(args) == 0 || args[0] == "status" {
+		kmsStatus()
+		return
+	}
+	switch {
+	case args[0] == "enroll" && len(args) >= 2:
+		kmsEnroll(args[1], args[2:])
+	case args[0] == "remove" && len(args) == 1:
+		if err := kms.Remove(vaultDir()); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("kms.removed"))
+	default:
+		fatal(kmsUsage)
+	}
+}
+
+func kmsEnroll(provider string, args []string) {
+	p, err := kms.ParseProvider(provider)
+	if err != nil {
+		fatal("%v", err)
+	}
+	e := &kms.Enrollment{Provider: p}
+	for i := 0; i < len(args); i++ {
+		if i+1 >= len(args) {
+			fatal(kmsUsage)
+		}
+		switch args[i] {
+		case "--key":
+			e.Key = args[i+1]
+		case "--address":
+			e.Address = args[i+1]
+		case "--mount":
+			e.Mount = args[i+1]
+		case "--region":
+			e.Region = args[i+1]
+		default:
+			fatal(kmsUsage)
+		}
+		i++
+	}
+	w, err := kms.NewWrapper(*e)
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	dir := vaultDir()
+	pw, err := promptPassword(msg("prompt.password"))
+	if err != nil {
+		fatal("reading password: %v", err)
+	}
+	sk, err := readSecretKey()
+	if err != nil {
+		fatal("%v", err)
+	}
+	v, err := vault.Open(dir)
+	if err != nil {
+		fatal("open vault: %v", err)
+	}
+	key, err := v.ExportUnlockKey(pw, sk)
+	v.Close()
+	if err != nil {
+		fatal("%v", err)
+	}
+	defer clear(key)
+
+	ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+	defer cancel()
+	if e.Ciphertext, err = w.Wrap(ctx, key); err != nil {
+		fatal("%v", err)
+	}
+	// The server will need to decrypt, not just encrypt; find out now
+	// rather than at the next unattended start.
+	back, err := w.Unwrap(ctx, e.Ciphertext)
+	if err != nil {
+		fatal("%s", msg("kms.unwrap_failed", err))
+	}
+	if subtle.ConstantTimeCompare(back, key) != 1 {
+		fatal("%s", msg("kms.unwrap_failed", "the key came back different"))
+	}
+	clear(back)
+	e.CreatedAt = time.Now().UTC()
+	if err := kms.Save(dir, e); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("kms.enrolled", e.Provider, e.Key))
+	fmt.Println(msg("kms.headless"))
+}
+
+func kmsStatus() {
+	e, err := kms.Load(vaultDir())
+	if errors.Is(err, kms.ErrNotEnrolled) {
+		fmt.Println(msg("kms.none"))
+		return
+	}
+	if err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("kms.status", e.Provider, e.Key, e.CreatedAt.Local().Format("2006-01-02")))
+	if e.Address != "" {
+		fmt.Printf("  %s\n", e.Address)
+	}
+}
+
+// kmsUnlockKey unwraps the enrolled unlock key for serve --headless.
+func kmsUnlockKey(e *kms.Enrollment) ([]byte, error) {
+	w, err := kms.NewWrapper(*e)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+	defer cancel()
+	key, err := w.Unwrap(ctx, e.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap with %s: %w", e.Provider, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const linkUsage = "usage: pvault link [<from> <to> [--type <type>]]"
+
+func cmdLink() {
+	if len(os.Args) == 2 {
+		listLinks()
+		return
+	}
+	from, to, linkType := linkArgs(linkUsage)
+	resp, err := apiRequest("POST", "/vault/links", map[string]string{"from": from, "to": to, "type": linkType})
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("link.linked", from, to))
+}
+
+func cmdUnlink() {
+	from, to, linkType := linkArgs("usage: pvault unlink <from> <to> [--type <type>]")
+	q := url.Values{"from": {from}, "to": {to}, "type": {linkType}}
+	resp, err := apiRequest("DELETE", "/vault/links?"+q.Encode(), nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("link.unlinked", from, to))
+}
+
+// linkArgs parses <from> <to> [--type <type>].
+func linkArgs(usage string) (from, to, linkType string) {
+	args := os.Args[2:]
+	switch {
+	case len(args) == 2:
+	case len(args) == 4 && args[2] == "--type":
+		linkType = args[3]
+	default:
+		fatal(usage)
+	}
+	return args[0], args[1], linkType
+}
+
+func listLinks() {
+	resp, err := apiRequest("GET", "/vault/links", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var links []vault.FieldLink
+	if err := apiResult(resp, &links); err != nil {
+		fatal("%v", err)
+	}
+	if len(links) == 0 {
+		fmt.Println(msg("link.empty"))
+		return
+	}
+	for _, l := range links {
+		fmt.Printf("%-32s %-20s %s\n", l.From, l.Type, l.To)
+	}
+}
@@ -3,40 +3,89 @@ package main
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
 func cmdList() {
-	path := "/vault/fields"
-	if len(os.Args) >= 3 {
-		path = "/vault/fields/category/" + os.Args[2]
-	}
-
-	resp, err := apiRequest("GET", path, nil)
-	if err != nil {
-		fatal("request failed: %v", err)
-	}
-
+	args := os.Args[2:]
 	var fields []vault.FieldInfo
-	if err := apiResult(resp, &fields); err != nil {
-		fatal("%v", err)
+	if offline {
+		v := openOffline(false)
+		var err error
+		if len(args) >= 1 {
+			fields, err = v.GetByCategory(args[0])
+			logOfflineReveal(v, args[0]+".*", fields)
+		} else {
+			fields, err = v.List()
+		}
+		closeOffline(v)
+		if err != nil {
+			fatal("%v", err)
+		}
+	} else {
+		path := "/vault/fields"
+		if len(args) >= 1 {
+			path = "/vault/fields/category/" + args[0]
+		}
+		resp, err := apiRequest("GET", revealPath(path), nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, &fields); err != nil {
+			fatal("%v", err)
+		}
 	}
 
 	if len(fields) == 0 {
-		fmt.Println("No fields found.")
+		fmt.Println(msg("list.empty"))
 		return
 	}
 
+	// Pinned fields surface first.
+	slices.SortStableFunc(fields, func(a, b vault.FieldInfo) int {
+		switch {
+		case a.Pinned == b.Pinned:
+			return 0
+		case a.Pinned:
+			return -1
+		default:
+			return 1
+		}
+	})
+	anyMasked := false
 	for _, f := range fields {
 		sens := ""
 		if f.Sensitivity != "" && f.Sensitivity != "standard" {
 			sens = fmt.Sprintf(" [%s]", f.Sensitivity)
 		}
-		if f.Value != "" {
-			fmt.Printf("%-35s %s%s\n", f.ID, f.Value, sens)
+		if f.Type != "" {
+			sens += fmt.Sprintf(" [%s]", f.Type)
+		}
+		if f.Pinned {
+			sens += " [pinned]"
+		}
+		value, masked := maskedValue(&f)
+		anyMasked = anyMasked || masked
+		if value != "" {
+			fmt.Printf("%-35s %s%s\n", f.ID, firstLine(value), sens)
 		} else {
 			fmt.Printf("%-35s (v%d)%s\n", f.ID, f.Version, sens)
 		}
 	}
+	if anyMasked {
+		fmt.Fprintln(os.Stderr, msg("get.masked"))
+	}
+}
+
+// firstLine keeps a multi-line value, such as a PEM block, to one row of
+// the listing; 'pvault get' prints it whole.
+func firstLine(value string) string {
+	first, rest, ok := strings.Cut(value, "\n")
+	if !ok {
+		return value
+	}
+	return fmt.Sprintf("%s … (+%d lines)", strings.TrimSuffix(first, "\r"), strings.Count(rest, "\n")+1)
 }
@@ -2,34 +2,33 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"syscall"
+
+	"github.com/lovincyrus/personal-vault/internal/proc"
 )
 
 func cmdLock() {
+	// Resolve the language while the vault can still report its preference.
+	cliLang()
+
 	resp, err := apiRequest("POST", "/vault/lock", nil)
 	if err != nil {
 		// Try to kill server directly
 		if pid, pidErr := readPID(); pidErr == nil {
-			if p, findErr := os.FindProcess(pid); findErr == nil {
-				p.Signal(syscall.SIGTERM)
-			}
+			proc.Terminate(pid)
 		}
-		removeSessionToken()
+		clearSessionToken()
 		removePID()
-		fmt.Println("Vault locked (server stopped).")
+		fmt.Println(msg("lock.stopped"))
 		return
 	}
 	resp.Body.Close()
 
 	// Kill the server process
 	if pid, err := readPID(); err == nil {
-		if p, err := os.FindProcess(pid); err == nil {
-			p.Signal(syscall.SIGTERM)
-		}
+		proc.Terminate(pid)
 	}
 
-	removeSessionToken()
+	clearSessionToken()
 	removePID()
-	fmt.Println("Vault locked.")
+	fmt.Println(msg("lock.done"))
 }
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// mergePurpose marks the audit entries a merge writes.
+const mergePurpose = "merge"
+
+// cmdMerge merges another copy of this vault, such as one that diverged on
+// another machine, into the running one. Both must open with the same
+// password and secret key. Field by field, whichever copy changed last wins;
+// the other copy is only read.
+func cmdMerge() {
+	dryRun := false
+	var path string
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--dry-run":
+			dryRun = true
+		default:
+			path = arg
+		}
+	}
+	if path == "" {
+		fatal("usage: pvault merge [--dry-run] <other vault.db or vault dir>")
+	}
+
+	resp, err := apiRequest("GET", "/vault/export", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var local vault.Changes
+	if err := apiResult(resp, &local); err != nil {
+		fatal("%v", err)
+	}
+
+	other := readOtherVault(path)
+	plan, err := vault.PlanMerge(&local, other)
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	fmt.Println(msg("import.summary", len(plan.Added), plan.Unchanged, len(plan.Conflicts)))
+	for _, c := range plan.Conflicts {
+		fmt.Println(msg("merge.conflict", c.ID,
+			c.Existing.Version, c.Existing.UpdatedAt.Local().Format("2006-01-02 15:04"),
+			c.Incoming.Version, c.Incoming.UpdatedAt.Local().Format("2006-01-02 15:04")))
+		if c.Resolution == vault.ResolveImport {
+			fmt.Println("  " + msg("merge.take"))
+		} else {
+			fmt.Println("  " + msg("import.keep"))
+		}
+	}
+	if len(plan.Deletions) > 0 {
+		fmt.Println(msg("merge.deletions", len(plan.Deletions)))
+	}
+	for _, d := range plan.Deletions {
+		fmt.Println(msg("merge.deletion", d.ID, d.DeletedAt.Local().Format("2006-01-02 15:04"),
+			d.Existing.Version, d.Existing.UpdatedAt.Local().Format("2006-01-02 15:04")))
+		if d.Resolution == vault.ResolveImport {
+			fmt.Println("  " + msg("import.delete"))
+		} else {
+			fmt.Println("  " + msg("import.keep"))
+		}
+	}
+
+	ops := plan.Ops()
+	if dryRun {
+		fmt.Println(msg("import.dry_run"))
+		return
+	}
+	if len(ops) == 0 {
+		fmt.Println(msg("merge.nothing"))
+		return
+	}
+	resp, err = apiRequest("POST", "/vault/transactions", map[string]any{"ops": ops, "purpose": mergePurpose})
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		TransactionID string `json:"transaction_id"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("merge.done", len(ops), result.TransactionID))
+}
+
+// readOtherVault opens the vault at path, a database file or a vault
+// directory, with this vault's credentials and returns its fields and
+// deletions.
+func readOtherVault(path string) *vault.Changes {
+	dbPath := path
+	if info, err := os.Stat(path); err != nil {
+		fatal("%v", err)
+	} else if info.IsDir() {
+		dbPath = filepath.Join(path, "vault.db.enc")
+		if _, err := os.Stat(dbPath); err != nil {
+			dbPath = filepath.Join(path, "vault.db")
+		}
+	}
+	other, err := os.Stat(dbPath)
+	if err != nil {
+		fatal("no vault database at %s", path)
+	}
+	for _, name := range []string{"vault.db", "vault.db.enc"} {
+		if own, err := os.Stat(filepath.Join(vaultDir(), name)); err == nil && os.SameFile(own, other) {
+			fatal("%s", msg("merge.same"))
+		}
+	}
+
+	var db store.Store
+	if strings.HasSuffix(dbPath, ".enc") {
+		db, err = store.OpenEncrypted(dbPath)
+	} else {
+		db, err = store.Open(dbPath)
+	}
+	if err != nil {
+		fatal("open %s: %v", dbPath, err)
+	}
+	if ok, err := db.IsInitialized(); err != nil || !ok {
+		db.Close()
+		fatal("%s is not an initialized vault", dbPath)
+	}
+	v := vault.OpenStore(filepath.Dir(dbPath), db)
+	defer v.Close()
+
+	pw, err := promptPassword(msg("prompt.password"))
+	if err != nil {
+		fatal("reading password: %v", err)
+	}
+	sk, err := readSecretKey()
+	if err != nil {
+		fatal("%v", err)
+	}
+	if _, err := v.Unlock(pw, sk); err != nil {
+		fatal("unlock %s: %v", dbPath, err)
+	}
+	defer v.Lock()
+	changes, err := v.ChangesSince(time.Time{})
+	if err != nil {
+		fatal("read %s: %v", dbPath, err)
+	}
+	return changes
+}
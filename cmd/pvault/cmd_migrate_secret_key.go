@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/keystore"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const migrateSecretKeyUsage = "usage: pvault migrate-secret-key <keychain | pin | keyfile <path> | manual | file>"
+
+// cmdMigrateSecretKey moves the secret key to another storage mode. The key
+// is checked against the vault first, so a mistyped one is never stored
+// in place of the real one.
+func cmdMigrateSecretKey() {
+	if len(os.Args) < 3 {
+		fatal(migrateSecretKeyUsage)
+	}
+	mode, err := keystore.ParseMode(os.Args[2])
+	if err != nil {
+		fatal("%v", err)
+	}
+	var opts keystore.Options
+	if mode == keystore.Keyfile {
+		if len(os.Args) != 4 {
+			fatal(migrateSecretKeyUsage)
+		}
+		opts.Keyfile = keyfileArg(os.Args[3])
+	} else if len(os.Args) != 3 {
+		fatal(migrateSecretKeyUsage)
+	}
+	dir := vaultDir()
+	// Moving to PIN from PIN changes the PIN; keyfile to keyfile moves the
+	// key to another device.
+	if current := keystore.Detect(dir); current == mode && mode != keystore.PIN && mode != keystore.Keyfile {
+		fmt.Println(msg("migrate.same", keyModeName(mode)))
+		return
+	}
+	if mode == keystore.Keychain && !keystore.KeychainAvailable() {
+		fatal("%v", keystore.ErrNoKeychain)
+	}
+
+	sk, err := readSecretKey()
+	if err != nil {
+		fatal("%v", err)
+	}
+	v, err := vault.Open(dir)
+	if err != nil {
+		fatal("open vault: %v", err)
+	}
+	err = v.CheckSecretKey(sk)
+	v.Close()
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	switch mode {
+	case keystore.PIN:
+		opts.PIN = promptNewPIN()
+	case keystore.Manual:
+		fmt.Println(msg("migrate.confirm_manual"))
+		typed, err := promptPassword(msg("prompt.secret_key"))
+		if err != nil {
+			fatal("reading secret key: %v", err)
+		}
+		if strings.TrimSpace(typed) != sk {
+			fatal("%s", msg("migrate.mismatch"))
+		}
+	}
+	if err := keystore.Save(dir, mode, sk, opts); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("migrate.done", keyModeName(mode)))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const noteUsage = "usage: pvault note <id> [<text> | --clear]"
+
+func cmdNote() {
+	args := os.Args[2:]
+	switch {
+	case len(args) == 1:
+		showNote(args[0])
+	case len(args) == 2 && args[1] == "--clear":
+		setNote(args[0], "")
+		fmt.Println(msg("note.cleared", args[0]))
+	case len(args) >= 2:
+		setNote(args[0], strings.Join(args[1:], " "))
+		fmt.Println(msg("note.set", args[0]))
+	default:
+		fatal(noteUsage)
+	}
+}
+
+func setNote(id, note string) {
+	resp, err := apiRequest("PUT", "/vault/notes/"+id, map[string]string{"note": note})
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+}
+
+// showNote prints a field's note from the field list, so the value isn't
+// read just to see it.
+func showNote(id string) {
+	resp, err := apiRequest("GET", "/vault/fields", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var fields []vault.FieldInfo
+	if err := apiResult(resp, &fields); err != nil {
+		fatal("%v", err)
+	}
+	for _, f := range fields {
+		if f.ID == id && f.Note != "" {
+			fmt.Println(f.Note)
+			return
+		}
+	}
+	fmt.Println(msg("note.none", id))
+}
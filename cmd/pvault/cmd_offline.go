@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// offline is set by --offline: get, set, and list then open the vault
+// database themselves instead of going through the server.
+var offline bool
+
+// offlineCommands are the commands --offline supports.
+var offlineCommands = map[string]bool{"get": true, "set": true, "list": true}
+
+// openOffline opens and unlocks the vault database in this process, taking
+// the password from VAULT_PASSWORD or a prompt and the secret key from
+// VAULT_SECRET_KEY or wherever it is stored. A write is refused while a
+// server is running, since the server holds its own copy of the vault and
+// wouldn't see it. The caller locks and closes the vault.
+func openOffline(write bool) *vault.Vault {
+	dir := vaultDir()
+	if !fileExists(filepath.Join(dir, "vault.db")) && !fileExists(filepath.Join(dir, "vault.db.enc")) {
+		fatal("%s", msg("offline.no_vault", dir))
+	}
+	if write && portHasVault() {
+		fatal("%s", msg("offline.server_running", serverAddr()))
+	}
+
+	pw := os.Getenv("VAULT_PASSWORD")
+	os.Unsetenv("VAULT_PASSWORD")
+	if pw == "" {
+		var err error
+		if pw, err = promptPassword(msg("prompt.password")); err != nil {
+			fatal("reading password: %v", err)
+		}
+	}
+	sk := os.Getenv("VAULT_SECRET_KEY")
+	os.Unsetenv("VAULT_SECRET_KEY")
+	if sk == "" {
+		var err error
+		if sk, err = readSecretKey(); err != nil {
+			fatal("%v", err)
+		}
+	}
+
+	v, err := vault.Open(dir)
+	if err != nil {
+		fatal("open vault: %v", err)
+	}
+	if _, err := v.Unlock(pw, sk); err != nil {
+		v.Close()
+		fatal("unlock: %v", err)
+	}
+	return v
+}
+
+// closeOffline locks and closes a vault from openOffline.
+func closeOffline(v *vault.Vault) {
+	v.Lock()
+	v.Close()
+}
@@ -5,61 +5,133 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/http"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/lovincyrus/personal-vault/internal/keystore"
 	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
-var onboardFields = []struct {
-	prompt  string
-	fieldID string
-}{
-	{"Full name", "identity.full_name"},
-	{"Email", "identity.email"},
-	{"City", "addresses.home_city"},
-	{"State", "addresses.home_state"},
-	{"ZIP", "addresses.home_zip"},
-	{"Country", "addresses.home_country"},
-	{"Timezone", "preferences.timezone"},
+// onboardFields are prompted for in order, labeled by fieldLabel.
+var onboardFields = []string{
+	"identity.full_name",
+	"identity.email",
+	"addresses.home_street",
+	"addresses.home_city",
+	"addresses.home_state",
+	"addresses.home_zip",
+	"addresses.home_country",
+	"preferences.timezone",
+}
+
+// homeAddress is the home address fields, in prompt order.
+var homeAddress = []string{
+	"addresses.home_street",
+	"addresses.home_city",
+	"addresses.home_state",
+	"addresses.home_zip",
+	"addresses.home_country",
+}
+
+// onboardProfiles are the field sets --profile picks instead of
+// onboardFields, for what each kind of user's agents need.
+var onboardProfiles = map[string][]string{
+	"minimal": {"identity.full_name", "identity.email", "preferences.timezone"},
+	"travel": slices.Concat(
+		[]string{"identity.full_name", "identity.email", "identity.phone", "identity.date_of_birth"},
+		homeAddress,
+		[]string{"preferences.language", "preferences.timezone"},
+	),
+	"tax": slices.Concat(
+		[]string{"identity.full_name", "identity.date_of_birth", "financial.ssn", "financial.filing_status"},
+		homeAddress,
+		[]string{"employment.employer", "employment.title"},
+	),
+}
+
+const onboardUsage = "usage: pvault onboard [--profile travel|tax|minimal] [--from-file answers.json]"
+
+// onboardAnswers is the --from-file format: the password (else
+// VAULT_PASSWORD) and the field values to store.
+type onboardAnswers struct {
+	Password string            `json:"password"`
+	Fields   map[string]string `json:"fields"`
 }
 
 func cmdOnboard() {
 	dir := vaultDir()
+	var profile []string
+	var answers *onboardAnswers
+	for i := 2; i < len(os.Args); i++ {
+		switch {
+		case os.Args[i] == "--profile" && i+1 < len(os.Args):
+			i++
+			var ok bool
+			if profile, ok = onboardProfiles[os.Args[i]]; !ok {
+				fatal("%s", msg("onboard.unknown_profile", os.Args[i], strings.Join(slices.Sorted(maps.Keys(onboardProfiles)), ", ")))
+			}
+		case os.Args[i] == "--from-file" && i+1 < len(os.Args):
+			i++
+			answers = readOnboardAnswers(os.Args[i])
+		default:
+			fatal(onboardUsage)
+		}
+	}
 
 	// Check if vault already exists
 	if _, err := os.Stat(dir + "/vault.db"); err == nil {
 		fatal("vault already initialized at %s — use 'pvault unlock' instead", dir)
 	}
 
-	fmt.Println("Create your vault")
+	fmt.Println(msg("onboard.title"))
 
-	pw, err := promptPassword("  Profile password: ")
-	if err != nil {
-		fatal("reading password: %v", err)
-	}
-	if len(pw) < 8 {
-		fatal("password must be at least 8 characters")
-	}
+	var pw string
+	if answers != nil {
+		pw = answers.Password
+		if pw == "" {
+			pw = os.Getenv("VAULT_PASSWORD")
+		}
+		os.Unsetenv("VAULT_PASSWORD")
+		if pw == "" {
+			fatal("%s", msg("onboard.no_password"))
+		}
+		if len(pw) < 8 {
+			fatal("%s", msg("password.too_short"))
+		}
+	} else {
+		var err error
+		pw, err = promptPassword("  " + msg("prompt.password"))
+		if err != nil {
+			fatal("reading password: %v", err)
+		}
+		if len(pw) < 8 {
+			fatal("%s", msg("password.too_short"))
+		}
 
-	confirm, err := promptPassword("  Confirm: ")
-	if err != nil {
-		fatal("reading confirmation: %v", err)
-	}
-	if pw != confirm {
-		fatal("passwords do not match")
+		confirm, err := promptPassword("  " + msg("prompt.confirm"))
+		if err != nil {
+			fatal("reading confirmation: %v", err)
+		}
+		if pw != confirm {
+			fatal("%s", msg("password.mismatch"))
+		}
 	}
 
-	sk, err := vault.Init(dir, pw)
+	sk, err := vault.InitWithOptions(dir, pw, vault.InitOptions{NoSecretKeyFile: true})
 	if err != nil {
 		fatal("%v", err)
 	}
+	keyMode := saveNewSecretKey(dir, sk, defaultKeyMode(), keystore.Options{})
 
 	fmt.Println()
-	fmt.Println("Your secret key (save this somewhere safe):")
+	fmt.Println(msg("init.secret_key"))
 	fmt.Printf("  %s\n", sk)
+	printKeyStorage(dir, keyMode)
 	fmt.Println()
 
 	// Start background server (same pattern as cmdUnlock)
@@ -97,7 +169,7 @@ func cmdOnboard() {
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("Vault server started (could not verify status).")
+			fmt.Println(msg("unlock.unverified"))
 			return
 		default:
 			resp, err := apiRequest("GET", "/vault/status", nil)
@@ -118,42 +190,169 @@ func cmdOnboard() {
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	fmt.Printf("Vault unlocked. Server running on %s\n", serverAddr())
+	fmt.Println(msg("unlock.done", serverAddr()))
 	fmt.Println()
 
+	if answers != nil {
+		saveOnboardAnswers(answers, profile)
+		return
+	}
+
 	// Prompt for common fields
-	fmt.Println("Let's add some basics (press Enter to skip any):")
+	fmt.Println(msg("onboard.basics"))
 	reader := bufio.NewReader(os.Stdin)
 	saved := 0
 
-	for _, f := range onboardFields {
-		fmt.Printf("  %s: ", f.prompt)
+	fields := onboardFields
+	if profile != nil {
+		fields = profile
+	}
+	for _, id := range fields {
+		fmt.Printf("  %s: ", fieldLabel(id))
 		line, _ := reader.ReadString('\n')
 		value := strings.TrimSpace(line)
 		if value == "" {
 			continue
 		}
 
-		resp, err := apiRequest("PUT", "/vault/fields/"+f.fieldID, map[string]string{
+		resp, err := apiRequest("PUT", "/vault/fields/"+id, map[string]string{
 			"value": value,
 		})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "  Warning: could not save %s: %v\n", f.fieldID, err)
+			fmt.Fprintf(os.Stderr, "  Warning: could not save %s: %v\n", id, err)
 			continue
 		}
 		resp.Body.Close()
 		if resp.StatusCode >= 400 {
-			fmt.Fprintf(os.Stderr, "  Warning: could not save %s (HTTP %d)\n", f.fieldID, resp.StatusCode)
+			fmt.Fprintf(os.Stderr, "  Warning: could not save %s (HTTP %d)\n", id, resp.StatusCode)
 			continue
 		}
 		saved++
 	}
 
+	if saved > 0 {
+		reviewAddressSuggestions(reader)
+	}
+
 	fmt.Println()
 	if saved > 0 {
-		fmt.Printf("Done — %d field(s) saved. Your vault is ready.\n", saved)
+		fmt.Println(msg("onboard.done_saved", saved))
 	} else {
-		fmt.Println("Done — your vault is ready.")
+		fmt.Println(msg("onboard.done"))
+	}
+	fmt.Println(msg("onboard.next"))
+}
+
+// readOnboardAnswers loads a --from-file answers file, checking every field
+// ID before anything is created.
+func readOnboardAnswers(path string) *onboardAnswers {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fatal("%v", err)
+	}
+	var answers onboardAnswers
+	if err := json.Unmarshal(data, &answers); err != nil {
+		fatal("%s", msg("onboard.bad_file", path, err))
+	}
+	for id := range answers.Fields {
+		if err := vault.ValidateFieldID(id); err != nil {
+			fatal("%s", msg("onboard.bad_file", path, err))
+		}
+	}
+	return &answers
+}
+
+// saveOnboardAnswers stores the answers' values for the profile's fields, or
+// for every field in the file when profile is nil, and stops at the first
+// that fails so a provisioning script sees it.
+func saveOnboardAnswers(answers *onboardAnswers, profile []string) {
+	ids := profile
+	if ids == nil {
+		ids = slices.Sorted(maps.Keys(answers.Fields))
+	}
+	saved := 0
+	for _, id := range ids {
+		value := strings.TrimSpace(answers.Fields[id])
+		if value == "" {
+			continue
+		}
+		resp, err := apiRequest("PUT", "/vault/fields/"+id, map[string]string{"value": value})
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%s: %v", id, err)
+		}
+		saved++
+	}
+	fmt.Println(msg("onboard.done_saved", saved))
+}
+
+// fieldLabel is how a prompt names a field: its onboarding label if it has
+// one, else its schema description, else its ID.
+func fieldLabel(id string) string {
+	if label := msg("onboard.field." + id); label != "onboard.field."+id {
+		return label
+	}
+	for _, c := range vault.LocalizedSchema(cliLang()).Categories {
+		for _, f := range c.Fields {
+			if f.ID == id {
+				return f.Description
+			}
+		}
+	}
+	return id
+}
+
+// reviewAddressSuggestions asks the server's address enricher, if one is
+// configured, to check the home address and offers each suggested change.
+// Nothing is written unless the user accepts it.
+func reviewAddressSuggestions(reader *bufio.Reader) {
+	resp, err := apiRequest("POST", "/vault/enrich/address", map[string]string{"prefix": "home"})
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return // no enricher configured
+	}
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintln(os.Stderr, "  "+msg("enrich.failed", resp.StatusCode))
+		return
+	}
+	var result struct {
+		Suggestions []vault.ValueSuggestion `json:"suggestions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || len(result.Suggestions) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(msg("enrich.title"))
+	for _, s := range result.Suggestions {
+		label := fieldLabel(s.FieldID)
+		if s.Current != "" {
+			fmt.Printf("  %s ", msg("enrich.change", label, s.Current, s.Suggested))
+		} else {
+			fmt.Printf("  %s ", msg("enrich.add", label, s.Suggested))
+		}
+		line, _ := reader.ReadString('\n')
+		action, path := "DELETE", "/vault/suggestions/"+s.ID
+		if affirmative(line) {
+			action, path = "POST", path+"/accept"
+		}
+		if r, err := apiRequest(action, path, nil); err == nil {
+			r.Body.Close()
+		}
+	}
+}
+
+// affirmative reports whether a prompt answer means yes in any of the CLI's
+// languages.
+func affirmative(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "s", "si", "sí", "j", "ja", "o", "oui", "是":
+		return true
 	}
-	fmt.Println("Run 'pvault status' to see what's stored.")
+	return false
 }
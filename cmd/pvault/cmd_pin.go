@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+func cmdPin() {
+	if len(os.Args) < 3 {
+		listPinned()
+		return
+	}
+	id := os.Args[2]
+	resp, err := apiRequest("PUT", "/vault/pins/"+id, nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("pin.pinned", id))
+}
+
+func cmdUnpin() {
+	if len(os.Args) < 3 {
+		fatal("usage: pvault unpin <id>")
+	}
+	id := os.Args[2]
+	resp, err := apiRequest("DELETE", "/vault/pins/"+id, nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("pin.unpinned", id))
+}
+
+func listPinned() {
+	resp, err := apiRequest("GET", "/vault/fields?pinned=true", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var fields []vault.FieldInfo
+	if err := apiResult(resp, &fields); err != nil {
+		fatal("%v", err)
+	}
+	if len(fields) == 0 {
+		fmt.Println(msg("pin.empty"))
+		return
+	}
+	for _, f := range fields {
+		fmt.Println(f.ID)
+	}
+}
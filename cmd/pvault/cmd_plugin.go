@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/config"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// pluginPrefix names plugin programs: 'pvault foo' runs pvault-foo from
+// PATH, and plugins.hooks = foo runs it as 'pvault-foo hook'.
+const pluginPrefix = "pvault-"
+
+// pluginDescribeTimeout bounds asking a hook plugin what it handles.
+const pluginDescribeTimeout = 5 * time.Second
+
+// runPluginCommand runs pvault-<name> with the remaining arguments, the way
+// git runs git-<name>, and exits with its status. It returns only if there
+// is no such program.
+func runPluginCommand(name string) {
+	if strings.ContainsAny(name, `/\`) {
+		return
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(path, os.Args[2:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), pluginEnv()...)
+	err = cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.ExitCode())
+	}
+	if err != nil {
+		fatal("%s: %v", pluginPrefix+name, err)
+	}
+	os.Exit(0)
+}
+
+// pluginEnv tells a plugin where its vault and server are, and how to call
+// back into pvault.
+func pluginEnv() []string {
+	env := []string{"VAULT_DIR=" + vaultDir(), "VAULT_ADDR=" + serverAddr()}
+	if exe, err := os.Executable(); err == nil {
+		env = append(env, "PVAULT_BIN="+exe)
+	}
+	return env
+}
+
+// pluginCommands lists the pvault-<name> programs on PATH by name.
+func pluginCommands() map[string]string {
+	found := make(map[string]string)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), pluginPrefix)
+			if !ok || e.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if _, seen := found[name]; seen {
+				continue // PATH order: the first one wins, as for LookPath
+			}
+			path := filepath.Join(dir, e.Name())
+			if _, err := exec.LookPath(path); err == nil {
+				found[name] = path
+			}
+		}
+	}
+	return found
+}
+
+// pvault plugins
+func cmdPlugins() {
+	commands := pluginCommands()
+	if len(commands) == 0 {
+		fmt.Println(msg("plugins.no_commands"))
+	} else {
+		fmt.Println(msg("plugins.commands"))
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Printf("  %-20s %s\n", name, commands[name])
+		}
+	}
+
+	cfg := cliConfig()
+	hooks := cfg.Value("plugins.hooks")
+	if hooks == "" {
+		fmt.Println(msg("plugins.no_hooks"))
+		return
+	}
+	fmt.Println(msg("plugins.hooks"))
+	for _, spec := range strings.Split(hooks, ",") {
+		spec = strings.TrimSpace(spec)
+		p, err := hookPlugin(spec)
+		if err != nil {
+			fmt.Printf("  %-20s %s\n", spec, msg("plugins.broken", err))
+			continue
+		}
+		fmt.Printf("  %-20s %s\n", p.Name(), strings.Join(p.Hooks(), ", "))
+	}
+}
+
+// configurePlugins loads the hook plugins named in plugins.hooks. A plugin
+// that can't describe itself stops the server: starting without a write
+// validator the owner relies on would be worse than not starting.
+func configurePlugins(v *vault.Vault, cfg *config.Config) {
+	raw := cfg.Value("plugins.hooks")
+	if raw == "" {
+		return
+	}
+	var plugins []vault.Plugin
+	for _, spec := range strings.Split(raw, ",") {
+		p, err := hookPlugin(strings.TrimSpace(spec))
+		if err != nil {
+			fatal("plugins.hooks: %v", err)
+		}
+		plugins = append(plugins, p)
+	}
+	v.SetPlugins(plugins)
+}
+
+// hookPlugin resolves a plugins.hooks entry, a plugin name or a path, and
+// asks it which hooks it handles.
+func hookPlugin(spec string) (*vault.CommandPlugin, error) {
+	if spec == "" {
+		return nil, errors.New("empty plugin name")
+	}
+	path := spec
+	if !strings.ContainsAny(spec, `/\`) {
+		var err error
+		if path, err = exec.LookPath(pluginPrefix + spec); err != nil {
+			return nil, fmt.Errorf("%s: no %s%s on PATH", spec, pluginPrefix, spec)
+		}
+	}
+	p := &vault.CommandPlugin{Path: path, Args: []string{"hook"}}
+	ctx, cancel := context.WithTimeout(context.Background(), pluginDescribeTimeout)
+	defer cancel()
+	if err := p.Describe(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", spec, err)
+	}
+	return p, nil
+}
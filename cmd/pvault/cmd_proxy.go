@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/proc"
+	"github.com/lovincyrus/personal-vault/internal/proxy"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const (
+	proxyUsage = "usage: pvault proxy --rule <host>:<Header>=[prefix ]<field> [--rule ...] [--listen 127.0.0.1:7210]\n  example: pvault proxy --rule api.stripe.com:Authorization=secrets.stripe_key"
+
+	defaultProxyListen = "127.0.0.1:7210"
+)
+
+// cmdProxy runs a local forward proxy that adds vault values as headers to
+// requests for the hosts its rules name, so the process using it never
+// holds the secret.
+func cmdProxy() {
+	listen := defaultProxyListen
+	var rules []proxy.Rule
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--rule" && i+1 < len(args):
+			r, err := proxy.ParseRule(args[i+1])
+			if err != nil {
+				fatal("%v", err)
+			}
+			if err := vault.ValidateFieldID(r.Field); err != nil {
+				fatal("--rule %s: %v", args[i+1], err)
+			}
+			rules = append(rules, r)
+			i++
+		case args[i] == "--listen" && i+1 < len(args):
+			listen = args[i+1]
+			i++
+		default:
+			fatal(proxyUsage)
+		}
+	}
+	if len(rules) == 0 {
+		fatal(proxyUsage)
+	}
+	// Whoever reaches the proxy uses the secrets, so it stays on this machine.
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil || !isLoopback(host) {
+		fatal("--listen must be a loopback address, e.g. %s", defaultProxyListen)
+	}
+	if _, err := apiTransport(); err != nil {
+		fatal("%v", err)
+	}
+
+	p := &proxy.Proxy{
+		Rules:  rules,
+		Lookup: proxyLookup,
+		Logf:   log.New(os.Stderr, "pvault proxy: ", log.LstdFlags).Printf,
+	}
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		fatal("%v", err)
+	}
+	// Nor is it for other users of this machine.
+	ln = proc.OwnerListener(ln, os.Getuid())
+	for _, r := range rules {
+		fmt.Printf("  %-30s %s ← %s%s\n", r.Host, r.Header, r.Prefix, r.Field)
+	}
+	fmt.Printf("Proxy listening on http://%s\n", ln.Addr())
+	fmt.Printf("Run the process with HTTP_PROXY=http://%s and have it call http:// URLs;\nthe proxy connects to the host over HTTPS.\n", ln.Addr())
+	srv := &http.Server{Handler: p, ReadHeaderTimeout: 10 * time.Second}
+	fatal("%v", srv.Serve(ln))
+}
+
+// proxyLookup reads a field's value from the server for each request, with
+// the session or PVAULT_TOKEN, so every use is audited. It never prompts:
+// a critical field needs a service token, which reads without step-up.
+func proxyLookup(id string) (string, error) {
+	resp, err := sendAPIRequest("GET", "/vault/fields/"+id, nil)
+	if err != nil {
+		return "", err
+	}
+	var field vault.FieldInfo
+	if err := apiResult(resp, &field); err != nil {
+		return "", err
+	}
+	return field.Value, nil
+}
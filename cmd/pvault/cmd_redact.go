@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// cmdRedact prints a file (or stdin) with the vault values in it replaced
+// by placeholders, for scrubbing logs and transcripts.
+func cmdRedact() {
+	var in io.Reader = os.Stdin
+	switch args := os.Args[2:]; len(args) {
+	case 0:
+	case 1:
+		f, err := os.Open(args[0])
+		if err != nil {
+			fatal("%v", err)
+		}
+		defer f.Close()
+		in = f
+	default:
+		fatal("usage: pvault redact [file]")
+	}
+	text, err := io.ReadAll(in)
+	if err != nil {
+		fatal("%v", err)
+	}
+	resp, err := apiRequest("POST", "/vault/redact", map[string]string{"text": string(text)})
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		Text       string            `json:"text"`
+		Redactions []vault.Redaction `json:"redactions"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Print(result.Text)
+	if len(result.Redactions) == 0 {
+		fmt.Fprintln(os.Stderr, msg("redact.none"))
+		return
+	}
+	n := 0
+	for _, r := range result.Redactions {
+		n += r.Count
+	}
+	fmt.Fprintln(os.Stderr, msg("redact.summary", n, len(result.Redactions)))
+}
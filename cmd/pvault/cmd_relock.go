@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// promptOnLocked is set by --prompt-on-locked: a command that finds the
+// vault locked, or its session gone, asks for the password and carries on.
+var promptOnLocked bool
+
+// lockedHint is set once a command has been refused for want of a session,
+// so that fatal can say how to get one back.
+var lockedHint bool
+
+// relockTried keeps a command to one inline unlock.
+var relockTried bool
+
+// cmdRelock locks the vault but leaves the server running, so that
+// 'pvault unlock' (or --prompt-on-locked) opens it again without a restart.
+func cmdRelock() {
+	cliLang()
+	resp, err := apiRequest("POST", "/vault/lock", nil)
+	if err != nil {
+		fatal("cannot reach server: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+	clearSessionToken()
+	fmt.Println(msg("relock.done"))
+}
+
+// sessionLost reports whether resp refuses the CLI's session because the
+// vault locked under it, auto-lock included, or the session ended.
+func sessionLost(resp *http.Response) bool {
+	if remoteToken() != "" {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		_, reason := peekRefusal(resp)
+		return reason == "invalid_or_expired_token" || reason == "missing_authorization"
+	case http.StatusForbidden:
+		constraint, _ := peekRefusal(resp)
+		return constraint == "vault_locked"
+	}
+	return false
+}
+
+// unlockAgain answers a request the vault refused for want of a session.
+// With --prompt-on-locked at a terminal it asks for the password, opens a
+// new session through the running server, and sends the request again;
+// otherwise it hands resp back and leaves a hint for fatal.
+func unlockAgain(resp *http.Response, retry func() (*http.Response, error)) (*http.Response, error) {
+	if relockTried || !promptOnLocked || !term.IsTerminal(int(os.Stdin.Fd())) {
+		lockedHint = true
+		return resp, nil
+	}
+	relockTried = true
+	resp.Body.Close()
+
+	fmt.Fprintln(os.Stderr, msg("relock.prompt"))
+	pw, err := promptPassword(msg("prompt.password"))
+	if err != nil {
+		return nil, fmt.Errorf("reading password: %w", err)
+	}
+	sk, err := readSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := openSession(pw, sk); err != nil {
+		return nil, fmt.Errorf("unlock failed: %w", err)
+	}
+	return retry()
+}
+
+// openSession unlocks the vault through the running server and hands the
+// new session token to the session agent.
+func openSession(password, secretKey string) error {
+	body, _ := json.Marshal(map[string]string{
+		"password":   password,
+		"secret_key": secretKey,
+		"label":      "cli",
+	})
+	resp, err := sendAPIRequest("POST", "/vault/unlock", body)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		return err
+	}
+	if result.Token == "" {
+		return errors.New("unlock returned no token")
+	}
+	elevationToken, lockedHint = "", false
+	if err := writeSessionToken(result.Token); err != nil {
+		return fmt.Errorf("write session: %w", err)
+	}
+	return nil
+}
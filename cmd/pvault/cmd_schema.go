@@ -4,32 +4,68 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/lovincyrus/personal-vault/internal/config"
+	"github.com/lovincyrus/personal-vault/internal/i18n"
 	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
+const schemaUsage = "usage: pvault schema [--json] [--lang <code>] | packs | enable <pack> | disable <pack>"
+
 func cmdSchema() {
+	if len(os.Args) > 2 {
+		switch os.Args[2] {
+		case "packs":
+			cmdSchemaPacks()
+			return
+		case "enable", "disable":
+			if len(os.Args) != 4 {
+				fatal(schemaUsage)
+			}
+			cmdSchemaToggle(os.Args[3], os.Args[2] == "enable")
+			return
+		}
+	}
+
 	jsonFlag := false
-	for _, arg := range os.Args[2:] {
-		if arg == "--json" {
+	lang := ""
+	for i := 2; i < len(os.Args); i++ {
+		switch os.Args[i] {
+		case "--json":
 			jsonFlag = true
+		case "--lang":
+			if i+1 < len(os.Args) {
+				lang = i18n.Match(os.Args[i+1])
+				if lang == "" {
+					fatal("unsupported language %q (supported: %s)", os.Args[i+1], strings.Join(i18n.Supported, ", "))
+				}
+				i++
+			}
 		}
 	}
+	if lang == "" {
+		lang = cliLang()
+	}
+	schema := vault.LocalizedSchema(lang)
 
 	if jsonFlag {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		enc.Encode(vault.RecommendedSchema)
+		enc.Encode(schema)
 		return
 	}
 
-	fmt.Println("Recommended Vault Schema")
-	fmt.Println("========================")
+	title := i18n.T(lang, "schema.title")
+	fmt.Println(title)
+	fmt.Println(strings.Repeat("=", utf8.RuneCountInString(title)))
 	fmt.Println()
-	for _, cat := range vault.RecommendedSchema.Categories {
+	for _, cat := range schema.Categories {
 		fmt.Printf("%s — %s\n", cat.Name, cat.Description)
 		if len(cat.Fields) == 0 {
-			fmt.Println("  (user-defined fields)")
+			fmt.Println("  " + i18n.T(lang, "schema.user_defined"))
 		}
 		for _, f := range cat.Fields {
 			fmt.Printf("  %-35s %s", f.ID, f.Description)
@@ -41,3 +77,65 @@ func cmdSchema() {
 		fmt.Println()
 	}
 }
+
+// cmdSchemaPacks lists the optional schema packs and which are enabled.
+func cmdSchemaPacks() {
+	lang := cliLang()
+	enabled := schemaPacks(cliConfig())
+	fmt.Println(msg("schema.packs_title"))
+	for _, p := range vault.SchemaPacks {
+		desc := p.Description
+		if key := "schema.pack." + p.Name; i18n.Has(lang, key) {
+			desc = i18n.T(lang, key)
+		}
+		fmt.Printf("  %-12s %s", p.Name, desc)
+		if slices.Contains(enabled, p.Name) {
+			fmt.Printf(" [%s]", msg("schema.pack_enabled"))
+		}
+		fmt.Println()
+	}
+}
+
+// cmdSchemaToggle adds a pack to schema.packs or takes it off. Any name can
+// be taken off, so a mistyped one set by hand can be removed.
+func cmdSchemaToggle(name string, enable bool) {
+	if enable && vault.GetSchemaPack(name) == nil {
+		names := make([]string, len(vault.SchemaPacks))
+		for i, p := range vault.SchemaPacks {
+			names[i] = p.Name
+		}
+		fatal("%s", msg("schema.pack_unknown", name, strings.Join(names, ", ")))
+	}
+	cfg := cliConfig()
+	packs := slices.DeleteFunc(schemaPacks(cfg), func(p string) bool { return p == name })
+	if enable {
+		packs = append(packs, name)
+	}
+	if len(packs) == 0 {
+		cfg.Unset("schema.packs")
+	} else if err := cfg.Set("schema.packs", strings.Join(packs, ",")); err != nil {
+		fatal("%v", err)
+	}
+	saveConfig(cfg, "schema.packs")
+	fmt.Println(msg("schema.pack_restart"))
+}
+
+// schemaPacks returns the pack names in schema.packs.
+func schemaPacks(cfg *config.Config) []string {
+	var names []string
+	for _, name := range strings.Split(cfg.Value("schema.packs"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// enableSchemaPacks adds the packs in schema.packs to the recommended
+// schema. An unknown name is reported and no pack is enabled, leaving
+// 'pvault schema disable' to fix it.
+func enableSchemaPacks() {
+	if err := vault.EnableSchemaPacks(schemaPacks(cliConfig())); err != nil {
+		fmt.Fprintln(os.Stderr, msg("schema.packs_invalid", err))
+	}
+}
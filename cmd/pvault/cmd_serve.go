@@ -3,37 +3,105 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
-	"syscall"
+	"time"
 
 	"github.com/lovincyrus/personal-vault/internal/api"
+	"github.com/lovincyrus/personal-vault/internal/config"
+	"github.com/lovincyrus/personal-vault/internal/keystore"
+	"github.com/lovincyrus/personal-vault/internal/kms"
+	"github.com/lovincyrus/personal-vault/internal/proc"
 	"github.com/lovincyrus/personal-vault/internal/vault"
+	"golang.org/x/term"
 )
 
-var passwordFromStdin bool
+var passwordFromStdin, serveLocked, serveWatchdog, serveHeadless, serveReplica, serveApprove bool
+
+// servePort, passwordFile, secretKeyFile, and replicaSource are the --port,
+// --password-file, --secret-key-file, and --source flags, if given.
+var servePort, passwordFile, secretKeyFile, replicaSource string
 
 func init() {
-	for _, arg := range os.Args {
-		if arg == "--password-stdin" {
+	for i, arg := range os.Args {
+		switch arg {
+		case "--password-stdin":
 			passwordFromStdin = true
+		case "--locked":
+			serveLocked = true
+		case "--watchdog":
+			serveWatchdog = true
+		case "--headless":
+			serveHeadless = true
+		case "--replica":
+			serveReplica = true
+		case "--approve":
+			serveApprove = true
+		case "--port":
+			if i+1 < len(os.Args) {
+				servePort = os.Args[i+1]
+			}
+		case "--password-file":
+			if i+1 < len(os.Args) {
+				passwordFile = os.Args[i+1]
+			}
+		case "--secret-key-file":
+			if i+1 < len(os.Args) {
+				secretKeyFile = os.Args[i+1]
+			}
+		case "--source":
+			if i+1 < len(os.Args) {
+				replicaSource = os.Args[i+1]
+			}
 		}
 	}
 }
 
 func cmdServe() {
 	dir := vaultDir()
+	cfg := cliConfig()
 	v, err := vault.Open(dir)
 	if err != nil {
 		fatal("open vault: %v", err)
 	}
 	defer v.Close()
 
-	// Get credentials from stdin pipe (sent by unlock command) or prompt
+	autoLock, err := time.ParseDuration(cfg.Value("session.autolock"))
+	if err != nil || autoLock <= 0 {
+		fatal("session.autolock must be a positive duration such as 30m")
+	}
+	v.SetAutoLock(autoLock)
+
+	// Get credentials from stdin pipe (sent by unlock command), files or the
+	// environment (headless), or prompt. A locked server (e.g. a Windows
+	// service) waits for 'pvault unlock'.
 	var pw, sk string
-	if passwordFromStdin {
+	var unlockKey []byte
+	switch {
+	case serveLocked:
+	case serveHeadless:
+		if e, kerr := kms.Load(dir); kerr == nil && !headlessPasswordGiven(cfg) {
+			unlockKey, err = kmsUnlockKey(e)
+			// Like the password variables, the KMS token isn't for the
+			// programs the server runs.
+			os.Unsetenv("VAULT_TOKEN")
+		} else {
+			pw, sk, err = headlessCredentials(cfg)
+		}
+		if err != nil {
+			fatal("%v", err)
+		}
+	case passwordFromStdin:
 		scanner := bufio.NewScanner(os.Stdin)
 		if scanner.Scan() {
 			pw = strings.TrimSpace(scanner.Text())
@@ -44,7 +112,7 @@ func cmdServe() {
 		if pw == "" || sk == "" {
 			fatal("failed to read credentials from stdin")
 		}
-	} else {
+	default:
 		pw, err = promptPassword("Profile password: ")
 		if err != nil {
 			fatal("reading password: %v", err)
@@ -55,42 +123,532 @@ func cmdServe() {
 		}
 	}
 
-	token, err := v.Unlock(pw, sk)
-	if err != nil {
-		fatal("unlock: %v", err)
+	var token string
+	if !serveLocked {
+		label := "cli"
+		if serveHeadless {
+			label = "headless"
+		}
+		if unlockKey != nil {
+			token, err = v.UnlockWithKey(unlockKey, "kms")
+			clear(unlockKey)
+		} else {
+			token, err = v.UnlockSession(pw, sk, label)
+		}
+		if err != nil {
+			fatal("unlock: %v", err)
+		}
+		// The agent's session is for this user's CLI on this machine; a copy
+		// of it is refused anywhere else.
+		if err := v.BindSession(token, os.Getuid()); err != nil {
+			fatal("unlock: %v", err)
+		}
+	}
+	if serveHeadless {
+		// Nobody is around to unlock again, so idling must not lock the
+		// vault under the devices using it; 'pvault lock' still does.
+		if err := v.PinSession(token); err != nil {
+			fatal("unlock: %v", err)
+		}
 	}
 
 	// Note: Go strings are immutable; setting pw="" does not zero heap memory.
 	// Accept this limitation — use []byte for passwords if zeroing is critical.
 
-	addr := "127.0.0.1:7200"
-	if a := os.Getenv("VAULT_PORT"); a != "" {
-		addr = "127.0.0.1:" + a
+	host := cfg.Value("server.listen")
+	port, _ := cfg.Resolve("server.port", servePort)
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p // server.listen = "0.0.0.0:7200"
+	}
+	certFile, keyFile := cfg.Value("server.tls_cert"), cfg.Value("server.tls_key")
+	if (certFile == "") != (keyFile == "") {
+		fatal("set both server.tls_cert and server.tls_key (VAULT_TLS_CERT, VAULT_TLS_KEY)")
+	}
+	if certFile == "" && !isLoopback(host) {
+		fatal("listening on %s exposes the vault beyond this machine; set server.tls_cert and server.tls_key to serve HTTPS", host)
+	}
+
+	configureEnricher(v, cfg)
+	configureNotifier(v, cfg)
+	authorizer, authorizeTimeout := configureAuthorizer(v, cfg)
+	agent := newSessionAgent(cfg, token, authorizer, authorizeTimeout)
+	agent.valid = v.ValidateToken
+	agent.audit = v.LogAccess
+	configurePlugins(v, cfg)
+	if err := v.SetSynchronous(cfg.Value("storage.synchronous")); err != nil {
+		fatal("storage.synchronous: %v", err)
+	}
+	go releaseEmergencies(v)
+	go notifyExpiringTokens(v, tokenExpiryWindow(cfg))
+	if serveReplica {
+		go syncReplica(v, configureReplica(v, cfg))
+	} else {
+		go notifyExpiring(v, expiryWindow(cfg))
 	}
 
-	srv := api.New(v, addr)
-	ln, err := srv.Start()
+	srv := api.New(v, net.JoinHostPort(host, port))
+	if err := srv.SetLimits(serverLimits(cfg)); err != nil {
+		fatal("%v", err)
+	}
+	go cleanup(v, srv, auditRetention(cfg), compactThreshold(cfg))
+	if raw := cfg.Value("server.cors_origins"); raw != "" {
+		var origins []string
+		for _, o := range strings.Split(raw, ",") {
+			origins = append(origins, strings.TrimSpace(o))
+		}
+		if err := srv.SetCORSOrigins(origins); err != nil {
+			fatal("server.cors_origins: %v", err)
+		}
+	}
+	var ln net.Listener
+	if certFile != "" {
+		ln, err = srv.StartTLS(certFile, keyFile)
+	} else {
+		ln, err = srv.Start()
+	}
 	if err != nil {
 		fatal("start server: %v", err)
 	}
+	if sock := cfg.Value("server.socket"); sock != "" {
+		if _, err := srv.StartUnix(sock); err != nil {
+			fatal("listen on %s: %v", sock, err)
+		}
+		fmt.Fprintf(os.Stderr, "Vault socket at %s (workload-bound tokens)\n", sock)
+	}
 
-	// Write session token only after server binds successfully.
-	// Writing before srv.Start() causes token mismatch if the port is occupied
-	// by a stale process — the file gets a new token while the old server
-	// still holds the previous one.
-	if err := writeSessionToken(token); err != nil {
-		fatal("write session: %v", err)
+	// Start the session agent only after server binds successfully.
+	// Starting it before srv.Start() causes token mismatch if the port is
+	// occupied by a stale process — the agent hands out a new token while
+	// the old server still holds the previous one.
+	agentLn, err := agent.listen(agentPath())
+	if err != nil {
+		fatal("session agent: %v", err)
 	}
+	// A session file left by an older version holds a token in plain text.
+	os.Remove(sessionPath())
 	fmt.Fprintf(os.Stderr, "Vault server listening on %s\n", ln.Addr())
+	if src := v.ReplicaSource(); src != "" {
+		fmt.Fprintf(os.Stderr, "Read-only replica of %s\n", src)
+	}
+
+	shutdown := func() {
+		fmt.Fprintln(os.Stderr, "\nShutting down...")
+		v.Lock()
+		srv.Stop(context.Background())
+		agentLn.Close()
+		removePID()
+	}
+	if serveWatchdog {
+		go watchStorage(v, shutdown)
+	}
+	// A server started from a login (not a supervisor, container, or
+	// service) belongs to that login and must not outlive it.
+	if session, ok := proc.LoginSession(); ok && !serveLocked && !serveHeadless {
+		go watchLoginSession(session, shutdown)
+	}
+	runUntilShutdown(shutdown)
+}
+
+// loginCheckInterval is how often the server checks that its user is still
+// logged in.
+const loginCheckInterval = 5 * time.Second
+
+// watchLoginSession locks and stops the server once the user logs out of
+// the session that started it; on a shared machine the vault must not stay
+// unlocked for whoever uses it next.
+func watchLoginSession(session string, shutdown func()) {
+	for range time.Tick(loginCheckInterval) {
+		if !proc.LoginSessionOpen(session) {
+			fmt.Fprintf(os.Stderr, "login session %s ended; locking\n", session)
+			shutdown()
+			os.Exit(0)
+		}
+	}
+}
+
+// watchdogInterval is how often --watchdog checks the database.
+const watchdogInterval = 15 * time.Second
+
+// watchStorage exits non-zero once the database stops accepting writes, so
+// a supervisor (systemd, a container runtime) restarts or alerts instead of
+// the server carrying on unable to save anything.
+func watchStorage(v *vault.Vault, shutdown func()) {
+	for range time.Tick(watchdogInterval) {
+		if err := v.CheckStorage(); err != nil {
+			fmt.Fprintf(os.Stderr, "watchdog: database is not writable: %v\n", err)
+			shutdown()
+			os.Exit(1)
+		}
+	}
+}
+
+// headlessCredentials reads the password and secret key for an unattended
+// start, e.g. in a container: VAULT_PASSWORD, else the password file
+// (VAULT_PASSWORD_FILE, --password-file, server.password_file); likewise
+// VAULT_SECRET_KEY or the secret key file, defaulting to the vault's own
+// key if it can be read without a prompt. The variables are cleared once read so that programs the
+// server runs, such as notify.cmd, don't inherit them.
+func headlessCredentials(cfg *config.Config) (pw, sk string, err error) {
+	pw = os.Getenv("VAULT_PASSWORD")
+	os.Unsetenv("VAULT_PASSWORD")
+	if pw == "" {
+		path, _ := cfg.Resolve("server.password_file", passwordFile)
+		if path == "" {
+			return "", "", fmt.Errorf("headless mode needs the password: set VAULT_PASSWORD or VAULT_PASSWORD_FILE, pass --password-file, or enroll a KMS with 'pvault kms enroll'")
+		}
+		if pw, err = readCredentialFile(path); err != nil {
+			return "", "", fmt.Errorf("read password: %w", err)
+		}
+	}
+
+	sk = os.Getenv("VAULT_SECRET_KEY")
+	os.Unsetenv("VAULT_SECRET_KEY")
+	if sk == "" {
+		if path, _ := cfg.Resolve("server.secret_key_file", secretKeyFile); path != "" {
+			if sk, err = readCredentialFile(path); err != nil {
+				return "", "", fmt.Errorf("read secret key: %w", err)
+			}
+		} else if sk, err = keystore.Load(vaultDir(), nil); errors.Is(err, keystore.ErrNeedInput) {
+			return "", "", fmt.Errorf("the secret key is PIN-protected or entered by hand, so headless mode needs it in VAULT_SECRET_KEY or VAULT_SECRET_KEY_FILE")
+		} else if err != nil {
+			return "", "", fmt.Errorf("read secret key: %w", err)
+		}
+	}
+	return pw, sk, nil
+}
+
+// headlessPasswordGiven reports whether a password was supplied for
+// headless mode, which takes precedence over an enrolled KMS.
+func headlessPasswordGiven(cfg *config.Config) bool {
+	path, _ := cfg.Resolve("server.password_file", passwordFile)
+	return os.Getenv("VAULT_PASSWORD") != "" || path != ""
+}
 
-	// Wait for signal
+// readCredentialFile reads a one-line secret, such as a Docker secret,
+// without its trailing newline.
+func readCredentialFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimRight(string(data), "\r\n")
+	if s == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return s, nil
+}
+
+// waitForSignal blocks until the process is asked to stop.
+func waitForSignal() {
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sig, proc.ShutdownSignals...)
 	<-sig
+}
+
+// configureEnricher sets up address enrichment from enrich.url (an HTTP
+// endpoint) or enrich.cmd (a local program). The enricher is named after its
+// host or program in suggestions and the audit log.
+func configureEnricher(v *vault.Vault, cfg *config.Config) {
+	if raw := cfg.Value("enrich.url"); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fatal("enrich.url must be an http(s) URL")
+		}
+		v.SetEnricher(&vault.HTTPEnricher{URL: raw}, "enricher:"+u.Hostname())
+		return
+	}
+	if raw := cfg.Value("enrich.cmd"); raw != "" {
+		args := strings.Fields(raw)
+		v.SetEnricher(&vault.CommandEnricher{Path: args[0], Args: args[1:]}, "enricher:"+filepath.Base(args[0]))
+	}
+}
+
+// configureNotifier sends events the owner must hear about, like emergency
+// access requests, to notify.url (an HTTP endpoint) or notify.cmd (a local
+// program), limited to the types in notify.events if set.
+func configureNotifier(v *vault.Vault, cfg *config.Config) {
+	var n vault.Notifier
+	if raw := cfg.Value("notify.url"); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fatal("notify.url must be an http(s) URL")
+		}
+		n = &vault.HTTPNotifier{URL: raw}
+	} else if raw := cfg.Value("notify.cmd"); raw != "" {
+		args := strings.Fields(raw)
+		n = &vault.CommandNotifier{Path: args[0], Args: args[1:]}
+	}
+	if n == nil {
+		return
+	}
+	if raw := cfg.Value("notify.events"); raw != "" {
+		events := make(map[string]bool)
+		for _, e := range strings.Split(raw, ",") {
+			e = strings.TrimSpace(e)
+			if !slices.Contains(vault.EventTypes, e) {
+				fatal("notify.events: unknown event %q (known: %s)", e, strings.Join(vault.EventTypes, ", "))
+			}
+			events[e] = true
+		}
+		n = eventFilter{Notifier: n, events: events}
+	}
+	v.SetNotifier(n)
+}
+
+// configureAuthorizer has authorize.url (an HTTP endpoint) or authorize.cmd
+// (a local program) approve each service-token read of fields at
+// authorize.min_sensitivity or above, refusing it after authorize.timeout.
+// With --approve, the owner answers at this terminal instead, and is also
+// asked about each consumer's first read. It returns the authorizer, if
+// any, for the session agent to ask too.
+func configureAuthorizer(v *vault.Vault, cfg *config.Config) (vault.Authorizer, time.Duration) {
+	a, timeout := authorizerFromConfig(cfg)
+	if a == nil {
+		return nil, 0
+	}
+	if serveApprove {
+		v.SetAskNewConsumers(true)
+	}
+	if err := v.SetAuthorizer(a, cfg.Value("authorize.min_sensitivity"), timeout); err != nil {
+		fatal("authorize.min_sensitivity must be public, standard, sensitive, or critical")
+	}
+	if serveApprove {
+		fmt.Fprintln(os.Stderr, msg("approve.ready", cfg.Value("authorize.min_sensitivity")))
+	}
+	return a, timeout
+}
+
+// authorizerFromConfig returns the authorizer --approve, authorize.url, or
+// authorize.cmd configures, and how long to wait for it, or nil if none is.
+func authorizerFromConfig(cfg *config.Config) (vault.Authorizer, time.Duration) {
+	var a vault.Authorizer
+	if serveApprove {
+		if cfg.Value("authorize.url") != "" || cfg.Value("authorize.cmd") != "" {
+			fatal("--approve asks at this terminal; unset authorize.url and authorize.cmd to use it")
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			fatal("--approve needs a terminal to ask in")
+		}
+		a = newTerminalAuthorizer(os.Stdin, os.Stderr)
+	} else if raw := cfg.Value("authorize.url"); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fatal("authorize.url must be an http(s) URL")
+		}
+		a = &vault.HTTPAuthorizer{URL: raw}
+	} else if raw := cfg.Value("authorize.cmd"); raw != "" {
+		args := strings.Fields(raw)
+		a = &vault.CommandAuthorizer{Path: args[0], Args: args[1:]}
+	}
+	if a == nil {
+		return nil, 0
+	}
+	timeout, err := time.ParseDuration(cfg.Value("authorize.timeout"))
+	if err != nil || timeout <= 0 {
+		fatal("authorize.timeout must be a positive duration such as 60s")
+	}
+	return a, timeout
+}
+
+// eventFilter passes on only the event types the owner asked for.
+type eventFilter struct {
+	vault.Notifier
+	events map[string]bool
+}
+
+func (f eventFilter) Notify(ctx context.Context, e vault.Event) error {
+	if !f.events[e.Type] {
+		return nil
+	}
+	return f.Notifier.Notify(ctx, e)
+}
+
+// releaseEmergencies releases emergency requests as their waiting periods
+// end, so the owner is notified then rather than when the contact next asks.
+func releaseEmergencies(v *vault.Vault) {
+	for range time.Tick(time.Minute) {
+		if _, err := v.ReleaseDueEmergencies(time.Now()); err != nil && err != vault.ErrLocked {
+			fmt.Fprintf(os.Stderr, "release emergency requests: %v\n", err)
+		}
+	}
+}
+
+// serverLimits reads the server's concurrency limits and request timeout.
+// The routes with timeouts of their own keep them.
+func serverLimits(cfg *config.Config) api.Limits {
+	l := api.Limits{RouteTimeouts: api.DefaultRouteTimeouts}
+	var err error
+	if l.MaxConcurrent, err = strconv.Atoi(cfg.Value("server.max_concurrent")); err != nil || l.MaxConcurrent < 0 {
+		fatal("server.max_concurrent must be a whole number, 0 or more")
+	}
+	if l.MaxQueue, err = strconv.Atoi(cfg.Value("server.max_queue")); err != nil || l.MaxQueue < 0 {
+		fatal("server.max_queue must be a whole number, 0 or more")
+	}
+	if l.RequestTimeout, err = time.ParseDuration(cfg.Value("server.request_timeout")); err != nil || l.RequestTimeout <= 0 {
+		fatal("server.request_timeout must be a positive duration such as 30s")
+	}
+	return l
+}
+
+// expiryCheckInterval is how often the server looks for expiry dates
+// coming up.
+const expiryCheckInterval = time.Hour
+
+// expiryWindow is notify.expiry_window, how far ahead expiry dates are
+// announced.
+func expiryWindow(cfg *config.Config) time.Duration {
+	window, err := time.ParseDuration(cfg.Value("notify.expiry_window"))
+	if err != nil || window <= 0 {
+		fatal("notify.expiry_window must be a positive duration such as 2160h")
+	}
+	return window
+}
+
+// notifyExpiring sends field_expiring events as passports, visas, and other
+// expiry fields come within the window. A replica leaves it to its primary,
+// so the owner hears once.
+func notifyExpiring(v *vault.Vault, window time.Duration) {
+	for {
+		if err := v.NotifyExpiring(time.Now(), window); err != nil && err != vault.ErrLocked {
+			fmt.Fprintf(os.Stderr, "check expiring fields: %v\n", err)
+		}
+		time.Sleep(expiryCheckInterval)
+	}
+}
+
+// tokenExpiryWindow is notify.token_expiry_window, how far ahead service
+// token expiry is announced.
+func tokenExpiryWindow(cfg *config.Config) time.Duration {
+	window, err := time.ParseDuration(cfg.Value("notify.token_expiry_window"))
+	if err != nil || window <= 0 {
+		fatal("notify.token_expiry_window must be a positive duration such as 720h")
+	}
+	return window
+}
+
+// notifyExpiringTokens sends token_expiring events as service tokens come
+// within the window. Replicas run it too, since their tokens are their own.
+func notifyExpiringTokens(v *vault.Vault, window time.Duration) {
+	for {
+		if err := v.NotifyExpiringTokens(time.Now(), window); err != nil && err != vault.ErrLocked {
+			fmt.Fprintf(os.Stderr, "check expiring service tokens: %v\n", err)
+		}
+		time.Sleep(expiryCheckInterval)
+	}
+}
+
+// cleanupInterval is how often the server deletes expired tokens, prunes
+// the audit log, and checkpoints the database.
+const cleanupInterval = time.Hour
+
+// auditRetention is audit.retention, how long audit entries are kept, or 0
+// to keep them forever.
+func auditRetention(cfg *config.Config) time.Duration {
+	raw := cfg.Value("audit.retention")
+	if raw == "" {
+		return 0
+	}
+	retention, err := time.ParseDuration(raw)
+	if err != nil || retention <= 0 {
+		fatal("audit.retention must be a positive duration such as 8760h")
+	}
+	return retention
+}
+
+// compactThreshold is storage.compact_threshold as a fraction of the
+// database file, or 0 to never compact automatically.
+func compactThreshold(cfg *config.Config) float64 {
+	pct, err := strconv.Atoi(cfg.Value("storage.compact_threshold"))
+	if err != nil || pct < 0 || pct > 100 {
+		fatal("storage.compact_threshold must be a percentage from 0 to 100")
+	}
+	return float64(pct) / 100
+}
 
-	fmt.Fprintln(os.Stderr, "\nShutting down...")
-	v.Lock()
-	srv.Stop(context.Background())
-	removeSessionToken()
-	removePID()
+// cleanup deletes expired tokens and audit entries past their retention,
+// checkpoints the database, forgets browser logins of ended sessions, and
+// compacts the database once free space passes the threshold, so none of
+// them accumulate over a long-running server's life.
+func cleanup(v *vault.Vault, srv *api.Server, retention time.Duration, threshold float64) {
+	for {
+		res, err := v.Cleanup(time.Now(), retention)
+		if err != nil && err != vault.ErrLocked {
+			fmt.Fprintf(os.Stderr, "cleanup: %v\n", err)
+		}
+		tickets := srv.PruneUITickets()
+		if err == nil {
+			slog.Info("cleanup", "expired_tokens", res.ExpiredTokens, "audit_entries", res.AuditEntries, "ui_tickets", tickets)
+		}
+		if threshold > 0 {
+			if c, err := v.Compact(threshold); err != nil && err != vault.ErrLocked {
+				fmt.Fprintf(os.Stderr, "compact: %v\n", err)
+			} else if c.Compacted {
+				slog.Info("compact", "before_bytes", c.Before, "after_bytes", c.After)
+			}
+		}
+		time.Sleep(cleanupInterval)
+	}
+}
+
+// configureReplica makes the vault a read replica of replica.source (or
+// --source), pulling with the service token in VAULT_REPLICA_TOKEN or
+// replica.token_file, and returns how often to pull.
+func configureReplica(v *vault.Vault, cfg *config.Config) time.Duration {
+	source, _ := cfg.Resolve("replica.source", replicaSource)
+	u, err := url.Parse(source)
+	if source == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fatal("--replica needs the primary's URL: pass --source https://host:7200 or set replica.source")
+	}
+	if u.Scheme != "https" && !isLoopback(u.Hostname()) {
+		fatal("refusing to send the replica token to %s over plain HTTP; use an https:// source", u.Host)
+	}
+	token := os.Getenv("VAULT_REPLICA_TOKEN")
+	os.Unsetenv("VAULT_REPLICA_TOKEN")
+	if token == "" {
+		path := cfg.Value("replica.token_file")
+		if path == "" {
+			fatal("--replica needs a service token from the primary: set VAULT_REPLICA_TOKEN or replica.token_file")
+		}
+		if token, err = readCredentialFile(path); err != nil {
+			fatal("read replica token: %v", err)
+		}
+	}
+	interval, err := time.ParseDuration(cfg.Value("replica.interval"))
+	if err != nil || interval <= 0 {
+		fatal("replica.interval must be a positive duration such as 1m")
+	}
+	t, err := apiTransport()
+	if err != nil {
+		fatal("%v", err)
+	}
+	v.SetReplica(&vault.Primary{
+		URL:    strings.TrimRight(source, "/"),
+		Token:  strings.TrimSpace(token),
+		Client: &http.Client{Transport: t, Timeout: time.Minute},
+	})
+	return interval
+}
+
+// syncReplica pulls from the primary now and then every interval. Pulls
+// pause while the vault is locked; a primary that is offline is reported
+// once, and the replica keeps serving what it last pulled.
+func syncReplica(v *vault.Vault, interval time.Duration) {
+	var failing string
+	for {
+		n, err := v.SyncReplica(context.Background())
+		switch {
+		case err == vault.ErrLocked:
+		case err != nil:
+			if err.Error() != failing {
+				fmt.Fprintf(os.Stderr, "replica sync: %v\n", err)
+			}
+			failing = err.Error()
+		default:
+			if failing != "" || n > 0 {
+				fmt.Fprintf(os.Stderr, "replica sync: %d fields updated\n", n)
+			}
+			failing = ""
+		}
+		time.Sleep(interval)
+	}
 }
@@ -3,17 +3,30 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/proc"
 )
 
 func cmdCreateServiceToken() {
 	if len(os.Args) < 3 {
-		fatal("usage: pvault create-service-token <consumer> [--scope categories] [--ttl duration]")
+		fatal("usage: pvault create-service-token <consumer> [--scope categories] [--ttl duration] [--hours HH:MM-HH:MM] [--weekdays mon,tue,...] [--max-per-day n] [--critical-per-day n] [--timezone zone] [--bind-exe path] [--bind-container id] [--bind-uid n] [--list-out-of-scope] [--description text] [--label key=value]... [--review] [--discovery]")
 	}
 
 	consumer := os.Args[2]
 	scope := "*"
-	ttl := "8760h" // 1 year
+	var ttl string
+	constraints := map[string]any{}
+	workload := map[string]any{}
+	review := false
+	usage := "service"
+	var description string
+	labels := map[string]string{}
 
 	for i := 3; i < len(os.Args); i++ {
 		switch os.Args[i] {
@@ -27,13 +40,108 @@ func cmdCreateServiceToken() {
 				ttl = os.Args[i+1]
 				i++
 			}
+		case "--hours":
+			if i+1 < len(os.Args) {
+				constraints["hours"] = os.Args[i+1]
+				i++
+			}
+		case "--weekdays":
+			if i+1 < len(os.Args) {
+				constraints["weekdays"] = strings.Split(os.Args[i+1], ",")
+				i++
+			}
+		case "--max-per-day":
+			if i+1 < len(os.Args) {
+				n, err := strconv.Atoi(os.Args[i+1])
+				if err != nil || n < 1 {
+					fatal("--max-per-day must be a positive integer")
+				}
+				constraints["max_per_day"] = n
+				i++
+			}
+		case "--critical-per-day":
+			if i+1 < len(os.Args) {
+				n, err := strconv.Atoi(os.Args[i+1])
+				if err != nil || n < 1 {
+					fatal("--critical-per-day must be a positive integer")
+				}
+				constraints["critical_per_day"] = n
+				i++
+			}
+		case "--timezone":
+			if i+1 < len(os.Args) {
+				constraints["timezone"] = os.Args[i+1]
+				i++
+			}
+		case "--bind-exe":
+			if i+1 < len(os.Args) {
+				sum, err := proc.FileSHA256(os.Args[i+1])
+				if err != nil {
+					fatal("--bind-exe: %v", err)
+				}
+				workload["exe_sha256"] = sum
+				i++
+			}
+		case "--bind-container":
+			if i+1 < len(os.Args) {
+				workload["container_id"] = os.Args[i+1]
+				i++
+			}
+		case "--bind-uid":
+			if i+1 < len(os.Args) {
+				n, err := strconv.Atoi(os.Args[i+1])
+				if err != nil || n < 0 {
+					fatal("--bind-uid must be a non-negative integer")
+				}
+				workload["uid"] = n
+				i++
+			}
+		case "--description":
+			if i+1 < len(os.Args) {
+				description = os.Args[i+1]
+				i++
+			}
+		case "--label":
+			if i+1 < len(os.Args) {
+				k, v, ok := strings.Cut(os.Args[i+1], "=")
+				if !ok {
+					fatal("--label must be key=value")
+				}
+				labels[k] = v
+				i++
+			}
+		case "--list-out-of-scope":
+			constraints["list_out_of_scope"] = true
+		case "--review":
+			review = true
+		case "--discovery":
+			usage = "discovery"
 		}
 	}
 
-	resp, err := apiRequest("POST", "/vault/tokens/service", map[string]string{
-		"consumer": consumer,
-		"scope":    scope,
-		"ttl":      ttl,
+	// VAULT_TOKEN_TTL > --ttl > tokens.default_ttl > 1 year
+	ttl, _ = cliConfig().Resolve("tokens.default_ttl", ttl)
+
+	if len(workload) > 0 {
+		constraints["workload"] = workload
+	}
+
+	if review && usage == "discovery" {
+		fatal("--review is for tokens that read values; a discovery token only lists field names")
+	}
+	if review {
+		reviewServiceToken(consumer, scope, ttl, constraints, description, labels)
+		return
+	}
+
+	resp, err := apiRequest("POST", "/vault/tokens/service", map[string]any{
+		"consumer":    consumer,
+		"scope":       scope,
+		"ttl":         ttl,
+		"constraints": constraints,
+		"usage":       usage,
+		"description": description,
+		"labels":      labels,
 	})
 	if err != nil {
 		fatal("request failed: %v", err)
@@ -47,32 +155,130 @@ func cmdCreateServiceToken() {
 		fatal("%v", err)
 	}
 
-	fmt.Printf("Service token created for %q\n", consumer)
+	if usage == "discovery" {
+		fmt.Printf("Discovery token created for %q (lists field names and tiers, no values)\n", consumer)
+	} else {
+		fmt.Printf("Service token created for %q\n", consumer)
+	}
 	fmt.Printf("Token:   %s\n", result.Token)
 	fmt.Printf("Scope:   %s\n", scope)
 	fmt.Printf("Expires: %s\n", result.ExpiresAt)
+	if description != "" {
+		fmt.Printf("About:   %s\n", description)
+	}
+	if len(labels) > 0 {
+		fmt.Printf("Labels:  %s\n", formatLabels(labels))
+	}
+	if h, ok := constraints["hours"]; ok {
+		fmt.Printf("Hours:   %s\n", h)
+	}
+	if d, ok := constraints["weekdays"]; ok {
+		fmt.Printf("Days:    %s\n", strings.Join(d.([]string), ","))
+	}
+	if n, ok := constraints["max_per_day"]; ok {
+		fmt.Printf("Limit:   %d requests/day\n", n)
+	}
+	if n, ok := constraints["critical_per_day"]; ok {
+		fmt.Printf("Budget:  %d reads/day of each critical field\n", n)
+	}
+	if _, ok := constraints["list_out_of_scope"]; ok {
+		fmt.Println("Lists:   IDs and tiers of fields outside its scope (GET /vault/fields?include_out_of_scope=metadata)")
+	}
+	if len(workload) > 0 {
+		fmt.Println("Bound:   only usable over the vault socket (VAULT_SOCKET) by the matching process")
+	}
 	fmt.Println("\nSave this token — it cannot be displayed again.")
 }
 
+// reviewServiceToken opens the consent page, which shows every field the
+// scope covers and creates the token only after explicit confirmation.
+func reviewServiceToken(consumer, scope, ttl string, constraints map[string]any, description string, labels map[string]string) {
+	params := url.Values{}
+	params.Set("consumer", consumer)
+	params.Set("scope", scope)
+	params.Set("ttl", ttl)
+	if description != "" {
+		params.Set("description", description)
+	}
+	if len(labels) > 0 {
+		b, _ := json.Marshal(labels)
+		params.Set("labels", string(b))
+	}
+	for k, v := range constraints {
+		switch v := v.(type) {
+		case []string:
+			params.Set(k, strings.Join(v, ","))
+		case map[string]any:
+			b, _ := json.Marshal(v)
+			params.Set(k, string(b))
+		default:
+			params.Set(k, fmt.Sprint(v))
+		}
+	}
+	openBrowser(uiLoginURL("consent", params.Encode()), "Opened the consent page in your browser. The token is shown there once you confirm.")
+}
+
+// formatLabels renders labels as key=value pairs sorted by key.
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
 func cmdListServiceTokens() {
-	resp, err := apiRequest("GET", "/vault/tokens/service", nil)
+	query := url.Values{}
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--label" && i+1 < len(args):
+			query.Add("label", args[i+1])
+			i++
+		case args[i] == "--expiring" && i+1 < len(args):
+			days, err := strconv.Atoi(strings.TrimSuffix(args[i+1], "d"))
+			if err != nil || days < 1 {
+				fatal("--expiring takes a number of days, such as 30d")
+			}
+			query.Set("expiring_days", strconv.Itoa(days))
+			i++
+		default:
+			fatal("usage: pvault list-service-tokens [--label key=value]... [--expiring <days>d]")
+		}
+	}
+	path := "/vault/tokens/service"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	resp, err := apiRequest("GET", path, nil)
 	if err != nil {
 		fatal("request failed: %v", err)
 	}
 
 	var tokens []struct {
-		TokenPrefix string `json:"token_prefix"`
-		Consumer    string `json:"consumer"`
-		Scope       string `json:"scope"`
-		ExpiresAt   string `json:"expires_at"`
-		CreatedAt   string `json:"created_at"`
+		TokenPrefix string            `json:"token_prefix"`
+		Consumer    string            `json:"consumer"`
+		Scope       string            `json:"scope"`
+		Usage       string            `json:"usage"`
+		ExpiresAt   string            `json:"expires_at"`
+		CreatedAt   string            `json:"created_at"`
+		Constraints any               `json:"constraints,omitempty"`
+		Parent      string            `json:"parent,omitempty"`
+		Description string            `json:"description,omitempty"`
+		Labels      map[string]string `json:"labels,omitempty"`
 	}
 	if err := apiResult(resp, &tokens); err != nil {
 		fatal("%v", err)
 	}
 
 	if len(tokens) == 0 {
-		fmt.Println("No service tokens.")
+		if query.Has("expiring_days") {
+			fmt.Printf("No service tokens expire within %s days.\n", query.Get("expiring_days"))
+		} else if len(query) > 0 {
+			fmt.Println("No service tokens with those labels.")
+		} else {
+			fmt.Println("No service tokens.")
+		}
 		return
 	}
 
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const sessionsUsage = "usage: pvault sessions [list | revoke <id>]"
+
+func cmdSessions() {
+	args := os.Args[2:]
+	switch {
+	case len(args) == 0 || (len(args) == 1 && args[0] == "list"):
+		listSessions()
+	case len(args) == 2 && args[0] == "revoke":
+		resp, err := apiRequest("DELETE", "/vault/sessions/"+args[1], nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("sessions.revoked", args[1]))
+	default:
+		fatal(sessionsUsage)
+	}
+}
+
+func listSessions() {
+	resp, err := apiRequest("GET", "/vault/sessions", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var sessions []struct {
+		ID               string    `json:"id"`
+		Label            string    `json:"label"`
+		CreatedAt        time.Time `json:"created_at"`
+		ExpiresInSeconds int       `json:"expires_in_seconds"`
+		Pinned           bool      `json:"pinned"`
+		Current          bool      `json:"current"`
+	}
+	if err := apiResult(resp, &sessions); err != nil {
+		fatal("%v", err)
+	}
+	for _, s := range sessions {
+		mark := " "
+		if s.Current {
+			mark = "*"
+		}
+		label := s.Label
+		if label == "" {
+			label = "-"
+		}
+		created := s.CreatedAt.Local().Format(time.DateTime)
+		row := msg("sessions.row_pinned", created)
+		if !s.Pinned {
+			locksIn := (time.Duration(s.ExpiresInSeconds) * time.Second).String()
+			row = msg("sessions.row", created, locksIn)
+		}
+		fmt.Printf("%s %-10s %-12s %s\n", mark, s.ID, label, row)
+	}
+}
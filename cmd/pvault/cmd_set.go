@@ -2,41 +2,175 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+	"golang.org/x/term"
 )
 
+const setUsage = "usage: pvault set [--raw] [--type <type>] <id> <value | --stdin | --from-file <path> | --prompt>\n  example: pvault set identity.full_name \"Cool Cucumber\"\n           pvault set financial.ssn --prompt"
+
+// maxInputValue caps a value read with --stdin or --from-file, a little
+// under the server's request body limit.
+const maxInputValue = 1<<20 - 4<<10
+
 func cmdSet() {
-	if len(os.Args) < 4 {
-		fatal("usage: pvault set <id> <value>\n  example: pvault set identity.full_name \"Cool Cucumber\"")
+	raw, fromStdin, prompt := false, false, false
+	var fromFile, valueType string
+	var args []string
+	rest := os.Args[2:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--raw":
+			raw = true
+		case "--stdin":
+			fromStdin = true
+		case "--prompt":
+			prompt = true
+		case "--from-file", "--type":
+			if i+1 >= len(rest) {
+				fatal("%s", setUsage)
+			}
+			i++
+			if rest[i-1] == "--type" {
+				valueType = rest[i]
+			} else {
+				fromFile = rest[i]
+			}
+		default:
+			args = append(args, rest[i])
+		}
+	}
+	sources := 0
+	for _, on := range []bool{fromStdin, prompt, fromFile != ""} {
+		if on {
+			sources++
+		}
 	}
-	id := os.Args[2]
-	value := strings.Join(os.Args[3:], " ")
+	// A value read from stdin, a file, or a hidden prompt stays out of shell
+	// history and process listings, and isn't echoed back once normalized.
+	hidden := sources > 0
+	switch {
+	case sources > 1:
+		fatal("use one of --stdin, --from-file, and --prompt")
+	case hidden && len(args) != 1, !hidden && len(args) < 2:
+		fatal("%s", setUsage)
+	}
+	id := args[0]
 
 	if !strings.Contains(id, ".") {
 		fatal("field ID must be category.name (e.g., identity.full_name)")
 	}
 
-	resp, err := apiRequest("PUT", "/vault/fields/"+id, map[string]string{
-		"value": value,
-	})
-	if err != nil {
-		fatal("request failed: %v", err)
+	var value string
+	switch {
+	case fromStdin:
+		value = readValue(os.Stdin, "stdin")
+	case fromFile != "":
+		f, err := os.Open(fromFile)
+		if err != nil {
+			fatal("%v", err)
+		}
+		value = readValue(f, fromFile)
+		f.Close()
+	case prompt:
+		value = promptValue(id)
+	default:
+		value = strings.Join(args[1:], " ")
+		if vault.DefaultSensitivity(id) == "critical" && term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Fprintln(os.Stderr, msg("set.history_hint", id))
+		}
+	}
+
+	// Check the type before writing, so a value isn't stored without it.
+	if valueType != "" && !slices.Contains(vault.ValueTypes, valueType) {
+		fatal("%v", vault.ErrInvalidValueType)
+	}
+	if !vault.IsValueOfType(valueType, value) {
+		fatal("%v", vault.ErrValueTypeMismatch)
 	}
 
 	var result struct {
-		Status     string `json:"status"`
-		Suggestion *struct {
-			Canonical   string `json:"canonical"`
-			Description string `json:"description"`
-		} `json:"suggestion,omitempty"`
+		Status     string            `json:"status"`
+		Normalized string            `json:"normalized,omitempty"`
+		Suggestion *vault.Suggestion `json:"suggestion,omitempty"`
+	}
+	if offline {
+		v := openOffline(true)
+		stored, err := v.SetWithOptions(id, value, vault.SetOptions{Sensitivity: vault.DefaultSensitivity(id), Raw: raw, Type: valueType})
+		closeOffline(v)
+		if err != nil {
+			fatal("%v", err)
+		}
+		if stored != value {
+			result.Normalized = stored
+		}
+		result.Suggestion = vault.SuggestCanonical(id)
+	} else {
+		resp, err := apiRequest("PUT", "/vault/fields/"+id, map[string]any{
+			"value": value,
+			"raw":   raw,
+			"type":  valueType,
+		})
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, &result); err != nil {
+			fatal("%v", err)
+		}
 	}
-	if err := apiResult(resp, &result); err != nil {
-		fatal("%v", err)
+	fmt.Println(msg("field.set", id))
+	if result.Normalized != "" && hidden {
+		fmt.Println(msg("set.normalized"))
+	} else if result.Normalized != "" {
+		fmt.Println(msg("field.normalized", result.Normalized))
 	}
-	fmt.Printf("Set %s\n", id)
 	if result.Suggestion != nil {
 		fmt.Fprintf(os.Stderr, "Hint: the recommended field is %s (%s)\n",
 			result.Suggestion.Canonical, result.Suggestion.Description)
 	}
 }
+
+// readValue reads a value whole, newlines and all, so a PEM block or a list
+// of recovery codes is stored as written; only the newline ending the input
+// is dropped.
+func readValue(r io.Reader, name string) string {
+	data, err := io.ReadAll(io.LimitReader(r, maxInputValue+1))
+	if err != nil {
+		fatal("reading %s: %v", name, err)
+	}
+	if len(data) > maxInputValue {
+		fatal("the value in %s is over %d bytes", name, maxInputValue)
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if value == "" {
+		fatal("%s", msg("set.empty"))
+	}
+	return value
+}
+
+// promptValue reads a value without echo, twice, so a typo nobody can see
+// isn't stored.
+func promptValue(id string) string {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fatal("--prompt needs a terminal; pipe the value in with --stdin instead")
+	}
+	value, err := promptPassword(msg("set.prompt", id))
+	if err != nil {
+		fatal("reading %s: %v", id, err)
+	}
+	if value == "" {
+		fatal("%s", msg("set.empty"))
+	}
+	again, err := promptPassword(msg("set.prompt_again"))
+	if err != nil {
+		fatal("reading %s: %v", id, err)
+	}
+	if again != value {
+		fatal("%s", msg("set.mismatch"))
+	}
+	return value
+}
@@ -2,14 +2,19 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/lovincyrus/personal-vault/internal/keystore"
 	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
 func cmdStatus() {
 	resp, err := apiRequest("GET", "/vault/status", nil)
 	if err != nil {
-		fmt.Println("Vault is locked (server not running).")
+		fmt.Println(msg("status.not_running"))
+		printKeyStorageMode()
 		return
 	}
 
@@ -19,20 +24,94 @@ func cmdStatus() {
 	}
 
 	if !status.Initialized {
-		fmt.Println("Vault is not initialized. Run 'pvault init' first.")
+		fmt.Println(msg("status.not_initialized"))
 		return
 	}
 
 	if status.Locked {
-		fmt.Println("Status:  locked")
+		fmt.Println(msg("status.locked"))
 	} else {
-		fmt.Println("Status:  unlocked")
+		fmt.Println(msg("status.unlocked"))
 	}
-	fmt.Printf("Fields:  %d\n", status.FieldCount)
+	fmt.Println(msg("status.fields", status.FieldCount))
+	if !status.Locked {
+		printReplicaStatus()
+		printExpiringTokens()
+	}
+	printKeyStorageMode()
 	if len(status.Categories) > 0 {
-		fmt.Println("Categories:")
+		fmt.Println(msg("status.categories"))
 		for cat, count := range status.Categories {
-			fmt.Printf("  %-20s %d fields\n", cat, count)
+			fmt.Printf("  %-20s %s\n", cat, msg("status.category_fields", count))
+		}
+	}
+}
+
+// printReplicaStatus reports where a read replica pulls from and whether
+// that's working. Anything else, including a service token that may not
+// ask, prints nothing.
+func printReplicaStatus() {
+	resp, err := apiRequest("GET", "/vault/replica", nil)
+	if err != nil {
+		return
+	}
+	var rs vault.ReplicaStatus
+	if err := apiResult(resp, &rs); err != nil {
+		return
+	}
+	if rs.LastSync.IsZero() {
+		fmt.Println(msg("status.replica_never", rs.Source))
+	} else {
+		fmt.Println(msg("status.replica", rs.Source, rs.LastSync.Local().Format(time.DateTime)))
+	}
+	if rs.LastError != "" {
+		fmt.Println(msg("status.replica_error", rs.LastError))
+	}
+}
+
+// printExpiringTokens warns of service tokens that expire within
+// notify.token_expiry_window, before the agents using them stop working.
+// Only a session may list tokens; for anything else it prints nothing.
+func printExpiringTokens() {
+	window, err := time.ParseDuration(cliConfig().Value("notify.token_expiry_window"))
+	if err != nil || window < 24*time.Hour {
+		window = vault.DefaultTokenExpiryWindow
+	}
+	resp, err := apiRequest("GET", fmt.Sprintf("/vault/tokens/service?expiring_days=%d", int(window.Hours()/24)), nil)
+	if err != nil {
+		return
+	}
+	var tokens []struct {
+		TokenPrefix string    `json:"token_prefix"`
+		Consumer    string    `json:"consumer"`
+		ExpiresAt   time.Time `json:"expires_at"`
+	}
+	if err := apiResult(resp, &tokens); err != nil {
+		return
+	}
+	for _, t := range tokens {
+		prefix := strings.TrimSuffix(t.TokenPrefix, "...")
+		if days := int(time.Until(t.ExpiresAt).Hours() / 24); days > 0 {
+			fmt.Println(msg("status.token_expiring", t.Consumer, prefix, days))
+		} else {
+			fmt.Println(msg("status.token_expiring_soon", t.Consumer, prefix))
+		}
+	}
+}
+
+// printKeyStorageMode reports how this machine's vault keeps its secret key.
+// A CLI pointed at a remote vault has none to report.
+func printKeyStorageMode() {
+	dir := vaultDir()
+	if !fileExists(filepath.Join(dir, "vault.db")) && !fileExists(filepath.Join(dir, "vault.db.enc")) {
+		return
+	}
+	mode := keystore.Detect(dir)
+	name := keyModeName(mode)
+	if mode == keystore.Keyfile {
+		if path, err := keystore.KeyfilePath(dir); err == nil {
+			name += " (" + path + ")"
 		}
 	}
+	fmt.Println(msg("status.secret_key", name))
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const templateUsage = "usage: pvault template [list | set <consumer> <pattern>[,<pattern>...] [--mask <pattern>=redact|last4|initial ...] | delete <consumer>]"
+
+func cmdTemplate() {
+	args := os.Args[2:]
+	switch {
+	case len(args) == 0 || (len(args) == 1 && args[0] == "list"):
+		listTemplates()
+	case len(args) >= 3 && args[0] == "set":
+		setTemplate(args[1], args[2], args[3:])
+	case len(args) == 2 && args[0] == "delete":
+		resp, err := apiRequest("DELETE", "/vault/templates/"+args[1], nil)
+		if err != nil {
+			fatal("request failed: %v", err)
+		}
+		if err := apiResult(resp, nil); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println(msg("template.deleted", args[1]))
+	default:
+		fatal(templateUsage)
+	}
+}
+
+func setTemplate(consumer, patterns string, flags []string) {
+	var mask []vault.MaskRule
+	for i := 0; i < len(flags); i++ {
+		if flags[i] != "--mask" || i+1 == len(flags) {
+			fatal(templateUsage)
+		}
+		i++
+		pattern, rule, ok := strings.Cut(flags[i], "=")
+		if !ok {
+			fatal(templateUsage)
+		}
+		mask = append(mask, vault.MaskRule{Fields: pattern, Rule: rule})
+	}
+	body := map[string]any{"fields": strings.Split(patterns, ","), "mask": mask}
+	resp, err := apiRequest("PUT", "/vault/templates/"+consumer, body)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println(msg("template.set", consumer))
+}
+
+func listTemplates() {
+	resp, err := apiRequest("GET", "/vault/templates", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var result struct {
+		Templates []vault.ContextTemplate `json:"templates"`
+	}
+	if err := apiResult(resp, &result); err != nil {
+		fatal("%v", err)
+	}
+	if len(result.Templates) == 0 {
+		fmt.Println(msg("template.empty"))
+		return
+	}
+	for _, t := range result.Templates {
+		fmt.Printf("%-24s %s\n", t.Consumer, strings.Join(t.Fields, ","))
+		for _, m := range t.Mask {
+			fmt.Printf("%-24s   %s\n", "", msg("template.mask", m.Fields, m.Rule))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+const typeUsage = "usage: pvault type <id> [text | json | pem | url | number]"
+
+func cmdType() {
+	args := os.Args[2:]
+	switch len(args) {
+	case 1:
+		showType(args[0])
+	case 2:
+		setValueType(args[0], args[1])
+		if args[1] == vault.TypeText {
+			fmt.Println(msg("type.cleared", args[0]))
+		} else {
+			fmt.Println(msg("type.set", args[0], args[1]))
+		}
+	default:
+		fatal(typeUsage)
+	}
+}
+
+func setValueType(id, typ string) {
+	resp, err := apiRequest("PUT", "/vault/types/"+id, map[string]string{"type": typ})
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	if err := apiResult(resp, nil); err != nil {
+		fatal("%v", err)
+	}
+}
+
+// showType prints a field's value type from the field list, so the value
+// isn't read just to see it.
+func showType(id string) {
+	resp, err := apiRequest("GET", "/vault/fields", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+	var fields []vault.FieldInfo
+	if err := apiResult(resp, &fields); err != nil {
+		fatal("%v", err)
+	}
+	for _, f := range fields {
+		if f.ID == id && f.Type != "" {
+			fmt.Println(f.Type)
+			return
+		}
+	}
+	fmt.Println(vault.TypeText)
+}
@@ -2,25 +2,47 @@ package main
 
 import (
 	"fmt"
+	"net/url"
+	"os"
 	"os/exec"
 	"runtime"
 )
 
 func cmdUI() {
-	token, err := readSessionToken()
-	if err != nil {
-		fatal("vault is not unlocked — run 'pvault unlock' first")
+	page := "onboarding"
+	if len(os.Args) > 2 && os.Args[2] == "manage" {
+		page = "manage"
 	}
+	openBrowser(uiLoginURL(page, ""), "Opened vault UI in your browser.")
+}
 
-	// Verify vault is reachable and unlocked
-	resp, err := apiRequest("GET", "/vault/status", nil)
+// uiLoginURL returns a link that logs the browser into this session and
+// lands on page, with fragment (page parameters) kept for the page. The link
+// carries a one-time code, never the session token, so the browser's history
+// holds nothing reusable.
+func uiLoginURL(page, fragment string) string {
+	if _, err := readSessionToken(); err != nil {
+		fatal("vault is not unlocked — run 'pvault unlock' first")
+	}
+	resp, err := apiRequest("POST", "/vault/ui/login", nil)
 	if err != nil {
 		fatal("cannot reach vault server — is it running? Try 'pvault unlock'")
 	}
-	resp.Body.Close()
-
-	url := serverAddr() + "/ui#token=" + token
+	var login struct {
+		Code string `json:"code"`
+	}
+	if err := apiResult(resp, &login); err != nil {
+		fatal("%v", err)
+	}
+	u := serverAddr() + "/ui/login?" + url.Values{"code": {login.Code}, "next": {page}}.Encode()
+	if fragment != "" {
+		u += "#" + fragment
+	}
+	return u
+}
 
+// openBrowser opens url in the default browser, printing it if that fails.
+func openBrowser(url, opened string) {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
 	case "darwin":
@@ -33,7 +55,7 @@ func cmdUI() {
 
 	if cmd != nil {
 		if err := cmd.Start(); err == nil {
-			fmt.Println("Opened vault UI in your browser.")
+			fmt.Println(opened)
 			return
 		}
 	}
@@ -8,17 +8,20 @@ import (
 	"os"
 	"os/exec"
 	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/proc"
 )
 
 func cmdUnlock() {
 	// Probe the port first — catches stale servers even if the PID file is gone.
 	if portHasVault() {
-		if isVaultUnlocked() {
-			fmt.Println("Vault is already unlocked (server running).")
+		if hasLiveSession() {
+			fmt.Println(msg("unlock.already"))
 			return
 		}
-		// Server running but vault auto-locked — re-unlock via API
-		pw, err := promptPassword("Profile password: ")
+		// Server running but our session expired or was revoked, or the
+		// vault auto-locked — open a new session via the API
+		pw, err := promptPassword(msg("prompt.password"))
 		if err != nil {
 			fatal("reading password: %v", err)
 		}
@@ -33,7 +36,7 @@ func cmdUnlock() {
 	// Nothing on the port — clean up any stale PID file
 	removePID()
 
-	pw, err := promptPassword("Profile password: ")
+	pw, err := promptPassword(msg("prompt.password"))
 	if err != nil {
 		fatal("reading password: %v", err)
 	}
@@ -62,6 +65,7 @@ func cmdUnlock() {
 
 	cmd.Stdout = nil
 	cmd.Stderr = nil
+	proc.Detach(cmd)
 
 	if err := cmd.Start(); err != nil {
 		fatal("starting server: %v", err)
@@ -83,7 +87,7 @@ func cmdUnlock() {
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("Vault server started (could not verify status).")
+			fmt.Println(msg("unlock.unverified"))
 			return
 		default:
 			resp, err := apiRequest("GET", "/vault/status", nil)
@@ -94,7 +98,7 @@ func cmdUnlock() {
 				json.NewDecoder(resp.Body).Decode(&status)
 				resp.Body.Close()
 				if !status.Locked {
-					fmt.Println("Vault unlocked. Server running on", serverAddr())
+					fmt.Println(msg("unlock.done", serverAddr()))
 					return
 				}
 				// Server responded but vault is locked — our spawn likely
@@ -109,8 +113,7 @@ func cmdUnlock() {
 // portHasVault probes the server address with GET /vault/status.
 // Returns true if a vault server responds, false otherwise.
 func portHasVault() bool {
-	client := &http.Client{Timeout: time.Second}
-	resp, err := client.Get(serverAddr() + "/vault/status")
+	resp, err := httpClient(time.Second).Get(serverAddr() + apiPrefix + "/vault/status")
 	if err != nil {
 		return false
 	}
@@ -118,47 +121,23 @@ func portHasVault() bool {
 	return resp.StatusCode == http.StatusOK
 }
 
-func isVaultUnlocked() bool {
-	resp, err := apiRequest("GET", "/vault/status", nil)
-	if err != nil {
+// hasLiveSession reports whether the saved session token still works. The
+// vault can be unlocked by other sessions while ours has expired.
+func hasLiveSession() bool {
+	if _, err := readSessionToken(); err != nil {
 		return false
 	}
-	defer resp.Body.Close()
-	var status struct {
-		Locked bool `json:"locked"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+	resp, err := sendAPIRequest("GET", "/vault/session", nil)
+	if err != nil {
 		return false
 	}
-	return !status.Locked
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
 }
 
 func reUnlock(password, secretKey string) {
-	body := map[string]string{
-		"password":   password,
-		"secret_key": secretKey,
-	}
-	resp, err := apiRequest("POST", "/vault/unlock", body)
-	if err != nil {
-		fatal("re-unlock request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	var result struct {
-		Token string `json:"token"`
-		Error string `json:"error"`
-	}
-	json.NewDecoder(resp.Body).Decode(&result)
-
-	if resp.StatusCode >= 400 {
-		fatal("unlock failed: %s", result.Error)
-	}
-	if result.Token == "" {
-		fatal("unlock returned no token")
-	}
-
-	if err := writeSessionToken(result.Token); err != nil {
-		fatal("write session: %v", err)
+	if err := openSession(password, secretKey); err != nil {
+		fatal("unlock failed: %v", err)
 	}
-	fmt.Println("Vault unlocked. Server running on", serverAddr())
+	fmt.Println(msg("unlock.done", serverAddr()))
 }
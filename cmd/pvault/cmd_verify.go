@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+func cmdVerify() {
+	resp, err := apiRequest("GET", "/vault/verify", nil)
+	if err != nil {
+		fatal("request failed: %v", err)
+	}
+
+	var report vault.VerifyReport
+	if err := apiResult(resp, &report); err != nil {
+		fatal("%v", err)
+	}
+
+	if !report.SaltMatch {
+		fmt.Println(msg("verify.salt_mismatch"))
+	}
+	fields := 0
+	for _, c := range report.Categories {
+		fields += c.Fields
+		switch c.KCV {
+		case vault.KCVMismatch:
+			fmt.Println(msg("verify.key_mismatch", c.Category))
+		case vault.KCVUnknown:
+			fmt.Println(msg("verify.key_unknown", c.Category))
+		case vault.KCVAdded:
+			fmt.Println(msg("verify.key_added", c.Category))
+		}
+		for _, id := range c.Corrupted {
+			fmt.Println(msg("verify.corrupted", id))
+		}
+	}
+
+	if !report.OK {
+		fmt.Println(msg("verify.failed"))
+		os.Exit(1)
+	}
+	fmt.Println(msg("verify.ok", fields, len(report.Categories)))
+}
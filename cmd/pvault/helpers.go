@@ -2,16 +2,23 @@ package main
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"syscall"
+	"sync"
+	"time"
 
+	"github.com/lovincyrus/personal-vault/internal/config"
+	"github.com/lovincyrus/personal-vault/internal/keystore"
 	"golang.org/x/term"
 )
 
@@ -23,47 +30,125 @@ func vaultDir() string {
 	return filepath.Join(home, ".pvault")
 }
 
+// cliConfig is the vault directory's config.toml. A malformed file stops
+// the command rather than silently falling back to defaults. It can't use
+// fatal, whose localized prefix needs the server address from here.
+var cliConfig = sync.OnceValue(func() *config.Config {
+	c, err := config.Load(config.Path(vaultDir()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	return c
+})
+
 func serverAddr() string {
-	if a := os.Getenv("VAULT_ADDR"); a != "" {
+	if a := cliConfig().Value("client.addr"); a != "" {
 		return a
 	}
-	return "http://127.0.0.1:7200"
+	return "http://127.0.0.1:" + cliConfig().Value("server.port")
 }
 
-func sessionPath() string {
-	return filepath.Join(vaultDir(), ".session")
+// remoteToken is the service token from PVAULT_TOKEN. When set, the CLI
+// authenticates with it instead of the session agent's token, so it can read
+// from a vault server on another host without any key material.
+func remoteToken() string {
+	return strings.TrimSpace(os.Getenv("PVAULT_TOKEN"))
 }
 
-func pidPath() string {
-	return filepath.Join(vaultDir(), "pvault.pid")
-}
+// apiTransport verifies the server's TLS certificate against the system
+// roots plus client.ca_cert, if set (e.g. a home server's self-signed CA).
+// Verification is never skipped; an unreadable CA file is reported by
+// apiRequest and leaves only the system roots.
+var apiTransport = sync.OnceValues(func() (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	caFile := cliConfig().Value("client.ca_cert")
+	if caFile == "" {
+		return t, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return t, fmt.Errorf("read CA certificate: %w", err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return t, fmt.Errorf("CA certificate %s contains no PEM certificates", caFile)
+	}
+	t.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	return t, nil
+})
 
-func secretKeyPath() string {
-	return filepath.Join(vaultDir(), "secret.key")
+// httpClient returns a client for the vault server; zero means no timeout.
+// It doesn't follow redirects: a read replica redirects writes to its
+// primary, which the CLI's token isn't for.
+func httpClient(timeout time.Duration) *http.Client {
+	t, _ := apiTransport()
+	return &http.Client{
+		Transport: t,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
 }
 
-func readSessionToken() (string, error) {
-	data, err := os.ReadFile(sessionPath())
-	if err != nil {
-		return "", fmt.Errorf("vault is not unlocked (no session file)")
+// isLoopback reports whether host (without port) names this machine.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
 	}
-	return strings.TrimSpace(string(data)), nil
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
 
-func writeSessionToken(token string) error {
-	return os.WriteFile(sessionPath(), []byte(token+"\n"), 0600)
+// sessionPath is where versions before the session agent kept the session
+// token in plain text.
+func sessionPath() string {
+	return filepath.Join(vaultDir(), ".session")
 }
 
-func removeSessionToken() {
-	os.Remove(sessionPath())
+func pidPath() string {
+	return filepath.Join(vaultDir(), "pvault.pid")
 }
 
+// readSecretKey returns the vault's secret key from wherever it is stored,
+// asking for the PIN, or for the key itself, if it needs one.
 func readSecretKey() (string, error) {
-	data, err := os.ReadFile(secretKeyPath())
+	return keystore.Load(vaultDir(), askSecretKey)
+}
+
+func askSecretKey(mode keystore.Mode) (string, error) {
+	if mode == keystore.PIN {
+		return promptPassword(msg("prompt.pin"))
+	}
+	return promptPassword(msg("prompt.secret_key"))
+}
+
+// promptNewPIN asks for a PIN to protect the secret key with, twice.
+func promptNewPIN() string {
+	pin, err := promptPassword(msg("prompt.new_pin"))
+	if err != nil {
+		fatal("reading PIN: %v", err)
+	}
+	if len(pin) < keystore.MinPINLength {
+		fatal("%s", msg("pin.too_short", keystore.MinPINLength))
+	}
+	confirm, err := promptPassword(msg("prompt.confirm_pin"))
 	if err != nil {
-		return "", fmt.Errorf("secret key not found at %s", secretKeyPath())
+		fatal("reading confirmation: %v", err)
 	}
-	return strings.TrimSpace(string(data)), nil
+	if pin != confirm {
+		fatal("%s", msg("pin.mismatch"))
+	}
+	return pin
+}
+
+// keyModeName describes a secret key storage mode to the user.
+func keyModeName(mode keystore.Mode) string {
+	return msg("secret_key." + string(mode))
 }
 
 func writePID(pid int) error {
@@ -84,7 +169,7 @@ func removePID() {
 
 func promptPassword(prompt string) (string, error) {
 	fmt.Fprint(os.Stderr, prompt)
-	pw, err := term.ReadPassword(int(syscall.Stdin))
+	pw, err := term.ReadPassword(int(os.Stdin.Fd()))
 	fmt.Fprintln(os.Stderr)
 	if err != nil {
 		return "", err
@@ -92,41 +177,126 @@ func promptPassword(prompt string) (string, error) {
 	return string(pw), nil
 }
 
-// apiRequest makes an authenticated HTTP request to the vault server.
+// elevationToken is the step-up token from POST /vault/elevate, kept for the
+// rest of the command once the password has been re-entered.
+var elevationToken string
+
+// apiRequest makes an authenticated HTTP request to the vault server. If the
+// server wants the password re-entered for a high-risk operation, it prompts,
+// elevates the session, and retries once. If the vault has locked under the
+// command, see unlockAgain.
 func apiRequest(method, path string, body any) (*http.Response, error) {
+	if _, err := apiTransport(); err != nil {
+		return nil, err
+	}
+	var buf []byte
+	if body != nil {
+		buf, _ = json.Marshal(body)
+	}
+	resp, err := sendAPIRequest(method, path, buf)
+	if err == nil && sessionLost(resp) {
+		resp, err = unlockAgain(resp, func() (*http.Response, error) {
+			return sendAPIRequest(method, path, buf)
+		})
+	}
+	if err != nil || resp.StatusCode != http.StatusForbidden || elevationToken != "" {
+		return resp, err
+	}
+
+	// Anything but elevation_required goes back as-is.
+	if constraint, _ := peekRefusal(resp); constraint != "elevation_required" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return resp, nil
+	}
+
+	fmt.Fprintln(os.Stderr, msg("prompt.elevate"))
+	password, err := promptPassword(msg("prompt.password"))
+	if err != nil {
+		return nil, err
+	}
+	elevBody, _ := json.Marshal(map[string]string{"password": password})
+	elevResp, err := sendAPIRequest("POST", "/vault/elevate", elevBody)
+	if err != nil {
+		return nil, err
+	}
+	var elevated struct {
+		ElevationToken string `json:"elevation_token"`
+	}
+	if err := apiResult(elevResp, &elevated); err != nil {
+		return nil, err
+	}
+	elevationToken = elevated.ElevationToken
+	return sendAPIRequest(method, path, buf)
+}
+
+// peekRefusal reads the constraint and reason of an error response, leaving
+// its body to be read again.
+func peekRefusal(resp *http.Response) (constraint, reason string) {
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	var refusal struct {
+		Constraint string `json:"constraint"`
+		Reason     string `json:"reason"`
+	}
+	json.Unmarshal(data, &refusal)
+	return refusal.Constraint, refusal.Reason
+}
+
+// apiPrefix is the API version the CLI speaks, prefixed to /vault paths.
+const apiPrefix = "/v1"
+
+func sendAPIRequest(method, path string, body []byte) (*http.Response, error) {
 	var bodyReader io.Reader
 	if body != nil {
-		buf, _ := json.Marshal(body)
-		bodyReader = bytes.NewReader(buf)
+		bodyReader = bytes.NewReader(body)
 	}
 
-	url := serverAddr() + path
-	req, err := http.NewRequest(method, url, bodyReader)
+	req, err := http.NewRequest(method, serverAddr()+apiPrefix+path, bodyReader)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	token, err := readSessionToken()
-	if err == nil {
+	token := remoteToken()
+	if token == "" {
+		// Without a session the request goes out bare, for the public
+		// endpoints; a session the agent won't hand over stops it here.
+		if token, err = readSessionToken(); err != nil && !errors.Is(err, errNoSession) {
+			return nil, err
+		}
+	}
+	if token != "" {
+		if req.URL.Scheme != "https" && !isLoopback(req.URL.Hostname()) {
+			return nil, fmt.Errorf("refusing to send a token to %s over plain HTTP; use an https:// VAULT_ADDR", req.URL.Host)
+		}
 		req.Header.Set("Authorization", "Bearer "+token)
+		if elevationToken != "" {
+			req.Header.Set("X-Vault-Elevation", elevationToken)
+		}
+		if purpose := os.Getenv("PVAULT_PURPOSE"); purpose != "" {
+			req.Header.Set("X-Vault-Purpose", purpose)
+		}
 	}
 
-	return http.DefaultClient.Do(req)
+	return httpClient(0).Do(req)
 }
 
 // apiResult decodes a JSON response or returns the error.
 func apiResult(resp *http.Response, target any) error {
 	defer resp.Body.Close()
-	if resp.StatusCode >= 400 {
+	if resp.StatusCode >= 300 {
 		var errResp struct {
 			Error string `json:"error"`
 		}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		if errResp.Error != "" {
-			return fmt.Errorf("%s", errResp.Error)
+		msg := errResp.Error
+		if msg == "" {
+			msg = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		}
+		if id := resp.Header.Get("X-Request-Id"); id != "" {
+			msg += " (request " + id + ")"
 		}
-		return fmt.Errorf("HTTP %d", resp.StatusCode)
+		return fmt.Errorf("%s", msg)
 	}
 	if target != nil {
 		return json.NewDecoder(resp.Body).Decode(target)
@@ -134,7 +304,10 @@ func apiResult(resp *http.Response, target any) error {
 	return nil
 }
 
-func fatal(msg string, args ...any) {
-	fmt.Fprintf(os.Stderr, "Error: "+msg+"\n", args...)
+func fatal(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, msg("error")+": "+format+"\n", args...)
+	if lockedHint {
+		fmt.Fprintln(os.Stderr, msg("relock.hint"))
+	}
 	os.Exit(1)
 }
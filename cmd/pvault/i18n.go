@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/i18n"
+)
+
+// cliLang is the language for CLI output: the vault's preferences.language
+// when a server is unlocked, otherwise the locale from the environment.
+var cliLang = sync.OnceValue(func() string {
+	if lang := vaultLanguage(); lang != "" {
+		return lang
+	}
+	// POSIX precedence: the first variable that is set wins, even if it
+	// names a locale without a translation (such as "C").
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			if lang := i18n.Match(v); lang != "" {
+				return lang
+			}
+			break
+		}
+	}
+	return i18n.Default
+})
+
+// vaultLanguage asks a running server for the vault's language preference.
+// It gives up quickly so a missing server never delays the command.
+func vaultLanguage() string {
+	resp, err := httpClient(300 * time.Millisecond).Get(serverAddr() + apiPrefix + "/vault/status")
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	var status struct {
+		Language string `json:"language"`
+	}
+	json.NewDecoder(resp.Body).Decode(&status)
+	return status.Language
+}
+
+// msg returns the localized message for key, formatted with args if any.
+func msg(key string, args ...any) string {
+	if len(args) == 0 {
+		return i18n.T(cliLang(), key)
+	}
+	return i18n.Tf(cliLang(), key, args...)
+}
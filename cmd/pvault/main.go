@@ -11,6 +11,50 @@ func main() {
 		os.Exit(1)
 	}
 
+	// --offline runs get, set, and list on the vault database directly,
+	// without a server.
+	if os.Args[1] == "--offline" {
+		offline = true
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		if len(os.Args) < 2 || !offlineCommands[os.Args[1]] {
+			fatal("%s", msg("offline.usage"))
+		}
+	}
+
+	// --reveal unmasks critical values for get, list, entry list, and import
+	// previews, wherever it appears.
+	if revealCommands[os.Args[1]] {
+		args := os.Args[:2]
+		for _, arg := range os.Args[2:] {
+			if arg == "--reveal" {
+				revealValues = true
+				continue
+			}
+			args = append(args, arg)
+		}
+		os.Args = args
+	}
+
+	// --prompt-on-locked asks for the password when any command finds the
+	// vault locked, wherever it appears.
+	args := os.Args[:2]
+	for _, arg := range os.Args[2:] {
+		if arg == "--prompt-on-locked" {
+			promptOnLocked = true
+			continue
+		}
+		args = append(args, arg)
+	}
+	os.Args = args
+
+	// With PVAULT_TOKEN the CLI is a keyless reader of a (possibly remote)
+	// server; anything that writes or needs local key material is refused.
+	if remoteToken() != "" && !offline && !remoteCommands[os.Args[1]] {
+		fatal("'%s' is not available with PVAULT_TOKEN (remote access is read-only)", os.Args[1])
+	}
+
+	enableSchemaPacks()
+
 	switch os.Args[1] {
 	case "init":
 		cmdInit()
@@ -18,8 +62,18 @@ func main() {
 		cmdUnlock()
 	case "lock":
 		cmdLock()
+	case "relock":
+		cmdRelock()
+	case "sessions":
+		cmdSessions()
 	case "serve":
 		cmdServe()
+	case "agent":
+		cmdAgent()
+	case "service":
+		cmdService()
+	case "config":
+		cmdConfig()
 	case "status":
 		cmdStatus()
 	case "schema":
@@ -28,14 +82,74 @@ func main() {
 		cmdSet()
 	case "get":
 		cmdGet()
+	case "add":
+		cmdAdd()
 	case "list":
 		cmdList()
 	case "delete":
 		cmdDelete()
+	case "pin":
+		cmdPin()
+	case "unpin":
+		cmdUnpin()
+	case "note":
+		cmdNote()
+	case "type":
+		cmdType()
+	case "entry":
+		cmdEntry()
+	case "link":
+		cmdLink()
+	case "unlink":
+		cmdUnlink()
+	case "expiring":
+		cmdExpiring()
+	case "redact":
+		cmdRedact()
+	case "proxy":
+		cmdProxy()
+	case "history":
+		cmdHistory()
+	case "alias":
+		cmdAlias()
+	case "append-only":
+		cmdAppendOnly()
+	case "canary":
+		cmdCanary()
+	case "acl":
+		cmdACL()
+	case "consumer":
+		cmdConsumer()
+	case "template":
+		cmdTemplate()
+	case "verify":
+		cmdVerify()
+	case "doctor":
+		cmdDoctor()
+	case "compact":
+		cmdCompact()
+	case "migrate-secret-key":
+		cmdMigrateSecretKey()
+	case "kms":
+		cmdKMS()
+	case "plugins":
+		cmdPlugins()
 	case "set-sensitivity":
 		cmdSetSensitivity()
 	case "export":
 		cmdExport()
+	case "bootstrap":
+		cmdBootstrap()
+	case "k8s":
+		cmdK8s()
+	case "import":
+		cmdImport()
+	case "merge":
+		cmdMerge()
+	case "escrow":
+		cmdEscrow()
+	case "emergency":
+		cmdEmergency()
 	case "audit":
 		cmdAudit()
 	case "create-service-token":
@@ -46,39 +160,210 @@ func main() {
 		cmdRevokeServiceToken()
 	case "onboard":
 		cmdOnboard()
+	case "demo":
+		cmdDemo()
+	case "bench":
+		cmdBench()
 	case "ui":
 		cmdUI()
 	case "help", "-h", "--help":
 		printUsage()
 	default:
+		runPluginCommand(os.Args[1])
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
 		printUsage()
 		os.Exit(1)
 	}
 }
 
+// remoteCommands are the commands that work with a service token alone.
+var remoteCommands = map[string]bool{
+	"status": true, "schema": true, "get": true, "list": true, "expiring": true, "redact": true, "proxy": true, "export": true, "bootstrap": true, "k8s": true,
+	"bench": true, "help": true, "-h": true, "--help": true,
+}
+
 func printUsage() {
 	fmt.Println(`pvault — Personal Context Protocol vault
 
 Usage: pvault <command> [args]
 
 Commands:
-  onboard                          Create vault, unlock, and populate common fields
-  init                             Create a new vault
+  onboard [--profile travel|tax|minimal] [--from-file answers.json]
+                                   Create vault, unlock, and populate common fields (or a
+                                   profile's); --from-file sets them without prompting
+  demo [--seed <n>] [--dir <path>]
+                                   Create a throwaway vault of made-up data in every category
+                                   (in a temp directory); the same seed gives the same data
+  bench [--run <name>]             Time unlock, reads, writes, and token checks on this machine
+                                   against throwaway vaults; exits non-zero if any is over budget
+  init [--encrypt-db|--blind-index] [--encrypt-audit] [--secret-key keychain|pin|manual|file] [--keyfile <path>]
+                                   Create a new vault (optionally hiding field names at rest);
+                                   the secret key goes to the OS keychain if there is one, or
+                                   with --keyfile to a removable device that must be mounted to unlock
   unlock                           Unlock vault (starts background server)
   lock                             Lock vault (stops server)
-  serve                            Run server in foreground
+  relock                           Lock vault but keep the server running for the next unlock
+  sessions [list | revoke <id>]    List unlocked sessions (CLI, browser, ...) or end one
+  agent                            Hold a session token from stdin for a server on another
+                                   machine ('pvault unlock' starts it; a local server is its own)
+  serve [--locked | --headless] [--port <n>] [--watchdog] [--approve] [--replica --source <url>]
+                                   Run server in foreground (--locked: wait for 'pvault unlock';
+                                   --headless: unlock from --password-file/--secret-key-file or
+                                   VAULT_PASSWORD(_FILE)/VAULT_SECRET_KEY(_FILE), e.g. in a container,
+                                   or with no password given, from the key enrolled with 'pvault kms';
+                                   --watchdog: exit non-zero if the database becomes unwritable;
+                                   --approve: ask y/n in this terminal before sensitive reads and
+                                   each consumer's first read;
+                                   --replica: serve a read-only copy pulled from the primary at
+                                   --source with the token in VAULT_REPLICA_TOKEN(_FILE))
+  service install|uninstall|start|stop|status
+                                   Manage the server as a Windows service
+  config [list | get <key> | set <key> <value> | unset <key>]
+                                   Show or change settings in ~/.pvault/config.toml
+  config export [<file>] | import [--reissue-tokens] <file>
+                                   Move settings, consumers, access lists, and token metadata to another machine
   status                           Show vault status
-  schema                           Show recommended field names (--json for raw JSON)
-  set <id> <value>                 Set a field (e.g., identity.full_name "Cool Cucumber")
-  get <id>                         Get a field value
-  list [category]                  List fields
+  schema [--json] [--lang <code>]  Show recommended field names (--json for raw JSON)
+  schema packs | enable <pack> | disable <pack>
+                                   List optional schema packs (vehicles and insurance, travel,
+                                   medical)
+                                   or add one to the recommended schema
+  set [--raw] <id> <value>         Set a field (e.g., identity.full_name "Cool Cucumber");
+                                   phones, countries, and US states are normalized unless --raw
+  set [--raw] <id> --stdin | --from-file <path> | --prompt
+                                   Set a field from stdin, a file, or a hidden prompt, keeping the
+                                   value out of shell history; stdin and files keep their newlines
+  get [--signed] [--reveal] <id>   Get a field value; a bare name like "email" finds the field;
+                                   critical values are masked unless --reveal, even in a pipe;
+                                   --signed prints it as JSON with a signature for your consumer;
+                                   --field value|sensitivity|updated_at prints one attribute;
+                                   --template '{{ .Value | shellquote }}' prints a Go template
+  add <category>                   Fill in a category's recommended fields one prompt at a time,
+                                   skipping those already stored
+  list [category] [--reveal]       List fields, pinned ones first
+  pin [<id>]                       Pin a field so lists show it first, or list pinned fields
+  unpin <id>                       Unpin a field
+  note <id> [<text> | --clear]     Show, set, or clear a field's note (why it exists, caveats)
+  type <id> [text|json|pem|url|number]
+                                   Show or set how a field's value is shown: get indents JSON,
+                                   and masks PEM blocks like critical values unless --reveal
+  entry add <id> <value> [--label <label>] [--primary]
+                                   Add one of several values to a field (phones, addresses);
+                                   get returns the primary one
+  entry list <id> [--reveal] | remove <id> <label|position>
+                                   List a field's values or remove one
+  link [<from> <to> [--type <type>]]
+                                   List links, or say which fields go together (a card and
+                                   addresses.billing_*), so agents get them as a set
+  unlink <from> <to> [--type <type>]
+                                   Remove a link
+  expiring [--days <n>]            List passports, visas, and other expiry dates within n days
+                                   (default 90) or already past
+  redact [file]                    Print a file (or stdin) with vault values replaced by
+                                   [redacted:<id>], to scrub logs before keeping them
+  proxy --rule <host>:<Header>=[prefix ]<field> [--listen <addr>]
+                                   Run a local HTTP proxy that adds vault values as headers to
+                                   requests for <host>, so the process behind it never holds them
   delete <id>                      Delete a field
+  history <id>                     Show a field's history (values as entered before normalization)
+  alias [<alias> <target>]         List aliases, or make <alias> read and write <target>
+  alias --delete <alias>           Remove an alias
+  append-only [<category> | --remove <category>]
+                                   List append-only categories, or mark one so service tokens
+                                   add fields to it but only a session changes stored values
+  canary create <id> [--value <v>] [--sensitivity <tier>] [--revoke]
+                                   Store a decoy (generated from the field name unless --value);
+                                   a service token reading it alerts notify.*, and --revoke
+                                   revokes that token
+  canary list | delete <id>        List or remove canaries
+  acl add <id> --allow|--deny <consumer>
+                                   Let only the allowed consumers, and never a denied one, reach
+                                   a field, whatever their tokens' scopes
+  acl list | remove <id> <consumer>
+                                   List access lists or take a consumer off one
+  consumer trust <name> untrusted|standard|trusted
+                                   Cap the sensitivity a consumer's tokens reach, whatever their
+                                   scopes (standard, sensitive, or critical fields)
+  consumer list | reset <name>     List consumers' trust levels, or make one trusted again
+  consumer signing-key <name>      Print the key that verifies the consumer's signed reads, for
+                                   the systems it passes values on to
+  template set <consumer> <patterns> [--mask <pattern>=redact|last4|initial]
+                                   Choose which fields, in which order and how masked, a
+                                   consumer's tokens get from /vault/context
+  template list | delete <consumer>
+                                   List context templates or remove one
   set-sensitivity <id> <tier>      Set field sensitivity (public|standard|sensitive|critical)
-  export                           Export all decrypted fields as JSON
-  audit                            Show access audit log
-  ui                               Open vault onboarding form in browser
-  create-service-token <consumer>  Create a long-lived service token
-  list-service-tokens              List active service tokens
-  revoke-service-token <prefix>    Revoke a service token by prefix`)
+  export [--since <rfc3339>]       Export all decrypted fields as JSON, or only the fields
+                                   changed and deleted since a time
+  export --csv [--scope <patterns>]
+                                   Export fields as CSV for editing in a spreadsheet
+  bootstrap --format terraform|ansible [--fields <scope>] [-o <file>]
+                                   Write fields for Terraform's external data source or
+                                   as an Ansible vars file
+  k8s sync --map <field>=<secret>/<key> [--namespace <ns>] [--context <ctx>] [--dry-run]
+                                   Create or update Secrets in a local cluster (kind, minikube, ...)
+                                   from vault fields, reporting keys that drifted
+  import [--merge-strategy keep-newest|keep-existing|interactive] [--dry-run] <file>
+                                   Import an export, reporting conflicts before writing
+  import --csv [--dry-run] [--yes] <file>
+                                   Apply an edited CSV export after previewing the changes;
+                                   previews mask critical values unless --reveal
+  merge [--dry-run] <vault.db|dir> Merge a diverged copy of this vault; the copy changed
+                                   last wins each field
+  escrow export --recipient <key> [--fields scope] [-o file]
+                                   Export critical (or scoped) fields encrypted to a
+                                   trusted contact's age or SSH public key
+  emergency setup <contact> --recipient <key> [--fields scope] [--wait 72h]
+                                   Escrow fields to a contact who can request them;
+                                   released after the wait unless you deny it
+  emergency list|deny <contact>|remove <contact>
+                                   Review, deny, or remove emergency contacts
+  emergency request|release <contact> --code <code>
+                                   As the contact: start the wait, then collect the bundle
+  verify                           Check keys and stored values for corruption
+  doctor                           Diagnose permissions, stale files, port, database, and clock problems
+  compact                          Reclaim the space deleted data leaves in the database file
+  migrate-secret-key keychain|pin|keyfile <path>|manual|file
+                                   Move the secret key to the OS keychain, a PIN-encrypted file,
+                                   a removable device, nowhere (typed at unlock), or an unencrypted file
+  kms enroll hashicorp|aws|gcp --key <key> [--address <url>] [--mount <path>] [--region <r>]
+                                   Wrap the vault key with an external KMS so serve --headless
+                                   unlocks without a password
+  kms status | remove              Show or remove the KMS enrollment
+  plugins                          List plugin commands (pvault-<name> on PATH) and hook plugins
+  <name> [args]                    Run the plugin command pvault-<name>
+  audit [--fields]                 Show access audit log, with the fields and bytes each read sent
+                                   (--fields lists their IDs)
+  ui [manage]                      Open vault onboarding form (or management console) in browser
+  create-service-token <consumer> [--scope <patterns>] [--list-out-of-scope]
+                                   Create a long-lived service token; --list-out-of-scope lets
+                                   it see which other fields exist (IDs and tiers, no values)
+  create-service-token <consumer> [--description <text>] [--label <key=value>]...
+                                   Describe and label the token for later listings
+  create-service-token <consumer> --discovery
+                                   Create a token that can only list field names and tiers,
+                                   so a new agent can see what exists before you grant reads
+  list-service-tokens [--label <key=value>] [--expiring <days>d]
+                                   List active service tokens, or those with every label given
+                                   or expiring within the days given
+  revoke-service-token <prefix>    Revoke a service token by prefix
+
+With PVAULT_TOKEN set to a service token, status, schema, get, list, expiring,
+redact, proxy, and export read from the server at VAULT_ADDR or client.addr (https:// for other
+hosts; add a CA with VAULT_CA_CERT or client.ca_cert) without local vault files.
+PVAULT_PURPOSE says why, as fields like medical.* require.
+
+'pvault --offline get|set|list ...' works on the vault database directly, with
+no server, prompting for the password (or reading VAULT_PASSWORD and
+VAULT_SECRET_KEY); set refuses to run while a server is.
+
+After the vault locks (auto-lock, 'pvault relock'), commands fail until
+'pvault unlock', which reuses the running server; with --prompt-on-locked on
+any command, it asks for the password instead and carries on.
+
+Settings come from the environment, then flags, then config.toml, then
+built-in defaults; 'pvault config list' shows each value and its source.
+
+Messages follow the vault's preferences.language when unlocked, else LANG
+(supported: en, es, de, fr, zh).`)
 }
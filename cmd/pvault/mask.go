@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+	"golang.org/x/term"
+)
+
+// revealValues is set by --reveal: critical values and PEM blocks are shown
+// instead of masked, and the server audits it.
+var revealValues bool
+
+// revealCommands are the commands that mask values and take --reveal.
+var revealCommands = map[string]bool{"get": true, "list": true, "import": true, "entry": true}
+
+// stdoutIsTerminal reports whether output goes to a person rather than a
+// pipe, for layout: masking doesn't depend on it.
+func stdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// revealPath asks the server to audit the read when --reveal is set.
+func revealPath(path string) string {
+	if !revealValues {
+		return path
+	}
+	if strings.Contains(path, "?") {
+		return path + "&reveal=true"
+	}
+	return path + "?reveal=true"
+}
+
+// logOfflineReveal audits --reveal for a read that didn't go through the
+// server, as the server does for one that did.
+func logOfflineReveal(v *vault.Vault, scope string, fields []vault.FieldInfo) {
+	if !revealValues {
+		return
+	}
+	var ids []string
+	for _, f := range fields {
+		if vault.MaskedByDefault(f) {
+			ids = append(ids, f.ID)
+		}
+	}
+	if len(ids) > 0 {
+		v.LogAccess(store.AuditEntry{Consumer: "vault", Scope: scope, Action: "reveal", Fields: ids})
+	}
+}
+
+// maskedValue hides a value vault.MaskedByDefault covers unless --reveal
+// was passed, so that no value is printed unmasked without a reveal in the
+// audit log: it gates every way a command prints values, to a terminal or a
+// pipe. A single-line value keeps its last four characters so it can be
+// told apart; a multi-line one is replaced whole.
+func maskedValue(f *vault.FieldInfo) (string, bool) {
+	if revealValues || f.Value == "" || !vault.MaskedByDefault(*f) {
+		return f.Value, false
+	}
+	if f.Type == vault.TypePEM || strings.Contains(f.Value, "\n") {
+		return vault.MaskValue(vault.MaskRedact, f.Value), true
+	}
+	return vault.MaskValue(vault.MaskLast4, f.Value), true
+}
+
+// shownField is f as a command may print it: its value and each of its
+// entries go through maskedValue. It reports whether any was masked.
+func shownField(f *vault.FieldInfo) (vault.FieldInfo, bool) {
+	shown := *f
+	var masked bool
+	shown.Value, masked = maskedValue(f)
+	if len(f.Entries) > 0 {
+		shown.Entries = make([]vault.ValueEntry, len(f.Entries))
+		for i, e := range f.Entries {
+			entry := *f
+			entry.Value = e.Value
+			value, m := maskedValue(&entry)
+			e.Value = value
+			shown.Entries[i] = e
+			masked = masked || m
+		}
+	}
+	return shown, masked
+}
+
+// prettyValue indents a JSON value; others are shown as they are.
+func prettyValue(f *vault.FieldInfo, value string) string {
+	if f.Type != vault.TypeJSON {
+		return value
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, []byte(strings.TrimSpace(value)), "", "  "); err != nil {
+		return value
+	}
+	return b.String()
+}
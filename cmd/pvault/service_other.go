@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+func cmdService() {
+	fatal("%s", msg("service.unsupported"))
+}
+
+// runUntilShutdown blocks until the server is asked to stop, then runs cleanup.
+func runUntilShutdown(cleanup func()) {
+	waitForSignal()
+	cleanup()
+}
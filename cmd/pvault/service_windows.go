@@ -0,0 +1,155 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const serviceName = "pvault"
+
+// cmdService manages the vault server as a Windows service. The service
+// starts locked; 'pvault unlock' unlocks it like any running server.
+func cmdService() {
+	if len(os.Args) < 3 {
+		fatal("usage: pvault service install|uninstall|start|stop|status")
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		fatal("connect to service manager (run as administrator): %v", err)
+	}
+	defer m.Disconnect()
+
+	switch os.Args[2] {
+	case "install":
+		installService(m)
+	case "uninstall":
+		s := openService(m)
+		defer s.Close()
+		s.Control(svc.Stop)
+		if err := s.Delete(); err != nil {
+			fatal("remove service: %v", err)
+		}
+		fmt.Println(msg("service.removed"))
+	case "start":
+		s := openService(m)
+		defer s.Close()
+		if err := s.Start(); err != nil {
+			fatal("start service: %v", err)
+		}
+		fmt.Println(msg("service.started"))
+	case "stop":
+		s := openService(m)
+		defer s.Close()
+		if _, err := s.Control(svc.Stop); err != nil {
+			fatal("stop service: %v", err)
+		}
+		fmt.Println(msg("service.stopped"))
+	case "status":
+		s := openService(m)
+		defer s.Close()
+		status, err := s.Query()
+		if err != nil {
+			fatal("query service: %v", err)
+		}
+		fmt.Println(msg("service.status", serviceState(status.State)))
+	default:
+		fatal("usage: pvault service install|uninstall|start|stop|status")
+	}
+}
+
+func installService(m *mgr.Mgr) {
+	exe, err := os.Executable()
+	if err != nil {
+		fatal("finding executable: %v", err)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: "Personal Vault",
+		Description: "Local personal data vault server (starts locked)",
+		StartType:   mgr.StartAutomatic,
+	}, "serve", "--locked")
+	if err != nil {
+		fatal("install service: %v", err)
+	}
+	defer s.Close()
+
+	// The service runs as LocalSystem, whose home is not the user's, so pass
+	// the vault location through the service's environment.
+	env := []string{"VAULT_DIR=" + vaultDir()}
+	if port := os.Getenv("VAULT_PORT"); port != "" {
+		env = append(env, "VAULT_PORT="+port)
+	}
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+serviceName, registry.SET_VALUE)
+	if err != nil {
+		s.Delete()
+		fatal("configure service: %v", err)
+	}
+	defer k.Close()
+	if err := k.SetStringsValue("Environment", env); err != nil {
+		s.Delete()
+		fatal("configure service: %v", err)
+	}
+	fmt.Println(msg("service.installed", vaultDir()))
+}
+
+func openService(m *mgr.Mgr) *mgr.Service {
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		fatal("%s", msg("service.not_installed"))
+	}
+	return s
+}
+
+func serviceState(state svc.State) string {
+	switch state {
+	case svc.Running:
+		return "running"
+	case svc.Stopped:
+		return "stopped"
+	case svc.StartPending:
+		return "starting"
+	case svc.StopPending:
+		return "stopping"
+	}
+	return fmt.Sprintf("state %d", state)
+}
+
+// runUntilShutdown blocks until the server is asked to stop, then runs
+// cleanup. Under the service manager, cleanup finishes before the service
+// reports stopped.
+func runUntilShutdown(cleanup func()) {
+	if ok, _ := svc.IsWindowsService(); ok {
+		if err := svc.Run(serviceName, &serviceHandler{cleanup: cleanup}); err != nil {
+			fatal("run service: %v", err)
+		}
+		return
+	}
+	waitForSignal()
+	cleanup()
+}
+
+type serviceHandler struct {
+	cleanup func()
+}
+
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.Running, Accepts: accepts}
+	for req := range requests {
+		switch req.Cmd {
+		case svc.Interrogate:
+			status <- req.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending, WaitHint: uint32((10 * time.Second).Milliseconds())}
+			h.cleanup()
+			return false, 0
+		}
+	}
+	return false, 0
+}
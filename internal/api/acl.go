@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// GET /vault/acls
+// Lists the fields with access lists. Session only.
+func (s *Server) handleListACLs(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	acls, err := s.vault.ACLs()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"acls": acls})
+}
+
+// PUT /vault/acls/{id...}
+// Allows or denies a consumer the field. Session only.
+func (s *Server) handleSetACL(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return
+	}
+	var req struct {
+		Consumer string `json:"consumer"`
+		Effect   string `json:"effect"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Consumer == "" {
+		invalidField(w, "consumer", "consumer required")
+		return
+	}
+	if req.Effect != "allow" && req.Effect != "deny" {
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "effect must be allow or deny", errorDetails{
+			"field":   "effect",
+			"allowed": []string{"allow", "deny"},
+		})
+		return
+	}
+	if err := s.vault.AddACL(id, req.Consumer, req.Effect == "deny"); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DELETE /vault/acls/{id...}?consumer=<name>
+// Takes a consumer off the field's access lists. Session only.
+func (s *Server) handleDeleteACL(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	consumer := r.URL.Query().Get("consumer")
+	if consumer == "" {
+		invalidField(w, "consumer", "consumer required")
+		return
+	}
+	if err := s.vault.RemoveACL(r.PathValue("id"), consumer); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// appendOnlyAllows rejects a change to a stored field in an append-only
+// category unless it comes with a session, and reports whether the request
+// may go on. New fields may still be added by any writable token.
+func (s *Server) appendOnlyAllows(w http.ResponseWriter, r *http.Request, target string) bool {
+	if isSessionAuth(r) {
+		return true
+	}
+	category, ok := s.vault.AppendOnly(target)
+	if !ok {
+		return true
+	}
+	tier, err := s.vault.Sensitivity(target)
+	if err != nil {
+		handleVaultError(w, err)
+		return false
+	}
+	if tier == "" {
+		return true
+	}
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     target,
+		Action:    "denied",
+		Purpose:   "append_only",
+		RequestID: requestIDFromRequest(r),
+	})
+	writeErrorDetails(w, http.StatusForbidden, constraintAppendOnly, "the field's category is append-only: stored values change only with a session", errorDetails{
+		"id":       target,
+		"category": category,
+		"remedy":   "change the field from an unlocked session, e.g. 'pvault set'",
+	})
+	return false
+}
+
+// GET /vault/append-only
+// Lists the append-only categories. Session only.
+func (s *Server) handleListAppendOnly(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	categories, err := s.vault.AppendOnlyCategories()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	if categories == nil {
+		categories = []string{}
+	}
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// PUT /vault/append-only/{category}
+// Marks a category append-only. Session only.
+func (s *Server) handleSetAppendOnly(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if err := s.vault.SetAppendOnly(r.PathValue("category")); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DELETE /vault/append-only/{category}
+// Makes a category writable again. Session only.
+func (s *Server) handleClearAppendOnly(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if err := s.vault.ClearAppendOnly(r.PathValue("category")); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// bootstrapFormats are the infrastructure-as-code formats GET
+// /vault/bootstrap/{format} writes.
+var bootstrapFormats = []string{"terraform", "ansible"}
+
+// GET /vault/bootstrap/{format}?fields=<scope>
+// Writes the selected fields (default all the token can read) in a form
+// Terraform's external data source or Ansible's include_vars reads directly.
+func (s *Server) handleBootstrap(w http.ResponseWriter, r *http.Request) {
+	format := r.PathValue("format")
+	if !slices.Contains(bootstrapFormats, format) {
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "unknown bootstrap format", errorDetails{
+			"field":   "format",
+			"allowed": bootstrapFormats,
+		})
+		return
+	}
+	fields := r.URL.Query().Get("fields")
+	if fields == "" {
+		fields = "*"
+	}
+
+	ctx, err := s.vault.GetContext()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	scope, reach := s.fieldScope(r), s.fieldCheck(r)
+	var selected []vault.FieldInfo
+	for _, cat := range ctx.Categories {
+		for _, f := range cat {
+			if vault.ScopeAllows(scope, f.ID) && vault.ScopeAllows(fields, f.ID) && reach(f) {
+				selected = append(selected, f)
+			}
+		}
+	}
+	slices.SortFunc(selected, func(a, b vault.FieldInfo) int { return strings.Compare(a.ID, b.ID) })
+	if hasCritical(selected) && !s.elevated(r) {
+		elevationRequired(w, "export")
+		return
+	}
+	if err := s.authorizeRead(r, "bootstrap", selected); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+
+	selected, err = s.transformRead(r, selected)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+
+	ids := make([]string, len(selected))
+	for i, f := range selected {
+		ids[i] = f.ID
+	}
+	var body []byte
+	switch format {
+	case "terraform":
+		body = jsonBody(terraformResult(selected))
+	case "ansible":
+		body = ansibleVars(selected)
+	}
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     fields,
+		Action:    "bootstrap",
+		Purpose:   format,
+		RequestID: requestIDFromRequest(r),
+		Fields:    ids,
+		Bytes:     len(body),
+	})
+	s.tripCanaries(r, ids...)
+
+	w.Header().Set("Cache-Control", "no-store")
+	switch format {
+	case "terraform":
+		writeJSONBody(w, http.StatusOK, body)
+	case "ansible":
+		w.Header().Set("Content-Type", "application/yaml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}
+}
+
+// terraformResult is the flat string map the external data source expects,
+// keyed by field ID: data.external.vault.result["identity.email"].
+func terraformResult(fields []vault.FieldInfo) map[string]string {
+	result := make(map[string]string, len(fields))
+	for _, f := range fields {
+		result[f.ID] = f.Value
+	}
+	return result
+}
+
+// ansibleVars renders fields as a YAML vars file, one pvault_<category>_<field>
+// variable each, since Ansible variable names can't hold dots or hyphens.
+// Values are JSON strings, which YAML reads as double-quoted scalars.
+func ansibleVars(fields []vault.FieldInfo) []byte {
+	var b strings.Builder
+	b.WriteString("# Generated by pvault bootstrap. Holds secrets: don't commit it.\n---\n")
+	for _, f := range fields {
+		value, _ := json.Marshal(f.Value)
+		fmt.Fprintf(&b, "%s: %s\n", ansibleVarName(f.ID), value)
+	}
+	return []byte(b.String())
+}
+
+// ansibleVarName is the variable a field becomes in Ansible output.
+func ansibleVarName(id string) string {
+	return "pvault_" + strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToLower(id))
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// contextCache holds decrypted context bundles keyed by token scope.
+// Entries are tagged with the vault generation they were built from; any
+// write or lock state change advances the generation and empties the cache.
+// The vault's seal hook also empties it the moment the vault locks, auto-lock
+// included, so plaintext doesn't wait for the next read to be dropped.
+type contextCache struct {
+	generation func() uint64 // the vault's current generation
+
+	mu      sync.Mutex
+	gen     uint64
+	entries map[string]*vault.ContextBundle
+}
+
+func newContextCache(generation func() uint64) *contextCache {
+	return &contextCache{generation: generation, entries: make(map[string]*vault.ContextBundle)}
+}
+
+// get returns the cached bundle for scope if it was built at generation gen.
+// A request from an older generation misses without moving the cache back
+// to it.
+func (c *contextCache) get(gen uint64, scope string) (*vault.ContextBundle, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen < c.gen {
+		return nil, false
+	}
+	if gen > c.gen {
+		c.reset(gen)
+		return nil, false
+	}
+	b, ok := c.entries[scope]
+	return b, ok
+}
+
+// put stores a bundle built at generation gen. Bundles from an older
+// generation are dropped so a slow request can't repopulate stale data.
+func (c *contextCache) put(gen uint64, scope string, b *vault.ContextBundle) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen < c.gen {
+		return
+	}
+	if gen > c.gen {
+		c.reset(gen)
+	}
+	c.entries[scope] = b
+}
+
+// invalidate drops all cached plaintext and moves the cache past the
+// vault's current generation. The seal hook runs before a lock advances the
+// generation, so a bundle a request decrypted just before the lock is then
+// refused by put rather than cached for the next unlock to find.
+func (c *contextCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset(max(c.gen, c.generation()+1))
+}
+
+func (c *contextCache) reset(gen uint64) {
+	c.gen = gen
+	c.entries = make(map[string]*vault.ContextBundle)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// authMethod is one way a client can authenticate, as advertised by
+// GET /vault/capabilities.
+type authMethod struct {
+	Type   string `json:"type"`
+	Header string `json:"header"`
+	Obtain string `json:"obtain"`
+	Reads  string `json:"reads"`
+}
+
+// authMethods are the ways to authenticate. All send a Bearer token; they
+// differ in how it is obtained and what it reaches.
+var authMethods = []authMethod{
+	{
+		Type:   "session",
+		Header: "Authorization: Bearer <session token>",
+		Obtain: "POST /vault/unlock with the owner's password and secret key",
+		Reads:  "everything, as the owner",
+	},
+	{
+		Type:   "service_token",
+		Header: "Authorization: Bearer <service token>",
+		Obtain: "ask the owner: 'pvault create-service-token <consumer> --scope <scope>'",
+		Reads:  "field values within the token's scope",
+	},
+	{
+		Type:   "discovery_token",
+		Header: "Authorization: Bearer <discovery token>",
+		Obtain: "ask the owner: 'pvault create-service-token <consumer> --discovery'",
+		Reads:  "field names and tiers within the token's scope, no values",
+	},
+	{
+		Type:   "delegated_token",
+		Header: "Authorization: Bearer <delegated token>",
+		Obtain: "POST /vault/tokens/delegate with a service token",
+		Reads:  "a narrower scope of its parent's, for less time",
+	},
+}
+
+// capabilities is the response of GET /vault/capabilities.
+type capabilities struct {
+	APIVersions    []string          `json:"api_versions"`
+	CurrentVersion string            `json:"current_version"`
+	Initialized    bool              `json:"initialized"`
+	Locked         bool              `json:"locked"`
+	ReadOnly       bool              `json:"read_only"` // a replica; writes go to its primary
+	Categories     map[string]int    `json:"categories"`
+	AuthMethods    []authMethod      `json:"auth_methods"`
+	Endpoints      map[string]string `json:"endpoints"`
+}
+
+// discoveryEndpoints are where an agent goes next, under the current
+// version.
+var discoveryEndpoints = map[string]string{
+	"schema":  "/vault/schema",
+	"fields":  "/vault/fields",
+	"context": "/vault/context",
+	"prompt":  "/vault/context/prompt",
+}
+
+// GET /vault/capabilities
+// Describes the vault for agent frameworks configuring themselves before
+// they hold a token: API versions, how to authenticate, and which
+// categories hold fields, with counts only. No auth; rate limited per
+// client, as it answers anyone who can reach the port.
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if limit := s.capsLimit.limiter(requestPeer(r)); !limit.allow() {
+		retry := int(limit.retryAfter().Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		writeErrorDetails(w, http.StatusTooManyRequests, constraintRateLimited, "too many capability requests, try again later",
+			errorDetails{"retry_after_seconds": retry})
+		return
+	}
+	status, err := s.vault.Status()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, constraintInternal, "internal error")
+		return
+	}
+	endpoints := make(map[string]string, len(discoveryEndpoints))
+	for name, path := range discoveryEndpoints {
+		endpoints[name] = "/v" + currentAPIVersion + path
+	}
+	categories := status.Categories
+	if categories == nil {
+		categories = map[string]int{}
+	}
+	writeJSON(w, http.StatusOK, capabilities{
+		APIVersions:    supportedVersions(),
+		CurrentVersion: currentAPIVersion,
+		Initialized:    status.Initialized,
+		Locked:         status.Locked,
+		ReadOnly:       s.vault.ReplicaSource() != "",
+		Categories:     categories,
+		AuthMethods:    authMethods,
+		Endpoints:      endpoints,
+	})
+}
@@ -0,0 +1,53 @@
+package api
+
+import "net/http"
+
+// GET /vault/consumers
+// Lists the registered consumers and those holding service tokens, with
+// their trust levels. Session only.
+func (s *Server) handleListConsumers(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	consumers, err := s.vault.Consumers()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"consumers": consumers})
+}
+
+// PUT /vault/consumers/{name}
+// Sets a consumer's trust level. Session only.
+func (s *Server) handleSetConsumer(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	var req struct {
+		Trust string `json:"trust"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := s.vault.SetConsumerTrust(r.PathValue("name"), req.Trust); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DELETE /vault/consumers/{name}
+// Drops a consumer from the registry, so it is trusted again. Session only.
+func (s *Server) handleDeleteConsumer(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if err := s.vault.RemoveConsumer(r.PathValue("name")); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// corsMaxAge is how long, in seconds, a browser may cache a preflight answer.
+const corsMaxAge = 600
+
+// CORS headers. Requests carry the token in Authorization, never a cookie,
+// so no response allows credentials.
+var (
+	corsAllowMethods  = strings.Join(routeMethods, ", ")
+	corsAllowHeaders  = strings.Join([]string{"Authorization", "Content-Type", elevationHeader, purposeHeader, requestIDHeader, apiVersionHeader}, ", ")
+	corsExposeHeaders = strings.Join([]string{requestIDHeader, apiVersionHeader, "Deprecation", "Sunset", "Link", "Retry-After", "Allow"}, ", ")
+)
+
+// SetCORSOrigins lets pages on the given origins — a locally served app at
+// http://localhost:5173, a browser extension at chrome-extension://<id> —
+// call the API from the browser. Each must be an exact scheme://host[:port];
+// "*" is refused, since any page could then drive a vault someone had given
+// it a token for. Call before Start. With none, the default, cross-origin
+// requests get no CORS headers and browsers block them.
+func (s *Server) SetCORSOrigins(origins []string) error {
+	allowed := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		origin, err := parseOrigin(o)
+		if err != nil {
+			return err
+		}
+		allowed[origin] = true
+	}
+	s.corsOrigins = allowed
+	return nil
+}
+
+// parseOrigin checks that o is a bare origin and returns it as browsers send
+// it in the Origin header.
+func parseOrigin(o string) (string, error) {
+	if o == "*" {
+		return "", fmt.Errorf("CORS origin %q: list each origin; a wildcard would let any page call the vault", o)
+	}
+	u, err := url.Parse(o)
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("CORS origin %q: must be scheme://host[:port]", o)
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host), nil
+}
+
+// corsMiddleware adds CORS headers for allowed origins and answers their
+// preflight requests. Other origins pass through untouched: the browser,
+// not the server, is what refuses them the response.
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.corsOrigins) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		if !s.corsOrigins[origin] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
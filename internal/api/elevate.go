@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// elevationHeader carries the token from POST /vault/elevate on high-risk
+// requests.
+const elevationHeader = "X-Vault-Elevation"
+
+// POST /vault/elevate
+// Re-checks the password and returns a short-lived elevation token for the
+// caller's session. Critical reads, exports, and wildcard service tokens
+// require it in X-Vault-Elevation.
+func (s *Server) handleElevate(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if !s.elevateLimit.allow() {
+		retry := int(s.elevateLimit.retryAfter().Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		writeErrorDetails(w, http.StatusTooManyRequests, constraintRateLimited, "too many elevation attempts, try again later",
+			errorDetails{"retry_after_seconds": retry})
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Password == "" {
+		invalidField(w, "password", "password required")
+		return
+	}
+
+	token := sessionTokenFromRequest(r)
+	elevation, expires, err := s.vault.Elevate(token, req.Password)
+	if err != nil {
+		switch err {
+		case vault.ErrWrongPassword:
+			writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, "wrong password",
+				errorDetails{"reason": "wrong_credentials"})
+		case vault.ErrSessionNotFound:
+			writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, "invalid or expired session token",
+				errorDetails{"reason": "invalid_or_expired_token"})
+		default:
+			handleVaultError(w, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"elevation_token": elevation,
+		"expires_at":      expires.UTC().Format(time.RFC3339),
+	})
+}
+
+// elevated reports whether a request may perform a high-risk operation.
+// Service tokens are exempt: their scope was approved when they were created.
+func (s *Server) elevated(r *http.Request) bool {
+	if !isSessionAuth(r) {
+		return true
+	}
+	return s.vault.CheckElevation(sessionTokenFromRequest(r), r.Header.Get(elevationHeader))
+}
+
+func elevationRequired(w http.ResponseWriter, reason string) {
+	writeErrorDetails(w, http.StatusForbidden, constraintElevationRequired, "this operation requires re-entering the password", errorDetails{
+		"reason": reason,
+		"remedy": "POST /vault/elevate with the password and send the token in " + elevationHeader,
+	})
+}
+
+// hasCritical reports whether any of fields is in the critical tier.
+func hasCritical(fields []vault.FieldInfo) bool {
+	for _, f := range fields {
+		if f.Sensitivity == "critical" {
+			return true
+		}
+	}
+	return false
+}
+
+func bundleHasCritical(b *vault.ContextBundle) bool {
+	for _, fields := range b.Categories {
+		if hasCritical(fields) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+type emergencyInfo struct {
+	Contact     string `json:"contact"`
+	Recipient   string `json:"recipient"`
+	Wait        string `json:"wait"`
+	CreatedAt   string `json:"created_at"`
+	Status      string `json:"status,omitempty"`
+	RequestedAt string `json:"requested_at,omitempty"`
+	ReleaseAt   string `json:"release_at,omitempty"` // pending requests only
+	DecidedAt   string `json:"decided_at,omitempty"`
+}
+
+func newEmergencyInfo(c *store.EmergencyContact) emergencyInfo {
+	info := emergencyInfo{
+		Contact:   c.Name,
+		Recipient: c.Recipient,
+		Wait:      c.Wait.String(),
+		CreatedAt: c.CreatedAt.UTC().Format(time.RFC3339),
+		Status:    c.Status,
+	}
+	if !c.RequestedAt.IsZero() {
+		info.RequestedAt = c.RequestedAt.UTC().Format(time.RFC3339)
+	}
+	if c.Status == vault.EmergencyPending {
+		info.ReleaseAt = vault.ReleaseAt(c).UTC().Format(time.RFC3339)
+	}
+	if !c.DecidedAt.IsZero() {
+		info.DecidedAt = c.DecidedAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+// emergencyRateLimited enforces the limit on the public emergency endpoints,
+// which take an access code and so are open to guessing.
+func (s *Server) emergencyRateLimited(w http.ResponseWriter) bool {
+	if s.emergencyLimit.allow() {
+		return false
+	}
+	retry := int(s.emergencyLimit.retryAfter().Seconds()) + 1
+	w.Header().Set("Retry-After", strconv.Itoa(retry))
+	writeErrorDetails(w, http.StatusTooManyRequests, constraintRateLimited, "too many emergency access attempts, try again later",
+		errorDetails{"retry_after_seconds": retry})
+	return true
+}
+
+// decodeEmergencyCode reads the {contact, code} body shared by the public
+// emergency endpoints.
+func decodeEmergencyCode(w http.ResponseWriter, r *http.Request) (contact, code string, ok bool) {
+	var req struct {
+		Contact string `json:"contact"`
+		Code    string `json:"code"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return "", "", false
+	}
+	if req.Contact == "" || req.Code == "" {
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "contact and code required",
+			errorDetails{"field": "contact,code"})
+		return "", "", false
+	}
+	return req.Contact, req.Code, true
+}
+
+// handleEmergencyError maps emergency access errors, falling back to
+// handleVaultError.
+func handleEmergencyError(w http.ResponseWriter, err error, contact string) {
+	var waiting *vault.EmergencyWaitingError
+	if errors.As(err, &waiting) {
+		writeErrorDetails(w, http.StatusConflict, constraintEmergencyWaiting, "the waiting period has not ended", errorDetails{
+			"release_at": waiting.ReleaseAt.UTC().Format(time.RFC3339),
+		})
+		return
+	}
+	switch err {
+	case vault.ErrEmergencyCode:
+		writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, err.Error(),
+			errorDetails{"reason": "wrong_credentials"})
+	case vault.ErrEmergencyDenied:
+		writeError(w, http.StatusForbidden, constraintEmergencyDenied, err.Error())
+	case vault.ErrEmergencyNotRequested:
+		writeErrorDetails(w, http.StatusConflict, constraintConflict, err.Error(),
+			errorDetails{"remedy": "request access with POST /vault/emergency/request"})
+	case vault.ErrEmergencyNotFound:
+		writeErrorDetails(w, http.StatusNotFound, constraintNotFound, err.Error(), errorDetails{"id": contact})
+	case vault.ErrEmergencyContactName:
+		invalidField(w, "contact", err.Error())
+	case vault.ErrEmergencyWait:
+		invalidField(w, "wait", err.Error())
+	case vault.ErrEmergencyNoBundle:
+		invalidField(w, "bundle", err.Error())
+	case vault.ErrEmergencyEncryptedDB:
+		writeErrorDetails(w, http.StatusConflict, constraintConflict, err.Error(), errorDetails{"reason": "encrypted_database"})
+	default:
+		handleVaultError(w, err)
+	}
+}
+
+// POST /vault/emergency/request
+// Public: a contact starts the waiting period with their access code.
+func (s *Server) handleEmergencyRequest(w http.ResponseWriter, r *http.Request) {
+	if s.emergencyRateLimited(w) {
+		return
+	}
+	contact, code, ok := decodeEmergencyCode(w, r)
+	if !ok {
+		return
+	}
+	c, err := s.vault.RequestEmergencyAccess(contact, code)
+	if err != nil {
+		handleEmergencyError(w, err, contact)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":     c.Status,
+		"release_at": vault.ReleaseAt(c).UTC().Format(time.RFC3339),
+	})
+}
+
+// POST /vault/emergency/release
+// Public: a contact collects their sealed bundle once the waiting period has
+// passed without the owner denying it.
+func (s *Server) handleEmergencyRelease(w http.ResponseWriter, r *http.Request) {
+	if s.emergencyRateLimited(w) {
+		return
+	}
+	contact, code, ok := decodeEmergencyCode(w, r)
+	if !ok {
+		return
+	}
+	bundle, err := s.vault.EmergencyBundle(contact, code)
+	if err != nil {
+		handleEmergencyError(w, err, contact)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"bundle": bundle})
+}
+
+// GET /vault/emergency
+func (s *Server) handleListEmergency(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	contacts, err := s.vault.EmergencyContacts()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	result := make([]emergencyInfo, len(contacts))
+	for i := range contacts {
+		result[i] = newEmergencyInfo(&contacts[i])
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// PUT /vault/emergency/{contact}
+// Stores a bundle already sealed to the contact and returns their access code.
+func (s *Server) handleSetEmergency(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	contact := r.PathValue("contact")
+	var req struct {
+		Recipient string `json:"recipient"`
+		Wait      string `json:"wait"`
+		Bundle    string `json:"bundle"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Recipient == "" {
+		invalidField(w, "recipient", "recipient required")
+		return
+	}
+	wait := vault.DefaultEmergencyWait
+	if req.Wait != "" {
+		parsed, err := time.ParseDuration(req.Wait)
+		if err != nil {
+			invalidField(w, "wait", "invalid wait duration")
+			return
+		}
+		wait = parsed
+	}
+
+	code, err := s.vault.SetEmergencyContact(contact, req.Recipient, req.Bundle, wait)
+	if err != nil {
+		handleEmergencyError(w, err, contact)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"contact": contact,
+		"code":    code,
+		"wait":    wait.String(),
+	})
+}
+
+// DELETE /vault/emergency/{contact}
+func (s *Server) handleDeleteEmergency(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	contact := r.PathValue("contact")
+	if err := s.vault.RemoveEmergencyContact(contact); err != nil {
+		handleEmergencyError(w, err, contact)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "removed", "contact": contact})
+}
+
+// POST /vault/emergency/{contact}/deny
+func (s *Server) handleDenyEmergency(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	contact := r.PathValue("contact")
+	if err := s.vault.DenyEmergencyAccess(contact); err != nil {
+		handleEmergencyError(w, err, contact)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": vault.EmergencyDenied, "contact": contact})
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// entryTarget checks that the caller may write field id, returning the
+// field an alias resolves to. Adding an entry changes a stored field, so
+// append-only categories refuse it too.
+func (s *Server) entryTarget(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return "", false
+	}
+	target := s.vault.ResolveAlias(id)
+	if !vault.ScopeAllows(s.fieldScope(r), target) {
+		s.scopeDenied(w, r, target)
+		return "", false
+	}
+	if !s.checkFieldAccess(w, r, target) || !s.appendOnlyAllows(w, r, target) {
+		return "", false
+	}
+	return target, true
+}
+
+// POST /vault/entries/{id...}
+// Appends an entry to a multi-valued field, turning a single value into a
+// list, or creating the field.
+func (s *Server) handleAddEntry(w http.ResponseWriter, r *http.Request) {
+	target, ok := s.entryTarget(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		vault.ValueEntry
+		Sensitivity string `json:"sensitivity"` // for a new field
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Value) == "" {
+		invalidField(w, "value", "value required")
+		return
+	}
+
+	tier, err := s.vault.Sensitivity(target)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	if tier == "" {
+		tier = req.Sensitivity
+		if tier == "" {
+			tier = vault.DefaultSensitivity(target)
+		}
+	}
+	if !s.trustCheck(r)(tier) {
+		s.trustDenied(w, r, target, tier)
+		return
+	}
+
+	if err := s.vault.AddEntry(target, req.ValueEntry, tier); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DELETE /vault/entries/{id...}?entry=<label or position>
+// Removes an entry from a multi-valued field.
+func (s *Server) handleRemoveEntry(w http.ResponseWriter, r *http.Request) {
+	target, ok := s.entryTarget(w, r)
+	if !ok {
+		return
+	}
+	ref := r.URL.Query().Get("entry")
+	if ref == "" {
+		invalidField(w, "entry", "entry label or position required")
+		return
+	}
+	tier, err := s.vault.Sensitivity(target)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	if tier != "" && !s.trustCheck(r)(tier) {
+		s.trustDenied(w, r, target, tier)
+		return
+	}
+
+	if err := s.vault.RemoveEntry(target, ref); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
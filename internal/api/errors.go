@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Constraints are the machine-readable reasons a request was refused. Every
+// error response carries one in "constraint", alongside a human "error"
+// message and any constraint-specific detail fields at the top level, e.g.
+//
+//	{"error": "...", "constraint": "scope_exceeded",
+//	 "required_scope": "financial.ssn", "token_scope": "identity.*"}
+const (
+	constraintInvalidRequest    = "invalid_request"    // details: field, reason, allowed
+	constraintBodyTooLarge      = "body_too_large"     // details: max_bytes
+	constraintUnauthenticated   = "unauthenticated"    // details: reason
+	constraintSessionRequired   = "session_required"   // details: required_auth, token_type
+	constraintServiceRequired   = "service_required"   // details: required_auth, token_type
+	constraintScopeExceeded     = "scope_exceeded"     // details: required_scope, token_scope
+	constraintACLDenied         = "acl_denied"         // details: id, consumer
+	constraintTrustExceeded     = "trust_exceeded"     // details: id, sensitivity, trust, max_sensitivity
+	constraintTokenRestricted   = "token_restricted"   // details: reason, hours, weekdays, max_per_day, timezone, workload
+	constraintVaultLocked       = "vault_locked"       // details: remedy
+	constraintNotInitialized    = "not_initialized"    // details: remedy
+	constraintNotFound          = "not_found"          // details: id, path
+	constraintMethodNotAllowed  = "method_not_allowed" // details: method, allowed
+	constraintConflict          = "conflict"
+	constraintRateLimited       = "rate_limited"      // details: retry_after_seconds
+	constraintCorrupted         = "corrupted"         // details: id, remedy
+	constraintEmergencyWaiting  = "emergency_waiting" // details: release_at
+	constraintEmergencyDenied   = "emergency_denied"
+	constraintElevationRequired = "elevation_required"  // details: reason, remedy
+	constraintCSRFFailed        = "csrf_failed"         // details: header
+	constraintPluginRejected    = "plugin_rejected"     // details: plugin, field, reason
+	constraintPluginFailed      = "plugin_failed"       // details: plugin
+	constraintAuthorizeDenied   = "authorize_denied"    // details: reason
+	constraintReadOnlyReplica   = "read_only_replica"   // details: primary, remedy
+	constraintPurposeRequired   = "purpose_required"    // details: id, header, max_bytes, remedy
+	constraintDiscoveryOnly     = "discovery_only"      // details: allowed
+	constraintOutOfScopeHidden  = "out_of_scope_hidden" // details: remedy
+	constraintBudgetExceeded    = "budget_exceeded"     // details: id, critical_per_day
+	constraintAppendOnly        = "append_only"         // details: id, category, remedy
+	constraintOverloaded        = "overloaded"          // details: retry_after_seconds
+	constraintInternal          = "internal"
+)
+
+// errorDetails are extra top-level fields on an error response.
+type errorDetails map[string]any
+
+func writeError(w http.ResponseWriter, status int, constraint, msg string) {
+	writeErrorDetails(w, status, constraint, msg, nil)
+}
+
+// writeErrorDetails writes an error response with constraint-specific fields.
+// Details can't override "error" or "constraint".
+func writeErrorDetails(w http.ResponseWriter, status int, constraint, msg string, details errorDetails) {
+	body := make(map[string]any, len(details)+2)
+	for k, v := range details {
+		body[k] = v
+	}
+	body["error"] = msg
+	body["constraint"] = constraint
+	writeJSON(w, status, body)
+}
+
+// decodeJSON decodes the request body into v, writing the appropriate error
+// response and returning false on failure.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeErrorDetails(w, http.StatusRequestEntityTooLarge, constraintBodyTooLarge,
+			"request body too large", errorDetails{"max_bytes": tooLarge.Limit})
+		return false
+	}
+	writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "invalid JSON",
+		errorDetails{"reason": "invalid_json"})
+	return false
+}
+
+// invalidField reports a malformed or missing request field.
+func invalidField(w http.ResponseWriter, field, msg string) {
+	writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, msg, errorDetails{"field": field})
+}
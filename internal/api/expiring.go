@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// maxExpiringDays bounds how far ahead GET /vault/expiring looks.
+const maxExpiringDays = 3650
+
+// GET /vault/expiring?days=90
+// Lists the expiry fields (passport, visa, ...) whose dates fall within the
+// next days, or have passed, soonest first. Service tokens see those their
+// scope and the field checks allow.
+func (s *Server) handleExpiring(w http.ResponseWriter, r *http.Request) {
+	days := int(vault.DefaultExpiryWindow / (24 * time.Hour))
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxExpiringDays {
+			writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "days must be between 1 and "+strconv.Itoa(maxExpiringDays),
+				errorDetails{"field": "days"})
+			return
+		}
+		days = n
+	}
+	expiring, err := s.vault.Expiring(time.Now(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+
+	scope, reach := s.fieldScope(r), s.fieldCheck(r)
+	allowed := make([]vault.ExpiringField, 0, len(expiring))
+	fields := make([]vault.FieldInfo, 0, len(expiring))
+	ids := make([]string, 0, len(expiring))
+	for _, e := range expiring {
+		f := vault.FieldInfo{ID: e.ID, Sensitivity: e.Sensitivity}
+		if vault.ScopeAllows(scope, e.ID) && reach(f) {
+			allowed = append(allowed, e)
+			fields = append(fields, f)
+			ids = append(ids, e.ID)
+		}
+	}
+	if hasCritical(fields) && !s.elevated(r) {
+		elevationRequired(w, "critical_field")
+		return
+	}
+	if err := s.authorizeRead(r, "expiring", fields); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	body := jsonBody(map[string]any{"days": days, "fields": allowed})
+	if len(ids) > 0 {
+		s.logConsumerRead(r, strings.Join(ids, ","), ids, len(body))
+		s.tripCanaries(r, ids...)
+	}
+	writeJSONBody(w, http.StatusOK, body)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// GET /vault/export?since=<rfc3339>
+// Returns the fields set at or after since, with their current values, and
+// the IDs of fields deleted since, for incremental backups. Pass the
+// response's as_of as the next since. Without since, every field is returned.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			invalidField(w, "since", "since must be an RFC 3339 timestamp")
+			return
+		}
+		since = parsed
+	}
+
+	changes, err := s.vault.ChangesSince(since)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	scope := scopeFromRequest(r)
+	if scope != "*" {
+		resolved := s.vault.ResolveScope(scope)
+		filtered := &vault.ContextBundle{Categories: make(map[string][]vault.FieldInfo)}
+		for cat, fields := range changes.Categories {
+			for _, f := range fields {
+				if vault.ScopeAllows(resolved, f.ID) {
+					filtered.Categories[cat] = append(filtered.Categories[cat], f)
+				}
+			}
+		}
+		changes.ContextBundle = filtered
+		deleted := changes.Deleted[:0]
+		for _, d := range changes.Deleted {
+			if vault.ScopeAllows(resolved, d.ID) {
+				deleted = append(deleted, d)
+			}
+		}
+		changes.Deleted = deleted
+	}
+	if !isSessionAuth(r) {
+		changes.ContextBundle = s.accessFilter(r, changes.ContextBundle)
+		acl := s.aclCheck(r)
+		deleted := changes.Deleted[:0]
+		for _, d := range changes.Deleted {
+			if acl(d.ID) {
+				deleted = append(deleted, d)
+			}
+		}
+		changes.Deleted = deleted
+	}
+	if !s.elevated(r) && bundleHasCritical(changes.ContextBundle) {
+		elevationRequired(w, "export")
+		return
+	}
+	if err := s.authorizeRead(r, "export", bundleFields(changes.ContextBundle)); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	ids := bundleIDs(changes.ContextBundle)
+	changes.ContextBundle, err = s.transformBundle(r, changes.ContextBundle)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+
+	body := jsonBody(changes)
+	entry := store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     scope,
+		Action:    "export",
+		RequestID: requestIDFromRequest(r),
+		Fields:    ids,
+		Bytes:     len(body),
+	}
+	if !since.IsZero() {
+		entry.Purpose = "since " + changes.Since.Format(time.RFC3339)
+	}
+	s.vault.LogAccess(entry)
+	s.tripCanaries(r, ids...)
+	writeJSONBody(w, http.StatusOK, body)
+}
@@ -1,16 +1,292 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/lovincyrus/personal-vault/internal/i18n"
+	"github.com/lovincyrus/personal-vault/internal/store"
 	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
-func scopeDenied(w http.ResponseWriter) {
-	writeError(w, http.StatusForbidden, "scope_exceeded", "token scope does not allow access to this field")
+// scopeDenied rejects an out-of-scope request and records the attempt so it
+// can be traced back through the request ID.
+func (s *Server) scopeDenied(w http.ResponseWriter, r *http.Request, target string) {
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     target,
+		Action:    "denied",
+		Purpose:   "scope_exceeded",
+		RequestID: requestIDFromRequest(r),
+	})
+	writeErrorDetails(w, http.StatusForbidden, constraintScopeExceeded, "token scope does not allow access to this field", errorDetails{
+		"required_scope": target,
+		"token_scope":    scopeFromRequest(r),
+		"remedy":         "use a service token whose scope includes required_scope",
+	})
+}
+
+// aclCheck returns whether the request may reach a field under the field
+// access lists, which bind service tokens only.
+func (s *Server) aclCheck(r *http.Request) func(id string) bool {
+	t := serviceTokenFromRequest(r)
+	if t == nil {
+		return func(string) bool { return true }
+	}
+	consumers := s.vault.TokenConsumers(t)
+	return func(id string) bool { return s.vault.ACLAllows(consumers, id) }
+}
+
+// trustCheck returns whether the request may reach a field of a given
+// sensitivity under its consumer's trust level.
+func (s *Server) trustCheck(r *http.Request) func(tier string) bool {
+	t := serviceTokenFromRequest(r)
+	if t == nil {
+		return func(string) bool { return true }
+	}
+	trust := s.vault.ConsumerTrust(s.vault.TokenConsumers(t))
+	return func(tier string) bool { return vault.TrustAllows(trust, tier) }
+}
+
+// fieldCheck returns whether the request may reach a field under the access
+// lists, its consumer's trust level, and the schema's purpose requirement.
+func (s *Server) fieldCheck(r *http.Request) func(f vault.FieldInfo) bool {
+	acl, trust, purpose := s.aclCheck(r), s.trustCheck(r), s.purposeCheck(r)
+	return func(f vault.FieldInfo) bool { return acl(f.ID) && trust(f.Sensitivity) && purpose(f.ID) }
+}
+
+// accessFilter drops the fields the request can't reach under fieldCheck,
+// leaving b itself untouched.
+func (s *Server) accessFilter(r *http.Request, b *vault.ContextBundle) *vault.ContextBundle {
+	if b == nil || isSessionAuth(r) {
+		return b
+	}
+	allowed := s.fieldCheck(r)
+	filtered := &vault.ContextBundle{Categories: make(map[string][]vault.FieldInfo)}
+	for cat, fields := range b.Categories {
+		for _, f := range fields {
+			if allowed(f) {
+				filtered.Categories[cat] = append(filtered.Categories[cat], f)
+			}
+		}
+	}
+	return filtered
+}
+
+// checkFieldAccess rejects a request for one field that the access lists,
+// its consumer's trust level, or a missing purpose keep it from, and reports
+// whether it may go on.
+func (s *Server) checkFieldAccess(w http.ResponseWriter, r *http.Request, target string) bool {
+	t := serviceTokenFromRequest(r)
+	if t == nil {
+		return true
+	}
+	consumers := s.vault.TokenConsumers(t)
+	if !s.vault.ACLAllows(consumers, target) {
+		s.aclDenied(w, r, target)
+		return false
+	}
+	tier, err := s.vault.Sensitivity(target)
+	if err != nil {
+		handleVaultError(w, err)
+		return false
+	}
+	if tier == "" {
+		tier = vault.DefaultSensitivity(target)
+	}
+	if !vault.TrustAllows(s.vault.ConsumerTrust(consumers), tier) {
+		s.trustDenied(w, r, target, tier)
+		return false
+	}
+	if vault.PurposeRequired(target) && purposeFromRequest(r) == "" {
+		s.purposeDenied(w, r, target)
+		return false
+	}
+	return true
+}
+
+// aclDenied rejects a request for a field whose access lists exclude the
+// token's consumer, and records the attempt.
+func (s *Server) aclDenied(w http.ResponseWriter, r *http.Request, target string) {
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     target,
+		Action:    "denied",
+		Purpose:   "acl",
+		RequestID: requestIDFromRequest(r),
+	})
+	writeErrorDetails(w, http.StatusForbidden, constraintACLDenied, "the field's access list excludes this consumer", errorDetails{
+		"id":       target,
+		"consumer": consumerFromRequest(r),
+	})
+}
+
+// trustDenied rejects a request for a field, or a field sensitivity, beyond
+// what the token's consumer is trusted with, and records the attempt.
+func (s *Server) trustDenied(w http.ResponseWriter, r *http.Request, target, tier string) {
+	trust := s.vault.ConsumerTrust(s.vault.TokenConsumers(serviceTokenFromRequest(r)))
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     target,
+		Action:    "denied",
+		Purpose:   "trust",
+		RequestID: requestIDFromRequest(r),
+	})
+	writeErrorDetails(w, http.StatusForbidden, constraintTrustExceeded, "the field is more sensitive than this consumer is trusted with", errorDetails{
+		"id":              target,
+		"sensitivity":     tier,
+		"trust":           trust,
+		"max_sensitivity": vault.TrustMaxSensitivity(trust),
+	})
+}
+
+// logConsumerRead attributes a read to the service token's consumer. The
+// vault's own read entries can't tell consumers apart. ids are the fields the
+// response carries and size its length, so the log says exactly what the
+// consumer received, not just what its scope allowed.
+func (s *Server) logConsumerRead(r *http.Request, scope string, ids []string, size int) {
+	if isSessionAuth(r) {
+		return
+	}
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     scope,
+		Action:    "read",
+		Purpose:   purposeFromRequest(r),
+		RequestID: requestIDFromRequest(r),
+		Fields:    ids,
+		Bytes:     size,
+	})
+}
+
+// logReveal records the owner's ?reveal=true reads of values the CLI and
+// the console mask by default, so showing one on screen leaves a trace.
+func (s *Server) logReveal(r *http.Request, scope string, fields []vault.FieldInfo) {
+	if !isSessionAuth(r) || r.URL.Query().Get("reveal") != "true" {
+		return
+	}
+	var ids []string
+	for _, f := range fields {
+		if vault.MaskedByDefault(f) {
+			ids = append(ids, f.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  "vault",
+		Scope:     scope,
+		Action:    "reveal",
+		RequestID: requestIDFromRequest(r),
+		Fields:    ids,
+	})
+}
+
+// authorizeRead spends the service token's read budget and asks the
+// authorizer, if one is configured, before the token is sent fields. The
+// owner's own session reads don't ask.
+func (s *Server) authorizeRead(r *http.Request, action string, fields []vault.FieldInfo) error {
+	if isSessionAuth(r) || len(fields) == 0 {
+		return nil
+	}
+	if t := serviceTokenFromRequest(r); t != nil {
+		if err := s.vault.SpendReadBudget(t, fields, time.Now()); err != nil {
+			var exceeded *vault.BudgetExceededError
+			if errors.As(err, &exceeded) {
+				s.vault.LogAccess(store.AuditEntry{
+					Consumer:  t.Consumer,
+					Scope:     exceeded.ID,
+					Action:    "denied",
+					Purpose:   constraintBudgetExceeded,
+					RequestID: requestIDFromRequest(r),
+				})
+			}
+			return err
+		}
+	}
+	ids := make([]string, len(fields))
+	for i, f := range fields {
+		ids[i] = f.ID
+	}
+	s.extendDeadline(r, s.vault.AuthorizeTimeout())
+	return s.vault.Authorize(r.Context(), vault.AuthorizationRequest{
+		Consumer:    consumerFromRequest(r),
+		Action:      action,
+		Fields:      ids,
+		Sensitivity: vault.MaxSensitivity(fields),
+		Purpose:     purposeFromRequest(r),
+		RequestID:   requestIDFromRequest(r),
+	})
+}
+
+// tripCanaries alerts on any canary among the fields a service token has
+// just been sent. The owner's own session reads don't count.
+func (s *Server) tripCanaries(r *http.Request, ids ...string) {
+	if isSessionAuth(r) {
+		return
+	}
+	s.vault.TripCanaries(ids, serviceTokenFromRequest(r), requestIDFromRequest(r))
+}
+
+// transformRead runs the read plugins over fields about to be sent to a
+// service token. The owner's own reads are sent as stored, so what they
+// edit is what is in the vault.
+func (s *Server) transformRead(r *http.Request, fields []vault.FieldInfo) ([]vault.FieldInfo, error) {
+	if isSessionAuth(r) {
+		return fields, nil
+	}
+	return s.vault.TransformRead(r.Context(), consumerFromRequest(r), fields)
+}
+
+// transformBundle is transformRead for a context bundle. It returns a new
+// bundle, leaving a cached one as stored.
+func (s *Server) transformBundle(r *http.Request, b *vault.ContextBundle) (*vault.ContextBundle, error) {
+	if isSessionAuth(r) {
+		return b, nil
+	}
+	out := &vault.ContextBundle{Categories: make(map[string][]vault.FieldInfo, len(b.Categories)), Order: b.Order, Links: b.Links}
+	for cat, fields := range b.Categories {
+		transformed, err := s.transformRead(r, fields)
+		if err != nil {
+			return nil, err
+		}
+		out.Categories[cat] = transformed
+	}
+	return out, nil
+}
+
+// bundleIDs lists the fields in a context bundle.
+func bundleIDs(b *vault.ContextBundle) []string {
+	var ids []string
+	for _, fields := range b.Categories {
+		for _, f := range fields {
+			ids = append(ids, f.ID)
+		}
+	}
+	return ids
+}
+
+// bundleFields flattens b's categories, sorted by field ID.
+func bundleFields(b *vault.ContextBundle) []vault.FieldInfo {
+	var fields []vault.FieldInfo
+	for _, fs := range b.Categories {
+		fields = append(fields, fs...)
+	}
+	slices.SortFunc(fields, func(a, b vault.FieldInfo) int { return strings.Compare(a.ID, b.ID) })
+	return fields
+}
+
+// fieldScope returns the request's scope with alias patterns resolved, for
+// checking access to stored fields. Aliases are checked by their target.
+func (s *Server) fieldScope(r *http.Request) string {
+	return s.vault.ResolveScope(scopeFromRequest(r))
 }
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -19,182 +295,770 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	json.NewEncoder(w).Encode(v)
 }
 
-func writeError(w http.ResponseWriter, status int, constraint, msg string) {
-	writeJSON(w, status, map[string]string{"error": msg, "constraint": constraint})
+// jsonBody encodes v as writeJSON sends it, for reads that record their
+// size before they are sent.
+func jsonBody(v any) []byte {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(v)
+	return buf.Bytes()
+}
+
+// writeJSONBody sends a body from jsonBody.
+func writeJSONBody(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
 }
 
+// maxSessionLabel bounds the label a client gives its session.
+const maxSessionLabel = 64
+
 // POST /vault/unlock
+// Opens a new session; the vault may already be unlocked by others.
 func (s *Server) handleUnlock(w http.ResponseWriter, r *http.Request) {
 	if !s.unlockLimit.allow() {
-		writeError(w, http.StatusTooManyRequests, "rate_limited", "too many unlock attempts, try again later")
+		retry := int(s.unlockLimit.retryAfter().Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		writeErrorDetails(w, http.StatusTooManyRequests, constraintRateLimited, "too many unlock attempts, try again later",
+			errorDetails{"retry_after_seconds": retry})
 		return
 	}
 
 	var req struct {
 		Password  string `json:"password"`
 		SecretKey string `json:"secret_key"`
+		Label     string `json:"label"` // names the session in GET /vault/sessions
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON")
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 	if req.Password == "" || req.SecretKey == "" {
-		writeError(w, http.StatusBadRequest, "invalid_request", "password and secret_key required")
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "password and secret_key required",
+			errorDetails{"field": "password,secret_key"})
+		return
+	}
+
+	if len(req.Label) > maxSessionLabel {
+		invalidField(w, "label", "label too long")
 		return
 	}
 
-	token, err := s.vault.Unlock(req.Password, req.SecretKey)
+	token, err := s.vault.UnlockSession(req.Password, req.SecretKey, req.Label)
 	if err != nil {
 		switch err {
 		case vault.ErrWrongPassword:
-			writeError(w, http.StatusUnauthorized, "unauthenticated", "wrong password or secret key")
-		case vault.ErrAlreadyUnlocked:
-			writeError(w, http.StatusConflict, "conflict", "vault is already unlocked")
+			writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, "wrong password or secret key",
+				errorDetails{"reason": "wrong_credentials"})
 		case vault.ErrNotInitialized:
-			writeError(w, http.StatusPreconditionFailed, "not_initialized", "vault is not initialized")
+			writeError(w, http.StatusPreconditionFailed, constraintNotInitialized, "vault is not initialized")
 		default:
-			writeError(w, http.StatusInternalServerError, "internal", "internal error")
+			writeError(w, http.StatusInternalServerError, constraintInternal, "internal error")
 		}
 		return
 	}
+	// A session opened from this machine is bound to it and its user, so a
+	// copy of the token is refused from anywhere else.
+	if peer, ok := peerFromRequest(r); ok && peer.local {
+		s.vault.BindSession(token, peer.uid)
+	}
 
 	writeJSON(w, http.StatusOK, map[string]string{"token": token})
 }
 
-// POST /vault/lock
-func (s *Server) handleLock(w http.ResponseWriter, r *http.Request) {
+// POST /vault/lock
+func (s *Server) handleLock(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	s.vault.Lock()
+	writeJSON(w, http.StatusOK, map[string]string{"status": "locked"})
+}
+
+// GET /vault/session
+// Registered outside authMiddleware, which would reset the auto-lock timer:
+// a UI polling for its countdown must not keep the vault unlocked.
+func (s *Server) handleSessionInfo(w http.ResponseWriter, r *http.Request) {
+	token, ok := s.requestToken(w, r)
+	if !ok || (s.vault.ValidateToken(token) && !s.sessionClientAllowed(w, r, token)) {
+		return
+	}
+	s.writeSessionInfo(w, token)
+}
+
+// POST /vault/session/refresh
+// Resets the auto-lock timer (authMiddleware already has) and reports it.
+func (s *Server) handleRefreshSession(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	token := sessionTokenFromRequest(r)
+	s.writeSessionInfo(w, token)
+}
+
+func (s *Server) writeSessionInfo(w http.ResponseWriter, token string) {
+	info, err := s.vault.SessionInfo(token)
+	if err != nil {
+		writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, "invalid or expired session token",
+			errorDetails{"reason": "invalid_or_expired_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// GET /vault/sessions
+// Lists every unlocked session; "current" marks the caller's.
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	token := sessionTokenFromRequest(r)
+	var current string
+	if info, err := s.vault.SessionInfo(token); err == nil {
+		current = info.ID
+	}
+
+	type sessionInfo struct {
+		vault.SessionInfo
+		Current bool `json:"current,omitempty"`
+	}
+	sessions := s.vault.Sessions()
+	result := make([]sessionInfo, len(sessions))
+	for i, info := range sessions {
+		result[i] = sessionInfo{SessionInfo: info, Current: info.ID == current}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// DELETE /vault/sessions/{id}
+// Ends a session. Revoking the last one locks the vault.
+func (s *Server) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	id := r.PathValue("id")
+	if err := s.vault.RevokeSession(id); err != nil {
+		if err == vault.ErrSessionNotFound {
+			writeErrorDetails(w, http.StatusNotFound, constraintNotFound, err.Error(), errorDetails{"id": id})
+			return
+		}
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "revoked", "id": id})
+}
+
+// GET /vault/status
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.vault.Status()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, constraintInternal, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// GET /vault/fields[?pinned=true]
+// With ?include_out_of_scope=metadata, a token created with
+// list_out_of_scope also gets the fields beyond its scope, by ID and
+// sensitivity only.
+func (s *Server) handleListFields(w http.ResponseWriter, r *http.Request) {
+	outOfScope, ok := s.listOutOfScope(w, r)
+	if !ok {
+		return
+	}
+	fields, err := s.vault.List()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	scope, reach := s.fieldScope(r), s.fieldCheck(r)
+	pinnedOnly := r.URL.Query().Get("pinned") == "true"
+	allowed := make([]any, 0, len(fields))
+	for _, f := range fields {
+		if pinnedOnly && !f.Pinned {
+			continue
+		}
+		if !vault.ScopeAllows(scope, f.ID) {
+			if outOfScope {
+				allowed = append(allowed, outOfScopeField{ID: f.ID, Sensitivity: f.Sensitivity, OutOfScope: true})
+			}
+			continue
+		}
+		if reach(f) {
+			if isDiscovery(r) {
+				// Notes are the owner's free text, not metadata.
+				f.Note = ""
+			}
+			allowed = append(allowed, f)
+		}
+	}
+	writeJSON(w, http.StatusOK, allowed)
+}
+
+// outOfScopeField is a field a token can't read, listed so its agent knows
+// what to ask for.
+type outOfScopeField struct {
+	ID          string `json:"id"`
+	Sensitivity string `json:"sensitivity"`
+	OutOfScope  bool   `json:"out_of_scope"`
+}
+
+// listOutOfScope reports whether the request asks for out-of-scope fields
+// and its token may see them, writing an error if it asks but may not.
+func (s *Server) listOutOfScope(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	switch r.URL.Query().Get("include_out_of_scope") {
+	case "":
+		return false, true
+	case "metadata":
+	default:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "include_out_of_scope must be metadata",
+			errorDetails{"field": "include_out_of_scope", "allowed": []string{"metadata"}})
+		return false, false
+	}
+	t := serviceTokenFromRequest(r)
+	if t == nil {
+		// A session's scope is everything.
+		return false, true
+	}
+	if !vault.ListsOutOfScope(t) {
+		writeErrorDetails(w, http.StatusForbidden, constraintOutOfScopeHidden, "this token can't list fields outside its scope",
+			errorDetails{"remedy": "ask the owner for a token created with --list-out-of-scope"})
+		return false, false
+	}
+	return true, true
+}
+
+// GET /vault/fields/{id...}
+func (s *Server) handleGetField(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return
+	}
+	target := s.vault.ResolveAlias(id)
+	if !vault.ScopeAllows(s.fieldScope(r), target) {
+		s.scopeDenied(w, r, target)
+		return
+	}
+	if !s.checkFieldAccess(w, r, target) {
+		return
+	}
+	if !s.elevated(r) {
+		tier, err := s.vault.Sensitivity(id)
+		if err != nil {
+			handleVaultError(w, err)
+			return
+		}
+		if tier == "critical" {
+			elevationRequired(w, "critical_field")
+			return
+		}
+	}
+	field, err := s.vault.Get(id)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	if field == nil {
+		writeErrorDetails(w, http.StatusNotFound, constraintNotFound, "field not found", errorDetails{"id": id})
+		return
+	}
+	if err := s.authorizeRead(r, "read", []vault.FieldInfo{*field}); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	transformed, err := s.transformRead(r, []vault.FieldInfo{*field})
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	transformed, err = s.signRead(r, transformed)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	body := jsonBody(transformed[0])
+	s.logConsumerRead(r, target, []string{target}, len(body))
+	s.logReveal(r, target, transformed)
+	s.tripCanaries(r, target)
+	writeJSONBody(w, http.StatusOK, body)
+}
+
+// PUT /vault/fields/{id...}
+func (s *Server) handleSetField(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return
+	}
+	target := s.vault.ResolveAlias(id)
+	if !vault.ScopeAllows(s.fieldScope(r), target) {
+		s.scopeDenied(w, r, target)
+		return
+	}
+	if !s.checkFieldAccess(w, r, target) || !s.appendOnlyAllows(w, r, target) {
+		return
+	}
+	var req struct {
+		Value       string             `json:"value"`
+		Entries     []vault.ValueEntry `json:"entries"` // instead of value, for a multi-valued field
+		Sensitivity string             `json:"sensitivity"`
+		Raw         bool               `json:"raw"`  // skip normalization
+		Type        string             `json:"type"` // value type, set with the value
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Type != "" && !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if req.Entries != nil {
+		if req.Value != "" {
+			invalidField(w, "value", "give value or entries, not both")
+			return
+		}
+		list, err := vault.EncodeEntries(req.Entries)
+		if err != nil {
+			handleVaultError(w, err)
+			return
+		}
+		req.Value = list
+	}
+	if strings.TrimSpace(req.Value) == "" {
+		invalidField(w, "value", "value required")
+		return
+	}
+
+	// Apply schema default sensitivity when none provided
+	if req.Sensitivity == "" {
+		req.Sensitivity = vault.DefaultSensitivity(target)
+	}
+	if !s.trustCheck(r)(req.Sensitivity) {
+		s.trustDenied(w, r, target, req.Sensitivity)
+		return
+	}
+
+	stored, err := s.vault.SetWithOptions(id, req.Value, vault.SetOptions{Sensitivity: req.Sensitivity, Raw: req.Raw, Type: req.Type})
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+
+	resp := map[string]any{"status": "ok"}
+	if stored != req.Value {
+		if entries, ok := vault.DecodeEntries(stored); ok {
+			resp["normalized_entries"] = entries
+		} else {
+			resp["normalized"] = stored
+		}
+	}
+	if suggestion := vault.SuggestCanonical(id); suggestion != nil {
+		resp["suggestion"] = suggestion
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// maxTxPurpose bounds the purpose a client records on a transaction.
+const maxTxPurpose = 64
+
+// POST /vault/transactions
+func (s *Server) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Ops     []vault.TxOp `json:"ops"`
+		Purpose string       `json:"purpose"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Ops) == 0 {
+		invalidField(w, "ops", "at least one operation required")
+		return
+	}
+	if len(req.Purpose) > maxTxPurpose {
+		invalidField(w, "purpose", "purpose too long")
+		return
+	}
+	scope, trust := s.fieldScope(r), s.trustCheck(r)
+	for i, op := range req.Ops {
+		field := "ops[" + strconv.Itoa(i) + "]"
+		if op.Op != vault.TxSet && op.Op != vault.TxDelete {
+			writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, vault.ErrInvalidTxOp.Error(), errorDetails{
+				"field":   field + ".op",
+				"allowed": []string{vault.TxSet, vault.TxDelete},
+			})
+			return
+		}
+		if err := vault.ValidateFieldID(op.ID); err != nil {
+			invalidField(w, field+".id", err.Error())
+			return
+		}
+		if op.Op == vault.TxSet && strings.TrimSpace(op.Value) == "" {
+			invalidField(w, field+".value", "value required")
+			return
+		}
+		// One out-of-scope field refuses the whole transaction.
+		target := s.vault.ResolveAlias(op.ID)
+		if !vault.ScopeAllows(scope, target) {
+			s.scopeDenied(w, r, target)
+			return
+		}
+		if !s.checkFieldAccess(w, r, target) || !s.appendOnlyAllows(w, r, target) {
+			return
+		}
+		if op.Op == vault.TxSet {
+			tier := op.Sensitivity
+			if tier == "" {
+				tier = vault.DefaultSensitivity(target)
+			}
+			if !trust(tier) {
+				s.trustDenied(w, r, target, tier)
+				return
+			}
+		}
+	}
+
+	group, results, err := s.vault.Apply(req.Ops, requestIDFromRequest(r), req.Purpose)
+	if err != nil {
+		var opErr *vault.TxOpError
+		if errors.As(err, &opErr) && opErr.Err == vault.ErrInvalidTier {
+			writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, opErr.Error(), errorDetails{
+				"field":   "ops[" + strconv.Itoa(opErr.Index) + "].sensitivity",
+				"allowed": []string{"public", "standard", "sensitive", "critical"},
+			})
+			return
+		}
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "transaction_id": group, "results": results})
+}
+
+// GET /vault/schema?lang=de
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	schema, ok := requestSchema(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, schema)
+}
+
+// requestSchema returns the schema in the language the request asks for
+// with ?lang= or Accept-Language, or else in English. On an unsupported
+// ?lang= it writes the error and returns false.
+func requestSchema(w http.ResponseWriter, r *http.Request) (vault.Schema, bool) {
+	if v := r.URL.Query().Get("lang"); v != "" {
+		lang := i18n.Match(v)
+		if lang == "" {
+			writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "unsupported language", errorDetails{
+				"field":   "lang",
+				"allowed": i18n.Supported,
+			})
+			return vault.Schema{}, false
+		}
+		return vault.LocalizedSchema(lang), true
+	}
+	if lang := acceptLanguage(r); lang != "" {
+		return vault.LocalizedSchema(lang), true
+	}
+	return vault.RecommendedSchema, true
+}
+
+// acceptLanguage returns the first supported language in the request's
+// Accept-Language header, ignoring quality weights, or "".
+func acceptLanguage(r *http.Request) string {
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if lang := i18n.Match(tag); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+// DELETE /vault/fields/{id...}
+func (s *Server) handleDeleteField(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return
+	}
+	target := s.vault.ResolveAlias(id)
+	if !vault.ScopeAllows(s.fieldScope(r), target) {
+		s.scopeDenied(w, r, target)
+		return
+	}
+	if !s.checkFieldAccess(w, r, target) || !s.appendOnlyAllows(w, r, target) {
+		return
+	}
+	if err := s.vault.Delete(id); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// GET /vault/history/{id...}
+func (s *Server) handleFieldHistory(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return
+	}
+	if !s.elevated(r) {
+		tier, err := s.vault.Sensitivity(id)
+		if err != nil {
+			handleVaultError(w, err)
+			return
+		}
+		if tier == "critical" {
+			elevationRequired(w, "critical_field")
+			return
+		}
+	}
+	history, err := s.vault.History(id)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "history": history})
+}
+
+// GET /vault/verify
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	report, err := s.vault.Verify()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// POST /vault/compact
+// Rewrites the database without its free space and reports its size before
+// and after.
+func (s *Server) handleCompact(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	res, err := s.vault.Compact(0)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// GET /vault/aliases
+func (s *Server) handleListAliases(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	aliases, err := s.vault.Aliases()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"aliases": aliases})
+}
+
+// PUT /vault/aliases/{alias...}
+func (s *Server) handleSetAlias(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	alias := r.PathValue("alias")
+	if err := vault.ValidateFieldID(alias); err != nil {
+		invalidField(w, "alias", err.Error())
+		return
+	}
+	var req struct {
+		Target string `json:"target"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := vault.ValidateFieldID(req.Target); err != nil {
+		invalidField(w, "target", err.Error())
+		return
+	}
+	if err := s.vault.SetAlias(alias, req.Target); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DELETE /vault/aliases/{alias...}
+func (s *Server) handleDeleteAlias(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if err := s.vault.DeleteAlias(r.PathValue("alias")); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// GET /vault/canaries
+func (s *Server) handleListCanaries(w http.ResponseWriter, r *http.Request) {
 	if !isSessionAuth(r) {
 		sessionRequired(w)
 		return
 	}
-	s.vault.Lock()
-	writeJSON(w, http.StatusOK, map[string]string{"status": "locked"})
-}
-
-// GET /vault/status
-func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
-	status, err := s.vault.Status()
+	canaries, err := s.vault.Canaries()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal", "internal error")
+		handleVaultError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, status)
+	writeJSON(w, http.StatusOK, map[string]any{"canaries": canaries})
 }
 
-// GET /vault/fields
-func (s *Server) handleListFields(w http.ResponseWriter, r *http.Request) {
-	fields, err := s.vault.List()
-	if err != nil {
-		handleVaultError(w, err)
+// PUT /vault/canaries/{id...}
+// Stores a decoy value, generated unless given, and returns it.
+func (s *Server) handleCreateCanary(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
 		return
 	}
-	scope := scopeFromRequest(r)
-	allowed := make([]vault.FieldInfo, 0, len(fields))
-	for _, f := range fields {
-		if vault.ScopeAllows(scope, f.ID) {
-			allowed = append(allowed, f)
-		}
-	}
-	writeJSON(w, http.StatusOK, allowed)
-}
-
-// GET /vault/fields/{id...}
-func (s *Server) handleGetField(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if err := vault.ValidateFieldID(id); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		invalidField(w, "id", err.Error())
 		return
 	}
-	if !vault.ScopeAllows(scopeFromRequest(r), id) {
-		scopeDenied(w)
+	var req struct {
+		Value       string `json:"value"`
+		Sensitivity string `json:"sensitivity"`
+		Revoke      bool   `json:"revoke"`
+	}
+	if !decodeJSON(w, r, &req) {
 		return
 	}
-	field, err := s.vault.Get(id)
+	value, err := s.vault.CreateCanary(id, req.Value, req.Sensitivity, req.Revoke)
 	if err != nil {
 		handleVaultError(w, err)
 		return
 	}
-	if field == nil {
-		writeError(w, http.StatusNotFound, "not_found", "field not found")
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "value": value, "revoke": req.Revoke})
+}
+
+// DELETE /vault/canaries/{id...}
+func (s *Server) handleDeleteCanary(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if err := s.vault.DeleteCanary(r.PathValue("id")); err != nil {
+		handleVaultError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, field)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
 
-// PUT /vault/fields/{id...}
-func (s *Server) handleSetField(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	if err := vault.ValidateFieldID(id); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+// POST /vault/enrich/address
+func (s *Server) handleEnrichAddress(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
 		return
 	}
-	if !vault.ScopeAllows(scopeFromRequest(r), id) {
-		scopeDenied(w)
+	var req struct {
+		Prefix string `json:"prefix"` // addresses.<prefix>_*, default "home"
+	}
+	if r.ContentLength != 0 && !decodeJSON(w, r, &req) {
 		return
 	}
-	var req struct {
-		Value       string `json:"value"`
-		Sensitivity string `json:"sensitivity"`
+	if req.Prefix == "" {
+		req.Prefix = "home"
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON")
+	if err := vault.ValidateFieldID("addresses." + req.Prefix + "_street"); err != nil {
+		invalidField(w, "prefix", err.Error())
 		return
 	}
-	if req.Value == "" {
-		writeError(w, http.StatusBadRequest, "invalid_request", "value required")
+	suggestions, err := s.vault.EnrichAddress(r.Context(), req.Prefix)
+	switch err {
+	case nil:
+	case vault.ErrNoEnricher, vault.ErrLocked:
+		handleVaultError(w, err)
+		return
+	default:
+		writeError(w, http.StatusBadGateway, constraintInternal, "address enricher failed")
 		return
 	}
+	writeJSON(w, http.StatusOK, map[string]any{"suggestions": suggestions})
+}
 
-	// Apply schema default sensitivity when none provided
-	if req.Sensitivity == "" {
-		req.Sensitivity = vault.DefaultSensitivity(id)
+// GET /vault/suggestions
+func (s *Server) handleListSuggestions(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
 	}
-
-	if err := s.vault.Set(id, req.Value, req.Sensitivity); err != nil {
+	suggestions, err := s.vault.Suggestions()
+	if err != nil {
 		handleVaultError(w, err)
 		return
 	}
-
-	resp := map[string]any{"status": "ok"}
-	if suggestion := vault.SuggestCanonical(id); suggestion != nil {
-		resp["suggestion"] = suggestion
-	}
-	writeJSON(w, http.StatusOK, resp)
-}
-
-// GET /vault/schema
-func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, vault.RecommendedSchema)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"enricher":    s.vault.HasEnricher(),
+		"suggestions": suggestions,
+	})
 }
 
-// DELETE /vault/fields/{id...}
-func (s *Server) handleDeleteField(w http.ResponseWriter, r *http.Request) {
+// POST /vault/suggestions/{id}/accept
+func (s *Server) handleAcceptSuggestion(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
 	id := r.PathValue("id")
-	if err := vault.ValidateFieldID(id); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+	stored, err := s.vault.AcceptSuggestion(id)
+	if err != nil {
+		handleVaultError(w, err)
 		return
 	}
-	if !vault.ScopeAllows(scopeFromRequest(r), id) {
-		scopeDenied(w)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "value": stored})
+}
+
+// DELETE /vault/suggestions/{id}
+func (s *Server) handleDismissSuggestion(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
 		return
 	}
-	if err := s.vault.Delete(id); err != nil {
+	if err := s.vault.DismissSuggestion(r.PathValue("id")); err != nil {
 		handleVaultError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+	writeJSON(w, http.StatusOK, map[string]string{"status": "dismissed"})
 }
 
 // GET /vault/fields/category/{category}
 func (s *Server) handleGetByCategory(w http.ResponseWriter, r *http.Request) {
 	category := r.PathValue("category")
 	if !vault.ValidCategoryName(category) {
-		writeError(w, http.StatusBadRequest, "invalid_request", "invalid category name: only alphanumeric, underscore, hyphen allowed")
+		invalidField(w, "category", "invalid category name: only alphanumeric, underscore, hyphen allowed")
 		return
 	}
-	scope := scopeFromRequest(r)
+	scope := s.fieldScope(r)
 	if !vault.ScopeAllowsCategory(scope, category) {
-		scopeDenied(w)
+		s.scopeDenied(w, r, category+".*")
 		return
 	}
 	fields, err := s.vault.GetByCategory(category)
@@ -203,35 +1067,205 @@ func (s *Server) handleGetByCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// Filter to only fields allowed by scope (handles exact field patterns)
+	// and the access lists and trust level
+	reach := s.fieldCheck(r)
 	allowed := make([]vault.FieldInfo, 0, len(fields))
+	ids := make([]string, 0, len(fields))
 	for _, f := range fields {
-		if vault.ScopeAllows(scope, f.ID) {
+		if vault.ScopeAllows(scope, f.ID) && reach(f) {
 			allowed = append(allowed, f)
+			ids = append(ids, f.ID)
 		}
 	}
-	writeJSON(w, http.StatusOK, allowed)
+	if hasCritical(allowed) && !s.elevated(r) {
+		elevationRequired(w, "critical_field")
+		return
+	}
+	if err := s.authorizeRead(r, "category", allowed); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	allowed, err = s.transformRead(r, allowed)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	allowed, err = s.signRead(r, allowed)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	body := jsonBody(allowed)
+	if len(ids) > 0 {
+		s.logConsumerRead(r, strings.Join(ids, ","), ids, len(body))
+		s.logReveal(r, category+".*", allowed)
+		s.tripCanaries(r, ids...)
+	}
+	writeJSONBody(w, http.StatusOK, body)
 }
 
 // GET /vault/context
 func (s *Server) handleGetContext(w http.ResponseWriter, r *http.Request) {
-	ctx, err := s.vault.GetContext()
-	if err != nil {
-		handleVaultError(w, err)
+	ctx, ok := s.readContext(w, r)
+	if !ok {
 		return
 	}
+	body := jsonBody(ctx)
+	s.logConsumerRead(r, scopeFromRequest(r), bundleIDs(ctx), len(body))
+	for _, fields := range ctx.Categories {
+		s.logReveal(r, "*", fields)
+	}
+	writeJSONBody(w, http.StatusOK, body)
+}
+
+// readContext returns the fields the caller's scope covers, as
+// /vault/context sends them; the caller records the read once it knows what
+// it sends. On failure it writes the error and returns false.
+func (s *Server) readContext(w http.ResponseWriter, r *http.Request) (*vault.ContextBundle, bool) {
 	scope := scopeFromRequest(r)
+	gen := s.vault.Generation()
+	ctx, cached := s.contextCache.get(gen, scope)
+	if cached {
+		// Cache hits still require an unlocked vault and still leave an audit trail.
+		if err := s.vault.CheckUnlocked(); err != nil {
+			s.contextCache.invalidate()
+			handleVaultError(w, err)
+			return nil, false
+		}
+	} else {
+		var err error
+		if ctx, err = s.loadContext(gen, scope); err != nil {
+			handleVaultError(w, err)
+			return nil, false
+		}
+	}
+	ctx, err := s.applyTemplate(r, s.accessFilter(r, ctx))
+	if err != nil {
+		handleVaultError(w, err)
+		return nil, false
+	}
+	ctx = s.vault.WithLinks(ctx)
+	if !s.elevated(r) && bundleHasCritical(ctx) {
+		elevationRequired(w, "export")
+		return nil, false
+	}
+	if err := s.authorizeRead(r, "context", bundleFields(ctx)); err != nil {
+		handleVaultError(w, err)
+		return nil, false
+	}
+	transformed, err := s.transformBundle(r, ctx)
+	if err != nil {
+		handleVaultError(w, err)
+		return nil, false
+	}
+	if cached {
+		s.vault.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "context"})
+	}
+	s.tripCanaries(r, bundleIDs(ctx)...)
+	return transformed, true
+}
+
+// loadContext decrypts the fields scope covers and caches them as of
+// generation gen.
+func (s *Server) loadContext(gen uint64, scope string) (*vault.ContextBundle, error) {
+	ctx, err := s.vault.GetContext()
+	if err != nil {
+		return nil, err
+	}
 	if scope != "*" {
+		resolved := s.vault.ResolveScope(scope)
 		filtered := &vault.ContextBundle{Categories: make(map[string][]vault.FieldInfo)}
 		for cat, fields := range ctx.Categories {
 			for _, f := range fields {
-				if vault.ScopeAllows(scope, f.ID) {
+				if vault.ScopeAllows(resolved, f.ID) {
 					filtered.Categories[cat] = append(filtered.Categories[cat], f)
 				}
 			}
 		}
 		ctx = filtered
 	}
-	writeJSON(w, http.StatusOK, ctx)
+	s.contextCache.put(gen, scope, ctx)
+	return ctx, nil
+}
+
+// GET /vault/context/digest
+// Returns hashes of what /vault/context would return for the caller's
+// scope, per category and overall, for polling cheaply before pulling the
+// bundle. The overall hash is also the ETag, so a client can send
+// If-None-Match and get a bodiless 304. No values leave the vault, so
+// critical fields need no step-up, canaries aren't tripped, and polls aren't
+// audited.
+func (s *Server) handleContextDigest(w http.ResponseWriter, r *http.Request) {
+	scope := scopeFromRequest(r)
+	gen := s.vault.Generation()
+	ctx, ok := s.contextCache.get(gen, scope)
+	if ok {
+		if err := s.vault.CheckUnlocked(); err != nil {
+			s.contextCache.invalidate()
+			handleVaultError(w, err)
+			return
+		}
+	} else {
+		var err error
+		if ctx, err = s.loadContext(gen, scope); err != nil {
+			handleVaultError(w, err)
+			return
+		}
+	}
+	ctx, err := s.applyTemplate(r, s.accessFilter(r, ctx))
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	digest, err := s.vault.Digest(s.vault.WithLinks(ctx))
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	etag := `"` + digest.Digest + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, digest)
+}
+
+// GET /vault/context/prompt?persona=assistant
+// Returns what /vault/context would, written as a system-prompt block to put
+// ahead of an agent's own instructions, with the schema's descriptions (in
+// the language asked for, as with /vault/schema) and each value marked with
+// the field it came from.
+func (s *Server) handleContextPrompt(w http.ResponseWriter, r *http.Request) {
+	persona := r.URL.Query().Get("persona")
+	if persona == "" {
+		persona = vault.DefaultPersona
+	}
+	if !slices.Contains(vault.Personas, persona) {
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, vault.ErrUnknownPersona.Error(), errorDetails{
+			"field":   "persona",
+			"allowed": vault.Personas,
+		})
+		return
+	}
+	schema, ok := requestSchema(w, r)
+	if !ok {
+		return
+	}
+	ctx, ok := s.readContext(w, r)
+	if !ok {
+		return
+	}
+	prompt, err := vault.RenderPrompt(ctx, persona, schema, time.Now())
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	s.logConsumerRead(r, scopeFromRequest(r), bundleIDs(ctx), len(prompt))
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(prompt))
 }
 
 // GET /vault/audit
@@ -253,7 +1287,7 @@ func (s *Server) handleAuditLog(w http.ResponseWriter, r *http.Request) {
 
 	entries, err := s.vault.AuditLog(limit)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal", "internal error")
+		writeError(w, http.StatusInternalServerError, constraintInternal, "internal error")
 		return
 	}
 	writeJSON(w, http.StatusOK, entries)
@@ -263,22 +1297,29 @@ func (s *Server) handleAuditLog(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleSetSensitivity(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if err := vault.ValidateFieldID(id); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		invalidField(w, "id", err.Error())
+		return
+	}
+	target := s.vault.ResolveAlias(id)
+	if !vault.ScopeAllows(s.fieldScope(r), target) {
+		s.scopeDenied(w, r, target)
 		return
 	}
-	if !vault.ScopeAllows(scopeFromRequest(r), id) {
-		scopeDenied(w)
+	if !s.checkFieldAccess(w, r, target) || !s.appendOnlyAllows(w, r, target) {
 		return
 	}
 	var req struct {
 		Tier string `json:"tier"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON")
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 	if req.Tier == "" {
-		writeError(w, http.StatusBadRequest, "invalid_request", "tier required")
+		invalidField(w, "tier", "tier required")
+		return
+	}
+	if !s.trustCheck(r)(req.Tier) {
+		s.trustDenied(w, r, target, req.Tier)
 		return
 	}
 
@@ -296,33 +1337,67 @@ func (s *Server) handleCreateServiceToken(w http.ResponseWriter, r *http.Request
 		return
 	}
 	var req struct {
-		Consumer string `json:"consumer"`
-		Scope    string `json:"scope"`
-		TTL      string `json:"ttl"`
+		Consumer    string                 `json:"consumer"`
+		Scope       string                 `json:"scope"`
+		TTL         string                 `json:"ttl"`
+		Constraints vault.TokenConstraints `json:"constraints"`
+		Usage       string                 `json:"usage"` // "service" (default) or "discovery"
+		Description string                 `json:"description"`
+		Labels      map[string]string      `json:"labels"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON")
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 	if req.Consumer == "" {
-		writeError(w, http.StatusBadRequest, "invalid_request", "consumer required")
+		invalidField(w, "consumer", "consumer required")
+		return
+	}
+	discovery := req.Usage == vault.TokenUsageDiscovery
+	if !discovery && req.Usage != "" && req.Usage != "service" {
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "usage must be service or discovery",
+			errorDetails{"field": "usage", "allowed": []string{"service", vault.TokenUsageDiscovery}})
 		return
 	}
 	if req.Scope == "" {
 		req.Scope = "*"
 	}
+	// A discovery token reads no values, so seeing every field's name
+	// needs no elevation.
+	if vault.ScopeIsWildcard(req.Scope) && !discovery && !s.elevated(r) {
+		elevationRequired(w, "wildcard_scope")
+		return
+	}
 
 	ttl := 365 * 24 * time.Hour // default 1 year
 	if req.TTL != "" {
 		parsed, err := time.ParseDuration(req.TTL)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_request", "invalid ttl duration")
+			invalidField(w, "ttl", "invalid ttl duration")
 			return
 		}
 		ttl = parsed
 	}
 
-	token, err := s.vault.CreateServiceToken(req.Consumer, req.Scope, ttl)
+	if err := req.Constraints.Validate(); err != nil {
+		invalidField(w, "constraints", err.Error())
+		return
+	}
+	meta := vault.TokenMetadata{Description: req.Description}
+	if err := meta.Validate(); err != nil {
+		invalidField(w, "description", err.Error())
+		return
+	}
+	meta.Labels = req.Labels
+	if err := meta.Validate(); err != nil {
+		invalidField(w, "labels", err.Error())
+		return
+	}
+
+	create := s.vault.CreateRestrictedServiceToken
+	if discovery {
+		create = s.vault.CreateDiscoveryToken
+	}
+	token, err := create(req.Consumer, req.Scope, ttl, req.Constraints, meta)
 	if err != nil {
 		handleVaultError(w, err)
 		return
@@ -334,44 +1409,182 @@ func (s *Server) handleCreateServiceToken(w http.ResponseWriter, r *http.Request
 	})
 }
 
-// GET /vault/tokens/service
+// POST /vault/tokens/service/preview
+// Expands a scope into the fields it would grant, for review before a token is created.
+func (s *Server) handlePreviewScope(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	var req struct {
+		Scope string `json:"scope"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = "*"
+	}
+
+	fields, err := s.vault.ExpandScope(req.Scope)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	if fields == nil {
+		fields = []vault.ScopeField{}
+	}
+	bySensitivity := make(map[string]int)
+	for _, f := range fields {
+		bySensitivity[f.Sensitivity]++
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"scope":          req.Scope,
+		"all_fields":     vault.ScopeIsWildcard(req.Scope),
+		"fields":         fields,
+		"by_sensitivity": bySensitivity,
+	})
+}
+
+// GET /vault/tokens/service?label=env=prod&expiring_days=30
+// With expiring_days, lists only the tokens expiring within that many days,
+// soonest first, leaving out delegated ones.
 func (s *Server) handleListServiceTokens(w http.ResponseWriter, r *http.Request) {
 	if !isSessionAuth(r) {
 		sessionRequired(w)
 		return
 	}
-	tokens, err := s.vault.ListServiceTokens()
+	sel, err := vault.ParseLabelSelector(r.URL.Query()["label"])
+	if err != nil {
+		invalidField(w, "label", err.Error())
+		return
+	}
+	list := s.vault.ListServiceTokens
+	if v := r.URL.Query().Get("expiring_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 1 || days > maxExpiringDays {
+			writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "expiring_days must be between 1 and "+strconv.Itoa(maxExpiringDays),
+				errorDetails{"field": "expiring_days"})
+			return
+		}
+		list = func() ([]store.Token, error) {
+			return s.vault.ExpiringServiceTokens(time.Now(), time.Duration(days)*24*time.Hour)
+		}
+	}
+	tokens, err := list()
 	if err != nil {
 		handleVaultError(w, err)
 		return
 	}
 
 	type tokenInfo struct {
-		TokenPrefix string `json:"token_prefix"`
-		Consumer    string `json:"consumer"`
-		Scope       string `json:"scope"`
-		ExpiresAt   string `json:"expires_at"`
-		CreatedAt   string `json:"created_at"`
+		TokenPrefix string                  `json:"token_prefix"`
+		Consumer    string                  `json:"consumer"`
+		Scope       string                  `json:"scope"`
+		Usage       string                  `json:"usage"`
+		ExpiresAt   string                  `json:"expires_at"`
+		CreatedAt   string                  `json:"created_at"`
+		Constraints *vault.TokenConstraints `json:"constraints,omitempty"`
+		Parent      string                  `json:"parent,omitempty"` // prefix of the token it was delegated from
+		Description string                  `json:"description,omitempty"`
+		Labels      map[string]string       `json:"labels,omitempty"`
 	}
 
-	result := make([]tokenInfo, len(tokens))
-	for i, t := range tokens {
+	result := make([]tokenInfo, 0, len(tokens))
+	for _, t := range tokens {
+		labels := vault.TokenLabels(&t)
+		if !vault.MatchLabels(labels, sel) {
+			continue
+		}
 		// TokenStr is now a hash; show first 8 chars for identification
 		hashPrefix := t.TokenStr
 		if len(hashPrefix) > 8 {
 			hashPrefix = hashPrefix[:8] + "..."
 		}
-		result[i] = tokenInfo{
+		info := tokenInfo{
 			TokenPrefix: hashPrefix,
 			Consumer:    t.Consumer,
 			Scope:       t.Scope,
+			Usage:       t.Usage,
 			ExpiresAt:   t.ExpiresAt.UTC().Format(time.RFC3339),
 			CreatedAt:   t.CreatedAt.UTC().Format(time.RFC3339),
+			Description: t.Description,
+			Labels:      labels,
+		}
+		if c, err := vault.ParseTokenConstraints(t.Constraints); err == nil && !c.IsZero() {
+			info.Constraints = &c
+		}
+		if len(t.Parent) > 8 {
+			info.Parent = t.Parent[:8] + "..."
 		}
+		result = append(result, info)
 	}
 	writeJSON(w, http.StatusOK, result)
 }
 
+// POST /vault/tokens/delegate
+// Mints a narrower, shorter-lived child of the calling service token.
+func (s *Server) handleDelegateToken(w http.ResponseWriter, r *http.Request) {
+	parent := serviceTokenFromRequest(r)
+	if parent == nil {
+		writeErrorDetails(w, http.StatusForbidden, constraintServiceRequired, "delegation requires a service token; session tokens create tokens with POST /vault/tokens/service",
+			errorDetails{"required_auth": "service", "token_type": "session"})
+		return
+	}
+	var req struct {
+		Consumer string `json:"consumer"`
+		Scope    string `json:"scope"`
+		TTL      string `json:"ttl"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Consumer == "" {
+		invalidField(w, "consumer", "consumer required")
+		return
+	}
+	if req.Scope == "" {
+		invalidField(w, "scope", "scope required")
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed <= 0 {
+			invalidField(w, "ttl", "invalid ttl duration")
+			return
+		}
+		ttl = parsed
+	}
+
+	token, expires, err := s.vault.DelegateServiceToken(parent, req.Consumer, req.Scope, ttl)
+	if err == vault.ErrScopeNotSubset {
+		s.vault.LogAccess(store.AuditEntry{
+			Consumer:  parent.Consumer,
+			Scope:     req.Scope,
+			Action:    "denied",
+			Purpose:   "scope_exceeded",
+			RequestID: requestIDFromRequest(r),
+		})
+		writeErrorDetails(w, http.StatusForbidden, constraintScopeExceeded, err.Error(), errorDetails{
+			"required_scope": req.Scope,
+			"token_scope":    parent.Scope,
+			"remedy":         "delegate a scope within token_scope",
+		})
+		return
+	}
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"token":      token,
+		"scope":      req.Scope,
+		"expires_at": expires.UTC().Format(time.RFC3339),
+	})
+}
+
 // DELETE /vault/tokens/service/{token}
 func (s *Server) handleRevokeServiceToken(w http.ResponseWriter, r *http.Request) {
 	if !isSessionAuth(r) {
@@ -380,7 +1593,7 @@ func (s *Server) handleRevokeServiceToken(w http.ResponseWriter, r *http.Request
 	}
 	prefix := r.PathValue("token")
 	if prefix == "" {
-		writeError(w, http.StatusBadRequest, "invalid_request", "token prefix required")
+		invalidField(w, "token", "token prefix required")
 		return
 	}
 
@@ -390,23 +1603,118 @@ func (s *Server) handleRevokeServiceToken(w http.ResponseWriter, r *http.Request
 		return
 	}
 	if n == 0 {
-		writeError(w, http.StatusNotFound, "not_found", "no matching token found")
+		writeErrorDetails(w, http.StatusNotFound, constraintNotFound, "no matching token found", errorDetails{"id": prefix})
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"status": "revoked", "count": n})
 }
 
 func handleVaultError(w http.ResponseWriter, err error) {
+	var corrupt *vault.CorruptFieldError
+	if errors.As(err, &corrupt) {
+		writeErrorDetails(w, http.StatusInternalServerError, constraintCorrupted, "stored value is corrupted", errorDetails{
+			"id":     corrupt.ID,
+			"remedy": "set the field again or restore it from a backup",
+		})
+		return
+	}
+	var rejected *vault.PluginRejectedError
+	if errors.As(err, &rejected) {
+		writeErrorDetails(w, http.StatusUnprocessableEntity, constraintPluginRejected, err.Error(), errorDetails{
+			"plugin": rejected.Plugin,
+			"field":  rejected.Field,
+			"reason": rejected.Reason,
+		})
+		return
+	}
+	var exceeded *vault.BudgetExceededError
+	if errors.As(err, &exceeded) {
+		writeErrorDetails(w, http.StatusForbidden, constraintBudgetExceeded, err.Error(),
+			errorDetails{"id": exceeded.ID, "critical_per_day": exceeded.Limit})
+		return
+	}
+	var pluginErr *vault.PluginError
+	if errors.As(err, &pluginErr) {
+		writeErrorDetails(w, http.StatusBadGateway, constraintPluginFailed, "plugin failed",
+			errorDetails{"plugin": pluginErr.Plugin})
+		return
+	}
+	if errors.Is(err, vault.ErrKeyMismatch) {
+		writeErrorDetails(w, http.StatusInternalServerError, constraintCorrupted, err.Error(),
+			errorDetails{"remedy": "run 'pvault verify'"})
+		return
+	}
 	switch err {
 	case vault.ErrLocked:
-		writeError(w, http.StatusForbidden, "vault_locked", "vault is locked")
-	case vault.ErrAlreadyUnlocked:
-		writeError(w, http.StatusConflict, "conflict", "vault is already unlocked")
+		writeErrorDetails(w, http.StatusForbidden, constraintVaultLocked, "vault is locked",
+			errorDetails{"remedy": "unlock the vault with 'pvault unlock'"})
 	case vault.ErrNotInitialized:
-		writeError(w, http.StatusPreconditionFailed, "not_initialized", "vault is not initialized")
+		writeErrorDetails(w, http.StatusPreconditionFailed, constraintNotInitialized, "vault is not initialized",
+			errorDetails{"remedy": "create a vault with 'pvault init'"})
+	case vault.ErrAliasConflict, vault.ErrAliasChain, vault.ErrCanaryExists:
+		writeError(w, http.StatusConflict, constraintConflict, err.Error())
+	case vault.ErrAliasNotFound, vault.ErrCanaryNotFound, vault.ErrACLNotFound, vault.ErrConsumerNotFound,
+		vault.ErrTemplateNotFound, vault.ErrPinMissing, vault.ErrNotPinned, vault.ErrNoteMissing, vault.ErrEntryNotFound,
+		vault.ErrLinkMissing, vault.ErrLinkNotFound, vault.ErrNotAppendOnly, vault.ErrValueTypeMissing:
+		writeError(w, http.StatusNotFound, constraintNotFound, err.Error())
+	case vault.ErrNoEnricher:
+		writeErrorDetails(w, http.StatusConflict, constraintConflict, err.Error(),
+			errorDetails{"remedy": "set VAULT_ENRICH_URL or VAULT_ENRICH_CMD and run 'pvault unlock' again"})
+	case vault.ErrSuggestionNotFound, vault.ErrSnapshotNotFound:
+		writeError(w, http.StatusNotFound, constraintNotFound, err.Error())
+	case vault.ErrSnapshotLimit:
+		writeError(w, http.StatusConflict, constraintConflict, err.Error())
+	case vault.ErrInvalidConsumer:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"field": "consumer"})
+	case vault.ErrAuthorizationDenied, vault.ErrAuthorizationTimeout, vault.ErrAuthorizationFailed:
+		reason := map[error]string{
+			vault.ErrAuthorizationDenied:  "denied",
+			vault.ErrAuthorizationTimeout: "timeout",
+			vault.ErrAuthorizationFailed:  "unavailable",
+		}[err]
+		writeErrorDetails(w, http.StatusForbidden, constraintAuthorizeDenied, err.Error(), errorDetails{"reason": reason})
+	case vault.ErrNoEntries, vault.ErrEmptyEntry, vault.ErrDuplicateLabel, vault.ErrManyPrimary:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"field": "entries"})
+	case vault.ErrLastEntry, vault.ErrLinkExists:
+		writeError(w, http.StatusConflict, constraintConflict, err.Error())
+	case vault.ErrInvalidLinkType:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"field": "type"})
+	case vault.ErrSelfLink:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"field": "to"})
+	case vault.ErrInvalidCategory:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"field": "category"})
+	case vault.ErrNoteTooLong:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{
+			"field": "note",
+			"max":   vault.MaxNoteLength,
+		})
+	case vault.ErrInvalidValueType:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{
+			"field":   "type",
+			"allowed": vault.ValueTypes,
+		})
+	case vault.ErrValueTypeMismatch:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"field": "type"})
+	case vault.ErrInvalidTemplate:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"field": "fields"})
+	case vault.ErrInvalidMask:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{
+			"field":   "mask",
+			"allowed": vault.MaskRules,
+		})
+	case vault.ErrInvalidTrust:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{
+			"field":   "trust",
+			"allowed": vault.TrustLevels,
+		})
+	case vault.ErrSnapshotTTL:
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"field": "ttl"})
 	case vault.ErrInvalidTier:
-		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{
+			"field":   "tier",
+			"allowed": []string{"public", "standard", "sensitive", "critical"},
+		})
 	default:
-		writeError(w, http.StatusInternalServerError, "internal", "internal error")
+		writeError(w, http.StatusInternalServerError, constraintInternal, "internal error")
 	}
 }
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// probePaths are the supervisor endpoints, logged only when they fail.
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// GET /healthz
+// Liveness: the process is up and serving. Nothing else is checked, so a
+// supervisor restarts the server only when it has stopped responding.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// GET /readyz[?unlocked=true]
+// Readiness: the database answers and the vault is initialized, and with
+// unlocked=true, that some session has it unlocked. Failures are 503 with a
+// reason: database_unreachable, not_initialized, or locked.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	requireUnlocked, _ := strconv.ParseBool(r.URL.Query().Get("unlocked"))
+	status, err := s.vault.Status()
+	switch {
+	case err != nil:
+		notReady(w, "database_unreachable")
+	case !status.Initialized:
+		notReady(w, "not_initialized")
+	case requireUnlocked && status.Locked:
+		notReady(w, "locked")
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ready", "locked": status.Locked})
+	}
+}
+
+func notReady(w http.ResponseWriter, reason string) {
+	writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not_ready", "reason": reason})
+}
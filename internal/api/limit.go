@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Limits bound the work the server takes on, so one misbehaving consumer
+// can't exhaust the memory or CPU of a small local daemon.
+type Limits struct {
+	// MaxConcurrent is how many requests are handled at once; 0 means no
+	// limit.
+	MaxConcurrent int
+	// MaxQueue is how many more may wait for a slot, each for up to
+	// maxQueueWait. Beyond that, requests get a 503 straight away.
+	MaxQueue int
+	// RequestTimeout bounds reading a request and writing its response.
+	// Reads that wait for the authorizer get its timeout on top.
+	RequestTimeout time.Duration
+	// RouteTimeouts replace RequestTimeout for the paths they prefix, with
+	// or without a version; the longest prefix wins.
+	RouteTimeouts map[string]time.Duration
+}
+
+// DefaultLimits are the limits a server starts with.
+var DefaultLimits = Limits{MaxConcurrent: 32, MaxQueue: 64, RequestTimeout: 30 * time.Second, RouteTimeouts: DefaultRouteTimeouts}
+
+// DefaultRouteTimeouts give the routes that decrypt and send the whole
+// vault longer than the rest, and single field reads and writes less.
+var DefaultRouteTimeouts = map[string]time.Duration{
+	"/vault/context":    2 * time.Minute,
+	"/vault/export":     2 * time.Minute,
+	"/vault/snapshots":  2 * time.Minute,
+	"/vault/replica":    2 * time.Minute,
+	"/vault/bootstrap/": time.Minute,
+	"/vault/fields/":    10 * time.Second,
+}
+
+const (
+	// maxQueueWait is how long a queued request waits for a slot before it
+	// is refused.
+	maxQueueWait = 5 * time.Second
+
+	// readHeaderTimeout bounds reading request headers, so a client that
+	// trickles them in can't hold a connection open.
+	readHeaderTimeout = 10 * time.Second
+
+	// idleTimeout is how long a kept-alive connection may sit unused.
+	idleTimeout = 2 * time.Minute
+)
+
+// SetLimits replaces DefaultLimits. Call before Start.
+func (s *Server) SetLimits(l Limits) error {
+	if l.MaxConcurrent < 0 || l.MaxQueue < 0 {
+		return errors.New("limits must not be negative")
+	}
+	if l.RequestTimeout <= 0 {
+		return errors.New("request timeout must be positive")
+	}
+	longest := l.RequestTimeout
+	for _, d := range l.RouteTimeouts {
+		if d <= 0 {
+			return errors.New("route timeouts must be positive")
+		}
+		longest = max(longest, d)
+	}
+	s.limits = l
+	s.slots = nil
+	if l.MaxConcurrent > 0 {
+		s.slots = make(chan struct{}, l.MaxConcurrent)
+	}
+	// The server's own timeouts are a backstop for the longest route;
+	// limitMiddleware holds each request to its route's.
+	s.server.ReadTimeout = longest
+	s.server.WriteTimeout = longest
+	return nil
+}
+
+// timeout returns how long a request to path has to arrive and be
+// answered.
+func (l Limits) timeout(path string) time.Duration {
+	if m := versionSegment.FindStringSubmatch(path); m != nil {
+		path = m[2]
+	}
+	d, matched := l.RequestTimeout, ""
+	for prefix, t := range l.RouteTimeouts {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+			d, matched = t, prefix
+		}
+	}
+	return d
+}
+
+// acquire takes a request slot, waiting in the queue if there is room, and
+// reports whether it got one.
+func (s *Server) acquire(ctx context.Context) bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if s.queued.Add(1) > int64(s.limits.MaxQueue) {
+		s.queued.Add(-1)
+		return false
+	}
+	defer s.queued.Add(-1)
+	timer := time.NewTimer(maxQueueWait)
+	defer timer.Stop()
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// limitMiddleware holds each request to its route's timeout, and sheds
+// requests beyond the concurrency limit and its queue with a 503. Health
+// probes are never shed, so a busy vault isn't taken for a dead one and
+// restarted.
+func (s *Server) limitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = withResponseController(r, w)
+		// Connections that can't take a deadline, such as a test
+		// recorder, keep the server's.
+		deadline := time.Now().Add(s.limits.timeout(r.URL.Path))
+		rc := r.Context().Value(responseControllerKey).(*http.ResponseController)
+		rc.SetReadDeadline(deadline)
+		rc.SetWriteDeadline(deadline)
+		if s.slots == nil || r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !s.acquire(r.Context()) {
+			w.Header().Set("Retry-After", "1")
+			writeErrorDetails(w, http.StatusServiceUnavailable, constraintOverloaded, "the server is busy, try again shortly",
+				errorDetails{"retry_after_seconds": 1})
+			return
+		}
+		defer func() { <-s.slots }()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// responseControllerKey holds the request's *http.ResponseController.
+const responseControllerKey contextKey = "response_controller"
+
+// withResponseController lets handlers deep in the chain reach w's deadline.
+func withResponseController(r *http.Request, w http.ResponseWriter) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), responseControllerKey, http.NewResponseController(w)))
+}
+
+// extendDeadline gives a request that is about to wait on something slow,
+// like the authorizer, d more than its route's timeout to answer.
+func (s *Server) extendDeadline(r *http.Request, d time.Duration) {
+	rc, ok := r.Context().Value(responseControllerKey).(*http.ResponseController)
+	if !ok || d <= 0 {
+		return
+	}
+	rc.SetWriteDeadline(time.Now().Add(s.limits.timeout(r.URL.Path) + d))
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// GET /vault/links
+// Lists the links between fields. Session only.
+func (s *Server) handleListLinks(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	links, err := s.vault.Links()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, links)
+}
+
+// POST /vault/links
+// Links a field to others, e.g. a card to its billing address. Session only.
+func (s *Server) handleLink(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	var req struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Type string `json:"type"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := vault.ValidateFieldID(req.From); err != nil {
+		invalidField(w, "from", err.Error())
+		return
+	}
+	if err := vault.ValidateLinkTarget(req.To); err != nil {
+		invalidField(w, "to", err.Error())
+		return
+	}
+	if err := s.vault.Link(req.From, req.To, req.Type); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DELETE /vault/links?from=<id>&to=<target>&type=<type>
+// Removes a link. Session only.
+func (s *Server) handleUnlink(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	q := r.URL.Query()
+	if err := s.vault.Unlink(q.Get("from"), q.Get("to"), q.Get("type")); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -2,19 +2,104 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/lovincyrus/personal-vault/internal/proc"
 	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
 type contextKey string
 
 const (
-	scopeKey      contextKey = "scope"
-	sessionAuthKey contextKey = "session_auth"
+	scopeKey        contextKey = "scope"
+	sessionAuthKey  contextKey = "session_auth"
+	sessionTokenKey contextKey = "session_token"
+	consumerKey     contextKey = "consumer"
+	requestIDKey    contextKey = "request_id"
+	serviceTokenKey contextKey = "service_token"
+	workloadKey     contextKey = "workload"
+	peerKey         contextKey = "peer"
 )
 
+const requestIDHeader = "X-Request-Id"
+
+// validRequestID bounds client-supplied request IDs to a safe charset so they
+// can't inject into logs or headers.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDFromRequest returns the request ID assigned by requestIDMiddleware.
+func requestIDFromRequest(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
+
+// consumerFromRequest returns the service token consumer, or "vault" for session tokens.
+func consumerFromRequest(r *http.Request) string {
+	if c, ok := r.Context().Value(consumerKey).(string); ok {
+		return c
+	}
+	return "vault"
+}
+
+// statusRecorder captures the response status for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// requestIDMiddleware assigns each request an ID — the client's X-Request-Id
+// if it is well-formed, otherwise a random one — echoes it in the response,
+// and writes one structured log line per request.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			b := make([]byte, 16)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+
+		level := slog.LevelInfo
+		if probePaths[r.URL.Path] && rec.status == http.StatusOK {
+			level = slog.LevelDebug // supervisors poll these every few seconds
+		}
+		slog.Log(r.Context(), level, "request",
+			"request_id", id,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+	})
+}
+
 // scopeFromRequest returns the token scope. Session tokens get "*" (full access).
 func scopeFromRequest(r *http.Request) string {
 	if s, ok := r.Context().Value(scopeKey).(string); ok {
@@ -30,7 +115,53 @@ func isSessionAuth(r *http.Request) bool {
 }
 
 func sessionRequired(w http.ResponseWriter) {
-	writeError(w, http.StatusForbidden, "session_required", "this operation requires a session token, not a service token")
+	writeErrorDetails(w, http.StatusForbidden, constraintSessionRequired, "this operation requires a session token, not a service token",
+		errorDetails{"required_auth": "session", "token_type": "service"})
+}
+
+// serviceTokenFromRequest returns the service token that authenticated the
+// request, or nil for session tokens.
+func serviceTokenFromRequest(r *http.Request) *store.Token {
+	t, _ := r.Context().Value(serviceTokenKey).(*store.Token)
+	return t
+}
+
+// workloadFromRequest returns the attested process that sent the request, or
+// nil if it didn't arrive over the vault socket.
+func workloadFromRequest(r *http.Request) *proc.Workload {
+	w, _ := r.Context().Value(workloadKey).(*proc.Workload)
+	return w
+}
+
+// peerFromRequest returns the client the request's connection came from. A
+// request that didn't come through the server, as in tests, has none.
+func peerFromRequest(r *http.Request) (clientPeer, bool) {
+	p, ok := r.Context().Value(peerKey).(clientPeer)
+	return p, ok
+}
+
+// sessionClientAllowed refuses a session token bound to the client that
+// opened it when it arrives from another machine or user, writing a 401 and
+// recording the attempt: the session token was most likely copied.
+func (s *Server) sessionClientAllowed(w http.ResponseWriter, r *http.Request, token string) bool {
+	peer, ok := peerFromRequest(r)
+	if !ok {
+		peer.uid = -1
+	}
+	if s.vault.SessionAllows(token, peer.local, peer.uid) {
+		return true
+	}
+	slog.Warn("refused a session token from another client", "uid", peer.uid, "remote", r.RemoteAddr)
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  "vault",
+		Scope:     "*",
+		Action:    "denied",
+		Purpose:   "session_bound",
+		RequestID: requestIDFromRequest(r),
+	})
+	writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, "session token is bound to another client",
+		errorDetails{"reason": "session_bound"})
+	return false
 }
 
 // securityHeadersMiddleware sets standard security headers on all responses.
@@ -55,40 +186,151 @@ func bodySizeMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// authMiddleware extracts the Bearer token and validates it.
+// requestToken returns the request's Bearer token or, from the browser UI,
+// the session behind its cookie, writing a 401 if there is neither and a 403
+// if a cookie-authenticated write lacks its CSRF token.
+func (s *Server) requestToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer "), true
+	}
+	if token, csrfOK, ok := s.uiSessionToken(r); ok {
+		if !csrfOK {
+			writeErrorDetails(w, http.StatusForbidden, constraintCSRFFailed, "missing or wrong CSRF token",
+				errorDetails{"header": csrfHeader})
+			return "", false
+		}
+		return token, true
+	}
+	writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, "missing authorization",
+		errorDetails{"reason": "missing_authorization"})
+	return "", false
+}
+
+// sessionTokenFromRequest returns the session token that authenticated the
+// request, or "" for a service token.
+func sessionTokenFromRequest(r *http.Request) string {
+	token, _ := r.Context().Value(sessionTokenKey).(string)
+	return token
+}
+
+// authMiddleware extracts the request's token and validates it.
 // Accepts both session tokens and service tokens.
 func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		auth := r.Header.Get("Authorization")
-		if !strings.HasPrefix(auth, "Bearer ") {
-			writeError(w, http.StatusUnauthorized, "unauthenticated", "missing authorization")
+		token, ok := s.requestToken(w, r)
+		if !ok {
 			return
 		}
-		token := strings.TrimPrefix(auth, "Bearer ")
 
 		// Try session token first — full access
 		if s.vault.ValidateToken(token) {
-			s.vault.TouchSession()
+			if !s.sessionClientAllowed(w, r, token) {
+				return
+			}
+			s.vault.TouchSession(token)
 			ctx := context.WithValue(r.Context(), scopeKey, "*")
 			ctx = context.WithValue(ctx, sessionAuthKey, true)
+			ctx = context.WithValue(ctx, sessionTokenKey, token)
 			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
-		// Try service token — scoped access
-		if svcToken, ok := s.vault.ValidateServiceToken(token); ok {
-			s.vault.TouchSession()
+		// Try service token — scoped access. A discovery token is one that
+		// may only list field metadata.
+		svcToken, ok := s.vault.ValidateServiceToken(token)
+		if !ok {
+			if svcToken, ok = s.vault.ValidateDiscoveryToken(token); ok && !discoveryAllows(r) {
+				s.discoveryDenied(w, r, svcToken)
+				return
+			}
+		}
+		if ok {
+			if err := s.vault.EnforceTokenConstraints(svcToken, time.Now(), workloadFromRequest(r)); err != nil {
+				s.tokenRestricted(w, r, svcToken, err)
+				return
+			}
+			s.vault.TouchSessions()
 			s.vault.LogAccess(store.AuditEntry{
-				Consumer: svcToken.Consumer,
-				Scope:    svcToken.Scope,
-				Action:   "api_access",
+				Consumer:  svcToken.Consumer,
+				Scope:     svcToken.Scope,
+				Action:    "api_access",
+				Purpose:   purposeFromRequest(r),
+				RequestID: requestIDFromRequest(r),
 			})
 			ctx := context.WithValue(r.Context(), scopeKey, svcToken.Scope)
 			ctx = context.WithValue(ctx, sessionAuthKey, false)
+			ctx = context.WithValue(ctx, consumerKey, svcToken.Consumer)
+			ctx = context.WithValue(ctx, serviceTokenKey, svcToken)
 			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
-		writeError(w, http.StatusUnauthorized, "unauthenticated", "invalid or expired token")
+		writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, "invalid or expired token",
+			errorDetails{"reason": "invalid_or_expired_token"})
 	})
 }
+
+// discoveryRoutes are the requests a discovery token may make. The schema
+// and status are public, so it reaches those too.
+var discoveryRoutes = []string{"GET /vault/fields", "GET /vault/schema", "GET /vault/status"}
+
+// discoveryAllows reports whether a discovery token may make request r:
+// listing fields, which carries no values.
+func discoveryAllows(r *http.Request) bool {
+	return (r.Method == http.MethodGet || r.Method == http.MethodHead) && r.URL.Path == "/vault/fields"
+}
+
+// discoveryDenied refuses a discovery token used for anything but listing
+// fields and records the attempt.
+func (s *Server) discoveryDenied(w http.ResponseWriter, r *http.Request, t *store.Token) {
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  t.Consumer,
+		Scope:     t.Scope,
+		Action:    "denied",
+		Purpose:   constraintDiscoveryOnly,
+		RequestID: requestIDFromRequest(r),
+	})
+	writeErrorDetails(w, http.StatusForbidden, constraintDiscoveryOnly, "discovery tokens can only list fields; ask the owner for a service token to read values",
+		errorDetails{"allowed": discoveryRoutes})
+}
+
+// isDiscovery reports whether the request came with a discovery token.
+func isDiscovery(r *http.Request) bool {
+	t := serviceTokenFromRequest(r)
+	return t != nil && t.Usage == vault.TokenUsageDiscovery
+}
+
+// tokenRestricted refuses a valid service token used outside its constraints
+// and records the attempt.
+func (s *Server) tokenRestricted(w http.ResponseWriter, r *http.Request, t *store.Token, err error) {
+	var restricted *vault.TokenRestrictedError
+	if !errors.As(err, &restricted) {
+		writeError(w, http.StatusInternalServerError, constraintInternal, "failed to check token constraints")
+		return
+	}
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  t.Consumer,
+		Scope:     t.Scope,
+		Action:    "denied",
+		Purpose:   restricted.Reason,
+		RequestID: requestIDFromRequest(r),
+	})
+	c := restricted.Constraints
+	details := errorDetails{"reason": restricted.Reason}
+	if c.Hours != "" {
+		details["hours"] = c.Hours
+	}
+	if len(c.Weekdays) > 0 {
+		details["weekdays"] = c.Weekdays
+	}
+	if c.MaxPerDay > 0 {
+		details["max_per_day"] = c.MaxPerDay
+	}
+	if c.Timezone != "" {
+		details["timezone"] = c.Timezone
+	}
+	if c.Workload != nil {
+		details["workload"] = c.Workload
+	}
+	writeErrorDetails(w, http.StatusForbidden, constraintTokenRestricted, restricted.Error(), details)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// PUT /vault/notes/{id...}
+// Sets a field's note; an empty note clears it. Session only.
+func (s *Server) handleSetNote(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return
+	}
+	var req struct {
+		Note string `json:"note"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := s.vault.SetNote(id, req.Note); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
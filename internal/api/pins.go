@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// PUT /vault/pins/{id}
+// Pins a field, so lists show it first. Session only.
+func (s *Server) handlePin(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return
+	}
+	if err := s.vault.Pin(id); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DELETE /vault/pins/{id}
+// Unpins a field. Session only.
+func (s *Server) handleUnpin(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if err := s.vault.Unpin(r.PathValue("id")); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
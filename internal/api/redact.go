@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// errElevationRequired stops a redaction that would match critical values
+// for a session that hasn't re-entered the password.
+var errElevationRequired = errors.New("elevation required")
+
+// POST /vault/redact
+// Returns text with the vault values found in it replaced by placeholders,
+// for scrubbing logs and transcripts before they are kept. A service token
+// redacts only the fields it could read, and a redaction is authorized as a
+// read of all of them whatever the text holds, so the endpoint can't be used
+// to test guesses at values the token may not read now. Canaries are never
+// redacted for a token: a match would tell it which fields are decoys.
+func (s *Server) handleRedact(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var canaries []vault.Canary
+	if !isSessionAuth(r) {
+		var err error
+		if canaries, err = s.vault.Canaries(); err != nil {
+			handleVaultError(w, err)
+			return
+		}
+	}
+	scope, reach := s.fieldScope(r), s.fieldCheck(r)
+	allow := func(f vault.FieldInfo) bool {
+		if slices.ContainsFunc(canaries, func(c vault.Canary) bool { return c.ID == f.ID }) {
+			return false
+		}
+		return vault.ScopeAllows(scope, f.ID) && reach(f)
+	}
+	authorize := func(fields []vault.FieldInfo) error {
+		if hasCritical(fields) && !s.elevated(r) {
+			return errElevationRequired
+		}
+		return s.authorizeRead(r, "redact", fields)
+	}
+	text, redactions, err := s.vault.Redact(req.Text, allow, authorize)
+	if errors.Is(err, errElevationRequired) {
+		elevationRequired(w, "critical_field")
+		return
+	}
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	if redactions == nil {
+		redactions = []vault.Redaction{}
+	}
+	if len(redactions) > 0 && !isSessionAuth(r) {
+		ids := make([]string, len(redactions))
+		for i, rd := range redactions {
+			ids[i] = rd.ID
+		}
+		s.vault.LogAccess(store.AuditEntry{
+			Consumer:  consumerFromRequest(r),
+			Scope:     strings.Join(ids, ","),
+			Action:    "redact",
+			RequestID: requestIDFromRequest(r),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"text": text, "redactions": redactions})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// replicaWritePaths are the paths whose writes change field data, which a
+// read replica only takes from its primary. Aliases, tokens, snapshots, and
+// the like belong to the device and stay writable.
+var replicaWritePaths = []string{
+	"/vault/fields/",
+	"/vault/transactions",
+	"/vault/sensitivity/",
+	"/vault/canaries/",
+	"/vault/suggestions/",
+	"/vault/enrich/",
+}
+
+// replicaMiddleware refuses field writes on a read replica with a 307 to
+// the same request on the primary, so a client that follows redirects
+// writes there and sees the change here after the next sync.
+func (s *Server) replicaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source := s.vault.ReplicaSource()
+		if source == "" || !isReplicaWrite(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		target := strings.TrimRight(source, "/") + "/v" + currentAPIVersion + r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		w.Header().Set("Location", target)
+		writeErrorDetails(w, http.StatusTemporaryRedirect, constraintReadOnlyReplica, "this vault is a read-only replica; write to the primary", errorDetails{
+			"primary": source,
+			"remedy":  "repeat the request at Location",
+		})
+	})
+}
+
+func isReplicaWrite(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	for _, p := range replicaWritePaths {
+		if r.URL.Path == strings.TrimSuffix(p, "/") || strings.HasPrefix(r.URL.Path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// GET /vault/replica
+// Reports where a read replica pulls from and how its syncing is going.
+// Session only.
+func (s *Server) handleReplicaStatus(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	status, ok := s.vault.ReplicaStatus()
+	if !ok {
+		writeErrorDetails(w, http.StatusNotFound, constraintNotFound, "this vault is not a replica", errorDetails{
+			"path": r.URL.Path,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// routeMethods are the methods probed when working out what a path allows.
+var routeMethods = []string{"GET", "HEAD", "POST", "PUT", "DELETE"}
+
+// protectedRoutes serves the protected mux behind auth, and answers
+// everything no route matches: OPTIONS with the methods a path allows, a
+// known path with the wrong method with a 405, and anything else with a
+// 404, all before auth, so clients get the same answer with or without a
+// token.
+func (s *Server) protectedRoutes(protected *http.ServeMux) http.Handler {
+	auth := s.authMiddleware(protected)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions && route(protected, r) != "" {
+			auth.ServeHTTP(w, r)
+			return
+		}
+		allowed := s.allowedMethods(protected, r)
+		if len(allowed) == 0 {
+			writeErrorDetails(w, http.StatusNotFound, constraintNotFound, "no such endpoint", errorDetails{
+				"path": r.URL.Path,
+			})
+			return
+		}
+		w.Header().Set("Allow", strings.Join(append(allowed, http.MethodOptions), ", "))
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			writeErrorDetails(w, http.StatusMethodNotAllowed, constraintMethodNotAllowed, r.Method+" not allowed on this endpoint", errorDetails{
+				"method":  r.Method,
+				"allowed": allowed,
+			})
+		}
+	})
+}
+
+// allowedMethods returns the methods some route, public or protected,
+// serves r's path with. The catch-all "/" that leads here doesn't count.
+func (s *Server) allowedMethods(protected *http.ServeMux, r *http.Request) []string {
+	var allowed []string
+	for _, method := range routeMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if pattern := route(s.mux, probe); pattern != "" && pattern != "/" {
+			allowed = append(allowed, method)
+			continue
+		}
+		if route(protected, probe) != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// route returns the pattern mux serves r with, or "" if none. A match that
+// only redirects to add a trailing slash, as "/vault/fields" would to reach
+// "PUT /vault/fields/{id...}", doesn't count: no client means it.
+func route(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	path := pattern
+	if i := strings.IndexByte(path, ' '); i >= 0 {
+		path = path[i+1:]
+	}
+	if i := strings.IndexByte(path, '{'); i >= 0 {
+		path = path[:i]
+	}
+	if strings.HasSuffix(path, "/") && r.URL.Path+"/" == path {
+		return ""
+	}
+	return pattern
+}
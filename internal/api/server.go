@@ -2,11 +2,16 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/lovincyrus/personal-vault/internal/proc"
 	"github.com/lovincyrus/personal-vault/internal/vault"
 )
 
@@ -46,57 +51,250 @@ func (rl *rateLimiter) allow() bool {
 	return true
 }
 
+// retryAfter returns how long until the oldest attempt leaves the window.
+func (rl *rateLimiter) retryAfter() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if len(rl.attempts) == 0 {
+		return 0
+	}
+	d := time.Until(rl.attempts[0].Add(rl.window))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// idle reports whether every attempt has left the window.
+func (rl *rateLimiter) idle() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.attempts) == 0 || rl.attempts[len(rl.attempts)-1].Before(time.Now().Add(-rl.window))
+}
+
+// peerLimiter keeps a rateLimiter per client, for endpoints anyone can call,
+// so that one noisy client can't use up the limit for the rest.
+type peerLimiter struct {
+	mu     sync.Mutex
+	peers  map[string]*rateLimiter
+	max    int
+	window time.Duration
+}
+
+func newPeerLimiter(max int, window time.Duration) *peerLimiter {
+	return &peerLimiter{peers: make(map[string]*rateLimiter), max: max, window: window}
+}
+
+// limiter returns peer's rateLimiter. Making one for a new peer first
+// forgets the peers that have gone quiet, so the map doesn't grow without
+// bound.
+func (pl *peerLimiter) limiter(peer string) *rateLimiter {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if rl, ok := pl.peers[peer]; ok {
+		return rl
+	}
+	for p, rl := range pl.peers {
+		if rl.idle() {
+			delete(pl.peers, p)
+		}
+	}
+	rl := newRateLimiter(pl.max, pl.window)
+	pl.peers[peer] = rl
+	return rl
+}
+
+// requestPeer names the client a request is counted against: its local
+// user where the connection says, since local clients share an address,
+// and otherwise its address.
+func requestPeer(r *http.Request) string {
+	if p, ok := peerFromRequest(r); ok && p.uid >= 0 {
+		return "uid:" + strconv.Itoa(p.uid)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // Server is the HTTP API server for the vault.
 type Server struct {
-	vault       *vault.Vault
-	mux         *http.ServeMux
-	handler     http.Handler // full chain: bodySizeMiddleware → mux
-	server      *http.Server
-	unlockLimit *rateLimiter
+	vault          *vault.Vault
+	mux            *http.ServeMux
+	handler        http.Handler // full chain: requestID → securityHeaders → cors → limit → bodySize → version → replica → mux
+	server         *http.Server
+	limits         Limits
+	slots          chan struct{} // one per request being handled; nil for no limit
+	queued         atomic.Int64  // requests waiting for a slot
+	unlockLimit    *rateLimiter
+	emergencyLimit *rateLimiter
+	elevateLimit   *rateLimiter
+	capsLimit      *peerLimiter // GET /vault/capabilities, which needs no auth
+	contextCache   *contextCache
+	uiLogins       *uiLogins
+	ownerUID       int // local connections from other users are refused
+	corsOrigins    map[string]bool
 }
 
 // New creates a new API server.
 func New(v *vault.Vault, addr string) *Server {
 	s := &Server{
-		vault:       v,
-		unlockLimit: newRateLimiter(5, time.Minute),
+		vault:          v,
+		unlockLimit:    newRateLimiter(5, time.Minute),
+		emergencyLimit: newRateLimiter(5, time.Minute),
+		elevateLimit:   newRateLimiter(5, time.Minute),
+		capsLimit:      newPeerLimiter(60, time.Minute),
+		contextCache:   newContextCache(v.Generation),
+		uiLogins:       newUILogins(),
+		ownerUID:       os.Getuid(),
 	}
+	v.OnSeal(s.contextCache.invalidate)
 	s.mux = http.NewServeMux()
 	s.registerRoutes()
-	s.handler = securityHeadersMiddleware(bodySizeMiddleware(s.mux))
+	s.handler = requestIDMiddleware(securityHeadersMiddleware(s.corsMiddleware(s.limitMiddleware(bodySizeMiddleware(versionMiddleware(s.replicaMiddleware(s.mux)))))))
 	s.server = &http.Server{
-		Addr:    addr,
-		Handler: s.handler,
+		Addr:              addr,
+		Handler:           s.handler,
+		ConnContext:       attestConn,
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
 	}
+	s.SetLimits(DefaultLimits)
 	return s
 }
 
+// clientPeer is what a connection says about the client: whether it runs
+// on this machine, and as which user, or -1 where the platform can't tell.
+// Sessions bound to the client that opened them are checked against it.
+type clientPeer struct {
+	local bool
+	uid   int
+}
+
+// attestConn records the client on the other end of a connection and, over
+// a Unix socket, its workload, for tokens bound to one. TCP connections
+// carry no workload.
+func attestConn(ctx context.Context, c net.Conn) context.Context {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+	peer := clientPeer{uid: -1}
+	switch c := c.(type) {
+	case *net.UnixConn:
+		peer.local = true
+		if w, err := proc.PeerWorkload(c); err == nil {
+			ctx = context.WithValue(ctx, workloadKey, w)
+			peer.uid = w.UID
+		}
+	case *net.TCPConn:
+		peer.local = c.RemoteAddr().(*net.TCPAddr).IP.IsLoopback()
+		if peer.local {
+			if uid, err := proc.PeerUID(c); err == nil {
+				peer.uid = uid
+			}
+		}
+	}
+	return context.WithValue(ctx, peerKey, peer)
+}
+
 func (s *Server) registerRoutes() {
 	// Public endpoints (no auth required)
-	s.mux.HandleFunc("GET /ui", s.handleUI)
+	s.mux.HandleFunc("GET /ui", uiPage("onboarding"))
+	s.mux.HandleFunc("GET /ui/consent", uiPage("consent"))
+	s.mux.HandleFunc("GET /ui/manage", uiPage("manage"))
+	s.mux.HandleFunc("GET /ui/assets/{name}", s.handleUIAsset)
+	s.mux.HandleFunc("GET /ui/login", s.handleUILogin)
 	s.mux.HandleFunc("GET /ui/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/ui", http.StatusMovedPermanently)
 	})
 	s.mux.HandleFunc("POST /vault/unlock", s.handleUnlock)
 	s.mux.HandleFunc("GET /vault/status", s.handleStatus)
+	s.mux.HandleFunc("GET /healthz", s.handleHealthz)
+	s.mux.HandleFunc("GET /readyz", s.handleReadyz)
+	s.mux.HandleFunc("GET /vault/session", s.handleSessionInfo)
 	s.mux.HandleFunc("GET /vault/schema", s.handleSchema)
+	s.mux.HandleFunc("GET /vault/capabilities", s.handleCapabilities)
+	s.mux.HandleFunc("POST /vault/emergency/request", s.handleEmergencyRequest)
+	s.mux.HandleFunc("POST /vault/emergency/release", s.handleEmergencyRelease)
 
 	// Protected endpoints
 	protected := http.NewServeMux()
 	protected.HandleFunc("POST /vault/lock", s.handleLock)
+	protected.HandleFunc("POST /vault/session/refresh", s.handleRefreshSession)
+	protected.HandleFunc("POST /vault/elevate", s.handleElevate)
+	protected.HandleFunc("GET /vault/sessions", s.handleListSessions)
+	protected.HandleFunc("POST /vault/ui/login", s.handleUILoginCode)
+	protected.HandleFunc("DELETE /vault/sessions/{id}", s.handleRevokeSession)
 	protected.HandleFunc("GET /vault/fields", s.handleListFields)
 	protected.HandleFunc("GET /vault/fields/category/{category}", s.handleGetByCategory)
 	protected.HandleFunc("GET /vault/fields/{id...}", s.handleGetField)
 	protected.HandleFunc("PUT /vault/fields/{id...}", s.handleSetField)
 	protected.HandleFunc("DELETE /vault/fields/{id...}", s.handleDeleteField)
+	protected.HandleFunc("POST /vault/transactions", s.handleTransaction)
+	protected.HandleFunc("GET /vault/history/{id...}", s.handleFieldHistory)
+	protected.HandleFunc("GET /vault/verify", s.handleVerify)
+	protected.HandleFunc("POST /vault/compact", s.handleCompact)
+	protected.HandleFunc("GET /vault/aliases", s.handleListAliases)
+	protected.HandleFunc("PUT /vault/aliases/{alias...}", s.handleSetAlias)
+	protected.HandleFunc("DELETE /vault/aliases/{alias...}", s.handleDeleteAlias)
+	protected.HandleFunc("GET /vault/acls", s.handleListACLs)
+	protected.HandleFunc("PUT /vault/acls/{id...}", s.handleSetACL)
+	protected.HandleFunc("DELETE /vault/acls/{id...}", s.handleDeleteACL)
+	protected.HandleFunc("GET /vault/consumers", s.handleListConsumers)
+	protected.HandleFunc("PUT /vault/consumers/{name}", s.handleSetConsumer)
+	protected.HandleFunc("DELETE /vault/consumers/{name}", s.handleDeleteConsumer)
+	protected.HandleFunc("GET /vault/consumers/{name}/signing-key", s.handleSigningKey)
+	protected.HandleFunc("GET /vault/templates", s.handleListTemplates)
+	protected.HandleFunc("PUT /vault/templates/{consumer}", s.handleSetTemplate)
+	protected.HandleFunc("DELETE /vault/templates/{consumer}", s.handleDeleteTemplate)
+	protected.HandleFunc("PUT /vault/pins/{id}", s.handlePin)
+	protected.HandleFunc("DELETE /vault/pins/{id}", s.handleUnpin)
+	protected.HandleFunc("PUT /vault/notes/{id...}", s.handleSetNote)
+	protected.HandleFunc("PUT /vault/types/{id...}", s.handleSetValueType)
+	protected.HandleFunc("GET /vault/append-only", s.handleListAppendOnly)
+	protected.HandleFunc("PUT /vault/append-only/{category}", s.handleSetAppendOnly)
+	protected.HandleFunc("DELETE /vault/append-only/{category}", s.handleClearAppendOnly)
+	protected.HandleFunc("POST /vault/entries/{id...}", s.handleAddEntry)
+	protected.HandleFunc("DELETE /vault/entries/{id...}", s.handleRemoveEntry)
+	protected.HandleFunc("GET /vault/links", s.handleListLinks)
+	protected.HandleFunc("POST /vault/links", s.handleLink)
+	protected.HandleFunc("DELETE /vault/links", s.handleUnlink)
+	protected.HandleFunc("GET /vault/expiring", s.handleExpiring)
+	protected.HandleFunc("POST /vault/redact", s.handleRedact)
+	protected.HandleFunc("GET /vault/canaries", s.handleListCanaries)
+	protected.HandleFunc("PUT /vault/canaries/{id...}", s.handleCreateCanary)
+	protected.HandleFunc("DELETE /vault/canaries/{id...}", s.handleDeleteCanary)
+	protected.HandleFunc("POST /vault/enrich/address", s.handleEnrichAddress)
+	protected.HandleFunc("GET /vault/suggestions", s.handleListSuggestions)
+	protected.HandleFunc("POST /vault/suggestions/{id}/accept", s.handleAcceptSuggestion)
+	protected.HandleFunc("DELETE /vault/suggestions/{id}", s.handleDismissSuggestion)
 	protected.HandleFunc("GET /vault/context", s.handleGetContext)
+	protected.HandleFunc("GET /vault/context/digest", s.handleContextDigest)
+	protected.HandleFunc("GET /vault/context/prompt", s.handleContextPrompt)
+	protected.HandleFunc("GET /vault/bootstrap/{format}", s.handleBootstrap)
+	protected.HandleFunc("GET /vault/export", s.handleExport)
+	protected.HandleFunc("GET /vault/wiring", s.handleExportWiring)
+	protected.HandleFunc("POST /vault/wiring", s.handleImportWiring)
+	protected.HandleFunc("GET /vault/replica", s.handleReplicaStatus)
+	protected.HandleFunc("POST /vault/snapshots", s.handleCreateSnapshot)
+	protected.HandleFunc("GET /vault/snapshots", s.handleListSnapshots)
+	protected.HandleFunc("GET /vault/snapshots/{id}", s.handleGetSnapshot)
+	protected.HandleFunc("DELETE /vault/snapshots/{id}", s.handleDeleteSnapshot)
 	protected.HandleFunc("GET /vault/audit", s.handleAuditLog)
+	protected.HandleFunc("GET /vault/audit/timeline", s.handleAuditTimeline)
 	protected.HandleFunc("PUT /vault/sensitivity/{id...}", s.handleSetSensitivity)
 	protected.HandleFunc("POST /vault/tokens/service", s.handleCreateServiceToken)
+	protected.HandleFunc("POST /vault/tokens/service/preview", s.handlePreviewScope)
 	protected.HandleFunc("GET /vault/tokens/service", s.handleListServiceTokens)
 	protected.HandleFunc("DELETE /vault/tokens/service/{token}", s.handleRevokeServiceToken)
+	protected.HandleFunc("POST /vault/tokens/delegate", s.handleDelegateToken)
+	protected.HandleFunc("GET /vault/emergency", s.handleListEmergency)
+	protected.HandleFunc("PUT /vault/emergency/{contact}", s.handleSetEmergency)
+	protected.HandleFunc("DELETE /vault/emergency/{contact}", s.handleDeleteEmergency)
+	protected.HandleFunc("POST /vault/emergency/{contact}/deny", s.handleDenyEmergency)
 
-	s.mux.Handle("/", s.authMiddleware(protected))
+	s.mux.Handle("/", s.protectedRoutes(protected))
 }
 
 // Start begins listening. Returns immediately; use the returned listener to get the actual port.
@@ -105,6 +303,43 @@ func (s *Server) Start() (net.Listener, error) {
 	if err != nil {
 		return nil, err
 	}
+	ln = proc.OwnerListener(ln, s.ownerUID)
+	go s.server.Serve(ln)
+	return ln, nil
+}
+
+// StartTLS is Start serving HTTPS with the given certificate and key, for
+// listening beyond loopback.
+func (s *Server) StartTLS(certFile, keyFile string) (net.Listener, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return nil, err
+	}
+	ln = tls.NewListener(proc.OwnerListener(ln, s.ownerUID), &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+	go s.server.Serve(ln)
+	return ln, nil
+}
+
+// StartUnix also serves on a Unix socket at path, where the kernel vouches
+// for the connecting process so workload-bound tokens can be checked. The
+// socket is private to the vault's owner; a stale one is replaced.
+func (s *Server) StartUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	ln = proc.OwnerListener(ln, s.ownerUID)
 	go s.server.Serve(ln)
 	return ln, nil
 }
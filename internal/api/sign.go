@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// signRead returns fields signed for the request's consumer when it asks
+// with ?signed=true, and as they are otherwise.
+func (s *Server) signRead(r *http.Request, fields []vault.FieldInfo) ([]vault.FieldInfo, error) {
+	if r.URL.Query().Get("signed") != "true" {
+		return fields, nil
+	}
+	return s.vault.Sign(consumerFromRequest(r), fields, time.Now())
+}
+
+// GET /vault/consumers/{name}/signing-key
+// Returns the key that verifies signed reads by a consumer, for the systems
+// the consumer passes values on to. Session only.
+func (s *Server) handleSigningKey(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	name := r.PathValue("name")
+	key, err := s.vault.SigningKey(name)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     name,
+		Action:    "signing_key",
+		RequestID: requestIDFromRequest(r),
+	})
+	writeJSON(w, http.StatusOK, map[string]string{"consumer": name, "alg": vault.SignatureAlg, "key": hex.EncodeToString(key)})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// POST /vault/snapshots
+// Freezes the fields a scope covers, as the caller would read them now, into
+// a snapshot retrievable by ID until it expires. The scope defaults to the
+// caller's own and can't exceed it.
+func (s *Server) handleCreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Scope string `json:"scope"`
+		TTL   string `json:"ttl"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	tokenScope := scopeFromRequest(r)
+	if req.Scope == "" {
+		req.Scope = tokenScope
+	}
+	if !vault.ScopeSubset(req.Scope, s.fieldScope(r)) {
+		s.vault.LogAccess(store.AuditEntry{
+			Consumer:  consumerFromRequest(r),
+			Scope:     req.Scope,
+			Action:    "denied",
+			Purpose:   "scope_exceeded",
+			RequestID: requestIDFromRequest(r),
+		})
+		writeErrorDetails(w, http.StatusForbidden, constraintScopeExceeded, "snapshot scope must be within the token's scope", errorDetails{
+			"required_scope": req.Scope,
+			"token_scope":    tokenScope,
+			"remedy":         "snapshot a scope within token_scope",
+		})
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed <= 0 {
+			invalidField(w, "ttl", "invalid ttl duration")
+			return
+		}
+		ttl = parsed
+	}
+
+	ctx, err := s.vault.GetContext()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	resolved := s.vault.ResolveScope(req.Scope)
+	bundle := &vault.ContextBundle{Categories: make(map[string][]vault.FieldInfo)}
+	for cat, fields := range ctx.Categories {
+		for _, f := range fields {
+			if vault.ScopeAllows(resolved, f.ID) {
+				bundle.Categories[cat] = append(bundle.Categories[cat], f)
+			}
+		}
+	}
+	bundle = s.accessFilter(r, bundle)
+	if !s.elevated(r) && bundleHasCritical(bundle) {
+		elevationRequired(w, "export")
+		return
+	}
+	if err := s.authorizeRead(r, "snapshot", bundleFields(bundle)); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	bundle, err = s.transformBundle(r, bundle)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+
+	snap, err := s.vault.CreateSnapshot(consumerFromRequest(r), req.Scope, ttl, bundle)
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  snap.Consumer,
+		Scope:     snap.Scope,
+		Action:    "snapshot",
+		Purpose:   snap.ID,
+		RequestID: requestIDFromRequest(r),
+	})
+	s.tripCanaries(r, bundleIDs(bundle)...)
+	writeJSON(w, http.StatusOK, snap)
+}
+
+// GET /vault/snapshots/{id}
+// Returns a snapshot as it was taken. A service token sees only its own
+// consumer's snapshots, and only within its scope.
+func (s *Server) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	snap, err := s.vault.Snapshot(id)
+	if err == nil && !isSessionAuth(r) && snap.Consumer != consumerFromRequest(r) {
+		err = vault.ErrSnapshotNotFound
+	}
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	if !isSessionAuth(r) && !vault.ScopeSubset(snap.Scope, s.fieldScope(r)) {
+		writeErrorDetails(w, http.StatusForbidden, constraintScopeExceeded, "token scope does not cover this snapshot", errorDetails{
+			"required_scope": snap.Scope,
+			"token_scope":    scopeFromRequest(r),
+		})
+		return
+	}
+	// Fields put out of the consumer's reach since the snapshot was taken
+	// are withheld.
+	snap.Context = s.accessFilter(r, snap.Context)
+	if !s.elevated(r) && bundleHasCritical(snap.Context) {
+		elevationRequired(w, "export")
+		return
+	}
+	if err := s.authorizeRead(r, "snapshot", bundleFields(snap.Context)); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	body := jsonBody(snap)
+	s.vault.LogAccess(store.AuditEntry{
+		Consumer:  consumerFromRequest(r),
+		Scope:     snap.Scope,
+		Action:    "snapshot_read",
+		Purpose:   snap.ID,
+		RequestID: requestIDFromRequest(r),
+		Fields:    bundleIDs(snap.Context),
+		Bytes:     len(body),
+	})
+	s.tripCanaries(r, bundleIDs(snap.Context)...)
+	writeJSONBody(w, http.StatusOK, body)
+}
+
+// GET /vault/snapshots
+// Lists live snapshots without their contents. Session only.
+func (s *Server) handleListSnapshots(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	snaps, err := s.vault.Snapshots()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, snaps)
+}
+
+// DELETE /vault/snapshots/{id}
+// Session only.
+func (s *Server) handleDeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if err := s.vault.DeleteSnapshot(r.PathValue("id")); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// GET /vault/templates
+// Lists the per-consumer context templates. Session only.
+func (s *Server) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	templates, err := s.vault.ContextTemplates()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"templates": templates})
+}
+
+// PUT /vault/templates/{consumer}
+// Sets the context template applied to a consumer's /vault/context reads.
+// Session only.
+func (s *Server) handleSetTemplate(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	var req struct {
+		Fields []string         `json:"fields"`
+		Mask   []vault.MaskRule `json:"mask"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Fields) == 0 {
+		invalidField(w, "fields", "at least one field pattern required")
+		return
+	}
+	for _, p := range req.Fields {
+		if err := vault.ValidateScopePattern(p); err != nil {
+			invalidField(w, "fields", err.Error())
+			return
+		}
+	}
+	for _, m := range req.Mask {
+		if err := vault.ValidateScopePattern(m.Fields); err != nil {
+			invalidField(w, "mask", err.Error())
+			return
+		}
+		if !slices.Contains(vault.MaskRules, m.Rule) {
+			writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, vault.ErrInvalidMask.Error(), errorDetails{
+				"field":   "mask",
+				"allowed": vault.MaskRules,
+			})
+			return
+		}
+	}
+	t := vault.ContextTemplate{Consumer: r.PathValue("consumer"), Fields: req.Fields, Mask: req.Mask}
+	if err := s.vault.SetContextTemplate(t); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DELETE /vault/templates/{consumer}
+// Removes a consumer's context template. Session only.
+func (s *Server) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	if err := s.vault.DeleteContextTemplate(r.PathValue("consumer")); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// applyTemplate narrows b to the context template of the calling service
+// token's consumer, if it has one. Sessions and consumers without a template
+// get b as is.
+func (s *Server) applyTemplate(r *http.Request, b *vault.ContextBundle) (*vault.ContextBundle, error) {
+	t := serviceTokenFromRequest(r)
+	if t == nil {
+		return b, nil
+	}
+	tmpl, err := s.vault.TemplateFor(s.vault.TokenConsumers(t))
+	if err != nil || tmpl == nil {
+		return b, err
+	}
+	return tmpl.Apply(b), nil
+}
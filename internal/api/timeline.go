@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// timelineScanLimit caps how many audit entries one timeline request reads.
+const timelineScanLimit = 10000
+
+// disclosingActions are the audit actions that hand decrypted values to the
+// consumer, and so get a sensitivity on the timeline.
+var disclosingActions = map[string]bool{"read": true, "context": true, "history": true, "enrich": true}
+
+type timelineEvent struct {
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"`
+	Scope       string    `json:"scope"`
+	Purpose     string    `json:"purpose,omitempty"`
+	RequestID   string    `json:"request_id,omitempty"`
+	Fields      []string  `json:"fields,omitempty"` // what a read sent
+	Bytes       int       `json:"bytes,omitempty"`
+	Sensitivity string    `json:"sensitivity,omitempty"`
+}
+
+type consumerTimeline struct {
+	Consumer      string          `json:"consumer"`
+	Total         int             `json:"total"`
+	Denied        int             `json:"denied"`
+	BySensitivity map[string]int  `json:"by_sensitivity"`
+	Events        []timelineEvent `json:"events"`
+}
+
+// buildTimeline groups entries created at or after since by consumer, oldest
+// event first, tagging reads with the highest sensitivity they touched.
+func buildTimeline(entries []store.AuditEntry, fields []vault.FieldInfo, since time.Time) []consumerTimeline {
+	byConsumer := make(map[string]*consumerTimeline)
+	for _, e := range entries {
+		if e.CreatedAt.Before(since) {
+			continue
+		}
+		c := byConsumer[e.Consumer]
+		if c == nil {
+			c = &consumerTimeline{Consumer: e.Consumer, BySensitivity: make(map[string]int)}
+			byConsumer[e.Consumer] = c
+		}
+		ev := timelineEvent{
+			ID:        e.ID,
+			Time:      e.CreatedAt.UTC(),
+			Action:    e.Action,
+			Scope:     e.Scope,
+			Purpose:   e.Purpose,
+			RequestID: e.RequestID,
+			Fields:    e.Fields,
+			Bytes:     e.Bytes,
+		}
+		if disclosingActions[e.Action] {
+			// A read that names what it sent is tagged by that, not by
+			// everything its scope allowed.
+			sent := e.Scope
+			if len(e.Fields) > 0 {
+				sent = strings.Join(e.Fields, ",")
+			}
+			ev.Sensitivity = vault.ScopeSensitivity(sent, fields)
+			if ev.Sensitivity != "" {
+				c.BySensitivity[ev.Sensitivity]++
+			}
+		}
+		if e.Action == "denied" {
+			c.Denied++
+		}
+		c.Total++
+		c.Events = append(c.Events, ev)
+	}
+
+	result := make([]consumerTimeline, 0, len(byConsumer))
+	for _, c := range byConsumer {
+		sort.SliceStable(c.Events, func(i, j int) bool { return c.Events[i].Time.Before(c.Events[j].Time) })
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Consumer < result[j].Consumer })
+	return result
+}
+
+// GET /vault/audit/timeline?days=7
+func (s *Server) handleAuditTimeline(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 90 {
+			writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "days must be between 1 and 90",
+				errorDetails{"field": "days"})
+			return
+		}
+		days = n
+	}
+
+	fields, err := s.vault.List()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	entries, err := s.vault.AuditLog(timelineScanLimit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, constraintInternal, "internal error")
+		return
+	}
+
+	until := time.Now().UTC()
+	since := until.Add(-time.Duration(days) * 24 * time.Hour)
+	// Entries are newest first, so a full page whose oldest entry is still in
+	// range means older entries in the window were cut off.
+	truncated := len(entries) == timelineScanLimit && !entries[len(entries)-1].CreatedAt.Before(since)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"since":     since.Format(time.RFC3339),
+		"until":     until.Format(time.RFC3339),
+		"truncated": truncated,
+		"consumers": buildTimeline(entries, fields, since),
+	})
+}
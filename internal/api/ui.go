@@ -1,14 +1,126 @@
 package api
 
 import (
-	_ "embed"
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
 	"net/http"
+	"path"
+	"strings"
 )
 
-//go:embed ui/onboarding.html
-var onboardingHTML []byte
+//go:embed ui
+var uiFS embed.FS
 
-func (s *Server) handleUI(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write(onboardingHTML)
+// uiPages are the pages under ui/, each rendered with layout.html and served
+// at its route.
+var uiPages = []string{"onboarding", "consent", "manage"}
+
+// uiCSP only allows same-origin scripts and styles, so everything a page runs
+// must come from the embedded assets. Inline <script> and style="" are blocked.
+const uiCSP = "default-src 'none'; " +
+	"script-src 'self'; " +
+	"style-src 'self' https://fonts.googleapis.com; " +
+	"font-src https://fonts.gstatic.com; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"base-uri 'none'; " +
+	"form-action 'none'; " +
+	"frame-ancestors 'none'"
+
+const assetPrefix = "/ui/assets/"
+
+type uiAsset struct {
+	body        []byte
+	contentType string
+}
+
+// uiBundle is the rendered UI: pages with their asset URLs filled in, and
+// the static files keyed by their content-hashed names.
+type uiBundle struct {
+	pages  map[string][]byte
+	assets map[string]uiAsset
+}
+
+var ui = mustLoadUI(uiFS)
+
+func mustLoadUI(fsys fs.FS) *uiBundle {
+	b, err := loadUI(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// loadUI hashes every file under ui/static into a name like
+// "manage.1a2b3c4d5e.js", so a URL changes whenever its content does and
+// can be cached forever, then renders each page against those URLs.
+func loadUI(fsys fs.FS) (*uiBundle, error) {
+	b := &uiBundle{pages: make(map[string][]byte), assets: make(map[string]uiAsset)}
+	urls := make(map[string]string)
+
+	entries, err := fs.ReadDir(fsys, "ui/static")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		body, err := fs.ReadFile(fsys, "ui/static/"+e.Name())
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(body)
+		ext := path.Ext(e.Name())
+		hashed := strings.TrimSuffix(e.Name(), ext) + "." + hex.EncodeToString(sum[:5]) + ext
+		b.assets[hashed] = uiAsset{body: body, contentType: mime.TypeByExtension(ext)}
+		urls[e.Name()] = assetPrefix + hashed
+	}
+
+	funcs := template.FuncMap{
+		"asset": func(name string) (string, error) {
+			u, ok := urls[name]
+			if !ok {
+				return "", fmt.Errorf("unknown asset %q", name)
+			}
+			return u, nil
+		},
+	}
+	for _, page := range uiPages {
+		t, err := template.New(page+".html").Funcs(funcs).ParseFS(fsys, "ui/"+page+".html", "ui/layout.html")
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, map[string]string{"Page": page}); err != nil {
+			return nil, fmt.Errorf("render %s: %w", page, err)
+		}
+		b.pages[page] = buf.Bytes()
+	}
+	return b, nil
+}
+
+// uiPage serves one rendered page under the UI content security policy.
+func uiPage(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", uiCSP)
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Write(ui.pages[name])
+	}
+}
+
+// GET /ui/assets/{name}
+func (s *Server) handleUIAsset(w http.ResponseWriter, r *http.Request) {
+	a, ok := ui.assets[r.PathValue("name")]
+	if !ok {
+		writeErrorDetails(w, http.StatusNotFound, constraintNotFound, "asset not found", errorDetails{"id": r.PathValue("name")})
+		return
+	}
+	w.Header().Set("Content-Type", a.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Write(a.body)
 }
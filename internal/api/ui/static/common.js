@@ -0,0 +1,219 @@
+// Shared helpers for the vault UI pages: sign-in state, API calls,
+// DOM construction, notifications, and the theme toggle.
+var PV = (function() {
+  'use strict';
+
+  // Page parameters arrive in the URL fragment so they never reach server
+  // logs. There is no token among them: 'pvault ui' logs the browser in
+  // through /ui/login, which leaves an HttpOnly session cookie the page
+  // can't read and a CSRF token it can, to send with every request.
+  var params = new URLSearchParams(window.location.hash.slice(1));
+  if (window.location.hash) {
+    history.replaceState(null, '', window.location.pathname);
+  }
+  var csrf = (document.cookie.match(/(?:^|; )pvault_csrf=([^;]*)/) || [])[1] || '';
+
+  // Critical reads, exports, and wildcard tokens need the password again
+  // (step-up). The elevation token lasts a few minutes and is kept in memory
+  // only.
+  var elevation = '';
+
+  function api(method, path, body) {
+    return send(method, path, body).then(function(res) {
+      if (res.status === 403 && res.data.constraint === 'elevation_required') {
+        return elevate().then(function() { return send(method, path, body); });
+      }
+      return res;
+    }).then(function(res) {
+      if (!res.ok) {
+        toast(res.status === 401 ? 'Session expired. Please run pvault ui again.' : (res.data.error || 'Request failed.'), true);
+        throw new Error(res.data.constraint || 'request failed');
+      }
+      return res.data;
+    });
+  }
+
+  // API is the version prefix for /vault paths.
+  var API = '/v1';
+
+  function send(method, path, body) {
+    var opts = {
+      method: method,
+      headers: {
+        'X-CSRF-Token': csrf,
+        'Content-Type': 'application/json'
+      }
+    };
+    if (elevation) opts.headers['X-Vault-Elevation'] = elevation;
+    if (body) opts.body = JSON.stringify(body);
+    return fetch(window.location.origin + API + path, opts).then(function(r) {
+      return r.json().then(function(data) {
+        return { ok: r.ok, status: r.status, data: data };
+      });
+    });
+  }
+
+  // elevate asks for the password and trades it for an elevation token.
+  function elevate() {
+    return askPassword().then(function(password) {
+      return send('POST', '/vault/elevate', { password: password });
+    }).then(function(res) {
+      if (!res.ok) {
+        toast(res.data.error || 'Could not confirm the password.', true);
+        throw new Error(res.data.constraint || 'elevation failed');
+      }
+      elevation = res.data.elevation_token;
+    });
+  }
+
+  function askPassword() {
+    return new Promise(function(resolve, reject) {
+      var dialog = el('dialog', { 'class': 'elevate' });
+      var form = el('form', { method: 'dialog' });
+      var input = el('input', { type: 'password', autocomplete: 'current-password', 'aria-label': 'Password', required: '' });
+      var actions = el('div', { 'class': 'elevate-actions' });
+      var cancel = el('button', { type: 'button' }, 'Cancel');
+      actions.appendChild(cancel);
+      actions.appendChild(el('button', { type: 'submit' }, 'Confirm'));
+      form.appendChild(el('p', {}, 'This needs your password again.'));
+      form.appendChild(input);
+      form.appendChild(actions);
+      dialog.appendChild(form);
+      document.body.appendChild(dialog);
+
+      cancel.addEventListener('click', function() { dialog.close(); });
+      dialog.addEventListener('close', function() {
+        dialog.remove();
+        if (dialog.returnValue === 'ok' && input.value) {
+          resolve(input.value);
+        } else {
+          reject(new Error('elevation cancelled'));
+        }
+      });
+      form.addEventListener('submit', function() { dialog.returnValue = 'ok'; });
+      dialog.showModal();
+      input.focus();
+    });
+  }
+
+  function el(tag, attrs, text) {
+    var e = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function(k) { e.setAttribute(k, attrs[k]); });
+    if (text !== undefined) e.textContent = text;
+    return e;
+  }
+
+  function fmtTime(s) {
+    var d = new Date(s);
+    return isNaN(d) ? s : d.toLocaleString();
+  }
+
+  // --- Notifications ---
+
+  var toastTimer;
+  function toast(msg, isError, sticky) {
+    var t = document.getElementById('toast');
+    if (!t) {
+      t = el('div', { id: 'toast', 'class': 'toast', role: 'status' });
+      document.body.appendChild(t);
+    }
+    t.textContent = msg;
+    t.classList.toggle('error', !!isError);
+    // Force a reflow so a freshly created banner still slides in.
+    void t.offsetWidth;
+    t.classList.add('show');
+    clearTimeout(toastTimer);
+    if (!sticky) {
+      toastTimer = setTimeout(function() { t.classList.remove('show'); }, isError ? 5000 : 2500);
+    }
+  }
+
+  // --- Theme ---
+
+  function isDark() {
+    var theme = document.documentElement.getAttribute('data-theme');
+    if (theme) return theme === 'dark';
+    return !window.matchMedia('(prefers-color-scheme: light)').matches;
+  }
+
+  var toggle = el('button', { type: 'button', 'class': 'theme-toggle' });
+  function labelToggle() {
+    toggle.textContent = isDark() ? '☼' : '☾';
+    toggle.setAttribute('aria-label', isDark() ? 'Switch to light theme' : 'Switch to dark theme');
+  }
+  toggle.addEventListener('click', function() {
+    var next = isDark() ? 'light' : 'dark';
+    document.documentElement.setAttribute('data-theme', next);
+    localStorage.setItem('pvault-theme', next);
+    labelToggle();
+  });
+  labelToggle();
+  document.body.appendChild(toggle);
+
+  // --- Session countdown ---
+
+  // The vault locks itself after a stretch of inactivity. Show how long is
+  // left and offer to extend it near the end, rather than failing the next
+  // request with a 401. Polling GET /vault/session doesn't count as activity.
+  var WARN_SECONDS = 5 * 60;
+  var expiresAt = 0;
+  var session = el('button', { type: 'button', 'class': 'session-timer', hidden: '' });
+  session.addEventListener('click', function() {
+    api('POST', '/vault/session/refresh').then(showSession).catch(function() {});
+  });
+  document.body.appendChild(session);
+
+  function showSession(info) {
+    expiresAt = Date.now() + info.expires_in_seconds * 1000;
+    tick();
+  }
+
+  function pollSession() {
+    fetch(window.location.origin + API + '/vault/session')
+      .then(function(r) { return r.ok ? r.json() : null; })
+      .then(function(info) {
+        if (info) {
+          showSession(info);
+        } else if (expiresAt) {
+          // Locked from elsewhere ('pvault lock') or the token is stale.
+          expiresAt = Date.now();
+          tick();
+        }
+      })
+      .catch(function() {});
+  }
+
+  function tick() {
+    var left = Math.max(0, Math.round((expiresAt - Date.now()) / 1000));
+    if (!expiresAt) return;
+    if (left === 0) {
+      session.classList.remove('warn');
+      session.textContent = 'Locked';
+      session.disabled = true;
+      return;
+    }
+    var mins = Math.floor(left / 60);
+    var secs = ('0' + (left % 60)).slice(-2);
+    var warn = left <= WARN_SECONDS;
+    session.hidden = false;
+    session.disabled = false;
+    session.classList.toggle('warn', warn);
+    session.textContent = (warn ? 'Locks in ' + mins + ':' + secs + ' · stay unlocked' : 'Locks in ' + (mins + 1) + ' min');
+    session.setAttribute('aria-label', 'Session locks in ' + mins + ' minutes ' + secs + ' seconds; click to stay unlocked');
+  }
+
+  if (csrf) {
+    pollSession();
+    setInterval(pollSession, 30000);
+    setInterval(tick, 1000);
+  }
+
+  return {
+    params: params,
+    signedIn: !!csrf,
+    api: api,
+    el: el,
+    fmtTime: fmtTime,
+    toast: toast
+  };
+})();
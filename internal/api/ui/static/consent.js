@@ -0,0 +1,147 @@
+(function() {
+  'use strict';
+
+  const params = PV.params;
+  const consumer = params.get('consumer') || '';
+  const scope = params.get('scope') || '*';
+  const ttl = params.get('ttl') || '8760h';
+  const constraints = {};
+  if (params.get('hours')) constraints.hours = params.get('hours');
+  if (params.get('weekdays')) constraints.weekdays = params.get('weekdays').split(',');
+  if (params.get('max_per_day')) constraints.max_per_day = parseInt(params.get('max_per_day'), 10);
+  if (params.get('critical_per_day')) constraints.critical_per_day = parseInt(params.get('critical_per_day'), 10);
+  if (params.get('timezone')) constraints.timezone = params.get('timezone');
+  if (params.get('workload')) constraints.workload = JSON.parse(params.get('workload'));
+  if (params.get('list_out_of_scope') === 'true') constraints.list_out_of_scope = true;
+  const description = params.get('description') || '';
+  const labels = params.get('labels') ? JSON.parse(params.get('labels')) : {};
+
+  if (!PV.signedIn || !consumer) {
+    PV.toast('Missing request details. Run pvault create-service-token --review to open this page.', true, true);
+    return;
+  }
+
+  const grantBtn = document.getElementById('grantBtn');
+  let needsCritical = false;
+  let wildcard = false;
+
+  document.getElementById('consumer').textContent = consumer;
+  document.getElementById('consumerEcho').textContent = consumer;
+  document.getElementById('scope').textContent = scope;
+  document.getElementById('ttl').textContent = ttl;
+  var about = Object.keys(labels).sort().map(function(k) { return k + '=' + labels[k]; });
+  if (description) about.unshift(description);
+  if (about.length) {
+    document.getElementById('about').textContent = about.join(' · ');
+    document.getElementById('aboutBox').hidden = false;
+  }
+
+  var limits = [];
+  if (constraints.hours) limits.push(constraints.hours);
+  if (constraints.weekdays) limits.push(constraints.weekdays.join(','));
+  if (constraints.max_per_day) limits.push(constraints.max_per_day + '/day');
+  if (constraints.critical_per_day) limits.push(constraints.critical_per_day + ' reads/day of each critical field');
+  if (constraints.workload) limits.push('bound to one workload');
+  if (constraints.list_out_of_scope) limits.push('sees the names of fields outside its scope');
+  if (limits.length) {
+    document.getElementById('limits').textContent = limits.join(' · ');
+    document.getElementById('limitsBox').hidden = false;
+  }
+
+  // --- Preview ---
+
+  PV.api('POST', '/vault/tokens/service/preview', { scope: scope }).then(function(preview) {
+    wildcard = preview.all_fields;
+    needsCritical = (preview.by_sensitivity.critical || 0) > 0;
+
+    document.getElementById('fieldCount').textContent = preview.fields.length;
+    document.getElementById('criticalCount').textContent = preview.by_sensitivity.critical || 0;
+    document.getElementById('wildcardWarning').hidden = !wildcard;
+    document.getElementById('wildcardConfirm').hidden = !wildcard;
+    document.getElementById('criticalConfirm').hidden = !needsCritical;
+
+    renderFields(preview.fields);
+    updateGrant();
+  }).catch(function() {});
+
+  function renderFields(fields) {
+    var container = document.getElementById('fields');
+    if (fields.length === 0) {
+      var none = document.createElement('p');
+      none.className = 'none';
+      none.textContent = 'This scope matches no stored or recommended fields.';
+      container.appendChild(none);
+      return;
+    }
+
+    var byCategory = {};
+    fields.forEach(function(f) {
+      var cat = f.id.split('.')[0];
+      (byCategory[cat] = byCategory[cat] || []).push(f);
+    });
+
+    Object.keys(byCategory).sort().forEach(function(cat) {
+      var section = document.createElement('div');
+      section.className = 'category';
+      var h = document.createElement('h2');
+      h.textContent = cat;
+      section.appendChild(h);
+
+      byCategory[cat].forEach(function(f) {
+        var row = document.createElement('div');
+        row.className = 'field-item' + (f.stored ? '' : ' empty');
+        var name = document.createElement('span');
+        name.textContent = f.id.slice(cat.length + 1);
+        if (!f.stored) {
+          var note = document.createElement('span');
+          note.className = 'note';
+          note.textContent = 'not filled yet';
+          name.appendChild(note);
+        }
+        var tier = document.createElement('span');
+        tier.className = 'tier ' + f.sensitivity;
+        tier.textContent = f.sensitivity;
+        row.appendChild(name);
+        row.appendChild(tier);
+        section.appendChild(row);
+      });
+      container.appendChild(section);
+    });
+  }
+
+  // --- Confirmation ---
+
+  function updateGrant() {
+    var ok = true;
+    if (needsCritical && !document.getElementById('criticalCheck').checked) ok = false;
+    if (wildcard && document.getElementById('wildcardInput').value.trim() !== consumer) ok = false;
+    grantBtn.disabled = !ok;
+  }
+
+  document.getElementById('criticalCheck').addEventListener('change', updateGrant);
+  document.getElementById('wildcardInput').addEventListener('input', updateGrant);
+
+  grantBtn.addEventListener('click', function() {
+    if (grantBtn.disabled) return;
+    grantBtn.disabled = true;
+    PV.api('POST', '/vault/tokens/service', {
+      consumer: consumer,
+      scope: scope,
+      ttl: ttl,
+      constraints: constraints,
+      description: description,
+      labels: labels
+    }).then(function(result) {
+      document.getElementById('confirm').hidden = true;
+      document.getElementById('tokenOut').textContent = result.token;
+      document.getElementById('result').classList.add('show');
+    }).catch(function() {
+      updateGrant();
+    });
+  });
+
+  document.getElementById('denyBtn').addEventListener('click', function() {
+    document.getElementById('confirm').hidden = true;
+    PV.toast('Request denied. No token was created.', true, true);
+  });
+})();
@@ -0,0 +1,499 @@
+(function() {
+  'use strict';
+
+  if (!PV.signedIn) {
+    PV.toast('Not signed in. Run pvault ui manage to open this page.', true, true);
+    return;
+  }
+
+  var api = PV.api;
+  var el = PV.el;
+  var fmtTime = PV.fmtTime;
+  var toast = PV.toast;
+  var TIERS = ['public', 'standard', 'sensitive', 'critical'];
+  var schemaCategories = [];
+
+  function cell(text, cls) {
+    return el('td', cls ? { 'class': cls } : {}, text);
+  }
+
+  // --- Tabs ---
+
+  var loaders = { fields: loadFields, tokens: loadTokens, audit: loadAudit, timeline: loadTimeline };
+
+  document.querySelectorAll('.tab').forEach(function(tab) {
+    tab.addEventListener('click', function() {
+      var name = tab.getAttribute('data-tab');
+      document.querySelectorAll('.tab').forEach(function(t) { t.classList.toggle('active', t === tab); });
+      document.querySelectorAll('[data-panel]').forEach(function(p) {
+        p.hidden = p.getAttribute('data-panel') !== name;
+      });
+      loaders[name]();
+    });
+  });
+
+  // --- Fields ---
+
+  function loadFields() {
+    return api('GET', '/vault/fields').then(function(fields) {
+      var rows = document.getElementById('fieldRows');
+      rows.textContent = '';
+      if (fields.length === 0) {
+        var tr = el('tr');
+        tr.appendChild(el('td', { 'class': 'empty', colspan: '5' }, 'No fields yet.'));
+        rows.appendChild(tr);
+        return fields;
+      }
+      // Pinned fields come first, in a section of their own.
+      var pinned = fields.filter(function(f) { return f.pinned; });
+      if (pinned.length > 0) {
+        var pinHead = el('tr', { 'class': 'category-row' });
+        pinHead.appendChild(el('td', { colspan: '5' }, 'pinned'));
+        rows.appendChild(pinHead);
+        pinned.forEach(function(f) { rows.appendChild(fieldRow(f)); });
+      }
+      var lastCat = '';
+      fields.forEach(function(f) {
+        if (f.pinned) return;
+        if (f.category !== lastCat) {
+          lastCat = f.category;
+          var head = el('tr', { 'class': 'category-row' });
+          head.appendChild(el('td', { colspan: '5' }, f.category));
+          rows.appendChild(head);
+        }
+        rows.appendChild(fieldRow(f));
+      });
+      return fields;
+    }).catch(function() { return []; });
+  }
+
+  function fieldRow(f) {
+    var tr = el('tr');
+    var nameCell = cell(f.pinned ? f.id : f.field_name, 'mono');
+    if (f.note) nameCell.appendChild(el('div', { 'class': 'muted' }, f.note));
+    tr.appendChild(nameCell);
+
+    var valueCell = cell('••••••', 'mono muted');
+    tr.appendChild(valueCell);
+
+    var tierCell = el('td');
+    var tier = el('select');
+    TIERS.forEach(function(t) {
+      var o = el('option', { value: t }, t);
+      if (t === f.sensitivity) o.selected = true;
+      tier.appendChild(o);
+    });
+    tier.addEventListener('change', function() {
+      api('PUT', '/vault/sensitivity/' + f.id, { tier: tier.value })
+        .then(function() { toast(f.id + ' is now ' + tier.value); })
+        .catch(function() { tier.value = f.sensitivity; });
+    });
+    tierCell.appendChild(tier);
+    tr.appendChild(tierCell);
+
+    tr.appendChild(cell(fmtTime(f.updated_at), 'muted'));
+
+    var actions = el('td', { 'class': 'actions' });
+    var show = el('button', { type: 'button', 'class': 'link' }, 'show');
+    show.addEventListener('click', function() {
+      if (show.textContent === 'hide') {
+        valueCell.textContent = '••••••';
+        valueCell.classList.add('muted');
+        show.textContent = 'show';
+        return;
+      }
+      api('GET', fieldPath(f)).then(function(full) {
+        valueCell.textContent = full.entries
+          ? full.entries.map(function(e) { return e.value; }).join(', ')
+          : full.value;
+        valueCell.classList.remove('muted');
+        show.textContent = 'hide';
+      }).catch(function() {});
+    });
+    var edit = el('button', { type: 'button', 'class': 'link' }, 'edit');
+    edit.addEventListener('click', function() { editField(f, valueCell, edit); });
+    var del = el('button', { type: 'button', 'class': 'link danger' }, 'delete');
+    del.addEventListener('click', function() {
+      if (!confirm('Delete ' + f.id + '? This cannot be undone.')) return;
+      api('DELETE', '/vault/fields/' + f.id).then(function() {
+        toast('Deleted ' + f.id);
+        loadFields();
+      }).catch(function() {});
+    });
+    var pin = el('button', { type: 'button', 'class': 'link' }, f.pinned ? 'unpin' : 'pin');
+    pin.addEventListener('click', function() {
+      api(f.pinned ? 'DELETE' : 'PUT', '/vault/pins/' + f.id).then(loadFields).catch(function() {});
+    });
+    var note = el('button', { type: 'button', 'class': 'link' }, 'note');
+    note.addEventListener('click', function() {
+      var text = prompt('Note for ' + f.id + ' (empty to clear):', f.note || '');
+      if (text === null) return;
+      api('PUT', '/vault/notes/' + f.id, { note: text.trim() }).then(loadFields).catch(function() {});
+    });
+    actions.appendChild(show);
+    actions.appendChild(edit);
+    actions.appendChild(pin);
+    actions.appendChild(note);
+    actions.appendChild(del);
+    tr.appendChild(actions);
+    return tr;
+  }
+
+  // fieldPath reads a field's value, asking to reveal it, which the audit
+  // log records, when it's critical or a PEM block.
+  function fieldPath(f) {
+    var path = '/vault/fields/' + f.id;
+    if (f.sensitivity === 'critical' || f.type === 'pem') path += '?reveal=true';
+    return path;
+  }
+
+  function editField(f, valueCell, editBtn) {
+    api('GET', fieldPath(f)).then(function(full) {
+      if (full.entries) {
+        editEntries(f, full.entries, valueCell, editBtn);
+        return;
+      }
+      var input = el('input', { type: 'text' });
+      input.value = full.value;
+      valueCell.textContent = '';
+      valueCell.classList.remove('muted');
+      valueCell.appendChild(input);
+      input.focus();
+      editBtn.hidden = true;
+
+      var done = false;
+      function save() {
+        if (done) return;
+        done = true;
+        var val = input.value.trim();
+        if (!val || val === full.value) {
+          loadFields();
+          return;
+        }
+        api('PUT', '/vault/fields/' + f.id, { value: val, sensitivity: f.sensitivity })
+          .then(function() { toast('Saved ' + f.id); loadFields(); })
+          .catch(function() {});
+      }
+      input.addEventListener('keydown', function(e) {
+        if (e.key === 'Enter') { e.preventDefault(); save(); }
+        if (e.key === 'Escape') { done = true; loadFields(); }
+      });
+      input.addEventListener('blur', save);
+    }).catch(function() {});
+  }
+
+  // editEntries lists a multi-valued field's entries, each with a remove
+  // link, and a line to add another.
+  function editEntries(f, entries, valueCell, editBtn) {
+    valueCell.textContent = '';
+    valueCell.classList.remove('muted');
+    editBtn.hidden = true;
+    entries.forEach(function(e, i) {
+      var tags = [e.label, e.primary ? 'primary' : ''].filter(Boolean).join(', ');
+      var line = el('div', {}, e.value + (tags ? ' (' + tags + ')' : ''));
+      var rm = el('button', { type: 'button', 'class': 'link danger' }, 'remove');
+      rm.addEventListener('click', function() {
+        api('DELETE', '/vault/entries/' + f.id + '?entry=' + encodeURIComponent(e.label || String(i + 1)))
+          .then(loadFields).catch(function() {});
+      });
+      line.appendChild(rm);
+      valueCell.appendChild(line);
+    });
+    var label = el('input', { type: 'text', placeholder: 'label' });
+    var input = el('input', { type: 'text', placeholder: 'another value' });
+    input.addEventListener('keydown', function(e) {
+      if (e.key === 'Escape') { loadFields(); return; }
+      if (e.key !== 'Enter' || !input.value.trim()) return;
+      e.preventDefault();
+      api('POST', '/vault/entries/' + f.id, { value: input.value.trim(), label: label.value.trim() })
+        .then(function() { toast('Saved ' + f.id); loadFields(); })
+        .catch(function() {});
+    });
+    valueCell.appendChild(label);
+    valueCell.appendChild(input);
+    input.focus();
+  }
+
+  document.getElementById('addField').addEventListener('submit', function(e) {
+    e.preventDefault();
+    var form = e.target;
+    var fields = form.elements;
+    var id = fields.field_id.value.trim();
+    var body = { value: fields.value.value.trim() };
+    if (fields.sensitivity.value) body.sensitivity = fields.sensitivity.value;
+    api('PUT', '/vault/fields/' + id, body).then(function(resp) {
+      var msg = 'Saved ' + id;
+      if (resp.suggestion) msg += ' — did you mean ' + resp.suggestion.canonical + '?';
+      toast(msg);
+      form.reset();
+      loadFields();
+    }).catch(function() {});
+  });
+
+  // --- Tokens ---
+
+  function loadTokens() {
+    return Promise.all([api('GET', '/vault/tokens/service'), loadFields()]).then(function(res) {
+      renderTokens(res[0]);
+      renderScopeBuilder(res[1]);
+    }).catch(function() {});
+  }
+
+  function renderTokens(tokens) {
+    var rows = document.getElementById('tokenRows');
+    rows.textContent = '';
+    if (tokens.length === 0) {
+      var tr = el('tr');
+      tr.appendChild(el('td', { 'class': 'empty', colspan: '6' }, 'No service tokens.'));
+      rows.appendChild(tr);
+      return;
+    }
+    tokens.forEach(function(t) {
+      var tr = el('tr');
+      tr.appendChild(cell(t.token_prefix, 'mono'));
+      var consumer = cell(t.consumer);
+      var about = Object.keys(t.labels || {}).sort().map(function(k) { return k + '=' + t.labels[k]; });
+      if (t.description) about.unshift(t.description);
+      if (about.length) consumer.appendChild(el('div', { 'class': 'muted' }, about.join(' · ')));
+      tr.appendChild(consumer);
+      tr.appendChild(cell(t.scope, 'mono'));
+      var limits = [];
+      if (t.constraints) {
+        if (t.constraints.hours) limits.push(t.constraints.hours);
+        if (t.constraints.weekdays) limits.push(t.constraints.weekdays.join(','));
+        if (t.constraints.max_per_day) limits.push(t.constraints.max_per_day + '/day');
+        if (t.constraints.critical_per_day) limits.push(t.constraints.critical_per_day + ' critical/day');
+        if (t.constraints.workload) limits.push('workload-bound');
+        if (t.constraints.list_out_of_scope) limits.push('lists out of scope');
+      }
+      tr.appendChild(cell(limits.join(' · ') || '—', 'muted'));
+      tr.appendChild(cell(fmtTime(t.expires_at), 'muted'));
+      var actions = el('td', { 'class': 'actions' });
+      var revoke = el('button', { type: 'button', 'class': 'link danger' }, 'revoke');
+      revoke.addEventListener('click', function() {
+        if (!confirm('Revoke the token for ' + t.consumer + '? Apps using it stop working immediately.')) return;
+        api('DELETE', '/vault/tokens/service/' + encodeURIComponent(t.token_prefix)).then(function() {
+          toast('Revoked token for ' + t.consumer);
+          loadTokens();
+        }).catch(function() {});
+      });
+      actions.appendChild(revoke);
+      tr.appendChild(actions);
+      rows.appendChild(tr);
+    });
+  }
+
+  function renderScopeBuilder(fields) {
+    var cats = {};
+    schemaCategories.forEach(function(c) { cats[c] = true; });
+    fields.forEach(function(f) { cats[f.category] = true; });
+
+    var builder = document.getElementById('scopeBuilder');
+    var checked = {};
+    builder.querySelectorAll('input:checked').forEach(function(i) { checked[i.value] = true; });
+    builder.textContent = '';
+
+    ['*'].concat(Object.keys(cats).sort().map(function(c) { return c + '.*'; })).forEach(function(pattern) {
+      var label = el('label');
+      var box = el('input', { type: 'checkbox', value: pattern });
+      box.checked = !!checked[pattern];
+      box.addEventListener('change', updateScopePreview);
+      label.appendChild(box);
+      label.appendChild(document.createTextNode(pattern === '*' ? '* (everything)' : pattern));
+      builder.appendChild(label);
+    });
+    updateScopePreview();
+  }
+
+  function selectedScope() {
+    var parts = [];
+    document.querySelectorAll('#scopeBuilder input:checked').forEach(function(i) { parts.push(i.value); });
+    return parts.indexOf('*') >= 0 ? '*' : parts.join(',');
+  }
+
+  function updateScopePreview() {
+    document.getElementById('scopePreview').textContent = selectedScope() || 'nothing selected';
+  }
+
+  // Creation goes through the consent screen so the grant is reviewed field by field.
+  document.getElementById('createToken').addEventListener('submit', function(e) {
+    e.preventDefault();
+    var fields = e.target.elements;
+    var scope = selectedScope();
+    if (!scope) {
+      toast('Pick at least one category for the scope.', true);
+      return;
+    }
+    var params = new URLSearchParams();
+    params.set('consumer', fields.consumer.value.trim());
+    params.set('scope', scope);
+    params.set('ttl', fields.ttl.value);
+    if (fields.hours.value.trim()) params.set('hours', fields.hours.value.trim());
+    if (fields.max_per_day.value) params.set('max_per_day', fields.max_per_day.value);
+    if (fields.description.value.trim()) params.set('description', fields.description.value.trim());
+    window.location.href = '/ui/consent#' + params.toString();
+  });
+
+  // --- Audit ---
+
+  var auditEntries = [];
+
+  function loadAudit() {
+    return api('GET', '/vault/audit?limit=1000').then(function(entries) {
+      auditEntries = entries || [];
+      fillOptions('auditConsumer', auditEntries.map(function(e) { return e.Consumer; }));
+      fillOptions('auditAction', auditEntries.map(function(e) { return e.Action; }));
+      renderAudit();
+    }).catch(function() {});
+  }
+
+  function fillOptions(id, values) {
+    var select = document.getElementById(id);
+    var current = select.value;
+    var unique = Array.from(new Set(values.filter(Boolean))).sort();
+    select.textContent = '';
+    select.appendChild(el('option', { value: '' }, 'all'));
+    unique.forEach(function(v) { select.appendChild(el('option', { value: v }, v)); });
+    select.value = current;
+  }
+
+  function renderAudit() {
+    var consumer = document.getElementById('auditConsumer').value;
+    var action = document.getElementById('auditAction').value;
+    var since = document.getElementById('auditSince').value;
+    var search = document.getElementById('auditSearch').value.trim().toLowerCase();
+    var sinceTime = since ? new Date(since + 'T00:00:00').getTime() : 0;
+
+    var rows = document.getElementById('auditRows');
+    rows.textContent = '';
+    var shown = auditEntries.filter(function(e) {
+      if (consumer && e.Consumer !== consumer) return false;
+      if (action && e.Action !== action) return false;
+      if (sinceTime && new Date(e.CreatedAt).getTime() < sinceTime) return false;
+      if (search) {
+        var hay = [e.Scope, e.Purpose, e.RequestID].join(' ').toLowerCase();
+        if (hay.indexOf(search) < 0) return false;
+      }
+      return true;
+    });
+    if (shown.length === 0) {
+      var tr = el('tr');
+      tr.appendChild(el('td', { 'class': 'empty', colspan: '6' }, 'No matching entries.'));
+      rows.appendChild(tr);
+      return;
+    }
+    shown.forEach(function(e) {
+      var tr = el('tr');
+      tr.appendChild(cell(fmtTime(e.CreatedAt), 'muted'));
+      tr.appendChild(cell(e.Consumer));
+      tr.appendChild(cell(e.Action));
+      tr.appendChild(cell(e.Scope, 'mono'));
+      tr.appendChild(cell(e.Purpose || '', 'muted'));
+      tr.appendChild(cell(e.RequestID || '', 'mono muted'));
+      rows.appendChild(tr);
+    });
+  }
+
+  ['auditConsumer', 'auditAction', 'auditSince'].forEach(function(id) {
+    document.getElementById(id).addEventListener('change', renderAudit);
+  });
+  document.getElementById('auditSearch').addEventListener('input', renderAudit);
+
+  // --- Timeline ---
+
+  function loadTimeline() {
+    var days = document.getElementById('timelineDays').value;
+    return api('GET', '/vault/audit/timeline?days=' + days).then(renderTimeline).catch(function() {});
+  }
+
+  function renderTimeline(tl) {
+    var start = new Date(tl.since).getTime();
+    var end = new Date(tl.until).getTime();
+    var lanes = document.getElementById('lanes');
+    lanes.textContent = '';
+    document.getElementById('drill').hidden = true;
+
+    if (tl.consumers.length === 0) {
+      lanes.appendChild(el('p', { 'class': 'empty' }, 'No activity in this period.'));
+      document.getElementById('axis').hidden = true;
+      return;
+    }
+    if (tl.truncated) toast('Showing the most recent entries only; older activity in this period was cut off.', true);
+
+    tl.consumers.forEach(function(c) {
+      var lane = el('div', { 'class': 'lane' });
+      var name = el('div', { 'class': 'lane-name', title: 'Show all entries for ' + c.consumer }, c.consumer);
+      var parts = [c.total + ' events'];
+      if (c.by_sensitivity.critical) parts.push(c.by_sensitivity.critical + ' critical');
+      if (c.denied) parts.push(c.denied + ' denied');
+      name.appendChild(el('small', {}, parts.join(' · ')));
+      name.addEventListener('click', function() { drill(c.consumer, c.events); });
+      lane.appendChild(name);
+
+      var track = el('div', { 'class': 'track' });
+      c.events.forEach(function(ev) {
+        var t = new Date(ev.time).getTime();
+        var pct = end > start ? (t - start) / (end - start) * 100 : 100;
+        var dot = el('button', {
+          type: 'button',
+          'class': 'dot' + (ev.action === 'denied' ? ' denied' : ''),
+          'data-tier': ev.sensitivity || 'none',
+          title: fmtTime(ev.time) + ' — ' + ev.action + ' ' + ev.scope
+        });
+        dot.style.left = Math.max(0, Math.min(100, pct)) + '%';
+        dot.addEventListener('click', function() {
+          drill(c.consumer, c.events.filter(function(o) {
+            return ev.request_id ? o.request_id === ev.request_id : o.id === ev.id;
+          }), ev.request_id ? 'request ' + ev.request_id : fmtTime(ev.time));
+        });
+        track.appendChild(dot);
+      });
+      lane.appendChild(track);
+      lanes.appendChild(lane);
+    });
+
+    document.getElementById('axisStart').textContent = new Date(start).toLocaleDateString();
+    document.getElementById('axisEnd').textContent = 'now';
+    document.getElementById('axis').hidden = false;
+  }
+
+  // drill lists raw entries, newest first.
+  function drill(consumer, events, detail) {
+    var title = document.getElementById('drillTitle');
+    title.textContent = consumer;
+    if (detail) title.appendChild(el('small', {}, detail));
+
+    var rows = document.getElementById('drillRows');
+    rows.textContent = '';
+    events.slice().reverse().forEach(function(ev) {
+      var tr = el('tr');
+      tr.appendChild(cell(fmtTime(ev.time), 'muted'));
+      tr.appendChild(cell(ev.action));
+      tr.appendChild(cell(ev.scope, 'mono'));
+      var tier = cell(ev.sensitivity || '—');
+      if (ev.sensitivity) {
+        tier.setAttribute('data-tier', ev.sensitivity);
+        tier.style.color = 'var(--dot)';
+      }
+      tr.appendChild(tier);
+      tr.appendChild(cell(ev.purpose || '', 'muted'));
+      tr.appendChild(cell(ev.request_id || '', 'mono muted'));
+      rows.appendChild(tr);
+    });
+    document.getElementById('drill').hidden = false;
+  }
+
+  document.getElementById('timelineDays').addEventListener('change', loadTimeline);
+
+  // --- Init ---
+
+  fetch('/v1/vault/schema').then(function(r) { return r.json(); }).then(function(schema) {
+    var list = document.getElementById('schemaIds');
+    schema.categories.forEach(function(c) {
+      schemaCategories.push(c.name);
+      c.fields.forEach(function(f) { list.appendChild(el('option', { value: f.id }, f.description)); });
+    });
+  }).catch(function() {});
+
+  loadFields();
+})();
@@ -0,0 +1,209 @@
+(function() {
+  'use strict';
+
+  if (!PV.signedIn) {
+    PV.toast('Not signed in. Run pvault ui to open this page.', true, true);
+    return;
+  }
+
+  const api = PV.api;
+  const inputs = document.querySelectorAll('[data-field]');
+  const totalCount = inputs.length;
+  let filledCount = 0;
+
+  document.getElementById('totalCount').textContent = totalCount;
+
+  // Categories to load
+  const categories = ['identity', 'addresses', 'employment', 'financial', 'payment', 'preferences'];
+
+  // --- Load existing values ---
+
+  function loadCategory(cat) {
+    return api('GET', '/vault/fields/category/' + cat).then(function(fields) {
+      if (!Array.isArray(fields)) return;
+      fields.forEach(function(f) {
+        const input = document.querySelector('[data-field="' + f.id + '"]');
+        if (input && f.value) {
+          input.value = f.value;
+        }
+      });
+    }).catch(function() {});
+  }
+
+  Promise.all(categories.map(loadCategory)).then(updateProgress);
+
+  // --- Auto-save on blur ---
+
+  inputs.forEach(function(input) {
+    let lastSaved = '';
+
+    input.addEventListener('blur', function() {
+      const val = input.value.trim();
+      const fieldId = input.getAttribute('data-field');
+      const statusEl = input.closest('.field').querySelector('.status');
+
+      if (!val || val === lastSaved) return;
+
+      setStatus(statusEl, 'saving');
+
+      api('PUT', '/vault/fields/' + fieldId, { value: val })
+        .then(function() {
+          lastSaved = val;
+          setStatus(statusEl, 'saved');
+          updateProgress();
+          if (fieldId.indexOf('addresses.home_') === 0) scheduleEnrich();
+          setTimeout(function() { setStatus(statusEl, 'idle'); }, 2000);
+        })
+        .catch(function() {
+          setStatus(statusEl, 'error');
+        });
+    });
+
+    // Also save on Enter for text inputs
+    if (input.tagName === 'INPUT') {
+      input.addEventListener('keydown', function(e) {
+        if (e.key === 'Enter') {
+          e.preventDefault();
+          input.blur();
+        }
+      });
+    }
+
+    // Selects: save on change
+    if (input.tagName === 'SELECT') {
+      input.addEventListener('change', function() {
+        input.blur();
+        // Re-trigger blur logic
+        const event = new Event('blur');
+        input.dispatchEvent(event);
+      });
+    }
+  });
+
+  // --- Address suggestions ---
+  // When the server has an address enricher, saved address fields are sent
+  // to it and its corrections are offered here. Nothing is written until a
+  // suggestion is accepted.
+
+  const suggestionsEl = document.getElementById('addressSuggestions');
+  let enricher = false;
+  let enrichTimer;
+
+  api('GET', '/vault/suggestions').then(function(data) {
+    enricher = !!data.enricher;
+    renderSuggestions(data.suggestions || []);
+  }).catch(function() {});
+
+  function scheduleEnrich() {
+    if (!enricher) return;
+    clearTimeout(enrichTimer);
+    enrichTimer = setTimeout(function() {
+      api('POST', '/vault/enrich/address', { prefix: 'home' })
+        .then(function() { return api('GET', '/vault/suggestions'); })
+        .then(function(data) { renderSuggestions(data.suggestions || []); })
+        .catch(function() {});
+    }, 1500);
+  }
+
+  function renderSuggestions(list) {
+    list = list.filter(function(s) { return s.field_id.indexOf('addresses.home_') === 0; });
+    suggestionsEl.textContent = '';
+    suggestionsEl.hidden = list.length === 0;
+    if (!list.length) return;
+    suggestionsEl.appendChild(PV.el('div', { 'class': 'hint' }, 'Suggested by ' + list[0].source));
+    list.forEach(function(s) {
+      const row = PV.el('div', { 'class': 'suggestion' });
+      const label = document.querySelector('[data-field="' + s.field_id + '"]').closest('.field').querySelector('label');
+      row.appendChild(PV.el('span', {}, label.textContent));
+      const value = PV.el('span', { 'class': 'value' });
+      if (s.current) {
+        value.appendChild(PV.el('del', {}, s.current));
+        value.appendChild(document.createTextNode(' → '));
+      }
+      value.appendChild(document.createTextNode(s.suggested));
+      row.appendChild(value);
+
+      const accept = PV.el('button', { type: 'button', 'class': 'accept' }, 'Accept');
+      accept.addEventListener('click', function() {
+        api('POST', '/vault/suggestions/' + s.id + '/accept').then(function(res) {
+          document.querySelector('[data-field="' + s.field_id + '"]').value = res.value;
+          updateProgress();
+          row.remove();
+          if (!suggestionsEl.querySelector('.suggestion')) suggestionsEl.hidden = true;
+        }).catch(function() {});
+      });
+      const dismiss = PV.el('button', { type: 'button' }, 'Dismiss');
+      dismiss.addEventListener('click', function() {
+        api('DELETE', '/vault/suggestions/' + s.id).then(function() {
+          row.remove();
+          if (!suggestionsEl.querySelector('.suggestion')) suggestionsEl.hidden = true;
+        }).catch(function() {});
+      });
+      row.appendChild(accept);
+      row.appendChild(dismiss);
+      suggestionsEl.appendChild(row);
+    });
+  }
+
+  // --- Status indicator ---
+
+  function setStatus(el, state) {
+    el.className = 'status ' + state;
+    var labels = { saved: 'Saved', saving: 'Saving...', error: 'Error', idle: '' };
+    el.textContent = labels[state] || '';
+  }
+
+  // --- Progress ---
+
+  function updateProgress() {
+    filledCount = 0;
+    inputs.forEach(function(input) {
+      if (input.value.trim()) filledCount++;
+    });
+    document.getElementById('filledCount').textContent = filledCount;
+    var pct = totalCount > 0 ? (filledCount / totalCount * 100) : 0;
+    document.getElementById('progress').style.width = pct + '%';
+  }
+
+  // --- Scroll reveal + nav dots ---
+
+  var sections = document.querySelectorAll('.section');
+  var navDots = document.getElementById('navDots');
+
+  sections.forEach(function(_, i) {
+    var dot = document.createElement('button');
+    dot.className = 'nav-dot';
+    dot.setAttribute('aria-label', 'Section ' + (i + 1));
+    dot.addEventListener('click', function() {
+      sections[i].scrollIntoView({ behavior: 'smooth', block: 'start' });
+    });
+    navDots.appendChild(dot);
+  });
+
+  var dots = navDots.querySelectorAll('.nav-dot');
+
+  var observer = new IntersectionObserver(function(entries) {
+    entries.forEach(function(entry) {
+      if (entry.isIntersecting) {
+        entry.target.classList.add('visible');
+        var idx = parseInt(entry.target.getAttribute('data-section'), 10);
+        dots.forEach(function(d, i) {
+          d.classList.toggle('active', i === idx);
+        });
+      }
+    });
+  }, { threshold: 0.15, rootMargin: '-40px 0px' });
+
+  sections.forEach(function(s) { observer.observe(s); });
+
+  // --- Password toggle ---
+
+  document.querySelectorAll('[data-toggle]').forEach(function(btn) {
+    btn.addEventListener('click', function() {
+      var input = btn.parentElement.querySelector('input');
+      var isPassword = input.type === 'password';
+      input.type = isPassword ? 'text' : 'password';
+      btn.textContent = isPassword ? 'hide' : 'show';
+    });
+  });
+})();
@@ -0,0 +1,8 @@
+// Applies the saved theme before first paint. Loaded synchronously in <head>.
+(function() {
+  'use strict';
+  var theme = localStorage.getItem('pvault-theme');
+  if (theme === 'light' || theme === 'dark') {
+    document.documentElement.setAttribute('data-theme', theme);
+  }
+})();
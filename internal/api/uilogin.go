@@ -0,0 +1,188 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// The browser UI never sees a session token. The CLI trades its session for
+// a one-time login code and opens /ui/login?code=...; redeeming the code sets
+// an HttpOnly, SameSite=Strict cookie naming a server-side ticket for that
+// session, plus a CSRF token the page echoes in a header on every write. The
+// code is dead once used and within a minute regardless, so the one URL that
+// carries it is worthless in history or a Referer.
+const (
+	uiCookie        = "pvault_ui"
+	csrfCookie      = "pvault_csrf"
+	csrfHeader      = "X-CSRF-Token"
+	uiLoginCodeTTL  = time.Minute
+	maxUILoginCodes = 16
+)
+
+type uiLoginCode struct {
+	token   string
+	expires time.Time
+}
+
+type uiTicket struct {
+	token string
+	csrf  string
+}
+
+// uiLogins holds pending login codes and the tickets browsers were given.
+type uiLogins struct {
+	mu      sync.Mutex
+	codes   map[string]uiLoginCode
+	tickets map[string]uiTicket
+}
+
+func newUILogins() *uiLogins {
+	return &uiLogins{codes: make(map[string]uiLoginCode), tickets: make(map[string]uiTicket)}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// issue returns a login code for the session token.
+func (l *uiLogins) issue(token string) (string, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	for code, c := range l.codes {
+		if now.After(c.expires) {
+			delete(l.codes, code)
+		}
+	}
+	if len(l.codes) >= maxUILoginCodes {
+		clear(l.codes) // nobody opens sixteen pages a minute; start over
+	}
+	code := randomHex(32)
+	expires := now.Add(uiLoginCodeTTL)
+	l.codes[code] = uiLoginCode{token: token, expires: expires}
+	return code, expires
+}
+
+// redeem spends a login code on a new ticket and returns it with its CSRF
+// token.
+func (l *uiLogins) redeem(code string) (ticket string, t uiTicket, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c, found := l.codes[code]
+	delete(l.codes, code)
+	if !found || time.Now().After(c.expires) {
+		return "", uiTicket{}, false
+	}
+	ticket = randomHex(32)
+	t = uiTicket{token: c.token, csrf: randomHex(32)}
+	l.tickets[ticket] = t
+	return ticket, t, true
+}
+
+func (l *uiLogins) ticket(id string) (uiTicket, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, ok := l.tickets[id]
+	return t, ok
+}
+
+func (l *uiLogins) forget(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.tickets, id)
+}
+
+// prune drops expired login codes and the tickets of sessions that have
+// ended, and returns how many tickets it dropped.
+func (l *uiLogins) prune(alive func(token string) bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	for code, c := range l.codes {
+		if now.After(c.expires) {
+			delete(l.codes, code)
+		}
+	}
+	n := 0
+	for id, t := range l.tickets {
+		if !alive(t.token) {
+			delete(l.tickets, id)
+			n++
+		}
+	}
+	return n
+}
+
+// PruneUITickets forgets the browser logins of sessions that have ended,
+// which are otherwise only dropped when their cookie is next presented, and
+// returns how many it forgot.
+func (s *Server) PruneUITickets() int {
+	return s.uiLogins.prune(s.vault.ValidateToken)
+}
+
+// POST /vault/ui/login
+// Session only: mints a one-time code that logs a browser into this session.
+func (s *Server) handleUILoginCode(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	code, expires := s.uiLogins.issue(sessionTokenFromRequest(r))
+	writeJSON(w, http.StatusOK, map[string]string{
+		"code":       code,
+		"expires_at": expires.UTC().Format(time.RFC3339),
+	})
+}
+
+// GET /ui/login?code=...&next=manage
+// Redeems a login code, sets the UI cookies, and redirects to the page,
+// leaving the code out of the address bar.
+func (s *Server) handleUILogin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	ticket, t, ok := s.uiLogins.redeem(r.URL.Query().Get("code"))
+	if !ok || !s.vault.ValidateToken(t.token) {
+		s.uiLogins.forget(ticket)
+		writeErrorDetails(w, http.StatusUnauthorized, constraintUnauthenticated, "this link has expired or was already used; run pvault ui again",
+			errorDetails{"reason": "invalid_login_code"})
+		return
+	}
+	secure := r.TLS != nil
+	http.SetCookie(w, &http.Cookie{Name: uiCookie, Value: ticket, Path: "/", HttpOnly: true, Secure: secure, SameSite: http.SameSiteStrictMode})
+	http.SetCookie(w, &http.Cookie{Name: csrfCookie, Value: t.csrf, Path: "/", Secure: secure, SameSite: http.SameSiteStrictMode})
+
+	next := "/ui"
+	if page := r.URL.Query().Get("next"); page != "onboarding" && slices.Contains(uiPages, page) {
+		next = "/ui/" + page
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// uiSessionToken returns the session token behind the request's UI cookie.
+// Requests that can change anything must also carry the ticket's CSRF token,
+// which a page on another site can't read.
+func (s *Server) uiSessionToken(r *http.Request) (token string, csrfOK bool, ok bool) {
+	c, err := r.Cookie(uiCookie)
+	if err != nil {
+		return "", false, false
+	}
+	t, ok := s.uiLogins.ticket(c.Value)
+	if !ok {
+		return "", false, false
+	}
+	if !s.vault.ValidateToken(t.token) {
+		s.uiLogins.forget(c.Value) // locked or revoked; the ticket is dead too
+		return "", false, false
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.token, true, true
+	}
+	return t.token, subtle.ConstantTimeCompare([]byte(r.Header.Get(csrfHeader)), []byte(t.csrf)) == 1, true
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// PUT /vault/types/{id...}
+// Sets a field's value type (json, pem, url, number); text or an empty type
+// clears it. Session only.
+func (s *Server) handleSetValueType(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	id := r.PathValue("id")
+	if err := vault.ValidateFieldID(id); err != nil {
+		invalidField(w, "id", err.Error())
+		return
+	}
+	var req struct {
+		Type string `json:"type"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := s.vault.SetValueType(id, req.Type); err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
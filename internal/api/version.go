@@ -0,0 +1,117 @@
+package api
+
+import (
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiVersionHeader names the API version a response was served by. A client
+// may also send it to pick a version for an unversioned path.
+const apiVersionHeader = "API-Version"
+
+// currentAPIVersion is the version new clients should use.
+const currentAPIVersion = "1"
+
+// apiVersion is one version of the /vault API, served under /v<n>/vault/.
+// Setting Deprecated announces its end (Deprecation header); setting Sunset
+// says when (Sunset header). Both stay zero while it is current.
+type apiVersion struct {
+	Deprecated time.Time
+	Sunset     time.Time
+}
+
+// apiVersions are the versions served. Retiring one means deprecating it
+// here for at least a release before removing it.
+var apiVersions = map[string]apiVersion{
+	"1": {},
+}
+
+// unversionedDeprecated is when bare /vault/ paths were deprecated in favor
+// of /v1/vault/. They are still served, as v1, for clients written before
+// versioning.
+var unversionedDeprecated = time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+var versionSegment = regexp.MustCompile(`^/v([0-9]+)(/.*)?$`)
+
+// versionMiddleware serves /v<n>/vault/... as /vault/... under version n,
+// and bare /vault/... as the version in the API-Version header, else v1 with
+// deprecation headers pointing at the versioned path. Paths outside /vault
+// (the UI, health probes) aren't versioned.
+func versionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested := r.Header.Get(apiVersionHeader)
+		if m := versionSegment.FindStringSubmatch(r.URL.Path); m != nil {
+			version, rest := m[1], m[2]
+			if _, ok := apiVersions[version]; !ok || !isVaultPath(rest) {
+				writeErrorDetails(w, http.StatusNotFound, constraintNotFound, "unknown API version or path", errorDetails{
+					"supported_versions": supportedVersions(),
+				})
+				return
+			}
+			if requested != "" && requested != version {
+				writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "API-Version header doesn't match the path", errorDetails{
+					"field":   apiVersionHeader,
+					"allowed": []string{version},
+				})
+				return
+			}
+			setVersionHeaders(w, version)
+			http.StripPrefix("/v"+version, next).ServeHTTP(w, r)
+			return
+		}
+		if !isVaultPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if requested != "" {
+			if _, ok := apiVersions[requested]; !ok {
+				writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, "unsupported API version", errorDetails{
+					"field":   apiVersionHeader,
+					"allowed": supportedVersions(),
+				})
+				return
+			}
+			setVersionHeaders(w, requested)
+			next.ServeHTTP(w, r)
+			return
+		}
+		setVersionHeaders(w, currentAPIVersion)
+		if w.Header().Get("Deprecation") == "" {
+			w.Header().Set("Deprecation", httpDeprecation(unversionedDeprecated))
+		}
+		w.Header().Add("Link", `</v`+currentAPIVersion+r.URL.Path+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isVaultPath(path string) bool {
+	return path == "/vault" || strings.HasPrefix(path, "/vault/")
+}
+
+// setVersionHeaders labels the response with its version and, if that
+// version is being retired, when.
+func setVersionHeaders(w http.ResponseWriter, version string) {
+	w.Header().Set(apiVersionHeader, version)
+	v := apiVersions[version]
+	if !v.Deprecated.IsZero() {
+		w.Header().Set("Deprecation", httpDeprecation(v.Deprecated))
+	}
+	if !v.Sunset.IsZero() {
+		w.Header().Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// httpDeprecation formats a Deprecation header value (RFC 9745).
+func httpDeprecation(t time.Time) string {
+	return "@" + strconv.FormatInt(t.Unix(), 10)
+}
+
+func supportedVersions() []string {
+	return slices.Sorted(maps.Keys(apiVersions))
+}
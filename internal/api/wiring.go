@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// GET /vault/wiring
+// Returns the consumer registry, access lists, templates, aliases,
+// append-only categories, and service token metadata, without field values
+// or token secrets. Session only.
+func (s *Server) handleExportWiring(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	wiring, err := s.vault.ExportWiring()
+	if err != nil {
+		handleVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, wiring)
+}
+
+// POST /vault/wiring?reissue_tokens=true
+// Applies an exported wiring on top of the vault's own. With
+// reissue_tokens, each unexpired token in it is issued anew and returned
+// once. Session only.
+func (s *Server) handleImportWiring(w http.ResponseWriter, r *http.Request) {
+	if !isSessionAuth(r) {
+		sessionRequired(w)
+		return
+	}
+	var wiring vault.Wiring
+	if !decodeJSON(w, r, &wiring) {
+		return
+	}
+	res, err := s.vault.ImportWiring(&wiring, r.URL.Query().Get("reissue_tokens") == "true")
+	switch {
+	case errors.Is(err, vault.ErrLocked):
+		handleVaultError(w, err)
+	case errors.Is(err, vault.ErrWiringVersion):
+		invalidField(w, "version", err.Error())
+	case err != nil:
+		// Entries before the failing one stay applied; say which.
+		writeErrorDetails(w, http.StatusBadRequest, constraintInvalidRequest, err.Error(), errorDetails{"applied": res})
+	default:
+		writeJSON(w, http.StatusOK, res)
+	}
+}
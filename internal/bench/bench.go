@@ -0,0 +1,170 @@
+// Package bench measures the vault's hot paths: unlocking, which is mostly
+// Argon2id, single-field writes and reads, reading the whole vault at
+// several sizes, and service token validation. The same benchmarks run
+// under 'go test -bench' and 'pvault bench', each against a throwaway vault
+// on disk, so KDF tuning and caching changes can be checked with numbers
+// from the machine that matters.
+package bench
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/vault"
+)
+
+// password unlocks every benchmark vault.
+const password = "pvault-bench"
+
+// ContextSizes are the vault sizes, in fields, GetContext is measured at.
+var ContextSizes = []int{100, 1000, 10000}
+
+// Benchmark is one measurement in the suite.
+type Benchmark struct {
+	Name string
+	// Budget is a generous ceiling for one operation, several times what a
+	// laptop takes, so going over it means a regression rather than a slow
+	// machine.
+	Budget time.Duration
+	Run    func(b *testing.B)
+}
+
+// Result is how long a benchmark's operation took.
+type Result struct {
+	Name   string
+	N      int
+	PerOp  time.Duration
+	Budget time.Duration
+}
+
+// OverBudget reports whether the operation took longer than its budget.
+func (r Result) OverBudget() bool {
+	return r.PerOp > r.Budget
+}
+
+// Suite returns the benchmarks, slowest to set up last.
+func Suite() []Benchmark {
+	s := []Benchmark{
+		{Name: "Unlock", Budget: 2 * time.Second, Run: benchUnlock},
+		{Name: "Set", Budget: 50 * time.Millisecond, Run: benchSet},
+		{Name: "Get", Budget: 10 * time.Millisecond, Run: benchGet},
+		{Name: "ValidateServiceToken", Budget: 10 * time.Millisecond, Run: benchValidateServiceToken},
+	}
+	for _, n := range ContextSizes {
+		s = append(s, Benchmark{
+			Name:   fmt.Sprintf("GetContext/%d", n),
+			Budget: 20*time.Millisecond + time.Duration(n)*50*time.Microsecond,
+			Run:    benchGetContext(n),
+		})
+	}
+	return s
+}
+
+// Measure runs bm for about a second, as 'go test -bench' would.
+func (bm Benchmark) Measure() (Result, error) {
+	r := testing.Benchmark(bm.Run)
+	if r.N == 0 {
+		return Result{}, errors.New(bm.Name + ": benchmark failed")
+	}
+	return Result{Name: bm.Name, N: r.N, PerOp: time.Duration(r.NsPerOp()), Budget: bm.Budget}, nil
+}
+
+// newVault creates a vault in a temp directory that is removed when b
+// ends, and returns it with its secret key.
+func newVault(b *testing.B) (*vault.Vault, string) {
+	dir := filepath.Join(b.TempDir(), ".vault")
+	sk, err := vault.Init(dir, password)
+	if err != nil {
+		b.Fatal(err)
+	}
+	v, err := vault.Open(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { v.Close() })
+	return v, sk
+}
+
+// unlocked returns an unlocked vault holding n fields spread over ten
+// categories.
+func unlocked(b *testing.B, n int) *vault.Vault {
+	v, sk := newVault(b)
+	if _, err := v.Unlock(password, sk); err != nil {
+		b.Fatal(err)
+	}
+	if n == 0 {
+		return v
+	}
+	ops := make([]vault.TxOp, n)
+	for i := range ops {
+		ops[i] = vault.TxOp{Op: vault.TxSet, ID: fieldID(i), Value: fmt.Sprintf("value %d", i), Sensitivity: "standard"}
+	}
+	if _, _, err := v.Apply(ops, "", "bench"); err != nil {
+		b.Fatal(err)
+	}
+	return v
+}
+
+func fieldID(i int) string {
+	return fmt.Sprintf("bench%d.field_%d", i%10, i)
+}
+
+func benchUnlock(b *testing.B) {
+	v, sk := newVault(b)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := v.Unlock(password, sk); err != nil {
+			b.Fatal(err)
+		}
+		v.Lock()
+	}
+}
+
+func benchSet(b *testing.B) {
+	v := unlocked(b, 0)
+	b.ResetTimer()
+	for i := range b.N {
+		if err := v.Set(fieldID(i%100), fmt.Sprintf("value %d", i), "standard"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchGet(b *testing.B) {
+	v := unlocked(b, 100)
+	b.ResetTimer()
+	for i := range b.N {
+		if f, err := v.Get(fieldID(i % 100)); err != nil || f == nil {
+			b.Fatalf("get %s: %v", fieldID(i%100), err)
+		}
+	}
+}
+
+func benchValidateServiceToken(b *testing.B) {
+	v := unlocked(b, 0)
+	token, err := v.CreateServiceToken("bench", "*", time.Hour)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for range b.N {
+		if _, ok := v.ValidateServiceToken(token); !ok {
+			b.Fatal("token rejected")
+		}
+	}
+}
+
+func benchGetContext(n int) func(b *testing.B) {
+	return func(b *testing.B) {
+		v := unlocked(b, n)
+		b.ResetTimer()
+		for range b.N {
+			if _, err := v.GetContext(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
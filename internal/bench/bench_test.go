@@ -0,0 +1,19 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+// BenchmarkSuite runs the suite and fails any benchmark over its budget, so
+// 'go test -bench . -benchtime 1x' doubles as a regression gate.
+func BenchmarkSuite(b *testing.B) {
+	for _, bm := range Suite() {
+		b.Run(bm.Name, func(b *testing.B) {
+			bm.Run(b)
+			if perOp := b.Elapsed() / time.Duration(b.N); perOp > bm.Budget {
+				b.Errorf("%s took %v per operation, over its %v budget", bm.Name, perOp, bm.Budget)
+			}
+		})
+	}
+}
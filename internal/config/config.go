@@ -0,0 +1,323 @@
+// Package config reads and writes the CLI and server settings in
+// ~/.pvault/config.toml.
+//
+// A setting is resolved from, in order: its environment variable, a
+// command-line flag where the command has one, the config file, and finally
+// its built-in default. The file holds a small subset of TOML: [section]
+// tables with string, integer, and boolean values.
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the config file's name inside the vault directory.
+const FileName = "config.toml"
+
+// Kind is the type of value a setting holds.
+type Kind int
+
+const (
+	String Kind = iota
+	Port
+	Duration
+	URL
+	List  // comma-separated
+	Count // non-negative integer
+	Bool  // true or false
+)
+
+// Key describes one setting.
+type Key struct {
+	Name    string // section.key, as written in the file
+	Env     string // environment variable that overrides the file
+	Kind    Kind
+	Default string
+	Doc     string
+}
+
+// Keys lists every setting, in the order the file is written.
+var Keys = []Key{
+	{Name: "server.listen", Env: "VAULT_LISTEN", Default: "127.0.0.1", Doc: "Host (or host:port) the server binds to; non-loopback requires TLS"},
+	{Name: "server.port", Env: "VAULT_PORT", Kind: Port, Default: "7200", Doc: "Server port; also the client's default"},
+	{Name: "server.socket", Env: "VAULT_SOCKET", Doc: "Unix socket for workload-bound tokens"},
+	{Name: "server.tls_cert", Env: "VAULT_TLS_CERT", Doc: "TLS certificate file"},
+	{Name: "server.tls_key", Env: "VAULT_TLS_KEY", Doc: "TLS private key file"},
+	{Name: "server.cors_origins", Env: "VAULT_CORS_ORIGINS", Kind: List, Doc: "Browser origins (scheme://host[:port]) allowed to call the API, e.g. a local app or extension; empty disables CORS"},
+	{Name: "server.max_concurrent", Env: "VAULT_MAX_CONCURRENT", Kind: Count, Default: "32", Doc: "Requests the server handles at once; 0 turns the limit off"},
+	{Name: "server.max_queue", Env: "VAULT_MAX_QUEUE", Kind: Count, Default: "64", Doc: "Requests that may wait for one of server.max_concurrent; more are refused with 503"},
+	{Name: "server.request_timeout", Env: "VAULT_REQUEST_TIMEOUT", Kind: Duration, Default: "30s", Doc: "How long a request may take to arrive and be answered; reads that wait for the authorizer get authorize.timeout more"},
+	{Name: "server.password_file", Env: "VAULT_PASSWORD_FILE", Doc: "File with the password for serve --headless"},
+	{Name: "server.secret_key_file", Env: "VAULT_SECRET_KEY_FILE", Doc: "File with the secret key for serve --headless (default: the vault's stored key)"},
+	{Name: "session.autolock", Env: "VAULT_AUTOLOCK", Kind: Duration, Default: "30m", Doc: "Idle time before a session locks"},
+	{Name: "session.confirm", Env: "VAULT_SESSION_CONFIRM", Kind: Bool, Default: "false", Doc: "Have the session agent ask the authorizer each time it hands a CLI command the session token"},
+	{Name: "client.addr", Env: "VAULT_ADDR", Kind: URL, Doc: "Server URL for CLI commands (default http://127.0.0.1:<server.port>)"},
+	{Name: "client.ca_cert", Env: "VAULT_CA_CERT", Doc: "Extra CA certificate to trust for the server"},
+	{Name: "notify.url", Env: "VAULT_NOTIFY_URL", Kind: URL, Doc: "Webhook that receives owner notifications"},
+	{Name: "notify.cmd", Env: "VAULT_NOTIFY_CMD", Doc: "Program that receives owner notifications on stdin"},
+	{Name: "notify.events", Env: "VAULT_NOTIFY_EVENTS", Kind: List, Doc: "Event types to send (default all)"},
+	{Name: "notify.expiry_window", Env: "VAULT_NOTIFY_EXPIRY_WINDOW", Kind: Duration, Default: "2160h", Doc: "How far ahead field_expiring events warn of passport, visa, and other expiry dates (2160h is 90 days)"},
+	{Name: "notify.token_expiry_window", Env: "VAULT_NOTIFY_TOKEN_EXPIRY_WINDOW", Kind: Duration, Default: "720h", Doc: "How far ahead token_expiring events and pvault status warn of service tokens expiring (720h is 30 days)"},
+	{Name: "enrich.url", Env: "VAULT_ENRICH_URL", Kind: URL, Doc: "Address enrichment webhook"},
+	{Name: "enrich.cmd", Env: "VAULT_ENRICH_CMD", Doc: "Address enrichment program"},
+	{Name: "authorize.url", Env: "VAULT_AUTHORIZE_URL", Kind: URL, Doc: "Webhook that approves or denies service-token reads of sensitive fields"},
+	{Name: "authorize.cmd", Env: "VAULT_AUTHORIZE_CMD", Doc: "Program that approves or denies service-token reads of sensitive fields"},
+	{Name: "authorize.min_sensitivity", Env: "VAULT_AUTHORIZE_MIN_SENSITIVITY", Default: "sensitive", Doc: "Least sensitive tier that needs the authorizer's approval"},
+	{Name: "authorize.timeout", Env: "VAULT_AUTHORIZE_TIMEOUT", Kind: Duration, Default: "60s", Doc: "How long a read waits for the authorizer before it is refused"},
+	{Name: "plugins.hooks", Env: "VAULT_PLUGINS", Kind: List, Doc: "Hook plugins (pvault-<name> programs or paths) that validate writes and transform reads"},
+	{Name: "replica.source", Env: "VAULT_REPLICA_SOURCE", Kind: URL, Doc: "Primary vault a serve --replica server pulls from"},
+	{Name: "replica.token_file", Env: "VAULT_REPLICA_TOKEN_FILE", Doc: "File with the primary's service token for serve --replica (or set VAULT_REPLICA_TOKEN)"},
+	{Name: "replica.interval", Env: "VAULT_REPLICA_INTERVAL", Kind: Duration, Default: "1m", Doc: "How often a replica pulls from its primary"},
+	{Name: "audit.retention", Env: "VAULT_AUDIT_RETENTION", Kind: Duration, Doc: "How long audit entries are kept, e.g. 8760h; empty keeps them forever"},
+	{Name: "storage.synchronous", Env: "VAULT_SYNCHRONOUS", Kind: String, Default: "NORMAL", Doc: "How hard SQLite works to get each write onto disk: OFF, NORMAL, FULL, or EXTRA"},
+	{Name: "storage.compact_threshold", Env: "VAULT_COMPACT_THRESHOLD", Kind: Count, Default: "25", Doc: "Percent of the database file that must be free space before serve compacts it; 0 turns it off"},
+	{Name: "tokens.default_ttl", Env: "VAULT_TOKEN_TTL", Kind: Duration, Default: "8760h", Doc: "Lifetime of new service tokens without --ttl"},
+	{Name: "schema.packs", Env: "VAULT_SCHEMA_PACKS", Kind: List, Doc: "Optional schema packs added to the recommended schema (see 'pvault schema packs')"},
+}
+
+// Source says where a resolved value came from.
+type Source string
+
+const (
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+	SourceFile    Source = "file"
+	SourceDefault Source = "default"
+)
+
+// ErrUnknownKey is returned for a name not in Keys.
+var ErrUnknownKey = errors.New("unknown config key")
+
+// Lookup returns the Key named name.
+func Lookup(name string) (Key, bool) {
+	i := slices.IndexFunc(Keys, func(k Key) bool { return k.Name == name })
+	if i < 0 {
+		return Key{}, false
+	}
+	return Keys[i], true
+}
+
+// Validate checks value against the key's kind.
+func (k Key) Validate(value string) error {
+	switch k.Kind {
+	case Port:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%s must be a port number (1-65535)", k.Name)
+		}
+	case Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("%s must be a positive duration such as 30m or 24h", k.Name)
+		}
+	case Count:
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("%s must be a whole number, 0 or more", k.Name)
+		}
+	case Bool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", k.Name)
+		}
+	case URL:
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s must be an http(s) URL", k.Name)
+		}
+	}
+	return nil
+}
+
+// Config is the contents of a config file.
+type Config struct {
+	path   string
+	values map[string]string
+}
+
+// Path returns the config file location for a vault directory.
+func Path(dir string) string {
+	return filepath.Join(dir, FileName)
+}
+
+// Load reads the config file at path. A missing file is an empty config.
+func Load(path string) (*Config, error) {
+	c := &Config{path: path, values: make(map[string]string)}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name, ok := strings.CutSuffix(stripComment(line), "]")
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: malformed table header", path, n)
+			}
+			section = strings.TrimSpace(name[1:])
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, n)
+		}
+		name := strings.TrimSpace(key)
+		if section != "" {
+			name = section + "." + name
+		}
+		k, ok := Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: %w %q", path, n, ErrUnknownKey, name)
+		}
+		value, err := parseValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		if err := k.Validate(value); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		c.values[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseValue decodes a TOML string, integer, or boolean into its text.
+func parseValue(raw string) (string, error) {
+	if strings.HasPrefix(raw, `"`) {
+		// Find the closing quote, skipping escaped ones.
+		end := -1
+		for i := 1; i < len(raw); i++ {
+			if raw[i] == '\\' {
+				i++
+				continue
+			}
+			if raw[i] == '"' {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", errors.New("unexpected text after string")
+		}
+		return strconv.Unquote(raw[:end+1])
+	}
+	raw = stripComment(raw)
+	if raw == "true" || raw == "false" {
+		return raw, nil
+	}
+	if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return raw, nil
+	}
+	return "", fmt.Errorf("unsupported value %q: quote strings", raw)
+}
+
+func stripComment(s string) string {
+	if i := strings.Index(s, "#"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// Get returns the value a setting has in the file, if any.
+func (c *Config) Get(name string) (string, bool) {
+	v, ok := c.values[name]
+	return v, ok
+}
+
+// Set stores a validated value; Save writes it out.
+func (c *Config) Set(name, value string) error {
+	k, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownKey, name)
+	}
+	if err := k.Validate(value); err != nil {
+		return err
+	}
+	c.values[name] = value
+	return nil
+}
+
+// Unset removes a setting from the file, restoring its default.
+func (c *Config) Unset(name string) error {
+	if _, ok := Lookup(name); !ok {
+		return fmt.Errorf("%w %q", ErrUnknownKey, name)
+	}
+	delete(c.values, name)
+	return nil
+}
+
+// Resolve returns a setting's effective value and where it came from:
+// environment, then flag (empty means not given), then file, then default.
+func (c *Config) Resolve(name, flag string) (string, Source) {
+	k, _ := Lookup(name)
+	if k.Env != "" {
+		if v := os.Getenv(k.Env); v != "" {
+			return v, SourceEnv
+		}
+	}
+	if flag != "" {
+		return flag, SourceFlag
+	}
+	if v, ok := c.values[name]; ok {
+		return v, SourceFile
+	}
+	return k.Default, SourceDefault
+}
+
+// Value is Resolve without a flag, for settings no command takes as one.
+func (c *Config) Value(name string) string {
+	v, _ := c.Resolve(name, "")
+	return v
+}
+
+// Save writes the config file (mode 0600), grouping keys by section.
+func (c *Config) Save() error {
+	var b strings.Builder
+	section := ""
+	for _, k := range Keys {
+		v, ok := c.values[k.Name]
+		if !ok {
+			continue
+		}
+		sec, name, _ := strings.Cut(k.Name, ".")
+		if sec != section {
+			if section != "" {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "[%s]\n", sec)
+			section = sec
+		}
+		if k.Kind == Port || k.Kind == Bool {
+			fmt.Fprintf(&b, "%s = %s\n", name, v)
+		} else {
+			fmt.Fprintf(&b, "%s = %s\n", name, strconv.Quote(v))
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, []byte(b.String()), 0600)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	os.WriteFile(path, []byte(`# pvault settings
+[server]
+port = 7300   # not the default
+listen = "127.0.0.1"
+
+[notify]
+url = "https://hooks.example.com/pvault?a=\"b\""
+events = "emergency_requested, emergency_released"
+`), 0600)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"server.port":   "7300",
+		"server.listen": "127.0.0.1",
+		"notify.url":    `https://hooks.example.com/pvault?a="b"`,
+		"notify.events": "emergency_requested, emergency_released",
+	}
+	for name, want := range tests {
+		if got, ok := c.Get(name); !ok || got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := c.Value("server.port"); v != "7200" {
+		t.Fatalf("expected the default port, got %q", v)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":   "[server]\nprot = 7300\n",
+		"bad port":      "[server]\nport = 70000\n",
+		"bad duration":  "[session]\nautolock = \"soon\"\n",
+		"bad url":       "[notify]\nurl = \"ftp://example.com\"\n",
+		"bare string":   "[server]\nlisten = 127.0.0.1\n",
+		"unterminated":  "[server]\nlisten = \"127.0.0.1\n",
+		"no equals":     "[server]\nport\n",
+		"broken header": "[server\nport = 7300\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(t.TempDir(), FileName)
+		os.WriteFile(path, []byte(content), 0600)
+		_, err := Load(path)
+		if err == nil || !strings.Contains(err.Error(), FileName+":") {
+			t.Errorf("%s: expected an error with a line number, got %v", name, err)
+		}
+	}
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	c, _ := Load(filepath.Join(t.TempDir(), FileName))
+	if v, src := c.Resolve("tokens.default_ttl", ""); v != "8760h" || src != SourceDefault {
+		t.Fatalf("expected the default, got %q from %s", v, src)
+	}
+	c.Set("tokens.default_ttl", "720h")
+	if v, src := c.Resolve("tokens.default_ttl", ""); v != "720h" || src != SourceFile {
+		t.Fatalf("expected the file value, got %q from %s", v, src)
+	}
+	if v, src := c.Resolve("tokens.default_ttl", "24h"); v != "24h" || src != SourceFlag {
+		t.Fatalf("expected the flag to beat the file, got %q from %s", v, src)
+	}
+	t.Setenv("VAULT_TOKEN_TTL", "1h")
+	if v, src := c.Resolve("tokens.default_ttl", "24h"); v != "1h" || src != SourceEnv {
+		t.Fatalf("expected the environment to beat the flag, got %q from %s", v, src)
+	}
+}
+
+func TestSetSave_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", FileName)
+	c, _ := Load(path)
+	if err := c.Set("server.port", "abc"); err == nil {
+		t.Fatal("expected an invalid port to be rejected")
+	}
+	if err := c.Set("server.max_concurrent", "-1"); err == nil {
+		t.Fatal("expected a negative count to be rejected")
+	}
+	if err := c.Set("session.confirm", "yes"); err == nil {
+		t.Fatal("expected a non-boolean switch to be rejected")
+	}
+	if err := c.Set("nope.key", "x"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+	c.Set("server.port", "7300")
+	c.Set("notify.cmd", `notify-send "vault event"`)
+	c.Set("session.autolock", "10m")
+	c.Set("session.confirm", "true")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 && os.PathSeparator == '/' {
+		t.Fatalf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"server.port", "notify.cmd", "session.autolock", "session.confirm"} {
+		want, _ := c.Get(name)
+		if got, _ := loaded.Get(name); got != want {
+			t.Errorf("%s: got %q after reload, want %q", name, got, want)
+		}
+	}
+
+	loaded.Unset("server.port")
+	loaded.Save()
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "port") || !strings.Contains(string(data), "[session]") {
+		t.Fatalf("unexpected file after unset:\n%s", data)
+	}
+}
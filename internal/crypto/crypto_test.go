@@ -2,7 +2,12 @@ package crypto
 
 import (
 	"bytes"
+	"io"
 	"testing"
+	"testing/quick"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
 )
 
 func TestDeriveVaultKey_Deterministic(t *testing.T) {
@@ -153,6 +158,93 @@ func TestEncryptDecryptBase64_Roundtrip(t *testing.T) {
 	}
 }
 
+func TestEncryptDecrypt_RoundtripProperty(t *testing.T) {
+	roundtrip := func(key [32]byte, plaintext []byte) bool {
+		encoded, err := EncryptToBase64(key[:], plaintext)
+		if err != nil {
+			return false
+		}
+		decrypted, err := DecryptFromBase64(key[:], encoded)
+		return err == nil && bytes.Equal(plaintext, decrypted)
+	}
+	if err := quick.Check(roundtrip, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	wrongKey := func(key, other [32]byte, plaintext []byte) bool {
+		if key == other {
+			return true
+		}
+		data, err := Encrypt(key[:], plaintext)
+		if err != nil {
+			return false
+		}
+		_, err = Decrypt(other[:], data)
+		return err != nil
+	}
+	if err := quick.Check(wrongKey, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// FuzzDecryptFromBase64 feeds stored values an attacker could have written
+// to the database: decryption must fail cleanly, never panic, and only
+// succeed for something Encrypt made under the same key.
+func FuzzDecryptFromBase64(f *testing.F) {
+	key := make([]byte, 32)
+	copy(key, "test-key-32-bytes-long-padding!!")
+	valid, err := EncryptToBase64(key, []byte("hello, vault"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, seed := range []string{"", "=", "!!!!", "AAAA", "AAAAAAAAAAAAAAAAAA==", valid, valid[:len(valid)-4], valid + "AA"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, encoded string) {
+		plaintext, err := DecryptFromBase64(key, encoded)
+		if err != nil {
+			return
+		}
+		// Anything that opens is authentic, so it must re-seal and open to
+		// the same plaintext.
+		again, err := EncryptToBase64(key, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := DecryptFromBase64(key, again)
+		if err != nil || !bytes.Equal(plaintext, decrypted) {
+			t.Fatalf("round trip of %q failed: %q, %v", plaintext, decrypted, err)
+		}
+	})
+}
+
+// FuzzEncryptTamper checks that Encrypt round-trips any plaintext and that
+// flipping any bit of its output makes Decrypt fail.
+func FuzzEncryptTamper(f *testing.F) {
+	key := make([]byte, 32)
+	copy(key, "test-key-32-bytes-long-padding!!")
+	f.Add([]byte("hello, vault"), uint(0))
+	f.Add([]byte{}, uint(12))
+	f.Add(bytes.Repeat([]byte{0xff}, 100), uint(1000))
+
+	f.Fuzz(func(t *testing.T, plaintext []byte, bit uint) {
+		data, err := Encrypt(key, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := Decrypt(key, data)
+		if err != nil || !bytes.Equal(plaintext, decrypted) {
+			t.Fatalf("round trip of %q failed: %q, %v", plaintext, decrypted, err)
+		}
+		bit %= uint(len(data) * 8)
+		data[bit/8] ^= 1 << (bit % 8)
+		if _, err := Decrypt(key, data); err == nil {
+			t.Fatalf("decrypted after flipping bit %d", bit)
+		}
+	})
+}
+
 func TestDeriveSubkey_DifferentCategories(t *testing.T) {
 	vaultKey := make([]byte, 32)
 	copy(vaultKey, "vault-key-32-bytes-long-padding!")
@@ -188,6 +280,24 @@ func TestDeriveSubkey_Deterministic(t *testing.T) {
 	}
 }
 
+func TestKeyCheckValue(t *testing.T) {
+	vaultKey := make([]byte, 32)
+	salt := []byte("test-salt-16bytes")
+	k1, _ := DeriveSubkey(vaultKey, salt, "identity")
+	k2, _ := DeriveSubkey(vaultKey, salt, "financial")
+
+	kcv := KeyCheckValue(k1)
+	if len(kcv) != 16 {
+		t.Fatalf("expected 8-byte hex KCV, got %q", kcv)
+	}
+	if KeyCheckValue(k1) != kcv {
+		t.Fatal("same key should produce same KCV")
+	}
+	if KeyCheckValue(k2) == kcv {
+		t.Fatal("different keys should produce different KCVs")
+	}
+}
+
 func TestHashSecretKey_Deterministic(t *testing.T) {
 	key := []byte("0123456789abcdef")
 
@@ -201,3 +311,56 @@ func TestHashSecretKey_Deterministic(t *testing.T) {
 		t.Fatalf("expected 32-byte hash, got %d", len(h1))
 	}
 }
+
+func TestSealToRecipients(t *testing.T) {
+	alice, _ := age.GenerateX25519Identity()
+	bob, _ := age.GenerateX25519Identity()
+	outsider, _ := age.GenerateX25519Identity()
+
+	sealed, err := SealToRecipients([]byte("ssn: 123-45-6789"), []string{alice.Recipient().String(), bob.Recipient().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(sealed, []byte("-----BEGIN AGE ENCRYPTED FILE-----")) {
+		t.Fatalf("expected armored output, got %q", sealed[:32])
+	}
+
+	open := func(id age.Identity) ([]byte, error) {
+		r, err := age.Decrypt(armor.NewReader(bytes.NewReader(sealed)), id)
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	}
+	for _, id := range []*age.X25519Identity{alice, bob} {
+		if got, err := open(id); err != nil || string(got) != "ssn: 123-45-6789" {
+			t.Fatalf("expected recipient to decrypt, got %q, %v", got, err)
+		}
+	}
+	if _, err := open(outsider); err == nil {
+		t.Fatal("expected a non-recipient to fail")
+	}
+
+	if _, err := SealToRecipients([]byte("x"), nil); err != ErrNoRecipients {
+		t.Fatalf("expected ErrNoRecipients, got %v", err)
+	}
+	if _, err := SealToRecipients([]byte("x"), []string{"0xDEADBEEF"}); err == nil {
+		t.Fatal("expected an unsupported recipient to be rejected")
+	}
+}
+
+func TestSealWithPassphrase(t *testing.T) {
+	sealed, err := SealWithPassphrase([]byte("a1b2c3"), "482913")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(sealed, []byte("-----BEGIN AGE ENCRYPTED FILE-----")) {
+		t.Fatalf("expected armored output, got %q", sealed[:32])
+	}
+	if got, err := OpenWithPassphrase(sealed, "482913"); err != nil || string(got) != "a1b2c3" {
+		t.Fatalf("expected the passphrase to decrypt, got %q, %v", got, err)
+	}
+	if _, err := OpenWithPassphrase(sealed, "000000"); err != ErrWrongPassphrase {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+}
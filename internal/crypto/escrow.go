@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+)
+
+var ErrNoRecipients = errors.New("at least one recipient is required")
+
+// ParseRecipient parses an age public key ("age1…") or an SSH public key
+// ("ssh-ed25519 …" or "ssh-rsa …").
+func ParseRecipient(s string) (age.Recipient, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "age1"):
+		return age.ParseX25519Recipient(s)
+	case strings.HasPrefix(s, "ssh-"):
+		return agessh.ParseRecipient(s)
+	}
+	return nil, fmt.Errorf("unsupported recipient %q: want an age1… or ssh- public key", s)
+}
+
+// SealToRecipients encrypts plaintext so that any one of the recipients can
+// decrypt it with their private key (e.g. 'age -d -i key.txt'). The result is
+// ASCII-armored age.
+func SealToRecipients(plaintext []byte, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, ErrNoRecipients
+	}
+	parsed := make([]age.Recipient, len(recipients))
+	for i, r := range recipients {
+		var err error
+		if parsed[i], err = ParseRecipient(r); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	a := armor.NewWriter(&buf)
+	w, err := age.Encrypt(a, parsed...)
+	if err != nil {
+		return nil, fmt.Errorf("age encrypt: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := a.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
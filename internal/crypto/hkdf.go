@@ -1,7 +1,9 @@
 package crypto
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 
@@ -18,3 +20,15 @@ func DeriveSubkey(vaultKey, salt []byte, category string) ([]byte, error) {
 	}
 	return subkey, nil
 }
+
+// kcvLen is the number of HMAC bytes kept in a key check value: enough to
+// catch a wrong key, too few to help an attacker.
+const kcvLen = 8
+
+// KeyCheckValue returns a short hex fingerprint of a key, stored so a later
+// decryption failure can be blamed on the key or on the ciphertext.
+func KeyCheckValue(key []byte) string {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte("personal-vault key check"))
+	return hex.EncodeToString(m.Sum(nil)[:kcvLen])
+}
@@ -11,7 +11,7 @@ const (
 	argonTime    = 3
 	argonMemory  = 64 * 1024 // 64 MB
 	argonThreads = 1         // sequential: deterministic performance across machines
-	keyLen       = 32 // 256-bit
+	keyLen       = 32        // 256-bit
 	saltLen      = 32
 	secretKeyLen = 16 // 128-bit
 )
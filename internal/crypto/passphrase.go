@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ErrWrongPassphrase is returned by OpenWithPassphrase for a passphrase that
+// doesn't decrypt the data.
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// SealWithPassphrase encrypts plaintext under a passphrase with age's scrypt
+// recipient, whose work factor makes each guess cost about a second. The
+// result is ASCII-armored age ('age -d' decrypts it).
+func SealWithPassphrase(plaintext []byte, passphrase string) ([]byte, error) {
+	r, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	a := armor.NewWriter(&buf)
+	w, err := age.Encrypt(a, r)
+	if err != nil {
+		return nil, fmt.Errorf("age encrypt: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := a.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// OpenWithPassphrase decrypts data from SealWithPassphrase.
+func OpenWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+	id, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(data)), id)
+	if err != nil {
+		if _, ok := errors.AsType[*age.NoIdentityMatchError](err); ok {
+			return nil, ErrWrongPassphrase
+		}
+		return nil, fmt.Errorf("age decrypt: %w", err)
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,428 @@
+package i18n
+
+var de = map[string]string{
+	"error": "Fehler",
+
+	"prompt.password":         "Profilpasswort: ",
+	"prompt.confirm_password": "Passwort bestätigen: ",
+	"prompt.confirm":          "Bestätigen: ",
+	"prompt.elevate":          "Dafür ist erneut Ihr Passwort nötig.",
+	"password.too_short":      "das Passwort muss mindestens 8 Zeichen lang sein",
+	"password.mismatch":       "die Passwörter stimmen nicht überein",
+	"prompt.pin":              "PIN des geheimen Schlüssels: ",
+	"prompt.new_pin":          "Wähle eine PIN für den geheimen Schlüssel: ",
+	"prompt.confirm_pin":      "PIN bestätigen: ",
+	"prompt.secret_key":       "Geheimer Schlüssel: ",
+	"pin.too_short":           "die PIN muss mindestens %d Zeichen lang sein",
+	"pin.mismatch":            "die PINs stimmen nicht überein",
+
+	"init.done":             "Tresor erfolgreich initialisiert.",
+	"init.secret_key":       "Dein geheimer Schlüssel (an einem sicheren Ort aufbewahren):",
+	"init.secret_saved":     "Geheimer Schlüssel außerdem gespeichert unter: %s",
+	"init.next":             "Weiter: Führe 'pvault unlock' aus, um deinen Tresor zu verwenden.",
+	"init.secret_keychain":  "Geheimer Schlüssel auch im Schlüsselbund des Systems gespeichert.",
+	"init.secret_pin":       "Geheimer Schlüssel auch in %s gespeichert, verschlüsselt mit deiner PIN.",
+	"init.secret_keyfile":   "Geheimer Schlüssel auch in %s gespeichert. Zum Entsperren muss dieses Gerät eingehängt sein; bewahre es getrennt von diesem Computer auf.",
+	"init.secret_manual":    "Der geheime Schlüssel wird nirgends gespeichert; du gibst ihn bei jedem Entsperren ein.",
+	"init.secret_plaintext": "Dort ist er unverschlüsselt. Zum Schutz führe 'pvault migrate-secret-key keychain' aus (oder pin, oder manual).",
+
+	"secret_key.file":        "unverschlüsselte Datei",
+	"secret_key.keychain":    "Schlüsselbund des Systems",
+	"secret_key.pin":         "PIN-geschützte Datei",
+	"secret_key.keyfile":     "Schlüsseldatei auf einem Wechseldatenträger",
+	"keyfile.need_path":      "--secret-key keyfile braucht --keyfile <Pfad>",
+	"keyfile.no_device":      "%s existiert nicht; ist das Gerät eingehängt?",
+	"secret_key.manual":      "beim Entsperren eingegeben (nicht gespeichert)",
+	"migrate.same":           "Der geheime Schlüssel ist bereits so gespeichert: %s",
+	"migrate.confirm_manual": "Der geheime Schlüssel wird nicht mehr gespeichert. Gib ihn ein, um zu bestätigen, dass du eine Kopie hast.",
+	"migrate.mismatch":       "das ist nicht der geheime Schlüssel; nichts wurde geändert",
+	"migrate.done":           "Geheimer Schlüssel jetzt gespeichert als: %s",
+
+	"onboard.title":      "Tresor anlegen",
+	"onboard.basics":     "Ein paar Grunddaten (Enter zum Überspringen):",
+	"onboard.done_saved": "Fertig — %d Feld(er) gespeichert. Dein Tresor ist bereit.",
+	"onboard.done":       "Fertig — dein Tresor ist bereit.",
+	"onboard.next":       "Führe 'pvault status' aus, um zu sehen, was gespeichert ist.",
+
+	"onboard.field.identity.full_name":     "Vollständiger Name",
+	"onboard.field.identity.email":         "E-Mail",
+	"onboard.field.addresses.home_street":  "Straße und Hausnummer",
+	"onboard.field.addresses.home_city":    "Stadt",
+	"onboard.field.addresses.home_state":   "Bundesland",
+	"onboard.field.addresses.home_zip":     "Postleitzahl",
+	"onboard.field.addresses.home_country": "Land",
+	"onboard.field.preferences.timezone":   "Zeitzone",
+
+	"unlock.already":    "Der Tresor ist bereits entsperrt (Server läuft).",
+	"unlock.unverified": "Tresor-Server gestartet (Status konnte nicht geprüft werden).",
+	"unlock.done":       "Tresor entsperrt. Server läuft auf %s",
+
+	"sessions.row":        "entsperrt %s, sperrt bei Inaktivität in %s",
+	"sessions.row_pinned": "entsperrt %s, sperrt nicht bei Inaktivität",
+	"sessions.revoked":    "Sitzung %s widerrufen",
+	"config.saved":        "%s in %s gespeichert",
+	"config.unknown":      "unbekannter Konfigurationsschlüssel %q; siehe 'pvault config list'",
+	"config.env_override": "Hinweis: %s ist in der Umgebung gesetzt und hat Vorrang vor der Datei",
+
+	"lock.stopped": "Tresor gesperrt (Server gestoppt).",
+	"lock.done":    "Tresor gesperrt.",
+
+	"relock.done":   "Tresor gesperrt; der Server läuft weiter. 'pvault unlock' entsperrt ihn wieder.",
+	"relock.prompt": "Der Tresor ist gesperrt. Geben Sie das Passwort ein, um ihn wieder zu entsperren.",
+	"relock.hint":   "Der Tresor wurde gesperrt oder diese Sitzung ist beendet. Führen Sie 'pvault unlock' aus oder geben Sie --prompt-on-locked an, um nach dem Passwort gefragt zu werden.",
+
+	"status.not_running":     "Der Tresor ist gesperrt (Server läuft nicht).",
+	"status.not_initialized": "Der Tresor ist nicht initialisiert. Führe zuerst 'pvault init' aus.",
+	"status.locked":          "Status:  gesperrt",
+	"status.unlocked":        "Status:  entsperrt",
+	"status.fields":          "Felder:  %d",
+	"status.categories":      "Kategorien:",
+	"status.category_fields": "%d Felder",
+	"status.secret_key":      "Schlüssel: %s",
+
+	"field.set":        "%s gespeichert",
+	"field.deleted":    "%s gelöscht",
+	"list.empty":       "Keine Felder gefunden.",
+	"field.normalized": "Gespeichert als %s (Original im Verlauf aufbewahrt)",
+	"history.empty":    "Kein Verlauf für dieses Feld.",
+
+	"verify.ok":            "%d Feld(er) in %d Kategorie(n) geprüft; keine Probleme gefunden.",
+	"verify.failed":        "Probleme gefunden.",
+	"verify.salt_mismatch": "Salt stimmt nicht überein: Die Metadaten des Tresors haben sich seit dem Entsperren geändert. Führe 'pvault lock' und 'pvault unlock' aus.",
+	"verify.key_mismatch":  "%s: Schlüsselprüfung fehlgeschlagen — diese Kategorie wurde mit einem anderen Schlüssel geschrieben oder ihre Metadaten wurden verändert",
+	"verify.key_unknown":   "%s: kein Wert lässt sich entschlüsseln, und es gibt keinen Prüfwert, der den Grund zeigt",
+	"verify.key_added":     "%s: Schlüsselprüfwert gespeichert",
+	"verify.corrupted":     "%s: Wert ist beschädigt; neu setzen oder aus einer Sicherung wiederherstellen",
+
+	"alias.set":           "Alias %s → %s",
+	"alias.deleted":       "Alias %s entfernt",
+	"alias.empty":         "Keine Aliase.",
+	"canary.created":      "Köder %s = %s",
+	"canary.revoke":       "Jedes Service-Token, das ihn liest, wird widerrufen.",
+	"canary.hint":         "Lesezugriffe durch Service-Tokens werden als 'canary' protokolliert und an notify.url oder notify.cmd gesendet.",
+	"canary.deleted":      "Köder %s entfernt",
+	"canary.empty":        "Keine Köder.",
+	"canary.alert_only":   "Alarm",
+	"canary.alert_revoke": "Alarm + Token widerrufen",
+
+	"acl.allow":      "%s für %s erlaubt (nur erlaubte Konsumenten erreichen das Feld jetzt)",
+	"acl.deny":       "%s für %s verweigert",
+	"acl.removed":    "%s aus den Zugriffslisten von %s entfernt",
+	"acl.empty":      "Keine Feld-Zugriffslisten.",
+	"acl.allow_list": "erlaubt: %s",
+	"acl.deny_list":  "verweigert: %s",
+
+	"consumer.trust":   "%s ist jetzt %s (erreicht Felder bis %s)",
+	"consumer.reset":   "%s ist wieder vertrauenswürdig",
+	"consumer.empty":   "Keine Konsumenten.",
+	"consumer.max":     "bis %s",
+	"consumer.default": "(Standard)",
+
+	"offline.usage":          "--offline funktioniert mit get, set und list",
+	"offline.no_vault":       "kein Tresor in %s — zuerst 'pvault init' ausführen",
+	"offline.server_running": "der Tresor-Server auf %s läuft und würde einen Offline-Schreibvorgang nicht sehen; --offline weglassen oder zuerst 'pvault lock' ausführen",
+
+	"template.set":     "Kontextabrufe von %s folgen jetzt seiner Vorlage",
+	"template.deleted": "%s erhält wieder alles, was seine Bereiche abdecken",
+	"template.empty":   "Keine Kontextvorlagen.",
+	"template.mask":    "%s maskiert: %s",
+
+	"pin.pinned":   "%s angeheftet",
+	"pin.unpinned": "%s nicht mehr angeheftet",
+	"pin.empty":    "Keine angehefteten Felder.",
+
+	"get.no_match":  "kein Feld passt zu %q",
+	"get.resolved":  "→ %s",
+	"get.choose":    "%q passt zu mehreren Feldern:",
+	"get.choice":    "Welches? [1-%d]",
+	"get.ambiguous": "%q passt zu mehreren Feldern: %s",
+	"get.no_choice": "kein Feld gewählt",
+
+	"add.unknown":  "unbekannte Kategorie %q (zur Auswahl: %s)",
+	"add.complete": "Alle empfohlenen Felder von %s sind bereits gespeichert.",
+	"add.intro":    "%d Feld(er) auszufüllen, %d bereits gespeichert. Mit Enter überspringen.",
+	"add.failed":   "%s konnte nicht gespeichert werden: %v",
+	"add.done":     "Fertig — %d Feld(er) gespeichert.",
+
+	"onboard.unknown_profile": "unbekanntes Profil %q (zur Auswahl: %s)",
+	"onboard.no_password":     "--from-file braucht das Passwort in der Datei oder in VAULT_PASSWORD",
+	"onboard.bad_file":        "%s lesen: %v",
+
+	"import.csv_summary":   "%d neu, %d geändert, %d unverändert",
+	"import.csv_prompt":    "Diese Änderungen übernehmen? [j/N]",
+	"import.csv_cancelled": "Nichts geschrieben.",
+
+	"note.set":     "Notiz zu %s gespeichert",
+	"note.cleared": "Notiz zu %s gelöscht",
+	"note.none":    "%s hat keine Notiz.",
+
+	"type.set":     "Werttyp von %s auf %s gesetzt",
+	"type.cleared": "%s ist wieder einfacher Text",
+	"get.masked":   "(maskiert: kritische Werte und PEM-Blöcke werden verborgen; mit --reveal anzeigen)",
+
+	"entry.added":   "Eintrag zu %s hinzugefügt",
+	"entry.removed": "%s aus %s entfernt",
+
+	"link.linked":   "%s mit %s verknüpft",
+	"link.unlinked": "Verknüpfung von %s mit %s entfernt",
+	"link.empty":    "Keine Verknüpfungen.",
+
+	"schema.packs_title":   "Schema-Pakete (pvault schema enable <paket> fügt eines zum empfohlenen Schema hinzu):",
+	"schema.pack_enabled":  "aktiviert",
+	"schema.pack_unknown":  "unbekanntes Schema-Paket %q (verfügbar: %s)",
+	"schema.pack_restart":  "Starten Sie einen laufenden Server neu (pvault lock, dann pvault unlock), um die Änderung zu übernehmen.",
+	"schema.packs_invalid": "Warnung: schema.packs: %v; keine Schema-Pakete aktiviert",
+	"schema.pack.vehicles": "Fahrzeuge und Versicherungspolicen",
+	"schema.pack.travel":   "Reisepass, Visum und Trusted-Traveler-Programme, mit Ablaufwarnungen",
+	"schema.pack.medical":  "Medizinische Angaben, für die Service-Tokens einen Zweck nennen müssen",
+
+	"expiring.none":    "In den nächsten %d Tagen läuft nichts ab.",
+	"expiring.in":      "in %d Tagen",
+	"expiring.today":   "heute",
+	"expiring.expired": "seit %d Tagen abgelaufen",
+
+	"redact.summary": "%d Wert(e) aus %d Feld(ern) geschwärzt.",
+	"redact.none":    "Nichts zu schwärzen.",
+
+	"appendonly.set":     "Bestehende %s-Felder ändern sich nur noch mit einer Sitzung; Tokens dürfen weiter neue anlegen.",
+	"appendonly.removed": "%s ist wieder beschreibbar.",
+	"appendonly.empty":   "Keine Kategorien, die nur Ergänzungen erlauben.",
+
+	"demo.done": "Demo-Tresor mit %d erfundenen Feldern (Seed %d) in %s angelegt",
+	"demo.next": "Ausprobieren: VAULT_DIR=%s VAULT_PORT=%s pvault unlock (Passwort: %s)",
+
+	"bench.start":      "Messung auf %s/%s mit %d CPUs, jeweils an einem Wegwerf-Tresor (unter einer Minute)",
+	"bench.row":        "%s %-22s %12s pro Vorgang (Budget %s)",
+	"bench.none":       "Keine Messung passt zu %q",
+	"bench.summary":    "%d Messung(en) über dem Budget.",
+	"bench.summary_ok": "Alle Messungen im Budget.",
+
+	"compact.done":    "Tresor-Datenbank verdichtet: %s → %s (%s freigegeben).",
+	"compact.nothing": "Nichts zu verdichten: Eine verschlüsselte Datenbank wird bei jedem Schreiben vollständig neu geschrieben.",
+
+	"config.export_settings_only": "Der Server läuft nicht, daher wurden nur Einstellungen exportiert; starten Sie ihn und entsperren Sie den Tresor, um Verbraucher, Zugriffslisten, Vorlagen und Tokens einzuschließen",
+	"config.exported":             "%d Einstellungen, %d Verbraucher und %d Tokens nach %s exportiert",
+	"config.import_needs_server":  "Die Datei enthält Verbraucher, Zugriffslisten und Tokens des Tresors; starten Sie zuerst den Server und entsperren Sie den Tresor",
+	"config.imported_settings":    "%d Einstellungen nach %s importiert",
+	"config.imported_wiring":      "Importiert: %d Verbraucher, %d Zugriffslisten, %d Vorlagen, %d Aliasse und %d Nur-Anhängen-Kategorien",
+	"config.reissued":             "Neues Token für %s, ersetzt %s...: %s",
+	"config.tokens_skipped":       "%d Tokens nicht neu ausgestellt: abgelaufen oder --reissue-tokens nicht angegeben",
+
+	"approve.ready":        "Freigabeanfragen erscheinen hier für Lesezugriffe ab %s und für den ersten Lesezugriff jedes Verbrauchers.",
+	"approve.new_consumer": "%s hat noch nie aus diesem Tresor gelesen.",
+	"approve.request":      "%s (%s) fragt nach %s, bis Stufe %s",
+	"approve.purpose":      "Zweck: %s",
+	"approve.more":         "und %d weitere",
+	"approve.prompt":       "Erlauben? [j/N]",
+	"approve.allowed":      "Erlaubt.",
+	"approve.denied":       "Abgelehnt.",
+	"approve.timed_out":    "Keine Antwort in der Zeit; der Lesezugriff wurde abgelehnt.",
+
+	"set.prompt":       "Wert für %s (verdeckt): ",
+	"set.prompt_again": "Noch einmal: ",
+	"set.mismatch":     "Die beiden Eingaben unterscheiden sich; nichts wurde gespeichert.",
+	"set.empty":        "Kein Wert eingegeben; nichts wurde gespeichert.",
+	"set.history_hint": "Tipp: 'pvault set %s --prompt' hält den Wert aus dem Shell-Verlauf heraus.",
+	"set.normalized":   "Normalisiert gespeichert (Original im Verlauf aufbewahrt)",
+
+	"status.token_expiring":      "Warnung: Das Service-Token für %s (%s) läuft in %d Tagen ab",
+	"status.token_expiring_soon": "Warnung: Das Service-Token für %s (%s) läuft innerhalb eines Tages ab",
+
+	"enrich.title":  "Der Adressdienst schlägt vor:",
+	"enrich.change": "%s: %s → %s. Übernehmen? [j/N]",
+	"enrich.add":    "%s: %s. Übernehmen? [j/N]",
+	"enrich.failed": "Die Adresse konnte nicht geprüft werden (HTTP %d).",
+
+	"import.summary":     "%d neu, %d unverändert, %d Konflikt(e)",
+	"import.conflict":    "%s: Tresor v%d (%s) gegen Datei v%d (%s)",
+	"import.vault_value": "Tresor: %s",
+	"import.file_value":  "Datei:  %s",
+	"import.prompt":      "Wert aus der Datei übernehmen? [j/N]",
+	"import.keep":        "→ Wert im Tresor bleibt",
+	"import.take":        "→ Wert aus der Datei wird importiert",
+	"import.dry_run":     "Probelauf: nichts geschrieben.",
+	"import.nothing":     "Nichts zu importieren.",
+	"import.done":        "%d Feld(er) importiert.",
+
+	"import.deletions":     "%d Feld(er) in der Datei gelöscht",
+	"import.deletion":      "%s: in der Datei gelöscht (%s) gegen Tresor v%d (%s)",
+	"import.delete_prompt": "Auch im Tresor löschen? [j/N]",
+	"import.delete":        "→ wird im Tresor gelöscht",
+	"import.deleted":       "%d Feld(er) gelöscht.",
+
+	"merge.same":      "Das ist dieser Tresor; gib den Pfad zur anderen Kopie an.",
+	"merge.conflict":  "%s: dieser Tresor v%d (%s) gegen andere Kopie v%d (%s)",
+	"merge.deletions": "%d Feld(er) in der anderen Kopie gelöscht",
+	"merge.deletion":  "%s: in der anderen Kopie gelöscht (%s) gegen diesen Tresor v%d (%s)",
+	"merge.take":      "→ Wert aus der anderen Kopie wird übernommen",
+	"merge.nothing":   "Bereits synchron.",
+	"merge.done":      "%d Änderung(en) zusammengeführt, Transaktion %s.",
+
+	"status.replica":       "Replikat: von %s, synchronisiert %s",
+	"status.replica_never": "Replikat: von %s, noch nicht synchronisiert",
+	"status.replica_error": "  letzter Abruf fehlgeschlagen: %s",
+
+	"escrow.none":       "Keine Felder zu hinterlegen: Der Vault hat keine kritischen Felder (oder keins passt zu --fields).",
+	"escrow.done":       "%d Feld(er) für %d Empfänger hinterlegt. Nur deren private Schlüssel können es öffnen.",
+	"bootstrap.written": "%s geschrieben (Modus 0600). Die Datei enthält Geheimnisse: halte sie aus der Versionsverwaltung heraus.",
+	"k8s.add":           "+ %s/%s %s  ← %s",
+	"k8s.drift":         "~ %s/%s %s  ← %s (abgewichen: unterscheidet sich vom Tresor)",
+	"k8s.same":          "= %s/%s %s  (synchron)",
+	"k8s.dry_run":       "Probelauf: Es wurden keine Secrets geändert.",
+	"k8s.done":          "%d Secret(s) erstellt, %d aktualisiert.",
+	"k8s.not_local":     "Kontext %q (%s) ist kein lokaler Cluster. pvault k8s schreibt nur in kind, minikube, k3d, Docker Desktop und Cluster auf localhost; wähle einen mit --context.",
+
+	"kms.enrolled":      "Tresorschlüssel mit %s-Schlüssel %s umhüllt.",
+	"kms.headless":      "'pvault serve --headless' entsperrt jetzt über das KMS, wenn kein Passwort angegeben ist.",
+	"kms.unwrap_failed": "Das KMS hat den Schlüssel verschlüsselt, kann ihn aber nicht wieder entschlüsseln (%v); der Server könnte nicht entsperren. Prüfe die Entschlüsselungsberechtigung des Schlüssels.",
+	"kms.none":          "Kein KMS eingerichtet. Richte eines mit 'pvault kms enroll' ein.",
+	"kms.status":        "Tresorschlüssel mit %s-Schlüssel %s umhüllt (seit %s)",
+	"kms.removed":       "KMS-Einrichtung entfernt. Der KMS-Schlüssel selbst bleibt unverändert.",
+
+	"plugins.commands":    "Plugin-Befehle:",
+	"plugins.no_commands": "Keine Plugin-Befehle (pvault-<name>-Programme im PATH).",
+	"plugins.hooks":       "Hook-Plugins (plugins.hooks):",
+	"plugins.no_hooks":    "Keine Hook-Plugins konfiguriert (plugins.hooks).",
+	"plugins.broken":      "funktioniert nicht: %v",
+
+	"emergency.setup":     "%d Feld(er) für %s versiegelt, freigegeben nach %s, sofern du die Anfrage nicht ablehnst. Zugangscode:",
+	"emergency.code_hint": "Gib diesen Code dem Kontakt vertraulich weiter. Er wird nicht erneut angezeigt.",
+	"emergency.empty":     "Keine Notfallkontakte.",
+	"emergency.pending":   "angefragt; Freigabe am %s, sofern nicht abgelehnt",
+	"emergency.denied":    "Notfallzugriff von %s abgelehnt",
+	"emergency.removed":   "Notfallkontakt %s entfernt",
+	"emergency.requested": "Anfrage gesendet. Der Eigentümer kann sie bis %s ablehnen; danach 'pvault emergency release' ausführen.",
+	"emergency.released":  "Paket freigegeben. Mit deinem privaten Schlüssel entschlüsseln, z. B. 'age -d -i key.txt'.",
+
+	"doctor.private":            "%s ist privat",
+	"doctor.mode":               "%s ist für andere Benutzer lesbar (Modus %04o)",
+	"doctor.no_dir":             "Kein Tresor unter %s",
+	"doctor.secret_plaintext":   "Geheimer Schlüssel liegt unverschlüsselt unter %s",
+	"doctor.secret_stored":      "Speicherort des geheimen Schlüssels: %s",
+	"doctor.secret_unreadable":  "Geheimer Schlüssel nicht lesbar (%s): %v",
+	"doctor.no_db":              "Keine Datenbank in %s",
+	"doctor.db_ok":              "Integritätsprüfung der Datenbank bestanden",
+	"doctor.db_corrupt":         "Integritätsprüfung der Datenbank fehlgeschlagen: %s",
+	"doctor.db_unreadable":      "Datenbank kann nicht gelesen werden: %v",
+	"doctor.enc_ok":             "Verschlüsselte Datenbankdatei ist lesbar (Inhalt mit 'pvault verify' prüfen)",
+	"doctor.server_ok":          "Tresor-Server läuft auf %s",
+	"doctor.server_down":        "Kein Server auf %s; 'pvault unlock' startet einen",
+	"doctor.port_conflict":      "Ein anderes Programm lauscht auf %s",
+	"doctor.stale_pid":          "PID-Datei nennt Prozess %d, der nicht läuft",
+	"doctor.pid_not_serving":    "Prozess %d aus der PID-Datei läuft, bedient aber den Tresor nicht",
+	"doctor.stale_session":      "Das Token des Sitzungsagenten ist veraltet",
+	"doctor.no_session":         "Kein Sitzungsagent; Token-Prüfungen übersprungen",
+	"doctor.session_file":       "Eine alte Sitzungsdatei enthält ein Sitzungstoken im Klartext",
+	"doctor.locked":             "Tresor ist gesperrt; Token-Prüfungen übersprungen",
+	"doctor.clock_skew":         "Die Server-Uhr weicht um %s von diesem Rechner ab",
+	"doctor.token_future":       "Dienst-Token für %s wurde in der Zukunft erstellt (%s)",
+	"doctor.token_expired":      "Dienst-Token für %s wird nach Ablauf noch akzeptiert (%s)",
+	"doctor.token_expiring":     "Dienst-Token für %s läuft ab am %s",
+	"doctor.clock_ok":           "Uhr und Token-Zeiten sind stimmig",
+	"doctor.summary":            "%d Problem(e) gefunden.",
+	"doctor.summary_ok":         "Keine Probleme gefunden.",
+	"doctor.fix.init":           "Führe 'pvault init' aus oder setze VAULT_DIR auf deinen Tresor",
+	"doctor.fix.chmod":          "Ausführen: chmod %s %s",
+	"doctor.fix.migrate_secret": "Führe 'pvault migrate-secret-key keychain' aus (oder pin, oder manual)",
+	"doctor.fix.secret_lost":    "Entferne %s und gib den geheimen Schlüssel aus deiner Sicherung beim Entsperren ein; 'pvault migrate-secret-key' kann ihn wieder speichern",
+	"doctor.keyfile_missing":    "Schlüsseldatei %s ist nicht verfügbar",
+	"doctor.fix.keyfile_mount":  "Hänge das Gerät, auf dem sie liegt, vor dem Entsperren ein",
+	"doctor.fix.restore":        "Stoppe den Server und stelle die Datenbank aus einer Sicherung wieder her",
+	"doctor.fix.port":           "Beende dieses Programm oder verlege den Tresor mit 'pvault config set server.port <port>'",
+	"doctor.fix.rm":             "Ausführen: rm %s",
+	"doctor.fix.lock":           "Führe 'pvault lock' und dann 'pvault unlock' aus",
+	"doctor.fix.unlock":         "Führe 'pvault unlock' aus",
+	"doctor.fix.clock":          "Synchronisiere die Systemuhr (NTP aktivieren), widerrufe dann die betroffenen Dienst-Tokens und erstelle sie neu",
+	"doctor.fix.token":          "Erstelle einen Ersatz mit 'pvault create-service-token'",
+
+	"service.installed":     "Dienst pvault für %s installiert. Starte ihn mit 'pvault service start' und führe dann 'pvault unlock' aus.",
+	"service.removed":       "Dienst pvault entfernt.",
+	"service.started":       "Dienst gestartet (gesperrt). Führe 'pvault unlock' aus, um ihn zu entsperren.",
+	"service.stopped":       "Dienst gestoppt.",
+	"service.status":        "Dienststatus: %s.",
+	"service.not_installed": "Der Dienst pvault ist nicht installiert. Führe 'pvault service install' als Administrator aus.",
+	"service.unsupported":   "pvault service gibt es nur unter Windows; mit 'pvault unlock' läuft der Server im Hintergrund.",
+
+	"schema.title":        "Empfohlenes Tresor-Schema",
+	"schema.user_defined": "(benutzerdefinierte Felder)",
+
+	"schema.identity":               "Persönliche Identitätsdaten",
+	"schema.identity.first_name":    "Vorname",
+	"schema.identity.last_name":     "Nachname",
+	"schema.identity.full_name":     "Vollständiger Anzeigename",
+	"schema.identity.email":         "Primäre E-Mail-Adresse",
+	"schema.identity.phone":         "Telefonnummer",
+	"schema.identity.date_of_birth": "Geburtsdatum",
+
+	"schema.addresses":              "Postanschriften",
+	"schema.addresses.home_street":  "Straße und Hausnummer (privat)",
+	"schema.addresses.home_city":    "Wohnort",
+	"schema.addresses.home_state":   "Bundesland oder Provinz (privat)",
+	"schema.addresses.home_zip":     "Postleitzahl (privat)",
+	"schema.addresses.home_country": "Ländercode des Wohnsitzes (z. B. DE)",
+
+	"schema.financial":               "Finanz- und Steuerdaten",
+	"schema.financial.filing_status": "Steuerlicher Veranlagungsstatus",
+	"schema.financial.ssn":           "US-Sozialversicherungsnummer (SSN)",
+
+	"schema.payment":                 "Zahlungskartendaten",
+	"schema.payment.card_number":     "Kartennummer",
+	"schema.payment.card_expiry":     "Ablaufdatum der Karte",
+	"schema.payment.cardholder_name": "Name des Karteninhabers",
+	"schema.payment.card_brand":      "Kartenmarke (z. B. Visa, Mastercard)",
+
+	"schema.preferences":          "Benutzereinstellungen",
+	"schema.preferences.timezone": "Bevorzugte Zeitzone (z. B. Europe/Berlin)",
+	"schema.preferences.language": "Bevorzugte Sprache (z. B. de)",
+
+	"schema.employment":          "Angaben zur Beschäftigung",
+	"schema.employment.employer": "Name des aktuellen Arbeitgebers",
+	"schema.employment.title":    "Berufsbezeichnung",
+
+	"schema.contacts":                        "Nahestehende Personen, je eine Gruppe von Feldern (z. B. contacts.child_1.full_name)",
+	"schema.contacts.spouse.full_name":       "Vollständiger Name des Ehepartners oder Partners",
+	"schema.contacts.spouse.phone":           "Telefonnummer des Ehepartners oder Partners",
+	"schema.contacts.spouse.email":           "E-Mail-Adresse des Ehepartners oder Partners",
+	"schema.contacts.spouse.date_of_birth":   "Geburtsdatum des Ehepartners oder Partners",
+	"schema.contacts.emergency.full_name":    "Vollständiger Name des Notfallkontakts",
+	"schema.contacts.emergency.phone":        "Telefonnummer des Notfallkontakts",
+	"schema.contacts.emergency.relationship": "Beziehung des Notfallkontakts zu Ihnen",
+
+	"schema.vehicles":                     "Fahrzeugdaten und Zulassung",
+	"schema.vehicles.make":                "Fahrzeughersteller",
+	"schema.vehicles.model":               "Fahrzeugmodell",
+	"schema.vehicles.year":                "Modelljahr",
+	"schema.vehicles.vin":                 "Fahrzeug-Identifizierungsnummer (FIN/VIN)",
+	"schema.vehicles.plate":               "Kennzeichen",
+	"schema.vehicles.registration_expiry": "Ablaufdatum der Zulassung",
+
+	"schema.insurance":                      "Versicherer und Policennummern",
+	"schema.insurance.auto_provider":        "Kfz-Versicherer",
+	"schema.insurance.auto_policy_number":   "Kfz-Versicherungsnummer",
+	"schema.insurance.health_provider":      "Krankenversicherer",
+	"schema.insurance.health_policy_number": "Kranken-Versicherungs- oder Gruppennummer",
+	"schema.insurance.health_member_id":     "Mitgliedsnummer der Krankenversicherung",
+	"schema.insurance.home_provider":        "Hausrat- oder Gebäudeversicherer",
+	"schema.insurance.home_policy_number":   "Hausrat- oder Gebäudeversicherungsnummer",
+
+	"schema.travel":                         "Reisedokumente",
+	"schema.travel.passport_number":         "Reisepassnummer",
+	"schema.travel.passport_country":        "Ländercode des ausstellenden Staates (z. B. US)",
+	"schema.travel.passport_expiry":         "Ablaufdatum des Reisepasses (JJJJ-MM-TT)",
+	"schema.travel.visa_country":            "Land, für das ein Visum gilt",
+	"schema.travel.visa_number":             "Visumnummer",
+	"schema.travel.visa_expiry":             "Ablaufdatum des Visums (JJJJ-MM-TT)",
+	"schema.travel.known_traveler_number":   "Known-Traveler-Nummer (Global Entry, TSA PreCheck, NEXUS)",
+	"schema.travel.trusted_traveler_expiry": "Ablaufdatum der Trusted-Traveler-Mitgliedschaft (JJJJ-MM-TT)",
+	"schema.travel.redress_number":          "DHS-Redress-Nummer",
+
+	"schema.medical":   "Medizinische Informationen",
+	"schema.documents": "Dokumentverweise (benutzerdefinierte Felder)",
+
+	"schema.medical.blood_type":          "Blutgruppe (z. B. 0+)",
+	"schema.medical.allergies":           "Allergien, auch gegen Medikamente",
+	"schema.medical.medications":         "Aktuelle Medikamente und Dosierungen",
+	"schema.medical.physician_name":      "Name des Hausarztes",
+	"schema.medical.physician_phone":     "Telefonnummer des Hausarztes",
+	"schema.medical.insurance_member_id": "Mitgliedsnummer der Krankenversicherung",
+}
@@ -0,0 +1,350 @@
+package i18n
+
+// en is the reference catalog. Schema descriptions are not repeated here;
+// the English text lives in the schema itself.
+var en = map[string]string{
+	"error": "Error",
+
+	"prompt.password":         "Profile password: ",
+	"prompt.confirm_password": "Confirm password: ",
+	"prompt.confirm":          "Confirm: ",
+	"prompt.elevate":          "This needs your password again.",
+	"password.too_short":      "password must be at least 8 characters",
+	"password.mismatch":       "passwords do not match",
+	"prompt.pin":              "Secret key PIN: ",
+	"prompt.new_pin":          "Choose a PIN for the secret key: ",
+	"prompt.confirm_pin":      "Confirm PIN: ",
+	"prompt.secret_key":       "Secret key: ",
+	"pin.too_short":           "PIN must be at least %d characters",
+	"pin.mismatch":            "PINs do not match",
+
+	"init.done":             "Vault initialized successfully.",
+	"init.secret_key":       "Your secret key (save this somewhere safe):",
+	"init.secret_saved":     "Secret key also saved to: %s",
+	"init.next":             "Next: run 'pvault unlock' to start using your vault.",
+	"init.secret_keychain":  "Secret key also saved in the OS keychain.",
+	"init.secret_pin":       "Secret key also saved to %s, encrypted with your PIN.",
+	"init.secret_keyfile":   "Secret key also saved to %s. Unlocking needs that device mounted; keep it apart from this computer.",
+	"init.secret_manual":    "The secret key is not stored anywhere; you will enter it at every unlock.",
+	"init.secret_plaintext": "It is not encrypted there. To protect it, run 'pvault migrate-secret-key keychain' (or pin, or manual).",
+
+	"secret_key.file":        "unencrypted file",
+	"secret_key.keychain":    "OS keychain",
+	"secret_key.pin":         "PIN-protected file",
+	"secret_key.keyfile":     "key file on removable media",
+	"keyfile.need_path":      "--secret-key keyfile needs --keyfile <path>",
+	"keyfile.no_device":      "%s does not exist; is the device mounted?",
+	"secret_key.manual":      "entered at unlock (not stored)",
+	"migrate.same":           "The secret key is already stored as: %s",
+	"migrate.confirm_manual": "The secret key will no longer be stored. Type it to confirm you have a copy.",
+	"migrate.mismatch":       "that is not the secret key; nothing was changed",
+	"migrate.done":           "Secret key now stored as: %s",
+
+	"onboard.title":      "Create your vault",
+	"onboard.basics":     "Let's add some basics (press Enter to skip any):",
+	"onboard.done_saved": "Done — %d field(s) saved. Your vault is ready.",
+	"onboard.done":       "Done — your vault is ready.",
+	"onboard.next":       "Run 'pvault status' to see what's stored.",
+
+	"onboard.field.identity.full_name":     "Full name",
+	"onboard.field.identity.email":         "Email",
+	"onboard.field.addresses.home_street":  "Street address",
+	"onboard.field.addresses.home_city":    "City",
+	"onboard.field.addresses.home_state":   "State",
+	"onboard.field.addresses.home_zip":     "ZIP",
+	"onboard.field.addresses.home_country": "Country",
+	"onboard.field.preferences.timezone":   "Timezone",
+
+	"unlock.already":    "Vault is already unlocked (server running).",
+	"unlock.unverified": "Vault server started (could not verify status).",
+	"unlock.done":       "Vault unlocked. Server running on %s",
+
+	"sessions.row":        "unlocked %s, locks in %s if idle",
+	"sessions.row_pinned": "unlocked %s, does not lock when idle",
+	"sessions.revoked":    "Revoked session %s",
+	"config.saved":        "Saved %s to %s",
+	"config.unknown":      "unknown config key %q; see 'pvault config list'",
+	"config.env_override": "Note: %s is set in the environment and overrides the file",
+
+	"lock.stopped": "Vault locked (server stopped).",
+	"lock.done":    "Vault locked.",
+
+	"relock.done":   "Vault locked; the server is still running. 'pvault unlock' unlocks it again.",
+	"relock.prompt": "The vault is locked. Enter the password to unlock it again.",
+	"relock.hint":   "The vault locked, or this session ended. Run 'pvault unlock', or pass --prompt-on-locked to be asked for the password.",
+
+	"status.not_running":     "Vault is locked (server not running).",
+	"status.not_initialized": "Vault is not initialized. Run 'pvault init' first.",
+	"status.locked":          "Status:  locked",
+	"status.unlocked":        "Status:  unlocked",
+	"status.fields":          "Fields:  %d",
+	"status.categories":      "Categories:",
+	"status.category_fields": "%d fields",
+	"status.secret_key":      "Secret:  %s",
+
+	"field.set":        "Set %s",
+	"field.deleted":    "Deleted %s",
+	"list.empty":       "No fields found.",
+	"field.normalized": "Stored as %s (original kept in history)",
+	"history.empty":    "No history for this field.",
+
+	"verify.ok":            "Verified %d field(s) in %d category(ies); no problems found.",
+	"verify.failed":        "Problems found.",
+	"verify.salt_mismatch": "Salt mismatch: the vault's metadata changed since it was unlocked. Run 'pvault lock' and 'pvault unlock'.",
+	"verify.key_mismatch":  "%s: key check failed — this category was written with a different key or its metadata was altered",
+	"verify.key_unknown":   "%s: no value decrypts and there is no key check value to tell why",
+	"verify.key_added":     "%s: key check value recorded",
+	"verify.corrupted":     "%s: value is corrupted; set it again or restore it from a backup",
+
+	"alias.set":           "Alias %s → %s",
+	"alias.deleted":       "Removed alias %s",
+	"alias.empty":         "No aliases.",
+	"canary.created":      "Canary %s = %s",
+	"canary.revoke":       "Any service token that reads it will be revoked.",
+	"canary.hint":         "Reads by service tokens are logged as 'canary' and sent to notify.url or notify.cmd.",
+	"canary.deleted":      "Removed canary %s",
+	"canary.empty":        "No canaries.",
+	"canary.alert_only":   "alert",
+	"canary.alert_revoke": "alert + revoke token",
+
+	"acl.allow":      "Allowed %s on %s (only allowed consumers can reach it now)",
+	"acl.deny":       "Denied %s on %s",
+	"acl.removed":    "Removed %s from the access lists of %s",
+	"acl.empty":      "No field access lists.",
+	"acl.allow_list": "allow: %s",
+	"acl.deny_list":  "deny: %s",
+
+	"consumer.trust":   "%s is now %s (reaches fields up to %s)",
+	"consumer.reset":   "%s is trusted again",
+	"consumer.empty":   "No consumers.",
+	"consumer.max":     "up to %s",
+	"consumer.default": "(default)",
+
+	"offline.usage":          "--offline works with get, set, and list",
+	"offline.no_vault":       "no vault in %s — run 'pvault init' first",
+	"offline.server_running": "the vault server at %s is running and wouldn't see an offline write; drop --offline, or 'pvault lock' first",
+
+	"template.set":     "%s's context reads now follow its template",
+	"template.deleted": "%s gets everything its scopes cover again",
+	"template.empty":   "No context templates.",
+	"template.mask":    "%s masked: %s",
+
+	"pin.pinned":   "Pinned %s",
+	"pin.unpinned": "Unpinned %s",
+	"pin.empty":    "No pinned fields.",
+
+	"get.no_match":  "no field matches %q",
+	"get.resolved":  "→ %s",
+	"get.choose":    "%q matches several fields:",
+	"get.choice":    "Which one? [1-%d]",
+	"get.ambiguous": "%q matches several fields: %s",
+	"get.no_choice": "no field chosen",
+
+	"add.unknown":  "unknown category %q (choose from: %s)",
+	"add.complete": "Every recommended %s field is already stored.",
+	"add.intro":    "%d field(s) to fill in, %d already stored. Press Enter to skip any.",
+	"add.failed":   "could not save %s: %v",
+	"add.done":     "Done — %d field(s) saved.",
+
+	"onboard.unknown_profile": "unknown profile %q (choose from: %s)",
+	"onboard.no_password":     "--from-file needs the password in the file or in VAULT_PASSWORD",
+	"onboard.bad_file":        "reading %s: %v",
+
+	"import.csv_summary":   "%d new, %d changed, %d unchanged",
+	"import.csv_prompt":    "Apply these changes? [y/N]",
+	"import.csv_cancelled": "Nothing written.",
+
+	"note.set":     "Saved the note on %s",
+	"note.cleared": "Cleared the note on %s",
+	"note.none":    "%s has no note.",
+
+	"type.set":     "Set the value type of %s to %s",
+	"type.cleared": "%s is plain text again",
+	"get.masked":   "(masked: critical values and PEM blocks are hidden; pass --reveal to show them)",
+
+	"entry.added":   "Added an entry to %s",
+	"entry.removed": "Removed %s from %s",
+
+	"link.linked":   "Linked %s to %s",
+	"link.unlinked": "Unlinked %s from %s",
+	"link.empty":    "No links.",
+
+	"schema.packs_title":   "Schema packs (pvault schema enable <pack> adds one to the recommended schema):",
+	"schema.pack_enabled":  "enabled",
+	"schema.pack_unknown":  "unknown schema pack %q (available: %s)",
+	"schema.pack_restart":  "Restart a running server (pvault lock, then pvault unlock) to use the change.",
+	"schema.packs_invalid": "Warning: schema.packs: %v; no schema packs enabled",
+	"schema.pack.vehicles": "Vehicles and insurance policies",
+	"schema.pack.travel":   "Passport, visa, and trusted traveler documents, with expiry alerts",
+	"schema.pack.medical":  "Medical details that service tokens must give a purpose to read",
+
+	"expiring.none":    "Nothing expires in the next %d days.",
+	"expiring.in":      "in %d days",
+	"expiring.today":   "today",
+	"expiring.expired": "expired %d days ago",
+
+	"redact.summary": "Redacted %d value(s) from %d field(s).",
+	"redact.none":    "Nothing to redact.",
+
+	"appendonly.set":     "Existing %s fields now change only with a session; tokens may still add new ones.",
+	"appendonly.removed": "%s is writable again.",
+	"appendonly.empty":   "No append-only categories.",
+
+	"demo.done": "Created a demo vault of %d made-up fields (seed %d) in %s",
+	"demo.next": "Try it: VAULT_DIR=%s VAULT_PORT=%s pvault unlock (password: %s)",
+
+	"bench.start":      "Benchmarking on %s/%s with %d CPUs, each against a throwaway vault (under a minute)",
+	"bench.row":        "%s %-22s %12s per op (budget %s)",
+	"bench.none":       "No benchmark matches %q",
+	"bench.summary":    "%d benchmark(s) over budget.",
+	"bench.summary_ok": "All benchmarks within budget.",
+
+	"compact.done":    "Compacted the vault database: %s → %s (%s reclaimed).",
+	"compact.nothing": "Nothing to compact: an encrypted database is rewritten whole on every write.",
+
+	"config.export_settings_only": "The server isn't running, so only settings were exported; start it and unlock the vault to include consumers, access lists, templates, and tokens",
+	"config.exported":             "Exported %d settings, %d consumers, and %d tokens to %s",
+	"config.import_needs_server":  "The file includes the vault's consumers, access lists, and tokens; start the server and unlock the vault first",
+	"config.imported_settings":    "Imported %d settings into %s",
+	"config.imported_wiring":      "Imported %d consumers, %d access lists, %d templates, %d aliases, and %d append-only categories",
+	"config.reissued":             "New token for %s, replacing %s...: %s",
+	"config.tokens_skipped":       "%d tokens not reissued: expired, or --reissue-tokens not given",
+
+	"approve.ready":        "Approval prompts appear here for reads at %s or above and for each consumer's first read.",
+	"approve.new_consumer": "%s has not read from this vault before.",
+	"approve.request":      "%s (%s) asks for %s, up to %s",
+	"approve.purpose":      "Purpose: %s",
+	"approve.more":         "and %d more",
+	"approve.prompt":       "Allow? [y/N]",
+	"approve.allowed":      "Allowed.",
+	"approve.denied":       "Denied.",
+	"approve.timed_out":    "No answer in time; the read was refused.",
+
+	"set.prompt":       "Value for %s (hidden): ",
+	"set.prompt_again": "Again: ",
+	"set.mismatch":     "The two entries differ; nothing was set.",
+	"set.empty":        "No value given; nothing was set.",
+	"set.history_hint": "Tip: 'pvault set %s --prompt' keeps the value out of shell history.",
+	"set.normalized":   "Stored normalized (original kept in history)",
+
+	"status.token_expiring":      "Warning: the service token for %s (%s) expires in %d days",
+	"status.token_expiring_soon": "Warning: the service token for %s (%s) expires within a day",
+
+	"enrich.title":  "Your address enricher suggests:",
+	"enrich.change": "%s: %s → %s. Accept? [y/N]",
+	"enrich.add":    "%s: %s. Accept? [y/N]",
+	"enrich.failed": "Could not check the address (HTTP %d).",
+
+	"import.summary":     "%d new, %d unchanged, %d conflict(s)",
+	"import.conflict":    "%s: vault v%d (%s) vs file v%d (%s)",
+	"import.vault_value": "vault: %s",
+	"import.file_value":  "file:  %s",
+	"import.prompt":      "Use the file value? [y/N]",
+	"import.keep":        "→ keeping the vault value",
+	"import.take":        "→ importing the file value",
+	"import.dry_run":     "Dry run: nothing written.",
+	"import.nothing":     "Nothing to import.",
+	"import.done":        "Imported %d field(s).",
+
+	"import.deletions":     "%d field(s) deleted in the file",
+	"import.deletion":      "%s: deleted in file (%s) vs vault v%d (%s)",
+	"import.delete_prompt": "Delete it from the vault? [y/N]",
+	"import.delete":        "→ deleting it from the vault",
+	"import.deleted":       "Deleted %d field(s).",
+
+	"merge.same":      "That is this vault; give the path to the other copy.",
+	"merge.conflict":  "%s: this vault v%d (%s) vs other copy v%d (%s)",
+	"merge.deletions": "%d field(s) deleted in the other copy",
+	"merge.deletion":  "%s: deleted in the other copy (%s) vs this vault v%d (%s)",
+	"merge.take":      "→ taking the other copy's value",
+	"merge.nothing":   "Already in sync.",
+	"merge.done":      "Merged %d change(s) as transaction %s.",
+
+	"status.replica":       "Replica: of %s, synced %s",
+	"status.replica_never": "Replica: of %s, not synced yet",
+	"status.replica_error": "  last pull failed: %s",
+
+	"escrow.none":       "No fields to escrow: the vault has no critical fields (or none match --fields).",
+	"escrow.done":       "Escrowed %d field(s) for %d recipient(s). Only their private keys can open it.",
+	"bootstrap.written": "Wrote %s (mode 0600). It holds secrets: keep it out of version control.",
+	"k8s.add":           "+ %s/%s %s  ← %s",
+	"k8s.drift":         "~ %s/%s %s  ← %s (drifted: differs from the vault)",
+	"k8s.same":          "= %s/%s %s  (in sync)",
+	"k8s.dry_run":       "Dry run: no Secrets were changed.",
+	"k8s.done":          "%d Secret(s) created, %d updated.",
+	"k8s.not_local":     "Context %q (%s) is not a local cluster. pvault k8s only writes to kind, minikube, k3d, Docker Desktop, and clusters on localhost; pick one with --context.",
+
+	"kms.enrolled":      "Vault key wrapped with %s key %s.",
+	"kms.headless":      "'pvault serve --headless' now unlocks through the KMS when no password is given.",
+	"kms.unwrap_failed": "The KMS encrypted the key but can't decrypt it again (%v); the server would fail to unlock. Check the key's decrypt permission.",
+	"kms.none":          "No KMS enrolled. Enroll one with 'pvault kms enroll'.",
+	"kms.status":        "Vault key wrapped with %s key %s (since %s)",
+	"kms.removed":       "KMS enrollment removed. The KMS key itself is unchanged.",
+
+	"plugins.commands":    "Plugin commands:",
+	"plugins.no_commands": "No plugin commands (pvault-<name> programs on PATH).",
+	"plugins.hooks":       "Hook plugins (plugins.hooks):",
+	"plugins.no_hooks":    "No hook plugins configured (plugins.hooks).",
+	"plugins.broken":      "not working: %v",
+
+	"emergency.setup":     "Sealed %d field(s) for %s, released after %s unless you deny the request. Their access code:",
+	"emergency.code_hint": "Give this code to the contact privately. It is not shown again.",
+	"emergency.empty":     "No emergency contacts.",
+	"emergency.pending":   "requested; released at %s unless denied",
+	"emergency.denied":    "Denied the emergency access request from %s",
+	"emergency.removed":   "Removed emergency contact %s",
+	"emergency.requested": "Request sent. The owner can deny it until %s; then run 'pvault emergency release'.",
+	"emergency.released":  "Bundle released. Decrypt it with your private key, e.g. 'age -d -i key.txt'.",
+
+	"doctor.private":            "%s is private",
+	"doctor.mode":               "%s is readable by other users (mode %04o)",
+	"doctor.no_dir":             "No vault at %s",
+	"doctor.secret_plaintext":   "Secret key is stored unencrypted at %s",
+	"doctor.secret_stored":      "Secret key storage: %s",
+	"doctor.secret_unreadable":  "Cannot read the secret key (%s): %v",
+	"doctor.no_db":              "No database in %s",
+	"doctor.db_ok":              "Database integrity check passed",
+	"doctor.db_corrupt":         "Database integrity check failed: %s",
+	"doctor.db_unreadable":      "Cannot read the database: %v",
+	"doctor.enc_ok":             "Encrypted database file is readable (run 'pvault verify' to check its contents)",
+	"doctor.server_ok":          "Vault server is running on %s",
+	"doctor.server_down":        "No server on %s; 'pvault unlock' starts one",
+	"doctor.port_conflict":      "Another program is listening on %s",
+	"doctor.stale_pid":          "PID file names process %d, which is not running",
+	"doctor.pid_not_serving":    "Process %d from the PID file is running but not serving the vault",
+	"doctor.stale_session":      "The session agent's token is stale",
+	"doctor.no_session":         "No session agent; token checks skipped",
+	"doctor.session_file":       "An old session file holds a session token in plain text",
+	"doctor.locked":             "Vault is locked; token checks skipped",
+	"doctor.clock_skew":         "Server clock differs from this machine by %s",
+	"doctor.token_future":       "Service token for %s was created in the future (%s)",
+	"doctor.token_expired":      "Service token for %s is still accepted after it expired (%s)",
+	"doctor.token_expiring":     "Service token for %s expires %s",
+	"doctor.clock_ok":           "Clock and token times are consistent",
+	"doctor.summary":            "%d problem(s) found.",
+	"doctor.summary_ok":         "No problems found.",
+	"doctor.fix.init":           "Run 'pvault init', or set VAULT_DIR to your vault",
+	"doctor.fix.chmod":          "Run: chmod %s %s",
+	"doctor.fix.migrate_secret": "Run 'pvault migrate-secret-key keychain' (or pin, or manual)",
+	"doctor.fix.secret_lost":    "Remove %s and enter the secret key from your backup at unlock; 'pvault migrate-secret-key' can store it again",
+	"doctor.keyfile_missing":    "Key file %s is not available",
+	"doctor.fix.keyfile_mount":  "Mount the device that holds it before unlocking",
+	"doctor.fix.restore":        "Stop the server and restore the database from a backup",
+	"doctor.fix.port":           "Stop that program, or move the vault with 'pvault config set server.port <port>'",
+	"doctor.fix.rm":             "Run: rm %s",
+	"doctor.fix.lock":           "Run 'pvault lock', then 'pvault unlock'",
+	"doctor.fix.unlock":         "Run 'pvault unlock'",
+	"doctor.fix.clock":          "Sync the system clock (enable NTP), then revoke and recreate affected service tokens",
+	"doctor.fix.token":          "Create a replacement with 'pvault create-service-token'",
+
+	"service.installed":     "Installed the pvault service for %s. Start it with 'pvault service start', then run 'pvault unlock'.",
+	"service.removed":       "Removed the pvault service.",
+	"service.started":       "Service started (locked). Run 'pvault unlock' to unlock it.",
+	"service.stopped":       "Service stopped.",
+	"service.status":        "Service is %s.",
+	"service.not_installed": "The pvault service is not installed. Run 'pvault service install' as administrator.",
+	"service.unsupported":   "pvault service is only available on Windows; use 'pvault unlock' to run the server in the background.",
+
+	"schema.title":        "Recommended Vault Schema",
+	"schema.user_defined": "(user-defined fields)",
+}
@@ -0,0 +1,428 @@
+package i18n
+
+var es = map[string]string{
+	"error": "Error",
+
+	"prompt.password":         "Contraseña del perfil: ",
+	"prompt.confirm_password": "Confirmar contraseña: ",
+	"prompt.confirm":          "Confirmar: ",
+	"prompt.elevate":          "Esta acción requiere volver a introducir la contraseña.",
+	"password.too_short":      "la contraseña debe tener al menos 8 caracteres",
+	"password.mismatch":       "las contraseñas no coinciden",
+	"prompt.pin":              "PIN de la clave secreta: ",
+	"prompt.new_pin":          "Elige un PIN para la clave secreta: ",
+	"prompt.confirm_pin":      "Confirma el PIN: ",
+	"prompt.secret_key":       "Clave secreta: ",
+	"pin.too_short":           "el PIN debe tener al menos %d caracteres",
+	"pin.mismatch":            "los PIN no coinciden",
+
+	"init.done":             "Bóveda inicializada correctamente.",
+	"init.secret_key":       "Tu clave secreta (guárdala en un lugar seguro):",
+	"init.secret_saved":     "La clave secreta también se guardó en: %s",
+	"init.next":             "Siguiente: ejecuta 'pvault unlock' para empezar a usar tu bóveda.",
+	"init.secret_keychain":  "Clave secreta guardada también en el llavero del sistema.",
+	"init.secret_pin":       "Clave secreta guardada también en %s, cifrada con tu PIN.",
+	"init.secret_keyfile":   "Clave secreta guardada también en %s. Para desbloquear, ese dispositivo debe estar montado; guárdalo lejos de este equipo.",
+	"init.secret_manual":    "La clave secreta no se guarda en ningún sitio; la introducirás en cada desbloqueo.",
+	"init.secret_plaintext": "Ahí no está cifrada. Para protegerla, ejecuta 'pvault migrate-secret-key keychain' (o pin, o manual).",
+
+	"secret_key.file":        "archivo sin cifrar",
+	"secret_key.keychain":    "llavero del sistema",
+	"secret_key.pin":         "archivo protegido con PIN",
+	"secret_key.keyfile":     "archivo de clave en un medio extraíble",
+	"keyfile.need_path":      "--secret-key keyfile necesita --keyfile <ruta>",
+	"keyfile.no_device":      "%s no existe; ¿está montado el dispositivo?",
+	"secret_key.manual":      "introducida al desbloquear (no se guarda)",
+	"migrate.same":           "La clave secreta ya está guardada como: %s",
+	"migrate.confirm_manual": "La clave secreta dejará de guardarse. Escríbela para confirmar que tienes una copia.",
+	"migrate.mismatch":       "esa no es la clave secreta; no se ha cambiado nada",
+	"migrate.done":           "Clave secreta guardada ahora como: %s",
+
+	"onboard.title":      "Crea tu bóveda",
+	"onboard.basics":     "Añadamos algunos datos básicos (pulsa Intro para omitir):",
+	"onboard.done_saved": "Listo: %d campo(s) guardado(s). Tu bóveda está lista.",
+	"onboard.done":       "Listo: tu bóveda está lista.",
+	"onboard.next":       "Ejecuta 'pvault status' para ver lo que hay guardado.",
+
+	"onboard.field.identity.full_name":     "Nombre completo",
+	"onboard.field.identity.email":         "Correo electrónico",
+	"onboard.field.addresses.home_street":  "Calle y número",
+	"onboard.field.addresses.home_city":    "Ciudad",
+	"onboard.field.addresses.home_state":   "Estado o provincia",
+	"onboard.field.addresses.home_zip":     "Código postal",
+	"onboard.field.addresses.home_country": "País",
+	"onboard.field.preferences.timezone":   "Zona horaria",
+
+	"unlock.already":    "La bóveda ya está desbloqueada (servidor en ejecución).",
+	"unlock.unverified": "Servidor de la bóveda iniciado (no se pudo verificar el estado).",
+	"unlock.done":       "Bóveda desbloqueada. Servidor en ejecución en %s",
+
+	"sessions.row":        "desbloqueada %s, se bloquea en %s si está inactiva",
+	"sessions.row_pinned": "desbloqueada %s, no se bloquea por inactividad",
+	"sessions.revoked":    "Sesión %s revocada",
+	"config.saved":        "%s guardado en %s",
+	"config.unknown":      "clave de configuración desconocida %q; consulte 'pvault config list'",
+	"config.env_override": "Nota: %s está definida en el entorno y tiene prioridad sobre el archivo",
+
+	"lock.stopped": "Bóveda bloqueada (servidor detenido).",
+	"lock.done":    "Bóveda bloqueada.",
+
+	"relock.done":   "Bóveda bloqueada; el servidor sigue en marcha. 'pvault unlock' la desbloquea de nuevo.",
+	"relock.prompt": "La bóveda está bloqueada. Introduce la contraseña para desbloquearla de nuevo.",
+	"relock.hint":   "La bóveda se bloqueó o esta sesión terminó. Ejecuta 'pvault unlock' o añade --prompt-on-locked para que se pida la contraseña.",
+
+	"status.not_running":     "La bóveda está bloqueada (el servidor no está en ejecución).",
+	"status.not_initialized": "La bóveda no está inicializada. Ejecuta primero 'pvault init'.",
+	"status.locked":          "Estado:  bloqueada",
+	"status.unlocked":        "Estado:  desbloqueada",
+	"status.fields":          "Campos:  %d",
+	"status.categories":      "Categorías:",
+	"status.category_fields": "%d campos",
+	"status.secret_key":      "Clave:   %s",
+
+	"field.set":        "Guardado %s",
+	"field.deleted":    "Eliminado %s",
+	"list.empty":       "No se encontraron campos.",
+	"field.normalized": "Guardado como %s (el original se conserva en el historial)",
+	"history.empty":    "Este campo no tiene historial.",
+
+	"verify.ok":            "Verificados %d campo(s) en %d categoría(s); no se encontraron problemas.",
+	"verify.failed":        "Se encontraron problemas.",
+	"verify.salt_mismatch": "La sal no coincide: los metadatos de la bóveda cambiaron desde que se desbloqueó. Ejecuta 'pvault lock' y 'pvault unlock'.",
+	"verify.key_mismatch":  "%s: la comprobación de clave falló — esta categoría se escribió con otra clave o sus metadatos se alteraron",
+	"verify.key_unknown":   "%s: ningún valor se puede descifrar y no hay valor de comprobación para saber por qué",
+	"verify.key_added":     "%s: valor de comprobación de clave registrado",
+	"verify.corrupted":     "%s: el valor está dañado; vuelve a guardarlo o restáuralo desde una copia de seguridad",
+
+	"alias.set":           "Alias %s → %s",
+	"alias.deleted":       "Alias %s eliminado",
+	"alias.empty":         "No hay alias.",
+	"canary.created":      "Canario %s = %s",
+	"canary.revoke":       "Se revocará cualquier token de servicio que lo lea.",
+	"canary.hint":         "Las lecturas con tokens de servicio se registran como 'canary' y se envían a notify.url o notify.cmd.",
+	"canary.deleted":      "Canario %s eliminado",
+	"canary.empty":        "No hay canarios.",
+	"canary.alert_only":   "alerta",
+	"canary.alert_revoke": "alerta + revocar token",
+
+	"acl.allow":      "%s permitido en %s (ahora solo los consumidores permitidos pueden acceder)",
+	"acl.deny":       "%s denegado en %s",
+	"acl.removed":    "%s eliminado de las listas de acceso de %s",
+	"acl.empty":      "No hay listas de acceso de campos.",
+	"acl.allow_list": "permitidos: %s",
+	"acl.deny_list":  "denegados: %s",
+
+	"consumer.trust":   "%s ahora es %s (accede a campos hasta %s)",
+	"consumer.reset":   "%s vuelve a ser de confianza",
+	"consumer.empty":   "No hay consumidores.",
+	"consumer.max":     "hasta %s",
+	"consumer.default": "(predeterminado)",
+
+	"offline.usage":          "--offline funciona con get, set y list",
+	"offline.no_vault":       "no hay bóveda en %s — ejecuta primero 'pvault init'",
+	"offline.server_running": "el servidor de la bóveda en %s está en marcha y no vería una escritura sin conexión; quita --offline o ejecuta primero 'pvault lock'",
+
+	"template.set":     "las lecturas de contexto de %s siguen ahora su plantilla",
+	"template.deleted": "%s vuelve a recibir todo lo que cubren sus ámbitos",
+	"template.empty":   "No hay plantillas de contexto.",
+	"template.mask":    "%s enmascarado: %s",
+
+	"pin.pinned":   "%s fijado",
+	"pin.unpinned": "%s desfijado",
+	"pin.empty":    "No hay campos fijados.",
+
+	"get.no_match":  "ningún campo coincide con %q",
+	"get.resolved":  "→ %s",
+	"get.choose":    "%q coincide con varios campos:",
+	"get.choice":    "¿Cuál? [1-%d]",
+	"get.ambiguous": "%q coincide con varios campos: %s",
+	"get.no_choice": "no se eligió ningún campo",
+
+	"add.unknown":  "categoría desconocida %q (elige entre: %s)",
+	"add.complete": "Todos los campos recomendados de %s ya están guardados.",
+	"add.intro":    "%d campo(s) por rellenar, %d ya guardado(s). Pulsa Intro para omitir cualquiera.",
+	"add.failed":   "no se pudo guardar %s: %v",
+	"add.done":     "Listo — %d campo(s) guardado(s).",
+
+	"onboard.unknown_profile": "perfil desconocido %q (elige entre: %s)",
+	"onboard.no_password":     "--from-file necesita la contraseña en el archivo o en VAULT_PASSWORD",
+	"onboard.bad_file":        "leyendo %s: %v",
+
+	"import.csv_summary":   "%d nuevo(s), %d cambiado(s), %d sin cambios",
+	"import.csv_prompt":    "¿Aplicar estos cambios? [s/N]",
+	"import.csv_cancelled": "No se escribió nada.",
+
+	"note.set":     "Nota de %s guardada",
+	"note.cleared": "Nota de %s borrada",
+	"note.none":    "%s no tiene nota.",
+
+	"type.set":     "Tipo de valor de %s establecido en %s",
+	"type.cleared": "%s vuelve a ser texto sin formato",
+	"get.masked":   "(oculto: los valores críticos y los bloques PEM se ocultan; usa --reveal para mostrarlos)",
+
+	"entry.added":   "Entrada añadida a %s",
+	"entry.removed": "%s eliminada de %s",
+
+	"link.linked":   "%s vinculado a %s",
+	"link.unlinked": "Vínculo de %s con %s eliminado",
+	"link.empty":    "No hay vínculos.",
+
+	"schema.packs_title":   "Paquetes de esquema (pvault schema enable <paquete> añade uno al esquema recomendado):",
+	"schema.pack_enabled":  "activado",
+	"schema.pack_unknown":  "paquete de esquema desconocido %q (disponibles: %s)",
+	"schema.pack_restart":  "Reinicie el servidor en ejecución (pvault lock y luego pvault unlock) para aplicar el cambio.",
+	"schema.packs_invalid": "Aviso: schema.packs: %v; no se activó ningún paquete de esquema",
+	"schema.pack.vehicles": "Vehículos y pólizas de seguro",
+	"schema.pack.travel":   "Pasaporte, visado y programas de viajero de confianza, con avisos de vencimiento",
+	"schema.pack.medical":  "Datos médicos que los tokens de servicio deben justificar para leer",
+
+	"expiring.none":    "Nada vence en los próximos %d días.",
+	"expiring.in":      "en %d días",
+	"expiring.today":   "hoy",
+	"expiring.expired": "venció hace %d días",
+
+	"redact.summary": "Se ocultaron %d valor(es) de %d campo(s).",
+	"redact.none":    "Nada que ocultar.",
+
+	"appendonly.set":     "Los campos %s existentes solo cambian con una sesión; los tokens aún pueden añadir nuevos.",
+	"appendonly.removed": "%s vuelve a ser modificable.",
+	"appendonly.empty":   "No hay categorías de solo adición.",
+
+	"demo.done": "Bóveda de demostración creada con %d campos ficticios (semilla %d) en %s",
+	"demo.next": "Pruébela: VAULT_DIR=%s VAULT_PORT=%s pvault unlock (contraseña: %s)",
+
+	"bench.start":      "Midiendo en %s/%s con %d CPU, cada prueba con una bóveda desechable (menos de un minuto)",
+	"bench.row":        "%s %-22s %12s por operación (presupuesto %s)",
+	"bench.none":       "Ninguna prueba coincide con %q",
+	"bench.summary":    "%d prueba(s) por encima del presupuesto.",
+	"bench.summary_ok": "Todas las pruebas dentro del presupuesto.",
+
+	"compact.done":    "Base de datos de la bóveda compactada: %s → %s (%s recuperados).",
+	"compact.nothing": "Nada que compactar: una base de datos cifrada se reescribe entera en cada escritura.",
+
+	"config.export_settings_only": "El servidor no está en marcha, así que solo se exportaron los ajustes; inícielo y desbloquee la bóveda para incluir consumidores, listas de acceso, plantillas y tokens",
+	"config.exported":             "Exportados %d ajustes, %d consumidores y %d tokens a %s",
+	"config.import_needs_server":  "El archivo incluye los consumidores, listas de acceso y tokens de la bóveda; inicie antes el servidor y desbloquee la bóveda",
+	"config.imported_settings":    "Importados %d ajustes en %s",
+	"config.imported_wiring":      "Importados %d consumidores, %d listas de acceso, %d plantillas, %d alias y %d categorías de solo añadir",
+	"config.reissued":             "Nuevo token para %s, sustituye a %s...: %s",
+	"config.tokens_skipped":       "%d tokens no reemitidos: caducados, o sin --reissue-tokens",
+
+	"approve.ready":        "Las solicitudes de aprobación aparecen aquí para lecturas de nivel %s o superior y para la primera lectura de cada consumidor.",
+	"approve.new_consumer": "%s nunca ha leído de esta bóveda.",
+	"approve.request":      "%s (%s) pide %s, hasta el nivel %s",
+	"approve.purpose":      "Motivo: %s",
+	"approve.more":         "y %d más",
+	"approve.prompt":       "¿Permitir? [s/N]",
+	"approve.allowed":      "Permitido.",
+	"approve.denied":       "Denegado.",
+	"approve.timed_out":    "Sin respuesta a tiempo; se rechazó la lectura.",
+
+	"set.prompt":       "Valor de %s (oculto): ",
+	"set.prompt_again": "Otra vez: ",
+	"set.mismatch":     "Las dos entradas no coinciden; no se guardó nada.",
+	"set.empty":        "No se dio ningún valor; no se guardó nada.",
+	"set.history_hint": "Consejo: 'pvault set %s --prompt' mantiene el valor fuera del historial del shell.",
+	"set.normalized":   "Guardado normalizado (el original se conserva en el historial)",
+
+	"status.token_expiring":      "Aviso: el token de servicio de %s (%s) caduca en %d días",
+	"status.token_expiring_soon": "Aviso: el token de servicio de %s (%s) caduca en menos de un día",
+
+	"enrich.title":  "El servicio de direcciones sugiere:",
+	"enrich.change": "%s: %s → %s. ¿Aceptar? [s/N]",
+	"enrich.add":    "%s: %s. ¿Aceptar? [s/N]",
+	"enrich.failed": "No se pudo comprobar la dirección (HTTP %d).",
+
+	"import.summary":     "%d nuevo(s), %d sin cambios, %d conflicto(s)",
+	"import.conflict":    "%s: bóveda v%d (%s) frente a archivo v%d (%s)",
+	"import.vault_value": "bóveda:  %s",
+	"import.file_value":  "archivo: %s",
+	"import.prompt":      "¿Usar el valor del archivo? [s/N]",
+	"import.keep":        "→ se conserva el valor de la bóveda",
+	"import.take":        "→ se importa el valor del archivo",
+	"import.dry_run":     "Simulación: no se escribió nada.",
+	"import.nothing":     "Nada que importar.",
+	"import.done":        "%d campo(s) importado(s).",
+
+	"import.deletions":     "%d campo(s) eliminado(s) en el archivo",
+	"import.deletion":      "%s: eliminado en el archivo (%s) frente a bóveda v%d (%s)",
+	"import.delete_prompt": "¿Eliminarlo de la bóveda? [s/N]",
+	"import.delete":        "→ se elimina de la bóveda",
+	"import.deleted":       "%d campo(s) eliminado(s).",
+
+	"merge.same":      "Esa es esta bóveda; indica la ruta de la otra copia.",
+	"merge.conflict":  "%s: esta bóveda v%d (%s) frente a la otra copia v%d (%s)",
+	"merge.deletions": "%d campo(s) eliminado(s) en la otra copia",
+	"merge.deletion":  "%s: eliminado en la otra copia (%s) frente a esta bóveda v%d (%s)",
+	"merge.take":      "→ se toma el valor de la otra copia",
+	"merge.nothing":   "Ya está sincronizada.",
+	"merge.done":      "%d cambio(s) fusionado(s), transacción %s.",
+
+	"status.replica":       "Réplica: de %s, sincronizada %s",
+	"status.replica_never": "Réplica: de %s, aún sin sincronizar",
+	"status.replica_error": "  la última descarga falló: %s",
+
+	"escrow.none":       "No hay campos para custodiar: el vault no tiene campos críticos (o ninguno coincide con --fields).",
+	"escrow.done":       "%d campo(s) custodiado(s) para %d destinatario(s). Solo sus claves privadas pueden abrirlo.",
+	"bootstrap.written": "Se escribió %s (modo 0600). Contiene secretos: mantenlo fuera del control de versiones.",
+	"k8s.add":           "+ %s/%s %s  ← %s",
+	"k8s.drift":         "~ %s/%s %s  ← %s (desviado: difiere de la bóveda)",
+	"k8s.same":          "= %s/%s %s  (sincronizado)",
+	"k8s.dry_run":       "Simulación: no se cambió ningún Secret.",
+	"k8s.done":          "%d Secret(s) creado(s), %d actualizado(s).",
+	"k8s.not_local":     "El contexto %q (%s) no es un clúster local. pvault k8s solo escribe en kind, minikube, k3d, Docker Desktop y clústeres en localhost; elige uno con --context.",
+
+	"kms.enrolled":      "Clave de la bóveda envuelta con la clave %s %s.",
+	"kms.headless":      "'pvault serve --headless' ahora desbloquea mediante el KMS cuando no se da contraseña.",
+	"kms.unwrap_failed": "El KMS cifró la clave pero no puede descifrarla (%v); el servidor no podría desbloquear. Revisa el permiso de descifrado de la clave.",
+	"kms.none":          "No hay ningún KMS inscrito. Inscribe uno con 'pvault kms enroll'.",
+	"kms.status":        "Clave de la bóveda envuelta con la clave %s %s (desde %s)",
+	"kms.removed":       "Inscripción del KMS eliminada. La clave del KMS no cambia.",
+
+	"plugins.commands":    "Comandos de plugins:",
+	"plugins.no_commands": "No hay comandos de plugins (programas pvault-<nombre> en el PATH).",
+	"plugins.hooks":       "Plugins de hooks (plugins.hooks):",
+	"plugins.no_hooks":    "No hay plugins de hooks configurados (plugins.hooks).",
+	"plugins.broken":      "no funciona: %v",
+
+	"emergency.setup":     "%d campo(s) sellado(s) para %s, liberados tras %s salvo que deniegues la solicitud. Su código de acceso:",
+	"emergency.code_hint": "Entrega este código al contacto en privado. No se vuelve a mostrar.",
+	"emergency.empty":     "No hay contactos de emergencia.",
+	"emergency.pending":   "solicitado; se libera el %s salvo denegación",
+	"emergency.denied":    "Solicitud de acceso de emergencia de %s denegada",
+	"emergency.removed":   "Contacto de emergencia %s eliminado",
+	"emergency.requested": "Solicitud enviada. El propietario puede denegarla hasta el %s; después ejecuta 'pvault emergency release'.",
+	"emergency.released":  "Paquete liberado. Descífralo con tu clave privada, p. ej. 'age -d -i key.txt'.",
+
+	"doctor.private":            "%s es privado",
+	"doctor.mode":               "%s es legible por otros usuarios (modo %04o)",
+	"doctor.no_dir":             "No hay bóveda en %s",
+	"doctor.secret_plaintext":   "La clave secreta está sin cifrar en %s",
+	"doctor.secret_stored":      "Almacenamiento de la clave secreta: %s",
+	"doctor.secret_unreadable":  "No se puede leer la clave secreta (%s): %v",
+	"doctor.no_db":              "No hay base de datos en %s",
+	"doctor.db_ok":              "La comprobación de integridad de la base de datos pasó",
+	"doctor.db_corrupt":         "La comprobación de integridad de la base de datos falló: %s",
+	"doctor.db_unreadable":      "No se puede leer la base de datos: %v",
+	"doctor.enc_ok":             "El archivo de base de datos cifrado se puede leer (ejecuta 'pvault verify' para comprobar su contenido)",
+	"doctor.server_ok":          "El servidor de la bóveda está en marcha en %s",
+	"doctor.server_down":        "No hay servidor en %s; 'pvault unlock' inicia uno",
+	"doctor.port_conflict":      "Otro programa está escuchando en %s",
+	"doctor.stale_pid":          "El archivo PID indica el proceso %d, que no está en marcha",
+	"doctor.pid_not_serving":    "El proceso %d del archivo PID está en marcha pero no sirve la bóveda",
+	"doctor.stale_session":      "El token del agente de sesión está obsoleto",
+	"doctor.no_session":         "No hay agente de sesión; se omiten las comprobaciones de tokens",
+	"doctor.session_file":       "Un archivo de sesión antiguo guarda un token de sesión en texto plano",
+	"doctor.locked":             "La bóveda está bloqueada; se omiten las comprobaciones de tokens",
+	"doctor.clock_skew":         "El reloj del servidor difiere del de esta máquina en %s",
+	"doctor.token_future":       "El token de servicio de %s se creó en el futuro (%s)",
+	"doctor.token_expired":      "El token de servicio de %s se sigue aceptando después de caducar (%s)",
+	"doctor.token_expiring":     "El token de servicio de %s caduca el %s",
+	"doctor.clock_ok":           "El reloj y las fechas de los tokens son coherentes",
+	"doctor.summary":            "Se encontraron %d problema(s).",
+	"doctor.summary_ok":         "No se encontraron problemas.",
+	"doctor.fix.init":           "Ejecuta 'pvault init' o define VAULT_DIR con tu bóveda",
+	"doctor.fix.chmod":          "Ejecuta: chmod %s %s",
+	"doctor.fix.migrate_secret": "Ejecuta 'pvault migrate-secret-key keychain' (o pin, o manual)",
+	"doctor.fix.secret_lost":    "Elimina %s e introduce la clave secreta de tu copia al desbloquear; 'pvault migrate-secret-key' puede volver a guardarla",
+	"doctor.keyfile_missing":    "El archivo de clave %s no está disponible",
+	"doctor.fix.keyfile_mount":  "Monta el dispositivo que lo contiene antes de desbloquear",
+	"doctor.fix.restore":        "Detén el servidor y restaura la base de datos desde una copia de seguridad",
+	"doctor.fix.port":           "Detén ese programa o mueve la bóveda con 'pvault config set server.port <puerto>'",
+	"doctor.fix.rm":             "Ejecuta: rm %s",
+	"doctor.fix.lock":           "Ejecuta 'pvault lock' y luego 'pvault unlock'",
+	"doctor.fix.unlock":         "Ejecuta 'pvault unlock'",
+	"doctor.fix.clock":          "Sincroniza el reloj del sistema (activa NTP) y luego revoca y vuelve a crear los tokens de servicio afectados",
+	"doctor.fix.token":          "Crea uno nuevo con 'pvault create-service-token'",
+
+	"service.installed":     "Servicio pvault instalado para %s. Inícialo con 'pvault service start' y luego ejecuta 'pvault unlock'.",
+	"service.removed":       "Servicio pvault eliminado.",
+	"service.started":       "Servicio iniciado (bloqueado). Ejecuta 'pvault unlock' para desbloquearlo.",
+	"service.stopped":       "Servicio detenido.",
+	"service.status":        "Estado del servicio: %s.",
+	"service.not_installed": "El servicio pvault no está instalado. Ejecuta 'pvault service install' como administrador.",
+	"service.unsupported":   "pvault service solo está disponible en Windows; usa 'pvault unlock' para ejecutar el servidor en segundo plano.",
+
+	"schema.title":        "Esquema recomendado de la bóveda",
+	"schema.user_defined": "(campos definidos por el usuario)",
+
+	"schema.identity":               "Información de identidad personal",
+	"schema.identity.first_name":    "Nombre de pila",
+	"schema.identity.last_name":     "Apellido(s)",
+	"schema.identity.full_name":     "Nombre completo para mostrar",
+	"schema.identity.email":         "Correo electrónico principal",
+	"schema.identity.phone":         "Número de teléfono",
+	"schema.identity.date_of_birth": "Fecha de nacimiento",
+
+	"schema.addresses":              "Direcciones físicas",
+	"schema.addresses.home_street":  "Calle y número del domicilio",
+	"schema.addresses.home_city":    "Ciudad del domicilio",
+	"schema.addresses.home_state":   "Estado o provincia del domicilio",
+	"schema.addresses.home_zip":     "Código postal del domicilio",
+	"schema.addresses.home_country": "Código de país del domicilio (p. ej. US)",
+
+	"schema.financial":               "Información financiera y fiscal",
+	"schema.financial.filing_status": "Situación de declaración de impuestos",
+	"schema.financial.ssn":           "Número de Seguro Social",
+
+	"schema.payment":                 "Datos de la tarjeta de pago",
+	"schema.payment.card_number":     "Número de la tarjeta",
+	"schema.payment.card_expiry":     "Fecha de caducidad de la tarjeta",
+	"schema.payment.cardholder_name": "Nombre del titular de la tarjeta",
+	"schema.payment.card_brand":      "Marca de la tarjeta (p. ej. Visa, Mastercard)",
+
+	"schema.preferences":          "Preferencias del usuario",
+	"schema.preferences.timezone": "Zona horaria preferida (p. ej. America/New_York)",
+	"schema.preferences.language": "Idioma preferido (p. ej. es)",
+
+	"schema.employment":          "Información laboral",
+	"schema.employment.employer": "Nombre del empleador actual",
+	"schema.employment.title":    "Puesto de trabajo",
+
+	"schema.contacts":                        "Personas cercanas, un grupo de campos cada una (p. ej. contacts.child_1.full_name)",
+	"schema.contacts.spouse.full_name":       "Nombre completo del cónyuge o pareja",
+	"schema.contacts.spouse.phone":           "Teléfono del cónyuge o pareja",
+	"schema.contacts.spouse.email":           "Correo electrónico del cónyuge o pareja",
+	"schema.contacts.spouse.date_of_birth":   "Fecha de nacimiento del cónyuge o pareja",
+	"schema.contacts.emergency.full_name":    "Nombre completo del contacto de emergencia",
+	"schema.contacts.emergency.phone":        "Teléfono del contacto de emergencia",
+	"schema.contacts.emergency.relationship": "Relación del contacto de emergencia con usted",
+
+	"schema.vehicles":                     "Datos y matrícula del vehículo",
+	"schema.vehicles.make":                "Marca del vehículo",
+	"schema.vehicles.model":               "Modelo del vehículo",
+	"schema.vehicles.year":                "Año del modelo",
+	"schema.vehicles.vin":                 "Número de identificación del vehículo (VIN)",
+	"schema.vehicles.plate":               "Número de matrícula",
+	"schema.vehicles.registration_expiry": "Fecha de vencimiento del registro",
+
+	"schema.insurance":                      "Aseguradoras y números de póliza",
+	"schema.insurance.auto_provider":        "Aseguradora del automóvil",
+	"schema.insurance.auto_policy_number":   "Número de póliza del seguro de automóvil",
+	"schema.insurance.health_provider":      "Aseguradora de salud",
+	"schema.insurance.health_policy_number": "Número de póliza o de grupo del seguro de salud",
+	"schema.insurance.health_member_id":     "Número de afiliado del seguro de salud",
+	"schema.insurance.home_provider":        "Aseguradora del hogar o de alquiler",
+	"schema.insurance.home_policy_number":   "Número de póliza del seguro del hogar o de alquiler",
+
+	"schema.travel":                         "Documentos de viaje",
+	"schema.travel.passport_number":         "Número de pasaporte",
+	"schema.travel.passport_country":        "Código del país emisor del pasaporte (p. ej. US)",
+	"schema.travel.passport_expiry":         "Fecha de vencimiento del pasaporte (AAAA-MM-DD)",
+	"schema.travel.visa_country":            "País al que corresponde un visado",
+	"schema.travel.visa_number":             "Número de visado",
+	"schema.travel.visa_expiry":             "Fecha de vencimiento del visado (AAAA-MM-DD)",
+	"schema.travel.known_traveler_number":   "Número de viajero conocido (Global Entry, TSA PreCheck, NEXUS)",
+	"schema.travel.trusted_traveler_expiry": "Fecha de vencimiento de la membresía de viajero de confianza (AAAA-MM-DD)",
+	"schema.travel.redress_number":          "Número de reparación (redress) del DHS",
+
+	"schema.medical":   "Información médica",
+	"schema.documents": "Referencias a documentos (campos definidos por el usuario)",
+
+	"schema.medical.blood_type":          "Grupo sanguíneo (p. ej. O+)",
+	"schema.medical.allergies":           "Alergias, incluidas las a medicamentos",
+	"schema.medical.medications":         "Medicamentos actuales y dosis",
+	"schema.medical.physician_name":      "Nombre del médico de cabecera",
+	"schema.medical.physician_phone":     "Teléfono del médico de cabecera",
+	"schema.medical.insurance_member_id": "Número de afiliado del seguro de salud",
+}
@@ -0,0 +1,428 @@
+package i18n
+
+var fr = map[string]string{
+	"error": "Erreur",
+
+	"prompt.password":         "Mot de passe du profil : ",
+	"prompt.confirm_password": "Confirmer le mot de passe : ",
+	"prompt.confirm":          "Confirmer : ",
+	"prompt.elevate":          "Cette action demande de saisir à nouveau le mot de passe.",
+	"password.too_short":      "le mot de passe doit contenir au moins 8 caractères",
+	"password.mismatch":       "les mots de passe ne correspondent pas",
+	"prompt.pin":              "PIN de la clé secrète : ",
+	"prompt.new_pin":          "Choisissez un PIN pour la clé secrète : ",
+	"prompt.confirm_pin":      "Confirmez le PIN : ",
+	"prompt.secret_key":       "Clé secrète : ",
+	"pin.too_short":           "le PIN doit comporter au moins %d caractères",
+	"pin.mismatch":            "les PIN ne correspondent pas",
+
+	"init.done":             "Coffre initialisé avec succès.",
+	"init.secret_key":       "Votre clé secrète (conservez-la en lieu sûr) :",
+	"init.secret_saved":     "Clé secrète également enregistrée dans : %s",
+	"init.next":             "Ensuite : lancez 'pvault unlock' pour commencer à utiliser votre coffre.",
+	"init.secret_keychain":  "Clé secrète également enregistrée dans le trousseau du système.",
+	"init.secret_pin":       "Clé secrète également enregistrée dans %s, chiffrée avec votre PIN.",
+	"init.secret_keyfile":   "Clé secrète également enregistrée dans %s. Le déverrouillage exige que ce périphérique soit monté ; rangez-le à l'écart de cet ordinateur.",
+	"init.secret_manual":    "La clé secrète n'est enregistrée nulle part ; vous la saisirez à chaque déverrouillage.",
+	"init.secret_plaintext": "Elle n'y est pas chiffrée. Pour la protéger, lancez 'pvault migrate-secret-key keychain' (ou pin, ou manual).",
+
+	"secret_key.file":        "fichier non chiffré",
+	"secret_key.keychain":    "trousseau du système",
+	"secret_key.pin":         "fichier protégé par PIN",
+	"secret_key.keyfile":     "fichier de clé sur un support amovible",
+	"keyfile.need_path":      "--secret-key keyfile nécessite --keyfile <chemin>",
+	"keyfile.no_device":      "%s n'existe pas ; le périphérique est-il monté ?",
+	"secret_key.manual":      "saisie au déverrouillage (non enregistrée)",
+	"migrate.same":           "La clé secrète est déjà enregistrée ainsi : %s",
+	"migrate.confirm_manual": "La clé secrète ne sera plus enregistrée. Saisissez-la pour confirmer que vous en avez une copie.",
+	"migrate.mismatch":       "ce n'est pas la clé secrète ; rien n'a été modifié",
+	"migrate.done":           "Clé secrète désormais enregistrée ainsi : %s",
+
+	"onboard.title":      "Créez votre coffre",
+	"onboard.basics":     "Ajoutons quelques informations de base (Entrée pour passer) :",
+	"onboard.done_saved": "Terminé — %d champ(s) enregistré(s). Votre coffre est prêt.",
+	"onboard.done":       "Terminé — votre coffre est prêt.",
+	"onboard.next":       "Lancez 'pvault status' pour voir ce qui est enregistré.",
+
+	"onboard.field.identity.full_name":     "Nom complet",
+	"onboard.field.identity.email":         "E-mail",
+	"onboard.field.addresses.home_street":  "Adresse (rue)",
+	"onboard.field.addresses.home_city":    "Ville",
+	"onboard.field.addresses.home_state":   "État ou région",
+	"onboard.field.addresses.home_zip":     "Code postal",
+	"onboard.field.addresses.home_country": "Pays",
+	"onboard.field.preferences.timezone":   "Fuseau horaire",
+
+	"unlock.already":    "Le coffre est déjà déverrouillé (serveur en cours d'exécution).",
+	"unlock.unverified": "Serveur du coffre démarré (état non vérifié).",
+	"unlock.done":       "Coffre déverrouillé. Serveur en cours d'exécution sur %s",
+
+	"sessions.row":        "déverrouillée %s, se verrouille dans %s si inactive",
+	"sessions.row_pinned": "déverrouillée %s, ne se verrouille pas si inactive",
+	"sessions.revoked":    "Session %s révoquée",
+	"config.saved":        "%s enregistré dans %s",
+	"config.unknown":      "clé de configuration inconnue %q ; voir 'pvault config list'",
+	"config.env_override": "Remarque : %s est défini dans l'environnement et prime sur le fichier",
+
+	"lock.stopped": "Coffre verrouillé (serveur arrêté).",
+	"lock.done":    "Coffre verrouillé.",
+
+	"relock.done":   "Coffre verrouillé ; le serveur tourne toujours. 'pvault unlock' le déverrouille à nouveau.",
+	"relock.prompt": "Le coffre est verrouillé. Saisissez le mot de passe pour le déverrouiller à nouveau.",
+	"relock.hint":   "Le coffre s'est verrouillé ou cette session a pris fin. Lancez 'pvault unlock', ou ajoutez --prompt-on-locked pour que le mot de passe soit demandé.",
+
+	"status.not_running":     "Le coffre est verrouillé (serveur arrêté).",
+	"status.not_initialized": "Le coffre n'est pas initialisé. Lancez d'abord 'pvault init'.",
+	"status.locked":          "État :  verrouillé",
+	"status.unlocked":        "État :  déverrouillé",
+	"status.fields":          "Champs :  %d",
+	"status.categories":      "Catégories :",
+	"status.category_fields": "%d champs",
+	"status.secret_key":      "Clé :    %s",
+
+	"field.set":        "%s enregistré",
+	"field.deleted":    "%s supprimé",
+	"list.empty":       "Aucun champ trouvé.",
+	"field.normalized": "Enregistré sous la forme %s (original conservé dans l'historique)",
+	"history.empty":    "Aucun historique pour ce champ.",
+
+	"verify.ok":            "%d champ(s) vérifié(s) dans %d catégorie(s) ; aucun problème trouvé.",
+	"verify.failed":        "Des problèmes ont été trouvés.",
+	"verify.salt_mismatch": "Sel différent : les métadonnées du coffre ont changé depuis son déverrouillage. Lancez 'pvault lock' puis 'pvault unlock'.",
+	"verify.key_mismatch":  "%s : échec de la vérification de clé — cette catégorie a été écrite avec une autre clé ou ses métadonnées ont été modifiées",
+	"verify.key_unknown":   "%s : aucune valeur ne se déchiffre et aucune valeur de contrôle ne permet de savoir pourquoi",
+	"verify.key_added":     "%s : valeur de contrôle de clé enregistrée",
+	"verify.corrupted":     "%s : la valeur est corrompue ; enregistrez-la à nouveau ou restaurez-la depuis une sauvegarde",
+
+	"alias.set":           "Alias %s → %s",
+	"alias.deleted":       "Alias %s supprimé",
+	"alias.empty":         "Aucun alias.",
+	"canary.created":      "Leurre %s = %s",
+	"canary.revoke":       "Tout jeton de service qui le lit sera révoqué.",
+	"canary.hint":         "Les lectures par des jetons de service sont journalisées comme 'canary' et envoyées à notify.url ou notify.cmd.",
+	"canary.deleted":      "Leurre %s supprimé",
+	"canary.empty":        "Aucun leurre.",
+	"canary.alert_only":   "alerte",
+	"canary.alert_revoke": "alerte + révocation du jeton",
+
+	"acl.allow":      "%s autorisé sur %s (seuls les consommateurs autorisés peuvent désormais y accéder)",
+	"acl.deny":       "%s refusé sur %s",
+	"acl.removed":    "%s retiré des listes d'accès de %s",
+	"acl.empty":      "Aucune liste d'accès de champ.",
+	"acl.allow_list": "autorisés : %s",
+	"acl.deny_list":  "refusés : %s",
+
+	"consumer.trust":   "%s est désormais %s (accède aux champs jusqu'à %s)",
+	"consumer.reset":   "%s est de nouveau de confiance",
+	"consumer.empty":   "Aucun consommateur.",
+	"consumer.max":     "jusqu'à %s",
+	"consumer.default": "(par défaut)",
+
+	"offline.usage":          "--offline fonctionne avec get, set et list",
+	"offline.no_vault":       "aucun coffre dans %s — lancez d'abord 'pvault init'",
+	"offline.server_running": "le serveur du coffre sur %s est en marche et ne verrait pas une écriture hors ligne ; retirez --offline, ou lancez d'abord 'pvault lock'",
+
+	"template.set":     "les lectures de contexte de %s suivent désormais son modèle",
+	"template.deleted": "%s reçoit de nouveau tout ce que couvrent ses portées",
+	"template.empty":   "Aucun modèle de contexte.",
+	"template.mask":    "%s masqué : %s",
+
+	"pin.pinned":   "%s épinglé",
+	"pin.unpinned": "%s désépinglé",
+	"pin.empty":    "Aucun champ épinglé.",
+
+	"get.no_match":  "aucun champ ne correspond à %q",
+	"get.resolved":  "→ %s",
+	"get.choose":    "%q correspond à plusieurs champs :",
+	"get.choice":    "Lequel ? [1-%d]",
+	"get.ambiguous": "%q correspond à plusieurs champs : %s",
+	"get.no_choice": "aucun champ choisi",
+
+	"add.unknown":  "catégorie inconnue %q (au choix : %s)",
+	"add.complete": "Tous les champs recommandés de %s sont déjà enregistrés.",
+	"add.intro":    "%d champ(s) à remplir, %d déjà enregistré(s). Appuyez sur Entrée pour en passer un.",
+	"add.failed":   "impossible d'enregistrer %s : %v",
+	"add.done":     "Terminé — %d champ(s) enregistré(s).",
+
+	"onboard.unknown_profile": "profil inconnu %q (au choix : %s)",
+	"onboard.no_password":     "--from-file a besoin du mot de passe dans le fichier ou dans VAULT_PASSWORD",
+	"onboard.bad_file":        "lecture de %s : %v",
+
+	"import.csv_summary":   "%d nouveau(x), %d modifié(s), %d inchangé(s)",
+	"import.csv_prompt":    "Appliquer ces modifications ? [o/N]",
+	"import.csv_cancelled": "Rien n'a été écrit.",
+
+	"note.set":     "Note de %s enregistrée",
+	"note.cleared": "Note de %s effacée",
+	"note.none":    "%s n'a pas de note.",
+
+	"type.set":     "Type de valeur de %s défini sur %s",
+	"type.cleared": "%s est de nouveau du texte brut",
+	"get.masked":   "(masqué : les valeurs critiques et les blocs PEM sont cachés ; ajoutez --reveal pour les afficher)",
+
+	"entry.added":   "Entrée ajoutée à %s",
+	"entry.removed": "%s retirée de %s",
+
+	"link.linked":   "%s lié à %s",
+	"link.unlinked": "Lien entre %s et %s supprimé",
+	"link.empty":    "Aucun lien.",
+
+	"schema.packs_title":   "Packs de schéma (pvault schema enable <pack> en ajoute un au schéma recommandé) :",
+	"schema.pack_enabled":  "activé",
+	"schema.pack_unknown":  "pack de schéma inconnu %q (disponibles : %s)",
+	"schema.pack_restart":  "Redémarrez le serveur en cours (pvault lock, puis pvault unlock) pour appliquer le changement.",
+	"schema.packs_invalid": "Attention : schema.packs : %v ; aucun pack de schéma activé",
+	"schema.pack.vehicles": "Véhicules et polices d'assurance",
+	"schema.pack.travel":   "Passeport, visa et programmes voyageurs de confiance, avec alertes d'expiration",
+	"schema.pack.medical":  "Données médicales que les jetons de service doivent justifier pour les lire",
+
+	"expiring.none":    "Rien n'expire dans les %d prochains jours.",
+	"expiring.in":      "dans %d jours",
+	"expiring.today":   "aujourd'hui",
+	"expiring.expired": "expiré depuis %d jours",
+
+	"redact.summary": "%d valeur(s) de %d champ(s) masquée(s).",
+	"redact.none":    "Rien à masquer.",
+
+	"appendonly.set":     "Les champs %s existants ne changent plus qu'avec une session ; les jetons peuvent encore en ajouter.",
+	"appendonly.removed": "%s est de nouveau modifiable.",
+	"appendonly.empty":   "Aucune catégorie en ajout seul.",
+
+	"demo.done": "Coffre de démonstration créé avec %d champs fictifs (graine %d) dans %s",
+	"demo.next": "Essayez : VAULT_DIR=%s VAULT_PORT=%s pvault unlock (mot de passe : %s)",
+
+	"bench.start":      "Mesures sur %s/%s avec %d processeurs, chacune sur un coffre jetable (moins d'une minute)",
+	"bench.row":        "%s %-22s %12s par opération (budget %s)",
+	"bench.none":       "Aucune mesure ne correspond à %q",
+	"bench.summary":    "%d mesure(s) au-delà du budget.",
+	"bench.summary_ok": "Toutes les mesures tiennent dans le budget.",
+
+	"compact.done":    "Base du coffre compactée : %s → %s (%s récupérés).",
+	"compact.nothing": "Rien à compacter : une base chiffrée est réécrite entièrement à chaque écriture.",
+
+	"config.export_settings_only": "Le serveur ne tourne pas, seuls les réglages ont été exportés ; démarrez-le et déverrouillez le coffre pour inclure consommateurs, listes d'accès, modèles et jetons",
+	"config.exported":             "%d réglages, %d consommateurs et %d jetons exportés vers %s",
+	"config.import_needs_server":  "Le fichier contient les consommateurs, listes d'accès et jetons du coffre ; démarrez d'abord le serveur et déverrouillez le coffre",
+	"config.imported_settings":    "%d réglages importés dans %s",
+	"config.imported_wiring":      "Importés : %d consommateurs, %d listes d'accès, %d modèles, %d alias et %d catégories en ajout seul",
+	"config.reissued":             "Nouveau jeton pour %s, remplace %s... : %s",
+	"config.tokens_skipped":       "%d jetons non réémis : expirés, ou --reissue-tokens absent",
+
+	"approve.ready":        "Les demandes d'approbation s'affichent ici pour les lectures de niveau %s ou plus et pour la première lecture de chaque consommateur.",
+	"approve.new_consumer": "%s n'a encore jamais lu dans ce coffre.",
+	"approve.request":      "%s (%s) demande %s, jusqu'au niveau %s",
+	"approve.purpose":      "Motif : %s",
+	"approve.more":         "et %d de plus",
+	"approve.prompt":       "Autoriser ? [o/N]",
+	"approve.allowed":      "Autorisé.",
+	"approve.denied":       "Refusé.",
+	"approve.timed_out":    "Pas de réponse à temps ; la lecture a été refusée.",
+
+	"set.prompt":       "Valeur de %s (masquée) : ",
+	"set.prompt_again": "Encore une fois : ",
+	"set.mismatch":     "Les deux saisies diffèrent ; rien n'a été enregistré.",
+	"set.empty":        "Aucune valeur saisie ; rien n'a été enregistré.",
+	"set.history_hint": "Astuce : 'pvault set %s --prompt' garde la valeur hors de l'historique du shell.",
+	"set.normalized":   "Enregistré sous une forme normalisée (original conservé dans l'historique)",
+
+	"status.token_expiring":      "Attention : le jeton de service de %s (%s) expire dans %d jours",
+	"status.token_expiring_soon": "Attention : le jeton de service de %s (%s) expire dans moins d'un jour",
+
+	"enrich.title":  "Le service d'adresses suggère :",
+	"enrich.change": "%s : %s → %s. Accepter ? [o/N]",
+	"enrich.add":    "%s : %s. Accepter ? [o/N]",
+	"enrich.failed": "Impossible de vérifier l'adresse (HTTP %d).",
+
+	"import.summary":     "%d nouveau(x), %d inchangé(s), %d conflit(s)",
+	"import.conflict":    "%s : coffre v%d (%s) contre fichier v%d (%s)",
+	"import.vault_value": "coffre :  %s",
+	"import.file_value":  "fichier : %s",
+	"import.prompt":      "Utiliser la valeur du fichier ? [o/N]",
+	"import.keep":        "→ la valeur du coffre est conservée",
+	"import.take":        "→ la valeur du fichier est importée",
+	"import.dry_run":     "Simulation : rien n'a été écrit.",
+	"import.nothing":     "Rien à importer.",
+	"import.done":        "%d champ(s) importé(s).",
+
+	"import.deletions":     "%d champ(s) supprimé(s) dans le fichier",
+	"import.deletion":      "%s : supprimé dans le fichier (%s) contre coffre v%d (%s)",
+	"import.delete_prompt": "Le supprimer du coffre ? [o/N]",
+	"import.delete":        "→ suppression du coffre",
+	"import.deleted":       "%d champ(s) supprimé(s).",
+
+	"merge.same":      "C'est ce coffre-ci ; indiquez le chemin de l'autre copie.",
+	"merge.conflict":  "%s : ce coffre v%d (%s) contre l'autre copie v%d (%s)",
+	"merge.deletions": "%d champ(s) supprimé(s) dans l'autre copie",
+	"merge.deletion":  "%s : supprimé dans l'autre copie (%s) contre ce coffre v%d (%s)",
+	"merge.take":      "→ la valeur de l'autre copie est reprise",
+	"merge.nothing":   "Déjà synchronisé.",
+	"merge.done":      "%d modification(s) fusionnée(s), transaction %s.",
+
+	"status.replica":       "Réplique : de %s, synchronisée %s",
+	"status.replica_never": "Réplique : de %s, pas encore synchronisée",
+	"status.replica_error": "  dernière récupération échouée : %s",
+
+	"escrow.none":       "Aucun champ à confier : le coffre n'a aucun champ critique (ou aucun ne correspond à --fields).",
+	"escrow.done":       "%d champ(s) confié(s) à %d destinataire(s). Seules leurs clés privées peuvent l'ouvrir.",
+	"bootstrap.written": "%s écrit (mode 0600). Il contient des secrets : gardez-le hors du contrôle de version.",
+	"k8s.add":           "+ %s/%s %s  ← %s",
+	"k8s.drift":         "~ %s/%s %s  ← %s (dérive : diffère du coffre)",
+	"k8s.same":          "= %s/%s %s  (synchronisé)",
+	"k8s.dry_run":       "Simulation : aucun Secret n'a été modifié.",
+	"k8s.done":          "%d Secret(s) créé(s), %d mis à jour.",
+	"k8s.not_local":     "Le contexte %q (%s) n'est pas un cluster local. pvault k8s n'écrit que dans kind, minikube, k3d, Docker Desktop et les clusters sur localhost ; choisissez-en un avec --context.",
+
+	"kms.enrolled":      "Clé du coffre enveloppée avec la clé %s %s.",
+	"kms.headless":      "'pvault serve --headless' déverrouille désormais via le KMS quand aucun mot de passe n'est fourni.",
+	"kms.unwrap_failed": "Le KMS a chiffré la clé mais ne peut pas la déchiffrer (%v) ; le serveur ne pourrait pas déverrouiller. Vérifiez l'autorisation de déchiffrement de la clé.",
+	"kms.none":          "Aucun KMS enregistré. Enregistrez-en un avec 'pvault kms enroll'.",
+	"kms.status":        "Clé du coffre enveloppée avec la clé %s %s (depuis le %s)",
+	"kms.removed":       "Enregistrement KMS supprimé. La clé du KMS elle-même est inchangée.",
+
+	"plugins.commands":    "Commandes de plugins :",
+	"plugins.no_commands": "Aucune commande de plugin (programmes pvault-<nom> dans le PATH).",
+	"plugins.hooks":       "Plugins de hooks (plugins.hooks) :",
+	"plugins.no_hooks":    "Aucun plugin de hook configuré (plugins.hooks).",
+	"plugins.broken":      "ne fonctionne pas : %v",
+
+	"emergency.setup":     "%d champ(s) scellé(s) pour %s, libérés après %s sauf refus de votre part. Son code d'accès :",
+	"emergency.code_hint": "Transmettez ce code au contact en privé. Il ne sera plus affiché.",
+	"emergency.empty":     "Aucun contact d'urgence.",
+	"emergency.pending":   "demandé ; libéré le %s sauf refus",
+	"emergency.denied":    "Demande d'accès d'urgence de %s refusée",
+	"emergency.removed":   "Contact d'urgence %s supprimé",
+	"emergency.requested": "Demande envoyée. Le propriétaire peut la refuser jusqu'au %s ; ensuite lancez 'pvault emergency release'.",
+	"emergency.released":  "Paquet libéré. Déchiffrez-le avec votre clé privée, p. ex. 'age -d -i key.txt'.",
+
+	"doctor.private":            "%s est privé",
+	"doctor.mode":               "%s est lisible par d'autres utilisateurs (mode %04o)",
+	"doctor.no_dir":             "Aucun coffre dans %s",
+	"doctor.secret_plaintext":   "La clé secrète est stockée en clair dans %s",
+	"doctor.secret_stored":      "Stockage de la clé secrète : %s",
+	"doctor.secret_unreadable":  "Impossible de lire la clé secrète (%s) : %v",
+	"doctor.no_db":              "Aucune base de données dans %s",
+	"doctor.db_ok":              "Vérification d'intégrité de la base de données réussie",
+	"doctor.db_corrupt":         "Échec de la vérification d'intégrité de la base de données : %s",
+	"doctor.db_unreadable":      "Impossible de lire la base de données : %v",
+	"doctor.enc_ok":             "Le fichier de base de données chiffré est lisible (lancez 'pvault verify' pour vérifier son contenu)",
+	"doctor.server_ok":          "Le serveur du coffre tourne sur %s",
+	"doctor.server_down":        "Aucun serveur sur %s ; 'pvault unlock' en démarre un",
+	"doctor.port_conflict":      "Un autre programme écoute sur %s",
+	"doctor.stale_pid":          "Le fichier PID désigne le processus %d, qui ne tourne pas",
+	"doctor.pid_not_serving":    "Le processus %d du fichier PID tourne mais ne sert pas le coffre",
+	"doctor.stale_session":      "Le jeton de l'agent de session est périmé",
+	"doctor.no_session":         "Aucun agent de session ; vérifications des jetons ignorées",
+	"doctor.session_file":       "Un ancien fichier de session contient un jeton de session en clair",
+	"doctor.locked":             "Le coffre est verrouillé ; vérifications des jetons ignorées",
+	"doctor.clock_skew":         "L'horloge du serveur diffère de celle de cette machine de %s",
+	"doctor.token_future":       "Le jeton de service de %s a été créé dans le futur (%s)",
+	"doctor.token_expired":      "Le jeton de service de %s est encore accepté après son expiration (%s)",
+	"doctor.token_expiring":     "Le jeton de service de %s expire le %s",
+	"doctor.clock_ok":           "L'horloge et les dates des jetons sont cohérentes",
+	"doctor.summary":            "%d problème(s) trouvé(s).",
+	"doctor.summary_ok":         "Aucun problème trouvé.",
+	"doctor.fix.init":           "Lancez 'pvault init', ou définissez VAULT_DIR vers votre coffre",
+	"doctor.fix.chmod":          "Lancez : chmod %s %s",
+	"doctor.fix.migrate_secret": "Lancez 'pvault migrate-secret-key keychain' (ou pin, ou manual)",
+	"doctor.fix.secret_lost":    "Supprimez %s et saisissez la clé secrète de votre sauvegarde au déverrouillage ; 'pvault migrate-secret-key' peut l'enregistrer à nouveau",
+	"doctor.keyfile_missing":    "Le fichier de clé %s n'est pas disponible",
+	"doctor.fix.keyfile_mount":  "Montez le périphérique qui le contient avant de déverrouiller",
+	"doctor.fix.restore":        "Arrêtez le serveur et restaurez la base de données depuis une sauvegarde",
+	"doctor.fix.port":           "Arrêtez ce programme, ou déplacez le coffre avec 'pvault config set server.port <port>'",
+	"doctor.fix.rm":             "Lancez : rm %s",
+	"doctor.fix.lock":           "Lancez 'pvault lock', puis 'pvault unlock'",
+	"doctor.fix.unlock":         "Lancez 'pvault unlock'",
+	"doctor.fix.clock":          "Synchronisez l'horloge système (activez NTP), puis révoquez et recréez les jetons de service concernés",
+	"doctor.fix.token":          "Créez-en un nouveau avec 'pvault create-service-token'",
+
+	"service.installed":     "Service pvault installé pour %s. Démarrez-le avec 'pvault service start', puis lancez 'pvault unlock'.",
+	"service.removed":       "Service pvault supprimé.",
+	"service.started":       "Service démarré (verrouillé). Lancez 'pvault unlock' pour le déverrouiller.",
+	"service.stopped":       "Service arrêté.",
+	"service.status":        "État du service : %s.",
+	"service.not_installed": "Le service pvault n'est pas installé. Lancez 'pvault service install' en tant qu'administrateur.",
+	"service.unsupported":   "pvault service n'existe que sous Windows ; utilisez 'pvault unlock' pour lancer le serveur en arrière-plan.",
+
+	"schema.title":        "Schéma de coffre recommandé",
+	"schema.user_defined": "(champs définis par l'utilisateur)",
+
+	"schema.identity":               "Informations d'identité personnelle",
+	"schema.identity.first_name":    "Prénom",
+	"schema.identity.last_name":     "Nom de famille",
+	"schema.identity.full_name":     "Nom complet affiché",
+	"schema.identity.email":         "Adresse e-mail principale",
+	"schema.identity.phone":         "Numéro de téléphone",
+	"schema.identity.date_of_birth": "Date de naissance",
+
+	"schema.addresses":              "Adresses postales",
+	"schema.addresses.home_street":  "Adresse du domicile (rue)",
+	"schema.addresses.home_city":    "Ville du domicile",
+	"schema.addresses.home_state":   "État ou région du domicile",
+	"schema.addresses.home_zip":     "Code postal du domicile",
+	"schema.addresses.home_country": "Code pays du domicile (ex. FR)",
+
+	"schema.financial":               "Informations financières et fiscales",
+	"schema.financial.filing_status": "Situation fiscale déclarée",
+	"schema.financial.ssn":           "Numéro de sécurité sociale américain (SSN)",
+
+	"schema.payment":                 "Données de carte de paiement",
+	"schema.payment.card_number":     "Numéro de carte",
+	"schema.payment.card_expiry":     "Date d'expiration de la carte",
+	"schema.payment.cardholder_name": "Nom du titulaire de la carte",
+	"schema.payment.card_brand":      "Réseau de la carte (ex. Visa, Mastercard)",
+
+	"schema.preferences":          "Préférences de l'utilisateur",
+	"schema.preferences.timezone": "Fuseau horaire préféré (ex. Europe/Paris)",
+	"schema.preferences.language": "Langue préférée (ex. fr)",
+
+	"schema.employment":          "Informations professionnelles",
+	"schema.employment.employer": "Nom de l'employeur actuel",
+	"schema.employment.title":    "Intitulé du poste",
+
+	"schema.contacts":                        "Proches, un groupe de champs chacun (ex. contacts.child_1.full_name)",
+	"schema.contacts.spouse.full_name":       "Nom complet du conjoint ou partenaire",
+	"schema.contacts.spouse.phone":           "Téléphone du conjoint ou partenaire",
+	"schema.contacts.spouse.email":           "E-mail du conjoint ou partenaire",
+	"schema.contacts.spouse.date_of_birth":   "Date de naissance du conjoint ou partenaire",
+	"schema.contacts.emergency.full_name":    "Nom complet du contact d'urgence",
+	"schema.contacts.emergency.phone":        "Téléphone du contact d'urgence",
+	"schema.contacts.emergency.relationship": "Lien du contact d'urgence avec vous",
+
+	"schema.vehicles":                     "Détails et immatriculation du véhicule",
+	"schema.vehicles.make":                "Marque du véhicule",
+	"schema.vehicles.model":               "Modèle du véhicule",
+	"schema.vehicles.year":                "Année du modèle",
+	"schema.vehicles.vin":                 "Numéro d'identification du véhicule (VIN)",
+	"schema.vehicles.plate":               "Numéro de plaque d'immatriculation",
+	"schema.vehicles.registration_expiry": "Date d'expiration de l'immatriculation",
+
+	"schema.insurance":                      "Assureurs et numéros de police",
+	"schema.insurance.auto_provider":        "Assureur automobile",
+	"schema.insurance.auto_policy_number":   "Numéro de police d'assurance automobile",
+	"schema.insurance.health_provider":      "Assureur santé",
+	"schema.insurance.health_policy_number": "Numéro de police ou de contrat collectif d'assurance santé",
+	"schema.insurance.health_member_id":     "Numéro d'adhérent à l'assurance santé",
+	"schema.insurance.home_provider":        "Assureur habitation",
+	"schema.insurance.home_policy_number":   "Numéro de police d'assurance habitation",
+
+	"schema.travel":                         "Documents de voyage",
+	"schema.travel.passport_number":         "Numéro de passeport",
+	"schema.travel.passport_country":        "Code du pays émetteur du passeport (ex. US)",
+	"schema.travel.passport_expiry":         "Date d'expiration du passeport (AAAA-MM-JJ)",
+	"schema.travel.visa_country":            "Pays concerné par un visa",
+	"schema.travel.visa_number":             "Numéro de visa",
+	"schema.travel.visa_expiry":             "Date d'expiration du visa (AAAA-MM-JJ)",
+	"schema.travel.known_traveler_number":   "Numéro de voyageur connu (Global Entry, TSA PreCheck, NEXUS)",
+	"schema.travel.trusted_traveler_expiry": "Date d'expiration de l'adhésion voyageur de confiance (AAAA-MM-JJ)",
+	"schema.travel.redress_number":          "Numéro de recours DHS (redress)",
+
+	"schema.medical":   "Informations médicales",
+	"schema.documents": "Références de documents (champs définis par l'utilisateur)",
+
+	"schema.medical.blood_type":          "Groupe sanguin (ex. O+)",
+	"schema.medical.allergies":           "Allergies, y compris aux médicaments",
+	"schema.medical.medications":         "Traitements en cours et posologies",
+	"schema.medical.physician_name":      "Nom du médecin traitant",
+	"schema.medical.physician_phone":     "Téléphone du médecin traitant",
+	"schema.medical.insurance_member_id": "Numéro d'adhérent à l'assurance santé",
+}
@@ -0,0 +1,60 @@
+// Package i18n holds translations for CLI messages and schema descriptions.
+//
+// Messages are looked up by key. A key missing from a language falls back to
+// English, so a partial catalog never produces an empty string.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Default is the language used when nothing else matches.
+const Default = "en"
+
+// Supported lists the languages with a catalog, Default first.
+var Supported = []string{"en", "es", "de", "fr", "zh"}
+
+var catalogs = map[string]map[string]string{
+	"en": en,
+	"es": es,
+	"de": de,
+	"fr": fr,
+	"zh": zh,
+}
+
+// Match maps a language tag or POSIX locale — "fr", "de-AT", "zh_CN.UTF-8",
+// "es_MX@euro" — to a supported language, or "" if none matches.
+func Match(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_.@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if _, ok := catalogs[tag]; ok {
+		return tag
+	}
+	return ""
+}
+
+// Has reports whether lang has its own translation for key.
+func Has(lang, key string) bool {
+	_, ok := catalogs[lang][key]
+	return ok
+}
+
+// T returns the message for key in lang, falling back to English and then
+// to the key itself.
+func T(lang, key string) string {
+	if s, ok := catalogs[lang][key]; ok {
+		return s
+	}
+	if s, ok := en[key]; ok {
+		return s
+	}
+	return key
+}
+
+// Tf formats the message for key in lang with args.
+func Tf(lang, key string, args ...any) string {
+	return fmt.Sprintf(T(lang, key), args...)
+}
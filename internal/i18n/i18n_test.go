@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"en", "en"},
+		{"de", "de"},
+		{"de-AT", "de"},
+		{"zh_CN.UTF-8", "zh"},
+		{"es_MX@euro", "es"},
+		{" FR ", "fr"},
+		{"C", ""},
+		{"pt-BR", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.tag); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestT_Fallback(t *testing.T) {
+	if got := T("de", "lock.done"); got != "Tresor gesperrt." {
+		t.Fatalf("expected German, got %q", got)
+	}
+	if got := T("xx", "lock.done"); got != "Vault locked." {
+		t.Fatalf("expected English fallback, got %q", got)
+	}
+	if got := T("de", "no.such.key"); got != "no.such.key" {
+		t.Fatalf("expected key fallback, got %q", got)
+	}
+	if got := Tf("es", "field.set", "identity.email"); got != "Guardado identity.email" {
+		t.Fatalf("unexpected formatted message %q", got)
+	}
+}
+
+var verbRe = regexp.MustCompile(`%[a-z]`)
+
+// Every translated CLI message must exist in English and take the same
+// format arguments, or Tf would print garbage.
+func TestCatalogs_MatchEnglish(t *testing.T) {
+	for _, lang := range Supported {
+		for key, s := range catalogs[lang] {
+			if strings.HasPrefix(key, "schema.") && key != "schema.title" && key != "schema.user_defined" {
+				continue
+			}
+			ref, ok := en[key]
+			if !ok {
+				t.Errorf("%s: key %q has no English message", lang, key)
+				continue
+			}
+			if got, want := strings.Join(verbRe.FindAllString(s, -1), ""), strings.Join(verbRe.FindAllString(ref, -1), ""); got != want {
+				t.Errorf("%s: %q uses verbs %q, English uses %q", lang, key, got, want)
+			}
+		}
+		for key := range en {
+			if _, ok := catalogs[lang][key]; !ok {
+				t.Errorf("%s: missing message %q", lang, key)
+			}
+		}
+	}
+}
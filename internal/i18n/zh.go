@@ -0,0 +1,428 @@
+package i18n
+
+var zh = map[string]string{
+	"error": "错误",
+
+	"prompt.password":         "个人资料密码：",
+	"prompt.confirm_password": "确认密码：",
+	"prompt.confirm":          "确认：",
+	"prompt.elevate":          "此操作需要再次输入密码。",
+	"password.too_short":      "密码长度至少为 8 个字符",
+	"password.mismatch":       "两次输入的密码不一致",
+	"prompt.pin":              "密钥 PIN：",
+	"prompt.new_pin":          "为密钥设置 PIN：",
+	"prompt.confirm_pin":      "确认 PIN：",
+	"prompt.secret_key":       "密钥：",
+	"pin.too_short":           "PIN 至少需要 %d 个字符",
+	"pin.mismatch":            "两次输入的 PIN 不一致",
+
+	"init.done":             "保险库初始化成功。",
+	"init.secret_key":       "你的密钥（请妥善保存）：",
+	"init.secret_saved":     "密钥已另存至：%s",
+	"init.next":             "下一步：运行 'pvault unlock' 开始使用保险库。",
+	"init.secret_keychain":  "密钥也已保存到系统钥匙串。",
+	"init.secret_pin":       "密钥也已保存到 %s，并用你的 PIN 加密。",
+	"init.secret_keyfile":   "密钥也已保存到 %s。解锁时必须挂载该设备；请将它与这台电脑分开存放。",
+	"init.secret_manual":    "密钥不会保存在任何地方；每次解锁时都需要输入。",
+	"init.secret_plaintext": "该文件未加密。运行 'pvault migrate-secret-key keychain'（或 pin、manual）来保护它。",
+
+	"secret_key.file":        "未加密文件",
+	"secret_key.keychain":    "系统钥匙串",
+	"secret_key.pin":         "PIN 保护的文件",
+	"secret_key.keyfile":     "可移动介质上的密钥文件",
+	"keyfile.need_path":      "--secret-key keyfile 需要 --keyfile <路径>",
+	"keyfile.no_device":      "%s 不存在；设备是否已挂载？",
+	"secret_key.manual":      "解锁时输入（不保存）",
+	"migrate.same":           "密钥已按此方式保存：%s",
+	"migrate.confirm_manual": "密钥将不再保存。请输入密钥以确认你有副本。",
+	"migrate.mismatch":       "这不是密钥；未做任何更改",
+	"migrate.done":           "密钥现在保存为：%s",
+
+	"onboard.title":      "创建你的保险库",
+	"onboard.basics":     "先填写一些基本信息（按回车可跳过）：",
+	"onboard.done_saved": "完成 — 已保存 %d 个字段。保险库已就绪。",
+	"onboard.done":       "完成 — 保险库已就绪。",
+	"onboard.next":       "运行 'pvault status' 查看已保存的内容。",
+
+	"onboard.field.identity.full_name":     "姓名",
+	"onboard.field.identity.email":         "电子邮箱",
+	"onboard.field.addresses.home_street":  "街道地址",
+	"onboard.field.addresses.home_city":    "城市",
+	"onboard.field.addresses.home_state":   "省/州",
+	"onboard.field.addresses.home_zip":     "邮政编码",
+	"onboard.field.addresses.home_country": "国家",
+	"onboard.field.preferences.timezone":   "时区",
+
+	"unlock.already":    "保险库已解锁（服务器正在运行）。",
+	"unlock.unverified": "保险库服务器已启动（无法确认状态）。",
+	"unlock.done":       "保险库已解锁。服务器运行于 %s",
+
+	"sessions.row":        "解锁于 %s，空闲 %s 后锁定",
+	"sessions.row_pinned": "解锁于 %s，空闲时不锁定",
+	"sessions.revoked":    "已撤销会话 %s",
+	"config.saved":        "已将 %s 保存到 %s",
+	"config.unknown":      "未知的配置项 %q；请参阅 'pvault config list'",
+	"config.env_override": "注意：环境变量 %s 已设置，优先于配置文件",
+
+	"lock.stopped": "保险库已锁定（服务器已停止）。",
+	"lock.done":    "保险库已锁定。",
+
+	"relock.done":   "保险库已锁定；服务器仍在运行。'pvault unlock' 可再次解锁。",
+	"relock.prompt": "保险库已锁定。输入密码以再次解锁。",
+	"relock.hint":   "保险库已锁定或此会话已结束。运行 'pvault unlock'，或加上 --prompt-on-locked 以在需要时输入密码。",
+
+	"status.not_running":     "保险库已锁定（服务器未运行）。",
+	"status.not_initialized": "保险库尚未初始化。请先运行 'pvault init'。",
+	"status.locked":          "状态：  已锁定",
+	"status.unlocked":        "状态：  已解锁",
+	"status.fields":          "字段：  %d",
+	"status.categories":      "类别：",
+	"status.category_fields": "%d 个字段",
+	"status.secret_key":      "密钥：  %s",
+
+	"field.set":        "已设置 %s",
+	"field.deleted":    "已删除 %s",
+	"list.empty":       "未找到任何字段。",
+	"field.normalized": "已存储为 %s（原始值保存在历史记录中）",
+	"history.empty":    "此字段没有历史记录。",
+
+	"verify.ok":            "已检查 %d 个字段（%d 个类别），未发现问题。",
+	"verify.failed":        "发现问题。",
+	"verify.salt_mismatch": "盐值不匹配：保险库元数据在解锁后已被更改。请运行 'pvault lock' 和 'pvault unlock'。",
+	"verify.key_mismatch":  "%s：密钥校验失败 — 此类别是用其他密钥写入的，或其元数据已被修改",
+	"verify.key_unknown":   "%s：没有任何值可以解密，也没有密钥校验值可判断原因",
+	"verify.key_added":     "%s：已记录密钥校验值",
+	"verify.corrupted":     "%s：值已损坏；请重新设置或从备份恢复",
+
+	"alias.set":           "别名 %s → %s",
+	"alias.deleted":       "已删除别名 %s",
+	"alias.empty":         "没有别名。",
+	"canary.created":      "诱饵字段 %s = %s",
+	"canary.revoke":       "任何读取它的服务令牌都会被吊销。",
+	"canary.hint":         "服务令牌的读取会记录为 'canary'，并发送到 notify.url 或 notify.cmd。",
+	"canary.deleted":      "已删除诱饵字段 %s",
+	"canary.empty":        "没有诱饵字段。",
+	"canary.alert_only":   "告警",
+	"canary.alert_revoke": "告警并吊销令牌",
+
+	"acl.allow":      "已允许 %s 访问 %s（现在只有被允许的使用方可以访问）",
+	"acl.deny":       "已拒绝 %s 访问 %s",
+	"acl.removed":    "已将 %s 从 %s 的访问列表中移除",
+	"acl.empty":      "没有字段访问列表。",
+	"acl.allow_list": "允许：%s",
+	"acl.deny_list":  "拒绝：%s",
+
+	"consumer.trust":   "%s 现在为 %s（可访问至 %s 级别的字段）",
+	"consumer.reset":   "%s 已恢复为受信任",
+	"consumer.empty":   "没有使用方。",
+	"consumer.max":     "最高 %s",
+	"consumer.default": "（默认）",
+
+	"offline.usage":          "--offline 仅适用于 get、set 和 list",
+	"offline.no_vault":       "%s 中没有保险库 — 请先运行 'pvault init'",
+	"offline.server_running": "%s 上的保险库服务器正在运行，看不到离线写入；请去掉 --offline，或先运行 'pvault lock'",
+
+	"template.set":     "%s 的上下文读取现在遵循其模板",
+	"template.deleted": "%s 重新获得其范围涵盖的全部内容",
+	"template.empty":   "没有上下文模板。",
+	"template.mask":    "%s 已遮蔽：%s",
+
+	"pin.pinned":   "已置顶 %s",
+	"pin.unpinned": "已取消置顶 %s",
+	"pin.empty":    "没有置顶字段。",
+
+	"get.no_match":  "没有与 %q 匹配的字段",
+	"get.resolved":  "→ %s",
+	"get.choose":    "%q 匹配多个字段：",
+	"get.choice":    "选择哪一个？[1-%d]",
+	"get.ambiguous": "%q 匹配多个字段：%s",
+	"get.no_choice": "未选择字段",
+
+	"add.unknown":  "未知类别 %q（可选：%s）",
+	"add.complete": "%s 的所有推荐字段均已存储。",
+	"add.intro":    "需填写 %d 个字段，已存储 %d 个。按回车跳过。",
+	"add.failed":   "无法保存 %s：%v",
+	"add.done":     "完成 — 已保存 %d 个字段。",
+
+	"onboard.unknown_profile": "未知配置 %q（可选：%s）",
+	"onboard.no_password":     "--from-file 需要在文件或 VAULT_PASSWORD 中提供密码",
+	"onboard.bad_file":        "读取 %s：%v",
+
+	"import.csv_summary":   "新增 %d 个，修改 %d 个，未变 %d 个",
+	"import.csv_prompt":    "应用这些更改？[y/N]",
+	"import.csv_cancelled": "未写入任何内容。",
+
+	"note.set":     "已保存 %s 的备注",
+	"note.cleared": "已清除 %s 的备注",
+	"note.none":    "%s 没有备注。",
+
+	"type.set":     "已将 %s 的值类型设为 %s",
+	"type.cleared": "%s 已恢复为纯文本",
+	"get.masked":   "（已遮盖：关键级别的值和 PEM 块会被隐藏；使用 --reveal 显示）",
+
+	"entry.added":   "已为 %s 添加条目",
+	"entry.removed": "已移除 %s（%s）",
+
+	"link.linked":   "已将 %s 关联到 %s",
+	"link.unlinked": "已取消 %s 与 %s 的关联",
+	"link.empty":    "没有关联。",
+
+	"schema.packs_title":   "模式包（pvault schema enable <包> 将其加入推荐模式）：",
+	"schema.pack_enabled":  "已启用",
+	"schema.pack_unknown":  "未知的模式包 %q（可用：%s）",
+	"schema.pack_restart":  "请重启正在运行的服务器（pvault lock，然后 pvault unlock）以应用更改。",
+	"schema.packs_invalid": "警告：schema.packs：%v；未启用任何模式包",
+	"schema.pack.vehicles": "车辆和保险单",
+	"schema.pack.travel":   "护照、签证和可信旅客计划，附到期提醒",
+	"schema.pack.medical":  "服务令牌须说明用途才能读取的医疗信息",
+
+	"expiring.none":    "未来 %d 天内没有到期的项目。",
+	"expiring.in":      "%d 天后",
+	"expiring.today":   "今天",
+	"expiring.expired": "已过期 %d 天",
+
+	"redact.summary": "已遮盖 %d 个值（来自 %d 个字段）。",
+	"redact.none":    "没有需要遮盖的内容。",
+
+	"appendonly.set":     "现有的 %s 字段只能通过会话更改；令牌仍可添加新字段。",
+	"appendonly.removed": "%s 已恢复可写。",
+	"appendonly.empty":   "没有仅追加的类别。",
+
+	"demo.done": "已创建演示保险库：%d 个虚构字段（种子 %d），位于 %s",
+	"demo.next": "试用：VAULT_DIR=%s VAULT_PORT=%s pvault unlock（密码：%s）",
+
+	"bench.start":      "正在 %s/%s（%d 个 CPU）上测量，每项使用一个临时保险库（不到一分钟）",
+	"bench.row":        "%s %-22s 每次操作 %12s（预算 %s）",
+	"bench.none":       "没有与 %q 匹配的基准测试",
+	"bench.summary":    "%d 项基准测试超出预算。",
+	"bench.summary_ok": "所有基准测试均在预算内。",
+
+	"compact.done":    "已压缩保险库数据库：%s → %s（回收 %s）。",
+	"compact.nothing": "无需压缩：加密数据库每次写入都会整体重写。",
+
+	"config.export_settings_only": "服务器未运行，因此只导出了设置；启动服务器并解锁保险库后可包含使用方、访问列表、模板和令牌",
+	"config.exported":             "已将 %d 项设置、%d 个使用方和 %d 个令牌导出到 %s",
+	"config.import_needs_server":  "该文件包含保险库的使用方、访问列表和令牌；请先启动服务器并解锁保险库",
+	"config.imported_settings":    "已将 %d 项设置导入 %s",
+	"config.imported_wiring":      "已导入 %d 个使用方、%d 个访问列表、%d 个模板、%d 个别名和 %d 个仅追加类别",
+	"config.reissued":             "%s 的新令牌，替换 %s...：%s",
+	"config.tokens_skipped":       "%d 个令牌未重新签发：已过期，或未指定 --reissue-tokens",
+
+	"approve.ready":        "读取 %s 及以上级别字段以及每个使用方首次读取时，会在此处请求批准。",
+	"approve.new_consumer": "%s 此前从未读取过此保险库。",
+	"approve.request":      "%s（%s）请求读取 %s，最高级别 %s",
+	"approve.purpose":      "用途：%s",
+	"approve.more":         "及另外 %d 个",
+	"approve.prompt":       "允许？[y/N]",
+	"approve.allowed":      "已允许。",
+	"approve.denied":       "已拒绝。",
+	"approve.timed_out":    "未及时回答，已拒绝读取。",
+
+	"set.prompt":       "%s 的值（不回显）：",
+	"set.prompt_again": "再输入一次：",
+	"set.mismatch":     "两次输入不一致，未保存任何内容。",
+	"set.empty":        "未提供值，未保存任何内容。",
+	"set.history_hint": "提示：'pvault set %s --prompt' 可避免值出现在 shell 历史记录中。",
+	"set.normalized":   "已规范化存储（原始值保存在历史记录中）",
+
+	"status.token_expiring":      "警告：%s 的服务令牌（%s）将在 %d 天后过期",
+	"status.token_expiring_soon": "警告：%s 的服务令牌（%s）将在一天内过期",
+
+	"enrich.title":  "地址服务建议：",
+	"enrich.change": "%s：%s → %s。接受吗？[y/N]",
+	"enrich.add":    "%s：%s。接受吗？[y/N]",
+	"enrich.failed": "无法检查地址（HTTP %d）。",
+
+	"import.summary":     "%d 个新字段，%d 个未变，%d 个冲突",
+	"import.conflict":    "%s：保险库 v%d（%s）与文件 v%d（%s）",
+	"import.vault_value": "保险库：%s",
+	"import.file_value":  "文件：  %s",
+	"import.prompt":      "使用文件中的值吗？[y/N]",
+	"import.keep":        "→ 保留保险库中的值",
+	"import.take":        "→ 导入文件中的值",
+	"import.dry_run":     "试运行：未写入任何内容。",
+	"import.nothing":     "没有需要导入的内容。",
+	"import.done":        "已导入 %d 个字段。",
+
+	"import.deletions":     "文件中删除了 %d 个字段",
+	"import.deletion":      "%s：文件中已删除（%s）与保险库 v%d（%s）",
+	"import.delete_prompt": "也从保险库中删除吗？[y/N]",
+	"import.delete":        "→ 从保险库中删除",
+	"import.deleted":       "已删除 %d 个字段。",
+
+	"merge.same":      "这就是当前保险库；请指定另一份副本的路径。",
+	"merge.conflict":  "%s：当前保险库 v%d（%s）与另一份副本 v%d（%s）",
+	"merge.deletions": "另一份副本中删除了 %d 个字段",
+	"merge.deletion":  "%s：另一份副本中已删除（%s）与当前保险库 v%d（%s）",
+	"merge.take":      "→ 采用另一份副本中的值",
+	"merge.nothing":   "已经同步。",
+	"merge.done":      "已合并 %d 项更改，事务 %s。",
+
+	"status.replica":       "副本：来自 %s，同步于 %s",
+	"status.replica_never": "副本：来自 %s，尚未同步",
+	"status.replica_error": "  上次拉取失败：%s",
+
+	"escrow.none":       "没有可托管的字段：保险库中没有关键字段（或没有字段匹配 --fields）。",
+	"escrow.done":       "已托管 %d 个字段，共 %d 位接收者。只有他们的私钥可以打开。",
+	"bootstrap.written": "已写入 %s（权限 0600）。其中包含机密：不要提交到版本控制。",
+	"k8s.add":           "+ %s/%s %s  ← %s",
+	"k8s.drift":         "~ %s/%s %s  ← %s（已漂移：与保险库不一致）",
+	"k8s.same":          "= %s/%s %s  （已同步）",
+	"k8s.dry_run":       "试运行：未更改任何 Secret。",
+	"k8s.done":          "已创建 %d 个 Secret，已更新 %d 个。",
+	"k8s.not_local":     "上下文 %q（%s）不是本地集群。pvault k8s 只写入 kind、minikube、k3d、Docker Desktop 和 localhost 上的集群；请用 --context 选择一个。",
+
+	"kms.enrolled":      "保险库密钥已用 %s 密钥 %s 包装。",
+	"kms.headless":      "未提供密码时，'pvault serve --headless' 现在会通过 KMS 解锁。",
+	"kms.unwrap_failed": "KMS 加密了密钥却无法再解密（%v）；服务器将无法解锁。请检查该密钥的解密权限。",
+	"kms.none":          "尚未登记 KMS。请用 'pvault kms enroll' 登记。",
+	"kms.status":        "保险库密钥已用 %s 密钥 %s 包装（自 %s 起）",
+	"kms.removed":       "已移除 KMS 登记。KMS 密钥本身不受影响。",
+
+	"plugins.commands":    "插件命令：",
+	"plugins.no_commands": "没有插件命令（PATH 中的 pvault-<名称> 程序）。",
+	"plugins.hooks":       "钩子插件（plugins.hooks）：",
+	"plugins.no_hooks":    "未配置钩子插件（plugins.hooks）。",
+	"plugins.broken":      "无法工作：%v",
+
+	"emergency.setup":     "已密封 %d 个字段给 %s，除非你拒绝请求，将在 %s 后发放。对方的访问码：",
+	"emergency.code_hint": "请私下将此访问码交给联系人。它不会再次显示。",
+	"emergency.empty":     "没有紧急联系人。",
+	"emergency.pending":   "已请求；除非拒绝，将于 %s 发放",
+	"emergency.denied":    "已拒绝 %s 的紧急访问请求",
+	"emergency.removed":   "已删除紧急联系人 %s",
+	"emergency.requested": "请求已发送。所有者可在 %s 之前拒绝；之后运行 'pvault emergency release'。",
+	"emergency.released":  "数据包已发放。请用你的私钥解密，例如 'age -d -i key.txt'。",
+
+	"doctor.private":            "%s 是私有的",
+	"doctor.mode":               "%s 可被其他用户读取（权限 %04o）",
+	"doctor.no_dir":             "%s 中没有保险库",
+	"doctor.secret_plaintext":   "密钥以未加密形式保存在 %s",
+	"doctor.secret_stored":      "密钥存储方式：%s",
+	"doctor.secret_unreadable":  "无法读取密钥（%s）：%v",
+	"doctor.no_db":              "%s 中没有数据库",
+	"doctor.db_ok":              "数据库完整性检查通过",
+	"doctor.db_corrupt":         "数据库完整性检查失败：%s",
+	"doctor.db_unreadable":      "无法读取数据库：%v",
+	"doctor.enc_ok":             "加密数据库文件可读（运行 'pvault verify' 检查其内容）",
+	"doctor.server_ok":          "保险库服务器正在 %s 上运行",
+	"doctor.server_down":        "%s 上没有服务器；'pvault unlock' 会启动一个",
+	"doctor.port_conflict":      "另一个程序正在监听 %s",
+	"doctor.stale_pid":          "PID 文件指向的进程 %d 未在运行",
+	"doctor.pid_not_serving":    "PID 文件中的进程 %d 正在运行，但没有提供保险库服务",
+	"doctor.stale_session":      "会话代理的令牌已失效",
+	"doctor.no_session":         "没有会话代理；已跳过令牌检查",
+	"doctor.session_file":       "旧的会话文件以明文保存着会话令牌",
+	"doctor.locked":             "保险库已锁定；已跳过令牌检查",
+	"doctor.clock_skew":         "服务器时钟与本机相差 %s",
+	"doctor.token_future":       "%s 的服务令牌创建时间在未来（%s）",
+	"doctor.token_expired":      "%s 的服务令牌过期后仍被接受（%s）",
+	"doctor.token_expiring":     "%s 的服务令牌将于 %s 过期",
+	"doctor.clock_ok":           "时钟与令牌时间一致",
+	"doctor.summary":            "发现 %d 个问题。",
+	"doctor.summary_ok":         "未发现问题。",
+	"doctor.fix.init":           "运行 'pvault init'，或将 VAULT_DIR 设为你的保险库",
+	"doctor.fix.chmod":          "运行：chmod %s %s",
+	"doctor.fix.migrate_secret": "运行 'pvault migrate-secret-key keychain'（或 pin、manual）",
+	"doctor.fix.secret_lost":    "删除 %s，并在解锁时输入备份中的密钥；'pvault migrate-secret-key' 可以重新保存它",
+	"doctor.keyfile_missing":    "密钥文件 %s 不可用",
+	"doctor.fix.keyfile_mount":  "解锁前请挂载存放它的设备",
+	"doctor.fix.restore":        "停止服务器并从备份恢复数据库",
+	"doctor.fix.port":           "停止该程序，或用 'pvault config set server.port <端口>' 更换保险库端口",
+	"doctor.fix.rm":             "运行：rm %s",
+	"doctor.fix.lock":           "运行 'pvault lock'，然后运行 'pvault unlock'",
+	"doctor.fix.unlock":         "运行 'pvault unlock'",
+	"doctor.fix.clock":          "同步系统时钟（启用 NTP），然后撤销并重新创建受影响的服务令牌",
+	"doctor.fix.token":          "用 'pvault create-service-token' 创建替代令牌",
+
+	"service.installed":     "已为 %s 安装 pvault 服务。用 'pvault service start' 启动它，然后运行 'pvault unlock'。",
+	"service.removed":       "已删除 pvault 服务。",
+	"service.started":       "服务已启动（已锁定）。运行 'pvault unlock' 解锁。",
+	"service.stopped":       "服务已停止。",
+	"service.status":        "服务状态：%s。",
+	"service.not_installed": "未安装 pvault 服务。请以管理员身份运行 'pvault service install'。",
+	"service.unsupported":   "pvault service 仅在 Windows 上可用；使用 'pvault unlock' 在后台运行服务器。",
+
+	"schema.title":        "推荐的保险库结构",
+	"schema.user_defined": "（用户自定义字段）",
+
+	"schema.identity":               "个人身份信息",
+	"schema.identity.first_name":    "名",
+	"schema.identity.last_name":     "姓",
+	"schema.identity.full_name":     "完整显示名称",
+	"schema.identity.email":         "主要电子邮箱地址",
+	"schema.identity.phone":         "电话号码",
+	"schema.identity.date_of_birth": "出生日期",
+
+	"schema.addresses":              "实际地址",
+	"schema.addresses.home_street":  "住址街道",
+	"schema.addresses.home_city":    "居住城市",
+	"schema.addresses.home_state":   "居住省份或州",
+	"schema.addresses.home_zip":     "住址邮政编码",
+	"schema.addresses.home_country": "居住国家代码（例如 CN）",
+
+	"schema.financial":               "财务与税务信息",
+	"schema.financial.filing_status": "报税身份",
+	"schema.financial.ssn":           "美国社会安全号码（SSN）",
+
+	"schema.payment":                 "支付卡信息",
+	"schema.payment.card_number":     "卡号",
+	"schema.payment.card_expiry":     "卡片有效期",
+	"schema.payment.cardholder_name": "持卡人姓名",
+	"schema.payment.card_brand":      "卡组织（例如 Visa、Mastercard）",
+
+	"schema.preferences":          "用户偏好",
+	"schema.preferences.timezone": "首选时区（例如 Asia/Shanghai）",
+	"schema.preferences.language": "首选语言（例如 zh）",
+
+	"schema.employment":          "工作信息",
+	"schema.employment.employer": "当前雇主名称",
+	"schema.employment.title":    "职位",
+
+	"schema.contacts":                        "亲近的人，每人一组字段（例如 contacts.child_1.full_name）",
+	"schema.contacts.spouse.full_name":       "配偶或伴侣的全名",
+	"schema.contacts.spouse.phone":           "配偶或伴侣的电话号码",
+	"schema.contacts.spouse.email":           "配偶或伴侣的电子邮箱",
+	"schema.contacts.spouse.date_of_birth":   "配偶或伴侣的出生日期",
+	"schema.contacts.emergency.full_name":    "紧急联系人的全名",
+	"schema.contacts.emergency.phone":        "紧急联系人的电话号码",
+	"schema.contacts.emergency.relationship": "紧急联系人与您的关系",
+
+	"schema.vehicles":                     "车辆信息和登记",
+	"schema.vehicles.make":                "车辆品牌",
+	"schema.vehicles.model":               "车辆型号",
+	"schema.vehicles.year":                "车型年份",
+	"schema.vehicles.vin":                 "车辆识别号（VIN）",
+	"schema.vehicles.plate":               "车牌号",
+	"schema.vehicles.registration_expiry": "车辆登记到期日",
+
+	"schema.insurance":                      "保险公司和保单号",
+	"schema.insurance.auto_provider":        "车险公司",
+	"schema.insurance.auto_policy_number":   "车险保单号",
+	"schema.insurance.health_provider":      "健康保险公司",
+	"schema.insurance.health_policy_number": "健康保险保单号或团体号",
+	"schema.insurance.health_member_id":     "健康保险会员号",
+	"schema.insurance.home_provider":        "家庭财产或租客保险公司",
+	"schema.insurance.home_policy_number":   "家庭财产或租客保险保单号",
+
+	"schema.travel":                         "旅行证件",
+	"schema.travel.passport_number":         "护照号码",
+	"schema.travel.passport_country":        "护照签发国家代码（如 US）",
+	"schema.travel.passport_expiry":         "护照到期日（YYYY-MM-DD）",
+	"schema.travel.visa_country":            "签证所属国家",
+	"schema.travel.visa_number":             "签证号码",
+	"schema.travel.visa_expiry":             "签证到期日（YYYY-MM-DD）",
+	"schema.travel.known_traveler_number":   "已知旅客号码（Global Entry、TSA PreCheck、NEXUS）",
+	"schema.travel.trusted_traveler_expiry": "可信旅客会员到期日（YYYY-MM-DD）",
+	"schema.travel.redress_number":          "DHS 申诉号码（redress）",
+
+	"schema.medical":   "医疗信息",
+	"schema.documents": "文件引用（用户自定义字段）",
+
+	"schema.medical.blood_type":          "血型（如 O+）",
+	"schema.medical.allergies":           "过敏史，包括药物过敏",
+	"schema.medical.medications":         "当前用药及剂量",
+	"schema.medical.physician_name":      "家庭医生姓名",
+	"schema.medical.physician_phone":     "家庭医生电话",
+	"schema.medical.insurance_member_id": "健康保险会员号",
+}
@@ -0,0 +1,40 @@
+package keystore
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// KeychainAvailable reports whether the login keychain can be used.
+func KeychainAvailable() bool {
+	_, err := exec.LookPath("security")
+	return err == nil
+}
+
+// keychainSet stores the key as a generic password in the login keychain.
+// The command goes through security's stdin, not its arguments, so the key
+// never shows in the process list. The reference is the account name.
+func keychainSet(account, secret string) ([]byte, error) {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		keychainService, strconv.Quote(account), secret))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("security: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return []byte(account), nil
+}
+
+func keychainGet(_ string, ref []byte) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", string(ref), "-w").Output()
+	if err != nil {
+		return "", fmt.Errorf("secret key not found in the keychain: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func keychainDelete(_ string, ref []byte) error {
+	return exec.Command("security", "delete-generic-password", "-s", keychainService, "-a", string(ref)).Run()
+}
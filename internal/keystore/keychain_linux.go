@@ -0,0 +1,44 @@
+package keystore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// KeychainAvailable reports whether the Secret Service (GNOME Keyring,
+// KWallet) can be reached through secret-tool, which needs a desktop
+// session's D-Bus.
+func KeychainAvailable() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+// keychainSet stores the key with secret-tool, which reads it from stdin.
+// The reference is the account name.
+func keychainSet(account, secret string) ([]byte, error) {
+	cmd := exec.Command("secret-tool", "store", "--label=pvault secret key",
+		"service", keychainService, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("secret-tool: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return []byte(account), nil
+}
+
+func keychainGet(_ string, ref []byte) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keychainService, "account", string(ref)).Output()
+	if err != nil || len(out) == 0 {
+		return "", fmt.Errorf("secret key not found in the keychain (is the desktop session's keyring unlocked?)")
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func keychainDelete(_ string, ref []byte) error {
+	return exec.Command("secret-tool", "clear", "service", keychainService, "account", string(ref)).Run()
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows
+
+package keystore
+
+// KeychainAvailable reports false: there is no keychain integration here.
+func KeychainAvailable() bool {
+	return false
+}
+
+func keychainSet(account, secret string) ([]byte, error) {
+	return nil, ErrNoKeychain
+}
+
+func keychainGet(account string, ref []byte) (string, error) {
+	return "", ErrNoKeychain
+}
+
+func keychainDelete(account string, ref []byte) error {
+	return ErrNoKeychain
+}
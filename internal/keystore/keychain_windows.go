@@ -0,0 +1,55 @@
+package keystore
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// KeychainAvailable reports true: DPAPI is always there.
+func KeychainAvailable() bool {
+	return true
+}
+
+// keychainSet encrypts the key with DPAPI, which ties it to the Windows
+// user account. The encrypted blob is itself the reference.
+func keychainSet(account, secret string) ([]byte, error) {
+	in := []byte(secret)
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(blob(in), windows.StringToUTF16Ptr("pvault secret key"), blob([]byte(account)),
+		0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("DPAPI: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	sealed := unsafe.Slice(out.Data, out.Size)
+	return []byte(base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+func keychainGet(account string, ref []byte) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(ref)))
+	if err != nil {
+		return "", fmt.Errorf("decode secret.key.keychain: %w", err)
+	}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(blob(sealed), nil, blob([]byte(account)),
+		0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return "", fmt.Errorf("DPAPI: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return string(unsafe.Slice(out.Data, out.Size)), nil
+}
+
+// keychainDelete has nothing to do: the blob lives in the reference file.
+func keychainDelete(string, []byte) error {
+	return nil
+}
+
+func blob(b []byte) *windows.DataBlob {
+	if len(b) == 0 {
+		return &windows.DataBlob{}
+	}
+	return &windows.DataBlob{Size: uint32(len(b)), Data: &b[0]}
+}
@@ -0,0 +1,308 @@
+// Package keystore keeps a vault's secret key somewhere other than a
+// plaintext file next to the database: the OS keychain, a file encrypted
+// with a PIN, a removable device, or nowhere at all, typed in at every
+// unlock.
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+)
+
+// Mode is how a vault's secret key is stored.
+type Mode string
+
+const (
+	File     Mode = "file"     // secret.key, unencrypted (mode 0600)
+	Keychain Mode = "keychain" // the OS keychain; secret.key.keychain says where
+	PIN      Mode = "pin"      // secret.key.pin, age-encrypted with a PIN
+	Keyfile  Mode = "keyfile"  // a file on removable media; secret.key.location says where
+	Manual   Mode = "manual"   // not stored; entered at every unlock
+)
+
+// Modes lists the storage modes, most protective of convenience first.
+var Modes = []Mode{Keychain, PIN, Keyfile, Manual, File}
+
+// MinPINLength is the shortest PIN Save accepts.
+const MinPINLength = 6
+
+// keychainService names the vault's entries in the OS keychain.
+const keychainService = "pvault"
+
+var (
+	ErrNeedInput   = errors.New("the secret key needs a PIN or to be entered by hand")
+	ErrWrongPIN    = errors.New("wrong PIN")
+	ErrShortPIN    = fmt.Errorf("PIN must be at least %d characters", MinPINLength)
+	ErrNoKeychain  = errors.New("no OS keychain is available")
+	ErrUnknownMode = errors.New("unknown secret key storage mode: want keychain, pin, keyfile, manual, or file")
+	ErrNoDevice    = errors.New("secret key file not found; is its device mounted?")
+)
+
+// Options carries what some modes need to store the key.
+type Options struct {
+	PIN     string // PIN mode: the PIN to encrypt with
+	Keyfile string // Keyfile mode: absolute path on the removable device
+}
+
+// ParseMode parses a mode name.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", ErrUnknownMode
+}
+
+// Path returns the file that holds or locates the key in mode m, or "" for
+// Manual.
+func Path(dir string, m Mode) string {
+	switch m {
+	case File:
+		return filepath.Join(dir, "secret.key")
+	case Keychain:
+		return filepath.Join(dir, "secret.key.keychain")
+	case PIN:
+		return filepath.Join(dir, "secret.key.pin")
+	case Keyfile:
+		return filepath.Join(dir, "secret.key.location")
+	}
+	return ""
+}
+
+// KeyfilePath returns where a Keyfile-mode key lives, as recorded in the
+// vault directory.
+func KeyfilePath(dir string) (string, error) {
+	data, err := os.ReadFile(Path(dir, Keyfile))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// stored lists the modes that leave a file in the vault directory, in the
+// order Detect checks them.
+var stored = []Mode{File, Keychain, PIN, Keyfile}
+
+// Detect reports how dir's secret key is stored. With no key file of any
+// kind, the key is entered by hand.
+func Detect(dir string) Mode {
+	for _, m := range stored {
+		if _, err := os.Stat(Path(dir, m)); err == nil {
+			return m
+		}
+	}
+	return Manual
+}
+
+// Prompter asks for a PIN (in PIN mode) or the secret key itself (in
+// Manual mode).
+type Prompter func(Mode) (string, error)
+
+// Load returns dir's secret key. ask is nil where nobody can answer a
+// prompt, in which case a PIN-protected or manual key is ErrNeedInput.
+func Load(dir string, ask Prompter) (string, error) {
+	m := Detect(dir)
+	if m == Manual || m == PIN {
+		if ask == nil {
+			return "", ErrNeedInput
+		}
+		input, err := ask(m)
+		if err != nil {
+			return "", err
+		}
+		if m == Manual {
+			return strings.TrimSpace(input), nil
+		}
+		return loadPIN(dir, input)
+	}
+	if m == Keyfile {
+		return loadKeyfile(dir)
+	}
+	data, err := os.ReadFile(Path(dir, m))
+	if err != nil {
+		return "", err
+	}
+	if m == File {
+		return strings.TrimSpace(string(data)), nil
+	}
+	return keychainGet(keychainAccount(dir), data)
+}
+
+// loadKeyfile reads the key from its removable device, which must be
+// mounted: that is the point of keeping it there.
+func loadKeyfile(dir string) (string, error) {
+	path, err := KeyfilePath(dir)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w (%s)", ErrNoDevice, path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func loadPIN(dir, pin string) (string, error) {
+	data, err := os.ReadFile(Path(dir, PIN))
+	if err != nil {
+		return "", err
+	}
+	sk, err := crypto.OpenWithPassphrase(data, pin)
+	if errors.Is(err, crypto.ErrWrongPassphrase) {
+		return "", ErrWrongPIN
+	}
+	return string(sk), err
+}
+
+// Save stores sk in mode m. The key's other forms are removed only once the
+// new one reads back, so a failure part way leaves the old one in place;
+// unencrypted files are overwritten first.
+func Save(dir string, m Mode, sk string, opts Options) error {
+	switch m {
+	case File:
+		if err := os.WriteFile(Path(dir, File), []byte(sk+"\n"), 0600); err != nil {
+			return err
+		}
+	case Keychain:
+		ref, err := keychainSet(keychainAccount(dir), sk)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(Path(dir, Keychain), ref, 0600); err != nil {
+			return err
+		}
+		if got, err := keychainGet(keychainAccount(dir), ref); err != nil || got != sk {
+			os.Remove(Path(dir, Keychain))
+			return fmt.Errorf("keychain did not return the stored key: %v", err)
+		}
+	case PIN:
+		if len(opts.PIN) < MinPINLength {
+			return ErrShortPIN
+		}
+		sealed, err := crypto.SealWithPassphrase([]byte(sk), opts.PIN)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(Path(dir, PIN), sealed, 0600); err != nil {
+			return err
+		}
+		if got, err := loadPIN(dir, opts.PIN); err != nil || got != sk {
+			os.Remove(Path(dir, PIN))
+			return fmt.Errorf("PIN-protected key did not read back: %v", err)
+		}
+	case Keyfile:
+		if err := saveKeyfile(dir, sk, opts.Keyfile); err != nil {
+			return err
+		}
+	case Manual:
+	default:
+		return ErrUnknownMode
+	}
+
+	for _, other := range stored {
+		if other != m {
+			remove(dir, other)
+		}
+	}
+	return nil
+}
+
+// saveKeyfile writes the key to path on a removable device and records
+// where it is. The path must be absolute, since the device is mounted
+// wherever the OS puts it, and outside the vault directory, or the second
+// factor would be sitting next to the first.
+func saveKeyfile(dir, sk, path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("key file path must be absolute: %s", path)
+	}
+	path = filepath.Clean(path)
+	if rel, err := filepath.Rel(keychainAccount(dir), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("key file must be on another device, not inside %s", dir)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("%w (%s)", ErrNoDevice, filepath.Dir(path))
+	}
+	if data, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(data)) != sk {
+		return fmt.Errorf("%s already exists and holds another key", path)
+	}
+	previous, _ := KeyfilePath(dir)
+	if err := os.WriteFile(path, []byte(sk+"\n"), 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(Path(dir, Keyfile), []byte(path+"\n"), 0600); err != nil {
+		return err
+	}
+	if got, err := loadKeyfile(dir); err != nil || got != sk {
+		if previous != "" {
+			os.WriteFile(Path(dir, Keyfile), []byte(previous+"\n"), 0600)
+		} else {
+			os.Remove(Path(dir, Keyfile))
+		}
+		return fmt.Errorf("key file did not read back: %v", err)
+	}
+	// Moving to another device takes the key off the old one.
+	if previous != "" && previous != path {
+		removeKeyfile(previous)
+	}
+	return nil
+}
+
+// removeKeyfile scrubs and deletes a key file on a removable device, if the
+// device is mounted.
+func removeKeyfile(path string) {
+	if info, err := os.Stat(path); err == nil {
+		scrub(path, int(info.Size()))
+		os.Remove(path)
+	}
+}
+
+// remove deletes the key's form in mode m, if any.
+func remove(dir string, m Mode) {
+	path := Path(dir, m)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	switch m {
+	case File:
+		scrub(path, len(data))
+	case Keychain:
+		keychainDelete(keychainAccount(dir), data)
+	case Keyfile:
+		// Take the key off the device too; if it isn't mounted, the key
+		// couldn't have been read to move it.
+		if keyfile := strings.TrimSpace(string(data)); keyfile != "" {
+			removeKeyfile(keyfile)
+		}
+	}
+	os.Remove(path)
+}
+
+// scrub overwrites a file before it is unlinked, so the key doesn't linger
+// in the freed blocks of simple filesystems. (Copy-on-write and flash
+// storage may still keep it.)
+func scrub(path string, size int) {
+	if f, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+		f.Write(make([]byte, size))
+		f.Sync()
+		f.Close()
+	}
+}
+
+// keychainAccount names a vault's keychain entry by its directory, so
+// several vaults can share one keychain.
+func keychainAccount(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
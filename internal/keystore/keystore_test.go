@@ -0,0 +1,123 @@
+package keystore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKey = "e2cf9d0f025925abd4be4bba081e4021"
+
+func TestSaveLoad_Modes(t *testing.T) {
+	dir := t.TempDir()
+	if m := Detect(dir); m != Manual {
+		t.Fatalf("expected an empty dir to mean manual entry, got %s", m)
+	}
+
+	if err := Save(dir, File, testKey, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if m := Detect(dir); m != File {
+		t.Fatalf("expected file, got %s", m)
+	}
+	if sk, err := Load(dir, nil); err != nil || sk != testKey {
+		t.Fatalf("expected the key from the file, got %q, %v", sk, err)
+	}
+
+	if err := Save(dir, PIN, testKey, Options{PIN: "123"}); err != ErrShortPIN {
+		t.Fatalf("expected ErrShortPIN, got %v", err)
+	}
+	if err := Save(dir, PIN, testKey, Options{PIN: "482913"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(Path(dir, File)); !os.IsNotExist(err) {
+		t.Fatal("expected the unencrypted file to be removed")
+	}
+	if m := Detect(dir); m != PIN {
+		t.Fatalf("expected pin, got %s", m)
+	}
+	if _, err := Load(dir, nil); err != ErrNeedInput {
+		t.Fatalf("expected ErrNeedInput without a prompt, got %v", err)
+	}
+	pin := func(p string) Prompter {
+		return func(m Mode) (string, error) {
+			if m != PIN {
+				t.Fatalf("expected a PIN prompt, got %s", m)
+			}
+			return p, nil
+		}
+	}
+	if _, err := Load(dir, pin("000000")); err != ErrWrongPIN {
+		t.Fatalf("expected ErrWrongPIN, got %v", err)
+	}
+	if sk, err := Load(dir, pin("482913")); err != nil || sk != testKey {
+		t.Fatalf("expected the key with the PIN, got %q, %v", sk, err)
+	}
+
+	if err := Save(dir, Manual, testKey, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if m := Detect(dir); m != Manual {
+		t.Fatalf("expected manual, got %s", m)
+	}
+	typed := func(m Mode) (string, error) { return " " + testKey + "\n", nil }
+	if sk, err := Load(dir, typed); err != nil || sk != testKey {
+		t.Fatalf("expected the typed key, got %q, %v", sk, err)
+	}
+}
+
+func TestSaveLoad_Keyfile(t *testing.T) {
+	dir := t.TempDir()
+	usb := filepath.Join(t.TempDir(), "USB")
+	keyfile := filepath.Join(usb, "pvault.key")
+	Save(dir, File, testKey, Options{})
+
+	if err := Save(dir, Keyfile, testKey, Options{Keyfile: keyfile}); !errors.Is(err, ErrNoDevice) {
+		t.Fatalf("expected ErrNoDevice for an unmounted device, got %v", err)
+	}
+	if err := Save(dir, Keyfile, testKey, Options{Keyfile: "pvault.key"}); err == nil {
+		t.Fatal("expected a relative path to be rejected")
+	}
+	if err := Save(dir, Keyfile, testKey, Options{Keyfile: filepath.Join(dir, "pvault.key")}); err == nil {
+		t.Fatal("expected a path inside the vault directory to be rejected")
+	}
+
+	os.Mkdir(usb, 0700) // plug in the device
+	if err := Save(dir, Keyfile, testKey, Options{Keyfile: keyfile}); err != nil {
+		t.Fatal(err)
+	}
+	if m := Detect(dir); m != Keyfile {
+		t.Fatalf("expected keyfile, got %s", m)
+	}
+	if _, err := os.Stat(Path(dir, File)); !os.IsNotExist(err) {
+		t.Fatal("expected the file next to the database to be removed")
+	}
+	if sk, err := Load(dir, nil); err != nil || sk != testKey {
+		t.Fatalf("expected the key from the device, got %q, %v", sk, err)
+	}
+
+	os.Rename(usb, usb+".unplugged")
+	if _, err := Load(dir, nil); !errors.Is(err, ErrNoDevice) {
+		t.Fatalf("expected ErrNoDevice with the device unplugged, got %v", err)
+	}
+	os.Rename(usb+".unplugged", usb)
+
+	if err := Save(dir, File, testKey, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(keyfile); !os.IsNotExist(err) {
+		t.Fatal("expected moving away to take the key off the device")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, m := range Modes {
+		if got, err := ParseMode(string(m)); err != nil || got != m {
+			t.Errorf("ParseMode(%q) = %q, %v", m, got, err)
+		}
+	}
+	if _, err := ParseMode("usb"); err != ErrUnknownMode {
+		t.Fatalf("expected ErrUnknownMode, got %v", err)
+	}
+}
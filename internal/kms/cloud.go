@@ -0,0 +1,88 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// AWSKMS wraps with an AWS KMS key through the aws CLI, which brings its
+// own credentials (profiles, SSO, instance roles). The plaintext goes over
+// stdin, never on the command line.
+type AWSKMS struct {
+	KeyID  string // key ID, ARN, or alias/<name>
+	Region string // empty means the CLI's default
+}
+
+func (a *AWSKMS) Wrap(ctx context.Context, plaintext []byte) (string, error) {
+	out, err := a.run(ctx, plaintext, "encrypt", "--key-id", a.KeyID, "--plaintext", "fileb:///dev/stdin", "--query", "CiphertextBlob")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (a *AWSKMS) Unwrap(ctx context.Context, ciphertext string) ([]byte, error) {
+	blob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("aws kms: decode ciphertext: %w", err)
+	}
+	out, err := a.run(ctx, blob, "decrypt", "--key-id", a.KeyID, "--ciphertext-blob", "fileb:///dev/stdin", "--query", "Plaintext")
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("aws kms decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func (a *AWSKMS) run(ctx context.Context, stdin []byte, op string, args ...string) ([]byte, error) {
+	args = append([]string{"kms", op, "--output", "text"}, args...)
+	if a.Region != "" {
+		args = append(args, "--region", a.Region)
+	}
+	return runCLI(ctx, "aws", stdin, args...)
+}
+
+// GCPKMS wraps with a Google Cloud KMS key through the gcloud CLI, which
+// brings its own credentials. Both directions stream over stdin and stdout.
+type GCPKMS struct {
+	Key string // projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>
+}
+
+func (g *GCPKMS) Wrap(ctx context.Context, plaintext []byte) (string, error) {
+	out, err := runCLI(ctx, "gcloud", plaintext, "kms", "encrypt", "--key", g.Key, "--plaintext-file", "-", "--ciphertext-file", "-")
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+func (g *GCPKMS) Unwrap(ctx context.Context, ciphertext string) ([]byte, error) {
+	blob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("gcloud kms: decode ciphertext: %w", err)
+	}
+	return runCLI(ctx, "gcloud", blob, "kms", "decrypt", "--key", g.Key, "--ciphertext-file", "-", "--plaintext-file", "-")
+}
+
+// runCLI runs a cloud CLI with stdin and returns its stdout.
+func runCLI(ctx context.Context, name string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s %s: %s", name, strings.Join(args[:2], " "), msg)
+		}
+		return nil, fmt.Errorf("%s %s: %w", name, strings.Join(args[:2], " "), err)
+	}
+	return out, nil
+}
@@ -0,0 +1,134 @@
+// Package kms wraps a vault's unlock key with an external key management
+// service (HashiCorp Vault's transit engine, AWS KMS, or Google Cloud KMS),
+// so a headless server can unlock itself with the service's permission
+// instead of a password on disk.
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Provider names a key management service.
+type Provider string
+
+const (
+	HashiCorp Provider = "hashicorp" // HashiCorp Vault transit secrets engine
+	AWS       Provider = "aws"       // AWS KMS, through the aws CLI
+	GCP       Provider = "gcp"       // Google Cloud KMS, through the gcloud CLI
+)
+
+// Providers lists the supported services.
+var Providers = []Provider{HashiCorp, AWS, GCP}
+
+// enrollmentFile holds the enrollment, next to the database.
+const enrollmentFile = "kms.json"
+
+var (
+	ErrNotEnrolled     = errors.New("no KMS is enrolled")
+	ErrUnknownProvider = errors.New("unknown KMS provider: want hashicorp, aws, or gcp")
+	ErrNeedKey         = errors.New("the KMS key is required")
+	ErrNeedAddress     = errors.New("HashiCorp Vault needs its address")
+)
+
+// Enrollment records which KMS key wraps the vault's unlock key, and the
+// wrapped key itself. Nothing in it opens the vault without the KMS.
+type Enrollment struct {
+	Provider   Provider  `json:"provider"`
+	Key        string    `json:"key"`               // transit key name, AWS key ID/ARN/alias, or GCP key resource name
+	Address    string    `json:"address,omitempty"` // HashiCorp: the server URL
+	Mount      string    `json:"mount,omitempty"`   // HashiCorp: the transit mount (default "transit")
+	Region     string    `json:"region,omitempty"`  // AWS: the key's region (default the CLI's)
+	Ciphertext string    `json:"ciphertext"`        // the wrapped unlock key, as the provider returns it
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Wrapper encrypts and decrypts small secrets with a key the service holds.
+type Wrapper interface {
+	Wrap(ctx context.Context, plaintext []byte) (string, error)
+	Unwrap(ctx context.Context, ciphertext string) ([]byte, error)
+}
+
+// ParseProvider parses a provider name.
+func ParseProvider(s string) (Provider, error) {
+	if slices.Contains(Providers, Provider(s)) {
+		return Provider(s), nil
+	}
+	return "", ErrUnknownProvider
+}
+
+// NewWrapper returns the wrapper for e's provider and key.
+func NewWrapper(e Enrollment) (Wrapper, error) {
+	if e.Key == "" {
+		return nil, ErrNeedKey
+	}
+	switch e.Provider {
+	case HashiCorp:
+		if e.Address == "" {
+			return nil, ErrNeedAddress
+		}
+		mount := e.Mount
+		if mount == "" {
+			mount = "transit"
+		}
+		return &Transit{Address: e.Address, Mount: mount, Key: e.Key}, nil
+	case AWS:
+		return &AWSKMS{KeyID: e.Key, Region: e.Region}, nil
+	case GCP:
+		return &GCPKMS{Key: e.Key}, nil
+	}
+	return nil, ErrUnknownProvider
+}
+
+// Path returns where a vault's enrollment is kept.
+func Path(dir string) string {
+	return filepath.Join(dir, enrollmentFile)
+}
+
+// Load reads a vault's enrollment, or returns ErrNotEnrolled.
+func Load(dir string) (*Enrollment, error) {
+	data, err := os.ReadFile(Path(dir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e Enrollment
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("%s: %w", Path(dir), err)
+	}
+	return &e, nil
+}
+
+// Save writes a vault's enrollment, replacing any previous one.
+func Save(dir string, e *Enrollment) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := Path(dir) + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, Path(dir)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Remove deletes a vault's enrollment. It does not touch the KMS key.
+func Remove(dir string) error {
+	err := os.Remove(Path(dir))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotEnrolled
+	}
+	return err
+}
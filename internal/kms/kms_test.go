@@ -0,0 +1,125 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(dir); !errors.Is(err, ErrNotEnrolled) {
+		t.Fatalf("expected ErrNotEnrolled, got %v", err)
+	}
+	e := &Enrollment{Provider: AWS, Key: "alias/pvault", Ciphertext: "AQID"}
+	if err := Save(dir, e); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(Path(dir)); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected the enrollment to be private, got %v, %v", info.Mode(), err)
+	}
+	got, err := Load(dir)
+	if err != nil || got.Provider != AWS || got.Key != "alias/pvault" || got.Ciphertext != "AQID" {
+		t.Fatalf("expected the saved enrollment back, got %+v, %v", got, err)
+	}
+	if err := Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(dir); !errors.Is(err, ErrNotEnrolled) {
+		t.Fatalf("expected ErrNotEnrolled, got %v", err)
+	}
+}
+
+func TestNewWrapper(t *testing.T) {
+	cases := []struct {
+		e    Enrollment
+		want error
+	}{
+		{Enrollment{Provider: HashiCorp, Key: "pvault"}, ErrNeedAddress},
+		{Enrollment{Provider: GCP}, ErrNeedKey},
+		{Enrollment{Provider: "azure", Key: "k"}, ErrUnknownProvider},
+	}
+	for _, c := range cases {
+		if _, err := NewWrapper(c.e); !errors.Is(err, c.want) {
+			t.Errorf("%+v: expected %v, got %v", c.e, c.want, err)
+		}
+	}
+	w, err := NewWrapper(Enrollment{Provider: HashiCorp, Key: "pvault", Address: "https://vault.example:8200"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr := w.(*Transit); tr.Mount != "transit" {
+		t.Fatalf("expected the default transit mount, got %q", tr.Mount)
+	}
+}
+
+// fakeTransit is a transit engine that "encrypts" by reversing the bytes.
+func fakeTransit(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.test" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+		var in map[string]string
+		json.NewDecoder(r.Body).Decode(&in)
+		reverse := func(b []byte) []byte {
+			out := make([]byte, len(b))
+			for i := range b {
+				out[len(b)-1-i] = b[i]
+			}
+			return out
+		}
+		var data map[string]string
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/pvault":
+			pt, _ := base64.StdEncoding.DecodeString(in["plaintext"])
+			data = map[string]string{"ciphertext": "vault:v1:" + base64.StdEncoding.EncodeToString(reverse(pt))}
+		case "/v1/transit/decrypt/pvault":
+			ct, _ := base64.StdEncoding.DecodeString(in["ciphertext"][len("vault:v1:"):])
+			data = map[string]string{"plaintext": base64.StdEncoding.EncodeToString(reverse(ct))}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+}
+
+func TestTransit(t *testing.T) {
+	srv := fakeTransit(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	tr := &Transit{Address: srv.URL, Mount: "transit", Key: "pvault", Token: "s.test"}
+	secret := []byte("unlock key bytes")
+	ct, err := tr.Wrap(ctx, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ct) < 9 || ct[:9] != "vault:v1:" {
+		t.Fatalf("expected a transit ciphertext, got %q", ct)
+	}
+	pt, err := tr.Unwrap(ctx, ct)
+	if err != nil || !bytes.Equal(pt, secret) {
+		t.Fatalf("expected the secret back, got %q, %v", pt, err)
+	}
+
+	denied := &Transit{Address: srv.URL, Mount: "transit", Key: "pvault", Token: "s.wrong"}
+	if _, err := denied.Unwrap(ctx, ct); err == nil || err.Error() != "transit decrypt: permission denied" {
+		t.Fatalf("expected the server's error, got %v", err)
+	}
+
+	t.Setenv("VAULT_TOKEN", "")
+	t.Setenv("HOME", t.TempDir())
+	anon := &Transit{Address: srv.URL, Mount: "transit", Key: "pvault"}
+	if _, err := anon.Wrap(ctx, secret); !errors.Is(err, ErrNoVaultToken) {
+		t.Fatalf("expected ErrNoVaultToken, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoVaultToken means no HashiCorp Vault token was found.
+var ErrNoVaultToken = errors.New("HashiCorp Vault needs a token: set VAULT_TOKEN or run 'vault login'")
+
+// Transit wraps with a key in HashiCorp Vault's transit secrets engine.
+// It authenticates with VAULT_TOKEN, else the token 'vault login' saves in
+// ~/.vault-token. (VAULT_ADDR isn't read: pvault uses it for its own
+// server, so the address is part of the enrollment.)
+type Transit struct {
+	Address string
+	Mount   string
+	Key     string
+	Token   string       // empty means VAULT_TOKEN or ~/.vault-token
+	Client  *http.Client // nil means http.DefaultClient
+}
+
+func (t *Transit) Wrap(ctx context.Context, plaintext []byte) (string, error) {
+	var out struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	in := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}
+	if err := t.call(ctx, "encrypt", in, &out); err != nil {
+		return "", err
+	}
+	if out.Ciphertext == "" {
+		return "", errors.New("transit encrypt returned no ciphertext")
+	}
+	return out.Ciphertext, nil
+}
+
+func (t *Transit) Unwrap(ctx context.Context, ciphertext string) ([]byte, error) {
+	var out struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := t.call(ctx, "decrypt", map[string]string{"ciphertext": ciphertext}, &out); err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(out.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("transit decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// call POSTs to /v1/<mount>/<op>/<key> and decodes the response's data.
+func (t *Transit) call(ctx context.Context, op string, in, out any) error {
+	token, err := t.token()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	u := strings.TrimRight(t.Address, "/") + "/v1/" + strings.Trim(t.Mount, "/") + "/" + op + "/" + url.PathEscape(t.Key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []string        `json:"errors"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode/100 != 2 {
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("transit %s: %s", op, strings.Join(result.Errors, "; "))
+		}
+		return fmt.Errorf("transit %s: %s", op, resp.Status)
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("transit %s: %w", op, err)
+	}
+	return nil
+}
+
+func (t *Transit) token() (string, error) {
+	if t.Token != "" {
+		return t.Token, nil
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			if token := strings.TrimSpace(string(data)); token != "" {
+				return token, nil
+			}
+		}
+	}
+	return "", ErrNoVaultToken
+}
@@ -0,0 +1,125 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// PeerUID returns the user ID of the local process on the other end of c:
+// a Unix socket peer or, on Linux, a loopback TCP client. It returns
+// errors.ErrUnsupported where the platform can't tell.
+func PeerUID(c net.Conn) (int, error) {
+	switch c := c.(type) {
+	case *net.UnixConn:
+		w, err := PeerWorkload(c)
+		if err != nil {
+			return -1, err
+		}
+		return w.UID, nil
+	case *net.TCPConn:
+		remote := c.RemoteAddr().(*net.TCPAddr).AddrPort()
+		local := c.LocalAddr().(*net.TCPAddr).AddrPort()
+		return tcpPeerUID(remote, local)
+	}
+	return -1, errors.ErrUnsupported
+}
+
+// OwnerListener wraps ln to refuse connections from local processes run by
+// a user other than uid, so that on a shared machine they can't reach the
+// service at all. Remote clients, and local ones on platforms that can't
+// name the peer's user (Windows, and TCP on macOS), are let through for the
+// service to authenticate.
+func OwnerListener(ln net.Listener, uid int) net.Listener {
+	return ownerListener{ln, uid}
+}
+
+type ownerListener struct {
+	net.Listener
+	uid int
+}
+
+func (l ownerListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.allowed(c) {
+			return c, nil
+		}
+		c.Close()
+	}
+}
+
+func (l ownerListener) allowed(c net.Conn) bool {
+	if tc, ok := c.(*net.TCPConn); ok && !tc.RemoteAddr().(*net.TCPAddr).IP.IsLoopback() {
+		return true
+	}
+	uid, err := PeerUID(c)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return true
+	}
+	if err != nil || uid != l.uid {
+		slog.Warn("refused connection from another user", "uid", uid, "remote", c.RemoteAddr().String())
+		return false
+	}
+	return true
+}
+
+// socketUID finds the socket bound to local and connected to remote in a
+// /proc/net/tcp or tcp6 table and returns its owner's UID.
+func socketUID(table []byte, local, remote netip.AddrPort) (int, bool) {
+	local = netip.AddrPortFrom(local.Addr().Unmap(), local.Port())
+	remote = netip.AddrPortFrom(remote.Addr().Unmap(), remote.Port())
+	scanner := bufio.NewScanner(bytes.NewReader(table))
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		l, err1 := parseProcAddr(fields[1])
+		r, err2 := parseProcAddr(fields[2])
+		if err1 != nil || err2 != nil || l != local || r != remote {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[7])
+		if err != nil {
+			return -1, false
+		}
+		return uid, true
+	}
+	return -1, false
+}
+
+// parseProcAddr decodes an address such as "0100007F:1C20". The kernel
+// prints each 32-bit word of the address in host byte order.
+func parseProcAddr(s string) (netip.AddrPort, error) {
+	ipHex, portHex, ok := strings.Cut(s, ":")
+	if !ok {
+		return netip.AddrPort{}, errors.New("malformed address")
+	}
+	raw, err := hex.DecodeString(ipHex)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return netip.AddrPort{}, errors.New("malformed address")
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+	ip := make([]byte, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.NativeEndian.PutUint32(ip[i:], binary.BigEndian.Uint32(raw[i:]))
+	}
+	addr, _ := netip.AddrFromSlice(ip)
+	return netip.AddrPortFrom(addr.Unmap(), uint16(port)), nil
+}
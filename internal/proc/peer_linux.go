@@ -0,0 +1,23 @@
+package proc
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+)
+
+// tcpPeerUID looks up the client's end of a loopback connection in the
+// kernel's socket tables, which record the UID that created each socket.
+func tcpPeerUID(remote, local netip.AddrPort) (int, error) {
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		table, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// The client's socket is bound to our remote address.
+		if uid, ok := socketUID(table, remote, local); ok {
+			return uid, nil
+		}
+	}
+	return -1, errors.New("peer socket not found")
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package proc
+
+import (
+	"errors"
+	"net/netip"
+)
+
+func tcpPeerUID(remote, local netip.AddrPort) (int, error) {
+	return -1, errors.ErrUnsupported
+}
@@ -0,0 +1,13 @@
+// Package proc holds the platform-specific process control the CLI uses to
+// run the vault server in the background and stop it again.
+package proc
+
+import (
+	"os"
+	"syscall"
+)
+
+// ShutdownSignals are the signals a foreground server stops on: Ctrl+C,
+// SIGTERM, and on Unix SIGHUP when its terminal closes, or on Windows a
+// console close, logoff, or shutdown.
+var ShutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
@@ -0,0 +1,180 @@
+package proc
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestMain doubles as a long-running child process for the tests below.
+func TestMain(m *testing.M) {
+	if os.Getenv("PROC_TEST_CHILD") == "1" {
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestAlive_Self(t *testing.T) {
+	if !Alive(os.Getpid()) {
+		t.Fatal("expected the test process to be alive")
+	}
+}
+
+func TestDetachAliveTerminate(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), "PROC_TEST_CHILD=1")
+	Detach(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := cmd.Process.Pid
+	t.Cleanup(func() { cmd.Process.Kill() })
+
+	if !Alive(pid) {
+		t.Fatal("expected child to be alive")
+	}
+	if err := Terminate(pid); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() { cmd.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("child did not exit after Terminate")
+	}
+	if Alive(pid) {
+		t.Fatal("expected child to be gone after Terminate")
+	}
+}
+
+func TestPeerWorkload_Self(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("peer attestation is not supported on " + runtime.GOOS)
+	}
+	// Socket paths are length-limited, so avoid the long default temp dir.
+	dir, err := os.MkdirTemp("", "pv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	ln, err := net.Listen("unix", filepath.Join(dir, "s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	client, err := net.Dial("unix", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	w, err := PeerWorkload(server.(*net.UnixConn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.PID != os.Getpid() || w.UID != os.Getuid() {
+		t.Fatalf("expected pid %d uid %d, got %+v", os.Getpid(), os.Getuid(), w)
+	}
+	exe, _ := os.Executable()
+	want, err := FileSHA256(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := w.ExeSHA256(); err != nil || got != want {
+		t.Fatalf("expected exe hash %s, got %s, %v", want, got, err)
+	}
+}
+
+func TestPeerUID_TCP(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("TCP peer users are only known on linux")
+	}
+	for _, addr := range []string{"127.0.0.1:0", "[::1]:0"} {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Logf("%s: %v", addr, err) // no IPv6 loopback
+			continue
+		}
+		defer ln.Close()
+		client, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		server, err := ln.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer server.Close()
+
+		if uid, err := PeerUID(server); err != nil || uid != os.Getuid() {
+			t.Fatalf("%s: expected uid %d, got %d, %v", addr, os.Getuid(), uid, err)
+		}
+	}
+}
+
+func TestOwnerListener(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("TCP peer users are only known on linux")
+	}
+	accepts := func(uid int) bool {
+		inner, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ln := OwnerListener(inner, uid)
+		defer ln.Close()
+		accepted := make(chan bool, 1)
+		go func() {
+			c, err := ln.Accept()
+			if err == nil {
+				c.Close()
+			}
+			accepted <- err == nil
+		}()
+		client, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		select {
+		case ok := <-accepted:
+			return ok
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+
+	if !accepts(os.Getuid()) {
+		t.Fatal("expected the owner's connection to be accepted")
+	}
+	if accepts(os.Getuid() + 1) {
+		t.Fatal("expected another user's connection to be refused")
+	}
+}
+
+func TestSessionFileOpen(t *testing.T) {
+	tests := map[string]bool{
+		"UID=1000\nUSER=jane\nSTATE=active\n":  true,
+		"UID=1000\nUSER=jane\nSTATE=online\n":  true,
+		"UID=1000\nUSER=jane\nSTATE=closing\n": false,
+		"UID=1000\n":                           true,
+	}
+	for data, want := range tests {
+		if got := sessionFileOpen([]byte(data)); got != want {
+			t.Errorf("%q: got %v, want %v", data, got, want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// sessionFileOpen reports whether a systemd-logind session file describes
+// a session that is still open. A session is "closing" from logout until
+// its last process exits, which for the vault server is never.
+func sessionFileOpen(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if state, ok := strings.CutPrefix(scanner.Text(), "STATE="); ok {
+			return state != "closing"
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package proc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sessionsDir is where systemd-logind describes each login session.
+const sessionsDir = "/run/systemd/sessions"
+
+// noAuditSession is /proc/self/sessionid for a process outside any login.
+const noAuditSession = "4294967295"
+
+// LoginSession returns the login session this process runs in, if logind
+// tracks it. Processes started by a supervisor, in a container, or without
+// pam_systemd have none.
+func LoginSession() (string, bool) {
+	id := os.Getenv("XDG_SESSION_ID")
+	if id == "" {
+		data, err := os.ReadFile("/proc/self/sessionid")
+		if err != nil {
+			return "", false
+		}
+		id = strings.TrimSpace(string(data))
+	}
+	if id == "" || id == noAuditSession || strings.ContainsAny(id, `/\`) {
+		return "", false
+	}
+	if _, err := os.Stat(filepath.Join(sessionsDir, id)); err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// LoginSessionOpen reports whether the user is still logged in to session
+// id. It turns false at logout, even while processes like the vault server
+// linger.
+func LoginSessionOpen(id string) bool {
+	data, err := os.ReadFile(filepath.Join(sessionsDir, id))
+	if err != nil {
+		return false
+	}
+	return sessionFileOpen(data)
+}
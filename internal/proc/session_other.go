@@ -0,0 +1,14 @@
+//go:build !linux
+
+package proc
+
+// LoginSession reports no session: only systemd-logind sessions are
+// tracked. Elsewhere, logout ends the server with a signal instead.
+func LoginSession() (string, bool) {
+	return "", false
+}
+
+// LoginSessionOpen always reports true where sessions aren't tracked.
+func LoginSessionOpen(id string) bool {
+	return true
+}
@@ -0,0 +1,61 @@
+package proc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"sync"
+)
+
+// Workload identifies the process on the other end of a Unix socket, as
+// reported by the kernel rather than by the process itself. PIDs can be
+// reused, so a workload is only as trustworthy as the moment it was read;
+// it is attested once per connection.
+type Workload struct {
+	PID         int
+	UID         int
+	Exe         string // executable path, if known
+	ContainerID string // container the process runs in (Linux only), if any
+
+	image    string // file to hash for the executable's contents
+	hashOnce sync.Once
+	hash     string
+	hashErr  error
+}
+
+// PeerWorkload attests the process connected on conn.
+func PeerWorkload(conn *net.UnixConn) (*Workload, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var w *Workload
+	var peerErr error
+	if err := raw.Control(func(fd uintptr) { w, peerErr = peerWorkload(int(fd)) }); err != nil {
+		return nil, err
+	}
+	return w, peerErr
+}
+
+// ExeSHA256 returns the hex SHA-256 of the process's executable. It is
+// computed on first use, since only workload-bound tokens need it.
+func (w *Workload) ExeSHA256() (string, error) {
+	w.hashOnce.Do(func() { w.hash, w.hashErr = FileSHA256(w.image) })
+	return w.hash, w.hashErr
+}
+
+// FileSHA256 returns the hex SHA-256 of a file's contents.
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
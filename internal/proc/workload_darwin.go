@@ -0,0 +1,29 @@
+package proc
+
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
+
+func peerWorkload(fd int) (*Workload, error) {
+	cred, err := unix.GetsockoptXucred(fd, unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := unix.GetsockoptInt(fd, unix.SOL_LOCAL, unix.LOCAL_PEERPID)
+	if err != nil {
+		return nil, err
+	}
+	w := &Workload{PID: pid, UID: int(cred.Uid)}
+	// kern.procargs2 is argc followed by the NUL-terminated executable path.
+	if args, err := unix.SysctlRaw("kern.procargs2", pid); err == nil && len(args) > 4 {
+		path := args[4:]
+		if i := bytes.IndexByte(path, 0); i >= 0 {
+			path = path[:i]
+		}
+		w.Exe = string(path)
+	}
+	w.image = w.Exe
+	return w, nil
+}
@@ -0,0 +1,35 @@
+package proc
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// containerIDPattern matches the 64-hex container ID that Docker, containerd,
+// and Podman put in a process's cgroup path.
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+func peerWorkload(fd int) (*Workload, error) {
+	cred, err := unix.GetsockoptUcred(fd, unix.SOL_SOCKET, unix.SO_PEERCRED)
+	if err != nil {
+		return nil, err
+	}
+	procDir := "/proc/" + strconv.Itoa(int(cred.Pid))
+	w := &Workload{
+		PID: int(cred.Pid),
+		UID: int(cred.Uid),
+		// /proc/<pid>/exe opens the image the process is running, even if
+		// the file at its path has since been replaced.
+		image: procDir + "/exe",
+	}
+	w.Exe, _ = os.Readlink(w.image)
+	if cgroup, err := os.ReadFile(procDir + "/cgroup"); err == nil {
+		if ids := containerIDPattern.FindAll(cgroup, -1); len(ids) > 0 {
+			w.ContainerID = string(ids[len(ids)-1])
+		}
+	}
+	return w, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package proc
+
+import "errors"
+
+func peerWorkload(fd int) (*Workload, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,163 @@
+// Package proxy is a local HTTP forward proxy that adds vault values to the
+// requests it forwards, so the process behind it holds only the proxy's
+// address, never the secret itself.
+//
+// The process sends plain http:// requests through the proxy (HTTP_PROXY),
+// and the proxy connects to the host over HTTPS. A tunneled https:// request
+// (CONNECT) is refused: its headers are encrypted end to end, so nothing
+// could be added to them.
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidRule is returned by ParseRule for a malformed rule.
+var ErrInvalidRule = errors.New("invalid rule: want host:Header=[prefix ]field, e.g. api.stripe.com:Authorization=secrets.stripe_key")
+
+// Rule adds a field's value, after an optional prefix such as "Bearer ", as
+// a header on requests to a host.
+type Rule struct {
+	Host   string // host, or host:port to match that port only
+	Header string
+	Prefix string
+	Field  string
+}
+
+// ParseRule reads a rule written host:Header=field, or host:Header=prefix
+// field to put text such as "Bearer" before the value.
+func ParseRule(s string) (Rule, error) {
+	left, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return Rule{}, ErrInvalidRule
+	}
+	i := strings.LastIndex(left, ":")
+	if i <= 0 || i == len(left)-1 {
+		return Rule{}, ErrInvalidRule
+	}
+	r := Rule{Host: strings.ToLower(left[:i]), Header: http.CanonicalHeaderKey(left[i+1:])}
+	value = strings.TrimSpace(value)
+	if j := strings.LastIndex(value, " "); j >= 0 {
+		r.Prefix, r.Field = value[:j+1], value[j+1:]
+	} else {
+		r.Field = value
+	}
+	if r.Field == "" || strings.ContainsAny(r.Header, " \t\r\n") {
+		return Rule{}, ErrInvalidRule
+	}
+	return r, nil
+}
+
+func (r Rule) String() string { return r.Host + ":" + r.Header + "=" + r.Prefix + r.Field }
+
+// matches reports whether the rule applies to a request for hostport.
+func (r Rule) matches(hostport string) bool {
+	hostport = strings.ToLower(hostport)
+	if strings.Contains(r.Host, ":") {
+		return r.Host == hostport
+	}
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	return r.Host == host
+}
+
+// Proxy forwards requests to the hosts its rules name, adding their headers.
+// Requests for any other host are refused, so it is no open proxy.
+type Proxy struct {
+	Rules []Rule
+
+	// Lookup returns a field's current value. It is called on every
+	// request, so a rotated secret is picked up at once.
+	Lookup func(id string) (string, error)
+
+	// Transport sends the requests; nil means http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Logf, if set, is told of each request, never of the values added.
+	Logf func(format string, args ...any)
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		if len(p.rulesFor(r.Host)) > 0 {
+			http.Error(w, fmt.Sprintf("send http://%s through the proxy; it connects over HTTPS itself, since it can't add headers inside a tunnel", r.Host), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("no rule for %s", r.Host), http.StatusForbidden)
+		return
+	}
+	if r.URL.Host == "" {
+		http.Error(w, "this is a forward proxy: send requests with HTTP_PROXY set to its address", http.StatusBadRequest)
+		return
+	}
+	rules := p.rulesFor(r.URL.Host)
+	if len(rules) == 0 {
+		p.logf("refused %s %s: no rule", r.Method, r.URL.Host)
+		http.Error(w, fmt.Sprintf("no rule for %s", r.URL.Host), http.StatusForbidden)
+		return
+	}
+
+	headers := make(http.Header)
+	for _, rule := range rules {
+		value, err := p.Lookup(rule.Field)
+		if err != nil {
+			p.logf("failed %s %s: %s: %v", r.Method, r.URL.Host, rule.Field, err)
+			http.Error(w, fmt.Sprintf("can't read %s: %v", rule.Field, err), http.StatusBadGateway)
+			return
+		}
+		headers.Set(rule.Header, rule.Prefix+value)
+	}
+
+	// Loopback hosts are reached as asked, for local services and tests;
+	// anything else only over HTTPS.
+	scheme := "https"
+	if isLoopback(r.URL.Hostname()) {
+		scheme = r.URL.Scheme
+	}
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Scheme = scheme
+			pr.Out.Host = ""
+			for k, v := range headers {
+				pr.Out.Header[k] = v
+			}
+		},
+		Transport: p.Transport,
+	}
+	names := slices.Sorted(maps.Keys(headers))
+	p.logf("%s %s://%s%s (+%s)", r.Method, scheme, r.URL.Host, r.URL.Path, strings.Join(names, ", "))
+	rp.ServeHTTP(w, r)
+}
+
+func (p *Proxy) rulesFor(hostport string) []Rule {
+	var rules []Rule
+	for _, r := range p.Rules {
+		if r.matches(hostport) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+func (p *Proxy) logf(format string, args ...any) {
+	if p.Logf != nil {
+		p.Logf(format, args...)
+	}
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
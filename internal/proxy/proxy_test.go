@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rule
+	}{
+		{"api.stripe.com:Authorization=secrets.stripe_key", Rule{Host: "api.stripe.com", Header: "Authorization", Field: "secrets.stripe_key"}},
+		{"API.example.com:x-api-key=secrets.key", Rule{Host: "api.example.com", Header: "X-Api-Key", Field: "secrets.key"}},
+		{"localhost:8080:Authorization=Bearer secrets.token", Rule{Host: "localhost:8080", Header: "Authorization", Prefix: "Bearer ", Field: "secrets.token"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRule(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRule(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "api.stripe.com", "api.stripe.com=secrets.key", ":Authorization=secrets.key", "api.stripe.com:=secrets.key", "api.stripe.com:Authorization="} {
+		if _, err := ParseRule(bad); err != ErrInvalidRule {
+			t.Errorf("ParseRule(%q): expected ErrInvalidRule, got %v", bad, err)
+		}
+	}
+}
+
+func TestProxy_InjectsHeaders(t *testing.T) {
+	var got http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+	host := strings.TrimPrefix(upstream.URL, "http://")
+
+	rule, _ := ParseRule(host + ":Authorization=Bearer secrets.api_key")
+	values := map[string]string{"secrets.api_key": "sk_live_123"}
+	p := &Proxy{Rules: []Rule{rule}, Lookup: func(id string) (string, error) {
+		if v, ok := values[id]; ok {
+			return v, nil
+		}
+		return "", errors.New("field not found")
+	}}
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+	proxyURL, _ := url.Parse(srv.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	req, _ := http.NewRequest("GET", upstream.URL+"/v1/charges", nil)
+	req.Header.Set("Authorization", "Bearer forged")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(body) != "ok" {
+		t.Fatalf("expected 200 ok, got %d %q", resp.StatusCode, body)
+	}
+	if a := got.Get("Authorization"); a != "Bearer sk_live_123" {
+		t.Errorf("upstream got Authorization %q", a)
+	}
+
+	// Other hosts are refused: this is no open proxy.
+	resp, err = client.Get("http://127.0.0.2:1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("unruled host: expected 403, got %d", resp.StatusCode)
+	}
+
+	// A field that can't be read stops the request before it goes out.
+	values = nil
+	got = nil
+	resp, err = client.Get(upstream.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || got != nil {
+		t.Errorf("missing field: expected 502 and no upstream request, got %d", resp.StatusCode)
+	}
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,9 @@ type AuditEntry struct {
 	Scope     string
 	Action    string
 	Purpose   string
+	RequestID string   // X-Request-Id of the API call that caused the entry, if any
+	Fields    []string // IDs of the fields a read sent, when its scope is a pattern or a list
+	Bytes     int      // size of the response body a read sent
 	CreatedAt time.Time
 }
 
@@ -26,19 +30,30 @@ func (d *DB) LogAccess(entry AuditEntry) error {
 	if entry.CreatedAt.IsZero() {
 		entry.CreatedAt = time.Now()
 	}
-	_, err := d.conn.Exec(
-		`INSERT INTO vault_access_log (id, consumer, scope, action, purpose, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
-		entry.ID, entry.Consumer, entry.Scope, entry.Action, entry.Purpose,
-		entry.CreatedAt.UTC().Format(time.RFC3339),
+	entry = d.sealAudit(entry)
+	_, err := d.exec(
+		`INSERT INTO vault_access_log (id, consumer, scope, action, purpose, request_id, fields, bytes, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.Consumer, entry.Scope, entry.Action, entry.Purpose, entry.RequestID,
+		strings.Join(entry.Fields, ","), entry.Bytes, entry.CreatedAt.UTC().Format(time.RFC3339),
 	)
 	return err
 }
 
+// PruneAuditLog deletes audit entries older than before and returns how
+// many it deleted.
+func (d *DB) PruneAuditLog(before time.Time) (int64, error) {
+	result, err := d.exec("DELETE FROM vault_access_log WHERE created_at < ?", before.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 // GetAuditLog retrieves recent audit entries, newest first.
 func (d *DB) GetAuditLog(limit int) ([]AuditEntry, error) {
-	rows, err := d.conn.Query(
-		"SELECT id, consumer, scope, action, purpose, created_at FROM vault_access_log ORDER BY created_at DESC LIMIT ?",
+	rows, err := d.query(
+		"SELECT id, consumer, scope, action, purpose, request_id, fields, bytes, created_at FROM vault_access_log ORDER BY created_at DESC LIMIT ?",
 		limit,
 	)
 	if err != nil {
@@ -49,12 +64,19 @@ func (d *DB) GetAuditLog(limit int) ([]AuditEntry, error) {
 	var entries []AuditEntry
 	for rows.Next() {
 		var e AuditEntry
-		var createdAt string
-		if err := rows.Scan(&e.ID, &e.Consumer, &e.Scope, &e.Action, &e.Purpose, &createdAt); err != nil {
+		var fields, createdAt string
+		if err := rows.Scan(&e.ID, &e.Consumer, &e.Scope, &e.Action, &e.Purpose, &e.RequestID, &fields, &e.Bytes, &createdAt); err != nil {
 			return nil, err
 		}
+		if fields != "" {
+			e.Fields = strings.Split(fields, ",")
+		}
 		e.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		entries = append(entries, e)
 	}
-	return entries, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	d.openAudit(entries)
+	return entries, nil
 }
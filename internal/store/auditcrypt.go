@@ -0,0 +1,96 @@
+package store
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+)
+
+// AuditEncrypter is implemented by stores that can encrypt the scope,
+// purpose, and field IDs of audit entries, so a stolen database doesn't say
+// what was read or why.
+type AuditEncrypter interface {
+	// SetAuditKey turns encryption on with key, taking ownership of it and
+	// zeroing the one it replaces. With a nil key, as while the vault is
+	// locked, new entries are written without their scope, purpose, and
+	// fields, and stored ones are read back still encrypted.
+	SetAuditKey(key []byte)
+}
+
+// auditCipherPrefix marks an encrypted value, so entries written before
+// encryption was turned on read back as they are.
+const auditCipherPrefix = "enc:"
+
+// auditCipher encrypts audit entries for the store that embeds it. It is
+// off until SetAuditKey is first called.
+type auditCipher struct {
+	auditMu  sync.RWMutex
+	auditOn  bool
+	auditKey []byte
+}
+
+func (a *auditCipher) SetAuditKey(key []byte) {
+	a.auditMu.Lock()
+	defer a.auditMu.Unlock()
+	clear(a.auditKey)
+	a.auditOn, a.auditKey = true, key
+}
+
+// sealAudit encrypts e's scope, purpose, and field IDs, the latter as one
+// value. Without a key to encrypt them with, it drops them.
+func (a *auditCipher) sealAudit(e AuditEntry) AuditEntry {
+	a.auditMu.RLock()
+	defer a.auditMu.RUnlock()
+	if !a.auditOn {
+		return e
+	}
+	seal := func(s string) string {
+		if s == "" || a.auditKey == nil {
+			return ""
+		}
+		sealed, err := crypto.EncryptToBase64(a.auditKey, []byte(s))
+		if err != nil {
+			return ""
+		}
+		return auditCipherPrefix + sealed
+	}
+	e.Scope, e.Purpose = seal(e.Scope), seal(e.Purpose)
+	if fields := seal(strings.Join(e.Fields, ",")); fields != "" {
+		e.Fields = []string{fields}
+	} else {
+		e.Fields = nil
+	}
+	return e
+}
+
+// openAudit decrypts what sealAudit encrypted. Values it can't decrypt,
+// as while locked, are left as they are.
+func (a *auditCipher) openAudit(entries []AuditEntry) {
+	a.auditMu.RLock()
+	defer a.auditMu.RUnlock()
+	if a.auditKey == nil {
+		return
+	}
+	open := func(s string) (string, bool) {
+		sealed, ok := strings.CutPrefix(s, auditCipherPrefix)
+		if !ok {
+			return s, false
+		}
+		plaintext, err := crypto.DecryptFromBase64(a.auditKey, sealed)
+		if err != nil {
+			return s, false
+		}
+		return string(plaintext), true
+	}
+	for i := range entries {
+		e := &entries[i]
+		e.Scope, _ = open(e.Scope)
+		e.Purpose, _ = open(e.Purpose)
+		if len(e.Fields) == 1 {
+			if fields, ok := open(e.Fields[0]); ok {
+				e.Fields = strings.Split(fields, ",")
+			}
+		}
+	}
+}
@@ -2,9 +2,17 @@ package store
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 const createSchema = `
@@ -24,6 +32,9 @@ CREATE TABLE IF NOT EXISTS vault_access_log (
 	scope      TEXT NOT NULL,
 	action     TEXT NOT NULL,
 	purpose    TEXT NOT NULL DEFAULT '',
+	request_id TEXT NOT NULL DEFAULT '',
+	fields     TEXT NOT NULL DEFAULT '',
+	bytes      INTEGER NOT NULL DEFAULT 0,
 	created_at TEXT NOT NULL
 );
 
@@ -32,10 +43,53 @@ CREATE TABLE IF NOT EXISTS vault_tokens (
 	consumer   TEXT NOT NULL,
 	scope      TEXT NOT NULL,
 	expires_at TEXT NOT NULL,
-	usage      TEXT NOT NULL DEFAULT 'multi',
+	usage       TEXT NOT NULL DEFAULT 'multi',
+	created_at  TEXT NOT NULL,
+	constraints TEXT NOT NULL DEFAULT '',
+	use_day     TEXT NOT NULL DEFAULT '',
+	use_count   INTEGER NOT NULL DEFAULT 0,
+	parent      TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	labels      TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS vault_token_reads (
+	token TEXT NOT NULL,
+	key   TEXT NOT NULL,
+	day   TEXT NOT NULL,
+	count INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (token, key)
+);
+
+CREATE TABLE IF NOT EXISTS vault_field_history (
+	seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+	field_id   TEXT NOT NULL,
+	version    INTEGER NOT NULL,
+	value      TEXT NOT NULL,
+	reason     TEXT NOT NULL,
 	created_at TEXT NOT NULL
 );
 
+CREATE TABLE IF NOT EXISTS vault_field_tombstones (
+	id         TEXT PRIMARY KEY,
+	category   TEXT NOT NULL,
+	field_name TEXT NOT NULL,
+	version    INTEGER NOT NULL DEFAULT 0,
+	deleted_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS vault_emergency (
+	name         TEXT PRIMARY KEY,
+	recipient    TEXT NOT NULL,
+	code_hash    TEXT NOT NULL,
+	wait_seconds INTEGER NOT NULL,
+	bundle       TEXT NOT NULL,
+	created_at   TEXT NOT NULL,
+	status       TEXT NOT NULL DEFAULT '',
+	requested_at TEXT NOT NULL DEFAULT '',
+	decided_at   TEXT NOT NULL DEFAULT ''
+);
+
 CREATE TABLE IF NOT EXISTS vault_meta (
 	key   TEXT PRIMARY KEY,
 	value TEXT NOT NULL
@@ -45,40 +99,373 @@ CREATE INDEX IF NOT EXISTS idx_fields_category ON vault_fields(category);
 CREATE INDEX IF NOT EXISTS idx_fields_sensitivity ON vault_fields(sensitivity);
 CREATE INDEX IF NOT EXISTS idx_tokens_expires ON vault_tokens(expires_at);
 CREATE INDEX IF NOT EXISTS idx_access_log_created ON vault_access_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_field_history_field ON vault_field_history(field_id);
+CREATE INDEX IF NOT EXISTS idx_field_tombstones_deleted ON vault_field_tombstones(deleted_at);
 `
 
-// DB wraps a *sql.DB with vault-specific operations.
+// DB wraps SQLite with vault-specific operations. Reads go through a pool
+// of read-only connections and writes through a single connection of their
+// own, so agents polling the vault never queue behind the owner's edits and
+// writers never contend with each other for SQLite's lock.
 type DB struct {
-	conn *sql.DB
+	auditCipher
+
+	path  string
+	read  *sql.DB
+	write *sql.DB
+
+	// writeMu serializes writers. SQLite allows one writer at a time; queueing
+	// in-process avoids burning busy_timeout retries under concurrent traffic.
+	writeMu sync.Mutex
+
+	stmtMu sync.Mutex
+	stmts  map[stmtKey]*sql.Stmt
+}
+
+// stmtKey identifies a prepared statement, which belongs to one handle.
+type stmtKey struct {
+	conn  *sql.DB
+	query string
+}
+
+// DefaultSynchronous is the synchronous mode writes start with. In WAL mode
+// NORMAL can lose the last transactions on power loss, but never corrupts
+// the database.
+const DefaultSynchronous = "NORMAL"
+
+// SynchronousModes are the values SetSynchronous accepts, from fastest to
+// most durable.
+var SynchronousModes = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+
+// Tuner is implemented by stores whose durability per write can be traded
+// against write speed.
+type Tuner interface {
+	SetSynchronous(mode string) error
+}
+
+// readPragmas are applied to every pooled read connection via the DSN, not
+// just the first one handed out by database/sql. query_only makes a write
+// sent down the read path fail instead of racing the writer.
+var readPragmas = []string{
+	"busy_timeout(5000)",
+	"query_only(1)",
+}
+
+// writePragmas are applied to the write connection. The journal mode is
+// stored in the database file, so setting it here covers the readers too.
+func writePragmas(synchronous string) []string {
+	return []string{
+		"journal_mode(WAL)",
+		"busy_timeout(5000)",
+		"synchronous(" + synchronous + ")",
+	}
 }
 
 // Open opens or creates the vault database at the given path.
 func Open(path string) (*DB, error) {
-	conn, err := sql.Open("sqlite", path)
+	// Transactions begin IMMEDIATE, taking the write lock up front: a
+	// deferred one that reads first can't wait for the lock when it later
+	// writes, and fails with SQLITE_BUSY instead.
+	write, err := openHandle(path, writePragmas(DefaultSynchronous), "immediate")
 	if err != nil {
-		return nil, fmt.Errorf("opening database: %w", err)
+		return nil, err
 	}
+	// A single connection that stays open, so the synchronous mode and the
+	// WAL it holds aren't re-established per write.
+	write.SetMaxOpenConns(1)
+	write.SetMaxIdleConns(1)
 
-	for _, pragma := range []string{
-		"PRAGMA journal_mode=WAL",
-		"PRAGMA busy_timeout=5000",
-		"PRAGMA synchronous=NORMAL",
-	} {
-		if _, err := conn.Exec(pragma); err != nil {
-			conn.Close()
-			return nil, fmt.Errorf("setting %s: %w", pragma, err)
-		}
+	if err := migrate(write, path); err != nil {
+		write.Close()
+		return nil, err
 	}
 
-	if _, err := conn.Exec(createSchema); err != nil {
+	read, err := openHandle(path, readPragmas, "")
+	if err != nil {
+		write.Close()
+		return nil, err
+	}
+	// WAL lets readers proceed alongside the single writer; size the pool for
+	// parallel reads and keep connections warm so pragmas aren't re-run often.
+	maxConns := max(4, runtime.NumCPU())
+	read.SetMaxOpenConns(maxConns)
+	read.SetMaxIdleConns(maxConns)
+	read.SetConnMaxIdleTime(5 * time.Minute)
+
+	return &DB{path: path, read: read, write: write, stmts: make(map[stmtKey]*sql.Stmt)}, nil
+}
+
+// openHandle opens path with pragmas and, if txlock isn't empty, the given
+// BEGIN mode for transactions.
+func openHandle(path string, pragmas []string, txlock string) (*sql.DB, error) {
+	q := make(url.Values)
+	for _, p := range pragmas {
+		q.Add("_pragma", p)
+	}
+	if txlock != "" {
+		q.Set("_txlock", txlock)
+	}
+	conn, err := sql.Open("sqlite", path+"?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
 		conn.Close()
-		return nil, fmt.Errorf("creating schema: %w", err)
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return conn, nil
+}
+
+// SetSynchronous changes how hard SQLite works to get each write onto disk:
+// OFF, NORMAL, FULL, or EXTRA. FULL survives power loss at the cost of an
+// fsync per transaction.
+func (d *DB) SetSynchronous(mode string) error {
+	mode = strings.ToUpper(mode)
+	if !slices.Contains(SynchronousModes, mode) {
+		return fmt.Errorf("unknown synchronous mode %q, want one of %s", mode, strings.Join(SynchronousModes, ", "))
+	}
+	write, err := openHandle(d.path, writePragmas(mode), "immediate")
+	if err != nil {
+		return err
 	}
+	write.SetMaxOpenConns(1)
+	write.SetMaxIdleConns(1)
+
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	old := d.write
+	d.closeStmts(old)
+	d.write = write
+	return old.Close()
+}
 
-	return &DB{conn: conn}, nil
+// addedColumns lists columns introduced after a table was first created but
+// before the schema was versioned. CREATE TABLE IF NOT EXISTS leaves older
+// databases without them. Later changes are migrations.
+var addedColumns = []struct{ table, column, decl string }{
+	{"vault_access_log", "request_id", "TEXT NOT NULL DEFAULT ''"},
+	{"vault_access_log", "fields", "TEXT NOT NULL DEFAULT ''"},
+	{"vault_access_log", "bytes", "INTEGER NOT NULL DEFAULT 0"},
+	{"vault_tokens", "constraints", "TEXT NOT NULL DEFAULT ''"},
+	{"vault_tokens", "use_day", "TEXT NOT NULL DEFAULT ''"},
+	{"vault_tokens", "use_count", "INTEGER NOT NULL DEFAULT 0"},
+	{"vault_tokens", "parent", "TEXT NOT NULL DEFAULT ''"},
+	{"vault_tokens", "description", "TEXT NOT NULL DEFAULT ''"},
+	{"vault_tokens", "labels", "TEXT NOT NULL DEFAULT ''"},
+	{"vault_field_tombstones", "version", "INTEGER NOT NULL DEFAULT 0"},
 }
 
-// Close closes the database connection.
+// ensureColumn adds a column to an existing table if it is missing.
+func ensureColumn(tx *sql.Tx, table, column, decl string) error {
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
+	return err
+}
+
+// Close closes prepared statements and the database connections.
 func (d *DB) Close() error {
-	return d.conn.Close()
+	d.closeStmts(nil)
+	return errors.Join(d.read.Close(), d.write.Close())
+}
+
+// closeStmts closes the prepared statements of conn, or of both handles if
+// conn is nil.
+func (d *DB) closeStmts(conn *sql.DB) {
+	d.stmtMu.Lock()
+	defer d.stmtMu.Unlock()
+	for k, stmt := range d.stmts {
+		if conn == nil || k.conn == conn {
+			stmt.Close()
+			delete(d.stmts, k)
+		}
+	}
+}
+
+// Checkpoint copies the write-ahead log into the database file and
+// truncates it, so the log doesn't grow without bound between SQLite's own
+// checkpoints.
+func (d *DB) Checkpoint() error {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	return retryBusy(func() error {
+		_, err := d.write.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+		return err
+	})
+}
+
+// Compact runs VACUUM between checkpoints, leaving the database file
+// without free pages and the write-ahead log empty. Deleted fields, pruned
+// audit entries, and old history otherwise leave the file at its largest
+// size.
+func (d *DB) Compact(minFree float64) (bool, error) {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	if minFree > 0 {
+		var pages, free int64
+		if err := d.write.QueryRow("PRAGMA page_count").Scan(&pages); err != nil {
+			return false, err
+		}
+		if err := d.write.QueryRow("PRAGMA freelist_count").Scan(&free); err != nil {
+			return false, err
+		}
+		if pages == 0 || float64(free)/float64(pages) < minFree {
+			return false, nil
+		}
+	}
+	for _, stmt := range []string{"PRAGMA wal_checkpoint(TRUNCATE)", "VACUUM", "PRAGMA wal_checkpoint(TRUNCATE)"} {
+		if err := retryBusy(func() error { _, err := d.write.Exec(stmt); return err }); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns the problems it
+// reports, or nil if the database is intact.
+func (d *DB) IntegrityCheck() ([]string, error) {
+	rows, err := d.read.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// prepared returns a cached prepared statement for query on conn, preparing
+// it on first use.
+func (d *DB) prepared(conn *sql.DB, query string) (*sql.Stmt, error) {
+	d.stmtMu.Lock()
+	defer d.stmtMu.Unlock()
+	key := stmtKey{conn, query}
+	if stmt, ok := d.stmts[key]; ok {
+		return stmt, nil
+	}
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	d.stmts[key] = stmt
+	return stmt, nil
+}
+
+// exec runs a write statement on the write connection, under the writer
+// lock.
+func (d *DB) exec(query string, args ...any) (sql.Result, error) {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	stmt, err := d.prepared(d.write, query)
+	if err != nil {
+		return nil, err
+	}
+	var res sql.Result
+	err = retryBusy(func() error {
+		res, err = stmt.Exec(args...)
+		return err
+	})
+	return res, err
+}
+
+// writeRow runs a write statement that returns a single row, such as an
+// UPDATE ... RETURNING, and scans it into dest.
+func (d *DB) writeRow(query string, args []any, dest ...any) error {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	stmt, err := d.prepared(d.write, query)
+	if err != nil {
+		return err
+	}
+	return retryBusy(func() error { return stmt.QueryRow(args...).Scan(dest...) })
+}
+
+// transact runs fn in a transaction on the write connection, committing if
+// it returns nil. fn may run more than once if the database is busy.
+func (d *DB) transact(fn func(tx *sql.Tx) error) error {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	return retryBusy(func() error {
+		tx, err := d.write.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// query runs a read statement on the read pool.
+func (d *DB) query(query string, args ...any) (*sql.Rows, error) {
+	stmt, err := d.prepared(d.read, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(args...)
+}
+
+// queryRow runs a single-row read statement. Prepare errors fall back to an
+// unprepared query so they surface from Scan like any other query error.
+func (d *DB) queryRow(query string, args ...any) *sql.Row {
+	stmt, err := d.prepared(d.read, query)
+	if err != nil {
+		return d.read.QueryRow(query, args...)
+	}
+	return stmt.QueryRow(args...)
+}
+
+// busyRetries bounds how often a write is retried after SQLITE_BUSY, which
+// busy_timeout doesn't prevent when another process, such as an offline
+// CLI command, holds the lock for longer or a snapshot went stale.
+const busyRetries = 4
+
+// retryBusy runs fn, retrying with backoff while it fails because the
+// database is busy or locked.
+func retryBusy(fn func() error) error {
+	wait := 50 * time.Millisecond
+	for i := 0; ; i++ {
+		err := fn()
+		if i == busyRetries || !isBusy(err) {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// isBusy reports whether err is SQLITE_BUSY or SQLITE_LOCKED, including
+// their extended codes.
+func isBusy(err error) bool {
+	var e *sqlite.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	code := e.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
 }
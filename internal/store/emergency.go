@@ -0,0 +1,97 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// EmergencyContact represents a row in vault_emergency: someone who can ask
+// for a sealed bundle and receive it if the owner doesn't deny the request
+// within Wait. The bundle is encrypted to the contact's key, never the
+// vault's, so it can be released while the vault is locked.
+type EmergencyContact struct {
+	Name        string
+	Recipient   string // public key the bundle is sealed to, for display
+	CodeHash    string // SHA-256 of the access code given to the contact
+	Wait        time.Duration
+	Bundle      string // ASCII-armored age ciphertext
+	CreatedAt   time.Time
+	Status      string    // "" until a request, then pending, denied, or released
+	RequestedAt time.Time // zero if never requested
+	DecidedAt   time.Time // when the request was denied or released
+}
+
+// PutEmergencyContact inserts or replaces a contact.
+func (d *DB) PutEmergencyContact(c EmergencyContact) error {
+	_, err := d.exec(
+		`INSERT OR REPLACE INTO vault_emergency
+		 (name, recipient, code_hash, wait_seconds, bundle, created_at, status, requested_at, decided_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.Name, c.Recipient, c.CodeHash, int64(c.Wait/time.Second), c.Bundle,
+		formatTime(c.CreatedAt), c.Status, formatTime(c.RequestedAt), formatTime(c.DecidedAt),
+	)
+	return err
+}
+
+// GetEmergencyContact returns a contact, or nil if there is none by that name.
+func (d *DB) GetEmergencyContact(name string) (*EmergencyContact, error) {
+	c, err := scanEmergencyContact(d.queryRow(
+		"SELECT "+emergencyColumns+" FROM vault_emergency WHERE name = ?", name,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// ListEmergencyContacts returns all contacts by name.
+func (d *DB) ListEmergencyContacts() ([]EmergencyContact, error) {
+	rows, err := d.query("SELECT " + emergencyColumns + " FROM vault_emergency ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []EmergencyContact
+	for rows.Next() {
+		c, err := scanEmergencyContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, *c)
+	}
+	return contacts, rows.Err()
+}
+
+// DeleteEmergencyContact removes a contact. Returns the number of rows deleted.
+func (d *DB) DeleteEmergencyContact(name string) (int64, error) {
+	result, err := d.exec("DELETE FROM vault_emergency WHERE name = ?", name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const emergencyColumns = "name, recipient, code_hash, wait_seconds, bundle, created_at, status, requested_at, decided_at"
+
+func scanEmergencyContact(row interface{ Scan(...any) error }) (*EmergencyContact, error) {
+	var c EmergencyContact
+	var wait int64
+	var createdAt, requestedAt, decidedAt string
+	if err := row.Scan(&c.Name, &c.Recipient, &c.CodeHash, &wait, &c.Bundle, &createdAt, &c.Status, &requestedAt, &decidedAt); err != nil {
+		return nil, err
+	}
+	c.Wait = time.Duration(wait) * time.Second
+	c.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	c.RequestedAt, _ = time.Parse(time.RFC3339, requestedAt)
+	c.DecidedAt, _ = time.Parse(time.RFC3339, decidedAt)
+	return &c, nil
+}
+
+// formatTime formats t for a TEXT column, with the zero time as "".
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,661 @@
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+)
+
+// ErrSealed is returned by an encrypted store that has not been unsealed.
+var ErrSealed = errors.New("store is sealed")
+
+// Sealer is implemented by stores that encrypt their entire contents at rest.
+// Such a store serves only its plaintext header until Unseal is called with
+// the database key, and drops all decrypted state on Seal.
+type Sealer interface {
+	Unseal(key []byte) error
+	Seal()
+}
+
+// headerKeys are the meta keys needed to unlock a vault. They stay readable
+// while sealed; everything else lives in the encrypted body.
+var headerKeys = map[string]bool{
+	"salt":            true,
+	"secret_key_hash": true,
+	"verification":    true,
+}
+
+// encryptedFileFormat is the on-disk layout of an EncryptedFile.
+type encryptedFileFormat struct {
+	Version int               `json:"version"`
+	Header  map[string]string `json:"header"`
+	Body    string            `json:"body,omitempty"` // base64(nonce || AES-256-GCM(snapshot))
+}
+
+// snapshot is the plaintext body: the full contents of the in-memory store.
+type snapshot struct {
+	Meta    map[string]string   `json:"meta"`
+	Fields  map[string]Field    `json:"fields"`
+	Tokens  map[string]Token    `json:"tokens"`
+	Uses    map[string]tokenUse `json:"uses,omitempty"`
+	History []FieldHistory      `json:"history,omitempty"`
+	Audit   []AuditEntry        `json:"audit,omitempty"` // before the audit log moved to its own file
+
+	Emergency  map[string]EmergencyContact `json:"emergency,omitempty"`
+	Tombstones map[string]Tombstone        `json:"tombstones,omitempty"`
+
+	Reads map[string]map[string]tokenUse `json:"reads,omitempty"` // per-field read counters, by token
+}
+
+// EncryptedFile is a Store that keeps the whole database — field IDs,
+// categories, sensitivity, and tokens — in one AES-256-GCM encrypted file.
+// Every write re-encrypts and atomically replaces the file, which is fine at
+// personal-vault sizes. Audit entries, written far more often than anything
+// else, are appended one encrypted line each to a log beside it instead.
+type EncryptedFile struct {
+	mu     sync.RWMutex
+	path   string
+	header map[string]string
+	body   string  // ciphertext as last read or written
+	key    []byte  // nil while sealed
+	mem    *Memory // nil while sealed
+
+	// pending holds audit entries logged while sealed, until Unseal can
+	// encrypt them. They are lost if the process exits first.
+	pending []AuditEntry
+}
+
+// maxPendingAudit bounds the audit entries held while sealed, so a flood of
+// refused requests against a locked vault can't grow memory without limit.
+// The oldest are dropped first.
+const maxPendingAudit = 10000
+
+// EncryptedAuditLogPath is where the audit log of the encrypted database at
+// path is kept.
+func EncryptedAuditLogPath(path string) string {
+	return path + ".audit"
+}
+
+// OpenEncrypted opens or creates an encrypted database file at path.
+func OpenEncrypted(path string) (*EncryptedFile, error) {
+	e := &EncryptedFile{path: path, header: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return e, e.writeLocked()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading encrypted database: %w", err)
+	}
+	var f encryptedFileFormat
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing encrypted database: %w", err)
+	}
+	if f.Version != 1 {
+		return nil, fmt.Errorf("unsupported encrypted database version %d", f.Version)
+	}
+	if f.Header != nil {
+		e.header = f.Header
+	}
+	e.body = f.Body
+	return e, nil
+}
+
+// Unseal decrypts the body with key. A fresh file starts with an empty body.
+func (e *EncryptedFile) Unseal(key []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mem := NewMemory()
+	if e.body != "" {
+		plaintext, err := crypto.DecryptFromBase64(key, e.body)
+		if err != nil {
+			return fmt.Errorf("unseal database: %w", err)
+		}
+		var snap snapshot
+		err = json.Unmarshal(plaintext, &snap)
+		clear(plaintext)
+		if err != nil {
+			return fmt.Errorf("unseal database: %w", err)
+		}
+		if snap.Meta != nil {
+			mem.meta = snap.Meta
+		}
+		if snap.Fields != nil {
+			mem.fields = snap.Fields
+		}
+		if snap.Tokens != nil {
+			mem.tokens = snap.Tokens
+		}
+		if snap.Uses != nil {
+			mem.uses = snap.Uses
+		}
+		if snap.Reads != nil {
+			mem.reads = snap.Reads
+		}
+		if snap.Emergency != nil {
+			mem.emergency = snap.Emergency
+		}
+		if snap.Tombstones != nil {
+			mem.tombstones = snap.Tombstones
+		}
+		mem.history = snap.History
+		mem.audit = snap.Audit
+	}
+	for k, v := range e.header {
+		mem.meta[k] = v
+	}
+
+	logged, err := e.readAuditLog(key)
+	if errors.Is(err, os.ErrNotExist) {
+		// A file from before the audit log moved out of it: carry its
+		// entries over before the next write drops them from the body.
+		if err := writeAuditLog(EncryptedAuditLogPath(e.path), key, mem.audit); err != nil {
+			return fmt.Errorf("unseal database: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("unseal database: %w", err)
+	} else {
+		mem.audit = logged
+	}
+
+	e.key = make([]byte, len(key))
+	copy(e.key, key)
+	e.mem = mem
+
+	pending := e.pending
+	e.pending = nil
+	for _, entry := range pending {
+		if err := e.logLocked(entry); err != nil {
+			return fmt.Errorf("unseal database: %w", err)
+		}
+	}
+	return nil
+}
+
+// Seal zeroes the database key and discards decrypted state.
+func (e *EncryptedFile) Seal() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	clear(e.key)
+	e.key = nil
+	e.mem = nil
+}
+
+// writeLocked serializes the current state to disk. Callers hold e.mu.
+func (e *EncryptedFile) writeLocked() error {
+	if e.mem != nil {
+		e.mem.mu.RLock()
+		plaintext, err := json.Marshal(snapshot{
+			Meta:    e.mem.meta,
+			Fields:  e.mem.fields,
+			Tokens:  e.mem.tokens,
+			Uses:    e.mem.uses,
+			History: e.mem.history,
+
+			Emergency:  e.mem.emergency,
+			Tombstones: e.mem.tombstones,
+
+			Reads: e.mem.reads,
+		})
+		e.mem.mu.RUnlock()
+		if err != nil {
+			return err
+		}
+		body, err := crypto.EncryptToBase64(e.key, plaintext)
+		clear(plaintext)
+		if err != nil {
+			return fmt.Errorf("encrypt database: %w", err)
+		}
+		e.body = body
+	}
+
+	data, err := json.Marshal(encryptedFileFormat{Version: 1, Header: e.header, Body: e.body})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(e.path), ".vault-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), e.path)
+}
+
+// read runs fn against the unsealed state.
+func (e *EncryptedFile) read(fn func(m *Memory) error) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.mem == nil {
+		return ErrSealed
+	}
+	return fn(e.mem)
+}
+
+// write runs fn against the unsealed state and persists the result.
+func (e *EncryptedFile) write(fn func(m *Memory) error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.mem == nil {
+		return ErrSealed
+	}
+	if err := fn(e.mem); err != nil {
+		return err
+	}
+	return e.writeLocked()
+}
+
+// SetMeta upserts a key-value pair. Header keys are writable while sealed.
+func (e *EncryptedFile) SetMeta(key, value string) error {
+	if headerKeys[key] {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.header[key] = value
+		if e.mem != nil {
+			e.mem.SetMeta(key, value)
+		}
+		return e.writeLocked()
+	}
+	return e.write(func(m *Memory) error { return m.SetMeta(key, value) })
+}
+
+// GetMeta retrieves a value by key. Header keys are readable while sealed.
+func (e *EncryptedFile) GetMeta(key string) (string, error) {
+	if headerKeys[key] {
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+		return e.header[key], nil
+	}
+	var v string
+	err := e.read(func(m *Memory) (err error) { v, err = m.GetMeta(key); return })
+	return v, err
+}
+
+// IsInitialized checks if the vault has been initialized.
+func (e *EncryptedFile) IsInitialized() (bool, error) {
+	salt, err := e.GetMeta("salt")
+	return salt != "", err
+}
+
+// SetField upserts a field. If the field exists, bumps version.
+func (e *EncryptedFile) SetField(f Field) error {
+	return e.write(func(m *Memory) error { return m.SetField(f) })
+}
+
+// GetField retrieves a single field by ID (includes encrypted value).
+func (e *EncryptedFile) GetField(id string) (*Field, error) {
+	var f *Field
+	err := e.read(func(m *Memory) (err error) { f, err = m.GetField(id); return })
+	return f, err
+}
+
+// ListFields returns all field metadata (no values).
+func (e *EncryptedFile) ListFields() ([]Field, error) {
+	var fs []Field
+	err := e.read(func(m *Memory) (err error) { fs, err = m.ListFields(); return })
+	return fs, err
+}
+
+// ListFieldsByCategory returns field metadata for a category (no values).
+func (e *EncryptedFile) ListFieldsByCategory(category string) ([]Field, error) {
+	var fs []Field
+	err := e.read(func(m *Memory) (err error) { fs, err = m.ListFieldsByCategory(category); return })
+	return fs, err
+}
+
+// GetFieldsByCategory returns fields in a category (with encrypted values).
+func (e *EncryptedFile) GetFieldsByCategory(category string) ([]Field, error) {
+	var fs []Field
+	err := e.read(func(m *Memory) (err error) { fs, err = m.GetFieldsByCategory(category); return })
+	return fs, err
+}
+
+// GetAllFields returns all fields including encrypted values.
+func (e *EncryptedFile) GetAllFields() ([]Field, error) {
+	var fs []Field
+	err := e.read(func(m *Memory) (err error) { fs, err = m.GetAllFields(); return })
+	return fs, err
+}
+
+// DeleteField removes a field by ID.
+func (e *EncryptedFile) DeleteField(id string) error {
+	return e.write(func(m *Memory) error { return m.DeleteField(id) })
+}
+
+// ApplyFieldOps applies a batch of sets and deletes and persists it in one
+// file write.
+func (e *EncryptedFile) ApplyFieldOps(ops []FieldOp) error {
+	return e.write(func(m *Memory) error { return m.ApplyFieldOps(ops) })
+}
+
+// AddFieldHistory records a history entry.
+func (e *EncryptedFile) AddFieldHistory(h FieldHistory) error {
+	return e.write(func(m *Memory) error { return m.AddFieldHistory(h) })
+}
+
+// GetFieldHistory returns a field's history, newest first.
+func (e *EncryptedFile) GetFieldHistory(fieldID string) ([]FieldHistory, error) {
+	var history []FieldHistory
+	err := e.read(func(m *Memory) (err error) { history, err = m.GetFieldHistory(fieldID); return })
+	return history, err
+}
+
+// ListTombstones returns the fields deleted at or after since, oldest first.
+func (e *EncryptedFile) ListTombstones(since time.Time) ([]Tombstone, error) {
+	var tombstones []Tombstone
+	err := e.read(func(m *Memory) (err error) { tombstones, err = m.ListTombstones(since); return })
+	return tombstones, err
+}
+
+// SetSensitivity updates the sensitivity tier of a field.
+func (e *EncryptedFile) SetSensitivity(id, tier string) error {
+	return e.write(func(m *Memory) error { return m.SetSensitivity(id, tier) })
+}
+
+// FieldCount returns total number of fields.
+func (e *EncryptedFile) FieldCount() (int, error) {
+	var n int
+	err := e.read(func(m *Memory) (err error) { n, err = m.FieldCount(); return })
+	return n, err
+}
+
+// CategoryCounts returns a map of category -> field count.
+func (e *EncryptedFile) CategoryCounts() (map[string]int, error) {
+	var c map[string]int
+	err := e.read(func(m *Memory) (err error) { c, err = m.CategoryCounts(); return })
+	return c, err
+}
+
+// CreateToken inserts a new session token.
+func (e *EncryptedFile) CreateToken(t Token) error {
+	return e.write(func(m *Memory) error { return m.CreateToken(t) })
+}
+
+// GetToken retrieves a token if it exists and hasn't expired.
+func (e *EncryptedFile) GetToken(token string) (*Token, error) {
+	var t *Token
+	err := e.read(func(m *Memory) (err error) { t, err = m.GetToken(token); return })
+	return t, err
+}
+
+// DeleteToken removes a token. Returns the number of rows deleted.
+func (e *EncryptedFile) DeleteToken(token string) (int64, error) {
+	var n int64
+	err := e.write(func(m *Memory) (err error) { n, err = m.DeleteToken(token); return })
+	return n, err
+}
+
+// DeleteExpiredTokens removes expired tokens.
+func (e *EncryptedFile) DeleteExpiredTokens() (int64, error) {
+	var n int64
+	err := e.write(func(m *Memory) (err error) { n, err = m.DeleteExpiredTokens(); return })
+	return n, err
+}
+
+// DeleteAllTokens removes all tokens.
+func (e *EncryptedFile) DeleteAllTokens() (int64, error) {
+	var n int64
+	err := e.write(func(m *Memory) (err error) { n, err = m.DeleteAllTokens(); return })
+	return n, err
+}
+
+// ListTokensByUsage returns tokens with the given usage type.
+func (e *EncryptedFile) ListTokensByUsage(usage string) ([]Token, error) {
+	var ts []Token
+	err := e.read(func(m *Memory) (err error) { ts, err = m.ListTokensByUsage(usage); return })
+	return ts, err
+}
+
+// DeleteTokenByPrefix removes a token matching the given prefix.
+func (e *EncryptedFile) DeleteTokenByPrefix(prefix string) (int64, error) {
+	var n int64
+	err := e.write(func(m *Memory) (err error) { n, err = m.DeleteTokenByPrefix(prefix); return })
+	return n, err
+}
+
+// RecordTokenUse increments a token's use counter for day and returns the new count.
+func (e *EncryptedFile) RecordTokenUse(token, day string) (int, error) {
+	var n int
+	err := e.write(func(m *Memory) (err error) { n, err = m.RecordTokenUse(token, day); return })
+	return n, err
+}
+
+// RecordTokenRead increments a token's counter for key on day and returns the new count.
+func (e *EncryptedFile) RecordTokenRead(token, key, day string) (int, error) {
+	var n int
+	err := e.write(func(m *Memory) (err error) { n, err = m.RecordTokenRead(token, key, day); return })
+	return n, err
+}
+
+// LogAccess appends an audit entry to the log. While sealed the entry is
+// held until Unseal.
+func (e *EncryptedFile) LogAccess(entry AuditEntry) error {
+	if entry.ID == "" {
+		b := make([]byte, 16)
+		rand.Read(b)
+		entry.ID = hex.EncodeToString(b)
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.CreatedAt = storedTime(entry.CreatedAt)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.mem == nil {
+		if len(e.pending) == maxPendingAudit {
+			e.pending = e.pending[1:]
+		}
+		e.pending = append(e.pending, entry)
+		return nil
+	}
+	return e.logLocked(entry)
+}
+
+// logLocked appends entry to the log and the unsealed state. Callers hold
+// e.mu.
+func (e *EncryptedFile) logLocked(entry AuditEntry) error {
+	line, err := sealAuditLine(e.key, entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(EncryptedAuditLogPath(e.path), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return e.mem.LogAccess(entry)
+}
+
+// GetAuditLog retrieves recent audit entries, newest first.
+func (e *EncryptedFile) GetAuditLog(limit int) ([]AuditEntry, error) {
+	var es []AuditEntry
+	err := e.read(func(m *Memory) (err error) { es, err = m.GetAuditLog(limit); return })
+	return es, err
+}
+
+// PruneAuditLog deletes audit entries older than before and returns how
+// many it deleted. The log is rewritten without them.
+func (e *EncryptedFile) PruneAuditLog(before time.Time) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.mem == nil {
+		return 0, ErrSealed
+	}
+	n, err := e.mem.PruneAuditLog(before)
+	if err != nil || n == 0 {
+		return n, err
+	}
+	e.mem.mu.RLock()
+	kept := e.mem.audit
+	err = writeAuditLog(EncryptedAuditLogPath(e.path), e.key, kept)
+	e.mem.mu.RUnlock()
+	return n, err
+}
+
+// readAuditLog decrypts the audit log with key. A last line cut short by a
+// crash mid-append is cut off, so the next entry starts on a line of its own.
+func (e *EncryptedFile) readAuditLog(key []byte) ([]AuditEntry, error) {
+	path := EncryptedAuditLogPath(e.path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i+1 < len(data) {
+		data = data[:i+1]
+		if err := os.Truncate(path, int64(len(data))); err != nil {
+			return nil, err
+		}
+	}
+	var entries []AuditEntry
+	for line := range bytes.Lines(data) {
+		plaintext, err := crypto.DecryptFromBase64(key, string(bytes.TrimSpace(line)))
+		if err != nil {
+			return nil, fmt.Errorf("decrypt audit log: %w", err)
+		}
+		var entry AuditEntry
+		err = json.Unmarshal(plaintext, &entry)
+		clear(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("decode audit log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// sealAuditLine encrypts entry as one line of the audit log.
+func sealAuditLine(key []byte, entry AuditEntry) ([]byte, error) {
+	plaintext, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := crypto.EncryptToBase64(key, plaintext)
+	clear(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt audit entry: %w", err)
+	}
+	return append([]byte(sealed), '\n'), nil
+}
+
+// writeAuditLog atomically replaces the audit log at path with entries.
+func writeAuditLog(path string, key []byte, entries []AuditEntry) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := sealAuditLine(key, entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vault-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Close seals the store. Audit entries logged while sealed and not yet
+// written are dropped.
+func (e *EncryptedFile) Close() error {
+	e.Seal()
+	return nil
+}
+
+// PutEmergencyContact inserts or replaces a contact.
+func (e *EncryptedFile) PutEmergencyContact(c EmergencyContact) error {
+	return e.write(func(m *Memory) error { return m.PutEmergencyContact(c) })
+}
+
+// GetEmergencyContact returns a contact, or nil if there is none by that name.
+func (e *EncryptedFile) GetEmergencyContact(name string) (*EmergencyContact, error) {
+	var c *EmergencyContact
+	err := e.read(func(m *Memory) (err error) { c, err = m.GetEmergencyContact(name); return })
+	return c, err
+}
+
+// ListEmergencyContacts returns all contacts by name.
+func (e *EncryptedFile) ListEmergencyContacts() ([]EmergencyContact, error) {
+	var contacts []EmergencyContact
+	err := e.read(func(m *Memory) (err error) { contacts, err = m.ListEmergencyContacts(); return })
+	return contacts, err
+}
+
+// DeleteEmergencyContact removes a contact. Returns the number deleted.
+func (e *EncryptedFile) DeleteEmergencyContact(name string) (int64, error) {
+	var n int64
+	err := e.write(func(m *Memory) (err error) { n, err = m.DeleteEmergencyContact(name); return })
+	return n, err
+}
+
+// CheckWritable creates and removes a temporary file next to the database,
+// the same step every write starts with.
+func (e *EncryptedFile) CheckWritable() error {
+	tmp, err := os.CreateTemp(filepath.Dir(e.path), ".vault-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
+
+// Checkpoint does nothing: each write replaces the whole file, so there is
+// no log to fold in.
+func (e *EncryptedFile) Checkpoint() error {
+	return nil
+}
+
+// Compact does nothing: each write replaces the whole file with just what
+// the store holds.
+func (e *EncryptedFile) Compact(float64) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+)
+
+func TestEncryptedFile_SealedHidesEverything(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db.enc")
+	key := bytes.Repeat([]byte{7}, 32)
+
+	e, err := OpenEncrypted(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetMeta("salt", "c2FsdA==")
+	e.Unseal(key)
+	e.SetField(Field{ID: "medical.blood_type", Category: "medical", FieldName: "blood_type", Value: "ciphertext", UpdatedAt: time.Now()})
+	e.LogAccess(AuditEntry{Consumer: "cli", Scope: "medical.blood_type", Action: "write"})
+	e.Close()
+
+	raw, _ := os.ReadFile(path)
+	for _, leak := range []string{"medical", "blood_type", "cli"} {
+		if bytes.Contains(raw, []byte(leak)) {
+			t.Fatalf("on-disk file leaks %q", leak)
+		}
+	}
+
+	// Reopen: header readable, body sealed
+	e, err = OpenEncrypted(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if init, _ := e.IsInitialized(); !init {
+		t.Fatal("header should be readable while sealed")
+	}
+	if _, err := e.GetField("medical.blood_type"); err != ErrSealed {
+		t.Fatalf("expected ErrSealed, got %v", err)
+	}
+
+	if err := e.Unseal(bytes.Repeat([]byte{8}, 32)); err == nil {
+		t.Fatal("unseal with wrong key should fail")
+	}
+	if err := e.Unseal(key); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := e.GetField("medical.blood_type")
+	if f == nil || f.Value != "ciphertext" {
+		t.Fatalf("expected field to survive reopen, got %+v", f)
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Fatalf("expected 0600, got %o", info.Mode().Perm())
+	}
+}
+
+func TestEncryptedFile_AuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db.enc")
+	key := bytes.Repeat([]byte{7}, 32)
+
+	e, err := OpenEncrypted(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Unseal(key)
+	e.SetField(Field{ID: "identity.email", Category: "identity", FieldName: "email", Value: "ciphertext", UpdatedAt: time.Now()})
+	before, _ := os.ReadFile(path)
+
+	// Entries are appended to the log, leaving the database file alone.
+	e.LogAccess(AuditEntry{Consumer: "agent", Scope: "identity.email", Action: "read"})
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Fatal("logging an access rewrote the database file")
+	}
+	raw, _ := os.ReadFile(EncryptedAuditLogPath(path))
+	if bytes.Count(raw, []byte("\n")) != 1 || bytes.Contains(raw, []byte("agent")) {
+		t.Fatalf("expected one encrypted line, got %q", raw)
+	}
+
+	// Entries logged while sealed are kept until the next unseal.
+	e.Seal()
+	if err := e.LogAccess(AuditEntry{Consumer: "agent", Action: "denied"}); err != nil {
+		t.Fatalf("logging while sealed: %v", err)
+	}
+	if err := e.Unseal(key); err != nil {
+		t.Fatal(err)
+	}
+	e.Close()
+
+	// A crash mid-append leaves a partial last line, which is cut off.
+	f, _ := os.OpenFile(EncryptedAuditLogPath(path), os.O_WRONLY|os.O_APPEND, 0600)
+	f.Write([]byte("partial"))
+	f.Close()
+
+	e, err = OpenEncrypted(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Unseal(key); err != nil {
+		t.Fatal(err)
+	}
+	e.LogAccess(AuditEntry{Consumer: "cli", Action: "write"})
+	entries, _ := e.GetAuditLog(-1)
+	if len(entries) != 3 || entries[0].Consumer != "cli" || entries[1].Action != "denied" || entries[2].Scope != "identity.email" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	// Pruning rewrites the log without the old entries.
+	if n, err := e.PruneAuditLog(time.Now().Add(time.Hour)); err != nil || n != 3 {
+		t.Fatalf("expected 3 pruned, got %d, %v", n, err)
+	}
+	e.Close()
+	e, _ = OpenEncrypted(path)
+	e.Unseal(key)
+	if entries, _ := e.GetAuditLog(-1); len(entries) != 0 {
+		t.Fatalf("expected pruned entries gone after reopen, got %+v", entries)
+	}
+}
+
+func TestEncryptedFile_AuditCarriedOverFromBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db.enc")
+	key := bytes.Repeat([]byte{7}, 32)
+
+	// A file written before the audit log had its own file.
+	plaintext, _ := json.Marshal(snapshot{Audit: []AuditEntry{{ID: "1", Consumer: "cli", Action: "write", CreatedAt: time.Now()}}})
+	body, err := crypto.EncryptToBase64(key, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(encryptedFileFormat{Version: 1, Header: map[string]string{}, Body: body})
+	os.WriteFile(path, data, 0600)
+
+	e, err := OpenEncrypted(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Unseal(key); err != nil {
+		t.Fatal(err)
+	}
+	e.SetMeta("k", "v") // drops the entries from the body
+	e.Close()
+
+	e, _ = OpenEncrypted(path)
+	e.Unseal(key)
+	if entries, _ := e.GetAuditLog(-1); len(entries) != 1 || entries[0].Consumer != "cli" {
+		t.Fatalf("expected the old entry carried over, got %+v", entries)
+	}
+}
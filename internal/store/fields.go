@@ -16,9 +16,21 @@ type Field struct {
 	Version     int
 }
 
+// FieldOp is one write in an ApplyFieldOps batch. A delete uses only
+// Field.ID. History, if set, is recorded against the version the set
+// produces; its Version is ignored.
+type FieldOp struct {
+	Delete  bool
+	Field   Field
+	History *FieldHistory
+}
+
 // SetField upserts a field. If the field exists, bumps version.
 func (d *DB) SetField(f Field) error {
-	_, err := d.conn.Exec(
+	if _, err := d.exec("DELETE FROM vault_field_tombstones WHERE id = ?", f.ID); err != nil {
+		return err
+	}
+	_, err := d.exec(
 		`INSERT INTO vault_fields (id, category, field_name, value, sensitivity, updated_at, version)
 		 VALUES (?, ?, ?, ?, ?, ?, 1)
 		 ON CONFLICT(id) DO UPDATE SET
@@ -35,7 +47,7 @@ func (d *DB) SetField(f Field) error {
 func (d *DB) GetField(id string) (*Field, error) {
 	var f Field
 	var updatedAt string
-	err := d.conn.QueryRow(
+	err := d.queryRow(
 		"SELECT id, category, field_name, value, sensitivity, updated_at, version FROM vault_fields WHERE id = ?",
 		id,
 	).Scan(&f.ID, &f.Category, &f.FieldName, &f.Value, &f.Sensitivity, &updatedAt, &f.Version)
@@ -51,7 +63,7 @@ func (d *DB) GetField(id string) (*Field, error) {
 
 // ListFields returns all field metadata (no values).
 func (d *DB) ListFields() ([]Field, error) {
-	rows, err := d.conn.Query(
+	rows, err := d.query(
 		"SELECT id, category, field_name, sensitivity, updated_at, version FROM vault_fields ORDER BY category, field_name",
 	)
 	if err != nil {
@@ -74,7 +86,7 @@ func (d *DB) ListFields() ([]Field, error) {
 
 // ListFieldsByCategory returns field metadata for a category (no values).
 func (d *DB) ListFieldsByCategory(category string) ([]Field, error) {
-	rows, err := d.conn.Query(
+	rows, err := d.query(
 		"SELECT id, category, field_name, sensitivity, updated_at, version FROM vault_fields WHERE category = ? ORDER BY field_name",
 		category,
 	)
@@ -98,7 +110,7 @@ func (d *DB) ListFieldsByCategory(category string) ([]Field, error) {
 
 // GetFieldsByCategory returns fields in a category (with encrypted values).
 func (d *DB) GetFieldsByCategory(category string) ([]Field, error) {
-	rows, err := d.conn.Query(
+	rows, err := d.query(
 		"SELECT id, category, field_name, value, sensitivity, updated_at, version FROM vault_fields WHERE category = ? ORDER BY field_name",
 		category,
 	)
@@ -122,7 +134,7 @@ func (d *DB) GetFieldsByCategory(category string) ([]Field, error) {
 
 // GetAllFields returns all fields including encrypted values.
 func (d *DB) GetAllFields() ([]Field, error) {
-	rows, err := d.conn.Query(
+	rows, err := d.query(
 		"SELECT id, category, field_name, value, sensitivity, updated_at, version FROM vault_fields ORDER BY category, field_name",
 	)
 	if err != nil {
@@ -143,15 +155,83 @@ func (d *DB) GetAllFields() ([]Field, error) {
 	return fields, rows.Err()
 }
 
-// DeleteField removes a field by ID.
+// DeleteField removes a field by ID, along with its history, leaving a
+// tombstone.
 func (d *DB) DeleteField(id string) error {
-	_, err := d.conn.Exec("DELETE FROM vault_fields WHERE id = ?", id)
+	if _, err := d.exec(tombstoneSQL, time.Now().UTC().Format(time.RFC3339), id); err != nil {
+		return err
+	}
+	if _, err := d.exec("DELETE FROM vault_fields WHERE id = ?", id); err != nil {
+		return err
+	}
+	_, err := d.exec("DELETE FROM vault_field_history WHERE field_id = ?", id)
 	return err
 }
 
+// ApplyFieldOps applies sets and deletes in order inside one transaction:
+// either all of them take effect or none do.
+func (d *DB) ApplyFieldOps(ops []FieldOp) error {
+	return d.transact(func(tx *sql.Tx) error {
+		return applyFieldOps(tx, ops)
+	})
+}
+
+// applyFieldOps is ApplyFieldOps within tx.
+func applyFieldOps(tx *sql.Tx, ops []FieldOp) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, op := range ops {
+		if op.Delete {
+			if _, err := tx.Exec(tombstoneSQL, now, op.Field.ID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("DELETE FROM vault_fields WHERE id = ?", op.Field.ID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("DELETE FROM vault_field_history WHERE field_id = ?", op.Field.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		f := op.Field
+		if _, err := tx.Exec("DELETE FROM vault_field_tombstones WHERE id = ?", f.ID); err != nil {
+			return err
+		}
+		var version int
+		err := tx.QueryRow(
+			`INSERT INTO vault_fields (id, category, field_name, value, sensitivity, updated_at, version)
+			 VALUES (?, ?, ?, ?, ?, ?, 1)
+			 ON CONFLICT(id) DO UPDATE SET
+				value = excluded.value,
+				sensitivity = CASE WHEN excluded.sensitivity != '' THEN excluded.sensitivity ELSE vault_fields.sensitivity END,
+				updated_at = excluded.updated_at,
+				version = vault_fields.version + 1
+			 RETURNING version`,
+			f.ID, f.Category, f.FieldName, f.Value, f.Sensitivity, f.UpdatedAt.UTC().Format(time.RFC3339),
+		).Scan(&version)
+		if err != nil {
+			return err
+		}
+		if h := op.History; h != nil {
+			createdAt := h.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+			_, err := tx.Exec(
+				`INSERT INTO vault_field_history (field_id, version, value, reason, created_at)
+				 VALUES (?, ?, ?, ?, ?)`,
+				f.ID, version, h.Value, h.Reason, createdAt.UTC().Format(time.RFC3339),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // SetSensitivity updates the sensitivity tier of a field.
 func (d *DB) SetSensitivity(id, tier string) error {
-	_, err := d.conn.Exec(
+	_, err := d.exec(
 		"UPDATE vault_fields SET sensitivity = ?, updated_at = ? WHERE id = ?",
 		tier, time.Now().UTC().Format(time.RFC3339), id,
 	)
@@ -161,13 +241,13 @@ func (d *DB) SetSensitivity(id, tier string) error {
 // FieldCount returns total number of fields.
 func (d *DB) FieldCount() (int, error) {
 	var count int
-	err := d.conn.QueryRow("SELECT COUNT(*) FROM vault_fields").Scan(&count)
+	err := d.queryRow("SELECT COUNT(*) FROM vault_fields").Scan(&count)
 	return count, err
 }
 
 // CategoryCounts returns a map of category -> field count.
 func (d *DB) CategoryCounts() (map[string]int, error) {
-	rows, err := d.conn.Query("SELECT category, COUNT(*) FROM vault_fields GROUP BY category")
+	rows, err := d.query("SELECT category, COUNT(*) FROM vault_fields GROUP BY category")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"time"
+)
+
+// FieldHistory represents a row in vault_field_history: an earlier form of
+// a field's value, kept alongside the current one.
+type FieldHistory struct {
+	FieldID   string
+	Version   int    // the field version this entry relates to
+	Value     string // encrypted ciphertext (base64), like Field.Value
+	Reason    string // why it was kept, e.g. "normalized" for the value as entered
+	CreatedAt time.Time
+}
+
+// AddFieldHistory records a history entry.
+func (d *DB) AddFieldHistory(h FieldHistory) error {
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now()
+	}
+	_, err := d.exec(
+		`INSERT INTO vault_field_history (field_id, version, value, reason, created_at)
+		 VALUES (?, ?, ?, ?, ?)`,
+		h.FieldID, h.Version, h.Value, h.Reason, h.CreatedAt.UTC().Format(time.RFC3339),
+	)
+	return err
+}
+
+// GetFieldHistory returns a field's history, newest first.
+func (d *DB) GetFieldHistory(fieldID string) ([]FieldHistory, error) {
+	rows, err := d.query(
+		`SELECT field_id, version, value, reason, created_at FROM vault_field_history
+		 WHERE field_id = ? ORDER BY version DESC, seq DESC`,
+		fieldID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []FieldHistory
+	for rows.Next() {
+		var h FieldHistory
+		var createdAt string
+		if err := rows.Scan(&h.FieldID, &h.Version, &h.Value, &h.Reason, &createdAt); err != nil {
+			return nil, err
+		}
+		h.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
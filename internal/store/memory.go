@@ -0,0 +1,502 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process Store backed by maps. It mirrors the SQLite
+// backend's semantics (upsert versioning, second-precision UTC timestamps,
+// ordering) so vault logic can be exercised without touching disk.
+type Memory struct {
+	auditCipher
+
+	mu      sync.RWMutex
+	meta    map[string]string
+	fields  map[string]Field
+	tokens  map[string]Token
+	uses    map[string]tokenUse
+	reads   map[string]map[string]tokenUse // by token, then key
+	history []FieldHistory
+	audit   []AuditEntry
+
+	tombstones map[string]Tombstone
+
+	emergency map[string]EmergencyContact
+}
+
+// tokenUse is a token's per-day request counter.
+type tokenUse struct {
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		meta:   make(map[string]string),
+		fields: make(map[string]Field),
+		tokens: make(map[string]Token),
+		uses:   make(map[string]tokenUse),
+		reads:  make(map[string]map[string]tokenUse),
+
+		emergency:  make(map[string]EmergencyContact),
+		tombstones: make(map[string]Tombstone),
+	}
+}
+
+// storedTime matches what a round trip through an RFC3339 column yields.
+func storedTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// SetMeta upserts a key-value pair.
+func (m *Memory) SetMeta(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.meta[key] = value
+	return nil
+}
+
+// GetMeta retrieves a value by key. Returns empty string if not found.
+func (m *Memory) GetMeta(key string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.meta[key], nil
+}
+
+// IsInitialized checks if the vault has been initialized.
+func (m *Memory) IsInitialized() (bool, error) {
+	salt, _ := m.GetMeta("salt")
+	return salt != "", nil
+}
+
+// SetField upserts a field. If the field exists, bumps version.
+func (m *Memory) SetField(f Field) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setFieldLocked(f)
+	return nil
+}
+
+// setFieldLocked upserts a field and returns its new version.
+func (m *Memory) setFieldLocked(f Field) int {
+	f.UpdatedAt = storedTime(f.UpdatedAt)
+	delete(m.tombstones, f.ID)
+	if old, ok := m.fields[f.ID]; ok {
+		if f.Sensitivity == "" {
+			f.Sensitivity = old.Sensitivity
+		}
+		f.Category, f.FieldName = old.Category, old.FieldName
+		f.Version = old.Version + 1
+	} else {
+		f.Version = 1
+	}
+	m.fields[f.ID] = f
+	return f.Version
+}
+
+// GetField retrieves a single field by ID (includes encrypted value).
+func (m *Memory) GetField(id string) (*Field, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.fields[id]
+	if !ok {
+		return nil, nil
+	}
+	return &f, nil
+}
+
+// sortedFields returns fields matching keep, ordered by category then field name.
+func (m *Memory) sortedFields(keep func(Field) bool, withValues bool) []Field {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var fields []Field
+	for _, f := range m.fields {
+		if !keep(f) {
+			continue
+		}
+		if !withValues {
+			f.Value = ""
+		}
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Category != fields[j].Category {
+			return fields[i].Category < fields[j].Category
+		}
+		return fields[i].FieldName < fields[j].FieldName
+	})
+	return fields
+}
+
+func allFields(Field) bool { return true }
+
+func inCategory(category string) func(Field) bool {
+	return func(f Field) bool { return f.Category == category }
+}
+
+// ListFields returns all field metadata (no values).
+func (m *Memory) ListFields() ([]Field, error) {
+	return m.sortedFields(allFields, false), nil
+}
+
+// ListFieldsByCategory returns field metadata for a category (no values).
+func (m *Memory) ListFieldsByCategory(category string) ([]Field, error) {
+	return m.sortedFields(inCategory(category), false), nil
+}
+
+// GetFieldsByCategory returns fields in a category (with encrypted values).
+func (m *Memory) GetFieldsByCategory(category string) ([]Field, error) {
+	return m.sortedFields(inCategory(category), true), nil
+}
+
+// GetAllFields returns all fields including encrypted values.
+func (m *Memory) GetAllFields() ([]Field, error) {
+	return m.sortedFields(allFields, true), nil
+}
+
+// DeleteField removes a field by ID, along with its history.
+func (m *Memory) DeleteField(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleteFieldLocked(id)
+	return nil
+}
+
+func (m *Memory) deleteFieldLocked(id string) {
+	if f, ok := m.fields[id]; ok {
+		m.tombstones[id] = Tombstone{ID: id, Category: f.Category, FieldName: f.FieldName, Version: f.Version, DeletedAt: storedTime(time.Now())}
+	}
+	delete(m.fields, id)
+	m.history = slices.DeleteFunc(m.history, func(h FieldHistory) bool { return h.FieldID == id })
+}
+
+// ApplyFieldOps applies sets and deletes in order under one lock.
+func (m *Memory) ApplyFieldOps(ops []FieldOp) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, op := range ops {
+		if op.Delete {
+			m.deleteFieldLocked(op.Field.ID)
+			continue
+		}
+		version := m.setFieldLocked(op.Field)
+		if op.History != nil {
+			h := *op.History
+			h.FieldID, h.Version = op.Field.ID, version
+			if h.CreatedAt.IsZero() {
+				h.CreatedAt = time.Now()
+			}
+			h.CreatedAt = storedTime(h.CreatedAt)
+			m.history = append(m.history, h)
+		}
+	}
+	return nil
+}
+
+// ListTombstones returns the fields deleted at or after since, oldest first.
+func (m *Memory) ListTombstones(since time.Time) ([]Tombstone, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var tombstones []Tombstone
+	for _, t := range m.tombstones {
+		if !t.DeletedAt.Before(since) {
+			tombstones = append(tombstones, t)
+		}
+	}
+	slices.SortFunc(tombstones, func(a, b Tombstone) int {
+		if c := a.DeletedAt.Compare(b.DeletedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return tombstones, nil
+}
+
+// SetSensitivity updates the sensitivity tier of a field.
+func (m *Memory) SetSensitivity(id, tier string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f, ok := m.fields[id]; ok {
+		f.Sensitivity = tier
+		f.UpdatedAt = storedTime(time.Now())
+		m.fields[id] = f
+	}
+	return nil
+}
+
+// FieldCount returns total number of fields.
+func (m *Memory) FieldCount() (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.fields), nil
+}
+
+// CategoryCounts returns a map of category -> field count.
+func (m *Memory) CategoryCounts() (map[string]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	counts := make(map[string]int)
+	for _, f := range m.fields {
+		counts[f.Category]++
+	}
+	return counts, nil
+}
+
+// AddFieldHistory records a history entry.
+func (m *Memory) AddFieldHistory(h FieldHistory) error {
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now()
+	}
+	h.CreatedAt = storedTime(h.CreatedAt)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history = append(m.history, h)
+	return nil
+}
+
+// GetFieldHistory returns a field's history, newest first.
+func (m *Memory) GetFieldHistory(fieldID string) ([]FieldHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var history []FieldHistory
+	for i := len(m.history) - 1; i >= 0; i-- {
+		if m.history[i].FieldID == fieldID {
+			history = append(history, m.history[i])
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].Version > history[j].Version })
+	return history, nil
+}
+
+// CreateToken inserts a new session token.
+func (m *Memory) CreateToken(t Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t.Usage == "" {
+		t.Usage = "multi"
+	}
+	t.ExpiresAt = storedTime(t.ExpiresAt)
+	t.CreatedAt = storedTime(t.CreatedAt)
+	m.tokens[t.TokenStr] = t
+	return nil
+}
+
+// GetToken retrieves a token if it exists and hasn't expired.
+func (m *Memory) GetToken(token string) (*Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.tokens[token]
+	if !ok || time.Now().After(t.ExpiresAt) {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+// deleteTokens removes tokens matching match and returns how many were removed.
+func (m *Memory) deleteTokens(match func(Token) bool) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for k, t := range m.tokens {
+		if match(t) {
+			delete(m.tokens, k)
+			delete(m.uses, k)
+			delete(m.reads, k)
+			n++
+		}
+	}
+	return n
+}
+
+// DeleteToken removes a token. Returns the number of rows deleted.
+func (m *Memory) DeleteToken(token string) (int64, error) {
+	return m.deleteTokens(func(t Token) bool { return t.TokenStr == token }), nil
+}
+
+// DeleteExpiredTokens removes expired tokens.
+func (m *Memory) DeleteExpiredTokens() (int64, error) {
+	now := storedTime(time.Now())
+	return m.deleteTokens(func(t Token) bool { return t.ExpiresAt.Before(now) }), nil
+}
+
+// DeleteAllTokens removes all tokens.
+func (m *Memory) DeleteAllTokens() (int64, error) {
+	return m.deleteTokens(func(Token) bool { return true }), nil
+}
+
+// ListTokensByUsage returns tokens with the given usage type, newest first.
+func (m *Memory) ListTokensByUsage(usage string) ([]Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var tokens []Token
+	for _, t := range m.tokens {
+		if t.Usage == usage {
+			tokens = append(tokens, t)
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// DeleteTokenByPrefix removes a token matching the given prefix.
+func (m *Memory) DeleteTokenByPrefix(prefix string) (int64, error) {
+	return m.deleteTokens(func(t Token) bool { return strings.HasPrefix(t.TokenStr, prefix) }), nil
+}
+
+// RecordTokenUse increments a token's use counter for day, restarting from 1
+// when the day changes, and returns the new count.
+func (m *Memory) RecordTokenUse(token, day string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tokens[token]; !ok {
+		return 0, nil
+	}
+	u := m.uses[token]
+	if u.Day != day {
+		u = tokenUse{Day: day}
+	}
+	u.Count++
+	m.uses[token] = u
+	return u.Count, nil
+}
+
+// RecordTokenRead increments a token's counter for key on day, restarting
+// from 1 when the day changes, and returns the new count.
+func (m *Memory) RecordTokenRead(token, key, day string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tokens[token]; !ok {
+		return 0, nil
+	}
+	if m.reads[token] == nil {
+		m.reads[token] = make(map[string]tokenUse)
+	}
+	u := m.reads[token][key]
+	if u.Day != day {
+		u = tokenUse{Day: day}
+	}
+	u.Count++
+	m.reads[token][key] = u
+	return u.Count, nil
+}
+
+// LogAccess writes an audit entry.
+func (m *Memory) LogAccess(entry AuditEntry) error {
+	if entry.ID == "" {
+		b := make([]byte, 16)
+		rand.Read(b)
+		entry.ID = hex.EncodeToString(b)
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.CreatedAt = storedTime(entry.CreatedAt)
+	entry = m.sealAudit(entry)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audit = append(m.audit, entry)
+	return nil
+}
+
+// GetAuditLog retrieves recent audit entries, newest first.
+func (m *Memory) GetAuditLog(limit int) ([]AuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := make([]AuditEntry, len(m.audit))
+	for i, e := range m.audit {
+		entries[len(m.audit)-1-i] = e
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	if limit >= 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	m.openAudit(entries)
+	return entries, nil
+}
+
+// PruneAuditLog deletes audit entries older than before and returns how
+// many it deleted.
+func (m *Memory) PruneAuditLog(before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.audit)
+	m.audit = slices.DeleteFunc(m.audit, func(e AuditEntry) bool { return e.CreatedAt.Before(before) })
+	return int64(n - len(m.audit)), nil
+}
+
+// Close is a no-op for the in-memory store.
+func (m *Memory) Close() error {
+	return nil
+}
+
+// PutEmergencyContact inserts or replaces a contact.
+func (m *Memory) PutEmergencyContact(c EmergencyContact) error {
+	c.CreatedAt = storedTime(c.CreatedAt)
+	c.RequestedAt = storedTime(c.RequestedAt)
+	c.DecidedAt = storedTime(c.DecidedAt)
+	c.Wait = c.Wait.Truncate(time.Second)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emergency[c.Name] = c
+	return nil
+}
+
+// GetEmergencyContact returns a contact, or nil if there is none by that name.
+func (m *Memory) GetEmergencyContact(name string) (*EmergencyContact, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.emergency[name]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// ListEmergencyContacts returns all contacts by name.
+func (m *Memory) ListEmergencyContacts() ([]EmergencyContact, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var contacts []EmergencyContact
+	for _, c := range m.emergency {
+		contacts = append(contacts, c)
+	}
+	sort.Slice(contacts, func(i, j int) bool { return contacts[i].Name < contacts[j].Name })
+	return contacts, nil
+}
+
+// DeleteEmergencyContact removes a contact. Returns the number deleted.
+func (m *Memory) DeleteEmergencyContact(name string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.emergency[name]; !ok {
+		return 0, nil
+	}
+	delete(m.emergency, name)
+	return 1, nil
+}
+
+// CheckWritable always succeeds: memory is always writable.
+func (m *Memory) CheckWritable() error {
+	return nil
+}
+
+// Checkpoint does nothing: memory has no write-ahead log.
+func (m *Memory) Checkpoint() error {
+	return nil
+}
+
+// Compact does nothing: memory has no free space to reclaim.
+func (m *Memory) Compact(float64) (bool, error) {
+	return false, nil
+}
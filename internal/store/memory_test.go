@@ -0,0 +1,360 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// backends runs fn against every Store implementation so they stay in lockstep.
+func backends(t *testing.T, fn func(t *testing.T, s Store)) {
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		fn(t, db)
+	})
+	t.Run("memory", func(t *testing.T) {
+		fn(t, NewMemory())
+	})
+	t.Run("encrypted", func(t *testing.T) {
+		e, err := OpenEncrypted(filepath.Join(t.TempDir(), "vault.db.enc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Unseal(make([]byte, 32)); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { e.Close() })
+		fn(t, e)
+	})
+}
+
+func TestStore_FieldUpsertAndVersion(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		s.SetField(Field{ID: "identity.ssn", Category: "identity", FieldName: "ssn", Value: "v1", Sensitivity: "critical", UpdatedAt: time.Now()})
+		s.SetField(Field{ID: "identity.ssn", Category: "identity", FieldName: "ssn", Value: "v2", UpdatedAt: time.Now()})
+
+		f, err := s.GetField("identity.ssn")
+		if err != nil || f == nil {
+			t.Fatalf("expected field, got %v, %v", f, err)
+		}
+		if f.Value != "v2" || f.Version != 2 {
+			t.Fatalf("expected v2 at version 2, got %s at %d", f.Value, f.Version)
+		}
+		if f.Sensitivity != "critical" {
+			t.Fatalf("empty sensitivity should keep existing tier, got %s", f.Sensitivity)
+		}
+	})
+}
+
+func TestStore_ListOrderingAndValues(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		now := time.Now()
+		s.SetField(Field{ID: "b.z", Category: "b", FieldName: "z", Value: "1", UpdatedAt: now})
+		s.SetField(Field{ID: "a.y", Category: "a", FieldName: "y", Value: "2", UpdatedAt: now})
+		s.SetField(Field{ID: "b.a", Category: "b", FieldName: "a", Value: "3", UpdatedAt: now})
+
+		list, _ := s.ListFields()
+		want := []string{"a.y", "b.a", "b.z"}
+		if len(list) != len(want) {
+			t.Fatalf("expected %d fields, got %d", len(want), len(list))
+		}
+		for i, f := range list {
+			if f.ID != want[i] {
+				t.Fatalf("position %d: expected %s, got %s", i, want[i], f.ID)
+			}
+			if f.Value != "" {
+				t.Fatal("ListFields should not include values")
+			}
+		}
+
+		byCat, _ := s.GetFieldsByCategory("b")
+		if len(byCat) != 2 || byCat[0].Value != "3" {
+			t.Fatalf("unexpected category result: %+v", byCat)
+		}
+
+		counts, _ := s.CategoryCounts()
+		if counts["a"] != 1 || counts["b"] != 2 {
+			t.Fatalf("unexpected counts: %v", counts)
+		}
+	})
+}
+
+func TestStore_Tokens(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		now := time.Now()
+		s.CreateToken(Token{TokenStr: "live", Consumer: "c", Scope: "*", ExpiresAt: now.Add(time.Hour), Usage: "service", CreatedAt: now})
+		s.CreateToken(Token{TokenStr: "dead", Consumer: "c", Scope: "*", ExpiresAt: now.Add(-time.Hour), Usage: "service", CreatedAt: now})
+
+		if tok, _ := s.GetToken("live"); tok == nil {
+			t.Fatal("live token should be returned")
+		}
+		if tok, _ := s.GetToken("dead"); tok != nil {
+			t.Fatal("expired token should not be returned")
+		}
+		if n, _ := s.DeleteExpiredTokens(); n != 1 {
+			t.Fatalf("expected 1 expired token deleted, got %d", n)
+		}
+		if n, _ := s.DeleteTokenByPrefix("li"); n != 1 {
+			t.Fatalf("expected 1 token deleted by prefix, got %d", n)
+		}
+		if toks, _ := s.ListTokensByUsage("service"); len(toks) != 0 {
+			t.Fatalf("expected no tokens left, got %d", len(toks))
+		}
+	})
+}
+
+func TestStore_AuditNewestFirst(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		base := time.Now().Add(-time.Minute)
+		s.LogAccess(AuditEntry{Consumer: "cli", Scope: "first", Action: "read", CreatedAt: base})
+		s.LogAccess(AuditEntry{Consumer: "cli", Scope: "second", Action: "read", CreatedAt: base.Add(time.Second)})
+		s.LogAccess(AuditEntry{Consumer: "cli", Scope: "third", Action: "read", CreatedAt: base.Add(2 * time.Second)})
+
+		entries, _ := s.GetAuditLog(2)
+		if len(entries) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(entries))
+		}
+		if entries[0].Scope != "third" || entries[1].Scope != "second" {
+			t.Fatalf("expected newest first, got %s, %s", entries[0].Scope, entries[1].Scope)
+		}
+	})
+}
+
+func TestStore_PruneAuditLog(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		now := time.Now()
+		s.LogAccess(AuditEntry{Consumer: "cli", Scope: "old", Action: "read", CreatedAt: now.Add(-48 * time.Hour)})
+		s.LogAccess(AuditEntry{Consumer: "cli", Scope: "new", Action: "read", CreatedAt: now})
+
+		if n, err := s.PruneAuditLog(now.Add(-24 * time.Hour)); err != nil || n != 1 {
+			t.Fatalf("expected 1 entry pruned, got %d, %v", n, err)
+		}
+		if entries, _ := s.GetAuditLog(10); len(entries) != 1 || entries[0].Scope != "new" {
+			t.Fatalf("expected only the new entry left, got %+v", entries)
+		}
+		if err := s.Checkpoint(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestStore_Compact(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		compacted, err := s.Compact(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, sqlite := s.(*DB); compacted != sqlite {
+			t.Fatalf("expected compacted = %v, got %v", sqlite, compacted)
+		}
+		if compacted, err := s.Compact(1); err != nil || compacted {
+			t.Fatalf("expected no compaction with no free space, got %v, %v", compacted, err)
+		}
+	})
+}
+
+func TestStore_RecordTokenUse(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		now := time.Now()
+		s.CreateToken(Token{TokenStr: "tok", Consumer: "c", Scope: "*", ExpiresAt: now.Add(time.Hour), Usage: "service", CreatedAt: now, Constraints: `{"max_per_day":5}`, Parent: "root", Description: "payroll", Labels: `{"env":"prod"}`})
+
+		if tok, _ := s.GetToken("tok"); tok == nil || tok.Constraints != `{"max_per_day":5}` || tok.Parent != "root" || tok.Description != "payroll" || tok.Labels != `{"env":"prod"}` {
+			t.Fatalf("expected constraints, parent, and metadata to round-trip, got %+v", tok)
+		}
+		for want := 1; want <= 2; want++ {
+			if n, err := s.RecordTokenUse("tok", "2026-03-02"); err != nil || n != want {
+				t.Fatalf("expected count %d, got %d, %v", want, n, err)
+			}
+		}
+		if n, _ := s.RecordTokenUse("tok", "2026-03-03"); n != 1 {
+			t.Fatalf("expected count to reset on a new day, got %d", n)
+		}
+		if n, _ := s.RecordTokenUse("missing", "2026-03-03"); n != 0 {
+			t.Fatalf("expected 0 for unknown token, got %d", n)
+		}
+	})
+}
+
+func TestStore_RecordTokenRead(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		now := time.Now()
+		s.CreateToken(Token{TokenStr: "tok", Consumer: "c", Scope: "*", ExpiresAt: now.Add(time.Hour), Usage: "service", CreatedAt: now})
+
+		for want := 1; want <= 2; want++ {
+			if n, err := s.RecordTokenRead("tok", "card", "2026-03-02"); err != nil || n != want {
+				t.Fatalf("expected count %d, got %d, %v", want, n, err)
+			}
+		}
+		if n, _ := s.RecordTokenRead("tok", "ssn", "2026-03-02"); n != 1 {
+			t.Fatalf("expected a separate count per key, got %d", n)
+		}
+		if n, _ := s.RecordTokenRead("tok", "card", "2026-03-03"); n != 1 {
+			t.Fatalf("expected count to reset on a new day, got %d", n)
+		}
+		if n, _ := s.RecordTokenRead("missing", "card", "2026-03-03"); n != 0 {
+			t.Fatalf("expected 0 for unknown token, got %d", n)
+		}
+
+		// A token made again with the same hash starts from zero.
+		s.DeleteToken("tok")
+		s.CreateToken(Token{TokenStr: "tok", Consumer: "c", Scope: "*", ExpiresAt: now.Add(time.Hour), Usage: "service", CreatedAt: now})
+		if n, _ := s.RecordTokenRead("tok", "card", "2026-03-03"); n != 1 {
+			t.Fatalf("expected counters to go with the token, got %d", n)
+		}
+	})
+}
+
+func TestStore_FieldHistory(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		s.SetField(Field{ID: "identity.phone", Category: "identity", FieldName: "phone", Value: "v1", UpdatedAt: time.Now()})
+		s.AddFieldHistory(FieldHistory{FieldID: "identity.phone", Version: 1, Value: "orig1", Reason: "normalized"})
+		s.AddFieldHistory(FieldHistory{FieldID: "identity.phone", Version: 2, Value: "orig2", Reason: "normalized"})
+		s.AddFieldHistory(FieldHistory{FieldID: "identity.email", Version: 1, Value: "other", Reason: "normalized"})
+
+		history, err := s.GetFieldHistory("identity.phone")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 2 || history[0].Value != "orig2" || history[1].Value != "orig1" {
+			t.Fatalf("expected two entries newest first, got %+v", history)
+		}
+
+		s.DeleteField("identity.phone")
+		if history, _ := s.GetFieldHistory("identity.phone"); len(history) != 0 {
+			t.Fatalf("expected history to be deleted with the field, got %+v", history)
+		}
+		if history, _ := s.GetFieldHistory("identity.email"); len(history) != 1 {
+			t.Fatal("deleting one field must not touch another's history")
+		}
+	})
+}
+
+func TestStore_ApplyFieldOps(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		s.SetField(Field{ID: "financial.card_brand", Category: "financial", FieldName: "card_brand", Value: "old", Sensitivity: "sensitive", UpdatedAt: time.Now()})
+		s.SetField(Field{ID: "financial.card_cvv", Category: "financial", FieldName: "card_cvv", Value: "123", UpdatedAt: time.Now()})
+
+		err := s.ApplyFieldOps([]FieldOp{
+			{Field: Field{ID: "financial.card_number", Category: "financial", FieldName: "card_number", Value: "n", Sensitivity: "critical", UpdatedAt: time.Now()}},
+			{Field: Field{ID: "financial.card_brand", Category: "financial", FieldName: "card_brand", Value: "new", UpdatedAt: time.Now()},
+				History: &FieldHistory{Value: "orig", Reason: "normalized"}},
+			{Delete: true, Field: Field{ID: "financial.card_cvv"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if f, _ := s.GetField("financial.card_number"); f == nil || f.Value != "n" || f.Version != 1 {
+			t.Fatalf("expected new field at version 1, got %+v", f)
+		}
+		brand, _ := s.GetField("financial.card_brand")
+		if brand == nil || brand.Value != "new" || brand.Version != 2 || brand.Sensitivity != "sensitive" {
+			t.Fatalf("expected updated field to keep its tier and bump version, got %+v", brand)
+		}
+		if f, _ := s.GetField("financial.card_cvv"); f != nil {
+			t.Fatal("expected deleted field to be gone")
+		}
+		history, _ := s.GetFieldHistory("financial.card_brand")
+		if len(history) != 1 || history[0].Version != 2 || history[0].Value != "orig" {
+			t.Fatalf("expected history against the new version, got %+v", history)
+		}
+	})
+}
+
+func TestStore_Tombstones(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		start := time.Now().Add(-time.Second)
+		s.SetField(Field{ID: "identity.email", Category: "identity", FieldName: "email", Value: "e", UpdatedAt: time.Now()})
+		s.SetField(Field{ID: "identity.phone", Category: "identity", FieldName: "phone", Value: "p", UpdatedAt: time.Now()})
+		s.SetField(Field{ID: "identity.phone", Category: "identity", FieldName: "phone", Value: "p2", UpdatedAt: time.Now()})
+		s.DeleteField("identity.email")
+		s.ApplyFieldOps([]FieldOp{{Delete: true, Field: Field{ID: "identity.phone"}}})
+		s.DeleteField("identity.missing")
+
+		ts, err := s.ListTombstones(start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ts) != 2 || ts[0].ID != "identity.email" || ts[1].ID != "identity.phone" || ts[0].FieldName != "email" || ts[1].Version != 2 {
+			t.Fatalf("expected tombstones for both deleted fields, got %+v", ts)
+		}
+		if ts, _ := s.ListTombstones(time.Now().Add(time.Hour)); len(ts) != 0 {
+			t.Fatalf("expected no tombstones after the deletions, got %+v", ts)
+		}
+
+		// Setting a field again clears its tombstone.
+		s.SetField(Field{ID: "identity.email", Category: "identity", FieldName: "email", Value: "e2", UpdatedAt: time.Now()})
+		s.ApplyFieldOps([]FieldOp{{Field: Field{ID: "identity.phone", Category: "identity", FieldName: "phone", Value: "p2", UpdatedAt: time.Now()}}})
+		if ts, _ := s.ListTombstones(start); len(ts) != 0 {
+			t.Fatalf("expected tombstones cleared by set, got %+v", ts)
+		}
+	})
+}
+
+func TestStore_EmergencyContacts(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		now := time.Now()
+		c := EmergencyContact{Name: "alex", Recipient: "age1xyz", CodeHash: "h", Wait: 72 * time.Hour, Bundle: "sealed", CreatedAt: now}
+		if err := s.PutEmergencyContact(c); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.GetEmergencyContact("alex")
+		if err != nil || got == nil || got.Wait != 72*time.Hour || got.Bundle != "sealed" || got.Status != "" || !got.RequestedAt.IsZero() {
+			t.Fatalf("expected contact to round-trip, got %+v, %v", got, err)
+		}
+
+		got.Status, got.RequestedAt = "pending", now
+		s.PutEmergencyContact(*got)
+		s.PutEmergencyContact(EmergencyContact{Name: "bea", CreatedAt: now})
+		list, _ := s.ListEmergencyContacts()
+		if len(list) != 2 || list[0].Name != "alex" || list[0].Status != "pending" || list[0].RequestedAt.Unix() != now.Unix() {
+			t.Fatalf("expected two contacts with alex pending, got %+v", list)
+		}
+
+		if n, _ := s.DeleteEmergencyContact("alex"); n != 1 {
+			t.Fatalf("expected 1 deleted, got %d", n)
+		}
+		if got, _ := s.GetEmergencyContact("alex"); got != nil {
+			t.Fatalf("expected contact gone, got %+v", got)
+		}
+	})
+}
+
+func TestStore_CheckWritable(t *testing.T) {
+	backends(t, func(t *testing.T, s Store) {
+		s.SetMeta("salt", "c2FsdA==")
+		if err := s.CheckWritable(); err != nil {
+			t.Fatalf("expected a writable store, got %v", err)
+		}
+		if v, _ := s.GetMeta("salt"); v != "c2FsdA==" {
+			t.Fatalf("expected the check to leave data alone, got salt %q", v)
+		}
+	})
+}
+
+func TestCheckWritable_Fails(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if err := db.CheckWritable(); err == nil {
+		t.Fatal("expected a closed database to fail the check")
+	}
+
+	dir := filepath.Join(t.TempDir(), "vault")
+	os.Mkdir(dir, 0700)
+	e, err := OpenEncrypted(filepath.Join(dir, "vault.db.enc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(dir)
+	if err := e.CheckWritable(); err == nil {
+		t.Fatal("expected a missing directory to fail the check")
+	}
+}
@@ -4,7 +4,7 @@ import "database/sql"
 
 // SetMeta upserts a key-value pair in vault_meta.
 func (d *DB) SetMeta(key, value string) error {
-	_, err := d.conn.Exec(
+	_, err := d.exec(
 		`INSERT INTO vault_meta (key, value) VALUES (?, ?)
 		 ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
 		key, value,
@@ -15,7 +15,7 @@ func (d *DB) SetMeta(key, value string) error {
 // GetMeta retrieves a value by key. Returns empty string if not found.
 func (d *DB) GetMeta(key string) (string, error) {
 	var value string
-	err := d.conn.QueryRow("SELECT value FROM vault_meta WHERE key = ?", key).Scan(&value)
+	err := d.queryRow("SELECT value FROM vault_meta WHERE key = ?", key).Scan(&value)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil
@@ -33,3 +33,10 @@ func (d *DB) IsInitialized() (bool, error) {
 	}
 	return salt != "", nil
 }
+
+// CheckWritable rewrites the salt with itself, which takes SQLite's write
+// lock and touches the WAL, failing if the file or disk has become read-only.
+func (d *DB) CheckWritable() error {
+	_, err := d.exec("UPDATE vault_meta SET value = value WHERE key = 'salt'")
+	return err
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ErrSchemaTooNew is returned by Open for a database migrated by a newer
+// pvault, whose tables this one may not understand.
+var ErrSchemaTooNew = errors.New("database schema is newer than this pvault")
+
+// schemaVersionMetaKey holds the number of the last migration applied.
+const schemaVersionMetaKey = "schema_version"
+
+// migration is one step in the evolution of the SQLite schema. Steps are
+// applied in order, each in its own transaction, and never change once
+// released: to change the schema, append a step.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// migrations is the schema's history. Version 1 is everything from before
+// the schema was versioned; it is written to be idempotent, since older
+// vaults have some or all of it already.
+var migrations = []migration{
+	{1, "initial schema", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(createSchema); err != nil {
+			return err
+		}
+		for _, c := range addedColumns {
+			if err := ensureColumn(tx, c.table, c.column, c.decl); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+}
+
+// SchemaVersion is the schema version this pvault migrates databases to.
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrate brings the database at path up to SchemaVersion. Before changing
+// a database that already holds a vault, it copies it to
+// <path>.v<version>.bak, so a failed or regretted upgrade can be undone by
+// restoring the copy.
+func migrate(conn *sql.DB, path string) error {
+	current, existing, err := schemaVersion(conn)
+	if err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	latest := SchemaVersion()
+	if current > latest {
+		return fmt.Errorf("%w: it is at version %d, this pvault knows up to %d", ErrSchemaTooNew, current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+	if existing {
+		if err := backupDatabase(conn, fmt.Sprintf("%s.v%d.bak", path, current)); err != nil {
+			return fmt.Errorf("backing up before migrating: %w", err)
+		}
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(conn, m); err != nil {
+			return fmt.Errorf("migrating schema to version %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the database's schema version, 0 if it predates
+// versioning, and whether it has any vault tables at all.
+func schemaVersion(conn *sql.DB) (version int, existing bool, err error) {
+	var tables int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name LIKE 'vault\\_%' ESCAPE '\\'").Scan(&tables); err != nil {
+		return 0, false, err
+	}
+	if tables == 0 {
+		return 0, false, nil
+	}
+	if !tableExists(conn, "vault_meta") {
+		return 0, true, nil
+	}
+	var raw string
+	err = conn.QueryRow("SELECT value FROM vault_meta WHERE key = ?", schemaVersionMetaKey).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return 0, true, nil
+	}
+	if err != nil {
+		return 0, true, err
+	}
+	if version, err = strconv.Atoi(raw); err != nil {
+		return 0, true, fmt.Errorf("invalid schema version %q", raw)
+	}
+	return version, true, nil
+}
+
+// tableExists reports whether the database has a table called name.
+func tableExists(conn *sql.DB, name string) bool {
+	var n int
+	conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n)
+	return n > 0
+}
+
+// applyMigration runs m and records its version in one transaction, so a
+// step either completes or leaves the database as it was.
+func applyMigration(conn *sql.DB, m migration) error {
+	return retryBusy(func() error {
+		tx, err := conn.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := m.up(tx); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO vault_meta (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value",
+			schemaVersionMetaKey, strconv.Itoa(m.version),
+		); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// backupDatabase writes a consistent copy of the database to dest, readable
+// only by the owner. An existing copy is kept: it is from before an earlier
+// attempt at the same migration, which is the one worth having.
+func backupDatabase(conn *sql.DB, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return nil
+	}
+	if _, err := conn.Exec("VACUUM INTO ?", dest); err != nil {
+		return err
+	}
+	return os.Chmod(dest, 0600)
+}
@@ -0,0 +1,78 @@
+package store
+
+import "time"
+
+// Store is the persistence interface the vault depends on. *DB is the SQLite
+// implementation, EncryptedFile encrypts the whole database at rest, and
+// Memory is an in-process implementation for tests. Values passed through a
+// Store are already encrypted — backends never see plaintext.
+type Store interface {
+	// Meta
+	SetMeta(key, value string) error
+	GetMeta(key string) (string, error)
+	IsInitialized() (bool, error)
+
+	// Fields
+	SetField(f Field) error
+	GetField(id string) (*Field, error)
+	ListFields() ([]Field, error)
+	ListFieldsByCategory(category string) ([]Field, error)
+	GetFieldsByCategory(category string) ([]Field, error)
+	GetAllFields() ([]Field, error)
+	DeleteField(id string) error
+	SetSensitivity(id, tier string) error
+	ApplyFieldOps(ops []FieldOp) error
+	FieldCount() (int, error)
+	CategoryCounts() (map[string]int, error)
+	ListTombstones(since time.Time) ([]Tombstone, error)
+
+	// Field history
+	AddFieldHistory(h FieldHistory) error
+	GetFieldHistory(fieldID string) ([]FieldHistory, error)
+
+	// Tokens
+	CreateToken(t Token) error
+	GetToken(token string) (*Token, error)
+	DeleteToken(token string) (int64, error)
+	DeleteExpiredTokens() (int64, error)
+	DeleteAllTokens() (int64, error)
+	ListTokensByUsage(usage string) ([]Token, error)
+	DeleteTokenByPrefix(prefix string) (int64, error)
+	RecordTokenUse(token, day string) (int, error)
+	RecordTokenRead(token, key, day string) (int, error)
+
+	// Emergency access
+	PutEmergencyContact(c EmergencyContact) error
+	GetEmergencyContact(name string) (*EmergencyContact, error)
+	ListEmergencyContacts() ([]EmergencyContact, error)
+	DeleteEmergencyContact(name string) (int64, error)
+
+	// Audit
+	LogAccess(entry AuditEntry) error
+	GetAuditLog(limit int) ([]AuditEntry, error)
+	PruneAuditLog(before time.Time) (int64, error)
+
+	// CheckWritable reports whether the store can still persist writes,
+	// without changing anything. It works while sealed.
+	CheckWritable() error
+
+	// Checkpoint moves what a write-ahead log holds into the database file
+	// and truncates the log. Backends without one do nothing.
+	Checkpoint() error
+
+	// Compact rewrites the database without its free space if that makes up
+	// at least minFree of it (0 always compacts), and reports whether it
+	// did. Backends that never leave free space do nothing.
+	Compact(minFree float64) (bool, error)
+
+	Close() error
+}
+
+var (
+	_ Store = (*DB)(nil)
+	_ Store = (*Memory)(nil)
+	_ Store = (*EncryptedFile)(nil)
+
+	_ Sealer = (*EncryptedFile)(nil)
+	_ Tuner  = (*DB)(nil)
+)
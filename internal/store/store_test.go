@@ -1,8 +1,14 @@
 package store
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 )
@@ -23,22 +29,109 @@ func TestOpen_CreatesSchema(t *testing.T) {
 	// Verify all tables exist by querying them
 	for _, table := range []string{"vault_fields", "vault_access_log", "vault_tokens", "vault_meta"} {
 		var name string
-		err := db.conn.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&name)
+		err := db.write.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&name)
 		if err != nil {
 			t.Fatalf("table %s not found: %v", table, err)
 		}
 	}
 }
 
+func TestOpen_RecordsSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if v, _ := db.GetMeta(schemaVersionMetaKey); v != fmt.Sprint(SchemaVersion()) {
+		t.Fatalf("expected schema version %d, got %q", SchemaVersion(), v)
+	}
+	if backups, _ := filepath.Glob(filepath.Join(dir, "*.bak")); len(backups) != 0 {
+		t.Fatalf("expected no backup of a new database, got %v", backups)
+	}
+}
+
+func TestOpen_MigratesUnversionedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	// A vault from before versioning, also missing a later column.
+	old, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE vault_meta (key TEXT PRIMARY KEY, value TEXT NOT NULL)",
+		"CREATE TABLE vault_access_log (id TEXT PRIMARY KEY, consumer TEXT NOT NULL, scope TEXT NOT NULL, action TEXT NOT NULL, purpose TEXT NOT NULL DEFAULT '', created_at TEXT NOT NULL)",
+		"INSERT INTO vault_meta (key, value) VALUES ('salt', 'abc')",
+	} {
+		if _, err := old.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old.Close()
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if v, _ := db.GetMeta(schemaVersionMetaKey); v != fmt.Sprint(SchemaVersion()) {
+		t.Fatalf("expected schema version %d, got %q", SchemaVersion(), v)
+	}
+	if err := db.LogAccess(AuditEntry{Consumer: "cli", Scope: "*", Action: "read", RequestID: "r1"}); err != nil {
+		t.Fatalf("expected the missing columns added: %v", err)
+	}
+
+	backup := path + ".v0.bak"
+	info, err := os.Stat(backup)
+	if err != nil {
+		t.Fatalf("expected a backup before migrating: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Fatalf("expected backup mode 0600, got %o", info.Mode().Perm())
+	}
+	bak, err := sql.Open("sqlite", backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bak.Close()
+	var salt string
+	if err := bak.QueryRow("SELECT value FROM vault_meta WHERE key = 'salt'").Scan(&salt); err != nil || salt != "abc" {
+		t.Fatalf("expected the backup to hold the old data, got %q, %v", salt, err)
+	}
+}
+
+func TestOpen_RefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMeta(schemaVersionMetaKey, fmt.Sprint(SchemaVersion()+1))
+	db.Close()
+
+	if _, err := Open(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
 func TestOpen_WALMode(t *testing.T) {
 	db := tmpDB(t)
 	var mode string
-	db.conn.QueryRow("PRAGMA journal_mode").Scan(&mode)
+	db.read.QueryRow("PRAGMA journal_mode").Scan(&mode)
 	if mode != "wal" {
 		t.Fatalf("expected WAL mode, got %s", mode)
 	}
 }
 
+func TestIntegrityCheck(t *testing.T) {
+	db := tmpDB(t)
+	db.SetField(Field{ID: "identity.email", Category: "identity", FieldName: "email", Value: "v", UpdatedAt: time.Now()})
+	problems, err := db.IntegrityCheck()
+	if err != nil || len(problems) != 0 {
+		t.Fatalf("expected intact database, got %v, %v", problems, err)
+	}
+}
+
 func TestSetMeta_GetMeta(t *testing.T) {
 	db := tmpDB(t)
 	if err := db.SetMeta("key1", "value1"); err != nil {
@@ -294,6 +387,221 @@ func TestGetFieldsByCategory(t *testing.T) {
 	}
 }
 
+func TestOpen_PragmasOnEveryConnection(t *testing.T) {
+	db := tmpDB(t)
+	// Pin several pooled connections at once and check each one
+	conns := make([]*sql.Conn, 3)
+	for i := range conns {
+		c, err := db.read.Conn(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		conns[i] = c
+	}
+	for i, c := range conns {
+		var timeout int
+		if err := c.QueryRowContext(t.Context(), "PRAGMA busy_timeout").Scan(&timeout); err != nil {
+			t.Fatal(err)
+		}
+		if timeout != 5000 {
+			t.Fatalf("conn %d: expected busy_timeout 5000, got %d", i, timeout)
+		}
+		if _, err := c.ExecContext(t.Context(), "DELETE FROM vault_meta"); err == nil {
+			t.Fatalf("conn %d: expected read connections to refuse writes", i)
+		}
+	}
+}
+
+func TestSetSynchronous(t *testing.T) {
+	db := tmpDB(t)
+	var mode int
+	db.write.QueryRow("PRAGMA synchronous").Scan(&mode)
+	if mode != 1 {
+		t.Fatalf("expected synchronous NORMAL (1), got %d", mode)
+	}
+	if err := db.SetSynchronous("full"); err != nil {
+		t.Fatal(err)
+	}
+	db.write.QueryRow("PRAGMA synchronous").Scan(&mode)
+	if mode != 2 {
+		t.Fatalf("expected synchronous FULL (2), got %d", mode)
+	}
+	if err := db.SetMeta("k", "v"); err != nil {
+		t.Fatalf("expected writes to work after switching modes: %v", err)
+	}
+	if err := db.SetSynchronous("sometimes"); err == nil {
+		t.Fatal("expected an unknown mode to be refused")
+	}
+}
+
+func TestWrite_RetriesWhileAnotherProcessHoldsTheLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Another handle stands in for another process, e.g. an offline CLI
+	// command, holding the write lock past busy_timeout.
+	other, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(0)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	tx, err := other.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT INTO vault_meta (key, value) VALUES ('other', '1')"); err != nil {
+		t.Fatal(err)
+	}
+	db.write.Exec("PRAGMA busy_timeout = 0")
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		tx.Commit()
+	}()
+
+	if err := db.SetMeta("k", "v"); err != nil {
+		t.Fatalf("expected the write to be retried until the lock was released: %v", err)
+	}
+	if v, _ := db.GetMeta("other"); v != "1" {
+		t.Fatalf("expected the other writer's row, got %q", v)
+	}
+}
+
+func TestConcurrentWrites_NoBusy(t *testing.T) {
+	db := tmpDB(t)
+	var wg sync.WaitGroup
+	errs := make(chan error, 400)
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				id := fmt.Sprintf("c%d.f%d", w, i)
+				if err := db.SetField(Field{ID: id, Category: fmt.Sprintf("c%d", w), FieldName: fmt.Sprintf("f%d", i), Value: "v", UpdatedAt: time.Now()}); err != nil {
+					errs <- err
+				}
+				if _, err := db.GetField(id); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent access failed: %v", err)
+	}
+	if n, _ := db.FieldCount(); n != 400 {
+		t.Fatalf("expected 400 fields, got %d", n)
+	}
+}
+
+func seedFields(b *testing.B, db *DB, n int) {
+	b.Helper()
+	now := time.Now()
+	for i := range n {
+		cat := fmt.Sprintf("cat%d", i%20)
+		name := fmt.Sprintf("field_%d", i)
+		if err := db.SetField(Field{ID: cat + "." + name, Category: cat, FieldName: name, Value: "ciphertext", UpdatedAt: now}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchDB(b *testing.B) *DB {
+	b.Helper()
+	db, err := Open(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	return db
+}
+
+func BenchmarkListFields_10k(b *testing.B) {
+	db := benchDB(b)
+	seedFields(b, db, 10000)
+	for b.Loop() {
+		if _, err := db.ListFields(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetAllFields_10k(b *testing.B) {
+	db := benchDB(b)
+	seedFields(b, db, 10000)
+	for b.Loop() {
+		if _, err := db.GetAllFields(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetField_ConcurrentReads(b *testing.B) {
+	db := benchDB(b)
+	seedFields(b, db, 10000)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			id := fmt.Sprintf("cat%d.field_%d", i%20, i%10000)
+			if _, err := db.GetField(id); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkSetField_ConcurrentWrites(b *testing.B) {
+	db := benchDB(b)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			name := fmt.Sprintf("field_%d", i%1000)
+			if err := db.SetField(Field{ID: "bench." + name, Category: "bench", FieldName: name, Value: "v", UpdatedAt: time.Now()}); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
+
+func TestOpen_AddsMissingColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Audit table as created before request_id existed
+	_, err = conn.Exec(`CREATE TABLE vault_access_log (
+		id TEXT PRIMARY KEY, consumer TEXT NOT NULL, scope TEXT NOT NULL,
+		action TEXT NOT NULL, purpose TEXT NOT NULL DEFAULT '', created_at TEXT NOT NULL)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	entry := AuditEntry{Consumer: "cli", Scope: "identity.*", Action: "read", RequestID: "r1", Fields: []string{"identity.email", "identity.phone"}, Bytes: 120}
+	if err := db.LogAccess(entry); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := db.GetAuditLog(1)
+	if len(entries) != 1 || entries[0].RequestID != "r1" || !slices.Equal(entries[0].Fields, entry.Fields) || entries[0].Bytes != 120 {
+		t.Fatalf("expected request_id, fields, and bytes to round-trip, got %+v", entries)
+	}
+}
+
 // Ensure temp dir cleanup works
 func TestCleanup(t *testing.T) {
 	dir := t.TempDir()
@@ -7,21 +7,26 @@ import (
 
 // Token represents a session token.
 type Token struct {
-	TokenStr  string
-	Consumer  string
-	Scope     string
-	ExpiresAt time.Time
-	Usage     string
-	CreatedAt time.Time
+	TokenStr    string
+	Consumer    string
+	Scope       string
+	ExpiresAt   time.Time
+	Usage       string
+	CreatedAt   time.Time
+	Constraints string // JSON-encoded usage restrictions, empty if none
+	Parent      string // token this one was delegated from, empty if none
+	Description string // free text for whoever manages the token
+	Labels      string // JSON-encoded key/value labels, empty if none
 }
 
 // CreateToken inserts a new session token.
 func (d *DB) CreateToken(t Token) error {
-	_, err := d.conn.Exec(
-		`INSERT INTO vault_tokens (token, consumer, scope, expires_at, usage, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
+	_, err := d.exec(
+		`INSERT INTO vault_tokens (token, consumer, scope, expires_at, usage, created_at, constraints, parent, description, labels)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		t.TokenStr, t.Consumer, t.Scope, t.ExpiresAt.UTC().Format(time.RFC3339),
-		t.Usage, t.CreatedAt.UTC().Format(time.RFC3339),
+		t.Usage, t.CreatedAt.UTC().Format(time.RFC3339), t.Constraints, t.Parent,
+		t.Description, t.Labels,
 	)
 	return err
 }
@@ -30,10 +35,10 @@ func (d *DB) CreateToken(t Token) error {
 func (d *DB) GetToken(token string) (*Token, error) {
 	var t Token
 	var expiresAt, createdAt string
-	err := d.conn.QueryRow(
-		"SELECT token, consumer, scope, expires_at, usage, created_at FROM vault_tokens WHERE token = ?",
+	err := d.queryRow(
+		"SELECT token, consumer, scope, expires_at, usage, created_at, constraints, parent, description, labels FROM vault_tokens WHERE token = ?",
 		token,
-	).Scan(&t.TokenStr, &t.Consumer, &t.Scope, &expiresAt, &t.Usage, &createdAt)
+	).Scan(&t.TokenStr, &t.Consumer, &t.Scope, &expiresAt, &t.Usage, &createdAt, &t.Constraints, &t.Parent, &t.Description, &t.Labels)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -51,35 +56,35 @@ func (d *DB) GetToken(token string) (*Token, error) {
 
 // DeleteToken removes a token. Returns the number of rows deleted.
 func (d *DB) DeleteToken(token string) (int64, error) {
-	result, err := d.conn.Exec("DELETE FROM vault_tokens WHERE token = ?", token)
+	result, err := d.exec("DELETE FROM vault_tokens WHERE token = ?", token)
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return d.pruneTokenReads(result)
 }
 
 // DeleteExpiredTokens removes expired tokens.
 func (d *DB) DeleteExpiredTokens() (int64, error) {
-	result, err := d.conn.Exec("DELETE FROM vault_tokens WHERE expires_at < ?", time.Now().UTC().Format(time.RFC3339))
+	result, err := d.exec("DELETE FROM vault_tokens WHERE expires_at < ?", time.Now().UTC().Format(time.RFC3339))
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return d.pruneTokenReads(result)
 }
 
 // DeleteAllTokens removes all tokens.
 func (d *DB) DeleteAllTokens() (int64, error) {
-	result, err := d.conn.Exec("DELETE FROM vault_tokens")
+	result, err := d.exec("DELETE FROM vault_tokens")
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return d.pruneTokenReads(result)
 }
 
 // ListTokensByUsage returns tokens with the given usage type.
 func (d *DB) ListTokensByUsage(usage string) ([]Token, error) {
-	rows, err := d.conn.Query(
-		"SELECT token, consumer, scope, expires_at, usage, created_at FROM vault_tokens WHERE usage = ? ORDER BY created_at DESC",
+	rows, err := d.query(
+		"SELECT token, consumer, scope, expires_at, usage, created_at, constraints, parent, description, labels FROM vault_tokens WHERE usage = ? ORDER BY created_at DESC",
 		usage,
 	)
 	if err != nil {
@@ -91,7 +96,7 @@ func (d *DB) ListTokensByUsage(usage string) ([]Token, error) {
 	for rows.Next() {
 		var t Token
 		var expiresAt, createdAt string
-		if err := rows.Scan(&t.TokenStr, &t.Consumer, &t.Scope, &expiresAt, &t.Usage, &createdAt); err != nil {
+		if err := rows.Scan(&t.TokenStr, &t.Consumer, &t.Scope, &expiresAt, &t.Usage, &createdAt, &t.Constraints, &t.Parent, &t.Description, &t.Labels); err != nil {
 			return nil, err
 		}
 		t.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
@@ -103,9 +108,59 @@ func (d *DB) ListTokensByUsage(usage string) ([]Token, error) {
 
 // DeleteTokenByPrefix removes a token matching the given prefix.
 func (d *DB) DeleteTokenByPrefix(prefix string) (int64, error) {
-	result, err := d.conn.Exec("DELETE FROM vault_tokens WHERE token LIKE ?", prefix+"%")
+	result, err := d.exec("DELETE FROM vault_tokens WHERE token LIKE ?", prefix+"%")
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return d.pruneTokenReads(result)
+}
+
+// pruneTokenReads drops the read counters of tokens a delete removed, and
+// returns the delete's row count.
+func (d *DB) pruneTokenReads(deleted sql.Result) (int64, error) {
+	n, err := deleted.RowsAffected()
+	if err != nil || n == 0 {
+		return n, err
+	}
+	if _, err := d.exec("DELETE FROM vault_token_reads WHERE token NOT IN (SELECT token FROM vault_tokens)"); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// RecordTokenUse increments a token's use counter for day (YYYY-MM-DD),
+// restarting from 1 when the day changes, and returns the new count.
+func (d *DB) RecordTokenUse(token, day string) (int, error) {
+	var count int
+	err := d.writeRow(
+		`UPDATE vault_tokens SET
+			use_count = CASE WHEN use_day = ?1 THEN use_count + 1 ELSE 1 END,
+			use_day = ?1
+		 WHERE token = ?2 RETURNING use_count`,
+		[]any{day, token}, &count,
+	)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return count, err
+}
+
+// RecordTokenRead increments a token's counter for key on day (YYYY-MM-DD),
+// restarting from 1 when the day changes, and returns the new count. It
+// returns 0 for an unknown token.
+func (d *DB) RecordTokenRead(token, key, day string) (int, error) {
+	var count int
+	err := d.writeRow(
+		`INSERT INTO vault_token_reads (token, key, day, count)
+		 SELECT ?1, ?2, ?3, 1 WHERE EXISTS (SELECT 1 FROM vault_tokens WHERE token = ?1)
+		 ON CONFLICT (token, key) DO UPDATE SET
+			count = CASE WHEN day = excluded.day THEN count + 1 ELSE 1 END,
+			day = excluded.day
+		 RETURNING count`,
+		[]any{token, key, day}, &count,
+	)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return count, err
 }
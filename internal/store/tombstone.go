@@ -0,0 +1,45 @@
+package store
+
+import (
+	"time"
+)
+
+// Tombstone represents a row in vault_field_tombstones: a field that was
+// deleted, kept so an incremental export can report the deletion. Setting
+// the field again removes its tombstone.
+type Tombstone struct {
+	ID        string
+	Category  string
+	FieldName string
+	Version   int // the field's version when it was deleted
+	DeletedAt time.Time
+}
+
+// tombstoneSQL records the field about to be deleted, if it exists.
+const tombstoneSQL = `INSERT OR REPLACE INTO vault_field_tombstones (id, category, field_name, version, deleted_at)
+	SELECT id, category, field_name, version, ? FROM vault_fields WHERE id = ?`
+
+// ListTombstones returns the fields deleted at or after since, oldest first.
+func (d *DB) ListTombstones(since time.Time) ([]Tombstone, error) {
+	rows, err := d.query(
+		`SELECT id, category, field_name, version, deleted_at FROM vault_field_tombstones
+		 WHERE deleted_at >= ? ORDER BY deleted_at, id`,
+		since.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tombstones []Tombstone
+	for rows.Next() {
+		var t Tombstone
+		var deletedAt string
+		if err := rows.Scan(&t.ID, &t.Category, &t.FieldName, &t.Version, &deletedAt); err != nil {
+			return nil, err
+		}
+		t.DeletedAt, _ = time.Parse(time.RFC3339, deletedAt)
+		tombstones = append(tombstones, t)
+	}
+	return tombstones, rows.Err()
+}
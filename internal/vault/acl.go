@@ -0,0 +1,163 @@
+package vault
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+var (
+	ErrACLNotFound     = errors.New("consumer is not on the field's access lists")
+	ErrInvalidConsumer = errors.New("consumer name required")
+)
+
+const (
+	// aclsMetaKey holds the field access lists, encrypted so that they
+	// don't point a copy of the database at the fields worth protecting.
+	aclsMetaKey = "field_acls"
+
+	// aclKeyInfo is the HKDF info for the access list table key.
+	aclKeyInfo = ":acls"
+)
+
+// FieldACL names the consumers allowed or denied a field, whatever their
+// tokens' scopes. A consumer on Deny never reaches the field; if Allow is
+// non-empty, only the consumers on it do. Both apply to service tokens
+// only, after the scope check: the owner's session reaches every field.
+type FieldACL struct {
+	ID    string   `json:"id"`
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// AddACL puts consumer on the field's deny list, or its allow list, taking
+// it off the other. An alias is resolved to its target. The field needn't
+// exist yet, so a value can be protected before it is stored.
+func (v *Vault) AddACL(id, consumer string, deny bool) error {
+	if err := ValidateFieldID(id); err != nil {
+		return err
+	}
+	if consumer == "" {
+		return ErrInvalidConsumer
+	}
+	id = v.ResolveAlias(id)
+	v.acls.writeMu.Lock()
+	defer v.acls.writeMu.Unlock()
+	m, err := v.acls.load()
+	if err != nil {
+		return err
+	}
+	acl := m[id]
+	acl.ID = id
+	acl.Allow = slices.DeleteFunc(slices.Clone(acl.Allow), func(c string) bool { return c == consumer })
+	acl.Deny = slices.DeleteFunc(slices.Clone(acl.Deny), func(c string) bool { return c == consumer })
+	action := "acl_allow"
+	if deny {
+		acl.Deny = append(acl.Deny, consumer)
+		action = "acl_deny"
+	} else {
+		acl.Allow = append(acl.Allow, consumer)
+	}
+	slices.Sort(acl.Allow)
+	slices.Sort(acl.Deny)
+
+	next := maps.Clone(m)
+	next[id] = acl
+	if err := v.acls.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: action, Purpose: "consumer: " + consumer})
+	return nil
+}
+
+// RemoveACL takes consumer off the field's access lists.
+func (v *Vault) RemoveACL(id, consumer string) error {
+	id = v.ResolveAlias(id)
+	v.acls.writeMu.Lock()
+	defer v.acls.writeMu.Unlock()
+	m, err := v.acls.load()
+	if err != nil {
+		return err
+	}
+	acl, ok := m[id]
+	if !ok || (!slices.Contains(acl.Allow, consumer) && !slices.Contains(acl.Deny, consumer)) {
+		return ErrACLNotFound
+	}
+	acl.Allow = slices.DeleteFunc(slices.Clone(acl.Allow), func(c string) bool { return c == consumer })
+	acl.Deny = slices.DeleteFunc(slices.Clone(acl.Deny), func(c string) bool { return c == consumer })
+
+	next := maps.Clone(m)
+	if len(acl.Allow) == 0 && len(acl.Deny) == 0 {
+		delete(next, id)
+	} else {
+		next[id] = acl
+	}
+	if err := v.acls.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "acl_remove", Purpose: "consumer: " + consumer})
+	return nil
+}
+
+// ACLs returns the fields with access lists, sorted by ID.
+func (v *Vault) ACLs() ([]FieldACL, error) {
+	m, err := v.acls.load()
+	if err != nil {
+		return nil, err
+	}
+	acls := slices.Collect(maps.Values(m))
+	sort.Slice(acls, func(i, j int) bool { return acls[i].ID < acls[j].ID })
+	return acls, nil
+}
+
+// TokenConsumers returns the consumer of t and of every token it was
+// delegated from, nearest first, for checking access lists: a consumer
+// denied a field can't reach it through a token it delegated.
+func (v *Vault) TokenConsumers(t *store.Token) []string {
+	var consumers []string
+	for t != nil {
+		consumers = append(consumers, t.Consumer)
+		if t.Parent == "" {
+			break
+		}
+		parent, err := v.db.GetToken(t.Parent)
+		if err != nil {
+			break
+		}
+		t = parent
+	}
+	return consumers
+}
+
+// ACLAllows reports whether a service token whose delegation chain has
+// consumers (see TokenConsumers) may reach field id. A deny for any of them
+// wins; otherwise an allow list must name every one of them, since a
+// delegated child picks its own consumer name. If the lists can't be read,
+// nothing is allowed.
+func (v *Vault) ACLAllows(consumers []string, id string) bool {
+	m, err := v.acls.load()
+	if err != nil {
+		return false
+	}
+	acl, ok := m[id]
+	if !ok {
+		return true
+	}
+	for _, c := range consumers {
+		if slices.Contains(acl.Deny, c) {
+			return false
+		}
+	}
+	if len(acl.Allow) == 0 {
+		return true
+	}
+	for _, c := range consumers {
+		if !slices.Contains(acl.Allow, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package vault
+
+import (
+	"errors"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+var (
+	ErrAliasConflict = errors.New("alias is already a stored field")
+	ErrAliasChain    = errors.New("an alias can't point to or be the target of another alias")
+	ErrAliasNotFound = errors.New("alias not found")
+)
+
+const (
+	// aliasesMetaKey holds the alias table, encrypted so that alias names
+	// stay hidden in blind-index mode.
+	aliasesMetaKey = "field_aliases"
+
+	// aliasKeyInfo is the HKDF info for the alias table key. Like dbKeyInfo,
+	// the leading colon keeps it disjoint from category names.
+	aliasKeyInfo = ":aliases"
+)
+
+// FieldAlias is an alternative ID that reads and writes another field.
+type FieldAlias struct {
+	Alias  string `json:"alias"`
+	Target string `json:"target"`
+}
+
+// ResolveAlias returns the field an ID refers to: the alias target, or the
+// ID itself if it is not an alias or the vault is locked.
+func (v *Vault) ResolveAlias(id string) string {
+	m, err := v.aliases.load()
+	if err != nil {
+		return id
+	}
+	if target, ok := m[id]; ok {
+		return target
+	}
+	return id
+}
+
+// ResolveScope adds the target of every exact alias pattern in a scope, so a
+// token granted "identity.name" can read the field it stands for. Wildcard
+// patterns are left alone: "preferences.*" does not reach a field in another
+// category just because an alias to it lives under preferences.
+func (v *Vault) ResolveScope(scope string) string {
+	m, err := v.aliases.load()
+	if err != nil || len(m) == 0 {
+		return scope
+	}
+	patterns := strings.Split(scope, ",")
+	for _, p := range patterns {
+		if target, ok := m[strings.TrimSpace(p)]; ok {
+			patterns = append(patterns, target)
+		}
+	}
+	return strings.Join(patterns, ",")
+}
+
+// SetAlias makes alias another name for target. Aliases don't chain, and an
+// ID that already holds a value can't become an alias.
+func (v *Vault) SetAlias(alias, target string) error {
+	if err := ValidateFieldID(alias); err != nil {
+		return err
+	}
+	if err := ValidateFieldID(target); err != nil {
+		return err
+	}
+	if alias == target {
+		return ErrAliasChain
+	}
+	v.aliases.writeMu.Lock()
+	defer v.aliases.writeMu.Unlock()
+	m, err := v.aliases.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[target]; ok {
+		return ErrAliasChain
+	}
+	for _, t := range m {
+		if t == alias {
+			return ErrAliasChain
+		}
+	}
+	if f, err := v.db.GetField(alias); err != nil {
+		return err
+	} else if f != nil {
+		return ErrAliasConflict
+	}
+
+	next := maps.Clone(m)
+	next[alias] = target
+	if err := v.aliases.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: alias, Action: "alias"})
+	return nil
+}
+
+// DeleteAlias removes an alias. The target field is untouched.
+func (v *Vault) DeleteAlias(alias string) error {
+	v.aliases.writeMu.Lock()
+	defer v.aliases.writeMu.Unlock()
+	m, err := v.aliases.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[alias]; !ok {
+		return ErrAliasNotFound
+	}
+	next := maps.Clone(m)
+	delete(next, alias)
+	if err := v.aliases.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: alias, Action: "unalias"})
+	return nil
+}
+
+// Aliases returns all aliases sorted by alias.
+func (v *Vault) Aliases() ([]FieldAlias, error) {
+	m, err := v.aliases.load()
+	if err != nil {
+		return nil, err
+	}
+	aliases := make([]FieldAlias, 0, len(m))
+	for alias, target := range m {
+		aliases = append(aliases, FieldAlias{Alias: alias, Target: target})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Alias < aliases[j].Alias })
+	return aliases, nil
+}
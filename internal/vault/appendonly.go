@@ -0,0 +1,95 @@
+package vault
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+var (
+	ErrInvalidCategory = errors.New("invalid category: only alphanumeric, underscore, hyphen allowed")
+	ErrNotAppendOnly   = errors.New("category is not append-only")
+)
+
+const (
+	// appendOnlyMetaKey holds the append-only categories, encrypted like the
+	// other tables that name fields.
+	appendOnlyMetaKey = "append_only_categories"
+
+	// appendOnlyKeyInfo is the HKDF info for the append-only table key.
+	appendOnlyKeyInfo = ":append-only"
+)
+
+// SetAppendOnly marks a category append-only: service tokens may still add
+// fields to it, but only a session changes or deletes those already stored.
+// Marking an append-only category is a no-op.
+func (v *Vault) SetAppendOnly(category string) error {
+	if !ValidCategoryName(category) {
+		return ErrInvalidCategory
+	}
+	v.appendOnly.writeMu.Lock()
+	defer v.appendOnly.writeMu.Unlock()
+	m, err := v.appendOnly.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[category]; ok {
+		return nil
+	}
+	next := maps.Clone(m)
+	next[category] = time.Now().UTC()
+	if err := v.appendOnly.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: category + ".*", Action: "append_only"})
+	return nil
+}
+
+// ClearAppendOnly makes a category writable again.
+func (v *Vault) ClearAppendOnly(category string) error {
+	v.appendOnly.writeMu.Lock()
+	defer v.appendOnly.writeMu.Unlock()
+	m, err := v.appendOnly.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[category]; !ok {
+		return ErrNotAppendOnly
+	}
+	next := maps.Clone(m)
+	delete(next, category)
+	if err := v.appendOnly.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: category + ".*", Action: "clear_append_only"})
+	return nil
+}
+
+// AppendOnlyCategories returns the append-only categories, sorted.
+func (v *Vault) AppendOnlyCategories() ([]string, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	m, err := v.appendOnly.load()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(m)), nil
+}
+
+// AppendOnly reports whether field id is in an append-only category, and
+// which. An unreadable table counts as append-only, so a failure never
+// opens a category up.
+func (v *Vault) AppendOnly(id string) (string, bool) {
+	category, _, _ := strings.Cut(v.ResolveAlias(id), ".")
+	m, err := v.appendOnly.load()
+	if err != nil {
+		return category, true
+	}
+	_, ok := m[category]
+	return category, ok
+}
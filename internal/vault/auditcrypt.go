@@ -0,0 +1,47 @@
+package vault
+
+import (
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// auditEncryptionMeta is set on vaults created with InitOptions.EncryptAudit.
+const auditEncryptionMeta = "audit_encryption"
+
+// auditKeyInfo is the HKDF info for the audit log key. Like dbKeyInfo, the
+// leading colon keeps it disjoint from category names.
+const auditKeyInfo = ":audit"
+
+// auditEncrypter returns the store beneath any blind index, if the vault
+// encrypts its audit log and the store can.
+func auditEncrypter(db store.Store) store.AuditEncrypter {
+	if bs, ok := db.(*blindStore); ok {
+		db = bs.Store
+	}
+	if on, _ := db.GetMeta(auditEncryptionMeta); on != "1" {
+		return nil
+	}
+	ae, _ := db.(store.AuditEncrypter)
+	return ae
+}
+
+// unlockAudit hands the store the audit key derived from the vault key.
+func (v *Vault) unlockAudit(vaultKey, salt []byte) error {
+	ae := auditEncrypter(v.db)
+	if ae == nil {
+		return nil
+	}
+	key, err := crypto.DeriveSubkey(vaultKey, salt, auditKeyInfo)
+	if err != nil {
+		return err
+	}
+	ae.SetAuditKey(key)
+	return nil
+}
+
+// lockAudit takes the audit key back from the store.
+func (v *Vault) lockAudit() {
+	if ae := auditEncrypter(v.db); ae != nil {
+		ae.SetAuditKey(nil)
+	}
+}
@@ -0,0 +1,227 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// Decisions an Authorizer returns.
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+)
+
+// DefaultAuthorizeTimeout is how long a read waits for the authorizer when
+// none is configured: long enough to find a phone and tap a prompt.
+const DefaultAuthorizeTimeout = 60 * time.Second
+
+var (
+	ErrAuthorizationDenied  = errors.New("the authorizer denied this read")
+	ErrAuthorizationTimeout = errors.New("the authorizer did not answer in time")
+	ErrAuthorizationFailed  = errors.New("the authorizer could not be reached")
+)
+
+// AuthorizationRequest describes a read waiting on the authorizer. It names
+// the fields but never carries their values.
+type AuthorizationRequest struct {
+	Consumer    string    `json:"consumer"`
+	Action      string    `json:"action"` // read, category, context, export, snapshot, bootstrap, expiring, session
+	Fields      []string  `json:"fields"`
+	Sensitivity string    `json:"sensitivity"`       // the most sensitive of the fields
+	Purpose     string    `json:"purpose,omitempty"` // why the consumer says it wants them
+	RequestID   string    `json:"request_id,omitempty"`
+	Time        time.Time `json:"time"`
+	// NewConsumer is set when the authorizer is asked because the consumer
+	// isn't registered yet, whatever the fields' sensitivity.
+	NewConsumer bool `json:"new_consumer,omitempty"`
+}
+
+// Authorizer approves or refuses sensitive reads as they happen, e.g. by
+// pushing a prompt to the owner's phone, adding a second factor to each
+// access rather than to each token.
+type Authorizer interface {
+	Authorize(ctx context.Context, req AuthorizationRequest) (string, error)
+}
+
+// SetAuthorizer configures the authorizer asked before service tokens read
+// fields at minTier or above, waiting up to timeout for each answer (zero
+// means DefaultAuthorizeTimeout). A nil authorizer turns the callout off.
+func (v *Vault) SetAuthorizer(a Authorizer, minTier string, timeout time.Duration) error {
+	if a != nil && !validTiers[minTier] {
+		return ErrInvalidTier
+	}
+	if timeout == 0 {
+		timeout = DefaultAuthorizeTimeout
+	}
+	v.authMu.Lock()
+	defer v.authMu.Unlock()
+	v.authorizer = a
+	v.authMinTier = minTier
+	v.authTimeout = timeout
+	return nil
+}
+
+// SetAskNewConsumers has the authorizer also asked before a consumer that
+// isn't registered reads anything. Allowing the read registers the consumer
+// as trusted, so it is asked about once; denying leaves it unregistered.
+func (v *Vault) SetAskNewConsumers(on bool) {
+	v.authMu.Lock()
+	defer v.authMu.Unlock()
+	v.authAskNew = on
+}
+
+// AuthorizeTimeout returns how long a read may wait for the authorizer, or
+// zero if none is configured.
+func (v *Vault) AuthorizeTimeout() time.Duration {
+	v.authMu.Lock()
+	defer v.authMu.Unlock()
+	if v.authorizer == nil {
+		return 0
+	}
+	return v.authTimeout
+}
+
+// Authorize asks the authorizer whether req may go ahead, if one is
+// configured and req reaches its minimum tier or comes from a new consumer
+// while SetAskNewConsumers is on. It returns nil to allow and
+// fails closed: a deny, a timeout, and an unreachable authorizer all refuse
+// the read. Every answer is recorded in the audit log.
+func (v *Vault) Authorize(ctx context.Context, req AuthorizationRequest) error {
+	v.authMu.Lock()
+	a, minTier, timeout, askNew := v.authorizer, v.authMinTier, v.authTimeout, v.authAskNew
+	v.authMu.Unlock()
+	if a == nil {
+		return nil
+	}
+	req.NewConsumer = askNew && !v.consumerRegistered(req.Consumer)
+	if !req.NewConsumer && tierRank[req.Sensitivity] < tierRank[minTier] {
+		return nil
+	}
+	if req.Time.IsZero() {
+		req.Time = time.Now().UTC()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	decision, err := a.Authorize(ctx, req)
+	outcome := decision
+	switch {
+	case err != nil && ctx.Err() != nil:
+		outcome, err = "timeout", ErrAuthorizationTimeout
+	case err != nil:
+		slog.Warn("authorize", "err", err)
+		outcome, err = "error", ErrAuthorizationFailed
+	case decision == DecisionAllow:
+	case decision == DecisionDeny:
+		err = ErrAuthorizationDenied
+	default:
+		slog.Warn("authorize", "err", fmt.Sprintf("unknown decision %q", decision))
+		outcome, err = "error", ErrAuthorizationFailed
+	}
+	v.db.LogAccess(store.AuditEntry{
+		Consumer:  req.Consumer,
+		Scope:     strings.Join(req.Fields, ","),
+		Action:    "authorize",
+		Purpose:   outcome,
+		RequestID: req.RequestID,
+	})
+	if err == nil && req.NewConsumer {
+		if err := v.SetConsumerTrust(req.Consumer, TrustTrusted); err != nil {
+			slog.Warn("authorize", "consumer", req.Consumer, "err", err)
+		}
+	}
+	return err
+}
+
+// consumerRegistered reports whether consumer is in the registry. A registry
+// that can't be read counts as not, so the authorizer is asked.
+func (v *Vault) consumerRegistered(consumer string) bool {
+	m, err := v.consumers.load()
+	if err != nil {
+		return false
+	}
+	_, ok := m[consumer]
+	return ok
+}
+
+// authorizeResponse is the answer an authorizer sends back.
+type authorizeResponse struct {
+	Decision string `json:"decision"`
+}
+
+// HTTPAuthorizer posts each request as JSON to URL and reads the decision
+// back as {"decision": "allow" | "deny"}.
+type HTTPAuthorizer struct {
+	URL    string
+	Client *http.Client // nil means http.DefaultClient
+}
+
+func (a *HTTPAuthorizer) Authorize(ctx context.Context, r AuthorizationRequest) (string, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("authorizer returned %s", resp.Status)
+	}
+	var out authorizeResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&out); err != nil {
+		return "", fmt.Errorf("decode authorizer response: %w", err)
+	}
+	return out.Decision, nil
+}
+
+// CommandAuthorizer runs a local program with each request as JSON on stdin
+// and reads the decision as JSON from stdout.
+type CommandAuthorizer struct {
+	Path string
+	Args []string
+}
+
+func (a *CommandAuthorizer) Authorize(ctx context.Context, r AuthorizationRequest) (string, error) {
+	in, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, a.Path, a.Args...)
+	cmd.Stdin = bytes.NewReader(in)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	var resp authorizeResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", fmt.Errorf("decode authorizer output: %w", err)
+	}
+	return resp.Decision, nil
+}
@@ -0,0 +1,341 @@
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// HKDF infos for blind-index mode. Like dbKeyInfo, the leading colon keeps
+// them disjoint from category names.
+const (
+	indexKeyInfo = ":blind-index"
+	namesKeyInfo = ":names"
+)
+
+// blindStore hides field IDs, categories, and audit scopes from the
+// underlying store. Rows are keyed by an HMAC blind index of the field ID and
+// category, and the real ID is kept AES-GCM encrypted in the field_name
+// column. On disk the database reveals how many fields exist, not which.
+//
+// Keys are only present while the vault is unlocked; field operations on a
+// locked blindStore return ErrLocked. Token and meta operations pass through.
+type blindStore struct {
+	store.Store
+
+	mu       sync.RWMutex
+	indexKey []byte
+	namesKey []byte
+}
+
+func newBlindStore(inner store.Store) *blindStore {
+	return &blindStore{Store: inner}
+}
+
+// setKeys derives the index and names keys from the vault key.
+func (b *blindStore) setKeys(vaultKey, salt []byte) error {
+	indexKey, err := crypto.DeriveSubkey(vaultKey, salt, indexKeyInfo)
+	if err != nil {
+		return err
+	}
+	namesKey, err := crypto.DeriveSubkey(vaultKey, salt, namesKeyInfo)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.indexKey, b.namesKey = indexKey, namesKey
+	return nil
+}
+
+// zero discards the keys.
+func (b *blindStore) zero() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.indexKey)
+	clear(b.namesKey)
+	b.indexKey, b.namesKey = nil, nil
+}
+
+// keys returns the current keys, or ErrLocked.
+func (b *blindStore) keys() (indexKey, namesKey []byte, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.indexKey == nil {
+		return nil, nil, ErrLocked
+	}
+	return b.indexKey, b.namesKey, nil
+}
+
+func blindIndex(key []byte, kind, value string) string {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(kind + ":" + value))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// toStored replaces a field's identifying columns with their hidden forms.
+func (b *blindStore) toStored(f store.Field) (store.Field, error) {
+	indexKey, namesKey, err := b.keys()
+	if err != nil {
+		return f, err
+	}
+	sealed, err := crypto.EncryptToBase64(namesKey, []byte(f.ID))
+	if err != nil {
+		return f, err
+	}
+	f.FieldName = sealed
+	f.ID = blindIndex(indexKey, "field", f.ID)
+	f.Category = blindIndex(indexKey, "category", f.Category)
+	return f, nil
+}
+
+// fromStored recovers the plaintext ID, category, and field name of a row.
+func (b *blindStore) fromStored(f store.Field) (store.Field, error) {
+	_, namesKey, err := b.keys()
+	if err != nil {
+		return f, err
+	}
+	id, err := crypto.DecryptFromBase64(namesKey, f.FieldName)
+	if err != nil {
+		return f, fmt.Errorf("decrypt field name: %w", err)
+	}
+	parts := strings.SplitN(string(id), ".", 2)
+	if len(parts) != 2 {
+		return f, fmt.Errorf("decrypt field name: malformed id")
+	}
+	f.ID, f.Category, f.FieldName = string(id), parts[0], parts[1]
+	return f, nil
+}
+
+// fromStoredAll decodes rows and restores the category, field_name ordering
+// the blind index scrambles.
+func (b *blindStore) fromStoredAll(fields []store.Field, err error) ([]store.Field, error) {
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		if fields[i], err = b.fromStored(fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Category != fields[j].Category {
+			return fields[i].Category < fields[j].Category
+		}
+		return fields[i].FieldName < fields[j].FieldName
+	})
+	return fields, nil
+}
+
+func (b *blindStore) fieldIndex(id string) (string, error) {
+	indexKey, _, err := b.keys()
+	if err != nil {
+		return "", err
+	}
+	return blindIndex(indexKey, "field", id), nil
+}
+
+func (b *blindStore) categoryIndex(category string) (string, error) {
+	indexKey, _, err := b.keys()
+	if err != nil {
+		return "", err
+	}
+	return blindIndex(indexKey, "category", category), nil
+}
+
+func (b *blindStore) SetField(f store.Field) error {
+	stored, err := b.toStored(f)
+	if err != nil {
+		return err
+	}
+	return b.Store.SetField(stored)
+}
+
+func (b *blindStore) GetField(id string) (*store.Field, error) {
+	key, err := b.fieldIndex(id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := b.Store.GetField(key)
+	if err != nil || f == nil {
+		return f, err
+	}
+	plain, err := b.fromStored(*f)
+	if err != nil {
+		return nil, err
+	}
+	return &plain, nil
+}
+
+func (b *blindStore) ListFields() ([]store.Field, error) {
+	return b.fromStoredAll(b.Store.ListFields())
+}
+
+func (b *blindStore) GetAllFields() ([]store.Field, error) {
+	return b.fromStoredAll(b.Store.GetAllFields())
+}
+
+func (b *blindStore) ListFieldsByCategory(category string) ([]store.Field, error) {
+	key, err := b.categoryIndex(category)
+	if err != nil {
+		return nil, err
+	}
+	return b.fromStoredAll(b.Store.ListFieldsByCategory(key))
+}
+
+func (b *blindStore) GetFieldsByCategory(category string) ([]store.Field, error) {
+	key, err := b.categoryIndex(category)
+	if err != nil {
+		return nil, err
+	}
+	return b.fromStoredAll(b.Store.GetFieldsByCategory(key))
+}
+
+func (b *blindStore) DeleteField(id string) error {
+	key, err := b.fieldIndex(id)
+	if err != nil {
+		return err
+	}
+	return b.Store.DeleteField(key)
+}
+
+func (b *blindStore) AddFieldHistory(h store.FieldHistory) error {
+	key, err := b.fieldIndex(h.FieldID)
+	if err != nil {
+		return err
+	}
+	h.FieldID = key
+	return b.Store.AddFieldHistory(h)
+}
+
+func (b *blindStore) GetFieldHistory(fieldID string) ([]store.FieldHistory, error) {
+	key, err := b.fieldIndex(fieldID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := b.Store.GetFieldHistory(key)
+	for i := range history {
+		history[i].FieldID = fieldID
+	}
+	return history, err
+}
+
+func (b *blindStore) ApplyFieldOps(ops []store.FieldOp) error {
+	stored := make([]store.FieldOp, len(ops))
+	for i, op := range ops {
+		var err error
+		if op.Delete {
+			op.Field.ID, err = b.fieldIndex(op.Field.ID)
+		} else {
+			op.Field, err = b.toStored(op.Field)
+		}
+		if err != nil {
+			return err
+		}
+		stored[i] = op
+	}
+	return b.Store.ApplyFieldOps(stored)
+}
+
+// ListTombstones recovers the deleted IDs from the field_name column, like
+// fromStoredAll, and restores the deletion ordering.
+func (b *blindStore) ListTombstones(since time.Time) ([]store.Tombstone, error) {
+	tombstones, err := b.Store.ListTombstones(since)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range tombstones {
+		f, err := b.fromStored(store.Field{FieldName: t.FieldName})
+		if err != nil {
+			return nil, err
+		}
+		tombstones[i].ID, tombstones[i].Category, tombstones[i].FieldName = f.ID, f.Category, f.FieldName
+	}
+	sort.Slice(tombstones, func(i, j int) bool {
+		if !tombstones[i].DeletedAt.Equal(tombstones[j].DeletedAt) {
+			return tombstones[i].DeletedAt.Before(tombstones[j].DeletedAt)
+		}
+		return tombstones[i].ID < tombstones[j].ID
+	})
+	return tombstones, nil
+}
+
+func (b *blindStore) SetSensitivity(id, tier string) error {
+	key, err := b.fieldIndex(id)
+	if err != nil {
+		return err
+	}
+	return b.Store.SetSensitivity(key, tier)
+}
+
+// CategoryCounts groups by the decrypted category, since the stored one is a blind index.
+func (b *blindStore) CategoryCounts() (map[string]int, error) {
+	fields, err := b.ListFields()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, f := range fields {
+		counts[f.Category]++
+	}
+	return counts, nil
+}
+
+// LogAccess encrypts the scope and the field IDs a read sent, the latter as
+// one value. Entries written while locked (e.g. a service token hitting a
+// locked vault) have both dropped rather than stored in plaintext.
+func (b *blindStore) LogAccess(entry store.AuditEntry) error {
+	_, namesKey, err := b.keys()
+	if err != nil {
+		entry.Scope, entry.Fields = "", nil
+		return b.Store.LogAccess(entry)
+	}
+	if entry.Scope, err = crypto.EncryptToBase64(namesKey, []byte(entry.Scope)); err != nil {
+		return err
+	}
+	if len(entry.Fields) > 0 {
+		fields, err := crypto.EncryptToBase64(namesKey, []byte(strings.Join(entry.Fields, ",")))
+		if err != nil {
+			return err
+		}
+		entry.Fields = []string{fields}
+	}
+	return b.Store.LogAccess(entry)
+}
+
+func (b *blindStore) GetAuditLog(limit int) ([]store.AuditEntry, error) {
+	_, namesKey, err := b.keys()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := b.Store.GetAuditLog(limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].Scope == "" {
+			continue
+		}
+		scope, err := crypto.DecryptFromBase64(namesKey, entries[i].Scope)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt audit scope: %w", err)
+		}
+		entries[i].Scope = string(scope)
+		if len(entries[i].Fields) == 1 {
+			fields, err := crypto.DecryptFromBase64(namesKey, entries[i].Fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("decrypt audit fields: %w", err)
+			}
+			entries[i].Fields = strings.Split(string(fields), ",")
+		}
+	}
+	return entries, nil
+}
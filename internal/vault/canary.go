@@ -0,0 +1,320 @@
+package vault
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+var (
+	ErrCanaryExists   = errors.New("field already holds a value; a canary needs an unused ID")
+	ErrCanaryNotFound = errors.New("canary not found")
+)
+
+const (
+	// canariesMetaKey holds the canary table, encrypted so that a copy of
+	// the database doesn't tell decoys from real fields.
+	canariesMetaKey = "canary_fields"
+
+	// canaryKeyInfo is the HKDF info for the canary table key.
+	canaryKeyInfo = ":canaries"
+
+	// canarySensitivity is the default tier for a canary: high enough to
+	// look worth stealing, low enough that a token can read it without
+	// step-up, which is the read the canary is there to catch.
+	canarySensitivity = "sensitive"
+)
+
+// Canary is a decoy field. Nothing legitimate reads it, so any read by a
+// service token raises an alert, and can revoke the token.
+type Canary struct {
+	ID        string    `json:"id"`
+	Revoke    bool      `json:"revoke"` // revoke the token that reads it
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateCanary stores a decoy at id and returns its value: value if given,
+// else one generated to look like what the field name suggests (see
+// DecoyValue). The ID must be unused, so a real value is never replaced.
+func (v *Vault) CreateCanary(id, value, sensitivity string, revoke bool) (string, error) {
+	if err := ValidateFieldID(id); err != nil {
+		return "", err
+	}
+	if sensitivity == "" {
+		sensitivity = canarySensitivity
+	}
+	if !validTiers[sensitivity] {
+		return "", ErrInvalidTier
+	}
+	v.canaries.writeMu.Lock()
+	defer v.canaries.writeMu.Unlock()
+	m, err := v.canaries.load()
+	if err != nil {
+		return "", err
+	}
+	if v.ResolveAlias(id) != id {
+		return "", ErrAliasConflict
+	}
+	if f, err := v.db.GetField(id); err != nil {
+		return "", err
+	} else if f != nil {
+		return "", ErrCanaryExists
+	}
+	if value == "" {
+		value = DecoyValue(id)
+	}
+	if _, err := v.SetWithOptions(id, value, SetOptions{Sensitivity: sensitivity, Raw: true}); err != nil {
+		return "", err
+	}
+
+	next := maps.Clone(m)
+	next[id] = Canary{ID: id, Revoke: revoke, CreatedAt: time.Now()}
+	if err := v.canaries.save(next); err != nil {
+		v.db.DeleteField(id)
+		return "", err
+	}
+	return value, nil
+}
+
+// Canaries returns all canaries sorted by ID.
+func (v *Vault) Canaries() ([]Canary, error) {
+	m, err := v.canaries.load()
+	if err != nil {
+		return nil, err
+	}
+	canaries := make([]Canary, 0, len(m))
+	for _, c := range m {
+		canaries = append(canaries, c)
+	}
+	sort.Slice(canaries, func(i, j int) bool { return canaries[i].ID < canaries[j].ID })
+	return canaries, nil
+}
+
+// DeleteCanary removes a canary and its decoy field.
+func (v *Vault) DeleteCanary(id string) error {
+	v.canaries.writeMu.Lock()
+	defer v.canaries.writeMu.Unlock()
+	m, err := v.canaries.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[id]; !ok {
+		return ErrCanaryNotFound
+	}
+	if err := v.db.DeleteField(id); err != nil {
+		return err
+	}
+	next := maps.Clone(m)
+	delete(next, id)
+	if err := v.canaries.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "delete"})
+	return nil
+}
+
+// forgetCanary drops id from the canary table once its field is deleted, so
+// a real value stored there later doesn't raise alerts.
+func (v *Vault) forgetCanary(id string) {
+	v.canaries.writeMu.Lock()
+	defer v.canaries.writeMu.Unlock()
+	m, err := v.canaries.load()
+	if err != nil {
+		return
+	}
+	if _, ok := m[id]; !ok {
+		return
+	}
+	next := maps.Clone(m)
+	delete(next, id)
+	v.canaries.save(next)
+}
+
+// TripCanaries raises an alert for each canary among the field IDs a service
+// token has just read, and revokes the token's whole delegation chain if any
+// of them asks for it. It returns the canaries that were read.
+func (v *Vault) TripCanaries(ids []string, t *store.Token, requestID string) []string {
+	if t == nil {
+		return nil
+	}
+	m, err := v.canaries.load()
+	if err != nil || len(m) == 0 {
+		return nil
+	}
+	var tripped []string
+	revoke := false
+	for _, id := range ids {
+		c, ok := m[id]
+		if !ok {
+			continue
+		}
+		tripped = append(tripped, id)
+		revoke = revoke || c.Revoke
+	}
+	if len(tripped) == 0 {
+		return nil
+	}
+
+	revoked := false
+	if revoke {
+		revoked = v.revokeChain(t)
+	}
+	now := time.Now()
+	for _, id := range tripped {
+		purpose := ""
+		if revoked {
+			purpose = "token revoked"
+		}
+		v.db.LogAccess(store.AuditEntry{
+			Consumer:  t.Consumer,
+			Scope:     id,
+			Action:    "canary",
+			Purpose:   purpose,
+			RequestID: requestID,
+		})
+		slog.Warn("canary field read", "field", id, "consumer", t.Consumer, "revoked", revoked)
+		v.notify(Event{Type: EventCanaryRead, Priority: PriorityHigh, Field: id, Consumer: t.Consumer, Revoked: revoked, Time: now})
+	}
+	if revoked {
+		v.db.LogAccess(store.AuditEntry{
+			Consumer: "vault",
+			Scope:    "*",
+			Action:   "revoke_service_token",
+			Purpose:  "canary read by " + t.Consumer,
+		})
+	}
+	return tripped
+}
+
+// DecoyValue generates a plausible value for a field from the words in its
+// ID: a Luhn-valid card number for "card", a well-formed SSN for "ssn", and
+// so on, falling back to a random token. Decoys must pass a casual check,
+// or whoever reads one will know to stop.
+func DecoyValue(id string) string {
+	words := strings.FieldsFunc(strings.ToLower(id), func(r rune) bool {
+		return r == '.' || r == '_' || r == '-'
+	})
+	has := func(want ...string) bool {
+		for _, w := range words {
+			if slices.Contains(want, w) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("cvv", "cvc", "cvv2"):
+		return randDigits(3)
+	case has("exp", "expiry", "expiration", "expires"):
+		return fmt.Sprintf("%02d/%02d", 1+randInt(12), (time.Now().Year()+2+randInt(4))%100)
+	case has("card", "cc", "credit", "debit", "pan"):
+		n := luhnComplete("4" + randDigits(14))
+		return n[:4] + " " + n[4:8] + " " + n[8:12] + " " + n[12:]
+	case has("ssn", "sin", "social"):
+		area := 1 + randInt(899)
+		for area == 666 {
+			area = 1 + randInt(899)
+		}
+		return fmt.Sprintf("%03d-%02d-%04d", area, 1+randInt(99), 1+randInt(9999))
+	case has("routing", "aba"):
+		return abaComplete(randDigits(8))
+	case has("iban"):
+		return "GB" + randDigits(2) + "NWBK" + randDigits(14)
+	case has("account", "acct"):
+		return randDigits(10 + randInt(3))
+	case has("pin"):
+		return randDigits(4)
+	case has("phone", "mobile", "tel", "cell"):
+		// 555-0100 to 555-0199 are reserved for fiction, so a decoy never
+		// rings a real person.
+		return fmt.Sprintf("+1 (%d) 555-01%02d", 201+randInt(700), randInt(100))
+	case has("email", "mail"):
+		// example.net accepts no mail, for the same reason.
+		first := decoyNames[randInt(len(decoyNames))]
+		last := decoyNames[randInt(len(decoyNames))]
+		return fmt.Sprintf("%s.%s%d@example.net", first, last, 10+randInt(90))
+	default:
+		return randToken(24)
+	}
+}
+
+var decoyNames = []string{"alex", "casey", "jordan", "morgan", "riley", "taylor", "avery", "quinn", "reese", "jamie"}
+
+func randInt(n int) int {
+	i, err := crand.Int(crand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		panic(err)
+	}
+	return int(i.Int64())
+}
+
+func randDigits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + randInt(10))
+	}
+	return string(b)
+}
+
+func randToken(n int) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[randInt(len(alphabet))]
+	}
+	return string(b)
+}
+
+// luhnComplete appends the Luhn check digit to digits.
+func luhnComplete(digits string) string {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return digits + string(byte('0'+(10-sum%10)%10))
+}
+
+// abaComplete appends the ABA routing number check digit to eight digits.
+func abaComplete(digits string) string {
+	weights := [8]int{3, 7, 1, 3, 7, 1, 3, 7}
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	return digits + string(byte('0'+(10-sum%10)%10))
+}
+
+// revokeChain deletes t and every token it was delegated from, up to the
+// root: the agent that read a canary may have been handed the token, so
+// whoever delegated it is revoked too, and with the root goes every other
+// token delegated from it. It reports whether anything was deleted.
+func (v *Vault) revokeChain(t *store.Token) bool {
+	revoked := false
+	for t != nil {
+		var parent *store.Token
+		if t.Parent != "" {
+			parent, _ = v.db.GetToken(t.Parent)
+		}
+		if n, err := v.db.DeleteToken(t.TokenStr); err == nil && n > 0 {
+			revoked = true
+		}
+		t = parent
+	}
+	return revoked
+}
@@ -0,0 +1,58 @@
+package vault
+
+import (
+	"time"
+)
+
+// DeletedField is a field removed since an incremental export's cutoff.
+type DeletedField struct {
+	ID        string    `json:"id"`
+	Version   int       `json:"version"` // the field's version when it was deleted
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Changes is what changed in the vault since a point in time: the fields set
+// at or after Since, with their current values, and the fields deleted. AsOf
+// is the Since to pass next time. Timestamps have one-second granularity, so
+// a change in the AsOf second may be reported again, but none is missed.
+type Changes struct {
+	Since time.Time `json:"since"`
+	AsOf  time.Time `json:"as_of"`
+	*ContextBundle
+	Deleted []DeletedField `json:"deleted"`
+}
+
+// ChangesSince returns the fields created, updated, or deleted at or after
+// since. A zero since returns every field.
+func (v *Vault) ChangesSince(since time.Time) (*Changes, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	since = since.UTC().Truncate(time.Second)
+	asOf := time.Now().UTC().Truncate(time.Second)
+
+	fields, err := v.db.GetAllFields()
+	if err != nil {
+		return nil, err
+	}
+	changed := fields[:0]
+	for _, f := range fields {
+		if !f.UpdatedAt.Before(since) {
+			changed = append(changed, f)
+		}
+	}
+	bundle, err := v.decryptBundle(changed)
+	if err != nil {
+		return nil, err
+	}
+
+	tombstones, err := v.db.ListTombstones(since)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]DeletedField, 0, len(tombstones))
+	for _, t := range tombstones {
+		deleted = append(deleted, DeletedField{ID: t.ID, Version: t.Version, DeletedAt: t.DeletedAt})
+	}
+	return &Changes{Since: since, AsOf: asOf, ContextBundle: bundle, Deleted: deleted}, nil
+}
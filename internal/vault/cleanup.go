@@ -0,0 +1,101 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// CleanupResult is what one run of Cleanup removed.
+type CleanupResult struct {
+	ExpiredTokens int64 `json:"expired_tokens"`
+	AuditEntries  int64 `json:"audit_entries"`
+}
+
+// Cleanup deletes expired tokens and, if auditRetention is positive, audit
+// entries older than that, then checkpoints the database's write-ahead log.
+// It doesn't need the vault unlocked, except with full-database encryption,
+// where a locked store returns ErrLocked. Pruning the audit log is itself
+// audited, so a gap in it is explained.
+func (v *Vault) Cleanup(now time.Time, auditRetention time.Duration) (CleanupResult, error) {
+	var res CleanupResult
+	var err error
+	if res.ExpiredTokens, err = v.db.DeleteExpiredTokens(); err != nil {
+		return res, sealedAsLocked(err)
+	}
+	if auditRetention > 0 {
+		cutoff := now.Add(-auditRetention)
+		if res.AuditEntries, err = v.db.PruneAuditLog(cutoff); err != nil {
+			return res, sealedAsLocked(err)
+		}
+		if res.AuditEntries > 0 {
+			v.db.LogAccess(store.AuditEntry{
+				Consumer: "vault",
+				Scope:    "*",
+				Action:   "prune_audit",
+				Purpose:  fmt.Sprintf("%d entries before %s", res.AuditEntries, cutoff.UTC().Format(time.RFC3339)),
+			})
+		}
+	}
+	return res, sealedAsLocked(v.db.Checkpoint())
+}
+
+// CompactResult is the size of the vault's database files before and after
+// Compact, in bytes.
+type CompactResult struct {
+	Compacted bool  `json:"compacted"`
+	Before    int64 `json:"before_bytes"`
+	After     int64 `json:"after_bytes"`
+}
+
+// Compact rewrites the database without its free space if that makes up at
+// least minFree of it, as a fraction; 0 always compacts. A vault with
+// full-database encryption is rewritten whole on every write, so there is
+// never anything to compact. Like Cleanup, it works while locked.
+func (v *Vault) Compact(minFree float64) (CompactResult, error) {
+	res := CompactResult{Before: v.databaseSize()}
+	compacted, err := v.db.Compact(minFree)
+	if err != nil {
+		return res, sealedAsLocked(err)
+	}
+	res.Compacted = compacted
+	res.After = v.databaseSize()
+	if compacted {
+		v.db.LogAccess(store.AuditEntry{
+			Consumer: "vault",
+			Scope:    "*",
+			Action:   "compact",
+			Purpose:  fmt.Sprintf("%d → %d bytes", res.Before, res.After),
+		})
+	}
+	return res, nil
+}
+
+// SetSynchronous sets how hard the database works to get each write onto
+// disk, one of store.SynchronousModes. A vault with full-database
+// encryption syncs its file on every write and ignores it.
+func (v *Vault) SetSynchronous(mode string) error {
+	db := v.db
+	if b, ok := db.(*blindStore); ok {
+		db = b.Store
+	}
+	if t, ok := db.(store.Tuner); ok {
+		return t.SetSynchronous(mode)
+	}
+	return nil
+}
+
+// databaseSize is the combined size of the database files on disk: the
+// database, its write-ahead log, or the encrypted file and its audit log.
+func (v *Vault) databaseSize() int64 {
+	var n int64
+	for _, name := range []string{dbFile, dbFile + "-wal", encryptedDBFile, filepath.Base(store.EncryptedAuditLogPath(encryptedDBFile))} {
+		if fi, err := os.Stat(filepath.Join(v.dir, name)); err == nil {
+			n += fi.Size()
+		}
+	}
+	return n
+}
@@ -0,0 +1,353 @@
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/proc"
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// TokenConstraints restrict when and how often a service token works. They
+// are checked on every request, so a leaked token is useless outside its
+// window. The zero value imposes no restriction.
+type TokenConstraints struct {
+	Hours     string   `json:"hours,omitempty"`       // "HH:MM-HH:MM", end exclusive; may wrap past midnight
+	Weekdays  []string `json:"weekdays,omitempty"`    // "mon" … "sun"
+	MaxPerDay int      `json:"max_per_day,omitempty"` // requests per calendar day
+	Timezone  string   `json:"timezone,omitempty"`    // IANA name; defaults to the server's local time
+
+	Workload *WorkloadBinding `json:"workload,omitempty"` // the only process allowed to present the token
+
+	// CriticalPerDay caps how often the token reads each critical field per
+	// calendar day, so a stolen token in scope can't drain a card number.
+	CriticalPerDay int `json:"critical_per_day,omitempty"`
+
+	// ListOutOfScope lets the token see which fields exist beyond its scope,
+	// by ID and sensitivity only, so its agent can ask for a precise grant.
+	// Unlike the rest it widens rather than restricts.
+	ListOutOfScope bool `json:"list_out_of_scope,omitempty"`
+}
+
+// WorkloadBinding ties a token to the process presenting it, as attested by
+// the kernel over the vault's Unix socket. A bound token is refused over TCP,
+// where the peer can't be identified, and from any process that doesn't
+// match every field set.
+type WorkloadBinding struct {
+	ExeSHA256   string `json:"exe_sha256,omitempty"`   // SHA-256 of the executable
+	ContainerID string `json:"container_id,omitempty"` // full ID or a prefix of at least 12 characters
+	UID         *int   `json:"uid,omitempty"`
+}
+
+// Reasons a restricted token is refused.
+const (
+	RestrictedOutsideHours = "outside_hours"
+	RestrictedWeekday      = "weekday_not_allowed"
+	RestrictedDailyLimit   = "daily_limit_reached"
+	RestrictedUnattested   = "workload_unattested"
+	RestrictedWorkload     = "workload_mismatch"
+)
+
+// TokenRestrictedError is returned when a valid service token is used
+// outside its constraints.
+type TokenRestrictedError struct {
+	Reason      string
+	Constraints TokenConstraints
+}
+
+func (e *TokenRestrictedError) Error() string {
+	switch e.Reason {
+	case RestrictedOutsideHours:
+		return "token is only valid between " + e.Constraints.Hours
+	case RestrictedWeekday:
+		return "token is only valid on " + strings.Join(e.Constraints.Weekdays, ",")
+	case RestrictedDailyLimit:
+		return fmt.Sprintf("token has used its %d requests for today", e.Constraints.MaxPerDay)
+	case RestrictedUnattested:
+		return "token is bound to a workload and must be presented over the vault socket"
+	case RestrictedWorkload:
+		return "token is bound to a different workload"
+	}
+	return "token restricted"
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// IsZero reports whether c sets nothing: no restriction and no ListOutOfScope.
+func (c TokenConstraints) IsZero() bool {
+	return c.Hours == "" && len(c.Weekdays) == 0 && c.MaxPerDay == 0 && c.Timezone == "" && c.Workload == nil && c.CriticalPerDay == 0 && !c.ListOutOfScope
+}
+
+// ListsOutOfScope reports whether token t was created with ListOutOfScope.
+func ListsOutOfScope(t *store.Token) bool {
+	c, err := ParseTokenConstraints(t.Constraints)
+	return err == nil && c.ListOutOfScope
+}
+
+// Validate checks the constraint syntax and normalizes weekday names.
+func (c *TokenConstraints) Validate() error {
+	if c.Hours != "" {
+		if _, _, err := parseHours(c.Hours); err != nil {
+			return err
+		}
+	}
+	for i, d := range c.Weekdays {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if len(d) > 3 {
+			d = d[:3]
+		}
+		if weekdayIndex(d) < 0 {
+			return fmt.Errorf("invalid weekday %q", c.Weekdays[i])
+		}
+		c.Weekdays[i] = d
+	}
+	if c.MaxPerDay < 0 {
+		return fmt.Errorf("max_per_day must not be negative")
+	}
+	if c.CriticalPerDay < 0 {
+		return fmt.Errorf("critical_per_day must not be negative")
+	}
+	if _, err := c.location(); err != nil {
+		return err
+	}
+	if c.Workload != nil {
+		return c.Workload.validate()
+	}
+	return nil
+}
+
+func (b *WorkloadBinding) validate() error {
+	b.ExeSHA256 = strings.ToLower(strings.TrimSpace(b.ExeSHA256))
+	b.ContainerID = strings.ToLower(strings.TrimSpace(b.ContainerID))
+	if b.ExeSHA256 == "" && b.ContainerID == "" && b.UID == nil {
+		return fmt.Errorf("workload binding needs exe_sha256, container_id, or uid")
+	}
+	if b.ExeSHA256 != "" && (len(b.ExeSHA256) != 64 || !isHex(b.ExeSHA256)) {
+		return fmt.Errorf("exe_sha256 must be 64 hex characters")
+	}
+	if b.ContainerID != "" && (len(b.ContainerID) < 12 || len(b.ContainerID) > 64 || !isHex(b.ContainerID)) {
+		return fmt.Errorf("container_id must be 12 to 64 hex characters")
+	}
+	if b.UID != nil && *b.UID < 0 {
+		return fmt.Errorf("uid must not be negative")
+	}
+	return nil
+}
+
+func isHex(s string) bool {
+	return strings.Trim(s, "0123456789abcdef") == ""
+}
+
+// matches checks an attested workload against the binding. A nil workload
+// means the request didn't come over the vault socket.
+func (b *WorkloadBinding) matches(w *proc.Workload) string {
+	if w == nil {
+		return RestrictedUnattested
+	}
+	if b.UID != nil && *b.UID != w.UID {
+		return RestrictedWorkload
+	}
+	if b.ContainerID != "" && (w.ContainerID == "" || !strings.HasPrefix(w.ContainerID, b.ContainerID)) {
+		return RestrictedWorkload
+	}
+	if b.ExeSHA256 != "" {
+		if sum, err := w.ExeSHA256(); err != nil || sum != b.ExeSHA256 {
+			return RestrictedWorkload
+		}
+	}
+	return ""
+}
+
+// ParseTokenConstraints decodes constraints as stored on a token.
+func ParseTokenConstraints(s string) (TokenConstraints, error) {
+	var c TokenConstraints
+	if s == "" {
+		return c, nil
+	}
+	err := json.Unmarshal([]byte(s), &c)
+	return c, err
+}
+
+func weekdayIndex(name string) int {
+	for i, n := range weekdayNames {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseHours returns the window as minutes since midnight.
+func parseHours(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid hours %q, want HH:MM-HH:MM", s)
+	}
+	if start, err = parseClock(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(to); err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("invalid hours %q: empty window", s)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (c TokenConstraints) location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", c.Timezone)
+	}
+	return loc, nil
+}
+
+// allows checks the time window only; the daily limit needs the store.
+func (c TokenConstraints) allows(now time.Time) error {
+	if c.Hours != "" {
+		start, end, err := parseHours(c.Hours)
+		if err != nil {
+			return err
+		}
+		m := now.Hour()*60 + now.Minute()
+		var in bool
+		if start < end {
+			in = m >= start && m < end
+		} else {
+			in = m >= start || m < end
+		}
+		if !in {
+			return &TokenRestrictedError{Reason: RestrictedOutsideHours, Constraints: c}
+		}
+	}
+	if len(c.Weekdays) > 0 {
+		today := weekdayNames[now.Weekday()]
+		allowed := false
+		for _, d := range c.Weekdays {
+			if d == today {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &TokenRestrictedError{Reason: RestrictedWeekday, Constraints: c}
+		}
+	}
+	return nil
+}
+
+// EnforceTokenConstraints checks a validated service token against its
+// constraints at time now, presented by workload w (nil unless the request
+// came over the vault socket), and counts the request toward its daily limit.
+// Delegated tokens draw on the limit of the token at the root of their chain,
+// so minting children can't multiply it. Unrestricted tokens always pass.
+func (v *Vault) EnforceTokenConstraints(t *store.Token, now time.Time, w *proc.Workload) error {
+	if t.Constraints == "" {
+		return nil
+	}
+	c, err := ParseTokenConstraints(t.Constraints)
+	if err != nil {
+		return fmt.Errorf("token constraints: %w", err)
+	}
+	if c.Workload != nil {
+		if reason := c.Workload.matches(w); reason != "" {
+			return &TokenRestrictedError{Reason: reason, Constraints: c}
+		}
+	}
+	loc, err := c.location()
+	if err != nil {
+		return err
+	}
+	now = now.In(loc)
+	if err := c.allows(now); err != nil {
+		return err
+	}
+	if c.MaxPerDay > 0 {
+		counted := t
+		if root := v.rootToken(t); root != nil {
+			counted = root
+		}
+		n, err := v.db.RecordTokenUse(counted.TokenStr, now.Format(time.DateOnly))
+		if err != nil {
+			return err
+		}
+		if n > c.MaxPerDay {
+			return &TokenRestrictedError{Reason: RestrictedDailyLimit, Constraints: c}
+		}
+	}
+	return nil
+}
+
+// budgetKeyInfo is the HKDF info for the key that names fields in read
+// budget counters, so the store doesn't learn which fields a token reads.
+const budgetKeyInfo = ":budget"
+
+// BudgetExceededError is returned when a token has read a critical field
+// as often today as its critical_per_day allows.
+type BudgetExceededError struct {
+	ID    string
+	Limit int
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("token has used its %d reads of %s for today", e.Limit, e.ID)
+}
+
+// SpendReadBudget counts a read of fields by token t against its
+// critical_per_day budget, returning a *BudgetExceededError for the first
+// critical field read more often than that today. Refused reads count too.
+// Tokens delegated from one another share their root's budget.
+func (v *Vault) SpendReadBudget(t *store.Token, fields []FieldInfo, now time.Time) error {
+	c, err := ParseTokenConstraints(t.Constraints)
+	if err != nil {
+		return fmt.Errorf("token constraints: %w", err)
+	}
+	if c.CriticalPerDay == 0 {
+		return nil
+	}
+	loc, err := c.location()
+	if err != nil {
+		return err
+	}
+	day := now.In(loc).Format(time.DateOnly)
+	counted := t
+	if root := v.rootToken(t); root != nil {
+		counted = root
+	}
+	key, err := v.subkey(budgetKeyInfo)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.Sensitivity != "critical" {
+			continue
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(f.ID))
+		n, err := v.db.RecordTokenRead(counted.TokenStr, hex.EncodeToString(mac.Sum(nil)), day)
+		if err != nil {
+			return err
+		}
+		if n > c.CriticalPerDay {
+			return &BudgetExceededError{ID: f.ID, Limit: c.CriticalPerDay}
+		}
+	}
+	return nil
+}
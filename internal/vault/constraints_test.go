@@ -0,0 +1,111 @@
+package vault
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/proc"
+)
+
+func TestTokenConstraints_Validate(t *testing.T) {
+	c := TokenConstraints{Hours: "09:00-17:30", Weekdays: []string{"Monday", "FRI"}}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Weekdays[0] != "mon" || c.Weekdays[1] != "fri" {
+		t.Fatalf("expected normalized weekdays, got %v", c.Weekdays)
+	}
+
+	for _, bad := range []TokenConstraints{
+		{Hours: "9-5"},
+		{Hours: "09:00-09:00"},
+		{Weekdays: []string{"xyz"}},
+		{MaxPerDay: -1},
+		{Timezone: "Mars/Olympus"},
+		{Workload: &WorkloadBinding{}},
+		{Workload: &WorkloadBinding{ExeSHA256: "abc"}},
+		{Workload: &WorkloadBinding{ContainerID: "short"}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestTokenConstraints_Window(t *testing.T) {
+	// 2026-03-02 is a Monday.
+	at := func(hhmm string) time.Time {
+		tm, _ := time.Parse("2006-01-02 15:04", "2026-03-02 "+hhmm)
+		return tm
+	}
+	tests := []struct {
+		c      TokenConstraints
+		now    time.Time
+		reason string
+	}{
+		{TokenConstraints{Hours: "09:00-17:00"}, at("09:00"), ""},
+		{TokenConstraints{Hours: "09:00-17:00"}, at("17:00"), RestrictedOutsideHours},
+		{TokenConstraints{Hours: "22:00-06:00"}, at("23:30"), ""},
+		{TokenConstraints{Hours: "22:00-06:00"}, at("05:59"), ""},
+		{TokenConstraints{Hours: "22:00-06:00"}, at("12:00"), RestrictedOutsideHours},
+		{TokenConstraints{Weekdays: []string{"mon"}}, at("12:00"), ""},
+		{TokenConstraints{Weekdays: []string{"sat", "sun"}}, at("12:00"), RestrictedWeekday},
+	}
+	for _, tt := range tests {
+		err := tt.c.allows(tt.now)
+		var restricted *TokenRestrictedError
+		switch {
+		case tt.reason == "" && err != nil:
+			t.Errorf("%+v at %s: unexpected %v", tt.c, tt.now.Format("15:04"), err)
+		case tt.reason != "" && (!errors.As(err, &restricted) || restricted.Reason != tt.reason):
+			t.Errorf("%+v at %s: expected %s, got %v", tt.c, tt.now.Format("15:04"), tt.reason, err)
+		}
+	}
+}
+
+func TestEnforceTokenConstraints_DailyLimitResetsNextDay(t *testing.T) {
+	v, _ := tmpVault(t)
+	token, err := v.CreateRestrictedServiceToken("payroll", "*", time.Hour, TokenConstraints{MaxPerDay: 1, Timezone: "UTC"}, TokenMetadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, ok := v.ValidateServiceToken(token)
+	if !ok {
+		t.Fatal("expected valid token")
+	}
+
+	day1 := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	if err := v.EnforceTokenConstraints(svc, day1, nil); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if err := v.EnforceTokenConstraints(svc, day1.Add(time.Minute), nil); err == nil {
+		t.Fatal("second request on the same day should be refused")
+	}
+	if err := v.EnforceTokenConstraints(svc, day1.Add(24*time.Hour), nil); err != nil {
+		t.Fatalf("limit should reset the next day: %v", err)
+	}
+}
+
+func TestWorkloadBinding_Matches(t *testing.T) {
+	uid := 501
+	container := "4f1c2e9a8b7d6c5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e"
+	tests := []struct {
+		b      WorkloadBinding
+		w      *proc.Workload
+		reason string
+	}{
+		{WorkloadBinding{UID: &uid}, nil, RestrictedUnattested},
+		{WorkloadBinding{UID: &uid}, &proc.Workload{UID: 501}, ""},
+		{WorkloadBinding{UID: &uid}, &proc.Workload{UID: 502}, RestrictedWorkload},
+		{WorkloadBinding{ContainerID: container[:12]}, &proc.Workload{ContainerID: container}, ""},
+		{WorkloadBinding{ContainerID: container[:12]}, &proc.Workload{}, RestrictedWorkload},
+		// An executable that can't be read never matches.
+		{WorkloadBinding{ExeSHA256: container}, &proc.Workload{}, RestrictedWorkload},
+	}
+	for _, tt := range tests {
+		if got := tt.b.matches(tt.w); got != tt.reason {
+			t.Errorf("%+v against %+v: expected %q, got %q", tt.b, tt.w, tt.reason, got)
+		}
+	}
+}
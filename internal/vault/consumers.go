@@ -0,0 +1,163 @@
+package vault
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// Consumer trust levels, from least to most trusted.
+const (
+	TrustUntrusted = "untrusted"
+	TrustStandard  = "standard"
+	TrustTrusted   = "trusted"
+)
+
+// TrustLevels lists the trust levels from least to most trusted.
+var TrustLevels = []string{TrustUntrusted, TrustStandard, TrustTrusted}
+
+// trustMaxTier is the most sensitive tier each trust level reaches.
+var trustMaxTier = map[string]string{
+	TrustUntrusted: "standard",
+	TrustStandard:  "sensitive",
+	TrustTrusted:   "critical",
+}
+
+var (
+	ErrInvalidTrust     = errors.New("invalid trust level: must be untrusted, standard, or trusted")
+	ErrConsumerNotFound = errors.New("consumer not registered")
+)
+
+const (
+	// consumersMetaKey holds the consumer registry, encrypted like the other
+	// access policy tables.
+	consumersMetaKey = "consumers"
+
+	// consumerKeyInfo is the HKDF info for the consumer registry key.
+	consumerKeyInfo = ":consumers"
+)
+
+// Consumer is a registered consumer's trust level, which caps the
+// sensitivity its service tokens reach whatever their scope: scope says
+// which fields, trust says how sensitive. A consumer that isn't registered
+// is trusted.
+type Consumer struct {
+	Name  string `json:"name"`
+	Trust string `json:"trust"`
+	// MaxSensitivity is the most sensitive tier the consumer reaches.
+	MaxSensitivity string `json:"max_sensitivity"`
+	// Registered is false for a consumer listed only because it holds
+	// service tokens.
+	Registered bool `json:"registered"`
+}
+
+// TrustMaxSensitivity returns the most sensitive tier trust reaches, or ""
+// for an unknown level.
+func TrustMaxSensitivity(trust string) string {
+	return trustMaxTier[trust]
+}
+
+// TrustAllows reports whether a consumer at trust may reach a field of
+// sensitivity tier.
+func TrustAllows(trust, tier string) bool {
+	top, ok := trustMaxTier[trust]
+	if !ok {
+		return false
+	}
+	return tierRank[tier] <= tierRank[top]
+}
+
+// SetConsumerTrust registers consumer at trust, or changes its level. It
+// takes effect on the consumer's existing tokens at once.
+func (v *Vault) SetConsumerTrust(consumer, trust string) error {
+	if strings.TrimSpace(consumer) == "" {
+		return ErrInvalidConsumer
+	}
+	if _, ok := trustMaxTier[trust]; !ok {
+		return ErrInvalidTrust
+	}
+	v.consumers.writeMu.Lock()
+	defer v.consumers.writeMu.Unlock()
+	m, err := v.consumers.load()
+	if err != nil {
+		return err
+	}
+	next := maps.Clone(m)
+	next[consumer] = Consumer{Name: consumer, Trust: trust}
+	if err := v.consumers.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: consumer, Action: "consumer_trust", Purpose: "trust: " + trust})
+	return nil
+}
+
+// RemoveConsumer drops consumer from the registry, making it trusted again.
+func (v *Vault) RemoveConsumer(consumer string) error {
+	v.consumers.writeMu.Lock()
+	defer v.consumers.writeMu.Unlock()
+	m, err := v.consumers.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[consumer]; !ok {
+		return ErrConsumerNotFound
+	}
+	next := maps.Clone(m)
+	delete(next, consumer)
+	if err := v.consumers.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: consumer, Action: "consumer_remove"})
+	return nil
+}
+
+// Consumers returns the registered consumers and those holding service
+// tokens, sorted by name.
+func (v *Vault) Consumers() ([]Consumer, error) {
+	m, err := v.consumers.load()
+	if err != nil {
+		return nil, err
+	}
+	all := maps.Clone(m)
+	for name, c := range all {
+		c.Registered = true
+		all[name] = c
+	}
+	tokens, err := v.ListServiceTokens()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if _, ok := all[t.Consumer]; !ok {
+			all[t.Consumer] = Consumer{Name: t.Consumer, Trust: TrustTrusted}
+		}
+	}
+	out := slices.Collect(maps.Values(all))
+	for i := range out {
+		out[i].MaxSensitivity = trustMaxTier[out[i].Trust]
+	}
+	slices.SortFunc(out, func(a, b Consumer) int { return strings.Compare(a.Name, b.Name) })
+	return out, nil
+}
+
+// ConsumerTrust returns the trust level of a service token whose delegation
+// chain has consumers (see TokenConsumers): the least trusted among them, so
+// a token can't be delegated up a level. If the registry can't be read, the
+// token is untrusted.
+func (v *Vault) ConsumerTrust(consumers []string) string {
+	m, err := v.consumers.load()
+	if err != nil {
+		return TrustUntrusted
+	}
+	trust := TrustTrusted
+	for _, name := range consumers {
+		c, ok := m[name]
+		if ok && slices.Index(TrustLevels, c.Trust) < slices.Index(TrustLevels, trust) {
+			trust = c.Trust
+		}
+	}
+	return trust
+}
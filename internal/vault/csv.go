@@ -0,0 +1,149 @@
+package vault
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// csvHeader is the first row of a CSV export, which an import requires
+// unchanged.
+var csvHeader = []string{"id", "value", "sensitivity"}
+
+// CSVError reports the line of a CSV import that failed validation.
+type CSVError struct {
+	Line int
+	Err  error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *CSVError) Unwrap() error { return e.Err }
+
+// WriteCSV writes the fields in b as CSV, one row per field sorted by ID,
+// for editing in a spreadsheet and reading back with ReadCSV.
+func WriteCSV(w io.Writer, b *ContextBundle) error {
+	var fields []FieldInfo
+	for _, fs := range b.Categories {
+		fields = append(fields, fs...)
+	}
+	slices.SortFunc(fields, func(a, b FieldInfo) int { return strings.Compare(a.ID, b.ID) })
+
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for _, f := range fields {
+		cw.Write([]string{f.ID, storedValue(f), f.Sensitivity})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads fields written by WriteCSV. It is strict so that a slip in
+// a spreadsheet can't write something unintended: the header must be
+// unchanged, every row needs a valid field ID that no other row has and a
+// value, and sensitivity must be a tier or empty for the schema default.
+func ReadCSV(r io.Reader) ([]FieldInfo, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(csvHeader)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, &CSVError{Line: 1, Err: errors.New("empty file")}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(header, csvHeader) {
+		return nil, &CSVError{Line: 1, Err: fmt.Errorf("header must be %s", strings.Join(csvHeader, ","))}
+	}
+
+	var fields []FieldInfo
+	seen := make(map[string]bool)
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return fields, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		id, value, tier := strings.TrimSpace(row[0]), strings.TrimSpace(row[1]), strings.TrimSpace(row[2])
+		if err := ValidateFieldID(id); err != nil {
+			return nil, &CSVError{Line: line, Err: err}
+		}
+		if seen[id] {
+			return nil, &CSVError{Line: line, Err: fmt.Errorf("%s appears more than once", id)}
+		}
+		seen[id] = true
+		if value == "" {
+			return nil, &CSVError{Line: line, Err: fmt.Errorf("%s has no value", id)}
+		}
+		if tier != "" && !validTiers[tier] {
+			return nil, &CSVError{Line: line, Err: ErrInvalidTier}
+		}
+		category, name, _ := strings.Cut(id, ".")
+		fields = append(fields, FieldInfo{ID: id, Category: category, FieldName: name, Value: value, Sensitivity: tier})
+	}
+}
+
+// CSVChange is a field a CSV import changes.
+type CSVChange struct {
+	Old FieldInfo `json:"old"`
+	New FieldInfo `json:"new"`
+}
+
+// CSVPlan is what applying a CSV import would do.
+type CSVPlan struct {
+	Added     []FieldInfo `json:"added"`
+	Changed   []CSVChange `json:"changed"`
+	Unchanged int         `json:"unchanged"`
+}
+
+// PlanCSV diffs rows read by ReadCSV against the vault's current contents.
+// A row changes a field if its value differs, or its sensitivity is given and
+// differs. Fields without a row are left alone, so rows can be deleted to
+// narrow an import.
+func PlanCSV(existing *ContextBundle, rows []FieldInfo) *CSVPlan {
+	current := make(map[string]FieldInfo)
+	for _, fields := range existing.Categories {
+		for _, f := range fields {
+			current[f.ID] = f
+		}
+	}
+	plan := &CSVPlan{}
+	for _, row := range rows {
+		old, ok := current[row.ID]
+		switch {
+		case !ok:
+			plan.Added = append(plan.Added, row)
+		case row.Value != storedValue(old) || (row.Sensitivity != "" && row.Sensitivity != old.Sensitivity):
+			plan.Changed = append(plan.Changed, CSVChange{Old: old, New: row})
+		default:
+			plan.Unchanged++
+		}
+	}
+	return plan
+}
+
+// Ops returns the transaction operations that apply the plan. Values are
+// normalized as they would be by 'pvault set'. A changed field keeps its
+// sensitivity unless the row gives one.
+func (p *CSVPlan) Ops() []TxOp {
+	var ops []TxOp
+	for _, f := range p.Added {
+		ops = append(ops, TxOp{Op: TxSet, ID: f.ID, Value: f.Value, Sensitivity: f.Sensitivity})
+	}
+	for _, c := range p.Changed {
+		tier := c.New.Sensitivity
+		if tier == "" {
+			tier = c.Old.Sensitivity
+		}
+		ops = append(ops, TxOp{Op: TxSet, ID: c.New.ID, Value: c.New.Value, Sensitivity: tier})
+	}
+	return ops
+}
@@ -0,0 +1,136 @@
+package vault
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// Delegated token lifetimes. A child never outlives MaxDelegationTTL or the
+// token it was delegated from, whichever ends first.
+const (
+	DefaultDelegationTTL = 5 * time.Minute
+	MaxDelegationTTL     = time.Hour
+)
+
+var (
+	ErrScopeNotSubset = errors.New("delegated scope must be a subset of the token's own scope")
+	ErrInvalidTTL     = errors.New("ttl must be positive")
+)
+
+// ScopeSubset reports whether every pattern in child is covered by parent:
+// "*" only by "*", "category.*" by "*" or the same category wildcard,
+// "contacts.person.*" also by "contacts.*", and an exact field ID by any
+// pattern that allows it. An empty child is not a subset.
+func ScopeSubset(child, parent string) bool {
+	found := false
+	for _, p := range strings.Split(child, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		found = true
+		switch {
+		case p == "*":
+			if !ScopeIsWildcard(parent) {
+				return false
+			}
+		case strings.HasSuffix(p, ".*"):
+			if !ScopeIsWildcard(parent) && !scopeCoversWildcard(parent, p) {
+				return false
+			}
+		default:
+			if !ScopeAllows(parent, p) {
+				return false
+			}
+		}
+	}
+	return found
+}
+
+// scopeCoversWildcard reports whether scope has a wildcard at or above
+// pattern's, like contacts.* above contacts.spouse.*.
+func scopeCoversWildcard(scope, pattern string) bool {
+	want := strings.TrimSuffix(pattern, "*")
+	for _, p := range strings.Split(scope, ",") {
+		p = strings.TrimSpace(p)
+		if strings.HasSuffix(p, ".*") && strings.HasPrefix(want, strings.TrimSuffix(p, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// DelegateServiceToken mints a child of a validated service token for another
+// consumer, such as a sub-agent. The child's scope must be a subset of the
+// parent's, it inherits the parent's constraints and labels, and its
+// lifetime is capped at MaxDelegationTTL and the parent's expiry; a zero ttl
+// means DefaultDelegationTTL. The child stops working as soon as any token
+// it descends from is revoked or expires. It returns the raw token and its
+// expiry.
+func (v *Vault) DelegateServiceToken(parent *store.Token, consumer, scope string, ttl time.Duration) (string, time.Time, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return "", time.Time{}, err
+	}
+	if ttl < 0 {
+		return "", time.Time{}, ErrInvalidTTL
+	}
+	if !ScopeSubset(scope, v.ResolveScope(parent.Scope)) {
+		return "", time.Time{}, ErrScopeNotSubset
+	}
+	if ttl == 0 {
+		ttl = DefaultDelegationTTL
+	}
+	now := time.Now()
+	expires := now.Add(min(ttl, MaxDelegationTTL))
+	if parent.ExpiresAt.Before(expires) {
+		expires = parent.ExpiresAt
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := crand.Read(tokenBytes); err != nil {
+		return "", time.Time{}, err
+	}
+	tokenStr := hex.EncodeToString(tokenBytes)
+
+	t := store.Token{
+		TokenStr:    hashServiceToken(tokenStr),
+		Consumer:    consumer,
+		Scope:       scope,
+		ExpiresAt:   expires,
+		Usage:       "service",
+		CreatedAt:   now,
+		Constraints: parent.Constraints,
+		Parent:      parent.TokenStr,
+		Labels:      parent.Labels,
+	}
+	if err := v.db.CreateToken(t); err != nil {
+		return "", time.Time{}, err
+	}
+
+	v.db.LogAccess(store.AuditEntry{
+		Consumer: parent.Consumer,
+		Scope:    scope,
+		Action:   "delegate_service_token",
+		Purpose:  "consumer: " + consumer,
+	})
+
+	return tokenStr, expires, nil
+}
+
+// rootToken returns the token at the top of t's delegation chain, or nil if
+// a token in the chain has been revoked or has expired.
+func (v *Vault) rootToken(t *store.Token) *store.Token {
+	for t != nil && t.Parent != "" {
+		parent, err := v.db.GetToken(t.Parent)
+		if err != nil || parent == nil || parent.Usage != "service" {
+			return nil
+		}
+		t = parent
+	}
+	return t
+}
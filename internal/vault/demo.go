@@ -0,0 +1,219 @@
+package vault
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// DemoField is a made-up value for a field, as DemoData gives it.
+type DemoField struct {
+	ID          string
+	Value       string
+	Sensitivity string
+}
+
+// demoPerson is one made-up person's details, shared by the fields that
+// describe them so a vault's name, email, and card holder agree.
+type demoPerson struct {
+	first, last string
+	email       string
+	phone       string
+	dob         string
+}
+
+// demoPlace is a city with a state, ZIP code, and timezone that go
+// together.
+type demoPlace struct{ city, state, zip, tz string }
+
+var (
+	demoFirstNames = []string{"Avery", "Jordan", "Riley", "Morgan", "Casey", "Quinn", "Harper", "Rowan", "Emerson", "Sage", "Parker", "Reese"}
+	demoLastNames  = []string{"Lindqvist", "Okafor", "Marchetti", "Nakamura", "Delacroix", "Brennan", "Castillo", "Haddad", "Whitfield", "Sorensen"}
+	demoStreets    = []string{"Maple Ave", "Harbor St", "Juniper Ln", "Willow Ct", "Cedar Ridge Rd", "Lakeview Dr", "Orchard Way"}
+	demoPlaces     = []demoPlace{
+		{"Portland", "OR", "97205", "America/Los_Angeles"},
+		{"Austin", "TX", "78701", "America/Chicago"},
+		{"Madison", "WI", "53703", "America/Chicago"},
+		{"Burlington", "VT", "05401", "America/New_York"},
+		{"Boulder", "CO", "80302", "America/Denver"},
+		{"Savannah", "GA", "31401", "America/New_York"},
+	}
+	demoEmployers = []string{"Northwind Traders", "Contoso Ltd", "Fabrikam Inc", "Tailspin Toys", "Globex Corporation", "Initech"}
+	demoTitles    = []string{"Software Engineer", "Product Manager", "Data Analyst", "Nurse Practitioner", "Architect", "Teacher"}
+	demoFiling    = []string{"single", "married_filing_jointly", "married_filing_separately", "head_of_household"}
+	// demoCards are the card networks' published test numbers, which no
+	// real account uses.
+	demoCards = []struct{ number, brand string }{
+		{"4111111111111111", "Visa"},
+		{"4242424242424242", "Visa"},
+		{"5555555555554444", "Mastercard"},
+		{"5105105105105100", "Mastercard"},
+		{"378282246310005", "American Express"},
+	}
+	demoRelations  = []string{"sibling", "parent", "friend", "neighbor"}
+	demoVehicles   = []struct{ make, model string }{{"Toyota", "Corolla"}, {"Honda", "Civic"}, {"Subaru", "Outback"}, {"Ford", "F-150"}, {"Tesla", "Model 3"}}
+	demoInsurers   = []string{"Acme Mutual", "Summit Assurance", "Harborline Insurance", "Bluefield Health"}
+	demoVisa       = []string{"GB", "JP", "CA", "DE", "AU"}
+	demoBloodTypes = []string{"O+", "O-", "A+", "A-", "B+", "AB+"}
+	demoAllergies  = []string{"none known", "penicillin", "peanuts", "shellfish", "latex"}
+	demoMeds       = []string{"none", "lisinopril 10 mg daily", "levothyroxine 50 mcg daily", "cetirizine 10 mg as needed"}
+)
+
+// demoExtra are made-up fields beyond the schema, for the categories it
+// leaves to the user.
+var demoExtra = []SchemaField{
+	{ID: "documents.drivers_license_number", Sensitivity: "sensitive"},
+	{ID: "documents.drivers_license_state", Sensitivity: "standard"},
+	{ID: "documents.drivers_license_expiry", Sensitivity: "standard"},
+}
+
+// DemoData returns realistic but made-up values for every field in the
+// built-in schema and the schema packs, plus a few user-defined ones. The
+// same seed always gives the same values. Phone numbers are in the 555-01xx
+// range set aside for fiction, emails use example.com, card numbers are the
+// networks' test numbers, and SSNs start with 9, which is never issued.
+func DemoData(seed int64) []DemoField {
+	r := rand.New(rand.NewPCG(uint64(seed), 0x5eed))
+	pick := func(s []string) string { return s[r.IntN(len(s))] }
+	code := func(n int, chars string) string {
+		var b strings.Builder
+		for range n {
+			b.WriteByte(chars[r.IntN(len(chars))])
+		}
+		return b.String()
+	}
+	digits := func(n int) string { return code(n, "0123456789") }
+	date := func(from, to int) string {
+		return fmt.Sprintf("%04d-%02d-%02d", from+r.IntN(to-from+1), 1+r.IntN(12), 1+r.IntN(28))
+	}
+	person := func(last string) demoPerson {
+		p := demoPerson{first: pick(demoFirstNames), last: last}
+		p.email = strings.ToLower(p.first+"."+p.last) + "@example.com"
+		p.phone = "+1" + pick([]string{"415", "503", "512", "720", "802"}) + "55501" + digits(2)
+		p.dob = date(1955, 2000)
+		return p
+	}
+
+	self := person(pick(demoLastNames))
+	spouse := person(self.last)
+	emergency := person(pick(demoLastNames))
+	place := demoPlaces[r.IntN(len(demoPlaces))]
+	card := demoCards[r.IntN(len(demoCards))]
+	vehicle := demoVehicles[r.IntN(len(demoVehicles))]
+
+	values := map[string]string{
+		"identity.first_name":    self.first,
+		"identity.last_name":     self.last,
+		"identity.full_name":     self.first + " " + self.last,
+		"identity.email":         self.email,
+		"identity.phone":         self.phone,
+		"identity.date_of_birth": self.dob,
+
+		"addresses.home_street":  fmt.Sprintf("%d %s", 100+r.IntN(9900), pick(demoStreets)),
+		"addresses.home_city":    place.city,
+		"addresses.home_state":   place.state,
+		"addresses.home_zip":     place.zip,
+		"addresses.home_country": "US",
+
+		"financial.filing_status": pick(demoFiling),
+		"financial.ssn":           fmt.Sprintf("9%s-%s-%s", digits(2), digits(2), digits(4)),
+
+		"payment.card_number":     card.number,
+		"payment.card_expiry":     fmt.Sprintf("%02d/%02d", 1+r.IntN(12), 28+r.IntN(5)),
+		"payment.cardholder_name": strings.ToUpper(self.first + " " + self.last),
+		"payment.card_brand":      card.brand,
+
+		"preferences.timezone": place.tz,
+		"preferences.language": "en",
+
+		"employment.employer": pick(demoEmployers),
+		"employment.title":    pick(demoTitles),
+
+		"contacts.spouse.full_name":       spouse.first + " " + spouse.last,
+		"contacts.spouse.phone":           spouse.phone,
+		"contacts.spouse.email":           spouse.email,
+		"contacts.spouse.date_of_birth":   spouse.dob,
+		"contacts.emergency.full_name":    emergency.first + " " + emergency.last,
+		"contacts.emergency.phone":        emergency.phone,
+		"contacts.emergency.relationship": pick(demoRelations),
+
+		"vehicles.make":                vehicle.make,
+		"vehicles.model":               vehicle.model,
+		"vehicles.year":                fmt.Sprint(2012 + r.IntN(13)),
+		"vehicles.vin":                 code(17, "ABCDEFGHJKLMNPRSTUVWXYZ0123456789"), // no I, O, or Q
+		"vehicles.plate":               code(1, "123456789") + code(3, "ABCDEFGHJKLMNPRSTUVWXYZ") + digits(3),
+		"vehicles.registration_expiry": date(2027, 2028),
+
+		"insurance.auto_provider":        pick(demoInsurers),
+		"insurance.auto_policy_number":   "AP-" + digits(9),
+		"insurance.health_provider":      pick(demoInsurers),
+		"insurance.health_policy_number": "GRP-" + digits(6),
+		"insurance.health_member_id":     "M" + digits(9),
+		"insurance.home_provider":        pick(demoInsurers),
+		"insurance.home_policy_number":   "HO-" + digits(8),
+
+		"travel.passport_number":         digits(9),
+		"travel.passport_country":        "US",
+		"travel.passport_expiry":         date(2028, 2035),
+		"travel.visa_country":            pick(demoVisa),
+		"travel.visa_number":             "V" + digits(8),
+		"travel.visa_expiry":             date(2027, 2030),
+		"travel.known_traveler_number":   digits(9),
+		"travel.trusted_traveler_expiry": date(2027, 2031),
+		"travel.redress_number":          digits(7),
+
+		"medical.blood_type":          pick(demoBloodTypes),
+		"medical.allergies":           pick(demoAllergies),
+		"medical.medications":         pick(demoMeds),
+		"medical.physician_name":      "Dr. " + pick(demoFirstNames) + " " + pick(demoLastNames),
+		"medical.physician_phone":     "+1" + pick([]string{"415", "503", "512"}) + "55501" + digits(2),
+		"medical.insurance_member_id": "M" + digits(9),
+
+		"documents.drivers_license_number": code(1, "ABCDEFGHJKLMNPRSTUVWXYZ") + digits(7),
+		"documents.drivers_license_state":  place.state,
+		"documents.drivers_license_expiry": date(2027, 2032),
+	}
+
+	var out []DemoField
+	for _, f := range demoSchemaFields() {
+		if v, ok := values[f.ID]; ok {
+			out = append(out, DemoField{ID: f.ID, Value: v, Sensitivity: f.Sensitivity})
+		}
+	}
+	return out
+}
+
+// demoSchemaFields lists the built-in schema's fields, then the packs',
+// then demoExtra, each once.
+func demoSchemaFields() []SchemaField {
+	var fields []SchemaField
+	add := func(cats []SchemaCategory) {
+		for _, c := range cats {
+			for _, f := range c.Fields {
+				if !slices.ContainsFunc(fields, func(g SchemaField) bool { return g.ID == f.ID }) {
+					fields = append(fields, f)
+				}
+			}
+		}
+	}
+	add(builtinCategories)
+	for _, p := range SchemaPacks {
+		add(p.Categories)
+	}
+	return append(fields, demoExtra...)
+}
+
+// LoadDemo writes DemoData(seed) to the vault in one transaction and
+// returns how many fields it wrote.
+func (v *Vault) LoadDemo(seed int64) (int, error) {
+	data := DemoData(seed)
+	ops := make([]TxOp, len(data))
+	for i, f := range data {
+		ops[i] = TxOp{Op: TxSet, ID: f.ID, Value: f.Value, Sensitivity: f.Sensitivity}
+	}
+	if _, _, err := v.Apply(ops, "", "demo"); err != nil {
+		return 0, err
+	}
+	return len(ops), nil
+}
@@ -0,0 +1,65 @@
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// digestKeyInfo is the HKDF info for the context digest key.
+const digestKeyInfo = ":digest"
+
+// ContextDigest summarizes a context bundle so a client can tell whether it
+// changed without fetching it: one hash per category and one over them all.
+// The hashes are keyed with the vault's own key, so they can only be
+// compared, never checked against a guessed value.
+type ContextDigest struct {
+	Digest     string            `json:"digest"`
+	Categories map[string]string `json:"categories"`
+}
+
+// Digest hashes bundle's IDs, values, and sensitivity tiers, and the
+// overall hash its links. Field order, versions, and timestamps don't count:
+// rewriting a field with the value it had leaves its category's hash as it
+// was.
+func (v *Vault) Digest(bundle *ContextBundle) (*ContextDigest, error) {
+	key, err := v.subkey(digestKeyInfo)
+	if err != nil {
+		return nil, err
+	}
+	d := &ContextDigest{Categories: make(map[string]string, len(bundle.Categories))}
+	for cat, fields := range bundle.Categories {
+		sorted := slices.Clone(fields)
+		slices.SortFunc(sorted, func(a, b FieldInfo) int { return strings.Compare(a.ID, b.ID) })
+		m := hmac.New(sha256.New, key)
+		for _, f := range sorted {
+			writeDigestString(m, f.ID)
+			writeDigestString(m, storedValue(f))
+			writeDigestString(m, f.Sensitivity)
+		}
+		d.Categories[cat] = hex.EncodeToString(m.Sum(nil))
+	}
+
+	m := hmac.New(sha256.New, key)
+	for _, cat := range slices.Sorted(maps.Keys(d.Categories)) {
+		writeDigestString(m, cat)
+		writeDigestString(m, d.Categories[cat])
+	}
+	for _, l := range bundle.Links {
+		writeDigestString(m, l.key())
+	}
+	d.Digest = hex.EncodeToString(m.Sum(nil))
+	return d, nil
+}
+
+// writeDigestString writes s length-prefixed, so that different field
+// lists can't run together into the same input.
+func writeDigestString(h hash.Hash, s string) {
+	h.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	h.Write([]byte(s))
+}
@@ -0,0 +1,92 @@
+package vault
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// DefaultElevationTTL is how long a step-up elevation lasts after the
+// password is re-entered.
+const DefaultElevationTTL = 5 * time.Minute
+
+// Elevate re-checks the password for the session token belongs to and issues
+// a short-lived elevation token, which high-risk operations (critical reads,
+// exports, wildcard service tokens) require on top of the session. Each call
+// replaces the session's previous elevation.
+func (v *Vault) Elevate(token, password string) (string, time.Time, error) {
+	v.mu.RLock()
+	s := v.sessionByToken(token)
+	sk := append([]byte(nil), v.secretKey...)
+	salt := v.salt
+	v.mu.RUnlock()
+	defer clear(sk)
+	if s == nil {
+		return "", time.Time{}, ErrSessionNotFound
+	}
+
+	// The secret key is held for the life of the unlock, so the password
+	// alone re-derives the vault key.
+	derived := crypto.DeriveVaultKey([]byte(password), sk, salt)
+	defer clear(derived)
+	current := s.VaultKey()
+	defer clear(current)
+	if current == nil {
+		return "", time.Time{}, ErrSessionNotFound
+	}
+	if subtle.ConstantTimeCompare(derived, current) != 1 {
+		v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "elevate_denied", Purpose: sessionPurpose(s)})
+		return "", time.Time{}, ErrWrongPassword
+	}
+
+	elevation, expires, err := s.elevate(DefaultElevationTTL)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "elevate", Purpose: sessionPurpose(s)})
+	return elevation, expires, nil
+}
+
+// CheckElevation reports whether elevation is a live elevation token issued
+// to the session token belongs to.
+func (v *Vault) CheckElevation(token, elevation string) bool {
+	if elevation == "" {
+		return false
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	s := v.sessionByToken(token)
+	return s != nil && s.checkElevation(elevation)
+}
+
+// elevate issues a fresh elevation token for the session, valid for ttl.
+// Only its hash is kept.
+func (s *Session) elevate(ttl time.Duration) (string, time.Time, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(b)
+	h := sha256.Sum256([]byte(token))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elevation = h[:]
+	s.elevatedUntil = time.Now().Add(ttl)
+	return token, s.elevatedUntil, nil
+}
+
+func (s *Session) checkElevation(token string) bool {
+	h := sha256.Sum256([]byte(token))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.elevation == nil || time.Now().After(s.elevatedUntil) {
+		return false
+	}
+	return subtle.ConstantTimeCompare(s.elevation, h[:]) == 1
+}
@@ -0,0 +1,264 @@
+package vault
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// Emergency request states.
+const (
+	EmergencyPending  = "pending"
+	EmergencyDenied   = "denied"
+	EmergencyReleased = "released"
+)
+
+const (
+	// DefaultEmergencyWait is how long the owner has to deny a request.
+	DefaultEmergencyWait = 72 * time.Hour
+	// MinEmergencyWait is the shortest waiting period a contact can be
+	// given, so the owner always has a real chance to deny a request.
+	MinEmergencyWait = time.Hour
+)
+
+var (
+	ErrEmergencyWait         = errors.New("waiting period must be at least an hour")
+	ErrEmergencyContactName  = errors.New("contact name may only contain letters, digits, underscores, and hyphens")
+	ErrEmergencyNoBundle     = errors.New("bundle required")
+	ErrEmergencyNotFound     = errors.New("no such emergency contact")
+	ErrEmergencyCode         = errors.New("unknown contact or wrong access code")
+	ErrEmergencyNotRequested = errors.New("no emergency access request is pending")
+	ErrEmergencyDenied       = errors.New("the owner denied this emergency access request")
+	ErrEmergencyEncryptedDB  = errors.New("emergency contacts can't be set up on a vault created with --encrypt-db: they would be unreadable while it is locked, when a contact needs them")
+)
+
+// EmergencyWaitingError is returned when a contact asks for the bundle
+// before the waiting period is over.
+type EmergencyWaitingError struct {
+	ReleaseAt time.Time
+}
+
+func (e *EmergencyWaitingError) Error() string {
+	return "waiting period ends at " + e.ReleaseAt.UTC().Format(time.RFC3339)
+}
+
+// ReleaseAt is when a pending request for c is released unless denied.
+func ReleaseAt(c *store.EmergencyContact) time.Time {
+	return c.RequestedAt.Add(c.Wait)
+}
+
+// SetEmergencyContact stores a bundle sealed to a contact (see 'pvault
+// escrow export') and returns the access code the contact needs to request
+// it. Replacing a contact resets any request in progress. A fully encrypted
+// database can't serve contacts while locked, so it refuses them.
+func (v *Vault) SetEmergencyContact(name, recipient, bundle string, wait time.Duration) (string, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return "", err
+	}
+	if _, ok := v.db.(store.Sealer); ok {
+		return "", ErrEmergencyEncryptedDB
+	}
+	if !validIDPart.MatchString(name) {
+		return "", ErrEmergencyContactName
+	}
+	if wait < MinEmergencyWait {
+		return "", ErrEmergencyWait
+	}
+	if strings.TrimSpace(bundle) == "" {
+		return "", ErrEmergencyNoBundle
+	}
+
+	b := make([]byte, 15)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	raw := base32.StdEncoding.EncodeToString(b) // 24 characters, no padding
+	var groups []string
+	for i := 0; i < len(raw); i += 4 {
+		groups = append(groups, raw[i:i+4])
+	}
+	code := strings.Join(groups, "-")
+
+	v.emergencyMu.Lock()
+	defer v.emergencyMu.Unlock()
+	err := v.db.PutEmergencyContact(store.EmergencyContact{
+		Name:      name,
+		Recipient: recipient,
+		CodeHash:  hashEmergencyCode(code),
+		Wait:      wait,
+		Bundle:    bundle,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "emergency_setup", Purpose: "contact: " + name})
+	return code, nil
+}
+
+// hashEmergencyCode hashes an access code, ignoring case, spaces, and dashes
+// so it survives being read over the phone.
+func hashEmergencyCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	h := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(h[:])
+}
+
+// EmergencyContacts lists the configured contacts and their request state.
+func (v *Vault) EmergencyContacts() ([]store.EmergencyContact, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	return v.db.ListEmergencyContacts()
+}
+
+// RemoveEmergencyContact deletes a contact and its bundle.
+func (v *Vault) RemoveEmergencyContact(name string) error {
+	if _, err := v.requireUnlocked(); err != nil {
+		return err
+	}
+	v.emergencyMu.Lock()
+	defer v.emergencyMu.Unlock()
+	n, err := v.db.DeleteEmergencyContact(name)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrEmergencyNotFound
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "emergency_remove", Purpose: "contact: " + name})
+	return nil
+}
+
+// contactByCode returns the contact if code is theirs. Unknown contacts and
+// wrong codes fail alike, so the endpoint can't be used to probe for names.
+// Callers hold emergencyMu.
+func (v *Vault) contactByCode(name, code string) (*store.EmergencyContact, error) {
+	c, err := v.db.GetEmergencyContact(name)
+	if err != nil {
+		return nil, sealedAsLocked(err)
+	}
+	if c == nil || subtle.ConstantTimeCompare([]byte(c.CodeHash), []byte(hashEmergencyCode(code))) != 1 {
+		return nil, ErrEmergencyCode
+	}
+	return c, nil
+}
+
+// sealedAsLocked reports a sealed encrypted database as a locked vault: its
+// emergency contacts are only readable while unlocked.
+func sealedAsLocked(err error) error {
+	if errors.Is(err, store.ErrSealed) {
+		return ErrLocked
+	}
+	return err
+}
+
+// RequestEmergencyAccess starts a contact's waiting period. It works while
+// the vault is locked. Asking again while a request is pending doesn't
+// restart the clock; asking after a denial starts a new one.
+func (v *Vault) RequestEmergencyAccess(name, code string) (*store.EmergencyContact, error) {
+	v.emergencyMu.Lock()
+	defer v.emergencyMu.Unlock()
+	c, err := v.contactByCode(name, code)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status == EmergencyPending || c.Status == EmergencyReleased {
+		return c, nil
+	}
+
+	now := time.Now()
+	c.Status, c.RequestedAt, c.DecidedAt = EmergencyPending, now, time.Time{}
+	if err := v.db.PutEmergencyContact(*c); err != nil {
+		return nil, sealedAsLocked(err)
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "emergency:" + name, Scope: "*", Action: "emergency_request"})
+	v.notify(Event{Type: EventEmergencyRequested, Contact: name, Time: now, ReleaseAt: ReleaseAt(c)})
+	return c, nil
+}
+
+// DenyEmergencyAccess refuses a pending request. Only the owner can, so the
+// vault must be unlocked.
+func (v *Vault) DenyEmergencyAccess(name string) error {
+	if _, err := v.requireUnlocked(); err != nil {
+		return err
+	}
+	v.emergencyMu.Lock()
+	defer v.emergencyMu.Unlock()
+	c, err := v.db.GetEmergencyContact(name)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return ErrEmergencyNotFound
+	}
+	if c.Status != EmergencyPending {
+		return ErrEmergencyNotRequested
+	}
+
+	now := time.Now()
+	c.Status, c.DecidedAt = EmergencyDenied, now
+	if err := v.db.PutEmergencyContact(*c); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "emergency_deny", Purpose: "contact: " + name})
+	v.notify(Event{Type: EventEmergencyDenied, Contact: name, Time: now})
+	return nil
+}
+
+// ReleaseDueEmergencies releases every pending request whose waiting period
+// has ended by now and returns the contacts released. The server calls it
+// on a timer so the owner hears about a release when it happens.
+func (v *Vault) ReleaseDueEmergencies(now time.Time) ([]store.EmergencyContact, error) {
+	v.emergencyMu.Lock()
+	defer v.emergencyMu.Unlock()
+	contacts, err := v.db.ListEmergencyContacts()
+	if err != nil {
+		return nil, sealedAsLocked(err)
+	}
+	var released []store.EmergencyContact
+	for _, c := range contacts {
+		if c.Status != EmergencyPending || now.Before(ReleaseAt(&c)) {
+			continue
+		}
+		c.Status, c.DecidedAt = EmergencyReleased, now
+		if err := v.db.PutEmergencyContact(c); err != nil {
+			return released, err
+		}
+		v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "emergency_release", Purpose: "contact: " + c.Name})
+		v.notify(Event{Type: EventEmergencyReleased, Contact: c.Name, Time: now})
+		released = append(released, c)
+	}
+	return released, nil
+}
+
+// EmergencyBundle returns a contact's sealed bundle once their request has
+// been released, releasing it first if the waiting period has just ended.
+func (v *Vault) EmergencyBundle(name, code string) (string, error) {
+	if _, err := v.ReleaseDueEmergencies(time.Now()); err != nil {
+		return "", err
+	}
+	v.emergencyMu.Lock()
+	defer v.emergencyMu.Unlock()
+	c, err := v.contactByCode(name, code)
+	if err != nil {
+		return "", err
+	}
+	switch c.Status {
+	case EmergencyReleased:
+		v.db.LogAccess(store.AuditEntry{Consumer: "emergency:" + name, Scope: "*", Action: "emergency_download"})
+		return c.Bundle, nil
+	case EmergencyPending:
+		return "", &EmergencyWaitingError{ReleaseAt: ReleaseAt(c)}
+	case EmergencyDenied:
+		return "", ErrEmergencyDenied
+	}
+	return "", ErrEmergencyNotRequested
+}
@@ -0,0 +1,292 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+var (
+	ErrNoEnricher         = errors.New("no address enricher configured")
+	ErrSuggestionNotFound = errors.New("suggestion not found")
+)
+
+// Address is a postal address as exchanged with an AddressEnricher.
+type Address struct {
+	Street  string `json:"street,omitempty"`
+	City    string `json:"city,omitempty"`
+	State   string `json:"state,omitempty"`
+	Zip     string `json:"zip,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+// AddressEnricher validates and completes an address, e.g. filling in the
+// city and ZIP for a street. It returns the address as it should read; an
+// empty part means no opinion, not "clear this".
+type AddressEnricher interface {
+	EnrichAddress(ctx context.Context, a Address) (Address, error)
+}
+
+// ValueSuggestion is a value proposed for a field by an enricher. It is held
+// until the user accepts or dismisses it and is never written on its own.
+type ValueSuggestion struct {
+	ID        string    `json:"id"`
+	FieldID   string    `json:"field_id"`
+	Current   string    `json:"current,omitempty"`
+	Suggested string    `json:"suggested"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// addressParts maps an address prefix's field suffixes to Address parts.
+var addressParts = []struct {
+	suffix string
+	get    func(*Address) *string
+}{
+	{"street", func(a *Address) *string { return &a.Street }},
+	{"city", func(a *Address) *string { return &a.City }},
+	{"state", func(a *Address) *string { return &a.State }},
+	{"zip", func(a *Address) *string { return &a.Zip }},
+	{"country", func(a *Address) *string { return &a.Country }},
+}
+
+// enrichTimeout bounds a single enricher call.
+const enrichTimeout = 10 * time.Second
+
+// SetEnricher configures the service used by EnrichAddress. source names it
+// in suggestions and the audit log; a nil enricher turns enrichment off.
+func (v *Vault) SetEnricher(e AddressEnricher, source string) {
+	v.sugMu.Lock()
+	defer v.sugMu.Unlock()
+	v.enricher = e
+	v.enrichSource = source
+}
+
+// HasEnricher reports whether an address enricher is configured.
+func (v *Vault) HasEnricher() bool {
+	v.sugMu.Lock()
+	defer v.sugMu.Unlock()
+	return v.enricher != nil
+}
+
+// EnrichAddress sends the address stored under addresses.<prefix>_* to the
+// configured enricher and records every part it would change as a pending
+// suggestion. Nothing is written to the vault. A new suggestion for a field
+// replaces any earlier one.
+func (v *Vault) EnrichAddress(ctx context.Context, prefix string) ([]ValueSuggestion, error) {
+	v.sugMu.Lock()
+	enricher, source := v.enricher, v.enrichSource
+	v.sugMu.Unlock()
+	if enricher == nil {
+		return nil, ErrNoEnricher
+	}
+	if err := ValidateFieldID("addresses." + prefix + "_street"); err != nil {
+		return nil, err
+	}
+	subkey, err := v.subkey("addresses")
+	if err != nil {
+		return nil, err
+	}
+
+	var current Address
+	var sent []string
+	for _, p := range addressParts {
+		id := "addresses." + prefix + "_" + p.suffix
+		f, err := v.db.GetField(id)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			continue
+		}
+		value, err := v.decryptValue(subkey, "addresses", id, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := DecodeEntries(value); ok {
+			continue // a list isn't one address to enrich
+		}
+		*p.get(&current) = value
+		sent = append(sent, id)
+	}
+	if len(sent) == 0 {
+		return []ValueSuggestion{}, nil
+	}
+
+	// The enricher sees these values, so it is recorded as their reader.
+	for _, id := range sent {
+		v.db.LogAccess(store.AuditEntry{Consumer: source, Scope: id, Action: "enrich"})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, enrichTimeout)
+	defer cancel()
+	enriched, err := enricher.EnrichAddress(ctx, current)
+	if err != nil {
+		return nil, fmt.Errorf("enrich address: %w", err)
+	}
+
+	now := time.Now()
+	suggestions := []ValueSuggestion{}
+	for _, p := range addressParts {
+		id := "addresses." + prefix + "_" + p.suffix
+		proposed := Normalize(id, *p.get(&enriched))
+		have := *p.get(&current)
+		if strings.TrimSpace(proposed) == "" || proposed == have {
+			continue
+		}
+		suggestions = append(suggestions, ValueSuggestion{
+			ID:        newSuggestionID(),
+			FieldID:   id,
+			Current:   have,
+			Suggested: proposed,
+			Source:    source,
+			CreatedAt: now,
+		})
+	}
+
+	v.sugMu.Lock()
+	for _, s := range suggestions {
+		v.suggestions = slices.DeleteFunc(v.suggestions, func(old ValueSuggestion) bool {
+			return old.FieldID == s.FieldID
+		})
+		v.suggestions = append(v.suggestions, s)
+	}
+	v.sugMu.Unlock()
+	return suggestions, nil
+}
+
+// Suggestions returns pending value suggestions, oldest first.
+func (v *Vault) Suggestions() ([]ValueSuggestion, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	v.sugMu.Lock()
+	defer v.sugMu.Unlock()
+	return append([]ValueSuggestion{}, v.suggestions...), nil
+}
+
+// AcceptSuggestion writes a pending suggestion to its field, keeping the
+// field's sensitivity, and returns the value as stored.
+func (v *Vault) AcceptSuggestion(id string) (string, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return "", err
+	}
+	s, ok := v.takeSuggestion(id)
+	if !ok {
+		return "", ErrSuggestionNotFound
+	}
+	sensitivity := DefaultSensitivity(s.FieldID)
+	if f, err := v.db.GetField(s.FieldID); err != nil {
+		return "", err
+	} else if f != nil {
+		sensitivity = f.Sensitivity
+	}
+	return v.SetWithOptions(s.FieldID, s.Suggested, SetOptions{Sensitivity: sensitivity})
+}
+
+// DismissSuggestion drops a pending suggestion without writing it.
+func (v *Vault) DismissSuggestion(id string) error {
+	if _, err := v.requireUnlocked(); err != nil {
+		return err
+	}
+	if _, ok := v.takeSuggestion(id); !ok {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+func (v *Vault) takeSuggestion(id string) (ValueSuggestion, bool) {
+	v.sugMu.Lock()
+	defer v.sugMu.Unlock()
+	i := slices.IndexFunc(v.suggestions, func(s ValueSuggestion) bool { return s.ID == id })
+	if i < 0 {
+		return ValueSuggestion{}, false
+	}
+	s := v.suggestions[i]
+	v.suggestions = slices.Delete(v.suggestions, i, i+1)
+	return s, true
+}
+
+func newSuggestionID() string {
+	b := make([]byte, 8)
+	crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// HTTPEnricher posts the address as JSON to URL and reads the enriched
+// address back as JSON.
+type HTTPEnricher struct {
+	URL    string
+	Client *http.Client // nil means http.DefaultClient
+}
+
+func (e *HTTPEnricher) EnrichAddress(ctx context.Context, a Address) (Address, error) {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return Address{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return Address{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Address{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Address{}, fmt.Errorf("enricher returned %s", resp.Status)
+	}
+	var out Address
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&out); err != nil {
+		return Address{}, fmt.Errorf("decode enricher response: %w", err)
+	}
+	return out, nil
+}
+
+// CommandEnricher runs a local program with the address as JSON on stdin and
+// reads the enriched address as JSON from stdout.
+type CommandEnricher struct {
+	Path string
+	Args []string
+}
+
+func (e *CommandEnricher) EnrichAddress(ctx context.Context, a Address) (Address, error) {
+	in, err := json.Marshal(a)
+	if err != nil {
+		return Address{}, err
+	}
+	cmd := exec.CommandContext(ctx, e.Path, e.Args...)
+	cmd.Stdin = bytes.NewReader(in)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return Address{}, fmt.Errorf("%w: %s", err, msg)
+		}
+		return Address{}, err
+	}
+	var enriched Address
+	if err := json.Unmarshal(out, &enriched); err != nil {
+		return Address{}, fmt.Errorf("decode enricher output: %w", err)
+	}
+	return enriched, nil
+}
@@ -0,0 +1,16 @@
+package vault
+
+// SelectEscrow picks the fields of an export bundle to hand to a trusted
+// contact: those a scope allows, or with an empty scope every critical field.
+// Categories left empty are dropped.
+func SelectEscrow(b *ContextBundle, scope string) *ContextBundle {
+	out := &ContextBundle{Categories: make(map[string][]FieldInfo)}
+	for category, fields := range b.Categories {
+		for _, f := range fields {
+			if scope == "" && f.Sensitivity == "critical" || scope != "" && ScopeAllows(scope, f.ID) {
+				out.Categories[category] = append(out.Categories[category], f)
+			}
+		}
+	}
+	return out
+}
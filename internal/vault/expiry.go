@@ -0,0 +1,134 @@
+package vault
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultExpiryWindow is how far ahead expiring fields are reported when
+// no window is given.
+const DefaultExpiryWindow = 90 * 24 * time.Hour
+
+const (
+	// expiryNoticesMetaKey holds the expiry dates already notified, so each
+	// date is announced once. Encrypted, since it names fields.
+	expiryNoticesMetaKey = "expiry_notices"
+
+	// expiryKeyInfo is the HKDF info for the expiry notice table key.
+	expiryKeyInfo = ":expiry"
+)
+
+// expiryLayouts are the date forms an expiry field may hold. A month alone
+// expires at the end of that month.
+var expiryLayouts = []string{"2006-01-02", "2006-01", "01/2006", "01/06"}
+
+// ExpiringField is a field holding a date that is coming up or has passed.
+type ExpiringField struct {
+	ID          string `json:"id"`
+	Sensitivity string `json:"sensitivity"`
+	Expires     string `json:"expires"`   // YYYY-MM-DD
+	DaysLeft    int    `json:"days_left"` // negative once expired
+}
+
+// IsExpiryField reports whether field id holds an expiry date: one the
+// schema marks as such, or a field of your own whose name ends in _expiry.
+func IsExpiryField(id string) bool {
+	if f := GetSchemaField(id); f != nil {
+		return f.Expiry
+	}
+	return strings.HasSuffix(id, "_expiry")
+}
+
+// ParseExpiry reads an expiry date in one of the forms expiryLayouts lists.
+func ParseExpiry(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range expiryLayouts {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(layout, "02") {
+			t = t.AddDate(0, 1, -1)
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// Expiring returns the expiry fields whose dates fall before now+within,
+// including those already past, soonest first. Values that aren't dates
+// are skipped.
+func (v *Vault) Expiring(now time.Time, within time.Duration) ([]ExpiringField, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	fields, err := v.db.ListFields()
+	if err != nil {
+		return nil, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var out []ExpiringField
+	for _, f := range fields {
+		if !IsExpiryField(f.ID) {
+			continue
+		}
+		stored, err := v.db.GetField(f.ID)
+		if err != nil || stored == nil {
+			continue
+		}
+		subkey, err := v.subkey(stored.Category)
+		if err != nil {
+			return nil, err
+		}
+		value, err := v.decryptValue(subkey, stored.Category, f.ID, stored.Value)
+		if err != nil {
+			continue
+		}
+		var info FieldInfo
+		info.setValue(value)
+		date, ok := ParseExpiry(info.Value)
+		if !ok || !date.Before(now.Add(within)) {
+			continue
+		}
+		out = append(out, ExpiringField{
+			ID:          f.ID,
+			Sensitivity: f.Sensitivity,
+			Expires:     date.Format("2006-01-02"),
+			DaysLeft:    int(date.Sub(today).Hours() / 24),
+		})
+	}
+	slices.SortFunc(out, func(a, b ExpiringField) int {
+		return strings.Compare(a.Expires+a.ID, b.Expires+b.ID)
+	})
+	return out, nil
+}
+
+// NotifyExpiring sends a field_expiring event for each expiry field coming
+// up within the window, once per date: a renewed passport is announced
+// again when its new date comes up.
+func (v *Vault) NotifyExpiring(now time.Time, within time.Duration) error {
+	v.expiryMu.Lock()
+	defer v.expiryMu.Unlock()
+	expiring, err := v.Expiring(now, within)
+	if err != nil {
+		return err
+	}
+	notices, err := v.notified.load()
+	if err != nil {
+		return err
+	}
+	next := make(map[string]string)
+	for _, f := range expiring {
+		next[f.ID] = f.Expires
+		if notices[f.ID] == f.Expires {
+			continue
+		}
+		v.notify(Event{Type: EventFieldExpiring, Field: f.ID, Expires: f.Expires, Time: now})
+	}
+	if maps.Equal(notices, next) {
+		return nil
+	}
+	return v.notified.save(next)
+}
@@ -0,0 +1,200 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Merge strategies for PlanImport.
+const (
+	MergeKeepNewest   = "keep-newest"   // the side updated last wins; ties go to the higher version, then the vault
+	MergeKeepExisting = "keep-existing" // only fields missing from the vault are imported
+	MergeInteractive  = "interactive"   // conflicts are left for the caller to resolve
+)
+
+// Conflict resolutions.
+const (
+	ResolveKeep   = "keep"   // leave the vault value
+	ResolveImport = "import" // overwrite with the file value
+)
+
+var ErrInvalidMergeStrategy = errors.New("merge strategy must be keep-newest, keep-existing, or interactive")
+
+// ImportConflict is a field present in both the vault and an import file
+// with different values.
+type ImportConflict struct {
+	ID         string    `json:"id"`
+	Existing   FieldInfo `json:"existing"`
+	Incoming   FieldInfo `json:"incoming"`
+	Resolution string    `json:"resolution,omitempty"` // empty until resolved under MergeInteractive
+}
+
+// ImportDeletion is a field an incremental export reports deleted that the
+// vault still holds. ResolveImport deletes it here too.
+type ImportDeletion struct {
+	ID         string    `json:"id"`
+	Existing   FieldInfo `json:"existing"`
+	Version    int       `json:"version"` // the field's version when it was deleted
+	DeletedAt  time.Time `json:"deleted_at"`
+	Resolution string    `json:"resolution,omitempty"` // empty until resolved under MergeInteractive
+}
+
+// ImportPlan is the diff between the vault and an import file, computed
+// before anything is written.
+type ImportPlan struct {
+	Added     []FieldInfo      `json:"added"`
+	Unchanged int              `json:"unchanged"`
+	Conflicts []ImportConflict `json:"conflicts"`
+	Deletions []ImportDeletion `json:"deletions"`
+}
+
+// PlanImport diffs an import file (in the format of GetContext, as written
+// by 'pvault export') against the vault's current contents by field ID and
+// resolves conflicts with the given strategy. deleted holds the deletions an
+// incremental export ('pvault export --since') reports; a deletion is a
+// conflict with the vault's value, so keep-existing never applies one and
+// keep-newest applies it unless the vault's field changed after it.
+func PlanImport(existing, incoming *ContextBundle, deleted []DeletedField, strategy string) (*ImportPlan, error) {
+	switch strategy {
+	case MergeKeepNewest, MergeKeepExisting, MergeInteractive:
+	default:
+		return nil, ErrInvalidMergeStrategy
+	}
+
+	current := make(map[string]FieldInfo)
+	for _, fields := range existing.Categories {
+		for _, f := range fields {
+			current[f.ID] = f
+		}
+	}
+
+	plan := &ImportPlan{Added: []FieldInfo{}, Conflicts: []ImportConflict{}, Deletions: []ImportDeletion{}}
+	seen := make(map[string]bool)
+	for _, fields := range incoming.Categories {
+		for _, in := range fields {
+			if err := ValidateFieldID(in.ID); err != nil {
+				return nil, fmt.Errorf("import %q: %w", in.ID, err)
+			}
+			if seen[in.ID] {
+				return nil, fmt.Errorf("import %q: listed more than once", in.ID)
+			}
+			seen[in.ID] = true
+
+			have, ok := current[in.ID]
+			switch {
+			case !ok:
+				plan.Added = append(plan.Added, in)
+			case storedValue(have) == storedValue(in):
+				plan.Unchanged++
+			default:
+				c := ImportConflict{ID: in.ID, Existing: have, Incoming: in}
+				switch strategy {
+				case MergeKeepExisting:
+					c.Resolution = ResolveKeep
+				case MergeKeepNewest:
+					c.Resolution = ResolveKeep
+					if incomingIsNewer(have, in) {
+						c.Resolution = ResolveImport
+					}
+				}
+				plan.Conflicts = append(plan.Conflicts, c)
+			}
+		}
+	}
+
+	for _, d := range deleted {
+		if seen[d.ID] {
+			return nil, fmt.Errorf("import %q: listed more than once", d.ID)
+		}
+		seen[d.ID] = true
+		have, ok := current[d.ID]
+		if !ok {
+			continue // already gone
+		}
+		del := ImportDeletion{ID: d.ID, Existing: have, Version: d.Version, DeletedAt: d.DeletedAt}
+		switch strategy {
+		case MergeKeepExisting:
+			del.Resolution = ResolveKeep
+		case MergeKeepNewest:
+			del.Resolution = ResolveKeep
+			if deletionIsNewer(have, d) {
+				del.Resolution = ResolveImport
+			}
+		}
+		plan.Deletions = append(plan.Deletions, del)
+	}
+
+	sort.Slice(plan.Added, func(i, j int) bool { return plan.Added[i].ID < plan.Added[j].ID })
+	sort.Slice(plan.Conflicts, func(i, j int) bool { return plan.Conflicts[i].ID < plan.Conflicts[j].ID })
+	sort.Slice(plan.Deletions, func(i, j int) bool { return plan.Deletions[i].ID < plan.Deletions[j].ID })
+	return plan, nil
+}
+
+func incomingIsNewer(have, in FieldInfo) bool {
+	if !in.UpdatedAt.Equal(have.UpdatedAt) {
+		return in.UpdatedAt.After(have.UpdatedAt)
+	}
+	return in.Version > have.Version
+}
+
+// PlanMerge diffs another copy of the vault against this one, both as read
+// by ChangesSince with a zero time, field by field: whichever side changed a
+// field last wins, and a deletion counts as a change. A field the other copy
+// holds that this one deleted later stays deleted and counts as unchanged.
+func PlanMerge(local, other *Changes) (*ImportPlan, error) {
+	deleted := make(map[string]DeletedField, len(local.Deleted))
+	for _, d := range local.Deleted {
+		deleted[d.ID] = d
+	}
+	incoming := &ContextBundle{Categories: make(map[string][]FieldInfo)}
+	kept := 0
+	for cat, fields := range other.Categories {
+		for _, f := range fields {
+			if d, ok := deleted[f.ID]; ok && !incomingIsNewer(FieldInfo{UpdatedAt: d.DeletedAt, Version: d.Version}, f) {
+				kept++
+				continue
+			}
+			incoming.Categories[cat] = append(incoming.Categories[cat], f)
+		}
+	}
+	plan, err := PlanImport(local.ContextBundle, incoming, other.Deleted, MergeKeepNewest)
+	if err != nil {
+		return nil, err
+	}
+	plan.Unchanged += kept
+	return plan, nil
+}
+
+// deletionIsNewer applies incomingIsNewer's rule to a deletion.
+func deletionIsNewer(have FieldInfo, d DeletedField) bool {
+	if !d.DeletedAt.Equal(have.UpdatedAt) {
+		return d.DeletedAt.After(have.UpdatedAt)
+	}
+	return d.Version > have.Version
+}
+
+// Ops returns the transaction that applies the plan: every added field, and
+// every conflict and deletion resolved to ResolveImport. Values are written
+// raw, since an export already holds them as stored.
+func (p *ImportPlan) Ops() []TxOp {
+	var ops []TxOp
+	set := func(f FieldInfo) {
+		ops = append(ops, TxOp{Op: TxSet, ID: f.ID, Value: storedValue(f), Sensitivity: f.Sensitivity, Raw: true})
+	}
+	for _, f := range p.Added {
+		set(f)
+	}
+	for _, c := range p.Conflicts {
+		if c.Resolution == ResolveImport {
+			set(c.Incoming)
+		}
+	}
+	for _, d := range p.Deletions {
+		if d.Resolution == ResolveImport {
+			ops = append(ops, TxOp{Op: TxDelete, ID: d.ID})
+		}
+	}
+	return ops
+}
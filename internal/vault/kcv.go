@@ -0,0 +1,192 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// ErrKeyMismatch means a category key no longer matches the key check value
+// stored when the category was first written: the vault was unlocked with
+// different key material than it was written with, or its metadata changed.
+var ErrKeyMismatch = errors.New("category key does not match its key check value")
+
+// CorruptFieldError reports a single value that failed to decrypt under a
+// category key known to be correct.
+type CorruptFieldError struct {
+	ID  string
+	Err error
+}
+
+func (e *CorruptFieldError) Error() string {
+	return fmt.Sprintf("field %s is corrupted: %v", e.ID, e.Err)
+}
+
+func (e *CorruptFieldError) Unwrap() error { return e.Err }
+
+// kcvMetaPrefix prefixes the meta key holding a category's key check value.
+const kcvMetaPrefix = "kcv:"
+
+// Key check states, as reported by Verify.
+const (
+	KCVOK       = "ok"
+	KCVMismatch = "mismatch"
+	KCVAdded    = "added"   // was missing; recorded by this check
+	KCVUnknown  = "unknown" // missing, and no value decrypted to vouch for the key
+	kcvMissing  = "missing"
+)
+
+// kcvMetaKey names a category's KCV entry. In blind-index mode the category
+// is replaced by its blind index so meta doesn't reveal it.
+func (v *Vault) kcvMetaKey(category string) (string, error) {
+	if bs, ok := v.db.(*blindStore); ok {
+		idx, err := bs.categoryIndex(category)
+		if err != nil {
+			return "", err
+		}
+		return kcvMetaPrefix + idx, nil
+	}
+	return kcvMetaPrefix + category, nil
+}
+
+// ensureKCV records the category's key check value if it has none yet.
+func (v *Vault) ensureKCV(category string, subkey []byte) error {
+	key, err := v.kcvMetaKey(category)
+	if err != nil {
+		return err
+	}
+	stored, err := v.db.GetMeta(key)
+	if err != nil || stored != "" {
+		return err
+	}
+	return v.db.SetMeta(key, crypto.KeyCheckValue(subkey))
+}
+
+// checkKCV compares a category key with its stored key check value.
+func (v *Vault) checkKCV(category string, subkey []byte) (string, error) {
+	key, err := v.kcvMetaKey(category)
+	if err != nil {
+		return "", err
+	}
+	stored, err := v.db.GetMeta(key)
+	if err != nil {
+		return "", err
+	}
+	switch stored {
+	case "":
+		return kcvMissing, nil
+	case crypto.KeyCheckValue(subkey):
+		return KCVOK, nil
+	}
+	return KCVMismatch, nil
+}
+
+// decryptValue decrypts a stored value. On failure the category's key check
+// value decides the blame: a mismatch is ErrKeyMismatch for the whole
+// category, a match is a CorruptFieldError for this value alone.
+func (v *Vault) decryptValue(subkey []byte, category, id, ciphertext string) (string, error) {
+	plaintext, err := crypto.DecryptFromBase64(subkey, ciphertext)
+	if err == nil {
+		return string(plaintext), nil
+	}
+	switch state, _ := v.checkKCV(category, subkey); state {
+	case KCVMismatch:
+		return "", fmt.Errorf("decrypt %s: %w", id, ErrKeyMismatch)
+	case KCVOK:
+		return "", &CorruptFieldError{ID: id, Err: err}
+	}
+	return "", fmt.Errorf("decrypt %s: %w", id, err)
+}
+
+// VerifyReport is the result of Verify.
+type VerifyReport struct {
+	OK         bool            `json:"ok"`
+	SaltMatch  bool            `json:"salt_match"` // the stored salt is the one this session derived keys with
+	Categories []CategoryCheck `json:"categories"`
+}
+
+// CategoryCheck is one category's result in a VerifyReport.
+type CategoryCheck struct {
+	Category  string   `json:"category"`
+	Fields    int      `json:"fields"`
+	KCV       string   `json:"kcv"`
+	Corrupted []string `json:"corrupted,omitempty"`
+}
+
+// Verify checks that the stored salt matches the session, that every
+// category key matches its key check value, and that every value decrypts.
+// Categories written before key check values existed get one recorded once a
+// value decrypts under the current key.
+func (v *Vault) Verify() (*VerifyReport, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	report := &VerifyReport{OK: true}
+
+	saltB64, err := v.db.GetMeta("salt")
+	if err != nil {
+		return nil, err
+	}
+	stored, err := base64.StdEncoding.DecodeString(saltB64)
+	v.mu.RLock()
+	report.SaltMatch = err == nil && bytes.Equal(stored, v.salt)
+	v.mu.RUnlock()
+	if !report.SaltMatch {
+		report.OK = false
+	}
+
+	fields, err := v.db.GetAllFields()
+	if err != nil {
+		return nil, err
+	}
+	byCategory := make(map[string][]store.Field)
+	for _, f := range fields {
+		byCategory[f.Category] = append(byCategory[f.Category], f)
+	}
+	categories := make([]string, 0, len(byCategory))
+	for c := range byCategory {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		subkey, err := v.subkey(category)
+		if err != nil {
+			return nil, err
+		}
+		check := CategoryCheck{Category: category, Fields: len(byCategory[category])}
+		for _, f := range byCategory[category] {
+			if _, err := crypto.DecryptFromBase64(subkey, f.Value); err != nil {
+				check.Corrupted = append(check.Corrupted, f.ID)
+			}
+		}
+		if check.KCV, err = v.checkKCV(category, subkey); err != nil {
+			return nil, err
+		}
+		switch check.KCV {
+		case KCVMismatch:
+			// The key is wrong, so failures say nothing about the values.
+			check.Corrupted = nil
+		case kcvMissing:
+			check.KCV = KCVUnknown
+			if len(check.Corrupted) < check.Fields {
+				if err := v.ensureKCV(category, subkey); err != nil {
+					return nil, err
+				}
+				check.KCV = KCVAdded
+			}
+		}
+		if check.KCV == KCVMismatch || check.KCV == KCVUnknown || len(check.Corrupted) > 0 {
+			report.OK = false
+		}
+		report.Categories = append(report.Categories, check)
+	}
+
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "verify"})
+	return report, nil
+}
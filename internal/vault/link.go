@@ -0,0 +1,229 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// DefaultLinkType is the type of a link made without one.
+const DefaultLinkType = "related"
+
+var (
+	ErrLinkMissing     = errors.New("only a stored field can be linked from")
+	ErrLinkNotFound    = errors.New("no such link")
+	ErrLinkExists      = errors.New("link already exists")
+	ErrInvalidLinkType = errors.New("invalid link type: use lowercase letters, digits, and underscores, up to 32")
+	ErrSelfLink        = errors.New("a field can't be linked to itself")
+)
+
+var linkTypeRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+const (
+	// linksMetaKey holds the links between fields, encrypted like the other
+	// tables that name fields.
+	linksMetaKey = "field_links"
+
+	// linkKeyInfo is the HKDF info for the link table key.
+	linkKeyInfo = ":links"
+)
+
+// FieldLink says how one field goes with others, e.g. that a card's billing
+// address is addresses.billing_*. To is a field ID, a category.*, or a
+// field ID prefix ending in *.
+type FieldLink struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (l FieldLink) key() string { return l.From + " " + l.Type + " " + l.To }
+
+// Matches reports whether field id is one the link points to.
+func (l FieldLink) Matches(id string) bool {
+	if prefix, ok := strings.CutSuffix(l.To, "*"); ok {
+		return strings.HasPrefix(id, prefix)
+	}
+	return l.To == id
+}
+
+// ValidateLinkTarget checks a link's To: a field ID, a category.*, or a
+// field ID prefix ending in *.
+func ValidateLinkTarget(to string) error {
+	prefix, ok := strings.CutSuffix(to, "*")
+	if !ok {
+		return ValidateFieldID(to)
+	}
+	if cat, ok := strings.CutSuffix(prefix, "."); ok {
+		if !validWildcardPrefix(cat) {
+			return fmt.Errorf("invalid category %q: only alphanumeric, underscore, hyphen allowed", cat)
+		}
+		return nil
+	}
+	return ValidateFieldID(prefix)
+}
+
+// decodeLinks reads the link table, stored as the list sortedLinks makes.
+func decodeLinks(data []byte) (map[string]FieldLink, error) {
+	var links []FieldLink
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, err
+	}
+	m := make(map[string]FieldLink, len(links))
+	for _, l := range links {
+		m[l.key()] = l
+	}
+	return m, nil
+}
+
+func sortedLinks(m map[string]FieldLink) []FieldLink {
+	links := slices.Collect(maps.Values(m))
+	slices.SortFunc(links, func(a, b FieldLink) int { return strings.Compare(a.key(), b.key()) })
+	return links
+}
+
+// Link records that stored field from goes with to, as linkType (related
+// if empty). An alias on either side is recorded as its target.
+func (v *Vault) Link(from, to, linkType string) error {
+	if err := ValidateFieldID(from); err != nil {
+		return err
+	}
+	if err := ValidateLinkTarget(to); err != nil {
+		return err
+	}
+	if linkType == "" {
+		linkType = DefaultLinkType
+	}
+	if !linkTypeRe.MatchString(linkType) {
+		return ErrInvalidLinkType
+	}
+	from = v.ResolveAlias(from)
+	if !strings.HasSuffix(to, "*") {
+		to = v.ResolveAlias(to)
+	}
+	if from == to {
+		return ErrSelfLink
+	}
+	if f, err := v.db.GetField(from); err != nil {
+		return err
+	} else if f == nil {
+		return ErrLinkMissing
+	}
+
+	v.links.writeMu.Lock()
+	defer v.links.writeMu.Unlock()
+	m, err := v.links.load()
+	if err != nil {
+		return err
+	}
+	l := FieldLink{From: from, To: to, Type: linkType, CreatedAt: time.Now().UTC()}
+	if _, ok := m[l.key()]; ok {
+		return ErrLinkExists
+	}
+	next := maps.Clone(m)
+	next[l.key()] = l
+	if err := v.links.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: from, Action: "link"})
+	return nil
+}
+
+// Unlink removes a link made with Link.
+func (v *Vault) Unlink(from, to, linkType string) error {
+	if linkType == "" {
+		linkType = DefaultLinkType
+	}
+	from = v.ResolveAlias(from)
+	if !strings.HasSuffix(to, "*") {
+		to = v.ResolveAlias(to)
+	}
+	v.links.writeMu.Lock()
+	defer v.links.writeMu.Unlock()
+	m, err := v.links.load()
+	if err != nil {
+		return err
+	}
+	k := FieldLink{From: from, To: to, Type: linkType}.key()
+	if _, ok := m[k]; !ok {
+		return ErrLinkNotFound
+	}
+	next := maps.Clone(m)
+	delete(next, k)
+	if err := v.links.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: from, Action: "unlink"})
+	return nil
+}
+
+// Links returns every link, sorted by the field linked from.
+func (v *Vault) Links() ([]FieldLink, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	m, err := v.links.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedLinks(m), nil
+}
+
+// WithLinks returns b with the links among its fields: those from a field
+// in b to at least one other field in b, so a bundle never names fields it
+// leaves out. b itself isn't changed, since it may be cached.
+func (v *Vault) WithLinks(b *ContextBundle) *ContextBundle {
+	m, err := v.links.load()
+	if err != nil || len(m) == 0 {
+		return b
+	}
+	ids := make(map[string]bool)
+	for _, fields := range b.Categories {
+		for _, f := range fields {
+			ids[f.ID] = true
+		}
+	}
+	var links []FieldLink
+	for _, l := range sortedLinks(m) {
+		if !ids[l.From] {
+			continue
+		}
+		for id := range ids {
+			if id != l.From && l.Matches(id) {
+				links = append(links, l)
+				break
+			}
+		}
+	}
+	if len(links) == 0 {
+		return b
+	}
+	out := *b
+	out.Links = links
+	return &out
+}
+
+// forgetLinks drops the links from id, and those to it by name, once its
+// field is deleted. Links to a pattern stay, since other fields may match.
+func (v *Vault) forgetLinks(id string) {
+	v.links.writeMu.Lock()
+	defer v.links.writeMu.Unlock()
+	m, err := v.links.load()
+	if err != nil {
+		return
+	}
+	next := maps.Clone(m)
+	maps.DeleteFunc(next, func(_ string, l FieldLink) bool { return l.From == id || l.To == id })
+	if len(next) == len(m) {
+		return
+	}
+	v.links.save(next)
+}
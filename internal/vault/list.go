@@ -0,0 +1,212 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+var (
+	ErrNoEntries      = errors.New("a list needs at least one entry")
+	ErrEmptyEntry     = errors.New("every entry needs a value")
+	ErrDuplicateLabel = errors.New("entry labels must be unique")
+	ErrManyPrimary    = errors.New("only one entry can be primary")
+	ErrEntryNotFound  = errors.New("no entry with that label or position")
+	ErrLastEntry      = errors.New("a list keeps at least one entry; delete the field instead")
+)
+
+// listPrefix starts the stored value of a field holding several entries,
+// followed by the entries as JSON. Keeping the list in the value means
+// history, exports, merges, and replicas carry it without knowing about it.
+const listPrefix = "pvault:list:"
+
+// ValueEntry is one value of a multi-valued field, like one of several
+// phone numbers.
+type ValueEntry struct {
+	Value   string `json:"value"`
+	Label   string `json:"label,omitempty"` // e.g. home or work
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// checkEntries trims and validates entries, marking the first primary if
+// none is.
+func checkEntries(entries []ValueEntry) ([]ValueEntry, error) {
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+	out := make([]ValueEntry, len(entries))
+	labels := make(map[string]bool)
+	primary := -1
+	for i, e := range entries {
+		e.Label = strings.TrimSpace(e.Label)
+		if strings.TrimSpace(e.Value) == "" {
+			return nil, ErrEmptyEntry
+		}
+		if e.Label != "" {
+			key := strings.ToLower(e.Label)
+			if labels[key] {
+				return nil, ErrDuplicateLabel
+			}
+			labels[key] = true
+		}
+		if e.Primary {
+			if primary >= 0 {
+				return nil, ErrManyPrimary
+			}
+			primary = i
+		}
+		out[i] = e
+	}
+	if primary < 0 {
+		out[0].Primary = true
+	}
+	return out, nil
+}
+
+// EncodeEntries returns the stored value for a field holding entries.
+func EncodeEntries(entries []ValueEntry) (string, error) {
+	entries, err := checkEntries(entries)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return "", err
+	}
+	return listPrefix + string(b), nil
+}
+
+// DecodeEntries returns the entries a stored value holds, or false if it is
+// a single value.
+func DecodeEntries(value string) ([]ValueEntry, bool) {
+	raw, ok := strings.CutPrefix(value, listPrefix)
+	if !ok {
+		return nil, false
+	}
+	var entries []ValueEntry
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, false
+	}
+	entries, err := checkEntries(entries)
+	if err != nil {
+		return nil, false
+	}
+	return entries, true
+}
+
+// setValue fills in f from a decrypted value: a list field's Value is its
+// primary entry, so readers that don't know about lists still get one.
+func (f *FieldInfo) setValue(value string) {
+	entries, ok := DecodeEntries(value)
+	if !ok {
+		f.Value = value
+		return
+	}
+	f.Entries = entries
+	for _, e := range entries {
+		if e.Primary {
+			f.Value = e.Value
+		}
+	}
+}
+
+// storedValue is the inverse of setValue: the value to write back for f.
+func storedValue(f FieldInfo) string {
+	if len(f.Entries) == 0 {
+		return f.Value
+	}
+	value, err := EncodeEntries(f.Entries)
+	if err != nil {
+		return f.Value
+	}
+	return value
+}
+
+// SetEntries stores entries as a field's value, replacing whatever it held.
+// Each entry is normalized like a single value unless opts.Raw is set.
+func (v *Vault) SetEntries(id string, entries []ValueEntry, opts SetOptions) error {
+	value, err := EncodeEntries(entries)
+	if err != nil {
+		return err
+	}
+	_, err = v.SetWithOptions(id, value, opts)
+	return err
+}
+
+// currentEntries returns a field's entries, a single value as one entry, and
+// its sensitivity; a missing field has none.
+func (v *Vault) currentEntries(id string) ([]ValueEntry, string, error) {
+	f, err := v.Get(id)
+	if err != nil || f == nil {
+		return nil, "", err
+	}
+	if len(f.Entries) > 0 {
+		return f.Entries, f.Sensitivity, nil
+	}
+	return []ValueEntry{{Value: f.Value, Primary: true}}, f.Sensitivity, nil
+}
+
+// AddEntry appends an entry to a field, turning a single value into a list
+// whose first entry it is, or creating the field with sensitivity if it
+// doesn't exist. A primary entry takes over from the old primary.
+func (v *Vault) AddEntry(id string, e ValueEntry, sensitivity string) error {
+	if err := ValidateFieldID(id); err != nil {
+		return err
+	}
+	id = v.ResolveAlias(id)
+	entries, tier, err := v.currentEntries(id)
+	if err != nil {
+		return err
+	}
+	if tier == "" {
+		tier = sensitivity
+		if tier == "" {
+			tier = DefaultSensitivity(id)
+		}
+	}
+	if e.Primary {
+		for i := range entries {
+			entries[i].Primary = false
+		}
+	}
+	if err := v.SetEntries(id, append(entries, e), SetOptions{Sensitivity: tier}); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "entry_add"})
+	return nil
+}
+
+// RemoveEntry removes the entry with the given label, or at the given
+// position counting from 1. If it was primary, the first entry left is.
+func (v *Vault) RemoveEntry(id, ref string) error {
+	if err := ValidateFieldID(id); err != nil {
+		return err
+	}
+	id = v.ResolveAlias(id)
+	entries, tier, err := v.currentEntries(id)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(entries, func(e ValueEntry) bool {
+		return e.Label != "" && strings.EqualFold(e.Label, ref)
+	})
+	if n, err := strconv.Atoi(ref); i < 0 && err == nil && n >= 1 && n <= len(entries) {
+		i = n - 1
+	}
+	if i < 0 {
+		return ErrEntryNotFound
+	}
+	if len(entries) == 1 {
+		return ErrLastEntry
+	}
+	entries = slices.Delete(entries, i, i+1)
+	if err := v.SetEntries(id, entries, SetOptions{Sensitivity: tier, Raw: true}); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "entry_remove"})
+	return nil
+}
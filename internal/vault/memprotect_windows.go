@@ -0,0 +1,49 @@
+//go:build windows
+
+package vault
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockMemory locks the byte slice's memory page(s) into the working set to
+// prevent paging to disk. Best-effort: failure is silently ignored (the
+// working set minimum may be too small).
+func lockMemory(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	_ = windows.VirtualLock(uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)))
+}
+
+// unlockMemory unlocks previously locked memory pages.
+// Best-effort: failure is silently ignored.
+func unlockMemory(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	_ = windows.VirtualUnlock(uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)))
+}
+
+// disableCoreDumps keeps key material out of crash dumps: it suppresses the
+// fault dialog that offers to send a dump and excludes the executable from
+// Windows Error Reporting. Best-effort: failure is silently ignored.
+func disableCoreDumps() {
+	windows.SetErrorMode(windows.SEM_FAILCRITICALERRORS | windows.SEM_NOGPFAULTERRORBOX)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	name, err := windows.UTF16PtrFromString(exe)
+	if err != nil {
+		return
+	}
+	exclude := windows.NewLazySystemDLL("wer.dll").NewProc("WerAddExcludedApplication")
+	if exclude.Find() == nil {
+		exclude.Call(uintptr(unsafe.Pointer(name)), 0)
+	}
+}
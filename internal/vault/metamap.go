@@ -0,0 +1,135 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+)
+
+// metaMap is a table of per-field or per-consumer settings kept encrypted
+// in one meta key under its own subkey, and cached decrypted while the
+// vault is unlocked. Aliases, canaries, access lists, and the other side
+// tables are each one.
+//
+// An uncached table is decrypted on every load instead, for one that holds
+// copies of field values or is read too rarely to keep in memory. Nothing
+// is built from it and cached, so saving it doesn't advance the generation.
+type metaMap[T any] struct {
+	v       *Vault
+	metaKey string // meta key the table is stored under
+	keyInfo string // HKDF info for the table's key
+	what    string // what the table holds, for errors
+
+	// encode and decode, when set, store the table in another shape than
+	// the map itself.
+	encode func(map[string]T) any
+	decode func([]byte) (map[string]T, error)
+
+	uncached bool
+
+	mu      sync.Mutex // guards m
+	writeMu sync.Mutex // serializes table updates
+	m       map[string]T
+}
+
+// metaTable is what seal needs of a metaMap of any type.
+type metaTable interface {
+	reset()
+}
+
+// newMetaMap returns the metaMap for the table stored under metaKey, and
+// registers it with v so that seal drops its cache.
+func newMetaMap[T any](v *Vault, metaKey, keyInfo, what string) *metaMap[T] {
+	t := &metaMap[T]{v: v, metaKey: metaKey, keyInfo: keyInfo, what: what}
+	v.metaTables = append(v.metaTables, t)
+	return t
+}
+
+// load returns the table, loading and caching it on first use. Callers
+// must not modify a cached table: save a modified copy instead.
+func (t *metaMap[T]) load() (map[string]T, error) {
+	t.mu.Lock()
+	cached := t.m
+	t.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	v := t.v
+	gen := v.gen.Load()
+	key, err := v.subkey(t.keyInfo)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := v.db.GetMeta(t.metaKey)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]T)
+	if raw != "" {
+		plaintext, err := crypto.DecryptFromBase64(key, raw)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt %s: %w", t.what, err)
+		}
+		if t.decode != nil {
+			m, err = t.decode(plaintext)
+		} else {
+			err = json.Unmarshal(plaintext, &m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", t.what, err)
+		}
+	}
+
+	if t.uncached {
+		return m, nil
+	}
+	// Don't cache across a lock that happened while loading.
+	t.mu.Lock()
+	if v.gen.Load() == gen {
+		t.m = m
+	}
+	t.mu.Unlock()
+	return m, nil
+}
+
+// save encrypts and stores m as the table.
+func (t *metaMap[T]) save(m map[string]T) error {
+	v := t.v
+	key, err := v.subkey(t.keyInfo)
+	if err != nil {
+		return err
+	}
+	var stored any = m
+	if t.encode != nil {
+		stored = t.encode(m)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	encrypted, err := crypto.EncryptToBase64(key, data)
+	if err != nil {
+		return fmt.Errorf("encrypt %s: %w", t.what, err)
+	}
+	if err := v.db.SetMeta(t.metaKey, encrypted); err != nil {
+		return err
+	}
+	if t.uncached {
+		return nil
+	}
+	t.mu.Lock()
+	t.m = m
+	t.mu.Unlock()
+	v.gen.Add(1)
+	return nil
+}
+
+// reset drops the cached table.
+func (t *metaMap[T]) reset() {
+	t.mu.Lock()
+	t.m = nil
+	t.mu.Unlock()
+}
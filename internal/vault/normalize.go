@@ -0,0 +1,192 @@
+package vault
+
+import (
+	"strings"
+)
+
+// Normalize returns value in the canonical format for the kind of field the
+// ID names, so agents see one format regardless of how it was entered:
+//
+//   - every value has leading and trailing whitespace trimmed
+//   - phone fields (phone, *_phone) become E.164, e.g. +14155550123
+//   - country fields (country, *_country) become ISO 3166-1 alpha-2 codes
+//   - state fields (state, *_state) holding a US state or territory become
+//     its USPS abbreviation
+//
+// Values that cannot be interpreted unambiguously are only trimmed. Each
+// entry of a list value is normalized on its own.
+func Normalize(id, value string) string {
+	if entries, ok := DecodeEntries(value); ok {
+		for i := range entries {
+			entries[i].Value = Normalize(id, entries[i].Value)
+		}
+		if list, err := EncodeEntries(entries); err == nil {
+			return list
+		}
+		return value
+	}
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return value
+	}
+	_, name, _ := strings.Cut(id, ".")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:] // contacts.spouse.phone is a phone
+	}
+	switch {
+	case fieldKind(name, "phone"):
+		if p, ok := normalizePhone(trimmed); ok {
+			return p
+		}
+	case fieldKind(name, "country"):
+		if c, ok := countryCodes[nameKey(trimmed)]; ok {
+			return c
+		}
+	case fieldKind(name, "state"):
+		if s, ok := usStates[nameKey(trimmed)]; ok {
+			return s
+		}
+	}
+	return trimmed
+}
+
+func fieldKind(name, kind string) bool {
+	return name == kind || strings.HasSuffix(name, "_"+kind)
+}
+
+// nameKey folds case, punctuation, and spacing so "U.S.A." and "usa" or
+// "New  York" and "new york" compare equal.
+func nameKey(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == '.' || r == ',' || r == '\'' || r == '’':
+		case r == ' ' || r == '-' || r == '\t':
+			space = b.Len() > 0
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// normalizePhone formats a number as E.164. Numbers written with a + or 00
+// prefix keep their country code; national numbers are only accepted in the
+// North American Numbering Plan, the one case where the country is implied
+// by the shape of the number. Extensions and anything else are rejected.
+func normalizePhone(s string) (string, bool) {
+	var digits strings.Builder
+	plus := false
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			plus = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/' || r == '\u00a0':
+		default:
+			return "", false
+		}
+	}
+	d := digits.String()
+	if !plus && strings.HasPrefix(d, "00") {
+		plus, d = true, d[2:]
+	}
+	if plus {
+		// E.164 allows at most 15 digits; the shortest assigned numbers
+		// including country code are 8.
+		if len(d) < 8 || len(d) > 15 || d[0] == '0' {
+			return "", false
+		}
+		return "+" + d, true
+	}
+	if len(d) == 11 && d[0] == '1' {
+		d = d[1:]
+	}
+	// NANP: area code and exchange both start with 2-9.
+	if len(d) == 10 && d[0] >= '2' && d[3] >= '2' {
+		return "+1" + d, true
+	}
+	return "", false
+}
+
+// countryCodes maps codes and names, folded by nameKey, to alpha-2 codes.
+var countryCodes = func() map[string]string {
+	m := make(map[string]string)
+	for _, c := range isoCountries {
+		m[nameKey(c.alpha2)] = c.alpha2
+		m[nameKey(c.alpha3)] = c.alpha2
+		for _, n := range c.names {
+			m[nameKey(n)] = c.alpha2
+		}
+	}
+	for alias, code := range countryAliases {
+		m[nameKey(alias)] = code
+	}
+	return m
+}()
+
+// countryAliases are everyday names that ISO 3166 spells differently.
+var countryAliases = map[string]string{
+	"America":        "US",
+	"UK":             "GB",
+	"Great Britain":  "GB",
+	"Britain":        "GB",
+	"Russia":         "RU",
+	"Iran":           "IR",
+	"Syria":          "SY",
+	"Bolivia":        "BO",
+	"Venezuela":      "VE",
+	"Tanzania":       "TZ",
+	"Moldova":        "MD",
+	"Laos":           "LA",
+	"North Korea":    "KP",
+	"Czech Republic": "CZ",
+	"Holland":        "NL",
+	"Macedonia":      "MK",
+	"Ivory Coast":    "CI",
+	"Vatican":        "VA",
+	"Micronesia":     "FM",
+	"Palestine":      "PS",
+	"Turkey":         "TR",
+	"Cape Verde":     "CV",
+	"Swaziland":      "SZ",
+	"Burma":          "MM",
+	"East Timor":     "TL",
+}
+
+// usStates maps USPS codes and names, folded by nameKey, to USPS codes.
+var usStates = func() map[string]string {
+	m := make(map[string]string)
+	for code, name := range usStateNames {
+		m[nameKey(code)] = code
+		m[nameKey(name)] = code
+	}
+	m[nameKey("Washington DC")] = "DC"
+	m[nameKey("Washington D.C.")] = "DC"
+	return m
+}()
+
+var usStateNames = map[string]string{
+	"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas",
+	"CA": "California", "CO": "Colorado", "CT": "Connecticut", "DE": "Delaware",
+	"FL": "Florida", "GA": "Georgia", "HI": "Hawaii", "ID": "Idaho",
+	"IL": "Illinois", "IN": "Indiana", "IA": "Iowa", "KS": "Kansas",
+	"KY": "Kentucky", "LA": "Louisiana", "ME": "Maine", "MD": "Maryland",
+	"MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota", "MS": "Mississippi",
+	"MO": "Missouri", "MT": "Montana", "NE": "Nebraska", "NV": "Nevada",
+	"NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico", "NY": "New York",
+	"NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio", "OK": "Oklahoma",
+	"OR": "Oregon", "PA": "Pennsylvania", "RI": "Rhode Island", "SC": "South Carolina",
+	"SD": "South Dakota", "TN": "Tennessee", "TX": "Texas", "UT": "Utah",
+	"VT": "Vermont", "VA": "Virginia", "WA": "Washington", "WV": "West Virginia",
+	"WI": "Wisconsin", "WY": "Wyoming",
+	"DC": "District of Columbia",
+	"AS": "American Samoa", "GU": "Guam", "MP": "Northern Mariana Islands",
+	"PR": "Puerto Rico", "VI": "U.S. Virgin Islands",
+}
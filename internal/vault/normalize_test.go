@@ -0,0 +1,64 @@
+package vault
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		id, value, want string
+	}{
+		// Whitespace
+		{"identity.full_name", "  Jane Smith \n", "Jane Smith"},
+		{"identity.full_name", "   ", "   "},
+
+		// Phones
+		{"identity.phone", "(415) 555-0123", "+14155550123"},
+		{"identity.phone", "1-415-555-0123", "+14155550123"},
+		{"identity.phone", "+44 20 7946 0958", "+442079460958"},
+		{"identity.phone", "0049 30 123456", "+4930123456"},
+		{"contacts.work_phone", "415.555.0123", "+14155550123"},
+		{"contacts.spouse.phone", "415.555.0123", "+14155550123"},
+		{"identity.phone", "555-0123", "555-0123"},                 // too short to place
+		{"identity.phone", "415-555-0123 x42", "415-555-0123 x42"}, // extension
+		{"identity.phone", "020 7946 0958", "020 7946 0958"},       // national, not NANP
+		{"identity.phone", "+1 (011) 555-0123", "+10115550123"},    // explicit code trusted
+		{"identity.phone", "+123", "+123"},                         // not E.164
+
+		// Countries
+		{"addresses.home_country", "us", "US"},
+		{"addresses.home_country", "USA", "US"},
+		{"addresses.home_country", "U.S.A.", "US"},
+		{"addresses.home_country", "united states of america", "US"},
+		{"addresses.home_country", "Deutschland", "Deutschland"},
+		{"addresses.home_country", "Germany", "DE"},
+		{"addresses.home_country", "UK", "GB"},
+		{"addresses.home_country", "South Korea", "KR"},
+		{"travel.passport_country", "fra", "FR"},
+
+		// US states
+		{"addresses.home_state", "california", "CA"},
+		{"addresses.home_state", "ny", "NY"},
+		{"addresses.home_state", "New  York", "NY"},
+		{"addresses.home_state", "Washington D.C.", "DC"},
+		{"addresses.home_state", "Bavaria", "Bavaria"},
+
+		// Other fields are only trimmed
+		{"addresses.home_city", " georgia ", "georgia"},
+		{"notes.country_notes", "usa", "usa"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.id, tt.value); got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, want %q", tt.id, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCountryTable(t *testing.T) {
+	if len(isoCountries) != 249 {
+		t.Fatalf("expected 249 ISO 3166-1 entries, got %d", len(isoCountries))
+	}
+	for alias, code := range countryAliases {
+		if countryCodes[nameKey(code)] != code {
+			t.Errorf("alias %q points at unknown code %s", alias, code)
+		}
+	}
+}
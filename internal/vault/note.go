@@ -0,0 +1,104 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"unicode/utf8"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// MaxNoteLength is the longest note a field can have, in characters.
+const MaxNoteLength = 1000
+
+var (
+	ErrNoteTooLong = fmt.Errorf("note is longer than %d characters", MaxNoteLength)
+	ErrNoteMissing = errors.New("only a stored field can have a note")
+)
+
+const (
+	// notesMetaKey holds the field notes, encrypted like the values they
+	// describe.
+	notesMetaKey = "field_notes"
+
+	// noteKeyInfo is the HKDF info for the note table key.
+	noteKeyInfo = ":notes"
+)
+
+// SetNote sets a stored field's note: why it exists, or a caveat such as
+// "billing address, not shipping". An empty note clears it. An alias's note
+// is its target's.
+func (v *Vault) SetNote(id, note string) error {
+	if err := ValidateFieldID(id); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(note) > MaxNoteLength {
+		return ErrNoteTooLong
+	}
+	id = v.ResolveAlias(id)
+	v.notes.writeMu.Lock()
+	defer v.notes.writeMu.Unlock()
+	m, err := v.notes.load()
+	if err != nil {
+		return err
+	}
+	if m[id] == note {
+		return nil
+	}
+	if note != "" {
+		if f, err := v.db.GetField(id); err != nil {
+			return err
+		} else if f == nil {
+			return ErrNoteMissing
+		}
+	}
+	next := maps.Clone(m)
+	if note == "" {
+		delete(next, id)
+	} else {
+		next[id] = note
+	}
+	if err := v.notes.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "note"})
+	return nil
+}
+
+// note returns id's note, or "" if the note table can't be read: a note
+// never keeps a value from being returned.
+func (v *Vault) note(id string) string {
+	m, err := v.notes.load()
+	if err != nil {
+		return ""
+	}
+	return m[id]
+}
+
+// addNotes fills in the notes of fields, as note does.
+func (v *Vault) addNotes(fields []FieldInfo) {
+	m, err := v.notes.load()
+	if err != nil || len(m) == 0 {
+		return
+	}
+	for i := range fields {
+		fields[i].Note = m[fields[i].ID]
+	}
+}
+
+// forgetNote drops id's note once its field is deleted.
+func (v *Vault) forgetNote(id string) {
+	v.notes.writeMu.Lock()
+	defer v.notes.writeMu.Unlock()
+	m, err := v.notes.load()
+	if err != nil {
+		return
+	}
+	if _, ok := m[id]; !ok {
+		return
+	}
+	next := maps.Clone(m)
+	delete(next, id)
+	v.notes.save(next)
+}
@@ -0,0 +1,134 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Event types sent to a Notifier.
+const (
+	EventEmergencyRequested = "emergency_requested"
+	EventEmergencyDenied    = "emergency_denied"
+	EventEmergencyReleased  = "emergency_released"
+	EventCanaryRead         = "canary_read"
+	EventFieldExpiring      = "field_expiring"
+	EventTokenExpiring      = "token_expiring"
+)
+
+// EventTypes lists every event type, for validating notification settings.
+var EventTypes = []string{EventEmergencyRequested, EventEmergencyDenied, EventEmergencyReleased, EventCanaryRead, EventFieldExpiring, EventTokenExpiring}
+
+// PriorityHigh marks an event that means the vault is probably being
+// misused right now, for notifiers that can page rather than queue.
+const PriorityHigh = "high"
+
+// Event is something the owner should hear about even when not at the
+// vault, e.g. an emergency access request they have days to deny.
+type Event struct {
+	Type      string    `json:"type"`
+	Priority  string    `json:"priority,omitempty"`
+	Contact   string    `json:"contact,omitempty"`
+	Field     string    `json:"field,omitempty"`    // the canary read, or the field expiring
+	Consumer  string    `json:"consumer,omitempty"` // the service token that read it, or the one expiring
+	Token     string    `json:"token,omitempty"`    // the expiring token's hash prefix
+	Revoked   bool      `json:"revoked,omitempty"`  // whether that token was revoked
+	Time      time.Time `json:"time"`
+	ReleaseAt time.Time `json:"release_at,omitzero"`
+	Expires   string    `json:"expires,omitempty"` // the expiring field's or token's date, YYYY-MM-DD
+}
+
+// Notifier delivers events to the owner: a push service, mail gateway,
+// chat webhook, or anything a small script can reach.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// notifyTimeout bounds a single notifier call.
+const notifyTimeout = 10 * time.Second
+
+// SetNotifier configures where events go; nil turns notifications off.
+func (v *Vault) SetNotifier(n Notifier) {
+	v.notifyMu.Lock()
+	defer v.notifyMu.Unlock()
+	v.notifier = n
+}
+
+// notify delivers e in the background. Failures are logged, not returned:
+// a notification outage must not block the request that caused it.
+func (v *Vault) notify(e Event) {
+	v.notifyMu.Lock()
+	n := v.notifier
+	v.notifyMu.Unlock()
+	if n == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := n.Notify(ctx, e); err != nil {
+			slog.Warn("notify", "type", e.Type, "err", err)
+		}
+	}()
+}
+
+// HTTPNotifier posts each event as JSON to URL.
+type HTTPNotifier struct {
+	URL    string
+	Client *http.Client // nil means http.DefaultClient
+}
+
+func (n *HTTPNotifier) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notifier returned %s", resp.Status)
+	}
+	return nil
+}
+
+// CommandNotifier runs a local program with each event as JSON on stdin.
+type CommandNotifier struct {
+	Path string
+	Args []string
+}
+
+func (n *CommandNotifier) Notify(ctx context.Context, e Event) error {
+	in, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, n.Path, n.Args...)
+	cmd.Stdin = bytes.NewReader(in)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package vault
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+var (
+	ErrPinMissing = errors.New("only a stored field can be pinned")
+	ErrNotPinned  = errors.New("field is not pinned")
+)
+
+const (
+	// pinsMetaKey holds the pinned fields, encrypted like the other tables
+	// that name fields.
+	pinsMetaKey = "pinned_fields"
+
+	// pinKeyInfo is the HKDF info for the pin table key.
+	pinKeyInfo = ":pins"
+)
+
+// Pin marks a stored field as pinned, so lists show it first. An alias pins
+// its target. Pinning a pinned field is a no-op.
+func (v *Vault) Pin(id string) error {
+	if err := ValidateFieldID(id); err != nil {
+		return err
+	}
+	id = v.ResolveAlias(id)
+	v.pins.writeMu.Lock()
+	defer v.pins.writeMu.Unlock()
+	m, err := v.pins.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[id]; ok {
+		return nil
+	}
+	if f, err := v.db.GetField(id); err != nil {
+		return err
+	} else if f == nil {
+		return ErrPinMissing
+	}
+	next := maps.Clone(m)
+	next[id] = time.Now().UTC()
+	if err := v.pins.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "pin"})
+	return nil
+}
+
+// Unpin clears a field's pin.
+func (v *Vault) Unpin(id string) error {
+	id = v.ResolveAlias(id)
+	v.pins.writeMu.Lock()
+	defer v.pins.writeMu.Unlock()
+	m, err := v.pins.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[id]; !ok {
+		return ErrNotPinned
+	}
+	next := maps.Clone(m)
+	delete(next, id)
+	if err := v.pins.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "unpin"})
+	return nil
+}
+
+// Pinned returns the pinned field IDs, sorted.
+func (v *Vault) Pinned() ([]string, error) {
+	m, err := v.pins.load()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(m)), nil
+}
+
+// forgetPin drops id's pin once its field is deleted.
+func (v *Vault) forgetPin(id string) {
+	v.pins.writeMu.Lock()
+	defer v.pins.writeMu.Unlock()
+	m, err := v.pins.load()
+	if err != nil {
+		return
+	}
+	if _, ok := m[id]; !ok {
+		return
+	}
+	next := maps.Clone(m)
+	delete(next, id)
+	v.pins.save(next)
+}
@@ -0,0 +1,228 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Plugin hooks. A plugin says which it handles when asked to describe itself.
+const (
+	HookRead  = "read"  // transform values on their way to a service token
+	HookWrite = "write" // accept or reject values before they are stored
+)
+
+// PluginProtocol is the version of the JSON messages exchanged with plugins.
+const PluginProtocol = 1
+
+// pluginTimeout bounds a single plugin call.
+const pluginTimeout = 5 * time.Second
+
+// PluginField is a field as a plugin sees it.
+type PluginField struct {
+	ID          string `json:"id"`
+	Value       string `json:"value"`
+	Sensitivity string `json:"sensitivity,omitempty"`
+}
+
+// Plugin extends the vault without a fork: it can veto writes and rewrite
+// what service tokens read, e.g. to format a value the way one consumer
+// wants it or mask what it shouldn't see in full.
+type Plugin interface {
+	Name() string
+	Hooks() []string
+	// ValidateWrite returns a *PluginRejectedError to refuse a write.
+	ValidateWrite(ctx context.Context, fields []PluginField) error
+	// TransformRead returns replacement values, keyed by field ID, for the
+	// fields a consumer is about to be sent. Fields it leaves out are sent
+	// unchanged.
+	TransformRead(ctx context.Context, consumer string, fields []PluginField) (map[string]string, error)
+}
+
+// PluginRejectedError is a write a plugin refused.
+type PluginRejectedError struct {
+	Plugin string
+	Field  string
+	Reason string
+}
+
+func (e *PluginRejectedError) Error() string {
+	return fmt.Sprintf("plugin %s rejected %s: %s", e.Plugin, e.Field, e.Reason)
+}
+
+// PluginError is a plugin that failed to answer. The operation it guards
+// fails with it rather than going ahead unchecked.
+type PluginError struct {
+	Plugin string
+	Err    error
+}
+
+func (e *PluginError) Error() string { return "plugin " + e.Plugin + ": " + e.Err.Error() }
+func (e *PluginError) Unwrap() error { return e.Err }
+
+// SetPlugins configures the hook plugins, run in order; nil turns them off.
+func (v *Vault) SetPlugins(plugins []Plugin) {
+	v.pluginMu.Lock()
+	defer v.pluginMu.Unlock()
+	v.plugins = plugins
+}
+
+// pluginsFor returns the configured plugins that handle hook.
+func (v *Vault) pluginsFor(hook string) []Plugin {
+	v.pluginMu.Lock()
+	defer v.pluginMu.Unlock()
+	var out []Plugin
+	for _, p := range v.plugins {
+		if slices.Contains(p.Hooks(), hook) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// validateWrite asks every write plugin to accept the fields.
+func (v *Vault) validateWrite(fields []PluginField) error {
+	for _, p := range v.pluginsFor(HookWrite) {
+		ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+		err := p.ValidateWrite(ctx, fields)
+		cancel()
+		var rejected *PluginRejectedError
+		if errors.As(err, &rejected) {
+			return err
+		}
+		if err != nil {
+			return &PluginError{Plugin: p.Name(), Err: err}
+		}
+	}
+	return nil
+}
+
+// TransformRead runs the read plugins over fields a consumer is about to be
+// sent and returns them with any replaced values. The input is not modified,
+// so cached reads stay as stored.
+func (v *Vault) TransformRead(ctx context.Context, consumer string, fields []FieldInfo) ([]FieldInfo, error) {
+	plugins := v.pluginsFor(HookRead)
+	if len(plugins) == 0 || len(fields) == 0 {
+		return fields, nil
+	}
+	out := slices.Clone(fields)
+	for _, p := range plugins {
+		in := make([]PluginField, len(out))
+		for i, f := range out {
+			in[i] = PluginField{ID: f.ID, Value: f.Value, Sensitivity: f.Sensitivity}
+		}
+		callCtx, cancel := context.WithTimeout(ctx, pluginTimeout)
+		values, err := p.TransformRead(callCtx, consumer, in)
+		cancel()
+		if err != nil {
+			return nil, &PluginError{Plugin: p.Name(), Err: err}
+		}
+		// A rewritten list field is sent as the rewritten value alone, so
+		// no entry gets past the plugin.
+		for i := range out {
+			if value, ok := values[out[i].ID]; ok {
+				out[i].Value = value
+				out[i].Entries = nil
+			}
+		}
+	}
+	return out, nil
+}
+
+// CommandPlugin runs a local program for each hook call, with a JSON request
+// on stdin and a JSON reply on stdout:
+//
+//	{"hook":"describe","protocol":1}
+//	  → {"name":"...","hooks":["read","write"]}
+//	{"hook":"write","protocol":1,"fields":[{"id","value","sensitivity"}]}
+//	  → {} to accept, or {"rejected":[{"id":"...","reason":"..."}]}
+//	{"hook":"read","protocol":1,"consumer":"...","fields":[...]}
+//	  → {"values":{"<id>":"<new value>"}}
+//
+// Describe must be called before use.
+type CommandPlugin struct {
+	Path  string
+	Args  []string
+	name  string
+	hooks []string
+}
+
+func (c *CommandPlugin) Name() string    { return c.name }
+func (c *CommandPlugin) Hooks() []string { return c.hooks }
+
+// Describe asks the program for its name and hooks.
+func (c *CommandPlugin) Describe(ctx context.Context) error {
+	var reply struct {
+		Name  string   `json:"name"`
+		Hooks []string `json:"hooks"`
+	}
+	if err := c.call(ctx, map[string]any{"hook": "describe"}, &reply); err != nil {
+		return err
+	}
+	for _, h := range reply.Hooks {
+		if h != HookRead && h != HookWrite {
+			return fmt.Errorf("%s: unknown hook %q", c.Path, h)
+		}
+	}
+	c.name = reply.Name
+	if c.name == "" {
+		c.name = strings.TrimPrefix(filepath.Base(c.Path), "pvault-")
+	}
+	c.hooks = reply.Hooks
+	return nil
+}
+
+func (c *CommandPlugin) ValidateWrite(ctx context.Context, fields []PluginField) error {
+	var reply struct {
+		Rejected []struct {
+			ID     string `json:"id"`
+			Reason string `json:"reason"`
+		} `json:"rejected"`
+	}
+	if err := c.call(ctx, map[string]any{"hook": HookWrite, "fields": fields}, &reply); err != nil {
+		return err
+	}
+	if len(reply.Rejected) > 0 {
+		r := reply.Rejected[0]
+		return &PluginRejectedError{Plugin: c.name, Field: r.ID, Reason: r.Reason}
+	}
+	return nil
+}
+
+func (c *CommandPlugin) TransformRead(ctx context.Context, consumer string, fields []PluginField) (map[string]string, error) {
+	var reply struct {
+		Values map[string]string `json:"values"`
+	}
+	err := c.call(ctx, map[string]any{"hook": HookRead, "consumer": consumer, "fields": fields}, &reply)
+	return reply.Values, err
+}
+
+func (c *CommandPlugin) call(ctx context.Context, req map[string]any, reply any) error {
+	req["protocol"] = PluginProtocol
+	in, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = bytes.NewReader(in)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	if err := json.Unmarshal(out, reply); err != nil {
+		return fmt.Errorf("%s: invalid reply: %w", c.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package vault
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// minRedactLength is the shortest value Redact replaces, so a state code or
+// a single digit doesn't scrub unrelated text.
+const minRedactLength = 4
+
+// Redaction says how many times Redact replaced one field's values.
+type Redaction struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// RedactPlaceholder is what Redact puts in place of field id's value.
+func RedactPlaceholder(id string) string { return "[redacted:" + id + "]" }
+
+// Redact returns text with every value of the fields allow accepts (each
+// entry of a multi-valued field included) replaced by RedactPlaceholder, and
+// how many of each it replaced. Matching is exact; where values overlap, the
+// longest wins.
+//
+// If authorize is set it is called with every field allow accepts before any
+// is matched, and its error returned, so what it decides can't depend on
+// which values the text holds.
+func (v *Vault) Redact(text string, allow func(FieldInfo) bool, authorize func([]FieldInfo) error) (string, []Redaction, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return "", nil, err
+	}
+	fields, err := v.db.GetAllFields()
+	if err != nil {
+		return "", nil, err
+	}
+	bundle, err := v.decryptBundle(fields)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var allowed []FieldInfo
+	for _, infos := range bundle.Categories {
+		for _, f := range infos {
+			if allow(f) {
+				allowed = append(allowed, f)
+			}
+		}
+	}
+	if authorize != nil {
+		slices.SortFunc(allowed, func(a, b FieldInfo) int { return strings.Compare(a.ID, b.ID) })
+		if err := authorize(allowed); err != nil {
+			return "", nil, err
+		}
+	}
+
+	type secret struct{ value, id string }
+	var secrets []secret
+	add := func(value, id string) {
+		if len(value) >= minRedactLength && strings.Contains(text, value) {
+			secrets = append(secrets, secret{value, id})
+		}
+	}
+	for _, f := range allowed {
+		add(f.Value, f.ID)
+		for _, e := range f.Entries {
+			add(e.Value, f.ID)
+		}
+	}
+	if len(secrets) == 0 {
+		return text, nil, nil
+	}
+	// strings.Replacer tries its pairs in order at each position.
+	slices.SortFunc(secrets, func(a, b secret) int {
+		return cmp.Or(cmp.Compare(len(b.value), len(a.value)), strings.Compare(a.id, b.id))
+	})
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		pairs = append(pairs, s.value, RedactPlaceholder(s.id))
+	}
+	out := strings.NewReplacer(pairs...).Replace(text)
+
+	var redactions []Redaction
+	for _, s := range secrets {
+		if slices.ContainsFunc(redactions, func(r Redaction) bool { return r.ID == s.id }) {
+			continue
+		}
+		placeholder := RedactPlaceholder(s.id)
+		if n := strings.Count(out, placeholder) - strings.Count(text, placeholder); n > 0 {
+			redactions = append(redactions, Redaction{ID: s.id, Count: n})
+		}
+	}
+	slices.SortFunc(redactions, func(a, b Redaction) int { return strings.Compare(a.ID, b.ID) })
+	return out, redactions, nil
+}
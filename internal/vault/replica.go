@@ -0,0 +1,203 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ReplicaPurpose marks the audit entries written by replica syncs.
+const ReplicaPurpose = "replica"
+
+// maxReplicaPull bounds a primary's export response.
+const maxReplicaPull = 32 << 20
+
+// Primary is the vault a read replica pulls from: its base URL and a
+// service token whose scope decides what the replica holds.
+type Primary struct {
+	URL    string
+	Token  string
+	Client *http.Client // nil means http.DefaultClient
+}
+
+// Changes fetches the primary's fields changed since since, all of them
+// when since is zero.
+func (p *Primary) Changes(ctx context.Context, since time.Time) (*Changes, error) {
+	u := strings.TrimRight(p.URL, "/") + "/v1/vault/export"
+	if !since.IsZero() {
+		u += "?" + url.Values{"since": {since.UTC().Format(time.RFC3339)}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.Token)
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e)
+		if e.Error != "" {
+			return nil, fmt.Errorf("primary returned %s: %s", resp.Status, e.Error)
+		}
+		return nil, fmt.Errorf("primary returned %s", resp.Status)
+	}
+	var c Changes
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxReplicaPull)).Decode(&c); err != nil {
+		return nil, fmt.Errorf("decode primary export: %w", err)
+	}
+	return &c, nil
+}
+
+// ReplicaStatus is a replica's sync health, for 'pvault status'.
+type ReplicaStatus struct {
+	Source    string    `json:"source"`
+	AsOf      time.Time `json:"as_of,omitzero"`     // the primary's time at the last good pull; the next pull's since
+	LastSync  time.Time `json:"last_sync,omitzero"` // when that pull happened here
+	LastError string    `json:"last_error,omitempty"`
+	ErrorAt   time.Time `json:"error_at,omitzero"`
+}
+
+// SetReplica makes this vault a read replica of p, or a normal vault again
+// if p is nil. It only records the primary: writes are refused by the API
+// server, and SyncReplica does the pulling.
+func (v *Vault) SetReplica(p *Primary) {
+	v.replicaMu.Lock()
+	defer v.replicaMu.Unlock()
+	v.primary = p
+	v.replicaStatus = ReplicaStatus{}
+	if p != nil {
+		v.replicaStatus.Source = p.URL
+	}
+}
+
+// ReplicaSource returns the primary's URL, or "" if this vault isn't a
+// replica.
+func (v *Vault) ReplicaSource() string {
+	v.replicaMu.Lock()
+	defer v.replicaMu.Unlock()
+	if v.primary == nil {
+		return ""
+	}
+	return v.primary.URL
+}
+
+// ReplicaStatus reports how the replica's syncing is going; ok is false if
+// this vault isn't a replica.
+func (v *Vault) ReplicaStatus() (status ReplicaStatus, ok bool) {
+	v.replicaMu.Lock()
+	defer v.replicaMu.Unlock()
+	return v.replicaStatus, v.primary != nil
+}
+
+// SyncReplica pulls what changed on the primary since the last sync and
+// applies it, returning the number of fields written or deleted. The first
+// pull after SetReplica is a full one, so fields the primary no longer
+// shares, e.g. after the token's scope was narrowed, don't linger. The vault
+// must be unlocked.
+func (v *Vault) SyncReplica(ctx context.Context) (int, error) {
+	v.replicaMu.Lock()
+	p, since := v.primary, v.replicaStatus.AsOf
+	v.replicaMu.Unlock()
+	if p == nil {
+		return 0, fmt.Errorf("vault is not a replica")
+	}
+	if _, err := v.requireUnlocked(); err != nil {
+		return 0, err
+	}
+	changes, err := p.Changes(ctx, since)
+	n := 0
+	if err == nil {
+		changes.Since = since
+		n, err = v.ApplyReplica(changes)
+	}
+
+	v.replicaMu.Lock()
+	defer v.replicaMu.Unlock()
+	if v.primary != p {
+		return n, err
+	}
+	if err != nil {
+		v.replicaStatus.LastError = err.Error()
+		v.replicaStatus.ErrorAt = time.Now().UTC()
+	} else {
+		v.replicaStatus.AsOf = changes.AsOf
+		v.replicaStatus.LastSync = time.Now().UTC()
+		v.replicaStatus.LastError = ""
+		v.replicaStatus.ErrorAt = time.Time{}
+	}
+	return n, err
+}
+
+// ApplyReplica makes this vault match a pull from its primary: changes as
+// returned by the primary's ChangesSince. A full pull (zero Since) also
+// deletes every local field the primary doesn't have. Values the vault
+// already holds are skipped, so a repeated pull writes nothing. The writes
+// go in one transaction, audited with ReplicaPurpose. It returns the number
+// of fields written or deleted.
+func (v *Vault) ApplyReplica(changes *Changes) (int, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return 0, err
+	}
+	fields, err := v.db.GetAllFields()
+	if err != nil {
+		return 0, err
+	}
+	bundle, err := v.decryptBundle(fields)
+	if err != nil {
+		return 0, err
+	}
+	local := make(map[string]FieldInfo)
+	for _, fs := range bundle.Categories {
+		for _, f := range fs {
+			local[f.ID] = f
+		}
+	}
+
+	var ops []TxOp
+	seen := make(map[string]bool)
+	if changes.ContextBundle != nil {
+		for _, fs := range changes.Categories {
+			for _, f := range fs {
+				seen[f.ID] = true
+				if have, ok := local[f.ID]; ok && storedValue(have) == storedValue(f) && have.Sensitivity == f.Sensitivity {
+					continue
+				}
+				ops = append(ops, TxOp{Op: TxSet, ID: f.ID, Value: storedValue(f), Sensitivity: f.Sensitivity, Raw: true})
+			}
+		}
+	}
+	for _, d := range changes.Deleted {
+		if _, ok := local[d.ID]; ok && !seen[d.ID] {
+			seen[d.ID] = true
+			ops = append(ops, TxOp{Op: TxDelete, ID: d.ID})
+		}
+	}
+	if changes.Since.IsZero() {
+		for id := range local {
+			if !seen[id] {
+				ops = append(ops, TxOp{Op: TxDelete, ID: id})
+			}
+		}
+	}
+	if len(ops) > 0 {
+		if _, _, err := v.Apply(ops, "", ReplicaPurpose); err != nil {
+			return 0, err
+		}
+	}
+	return len(ops), nil
+}
@@ -1,10 +1,20 @@
 package vault
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/i18n"
+)
+
 // SchemaField describes a recommended field in the vault schema.
 type SchemaField struct {
 	ID          string `json:"id"`
 	Description string `json:"description"`
 	Sensitivity string `json:"sensitivity"`
+	Expiry      bool   `json:"expiry,omitempty"` // holds a date worth warning about, like a passport's expiry
 }
 
 // SchemaCategory groups recommended fields under a category.
@@ -12,14 +22,106 @@ type SchemaCategory struct {
 	Name        string        `json:"name"`
 	Description string        `json:"description"`
 	Fields      []SchemaField `json:"fields"`
+	Pack        string        `json:"pack,omitempty"` // the schema pack it comes from, if any
+
+	// PurposeRequired makes service tokens state why they want the
+	// category's fields; see PurposeRequired.
+	PurposeRequired bool `json:"purpose_required,omitempty"`
 }
 
 // Schema is the full recommended schema for the vault.
 type Schema struct {
 	Version    string           `json:"version"`
+	Language   string           `json:"language,omitempty"`
 	Categories []SchemaCategory `json:"categories"`
 }
 
+// SchemaPack is a set of categories few vaults need, left out of the
+// recommended schema unless enabled with 'pvault schema enable <name>'. A
+// pack category named like a built-in one takes its place.
+type SchemaPack struct {
+	Name        string
+	Description string
+	Categories  []SchemaCategory
+}
+
+var ErrUnknownSchemaPack = errors.New("unknown schema pack")
+
+// SchemaPacks are the optional schema packs.
+var SchemaPacks = []SchemaPack{
+	{
+		Name:        "vehicles",
+		Description: "Vehicles and insurance policies",
+		Categories: []SchemaCategory{
+			{
+				Name:        "vehicles",
+				Description: "Vehicle details and registration",
+				Fields: []SchemaField{
+					{ID: "vehicles.make", Description: "Vehicle make", Sensitivity: "standard"},
+					{ID: "vehicles.model", Description: "Vehicle model", Sensitivity: "standard"},
+					{ID: "vehicles.year", Description: "Model year", Sensitivity: "standard"},
+					{ID: "vehicles.vin", Description: "Vehicle identification number (VIN)", Sensitivity: "sensitive"},
+					{ID: "vehicles.plate", Description: "License plate number", Sensitivity: "standard"},
+					{ID: "vehicles.registration_expiry", Description: "Registration expiry date", Sensitivity: "standard", Expiry: true},
+				},
+			},
+			{
+				Name:        "insurance",
+				Description: "Insurance providers and policy numbers",
+				Fields: []SchemaField{
+					{ID: "insurance.auto_provider", Description: "Auto insurance provider", Sensitivity: "standard"},
+					{ID: "insurance.auto_policy_number", Description: "Auto insurance policy number", Sensitivity: "sensitive"},
+					{ID: "insurance.health_provider", Description: "Health insurance provider", Sensitivity: "standard"},
+					{ID: "insurance.health_policy_number", Description: "Health insurance policy or group number", Sensitivity: "sensitive"},
+					{ID: "insurance.health_member_id", Description: "Health insurance member ID", Sensitivity: "sensitive"},
+					{ID: "insurance.home_provider", Description: "Home or renters insurance provider", Sensitivity: "standard"},
+					{ID: "insurance.home_policy_number", Description: "Home or renters insurance policy number", Sensitivity: "sensitive"},
+				},
+			},
+		},
+	},
+	{
+		Name:        "travel",
+		Description: "Passport, visa, and trusted traveler documents, with expiry alerts",
+		Categories: []SchemaCategory{
+			{
+				Name:        "travel",
+				Description: "Travel documents",
+				Fields: []SchemaField{
+					{ID: "travel.passport_number", Description: "Passport number", Sensitivity: "sensitive"},
+					{ID: "travel.passport_country", Description: "Passport issuing country code (e.g. US)", Sensitivity: "standard"},
+					{ID: "travel.passport_expiry", Description: "Passport expiry date (YYYY-MM-DD)", Sensitivity: "standard", Expiry: true},
+					{ID: "travel.visa_country", Description: "Country a visa is for", Sensitivity: "standard"},
+					{ID: "travel.visa_number", Description: "Visa number", Sensitivity: "sensitive"},
+					{ID: "travel.visa_expiry", Description: "Visa expiry date (YYYY-MM-DD)", Sensitivity: "standard", Expiry: true},
+					{ID: "travel.known_traveler_number", Description: "Known traveler number (Global Entry, TSA PreCheck, NEXUS)", Sensitivity: "sensitive"},
+					{ID: "travel.trusted_traveler_expiry", Description: "Trusted traveler membership expiry date (YYYY-MM-DD)", Sensitivity: "standard", Expiry: true},
+					{ID: "travel.redress_number", Description: "DHS redress number", Sensitivity: "sensitive"},
+				},
+			},
+		},
+	},
+	{
+		Name:        "medical",
+		Description: "Medical details that service tokens must give a purpose to read",
+		Categories: []SchemaCategory{
+			{
+				Name:            "medical",
+				Description:     "Medical information",
+				PurposeRequired: true,
+				Fields: []SchemaField{
+					{ID: "medical.blood_type", Description: "Blood type (e.g. O+)", Sensitivity: "sensitive"},
+					{ID: "medical.allergies", Description: "Allergies, including to medications", Sensitivity: "sensitive"},
+					{ID: "medical.medications", Description: "Current medications and doses", Sensitivity: "critical"},
+					{ID: "medical.physician_name", Description: "Primary care physician's name", Sensitivity: "sensitive"},
+					{ID: "medical.physician_phone", Description: "Primary care physician's phone number", Sensitivity: "sensitive"},
+					{ID: "medical.insurance_member_id", Description: "Health insurance member ID", Sensitivity: "critical"},
+				},
+			},
+		},
+	},
+}
+
 // RecommendedSchema is the canonical schema that agents can discover.
 var RecommendedSchema = Schema{
 	Version: "1",
@@ -34,7 +136,7 @@ var RecommendedSchema = Schema{
 				{ID: "identity.email", Description: "Primary email address", Sensitivity: "standard"},
 				{ID: "identity.phone", Description: "Phone number", Sensitivity: "sensitive"},
 				{ID: "identity.date_of_birth", Description: "Date of birth", Sensitivity: "sensitive"},
-				},
+			},
 		},
 		{
 			Name:        "addresses",
@@ -81,9 +183,22 @@ var RecommendedSchema = Schema{
 				{ID: "employment.title", Description: "Job title", Sensitivity: "standard"},
 			},
 		},
+		{
+			Name:        "contacts",
+			Description: "People close to you, one group of fields each (e.g. contacts.child_1.full_name)",
+			Fields: []SchemaField{
+				{ID: "contacts.spouse.full_name", Description: "Spouse or partner's full name", Sensitivity: "standard"},
+				{ID: "contacts.spouse.phone", Description: "Spouse or partner's phone number", Sensitivity: "sensitive"},
+				{ID: "contacts.spouse.email", Description: "Spouse or partner's email address", Sensitivity: "standard"},
+				{ID: "contacts.spouse.date_of_birth", Description: "Spouse or partner's date of birth", Sensitivity: "sensitive"},
+				{ID: "contacts.emergency.full_name", Description: "Emergency contact's full name", Sensitivity: "standard"},
+				{ID: "contacts.emergency.phone", Description: "Emergency contact's phone number", Sensitivity: "sensitive"},
+				{ID: "contacts.emergency.relationship", Description: "Emergency contact's relationship to you", Sensitivity: "standard"},
+			},
+		},
 		{
 			Name:        "medical",
-			Description: "Medical information (user-defined fields)",
+			Description: "Medical information",
 			Fields:      []SchemaField{},
 		},
 		{
@@ -96,14 +211,74 @@ var RecommendedSchema = Schema{
 
 var schemaIndex map[string]*SchemaField
 
+// purposeCategories are the categories whose fields service tokens must
+// give a purpose for.
+var purposeCategories map[string]bool
+
+// contactSensitivity gives a contact field's default tier by its name alone,
+// so contacts.child_1.phone is as sensitive as contacts.spouse.phone.
+var contactSensitivity map[string]string
+
+// builtinCategories are the schema's categories before any pack is enabled.
+var builtinCategories []SchemaCategory
+
 func init() {
+	builtinCategories = RecommendedSchema.Categories
+	indexSchema()
+}
+
+func indexSchema() {
 	schemaIndex = make(map[string]*SchemaField)
+	contactSensitivity = make(map[string]string)
+	purposeCategories = make(map[string]bool)
 	for i := range RecommendedSchema.Categories {
+		if RecommendedSchema.Categories[i].PurposeRequired {
+			purposeCategories[RecommendedSchema.Categories[i].Name] = true
+		}
 		for j := range RecommendedSchema.Categories[i].Fields {
 			f := &RecommendedSchema.Categories[i].Fields[j]
 			schemaIndex[f.ID] = f
+			if person, ok := strings.CutPrefix(f.ID, ContactsCategory+"."); ok {
+				_, name, _ := strings.Cut(person, ".")
+				contactSensitivity[name] = f.Sensitivity
+			}
+		}
+	}
+}
+
+// EnableSchemaPacks makes the recommended schema the built-in categories
+// followed by those of the named packs, so nil turns every pack off. It
+// changes nothing if a name is unknown. Call it at startup, before the
+// schema is read.
+func EnableSchemaPacks(names []string) error {
+	categories := slices.Clone(builtinCategories)
+	for _, name := range names {
+		p := GetSchemaPack(name)
+		if p == nil {
+			return fmt.Errorf("%w %q", ErrUnknownSchemaPack, name)
+		}
+		for _, c := range p.Categories {
+			c.Pack = p.Name
+			if i := slices.IndexFunc(categories, func(b SchemaCategory) bool { return b.Name == c.Name }); i >= 0 {
+				categories[i] = c
+			} else {
+				categories = append(categories, c)
+			}
 		}
 	}
+	RecommendedSchema.Categories = categories
+	indexSchema()
+	return nil
+}
+
+// GetSchemaPack returns the pack with the given name, or nil.
+func GetSchemaPack(name string) *SchemaPack {
+	for i := range SchemaPacks {
+		if SchemaPacks[i].Name == name {
+			return &SchemaPacks[i]
+		}
+	}
+	return nil
 }
 
 // IsCanonicalField returns true if the field ID is in the recommended schema.
@@ -118,10 +293,54 @@ func GetSchemaField(id string) *SchemaField {
 }
 
 // DefaultSensitivity returns the schema default sensitivity for a field ID,
-// or "standard" if the field is not in the schema.
+// or "standard" if the field is not in the schema. Any person's contact
+// field takes the tier the schema gives that field for the people it lists.
 func DefaultSensitivity(id string) string {
 	if f, ok := schemaIndex[id]; ok {
 		return f.Sensitivity
 	}
+	if person, ok := strings.CutPrefix(id, ContactsCategory+"."); ok {
+		_, name, _ := strings.Cut(person, ".")
+		if tier, ok := contactSensitivity[name]; ok {
+			return tier
+		}
+	}
 	return "standard"
 }
+
+// PurposeRequired reports whether a service token must state why it wants
+// field id, which the schema asks for a category's most private fields. The
+// purpose is recorded in the audit log.
+func PurposeRequired(id string) bool {
+	category, _, _ := strings.Cut(id, ".")
+	return purposeCategories[category]
+}
+
+// LocalizedSchema returns a copy of the recommended schema with category and
+// field descriptions in lang. Descriptions without a translation stay in
+// English; an unsupported lang yields the English schema.
+func LocalizedSchema(lang string) Schema {
+	lang = i18n.Match(lang)
+	if lang == "" {
+		lang = i18n.Default
+	}
+	s := Schema{Version: RecommendedSchema.Version, Language: lang}
+	for _, c := range RecommendedSchema.Categories {
+		lc := c
+		lc.Description = translate(lang, "schema."+c.Name, c.Description)
+		lc.Fields = make([]SchemaField, len(c.Fields))
+		for i, f := range c.Fields {
+			f.Description = translate(lang, "schema."+f.ID, f.Description)
+			lc.Fields[i] = f
+		}
+		s.Categories = append(s.Categories, lc)
+	}
+	return s
+}
+
+func translate(lang, key, fallback string) string {
+	if i18n.Has(lang, key) {
+		return i18n.T(lang, key)
+	}
+	return fallback
+}
@@ -1,6 +1,11 @@
 package vault
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/lovincyrus/personal-vault/internal/i18n"
+)
 
 func TestIsCanonicalField(t *testing.T) {
 	tests := []struct {
@@ -47,6 +52,9 @@ func TestDefaultSensitivity(t *testing.T) {
 		{"payment.card_number", "critical"},
 		{"preferences.timezone", "public"},
 		{"custom.whatever", "standard"},
+		{"contacts.spouse.phone", "sensitive"},
+		{"contacts.child_1.date_of_birth", "sensitive"},
+		{"contacts.child_1.nickname", "standard"},
 	}
 	for _, tt := range tests {
 		if got := DefaultSensitivity(tt.id); got != tt.want {
@@ -89,3 +97,110 @@ func TestSchemaIndex_MatchesData(t *testing.T) {
 		t.Errorf("schemaIndex has %d entries, but schema has %d fields", len(schemaIndex), count)
 	}
 }
+
+func TestLocalizedSchema(t *testing.T) {
+	s := LocalizedSchema("fr_FR.UTF-8")
+	if s.Language != "fr" {
+		t.Fatalf("expected language fr, got %q", s.Language)
+	}
+	if s.Categories[0].Fields[0].Description != "Prénom" {
+		t.Fatalf("expected French description, got %q", s.Categories[0].Fields[0].Description)
+	}
+	if RecommendedSchema.Categories[0].Fields[0].Description != "First/given name" {
+		t.Fatal("localizing must not modify the recommended schema")
+	}
+
+	if s := LocalizedSchema("xx"); s.Language != "en" || s.Categories[0].Description != "Personal identity information" {
+		t.Fatalf("expected English fallback, got %q", s.Language)
+	}
+}
+
+func TestLocalizedSchema_Complete(t *testing.T) {
+	for _, lang := range i18n.Supported {
+		if lang == i18n.Default {
+			continue
+		}
+		for _, c := range RecommendedSchema.Categories {
+			if !i18n.Has(lang, "schema."+c.Name) {
+				t.Errorf("%s: no translation for category %s", lang, c.Name)
+			}
+			for _, f := range c.Fields {
+				if !i18n.Has(lang, "schema."+f.ID) {
+					t.Errorf("%s: no translation for field %s", lang, f.ID)
+				}
+			}
+		}
+	}
+}
+
+func TestEnableSchemaPacks(t *testing.T) {
+	builtin := len(RecommendedSchema.Categories)
+	if IsCanonicalField("vehicles.vin") {
+		t.Fatal("pack fields must be off by default")
+	}
+	if err := EnableSchemaPacks([]string{"vehicles"}); err != nil {
+		t.Fatal(err)
+	}
+	defer EnableSchemaPacks(nil)
+
+	if got := DefaultSensitivity("vehicles.vin"); got != "sensitive" {
+		t.Errorf("vehicles.vin sensitivity = %q, want sensitive", got)
+	}
+	if got := DefaultSensitivity("insurance.health_member_id"); got != "sensitive" {
+		t.Errorf("insurance.health_member_id sensitivity = %q, want sensitive", got)
+	}
+	if s := SuggestCanonical("vehicles.license_plate"); s == nil || s.Canonical != "vehicles.plate" {
+		t.Errorf("expected license_plate to suggest vehicles.plate, got %+v", s)
+	}
+	last := RecommendedSchema.Categories[len(RecommendedSchema.Categories)-1]
+	if last.Name != "insurance" || last.Pack != "vehicles" {
+		t.Errorf("expected the insurance category from the vehicles pack last, got %s (%q)", last.Name, last.Pack)
+	}
+	TestSchemaIntegrity(t)
+	TestSchemaIndex_MatchesData(t)
+	TestLocalizedSchema_Complete(t)
+
+	if err := EnableSchemaPacks([]string{"vehicles", "boats"}); !errors.Is(err, ErrUnknownSchemaPack) {
+		t.Fatalf("expected ErrUnknownSchemaPack, got %v", err)
+	}
+	if !IsCanonicalField("vehicles.vin") {
+		t.Error("an unknown pack must leave the schema as it was")
+	}
+
+	EnableSchemaPacks(nil)
+	if len(RecommendedSchema.Categories) != builtin || IsCanonicalField("vehicles.vin") {
+		t.Error("expected no packs after EnableSchemaPacks(nil)")
+	}
+}
+
+func TestEnableSchemaPacks_Medical(t *testing.T) {
+	builtin := len(RecommendedSchema.Categories)
+	if PurposeRequired("medical.blood_type") {
+		t.Fatal("medical fields need no purpose without the pack")
+	}
+	if err := EnableSchemaPacks([]string{"medical"}); err != nil {
+		t.Fatal(err)
+	}
+	defer EnableSchemaPacks(nil)
+
+	if len(RecommendedSchema.Categories) != builtin {
+		t.Errorf("expected the pack to replace the built-in medical category, got %d categories", len(RecommendedSchema.Categories))
+	}
+	if got := DefaultSensitivity("medical.medications"); got != "critical" {
+		t.Errorf("medical.medications sensitivity = %q, want critical", got)
+	}
+	if !PurposeRequired("medical.blood_type") || !PurposeRequired("medical.custom") || PurposeRequired("identity.email") {
+		t.Error("expected a purpose required for the medical category only")
+	}
+	for _, c := range LocalizedSchema("de").Categories {
+		if c.Name == "medical" && (!c.PurposeRequired || len(c.Fields) == 0) {
+			t.Errorf("expected the localized medical category to keep its fields and policy, got %+v", c)
+		}
+	}
+	if s := SuggestCanonical("medical.blood_group"); s == nil || s.Canonical != "medical.blood_type" {
+		t.Errorf("expected blood_group to suggest medical.blood_type, got %+v", s)
+	}
+	TestSchemaIntegrity(t)
+	TestSchemaIndex_MatchesData(t)
+	TestLocalizedSchema_Complete(t)
+}
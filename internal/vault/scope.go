@@ -3,14 +3,21 @@ package vault
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
 // validIDPart matches alphanumeric, underscores, and hyphens.
 var validIDPart = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 
+// ContactsCategory holds people close to the user, one level deeper than
+// other categories: contacts.<person>.<field>, e.g. contacts.spouse.full_name.
+// Flat contacts.<field> IDs stay valid, as they were before.
+const ContactsCategory = "contacts"
+
 // ValidateFieldID checks that a field ID is safe: category.field_name where both
-// parts contain only alphanumeric characters, underscores, and hyphens.
+// parts contain only alphanumeric characters, underscores, and hyphens; under
+// contacts the field may also be person.field_name.
 func ValidateFieldID(id string) error {
 	parts := strings.SplitN(id, ".", 2)
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
@@ -19,19 +26,35 @@ func ValidateFieldID(id string) error {
 	if !validIDPart.MatchString(parts[0]) {
 		return fmt.Errorf("invalid category %q: only alphanumeric, underscore, hyphen allowed", parts[0])
 	}
+	if person, name, ok := strings.Cut(parts[1], "."); ok && parts[0] == ContactsCategory {
+		if !validIDPart.MatchString(person) || !validIDPart.MatchString(name) {
+			return fmt.Errorf("contact field ID must be contacts.person.field_name, got %q", id)
+		}
+		return nil
+	}
 	if !validIDPart.MatchString(parts[1]) {
 		return fmt.Errorf("invalid field name %q: only alphanumeric, underscore, hyphen allowed", parts[1])
 	}
 	return nil
 }
 
+// validWildcardPrefix reports whether p.* is a valid wildcard: a whole
+// category, or one person's fields under contacts.
+func validWildcardPrefix(p string) bool {
+	if cat, person, ok := strings.Cut(p, "."); ok {
+		return cat == ContactsCategory && validIDPart.MatchString(person)
+	}
+	return ValidCategoryName(p)
+}
+
 // ValidCategoryName checks that a category name contains only safe characters.
 func ValidCategoryName(name string) bool {
 	return name != "" && validIDPart.MatchString(name)
 }
 
 // ScopeAllows checks if a comma-separated scope pattern allows access to a field ID.
-// Patterns: "*" (all), "identity.*" (category), "identity.full_name" (exact).
+// Patterns: "*" (all), "identity.*" (category), "contacts.spouse.*" (a
+// person), "identity.full_name" (exact).
 func ScopeAllows(scope, fieldID string) bool {
 	for _, p := range strings.Split(scope, ",") {
 		p = strings.TrimSpace(p)
@@ -59,8 +82,8 @@ func ScopeAllowsCategory(scope, category string) bool {
 		if p == "*" {
 			return true
 		}
-		if strings.HasSuffix(p, ".*") {
-			if strings.TrimSuffix(p, ".*") == category {
+		if prefix, ok := strings.CutSuffix(p, ".*"); ok {
+			if prefix == category || strings.HasPrefix(prefix, category+".") {
 				return true
 			}
 			continue
@@ -72,3 +95,72 @@ func ScopeAllowsCategory(scope, category string) bool {
 	}
 	return false
 }
+
+// ScopeIsWildcard reports whether a scope grants every field, present and future.
+func ScopeIsWildcard(scope string) bool {
+	for _, p := range strings.Split(scope, ",") {
+		if strings.TrimSpace(p) == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandScope lists every field a scope grants: fields stored in the vault
+// plus recommended schema fields the scope would cover once filled in.
+func (v *Vault) ExpandScope(scope string) ([]ScopeField, error) {
+	stored, err := v.List()
+	if err != nil {
+		return nil, err
+	}
+	scope = v.ResolveScope(scope)
+
+	seen := make(map[string]bool)
+	var fields []ScopeField
+	for _, f := range stored {
+		if ScopeAllows(scope, f.ID) {
+			fields = append(fields, ScopeField{ID: f.ID, Sensitivity: f.Sensitivity, Stored: true})
+			seen[f.ID] = true
+		}
+	}
+	for _, c := range RecommendedSchema.Categories {
+		for _, f := range c.Fields {
+			if !seen[f.ID] && ScopeAllows(scope, f.ID) {
+				fields = append(fields, ScopeField{ID: f.ID, Sensitivity: f.Sensitivity})
+			}
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].ID < fields[j].ID })
+	return fields, nil
+}
+
+// tierRank orders sensitivity tiers from least to most sensitive.
+var tierRank = map[string]int{"public": 1, "standard": 2, "sensitive": 3, "critical": 4}
+
+// MaxSensitivity returns the highest sensitivity among fields, or "" if
+// there are none.
+func MaxSensitivity(fields []FieldInfo) string {
+	best := ""
+	for _, f := range fields {
+		if tierRank[f.Sensitivity] > tierRank[best] {
+			best = f.Sensitivity
+		}
+	}
+	return best
+}
+
+// ScopeSensitivity returns the highest sensitivity among fields a scope
+// covers, or "" if it covers none. An exact field ID that is no longer stored
+// falls back to its schema default.
+func ScopeSensitivity(scope string, fields []FieldInfo) string {
+	best := ""
+	for _, f := range fields {
+		if tierRank[f.Sensitivity] > tierRank[best] && ScopeAllows(scope, f.ID) {
+			best = f.Sensitivity
+		}
+	}
+	if best == "" && ValidateFieldID(scope) == nil {
+		return DefaultSensitivity(scope)
+	}
+	return best
+}
@@ -1,6 +1,9 @@
 package vault
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestScopeAllows(t *testing.T) {
 	tests := []struct {
@@ -34,6 +37,11 @@ func TestScopeAllows(t *testing.T) {
 		// Whitespace in patterns
 		{"identity.* , financial.*", "financial.income", true},
 
+		// One person's contact fields
+		{"contacts.spouse.*", "contacts.spouse.full_name", true},
+		{"contacts.spouse.*", "contacts.spouse_2.full_name", false},
+		{"contacts.*", "contacts.child_1.phone", true},
+
 		// Empty/edge cases
 		{"", "identity.full_name", false},
 		{"identity.*", "", false},
@@ -55,6 +63,9 @@ func TestValidateFieldID(t *testing.T) {
 		"identity.t-shirt_size",
 		"my_category.my_field",
 		"A.B",
+		"contacts.spouse.full_name",
+		"contacts.child_1.date_of_birth",
+		"contacts.work_phone", // flat contact fields predate people
 	}
 	for _, id := range valid {
 		if err := ValidateFieldID(id); err != nil {
@@ -73,6 +84,8 @@ func TestValidateFieldID(t *testing.T) {
 		"identity.name\x00extra",   // null byte
 		"identity.name;DROP TABLE", // SQL injection attempt
 		"cat.field.extra",          // three parts still valid as SplitN(2) keeps "field.extra"
+		"contacts.spouse.name.x",   // one person level only
+		"contacts..full_name",
 	}
 	for _, id := range invalid {
 		if err := ValidateFieldID(id); err == nil {
@@ -94,6 +107,8 @@ func TestScopeAllowsCategory(t *testing.T) {
 		{"identity.*,financial.*", "addresses", false},
 		{"identity.full_name", "identity", true},
 		{"identity.full_name", "financial", false},
+		{"contacts.spouse.*", "contacts", true},
+		{"contacts.spouse.*", "identity", false},
 		{"", "identity", false},
 	}
 
@@ -104,3 +119,145 @@ func TestScopeAllowsCategory(t *testing.T) {
 		}
 	}
 }
+
+func TestScopeIsWildcard(t *testing.T) {
+	for scope, want := range map[string]bool{
+		"*":                    true,
+		"identity.*, *":        true,
+		"identity.*":           false,
+		"identity.*,payment.*": false,
+	} {
+		if got := ScopeIsWildcard(scope); got != want {
+			t.Errorf("ScopeIsWildcard(%q) = %v, want %v", scope, got, want)
+		}
+	}
+}
+
+func TestScopeSubset(t *testing.T) {
+	for _, tc := range []struct {
+		child, parent string
+		want          bool
+	}{
+		{"identity.email", "identity.*", true},
+		{"identity.email", "*", true},
+		{"identity.*", "identity.*,financial.*", true},
+		{"identity.email, financial.iban", "identity.*,financial.iban", true},
+		{"*", "*", true},
+		{"*", "identity.*", false},
+		{"identity.*", "identity.email", false},
+		{"financial.iban", "identity.*", false},
+		{"identity.email,financial.iban", "identity.*", false},
+		{"contacts.spouse.*", "contacts.*", true},
+		{"contacts.spouse.full_name", "contacts.spouse.*", true},
+		{"contacts.*", "contacts.spouse.*", false},
+		{"contacts.spouse_2.*", "contacts.spouse.*", false},
+		{"", "*", false},
+	} {
+		if got := ScopeSubset(tc.child, tc.parent); got != tc.want {
+			t.Errorf("ScopeSubset(%q, %q) = %v, want %v", tc.child, tc.parent, got, tc.want)
+		}
+	}
+}
+
+func TestExpandScope(t *testing.T) {
+	v, _ := tmpVault(t)
+	v.Set("payment.card_number", "4242", "critical")
+	v.Set("custom.note", "hi", "")
+
+	fields, err := v.ExpandScope("payment.card_number,custom.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 || fields[0].ID != "custom.note" || fields[1].ID != "payment.card_number" {
+		t.Fatalf("unexpected expansion: %+v", fields)
+	}
+	if !fields[1].Stored || fields[1].Sensitivity != "critical" {
+		t.Fatalf("expected stored critical card number, got %+v", fields[1])
+	}
+}
+
+func TestScopeSensitivity(t *testing.T) {
+	fields := []FieldInfo{
+		{ID: "identity.full_name", Sensitivity: "standard"},
+		{ID: "identity.date_of_birth", Sensitivity: "sensitive"},
+		{ID: "financial.ssn", Sensitivity: "critical"},
+	}
+	tests := []struct {
+		scope string
+		want  string
+	}{
+		{"identity.full_name", "standard"},
+		{"identity.*", "sensitive"},
+		{"*", "critical"},
+		{"identity.full_name,financial.ssn", "critical"},
+		{"payment.card_number", "critical"}, // not stored: schema default
+		{"custom.note", "standard"},         // not stored, not in schema
+		{"travel.*", ""},
+	}
+	for _, tt := range tests {
+		if got := ScopeSensitivity(tt.scope, fields); got != tt.want {
+			t.Errorf("ScopeSensitivity(%q) = %q, want %q", tt.scope, got, tt.want)
+		}
+	}
+}
+
+// FuzzValidateFieldID checks that any ID ValidateFieldID accepts is one the
+// scope matchers treat as a single field of one category.
+func FuzzValidateFieldID(f *testing.F) {
+	for _, seed := range []string{
+		"identity.full_name", "contacts.spouse.phone", "contacts.phone", "identity", ".x", "x.",
+		"identity.full.name", "identity.*", "a,b.c", "identity.full name", "identity.full_name\n", "ïdentity.x",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, id string) {
+		if ValidateFieldID(id) != nil {
+			return
+		}
+		if strings.ContainsAny(id, ",* \t\r\n") {
+			t.Fatalf("accepted %q, which a scope can't name", id)
+		}
+		category, _, _ := strings.Cut(id, ".")
+		if !ValidCategoryName(category) {
+			t.Fatalf("accepted %q with invalid category %q", id, category)
+		}
+		for _, scope := range []string{"*", id, category + ".*", "other.x, " + id} {
+			if !ScopeAllows(scope, id) {
+				t.Errorf("ScopeAllows(%q, %q) = false", scope, id)
+			}
+		}
+		if !ScopeAllowsCategory(id, category) {
+			t.Errorf("ScopeAllowsCategory(%q, %q) = false", id, category)
+		}
+	})
+}
+
+// FuzzScopeAllows checks properties of ScopeAllows that hold for any scope
+// a token could carry.
+func FuzzScopeAllows(f *testing.F) {
+	f.Add("identity.*", "identity.full_name")
+	f.Add("identity.*,financial.income", "financial.income")
+	f.Add("contacts.spouse.*", "contacts.spouse.phone")
+	f.Add(" * ", "contacts.phone")
+	f.Add(".*", "identity.x")
+	f.Add("identity.full_name", "identity.dob")
+	f.Add(",,", "")
+
+	f.Fuzz(func(t *testing.T, scope, id string) {
+		allowed := ScopeAllows(scope, id)
+		if !allowed && ScopeIsWildcard(scope) {
+			t.Fatalf("wildcard scope %q denies %q", scope, id)
+		}
+		if allowed && !ScopeAllows(scope+",other.x", id) {
+			t.Fatalf("adding a pattern to %q took away %q", scope, id)
+		}
+		if !allowed || ValidateFieldID(id) != nil {
+			return
+		}
+		category, _, _ := strings.Cut(id, ".")
+		if !ScopeAllowsCategory(scope, category) {
+			t.Fatalf("%q allows %q but not its category %q", scope, id, category)
+		}
+	})
+}
@@ -6,18 +6,33 @@ import (
 	"encoding/hex"
 	"sync"
 	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
 )
 
 const defaultAutoLockDuration = 30 * time.Minute
 
-// Session holds the in-memory vault key and session token.
+// Session holds the in-memory vault key and session token. Each unlock
+// (the CLI, a browser tab, ...) gets its own session with its own idle timer;
+// the vault stays unlocked while any of them is live.
 type Session struct {
 	mu       sync.Mutex
+	id       string // public handle for listing and revoking; not a credential
+	label    string // who unlocked, e.g. "cli" or "ui"
+	created  time.Time
 	token    string
 	vaultKey []byte
+	subkeys  map[string][]byte // category -> HKDF subkey, zeroed with the vault key
 	timer    *time.Timer
 	lockFn   func()
 	ttl      time.Duration
+	lastUsed time.Time
+	pinned   bool // never auto-locks; see Vault.PinSession
+	bound    bool // only accepted from this machine; see Vault.BindSession
+	boundUID int  // and only from this user, unless negative
+
+	elevation     []byte // SHA-256 of the current step-up token, if any
+	elevatedUntil time.Time
 }
 
 // NewSession creates a session with the given vault key and auto-lock callback.
@@ -26,11 +41,20 @@ func NewSession(vaultKey []byte, lockFn func()) (*Session, error) {
 	if _, err := rand.Read(tokenBytes); err != nil {
 		return nil, err
 	}
+	idBytes := make([]byte, 4)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
 
+	now := time.Now()
 	s := &Session{
-		token:  hex.EncodeToString(tokenBytes),
-		lockFn: lockFn,
-		ttl:    defaultAutoLockDuration,
+		id:       hex.EncodeToString(idBytes),
+		created:  now,
+		token:    hex.EncodeToString(tokenBytes),
+		subkeys:  make(map[string][]byte),
+		lockFn:   lockFn,
+		ttl:      defaultAutoLockDuration,
+		lastUsed: now,
 	}
 	// Copy vault key so caller can't mutate it
 	s.vaultKey = make([]byte, len(vaultKey))
@@ -42,6 +66,28 @@ func NewSession(vaultKey []byte, lockFn func()) (*Session, error) {
 	return s, nil
 }
 
+// setTTL changes the idle time before the session locks and restarts the
+// timer.
+func (s *Session) setTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+	if s.timer != nil {
+		s.timer.Reset(ttl)
+	}
+}
+
+// pin stops the auto-lock timer for good.
+func (s *Session) pin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.pinned = true
+}
+
 // Token returns the session token string.
 func (s *Session) Token() string {
 	s.mu.Lock()
@@ -61,6 +107,30 @@ func (s *Session) VaultKey() []byte {
 	return cp
 }
 
+// Subkey returns a copy of the category subkey, deriving it with HKDF on first
+// use and caching it for the life of the session. Returns nil if the session
+// is destroyed.
+func (s *Session) Subkey(salt []byte, category string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vaultKey == nil {
+		return nil, nil
+	}
+	sk, ok := s.subkeys[category]
+	if !ok {
+		var err error
+		sk, err = crypto.DeriveSubkey(s.vaultKey, salt, category)
+		if err != nil {
+			return nil, err
+		}
+		lockMemory(sk)
+		s.subkeys[category] = sk
+	}
+	cp := make([]byte, len(sk))
+	copy(cp, sk)
+	return cp, nil
+}
+
 // ValidateToken checks a token using constant-time comparison.
 func (s *Session) ValidateToken(token string) bool {
 	s.mu.Lock()
@@ -71,12 +141,53 @@ func (s *Session) ValidateToken(token string) bool {
 	return subtle.ConstantTimeCompare([]byte(s.token), []byte(token)) == 1
 }
 
+// allows reports whether the session accepts a request from a client that
+// is local to the vault's machine or not, run by uid (negative if the
+// platform can't tell).
+func (s *Session) allows(local bool, uid int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.bound {
+		return true
+	}
+	return local && (s.boundUID < 0 || uid < 0 || uid == s.boundUID)
+}
+
 // Touch resets the auto-lock timer.
 func (s *Session) Touch() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.timer != nil {
 		s.timer.Reset(s.ttl)
+		s.lastUsed = time.Now()
+	}
+}
+
+// Info reports the session's identity and auto-lock timers.
+func (s *Session) Info() SessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idle := time.Since(s.lastUsed)
+	if s.pinned {
+		return SessionInfo{
+			ID:          s.id,
+			Label:       s.label,
+			CreatedAt:   s.created.Truncate(time.Second),
+			IdleSeconds: int(idle / time.Second),
+			Pinned:      true,
+			Bound:       s.bound,
+		}
+	}
+	remaining := max(s.ttl-idle, 0)
+	return SessionInfo{
+		ID:                 s.id,
+		Label:              s.label,
+		CreatedAt:          s.created.Truncate(time.Second),
+		ExpiresAt:          time.Now().Add(remaining).Truncate(time.Second),
+		ExpiresInSeconds:   int(remaining / time.Second),
+		IdleSeconds:        int(idle / time.Second),
+		IdleTimeoutSeconds: int(s.ttl / time.Second),
+		Bound:              s.bound,
 	}
 }
 
@@ -111,4 +222,13 @@ func (s *Session) zeroKey() {
 		s.vaultKey[i] = 0
 	}
 	s.vaultKey = nil
+
+	for category, sk := range s.subkeys {
+		unlockMemory(sk)
+		for i := range sk {
+			sk[i] = 0
+		}
+		delete(s.subkeys, category)
+	}
+	s.elevation = nil
 }
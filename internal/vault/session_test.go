@@ -1,7 +1,11 @@
 package vault
 
 import (
+	"bytes"
 	"testing"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
 )
 
 func TestMemProtect_NoPanic(t *testing.T) {
@@ -64,3 +68,88 @@ func TestSession_AutoLock_MemProtect(t *testing.T) {
 		t.Fatal("expected nil vault key after auto-lock")
 	}
 }
+
+func TestSession_Subkey_CachedAndZeroed(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	salt := []byte("salt")
+
+	s, err := NewSession(key, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := crypto.DeriveSubkey(key, salt, "identity")
+	got, err := s.Subkey(salt, "identity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("cached subkey does not match HKDF derivation")
+	}
+
+	// Mutating the returned copy must not affect the cache
+	got[0] ^= 0xff
+	again, _ := s.Subkey(salt, "identity")
+	if !bytes.Equal(again, want) {
+		t.Fatal("caller mutated cached subkey")
+	}
+
+	cached := s.subkeys["identity"]
+	s.Destroy()
+	if !bytes.Equal(cached, make([]byte, len(cached))) {
+		t.Fatal("expected cached subkey to be zeroed on destroy")
+	}
+	if sk, _ := s.Subkey(salt, "identity"); sk != nil {
+		t.Fatal("expected nil subkey after destroy")
+	}
+}
+
+func BenchmarkSubkey_Derive(b *testing.B) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	salt := make([]byte, 32)
+	for b.Loop() {
+		crypto.DeriveSubkey(key, salt, "identity")
+	}
+}
+
+func BenchmarkSubkey_SessionCached(b *testing.B) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	salt := make([]byte, 32)
+	s, err := NewSession(key, func() {})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer s.Destroy()
+	for b.Loop() {
+		s.Subkey(salt, "identity")
+	}
+}
+
+func TestSessionInfo_DoesNotTouch(t *testing.T) {
+	v, _ := tmpVault(t)
+	s := onlySession(t, v)
+	token := s.Token()
+	s.mu.Lock()
+	s.lastUsed = time.Now().Add(-10 * time.Minute)
+	s.mu.Unlock()
+
+	for range 2 {
+		info, err := v.SessionInfo(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.IdleSeconds < 600 || info.ExpiresInSeconds > 20*60 || info.IdleTimeoutSeconds != 30*60 {
+			t.Fatalf("unexpected timers %+v", info)
+		}
+	}
+
+	v.TouchSession(token)
+	if info, _ := v.SessionInfo(token); info.IdleSeconds != 0 || info.ExpiresInSeconds < 30*60-1 {
+		t.Fatalf("expected timers reset by activity, got %+v", info)
+	}
+
+	v.Lock()
+	if _, err := v.SessionInfo(token); err != ErrSessionNotFound {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// SignatureAlg names how read responses are signed.
+const SignatureAlg = "hmac-sha256"
+
+// signKeyInfo prefixes the HKDF info for a consumer's signing key.
+const signKeyInfo = ":sign:"
+
+// ValueSignature lets a system downstream of a consumer check that a value
+// came from the vault unchanged. MAC is the hex HMAC-SHA256, under the
+// consumer's signing key, of SignatureMessage(id, value, timestamp).
+type ValueSignature struct {
+	Alg       string `json:"alg"`
+	Consumer  string `json:"consumer"`
+	Timestamp string `json:"timestamp"` // RFC 3339, UTC
+	MAC       string `json:"mac"`
+}
+
+// SignatureMessage is what a signature covers: the field ID, the value as
+// returned, and the time it was signed, each on its own line. Neither the
+// ID nor the timestamp holds a newline, so a value with newlines can't be
+// read two ways.
+func SignatureMessage(id, value, timestamp string) []byte {
+	return []byte(id + "\n" + value + "\n" + timestamp)
+}
+
+// SigningKey returns consumer's signing key, which the owner hands to the
+// systems that verify what the consumer passes on. It is derived from the
+// vault key, so it stays the same across unlocks.
+func (v *Vault) SigningKey(consumer string) ([]byte, error) {
+	if consumer == "" {
+		return nil, ErrInvalidConsumer
+	}
+	return v.subkey(signKeyInfo + consumer)
+}
+
+// Sign returns fields with a signature for consumer on each, over the value
+// as given. fields itself isn't changed, since it may be cached.
+func (v *Vault) Sign(consumer string, fields []FieldInfo, now time.Time) ([]FieldInfo, error) {
+	key, err := v.SigningKey(consumer)
+	if err != nil {
+		return nil, err
+	}
+	ts := now.UTC().Format(time.RFC3339)
+	out := make([]FieldInfo, len(fields))
+	for i, f := range fields {
+		m := hmac.New(sha256.New, key)
+		m.Write(SignatureMessage(f.ID, f.Value, ts))
+		f.Signature = &ValueSignature{Alg: SignatureAlg, Consumer: consumer, Timestamp: ts, MAC: hex.EncodeToString(m.Sum(nil))}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// VerifySignature reports whether sig is a valid signature, under key, of
+// value as field id.
+func VerifySignature(key []byte, id, value string, sig ValueSignature) bool {
+	if sig.Alg != SignatureAlg {
+		return false
+	}
+	got, err := hex.DecodeString(sig.MAC)
+	if err != nil {
+		return false
+	}
+	m := hmac.New(sha256.New, key)
+	m.Write(SignatureMessage(id, value, sig.Timestamp))
+	return hmac.Equal(got, m.Sum(nil))
+}
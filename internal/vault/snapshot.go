@@ -0,0 +1,144 @@
+package vault
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Snapshot lifetimes.
+const (
+	DefaultSnapshotTTL = 24 * time.Hour
+	MaxSnapshotTTL     = 30 * 24 * time.Hour
+)
+
+const (
+	// snapshotsMetaKey holds the live snapshots, encrypted: they are copies
+	// of field values.
+	snapshotsMetaKey = "context_snapshots"
+
+	// snapshotKeyInfo is the HKDF info for the snapshot table key.
+	snapshotKeyInfo = ":snapshots"
+
+	// maxSnapshots bounds the live snapshots, which are kept whole until
+	// they expire.
+	maxSnapshots = 100
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found or expired")
+	ErrSnapshotLimit    = errors.New("too many live snapshots; delete some or let them expire")
+	ErrSnapshotTTL      = errors.New("snapshot ttl must be positive and at most 720h")
+)
+
+// Snapshot is a context bundle frozen at creation, so an agent run can cite
+// exactly the data it was given and have it reviewed later, whatever has
+// changed in the vault since.
+type Snapshot struct {
+	ID        string         `json:"id"`
+	Consumer  string         `json:"consumer"`
+	Scope     string         `json:"scope"`
+	CreatedAt time.Time      `json:"created_at"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	Context   *ContextBundle `json:"context,omitempty"`
+}
+
+// liveSnapshots loads the snapshot table without expired entries. The table
+// is uncached, so the map is the caller's to modify.
+func (v *Vault) liveSnapshots() (map[string]Snapshot, error) {
+	m, err := v.snapshots.load()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	maps.DeleteFunc(m, func(_ string, s Snapshot) bool { return !now.Before(s.ExpiresAt) })
+	return m, nil
+}
+
+// CreateSnapshot stores bundle, already filtered to scope for consumer, and
+// returns the snapshot. A zero ttl means DefaultSnapshotTTL.
+func (v *Vault) CreateSnapshot(consumer, scope string, ttl time.Duration, bundle *ContextBundle) (*Snapshot, error) {
+	if ttl == 0 {
+		ttl = DefaultSnapshotTTL
+	}
+	if ttl < 0 || ttl > MaxSnapshotTTL {
+		return nil, ErrSnapshotTTL
+	}
+	v.snapshots.writeMu.Lock()
+	defer v.snapshots.writeMu.Unlock()
+	m, err := v.liveSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	if len(m) >= maxSnapshots {
+		return nil, ErrSnapshotLimit
+	}
+	b := make([]byte, 12)
+	crand.Read(b)
+	now := time.Now().UTC()
+	s := Snapshot{
+		ID:        "snap_" + hex.EncodeToString(b),
+		Consumer:  consumer,
+		Scope:     scope,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		Context:   bundle,
+	}
+	m[s.ID] = s
+	if err := v.snapshots.save(m); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Snapshot returns a live snapshot with its contents.
+func (v *Vault) Snapshot(id string) (*Snapshot, error) {
+	m, err := v.liveSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	s, ok := m[id]
+	if !ok {
+		return nil, ErrSnapshotNotFound
+	}
+	return &s, nil
+}
+
+// Snapshots lists the live snapshots, oldest first, without their contents.
+func (v *Vault) Snapshots() ([]Snapshot, error) {
+	m, err := v.liveSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Snapshot, 0, len(m))
+	for _, s := range m {
+		s.Context = nil
+		out = append(out, s)
+	}
+	slices.SortFunc(out, func(a, b Snapshot) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out, nil
+}
+
+// DeleteSnapshot removes a snapshot before it expires.
+func (v *Vault) DeleteSnapshot(id string) error {
+	v.snapshots.writeMu.Lock()
+	defer v.snapshots.writeMu.Unlock()
+	m, err := v.liveSnapshots()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[id]; !ok {
+		return ErrSnapshotNotFound
+	}
+	delete(m, id)
+	return v.snapshots.save(m)
+}
@@ -1,6 +1,9 @@
 package vault
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // Suggestion is returned when a non-canonical field has a similar canonical name.
 type Suggestion struct {
@@ -62,9 +65,54 @@ var synonyms = map[string]string{
 	"position": "title",
 	"role":     "title",
 
+	// vehicles and insurance (the vehicles schema pack)
+	"license_plate":                 "plate",
+	"plate_number":                  "plate",
+	"registration_plate":            "plate",
+	"vin_number":                    "vin",
+	"vehicle_identification_number": "vin",
+	"manufacturer":                  "make",
+	"model_year":                    "year",
+	"registration_expiration":       "registration_expiry",
+	"auto_insurer":                  "auto_provider",
+	"car_insurer":                   "auto_provider",
+	"auto_policy":                   "auto_policy_number",
+	"car_policy_number":             "auto_policy_number",
+	"health_insurer":                "health_provider",
+	"health_policy":                 "health_policy_number",
+	"member_id":                     "health_member_id",
+	"home_insurer":                  "home_provider",
+	"home_policy":                   "home_policy_number",
+
+	// travel (the travel schema pack)
+	"passport":            "passport_number",
+	"passport_no":         "passport_number",
+	"passport_expiration": "passport_expiry",
+	"passport_exp":        "passport_expiry",
+	"visa":                "visa_number",
+	"visa_expiration":     "visa_expiry",
+	"ktn":                 "known_traveler_number",
+	"global_entry":        "known_traveler_number",
+	"tsa_precheck":        "known_traveler_number",
+	"global_entry_expiry": "trusted_traveler_expiry",
+	"redress":             "redress_number",
+
+	// medical (the medical schema pack)
+	"blood_group":         "blood_type",
+	"bloodtype":           "blood_type",
+	"allergy":             "allergies",
+	"medication":          "medications",
+	"meds":                "medications",
+	"prescriptions":       "medications",
+	"doctor":              "physician_name",
+	"physician":           "physician_name",
+	"gp":                  "physician_name",
+	"doctor_phone":        "physician_phone",
+	"health_insurance_id": "insurance_member_id",
+
 	// preferences
-	"tz":   "timezone",
-	"lang": "language",
+	"tz":     "timezone",
+	"lang":   "language",
 	"locale": "language",
 }
 
@@ -156,3 +204,37 @@ func levenshtein(a, b string) int {
 	}
 	return prev[len(b)]
 }
+
+// MatchFieldIDs resolves a bare field name such as "email" or "birthday"
+// against ids, for commands that take a field ID from a person typing it.
+// It returns the best matches, sorted: fields with that exact name if any,
+// else those it is a synonym for, else those whose name contains it, else
+// those within a small edit distance. More than one match is ambiguous.
+func MatchFieldIDs(query string, ids []string) []string {
+	q := strings.ToLower(strings.TrimSpace(query))
+	q = strings.NewReplacer(" ", "_", "-", "_").Replace(q)
+	if q == "" {
+		return nil
+	}
+	threshold := max(2, len(q)/3)
+	tiers := []func(name string) bool{
+		func(name string) bool { return name == q },
+		func(name string) bool { return synonyms[q] == name },
+		func(name string) bool { return strings.Contains(name, q) },
+		func(name string) bool { return levenshtein(q, name) <= threshold },
+	}
+	for _, match := range tiers {
+		var out []string
+		for _, id := range ids {
+			_, name, ok := strings.Cut(id, ".")
+			if ok && match(strings.ReplaceAll(name, ".", "_")) { // contacts.spouse.phone is spouse_phone
+				out = append(out, id)
+			}
+		}
+		if len(out) > 0 {
+			sort.Strings(out)
+			return out
+		}
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package vault
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestSuggestCanonical_Synonyms(t *testing.T) {
 	tests := []struct {
@@ -39,10 +42,10 @@ func TestSuggestCanonical_Typos(t *testing.T) {
 		id            string
 		wantCanonical string
 	}{
-		{"identity.fist_name", "identity.first_name"},   // missing 'r'
-		{"identity.ful_name", "identity.full_name"},     // missing 'l'
-		{"addresses.home_cty", "addresses.home_city"},   // missing 'i'
-		{"payment.card_bran", "payment.card_brand"},     // missing 'd'
+		{"identity.fist_name", "identity.first_name"}, // missing 'r'
+		{"identity.ful_name", "identity.full_name"},   // missing 'l'
+		{"addresses.home_cty", "addresses.home_city"}, // missing 'i'
+		{"payment.card_bran", "payment.card_brand"},   // missing 'd'
 	}
 	for _, tt := range tests {
 		s := SuggestCanonical(tt.id)
@@ -100,3 +103,24 @@ func TestLevenshtein(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchFieldIDs(t *testing.T) {
+	ids := []string{"identity.email", "identity.full_name", "work.email", "addresses.home_city", "identity.date_of_birth"}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"email", "identity.email,work.email"}, // ambiguous
+		{"Full Name", "identity.full_name"},
+		{"birthday", "identity.date_of_birth"}, // synonym
+		{"city", "addresses.home_city"},        // synonym
+		{"birth", "identity.date_of_birth"},    // substring
+		{"emial", "identity.email,work.email"}, // typo
+		{"passport", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(MatchFieldIDs(tt.query, ids), ","); got != tt.want {
+			t.Errorf("MatchFieldIDs(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
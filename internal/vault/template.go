@@ -0,0 +1,229 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// Mask rules a context template can apply to a field's value.
+const (
+	MaskRedact  = "redact"  // the value is replaced entirely
+	MaskLast4   = "last4"   // only the last four characters show
+	MaskInitial = "initial" // only the first character shows
+)
+
+// MaskRules lists the mask rules a context template accepts.
+var MaskRules = []string{MaskRedact, MaskLast4, MaskInitial}
+
+// redacted stands in for a masked value.
+const redacted = "[redacted]"
+
+var (
+	ErrTemplateNotFound = errors.New("no context template for this consumer")
+	ErrInvalidTemplate  = errors.New("a context template needs at least one field pattern")
+	ErrInvalidMask      = errors.New("invalid mask rule: must be redact, last4, or initial")
+)
+
+const (
+	// templatesMetaKey holds the context templates, encrypted like the
+	// other access policy tables.
+	templatesMetaKey = "context_templates"
+
+	// templateKeyInfo is the HKDF info for the context template table key.
+	templateKeyInfo = ":templates"
+)
+
+// ContextTemplate is the owner's choice of what a consumer's tokens get from
+// /vault/context: the fields matching Fields, in that order, with values
+// masked as Mask says. It narrows what the token's scope would give, never
+// widens it.
+type ContextTemplate struct {
+	Consumer  string     `json:"consumer"`
+	Fields    []string   `json:"fields"` // scope patterns, in the order fields are given
+	Mask      []MaskRule `json:"mask,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// MaskRule masks the values of the fields matching a scope pattern. The
+// first rule that matches a field applies.
+type MaskRule struct {
+	Fields string `json:"fields"`
+	Rule   string `json:"rule"`
+}
+
+// ValidateScopePattern checks a single scope pattern: "*", "category.*", or a
+// field ID.
+func ValidateScopePattern(p string) error {
+	if p == "*" {
+		return nil
+	}
+	if cat, ok := strings.CutSuffix(p, ".*"); ok {
+		if !validWildcardPrefix(cat) {
+			return fmt.Errorf("invalid category %q: only alphanumeric, underscore, hyphen allowed", cat)
+		}
+		return nil
+	}
+	return ValidateFieldID(p)
+}
+
+// SetContextTemplate stores t for its consumer, replacing any earlier one.
+// Aliases among the patterns are resolved to their targets.
+func (v *Vault) SetContextTemplate(t ContextTemplate) error {
+	if strings.TrimSpace(t.Consumer) == "" {
+		return ErrInvalidConsumer
+	}
+	if len(t.Fields) == 0 {
+		return ErrInvalidTemplate
+	}
+	for i, p := range t.Fields {
+		p = strings.TrimSpace(p)
+		if err := ValidateScopePattern(p); err != nil {
+			return err
+		}
+		t.Fields[i] = v.ResolveAlias(p)
+	}
+	for i, m := range t.Mask {
+		if err := ValidateScopePattern(m.Fields); err != nil {
+			return err
+		}
+		if !slices.Contains(MaskRules, m.Rule) {
+			return ErrInvalidMask
+		}
+		t.Mask[i].Fields = v.ResolveAlias(m.Fields)
+	}
+	t.UpdatedAt = time.Now().UTC()
+
+	v.templates.writeMu.Lock()
+	defer v.templates.writeMu.Unlock()
+	m, err := v.templates.load()
+	if err != nil {
+		return err
+	}
+	next := maps.Clone(m)
+	next[t.Consumer] = t
+	if err := v.templates.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: strings.Join(t.Fields, ","), Action: "template_set", Purpose: "consumer: " + t.Consumer})
+	return nil
+}
+
+// DeleteContextTemplate removes a consumer's template, so its tokens get
+// everything their scopes cover again.
+func (v *Vault) DeleteContextTemplate(consumer string) error {
+	v.templates.writeMu.Lock()
+	defer v.templates.writeMu.Unlock()
+	m, err := v.templates.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[consumer]; !ok {
+		return ErrTemplateNotFound
+	}
+	next := maps.Clone(m)
+	delete(next, consumer)
+	if err := v.templates.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: consumer, Action: "template_delete"})
+	return nil
+}
+
+// ContextTemplates returns every context template, sorted by consumer.
+func (v *Vault) ContextTemplates() ([]ContextTemplate, error) {
+	m, err := v.templates.load()
+	if err != nil {
+		return nil, err
+	}
+	out := slices.Collect(maps.Values(m))
+	slices.SortFunc(out, func(a, b ContextTemplate) int { return strings.Compare(a.Consumer, b.Consumer) })
+	return out, nil
+}
+
+// TemplateFor returns the template for a service token whose delegation
+// chain has consumers (see TokenConsumers): the nearest one's that has a
+// template, so a sub-agent gets its delegator's unless it has its own. It
+// returns nil if none has one.
+func (v *Vault) TemplateFor(consumers []string) (*ContextTemplate, error) {
+	m, err := v.templates.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range consumers {
+		if t, ok := m[c]; ok {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+// Apply returns the fields of b the template selects, masked, with Order
+// listing them in the template's order. b itself is left untouched.
+func (t *ContextTemplate) Apply(b *ContextBundle) *ContextBundle {
+	rank := func(id string) int {
+		return slices.IndexFunc(t.Fields, func(p string) bool { return ScopeAllows(p, id) })
+	}
+	var selected []FieldInfo
+	for _, fields := range b.Categories {
+		for _, f := range fields {
+			if rank(f.ID) >= 0 {
+				selected = append(selected, t.mask(f))
+			}
+		}
+	}
+	slices.SortFunc(selected, func(a, b FieldInfo) int {
+		if c := rank(a.ID) - rank(b.ID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	out := &ContextBundle{Categories: make(map[string][]FieldInfo), Order: make([]string, 0, len(selected))}
+	for _, f := range selected {
+		out.Categories[f.Category] = append(out.Categories[f.Category], f)
+		out.Order = append(out.Order, f.ID)
+	}
+	return out
+}
+
+// mask applies the first mask rule matching f.
+func (t *ContextTemplate) mask(f FieldInfo) FieldInfo {
+	i := slices.IndexFunc(t.Mask, func(m MaskRule) bool { return ScopeAllows(m.Fields, f.ID) })
+	if i < 0 {
+		return f
+	}
+	f.Value = MaskValue(t.Mask[i].Rule, f.Value)
+	f.Entries = slices.Clone(f.Entries)
+	for j := range f.Entries {
+		f.Entries[j].Value = MaskValue(t.Mask[i].Rule, f.Entries[j].Value)
+	}
+	return f
+}
+
+// MaskValue applies a mask rule to value.
+func MaskValue(rule, value string) string {
+	n := utf8.RuneCountInString(value)
+	switch rule {
+	case MaskLast4:
+		if n <= 4 {
+			return redacted
+		}
+		r := []rune(value)
+		return strings.Repeat("•", n-4) + string(r[n-4:])
+	case MaskInitial:
+		if n == 0 {
+			return value
+		}
+		r, _ := utf8.DecodeRuneInString(value)
+		return string(r) + "."
+	default:
+		return redacted
+	}
+}
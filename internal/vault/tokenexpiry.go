@@ -0,0 +1,87 @@
+package vault
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// DefaultTokenExpiryWindow is how far ahead expiring service tokens are
+// reported when no window is given.
+const DefaultTokenExpiryWindow = 30 * 24 * time.Hour
+
+// tokenExpiryNoticesMetaKey holds the expiry times already notified, by
+// token hash, so each is announced once. Token hashes and expiry times are
+// already in plaintext in the tokens table, so this isn't encrypted.
+const tokenExpiryNoticesMetaKey = "token_expiry_notices"
+
+// ExpiringServiceTokens returns the service and discovery tokens that
+// expire within the window from now, soonest first. Delegated tokens are
+// left out: they are meant to be short-lived.
+func (v *Vault) ExpiringServiceTokens(now time.Time, within time.Duration) ([]store.Token, error) {
+	tokens, err := v.ListServiceTokens()
+	if err != nil {
+		return nil, err
+	}
+	var out []store.Token
+	for _, t := range tokens {
+		if t.Parent == "" && t.ExpiresAt.After(now) && t.ExpiresAt.Before(now.Add(within)) {
+			out = append(out, t)
+		}
+	}
+	slices.SortFunc(out, func(a, b store.Token) int { return a.ExpiresAt.Compare(b.ExpiresAt) })
+	return out, nil
+}
+
+// NotifyExpiringTokens sends a token_expiring event for each service token
+// expiring within the window, once per token, so an agent doesn't stop
+// working by surprise when its token's year runs out.
+func (v *Vault) NotifyExpiringTokens(now time.Time, within time.Duration) error {
+	v.expiryMu.Lock()
+	defer v.expiryMu.Unlock()
+	expiring, err := v.ExpiringServiceTokens(now, within)
+	if err != nil {
+		return err
+	}
+	notices := make(map[string]string)
+	if raw, err := v.db.GetMeta(tokenExpiryNoticesMetaKey); err != nil {
+		return err
+	} else if raw != "" {
+		json.Unmarshal([]byte(raw), &notices)
+	}
+	next := make(map[string]string)
+	for _, t := range expiring {
+		expires := t.ExpiresAt.UTC().Format(time.RFC3339)
+		next[t.TokenStr] = expires
+		if notices[t.TokenStr] == expires {
+			continue
+		}
+		v.notify(Event{
+			Type:     EventTokenExpiring,
+			Consumer: t.Consumer,
+			Token:    tokenPrefix(t.TokenStr),
+			Expires:  t.ExpiresAt.UTC().Format("2006-01-02"),
+			Time:     now,
+		})
+	}
+	if maps.Equal(notices, next) {
+		return nil
+	}
+	data, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	return v.db.SetMeta(tokenExpiryNoticesMetaKey, string(data))
+}
+
+// tokenPrefix is the start of a token's hash that identifies it in
+// listings and revocations.
+func tokenPrefix(hash string) string {
+	if len(hash) > minRevokePrefix {
+		return hash[:minRevokePrefix]
+	}
+	return hash
+}
@@ -0,0 +1,108 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// TokenMetadata describes a token for whoever manages it later, so a list
+// of tokens for the same consumer stays readable. It doesn't change what
+// the token can do.
+type TokenMetadata struct {
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"` // e.g. env=prod, owner=tax-agent
+}
+
+// Limits on token metadata, so listings stay readable.
+const (
+	maxTokenDescription = 256
+	maxTokenLabels      = 16
+	maxLabelValue       = 128
+)
+
+// labelKeyPattern is a lowercase key of up to 63 characters, like env or
+// team.owner.
+var labelKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+
+// Validate checks the description and labels, trimming surrounding space.
+func (m *TokenMetadata) Validate() error {
+	m.Description = strings.TrimSpace(m.Description)
+	if len(m.Description) > maxTokenDescription {
+		return fmt.Errorf("description must be at most %d bytes", maxTokenDescription)
+	}
+	if hasControl(m.Description) {
+		return fmt.Errorf("description must not contain control characters")
+	}
+	if len(m.Labels) > maxTokenLabels {
+		return fmt.Errorf("at most %d labels", maxTokenLabels)
+	}
+	for k, val := range m.Labels {
+		if !labelKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid label key %q, want lowercase letters, digits, '_', '.', or '-'", k)
+		}
+		val = strings.TrimSpace(val)
+		if len(val) > maxLabelValue || hasControl(val) {
+			return fmt.Errorf("label %s must be at most %d bytes without control characters", k, maxLabelValue)
+		}
+		m.Labels[k] = val
+	}
+	return nil
+}
+
+func hasControl(s string) bool {
+	return strings.ContainsFunc(s, unicode.IsControl)
+}
+
+// TokenLabels decodes the labels stored on token t.
+func TokenLabels(t *store.Token) map[string]string {
+	var labels map[string]string
+	if t.Labels != "" {
+		json.Unmarshal([]byte(t.Labels), &labels)
+	}
+	return labels
+}
+
+// encodeLabels is the form labels are stored in on a token.
+func encodeLabels(labels map[string]string) (string, error) {
+	if len(labels) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(labels)
+	return string(b), err
+}
+
+// ParseLabelSelector parses "key=value" and "key" terms, as given to
+// 'pvault list-service-tokens --label'. A bare key matches any value.
+func ParseLabelSelector(terms []string) (map[string]*string, error) {
+	sel := make(map[string]*string, len(terms))
+	for _, term := range terms {
+		k, val, hasValue := strings.Cut(term, "=")
+		k = strings.TrimSpace(k)
+		if !labelKeyPattern.MatchString(k) {
+			return nil, fmt.Errorf("invalid label selector %q, want key=value or key", term)
+		}
+		if hasValue {
+			val = strings.TrimSpace(val)
+			sel[k] = &val
+		} else {
+			sel[k] = nil
+		}
+	}
+	return sel, nil
+}
+
+// MatchLabels reports whether labels satisfy every term of sel.
+func MatchLabels(labels map[string]string, sel map[string]*string) bool {
+	for k, want := range sel {
+		got, ok := labels[k]
+		if !ok || (want != nil && got != *want) {
+			return false
+		}
+	}
+	return true
+}
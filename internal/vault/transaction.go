@@ -0,0 +1,170 @@
+package vault
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// Transaction operation kinds.
+const (
+	TxSet    = "set"
+	TxDelete = "delete"
+)
+
+var (
+	ErrEmptyTransaction = errors.New("transaction has no operations")
+	ErrInvalidTxOp      = errors.New("op must be set or delete")
+	ErrEmptyValue       = errors.New("value required")
+)
+
+// TxOp is one operation in a transaction.
+type TxOp struct {
+	Op          string `json:"op"` // TxSet or TxDelete
+	ID          string `json:"id"`
+	Value       string `json:"value,omitempty"`
+	Sensitivity string `json:"sensitivity,omitempty"` // empty means the schema default
+	Raw         bool   `json:"raw,omitempty"`         // skip normalization
+}
+
+// TxResult reports what one operation did.
+type TxResult struct {
+	Op         string `json:"op"`
+	ID         string `json:"id"`
+	Alias      string `json:"alias,omitempty"`      // the ID as given, if it was an alias
+	Normalized string `json:"normalized,omitempty"` // the stored value, if normalization changed it
+}
+
+// TxOpError reports the operation that made a transaction invalid.
+type TxOpError struct {
+	Index int
+	Err   error
+}
+
+func (e *TxOpError) Error() string {
+	return fmt.Sprintf("ops[%d]: %v", e.Index, e.Err)
+}
+
+func (e *TxOpError) Unwrap() error { return e.Err }
+
+// Apply runs a list of sets and deletes as one transaction: every operation
+// is validated and encrypted up front, then all of them are written together
+// or none are. Each operation gets its own audit entry, and all of them share
+// group as their request ID so they read as one change; an empty group gets a
+// generated one. purpose, if set, is recorded on every entry. Apply returns
+// the group along with per-operation results.
+func (v *Vault) Apply(ops []TxOp, group, purpose string) (string, []TxResult, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return "", nil, err
+	}
+	if len(ops) == 0 {
+		return "", nil, ErrEmptyTransaction
+	}
+
+	storeOps := make([]store.FieldOp, 0, len(ops))
+	results := make([]TxResult, 0, len(ops))
+	var written []PluginField
+	subkeys := make(map[string][]byte)
+	now := time.Now()
+	for i, op := range ops {
+		if err := ValidateFieldID(op.ID); err != nil {
+			return "", nil, &TxOpError{Index: i, Err: err}
+		}
+		id := v.ResolveAlias(op.ID)
+		result := TxResult{Op: op.Op, ID: id}
+		if id != op.ID {
+			result.Alias = op.ID
+		}
+
+		switch op.Op {
+		case TxDelete:
+			storeOps = append(storeOps, store.FieldOp{Delete: true, Field: store.Field{ID: id}})
+		case TxSet:
+			if strings.TrimSpace(op.Value) == "" {
+				return "", nil, &TxOpError{Index: i, Err: ErrEmptyValue}
+			}
+			sensitivity := op.Sensitivity
+			if sensitivity == "" {
+				sensitivity = DefaultSensitivity(id)
+			}
+			if !validTiers[sensitivity] {
+				return "", nil, &TxOpError{Index: i, Err: ErrInvalidTier}
+			}
+			value := op.Value
+			if !op.Raw {
+				value = Normalize(id, value)
+			}
+
+			category, fieldName, _ := strings.Cut(id, ".")
+			subkey, ok := subkeys[category]
+			if !ok {
+				var err error
+				if subkey, err = v.subkey(category); err != nil {
+					return "", nil, err
+				}
+				subkeys[category] = subkey
+			}
+			encrypted, err := crypto.EncryptToBase64(subkey, []byte(value))
+			if err != nil {
+				return "", nil, fmt.Errorf("encrypt: %w", err)
+			}
+			fop := store.FieldOp{Field: store.Field{
+				ID:          id,
+				Category:    category,
+				FieldName:   fieldName,
+				Value:       encrypted,
+				Sensitivity: sensitivity,
+				UpdatedAt:   now,
+			}}
+			if value != op.Value {
+				original, err := crypto.EncryptToBase64(subkey, []byte(op.Value))
+				if err != nil {
+					return "", nil, fmt.Errorf("encrypt: %w", err)
+				}
+				fop.History = &store.FieldHistory{Value: original, Reason: HistoryNormalized, CreatedAt: now}
+				result.Normalized = value
+			}
+			storeOps = append(storeOps, fop)
+			written = append(written, PluginField{ID: id, Value: value, Sensitivity: sensitivity})
+		default:
+			return "", nil, &TxOpError{Index: i, Err: ErrInvalidTxOp}
+		}
+		results = append(results, result)
+	}
+
+	if len(written) > 0 {
+		if err := v.validateWrite(written); err != nil {
+			return "", nil, err
+		}
+	}
+	for category, subkey := range subkeys {
+		if err := v.ensureKCV(category, subkey); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := v.db.ApplyFieldOps(storeOps); err != nil {
+		return "", nil, err
+	}
+	v.gen.Add(1)
+
+	if group == "" {
+		b := make([]byte, 8)
+		crand.Read(b)
+		group = "tx-" + hex.EncodeToString(b)
+	}
+	for _, r := range results {
+		action := "write"
+		if r.Op == TxDelete {
+			action = "delete"
+			v.forgetCanary(r.ID)
+		}
+		v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: r.ID, Action: action, Purpose: purpose, RequestID: group})
+	}
+	return group, results, nil
+}
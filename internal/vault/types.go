@@ -8,20 +8,64 @@ type VaultStatus struct {
 	Locked      bool           `json:"locked"`
 	FieldCount  int            `json:"field_count"`
 	Categories  map[string]int `json:"categories"`
+	Language    string         `json:"language,omitempty"` // preferences.language, when unlocked and public
+}
+
+// SessionInfo describes an unlocked session and when it will auto-lock. Any
+// use of the session pushes ExpiresAt back to a full IdleTimeout from then.
+type SessionInfo struct {
+	ID                 string    `json:"id"`
+	Label              string    `json:"label,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	ExpiresInSeconds   int       `json:"expires_in_seconds"`
+	IdleSeconds        int       `json:"idle_seconds"`
+	IdleTimeoutSeconds int       `json:"idle_timeout_seconds"`
+	Pinned             bool      `json:"pinned,omitempty"` // never auto-locks; the expiry fields are zero
+	Bound              bool      `json:"bound,omitempty"`  // only accepted from the client that opened it
+}
+
+// HistoryNormalized marks a history entry holding a value as entered, before
+// Normalize rewrote it.
+const HistoryNormalized = "normalized"
+
+// FieldRevision is a decrypted field history entry.
+type FieldRevision struct {
+	Version   int       `json:"version"`
+	Value     string    `json:"value"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // FieldInfo is a decrypted field returned to callers.
 type FieldInfo struct {
-	ID          string    `json:"id"`
-	Category    string    `json:"category"`
-	FieldName   string    `json:"field_name"`
-	Value       string    `json:"value,omitempty"`
-	Sensitivity string    `json:"sensitivity"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Version     int       `json:"version"`
+	ID          string          `json:"id"`
+	Category    string          `json:"category"`
+	FieldName   string          `json:"field_name"`
+	Value       string          `json:"value,omitempty"` // a list field's primary entry
+	Sensitivity string          `json:"sensitivity"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Version     int             `json:"version"`
+	Alias       string          `json:"alias,omitempty"` // the alias this field was read through
+	Pinned      bool            `json:"pinned,omitempty"`
+	Note        string          `json:"note,omitempty"`      // the owner's note on why the field exists or how to use it
+	Type        string          `json:"type,omitempty"`      // how the value is best shown: json, pem, url, or number; text if empty
+	Entries     []ValueEntry    `json:"entries,omitempty"`   // every value of a multi-valued field
+	Signature   *ValueSignature `json:"signature,omitempty"` // when the read asked for one
 }
 
 // ContextBundle is a full decrypted dump grouped by category.
 type ContextBundle struct {
 	Categories map[string][]FieldInfo `json:"categories"`
+	// Order lists the field IDs in the order a context template gives them.
+	Order []string `json:"order,omitempty"`
+	// Links says which of the bundle's fields go together.
+	Links []FieldLink `json:"links,omitempty"`
+}
+
+// ScopeField is a field a scope would grant, as shown before a grant is confirmed.
+type ScopeField struct {
+	ID          string `json:"id"`
+	Sensitivity string `json:"sensitivity"`
+	Stored      bool   `json:"stored"` // false for schema fields not yet in the vault
 }
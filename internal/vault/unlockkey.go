@@ -0,0 +1,72 @@
+package vault
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/crypto"
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// unlockKeyLen is the size of the vault key at the front of an unlock key;
+// the secret key follows it.
+const unlockKeyLen = 32
+
+var ErrBadUnlockKey = errors.New("unlock key is malformed")
+
+// ExportUnlockKey checks the credentials and returns an unlock key: the
+// derived vault key followed by the secret key. It opens the vault without
+// the password or the Argon2 work, so it must only ever be stored wrapped,
+// e.g. by an external KMS. The secret key rides along so step-up elevation
+// still works on the sessions it opens.
+func (v *Vault) ExportUnlockKey(password, secretKeyHex string) ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	salt, err := v.loadSalt()
+	if err != nil {
+		return nil, err
+	}
+	sk, err := hex.DecodeString(strings.TrimSpace(secretKeyHex))
+	if err != nil {
+		return nil, fmt.Errorf("decode secret key: %w", err)
+	}
+	if err := v.checkSecretKeyHash(sk); err != nil {
+		return nil, err
+	}
+	vaultKey := crypto.DeriveVaultKey([]byte(password), sk, salt)
+	defer clear(vaultKey)
+	if err := v.checkVaultKey(vaultKey); err != nil {
+		return nil, err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "export_unlock_key"})
+	return append(append([]byte(nil), vaultKey...), sk...), nil
+}
+
+// UnlockWithKey opens a session with an unlock key from ExportUnlockKey,
+// as UnlockSession does with the password and secret key.
+func (v *Vault) UnlockWithKey(key []byte, label string) (string, error) {
+	if len(key) <= unlockKeyLen {
+		return "", ErrBadUnlockKey
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	salt, err := v.loadSalt()
+	if err != nil {
+		return "", err
+	}
+	vaultKey := append([]byte(nil), key[:unlockKeyLen]...)
+	sk := append([]byte(nil), key[unlockKeyLen:]...)
+	if err := v.checkSecretKeyHash(sk); err != nil {
+		clear(vaultKey)
+		return "", err
+	}
+	if err := v.checkVaultKey(vaultKey); err != nil {
+		clear(vaultKey)
+		return "", err
+	}
+	return v.openSession(vaultKey, salt, sk, label)
+}
@@ -0,0 +1,167 @@
+package vault
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// Value types, which say how a field's value is best shown. A field without
+// one is text.
+const (
+	TypeText   = "text"
+	TypeJSON   = "json"
+	TypePEM    = "pem"
+	TypeURL    = "url"
+	TypeNumber = "number"
+)
+
+// ValueTypes lists the value types.
+var ValueTypes = []string{TypeText, TypeJSON, TypePEM, TypeURL, TypeNumber}
+
+var (
+	ErrInvalidValueType  = errors.New("invalid value type: must be text, json, pem, url, or number")
+	ErrValueTypeMissing  = errors.New("only a stored field can have a value type")
+	ErrValueTypeMismatch = errors.New("the field's value isn't of that type")
+)
+
+const (
+	// typesMetaKey holds the fields' value types, encrypted like the other
+	// tables that name fields.
+	typesMetaKey = "field_types"
+
+	// typeKeyInfo is the HKDF info for the value type table key.
+	typeKeyInfo = ":types"
+)
+
+// SetValueType sets a stored field's value type, checking that its value
+// is of that type. Text, or an empty type, clears it. An alias's type is
+// its target's.
+func (v *Vault) SetValueType(id, typ string) error {
+	if err := ValidateFieldID(id); err != nil {
+		return err
+	}
+	if typ == TypeText {
+		typ = ""
+	}
+	if typ != "" && !slices.Contains(ValueTypes, typ) {
+		return ErrInvalidValueType
+	}
+	id = v.ResolveAlias(id)
+	v.types.writeMu.Lock()
+	defer v.types.writeMu.Unlock()
+	m, err := v.types.load()
+	if err != nil {
+		return err
+	}
+	if m[id] == typ {
+		return nil
+	}
+	if typ != "" {
+		f, err := v.Get(id)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return ErrValueTypeMissing
+		}
+		if !IsValueOfType(typ, f.Value) {
+			return ErrValueTypeMismatch
+		}
+	}
+	next := maps.Clone(m)
+	if typ == "" {
+		delete(next, id)
+	} else {
+		next[id] = typ
+	}
+	if err := v.types.save(next); err != nil {
+		return err
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "type", Purpose: typ})
+	return nil
+}
+
+// checkValueType returns the error SetValueType would give for setting typ
+// on a field storing value, checked before the value is written.
+func checkValueType(typ, value string) error {
+	if typ != TypeText && !slices.Contains(ValueTypes, typ) {
+		return ErrInvalidValueType
+	}
+	var f FieldInfo
+	f.setValue(value)
+	if !IsValueOfType(typ, f.Value) {
+		return ErrValueTypeMismatch
+	}
+	return nil
+}
+
+// IsValueOfType reports whether value parses as typ. Any value is text.
+func IsValueOfType(typ, value string) bool {
+	value = strings.TrimSpace(value)
+	switch typ {
+	case TypeJSON:
+		return json.Valid([]byte(value))
+	case TypePEM:
+		block, _ := pem.Decode([]byte(value))
+		return block != nil
+	case TypeURL:
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+	case TypeNumber:
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	}
+	return true
+}
+
+// MaskedByDefault reports whether f's value is masked on screen until the
+// owner asks to see it: critical values, and PEM blocks, which are usually
+// private keys.
+func MaskedByDefault(f FieldInfo) bool {
+	return f.Sensitivity == "critical" || f.Type == TypePEM
+}
+
+// valueType returns id's value type, or "" if the type table can't be
+// read: a type never keeps a value from being returned.
+func (v *Vault) valueType(id string) string {
+	m, err := v.types.load()
+	if err != nil {
+		return ""
+	}
+	return m[id]
+}
+
+// addTypes fills in the value types of fields, as valueType does.
+func (v *Vault) addTypes(fields []FieldInfo) {
+	m, err := v.types.load()
+	if err != nil || len(m) == 0 {
+		return
+	}
+	for i := range fields {
+		fields[i].Type = m[fields[i].ID]
+	}
+}
+
+// forgetType drops id's value type once its field is deleted.
+func (v *Vault) forgetType(id string) {
+	v.types.writeMu.Lock()
+	defer v.types.writeMu.Unlock()
+	m, err := v.types.load()
+	if err != nil {
+		return
+	}
+	if _, ok := m[id]; !ok {
+		return
+	}
+	next := maps.Clone(m)
+	delete(next, id)
+	v.types.save(next)
+}
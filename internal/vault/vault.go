@@ -6,25 +6,31 @@ import (
 	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lovincyrus/personal-vault/internal/crypto"
+	"github.com/lovincyrus/personal-vault/internal/i18n"
 	"github.com/lovincyrus/personal-vault/internal/store"
 )
 
 var (
-	ErrLocked         = errors.New("vault is locked")
-	ErrAlreadyUnlocked = errors.New("vault is already unlocked")
-	ErrNotInitialized = errors.New("vault is not initialized")
-	ErrAlreadyInit    = errors.New("vault is already initialized")
-	ErrWrongPassword  = errors.New("wrong password or secret key")
-	ErrInvalidTier    = errors.New("invalid sensitivity tier: must be public, standard, sensitive, or critical")
+	ErrLocked          = errors.New("vault is locked")
+	ErrSessionNotFound = errors.New("no such session")
+	ErrNotInitialized  = errors.New("vault is not initialized")
+	ErrAlreadyInit     = errors.New("vault is already initialized")
+	ErrWrongPassword   = errors.New("wrong password or secret key")
+	ErrWrongSecretKey  = errors.New("secret key does not belong to this vault")
+	ErrInvalidTier     = errors.New("invalid sensitivity tier: must be public, standard, sensitive, or critical")
 )
 
 var validTiers = map[string]bool{
@@ -33,40 +39,205 @@ var validTiers = map[string]bool{
 
 // Vault is the main entry point for vault operations.
 type Vault struct {
-	mu      sync.RWMutex
-	db      *store.DB
-	session *Session
-	dir     string // ~/.pvault
-	salt    []byte // loaded on unlock, used for HKDF subkey derivation
+	mu        sync.RWMutex
+	db        store.Store
+	sessions  map[string]*Session // by session ID; unlocked while non-empty
+	dir       string              // ~/.pvault
+	salt      []byte              // loaded on unlock, used for HKDF subkey derivation
+	secretKey []byte              // held while unlocked so Elevate needs only the password
+	autoLock  time.Duration       // idle timeout for new sessions; zero means the default
+	gen       atomic.Uint64
+
+	sugMu        sync.Mutex // guards the enricher and pending suggestions
+	enricher     AddressEnricher
+	enrichSource string
+	suggestions  []ValueSuggestion
+
+	// Side tables, each kept encrypted under its own meta key and, unless
+	// marked uncached, cached while unlocked; metaTables lists them all for
+	// seal.
+	aliases    *metaMap[string]          // alias -> target field
+	canaries   *metaMap[Canary]          // by field ID
+	acls       *metaMap[FieldACL]        // by field ID
+	consumers  *metaMap[Consumer]        // by consumer name
+	templates  *metaMap[ContextTemplate] // by consumer name
+	pins       *metaMap[time.Time]       // field ID -> when pinned
+	notes      *metaMap[string]          // by field ID
+	types      *metaMap[string]          // field ID -> value type
+	links      *metaMap[FieldLink]       // by FieldLink.key
+	appendOnly *metaMap[time.Time]       // category -> since when
+	snapshots  *metaMap[Snapshot]        // by ID; uncached
+	notified   *metaMap[string]          // field ID -> expiry date announced; uncached
+	metaTables []metaTable
+
+	expiryMu sync.Mutex // serializes expiry checks
+
+	sealHooks []func() // run by seal; set with OnSeal under mu
+
+	notifyMu    sync.Mutex // guards the notifier
+	notifier    Notifier
+	authMu      sync.Mutex // guards the authorizer and its settings
+	authorizer  Authorizer
+	authMinTier string
+	authTimeout time.Duration
+	authAskNew  bool
+	pluginMu    sync.Mutex // guards the hook plugins
+	plugins     []Plugin
+	emergencyMu sync.Mutex // serializes emergency contact updates
+
+	replicaMu     sync.Mutex // guards the primary and sync status
+	primary       *Primary   // set on a read replica
+	replicaStatus ReplicaStatus
 }
 
-// Open opens an existing vault database.
+const (
+	dbFile          = "vault.db"
+	encryptedDBFile = "vault.db.enc"
+
+	// dbKeyInfo is the HKDF info for the full-database key. The colon keeps it
+	// disjoint from category names, which can't contain one.
+	dbKeyInfo = ":database"
+)
+
+// Open opens an existing vault database. A vault created with
+// EncryptDatabase is detected by its file name and opened sealed until Unlock.
 func Open(dir string) (*Vault, error) {
-	dbPath := filepath.Join(dir, "vault.db")
+	encPath := filepath.Join(dir, encryptedDBFile)
+	if _, err := os.Stat(encPath); err == nil {
+		db, err := store.OpenEncrypted(encPath)
+		if err != nil {
+			return nil, fmt.Errorf("open database: %w", err)
+		}
+		return OpenStore(dir, db), nil
+	}
+
+	dbPath := filepath.Join(dir, dbFile)
 	db, err := store.Open(dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
-	return &Vault{db: db, dir: dir}, nil
+	return OpenStore(dir, db), nil
+}
+
+// OpenStore wraps an already-open store, e.g. store.NewMemory() in tests.
+// dir is only used for files kept outside the store.
+func OpenStore(dir string, db store.Store) *Vault {
+	// Until unlocked, entries are written without what they would encrypt.
+	if ae := auditEncrypter(db); ae != nil {
+		ae.SetAuditKey(nil)
+	}
+	if mode, _ := db.GetMeta("blind_index"); mode == "1" {
+		db = newBlindStore(db)
+	}
+	v := &Vault{db: db, dir: dir}
+	v.aliases = newMetaMap[string](v, aliasesMetaKey, aliasKeyInfo, "aliases")
+	v.canaries = newMetaMap[Canary](v, canariesMetaKey, canaryKeyInfo, "canaries")
+	v.acls = newMetaMap[FieldACL](v, aclsMetaKey, aclKeyInfo, "access lists")
+	v.consumers = newMetaMap[Consumer](v, consumersMetaKey, consumerKeyInfo, "consumers")
+	v.templates = newMetaMap[ContextTemplate](v, templatesMetaKey, templateKeyInfo, "context templates")
+	v.pins = newMetaMap[time.Time](v, pinsMetaKey, pinKeyInfo, "pins")
+	v.notes = newMetaMap[string](v, notesMetaKey, noteKeyInfo, "notes")
+	v.types = newMetaMap[string](v, typesMetaKey, typeKeyInfo, "value types")
+	v.links = newMetaMap[FieldLink](v, linksMetaKey, linkKeyInfo, "links")
+	v.links.encode = func(m map[string]FieldLink) any { return sortedLinks(m) }
+	v.links.decode = decodeLinks
+	v.appendOnly = newMetaMap[time.Time](v, appendOnlyMetaKey, appendOnlyKeyInfo, "append-only categories")
+	v.snapshots = newMetaMap[Snapshot](v, snapshotsMetaKey, snapshotKeyInfo, "snapshots")
+	v.snapshots.uncached = true
+	v.notified = newMetaMap[string](v, expiryNoticesMetaKey, expiryKeyInfo, "expiry notices")
+	v.notified.uncached = true
+	return v
+}
+
+// InitOptions selects how much metadata a new vault hides on disk.
+// By default only field values are encrypted.
+type InitOptions struct {
+	// EncryptDatabase encrypts the entire database at rest, so field IDs,
+	// categories, tokens, and the audit log are not readable on disk.
+	EncryptDatabase bool
+	// BlindIndex keeps SQLite but stores field IDs and categories as HMAC
+	// blind indexes and encrypts field names and audit scopes.
+	BlindIndex bool
+	// EncryptAudit encrypts the scope, purpose, and field IDs of audit
+	// entries with a key of their own.
+	EncryptAudit bool
+	// NoSecretKeyFile skips writing secret.key, for callers that store the
+	// key themselves (see internal/keystore).
+	NoSecretKeyFile bool
 }
 
 // Init creates a new vault: generates salt, secret key, and stores verification ciphertext.
 func Init(dir, password string) (secretKey string, err error) {
+	return InitWithOptions(dir, password, InitOptions{})
+}
+
+// InitWithOptions is Init with control over at-rest metadata protection.
+func InitWithOptions(dir, password string, opts InitOptions) (secretKey string, err error) {
+	if opts.EncryptDatabase && opts.BlindIndex {
+		return "", errors.New("blind index is redundant with full-database encryption: choose one")
+	}
+	if opts.EncryptDatabase && opts.EncryptAudit {
+		return "", errors.New("audit encryption is redundant with full-database encryption: choose one")
+	}
+
 	// Create directory
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return "", fmt.Errorf("create vault dir: %w", err)
 	}
-	dbPath := filepath.Join(dir, "vault.db")
-	if _, err := os.Stat(dbPath); err == nil {
-		return "", ErrAlreadyInit
+	for _, name := range []string{dbFile, encryptedDBFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return "", ErrAlreadyInit
+		}
 	}
 
-	db, err := store.Open(dbPath)
+	var db store.Store
+	if opts.EncryptDatabase {
+		db, err = store.OpenEncrypted(filepath.Join(dir, encryptedDBFile))
+	} else {
+		db, err = store.Open(filepath.Join(dir, dbFile))
+	}
 	if err != nil {
 		return "", fmt.Errorf("create database: %w", err)
 	}
 	defer db.Close()
 
+	skHex, err := InitStore(db, password)
+	if err != nil {
+		return "", err
+	}
+	if opts.BlindIndex {
+		if err := db.SetMeta("blind_index", "1"); err != nil {
+			return "", err
+		}
+	}
+	if opts.EncryptAudit {
+		if err := db.SetMeta(auditEncryptionMeta, "1"); err != nil {
+			return "", err
+		}
+	}
+
+	if opts.NoSecretKeyFile {
+		return skHex, nil
+	}
+
+	// Write secret key file
+	skPath := filepath.Join(dir, "secret.key")
+	if err := os.WriteFile(skPath, []byte(skHex+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("write secret key: %w", err)
+	}
+
+	return skHex, nil
+}
+
+// InitStore initializes vault metadata in an empty store and returns the
+// hex-encoded secret key. Unlike Init it writes no files.
+func InitStore(db store.Store, password string) (secretKey string, err error) {
+	if init, err := db.IsInitialized(); err != nil {
+		return "", err
+	} else if init {
+		return "", ErrAlreadyInit
+	}
+
 	// Generate salt
 	salt, err := crypto.GenerateSalt()
 	if err != nil {
@@ -98,93 +269,181 @@ func Init(dir, password string) (secretKey string, err error) {
 		return "", err
 	}
 
-	// Write secret key file
-	skPath := filepath.Join(dir, "secret.key")
-	skHex := hex.EncodeToString(sk)
-	if err := os.WriteFile(skPath, []byte(skHex+"\n"), 0600); err != nil {
-		return "", fmt.Errorf("write secret key: %w", err)
-	}
-
 	// Zero vault key
 	for i := range vaultKey {
 		vaultKey[i] = 0
 	}
 
-	return skHex, nil
+	return hex.EncodeToString(sk), nil
+}
+
+// CheckSecretKey reports whether secretKeyHex is this vault's secret key,
+// without the password; it can't unlock anything. It returns
+// ErrWrongSecretKey if not.
+func (v *Vault) CheckSecretKey(secretKeyHex string) error {
+	sk, err := hex.DecodeString(strings.TrimSpace(secretKeyHex))
+	if err != nil {
+		return ErrWrongSecretKey
+	}
+	storedHash, err := v.db.GetMeta("secret_key_hash")
+	if err != nil {
+		return err
+	}
+	if storedHash == "" {
+		return ErrNotInitialized
+	}
+	actualHash := hex.EncodeToString(crypto.HashSecretKey(sk))
+	if subtle.ConstantTimeCompare([]byte(storedHash), []byte(actualHash)) != 1 {
+		return ErrWrongSecretKey
+	}
+	return nil
 }
 
 // Unlock derives the vault key and creates a session.
 func (v *Vault) Unlock(password string, secretKeyHex string) (token string, err error) {
+	return v.UnlockSession(password, secretKeyHex, "")
+}
+
+// UnlockSession checks the credentials and opens a session labeled for
+// whoever asked. If the vault is already unlocked, the new session joins the
+// others: each has its own token, idle timer, and revocation.
+func (v *Vault) UnlockSession(password, secretKeyHex, label string) (string, error) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	if v.session != nil {
-		return "", ErrAlreadyUnlocked
+	salt, err := v.loadSalt()
+	if err != nil {
+		return "", err
 	}
 
-	init, err := v.db.IsInitialized()
+	// Decode secret key
+	sk, err := hex.DecodeString(strings.TrimSpace(secretKeyHex))
 	if err != nil {
+		return "", fmt.Errorf("decode secret key: %w", err)
+	}
+	if err := v.checkSecretKeyHash(sk); err != nil {
 		return "", err
 	}
-	if !init {
-		return "", ErrNotInitialized
+
+	// Derive vault key
+	vaultKey := crypto.DeriveVaultKey([]byte(password), sk, salt)
+	if err := v.checkVaultKey(vaultKey); err != nil {
+		return "", err
 	}
+	return v.openSession(vaultKey, salt, sk, label)
+}
 
-	// Load salt
-	saltB64, err := v.db.GetMeta("salt")
+// loadSalt returns the vault's KDF salt, failing if the vault isn't
+// initialized.
+func (v *Vault) loadSalt() ([]byte, error) {
+	init, err := v.db.IsInitialized()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	salt, err := base64.StdEncoding.DecodeString(saltB64)
+	if !init {
+		return nil, ErrNotInitialized
+	}
+	saltB64, err := v.db.GetMeta("salt")
 	if err != nil {
-		return "", fmt.Errorf("decode salt: %w", err)
+		return nil, err
 	}
-
-	// Decode secret key
-	sk, err := hex.DecodeString(strings.TrimSpace(secretKeyHex))
+	salt, err := base64.StdEncoding.DecodeString(saltB64)
 	if err != nil {
-		return "", fmt.Errorf("decode secret key: %w", err)
+		return nil, fmt.Errorf("decode salt: %w", err)
 	}
+	return salt, nil
+}
 
-	// Verify secret key hash
+// checkSecretKeyHash reports ErrWrongPassword unless sk is the vault's
+// secret key.
+func (v *Vault) checkSecretKeyHash(sk []byte) error {
 	storedHash, err := v.db.GetMeta("secret_key_hash")
 	if err != nil {
-		return "", err
+		return err
 	}
 	actualHash := hex.EncodeToString(crypto.HashSecretKey(sk))
 	if subtle.ConstantTimeCompare([]byte(storedHash), []byte(actualHash)) != 1 {
-		return "", ErrWrongPassword
+		return ErrWrongPassword
 	}
+	return nil
+}
 
-	// Derive vault key
-	vaultKey := crypto.DeriveVaultKey([]byte(password), sk, salt)
-
-	// Verify with stored ciphertext
+// checkVaultKey reports ErrWrongPassword unless vaultKey decrypts the stored
+// verification ciphertext.
+func (v *Vault) checkVaultKey(vaultKey []byte) error {
 	verifyCipher, err := v.db.GetMeta("verification")
 	if err != nil {
-		return "", err
+		return err
 	}
 	plaintext, err := crypto.DecryptFromBase64(vaultKey, verifyCipher)
 	if err != nil {
-		return "", ErrWrongPassword
+		return ErrWrongPassword
 	}
 	if string(plaintext) != "personal-vault-verification" {
-		return "", ErrWrongPassword
+		return ErrWrongPassword
 	}
+	return nil
+}
+
+// openSession opens a session on a checked vault key. The caller holds v.mu.
+func (v *Vault) openSession(vaultKey, salt, sk []byte, label string) (string, error) {
+	// The first session opens the store; later ones share it.
+	first := len(v.sessions) == 0
+	if first {
+		// Whole-database encryption: open the store with a key derived from the vault key
+		if sealer, ok := v.db.(store.Sealer); ok {
+			dbKey, err := crypto.DeriveSubkey(vaultKey, salt, dbKeyInfo)
+			if err != nil {
+				return "", err
+			}
+			err = sealer.Unseal(dbKey)
+			clear(dbKey)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if bs, ok := v.db.(*blindStore); ok {
+			if err := bs.setKeys(vaultKey, salt); err != nil {
+				v.seal()
+				return "", err
+			}
+		}
+		if err := v.unlockAudit(vaultKey, salt); err != nil {
+			v.seal()
+			return "", err
+		}
 
-	// Store salt for HKDF subkey derivation
-	v.salt = salt
+		// Store salt for HKDF subkey derivation
+		v.salt = salt
+		v.secretKey = sk
+		lockMemory(v.secretKey)
+	}
 
 	// Create session
+	var session *Session
 	session, err := NewSession(vaultKey, func() {
 		v.mu.Lock()
-		v.session = nil
-		v.mu.Unlock()
+		defer v.mu.Unlock()
+		v.dropSession(session)
 	})
 	if err != nil {
+		if first {
+			v.seal()
+		}
 		return "", err
 	}
-	v.session = session
+	session.label = label
+	if v.autoLock > 0 {
+		session.setTTL(v.autoLock)
+	}
+	if v.sessions == nil {
+		v.sessions = make(map[string]*Session)
+	}
+	v.sessions[session.id] = session
+	if first {
+		v.gen.Add(1)
+	}
 
 	// Zero local copy of vault key
 	for i := range vaultKey {
@@ -192,22 +451,128 @@ func (v *Vault) Unlock(password string, secretKeyHex string) (token string, err
 	}
 
 	// Log access
-	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "unlock"})
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "unlock", Purpose: sessionPurpose(session)})
 
 	return session.Token(), nil
 }
 
-// Lock destroys the session and zeroes the vault key.
+// SetAutoLock sets how long new sessions may sit idle before they lock.
+// Sessions already open keep their timeout.
+func (v *Vault) SetAutoLock(d time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.autoLock = d
+}
+
+// sessionPurpose identifies a session in the audit log.
+func sessionPurpose(s *Session) string {
+	if s.label == "" {
+		return "session: " + s.id
+	}
+	return "session: " + s.id + " (" + s.label + ")"
+}
+
+// Lock ends every session and zeroes the vault key.
 func (v *Vault) Lock() {
 	v.mu.Lock()
 	defer v.mu.Unlock()
-	if v.session != nil {
+	if len(v.sessions) > 0 {
 		v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "lock"})
-		v.session.Destroy()
-		v.session = nil
+		for _, s := range v.sessions {
+			s.Destroy()
+		}
+		clear(v.sessions)
+		v.seal()
+		v.gen.Add(1)
 	}
 }
 
+// dropSession forgets a session that has ended, locking the vault if it was
+// the last. Callers hold v.mu for writing.
+func (v *Vault) dropSession(s *Session) {
+	if v.sessions[s.id] != s {
+		return // already gone, e.g. Lock raced the auto-lock timer
+	}
+	delete(v.sessions, s.id)
+	if len(v.sessions) == 0 {
+		v.seal()
+		v.gen.Add(1)
+	}
+}
+
+// Sessions lists the live sessions, oldest first.
+func (v *Vault) Sessions() []SessionInfo {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	sessions := slices.SortedFunc(maps.Values(v.sessions), func(a, b *Session) int {
+		return a.created.Compare(b.created)
+	})
+	infos := make([]SessionInfo, len(sessions))
+	for i, s := range sessions {
+		infos[i] = s.Info()
+	}
+	return infos
+}
+
+// RevokeSession ends one session, invalidating its token. Revoking the last
+// session locks the vault.
+func (v *Vault) RevokeSession(id string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.sessions[id]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "revoke_session", Purpose: sessionPurpose(s)})
+	s.Destroy()
+	v.dropSession(s)
+	return nil
+}
+
+// seal discards decrypted database state, blind-index and audit keys, and
+// anything cached from them, here or by an OnSeal hook.
+func (v *Vault) seal() {
+	unlockMemory(v.secretKey)
+	clear(v.secretKey)
+	v.secretKey = nil
+
+	v.sugMu.Lock()
+	v.suggestions = nil
+	v.sugMu.Unlock()
+	for _, t := range v.metaTables {
+		t.reset()
+	}
+
+	v.lockAudit()
+	db := v.db
+	if bs, ok := db.(*blindStore); ok {
+		bs.zero()
+		db = bs.Store
+	}
+	if sealer, ok := db.(store.Sealer); ok {
+		sealer.Seal()
+	}
+	for _, f := range v.sealHooks {
+		f()
+	}
+}
+
+// OnSeal has f called whenever the vault locks, however it locks: Lock, the
+// last session ending or being revoked, or auto-lock. Callers holding
+// plaintext derived from the vault use it to drop that plaintext. f runs
+// with the vault's lock held, so it must not call back into the vault.
+func (v *Vault) OnSeal(f func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.sealHooks = append(v.sealHooks, f)
+}
+
+// CheckStorage reports whether the database still accepts writes. It changes
+// nothing and works while locked.
+func (v *Vault) CheckStorage() error {
+	return v.db.CheckWritable()
+}
+
 // Status returns the current vault status.
 func (v *Vault) Status() (*VaultStatus, error) {
 	init, err := v.db.IsInitialized()
@@ -221,9 +586,7 @@ func (v *Vault) Status() (*VaultStatus, error) {
 	}
 
 	v.mu.RLock()
-	if v.session != nil {
-		status.Locked = false
-	}
+	status.Locked = len(v.sessions) == 0
 	v.mu.RUnlock()
 
 	if init {
@@ -232,41 +595,91 @@ func (v *Vault) Status() (*VaultStatus, error) {
 		cats, _ := v.db.CategoryCounts()
 		status.Categories = cats
 	}
+	if !status.Locked {
+		status.Language = v.Language()
+	}
 
 	return status, nil
 }
 
-// Set encrypts and stores a field value.
+// Language returns the supported language named by preferences.language, or
+// "" if it is unset, unsupported, or marked above public. The field is read
+// without an audit entry: only public values are disclosed, like the rest of
+// the status.
+func (v *Vault) Language() string {
+	f, err := v.db.GetField("preferences.language")
+	if err != nil || f == nil || f.Sensitivity != "public" {
+		return ""
+	}
+	subkey, err := v.subkey(f.Category)
+	if err != nil {
+		return ""
+	}
+	plaintext, err := crypto.DecryptFromBase64(subkey, f.Value)
+	if err != nil {
+		return ""
+	}
+	return i18n.Match(string(plaintext))
+}
+
+// SetOptions adjust how SetWithOptions stores a value.
+type SetOptions struct {
+	Sensitivity string
+	Raw         bool   // store the value exactly as given, skipping Normalize
+	Type        string // value type to set with the value; empty leaves it as it is
+}
+
+// Set normalizes, encrypts, and stores a field value.
 func (v *Vault) Set(id, value, sensitivity string) error {
+	_, err := v.SetWithOptions(id, value, SetOptions{Sensitivity: sensitivity})
+	return err
+}
+
+// SetWithOptions encrypts and stores a field value and returns the value as
+// stored. Unless opts.Raw is set the value is normalized first; if that
+// changes it, the value as entered is kept in the field's history.
+func (v *Vault) SetWithOptions(id, value string, opts SetOptions) (string, error) {
 	if err := ValidateFieldID(id); err != nil {
-		return err
+		return "", err
 	}
-
-	vaultKey, err := v.requireUnlocked()
-	if err != nil {
-		return err
+	id = v.ResolveAlias(id)
+	original := value
+	if !opts.Raw {
+		value = Normalize(id, value)
 	}
+	sensitivity := opts.Sensitivity
 
 	parts := strings.SplitN(id, ".", 2)
 	category, fieldName := parts[0], parts[1]
 
-	// Derive category subkey
-	subkey, err := crypto.DeriveSubkey(vaultKey, v.salt, category)
+	subkey, err := v.subkey(category)
 	if err != nil {
-		return fmt.Errorf("derive subkey: %w", err)
+		return "", err
+	}
+	if err := v.ensureKCV(category, subkey); err != nil {
+		return "", err
 	}
 
 	// Encrypt
 	encrypted, err := crypto.EncryptToBase64(subkey, []byte(value))
 	if err != nil {
-		return fmt.Errorf("encrypt: %w", err)
+		return "", fmt.Errorf("encrypt: %w", err)
 	}
 
 	if sensitivity == "" {
 		sensitivity = "standard"
 	}
 	if !validTiers[sensitivity] {
-		return ErrInvalidTier
+		return "", ErrInvalidTier
+	}
+	if err := v.validateWrite([]PluginField{{ID: id, Value: value, Sensitivity: sensitivity}}); err != nil {
+		return "", err
+	}
+	// Check the type before writing, so a value isn't stored without it.
+	if opts.Type != "" {
+		if err := checkValueType(opts.Type, value); err != nil {
+			return "", err
+		}
 	}
 
 	err = v.db.SetField(store.Field{
@@ -278,19 +691,84 @@ func (v *Vault) Set(id, value, sensitivity string) error {
 		UpdatedAt:   time.Now(),
 	})
 	if err != nil {
-		return err
+		return "", err
+	}
+	v.gen.Add(1)
+
+	if value != original {
+		if err := v.keepOriginal(id, subkey, original); err != nil {
+			return "", err
+		}
+	}
+	if opts.Type != "" {
+		if err := v.SetValueType(id, opts.Type); err != nil {
+			return "", err
+		}
 	}
 
 	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "write"})
-	return nil
+	return value, nil
 }
 
-// Get decrypts and returns a field value.
-func (v *Vault) Get(id string) (*FieldInfo, error) {
-	vaultKey, err := v.requireUnlocked()
+// keepOriginal records the value as entered against the version just stored.
+func (v *Vault) keepOriginal(id string, subkey []byte, original string) error {
+	f, err := v.db.GetField(id)
+	if err != nil || f == nil {
+		return err
+	}
+	encrypted, err := crypto.EncryptToBase64(subkey, []byte(original))
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+	return v.db.AddFieldHistory(store.FieldHistory{
+		FieldID: id,
+		Version: f.Version,
+		Value:   encrypted,
+		Reason:  HistoryNormalized,
+	})
+}
+
+// History returns a field's history entries with decrypted values, newest
+// first.
+func (v *Vault) History(id string) ([]FieldRevision, error) {
+	if err := ValidateFieldID(id); err != nil {
+		return nil, err
+	}
+	id = v.ResolveAlias(id)
+	category, _, _ := strings.Cut(id, ".")
+	subkey, err := v.subkey(category)
 	if err != nil {
 		return nil, err
 	}
+	entries, err := v.db.GetFieldHistory(id)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]FieldRevision, 0, len(entries))
+	for _, h := range entries {
+		value, err := v.decryptValue(subkey, category, id, h.Value)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, FieldRevision{
+			Version:   h.Version,
+			Value:     value,
+			Reason:    h.Reason,
+			CreatedAt: h.CreatedAt,
+		})
+	}
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "history"})
+	return revisions, nil
+}
+
+// Get decrypts and returns a field value. An alias returns its target, with
+// the ID as requested in Alias; the audit entry names the target.
+func (v *Vault) Get(id string) (*FieldInfo, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	requested := id
+	id = v.ResolveAlias(id)
 
 	f, err := v.db.GetField(id)
 	if err != nil {
@@ -300,27 +778,46 @@ func (v *Vault) Get(id string) (*FieldInfo, error) {
 		return nil, nil
 	}
 
-	subkey, err := crypto.DeriveSubkey(vaultKey, v.salt, f.Category)
+	subkey, err := v.subkey(f.Category)
 	if err != nil {
 		return nil, err
 	}
 
-	plaintext, err := crypto.DecryptFromBase64(subkey, f.Value)
+	value, err := v.decryptValue(subkey, f.Category, id, f.Value)
 	if err != nil {
-		return nil, fmt.Errorf("decrypt field %s: %w", id, err)
+		return nil, err
 	}
 
 	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "read"})
 
-	return &FieldInfo{
+	info := &FieldInfo{
 		ID:          f.ID,
 		Category:    f.Category,
 		FieldName:   f.FieldName,
-		Value:       string(plaintext),
 		Sensitivity: f.Sensitivity,
 		UpdatedAt:   f.UpdatedAt,
 		Version:     f.Version,
-	}, nil
+		Note:        v.note(id),
+		Type:        v.valueType(id),
+	}
+	info.setValue(value)
+	if requested != id {
+		info.Alias = requested
+	}
+	return info, nil
+}
+
+// Sensitivity returns a field's tier without decrypting it, or "" if the
+// field doesn't exist.
+func (v *Vault) Sensitivity(id string) (string, error) {
+	if _, err := v.requireUnlocked(); err != nil {
+		return "", err
+	}
+	f, err := v.db.GetField(v.ResolveAlias(id))
+	if err != nil || f == nil {
+		return "", err
+	}
+	return f.Sensitivity, nil
 }
 
 // List returns all field metadata (no values).
@@ -333,9 +830,14 @@ func (v *Vault) List() ([]FieldInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	pins, err := v.pins.load()
+	if err != nil {
+		return nil, err
+	}
 
 	result := make([]FieldInfo, len(fields))
 	for i, f := range fields {
+		_, pinned := pins[f.ID]
 		result[i] = FieldInfo{
 			ID:          f.ID,
 			Category:    f.Category,
@@ -343,8 +845,11 @@ func (v *Vault) List() ([]FieldInfo, error) {
 			Sensitivity: f.Sensitivity,
 			UpdatedAt:   f.UpdatedAt,
 			Version:     f.Version,
+			Pinned:      pinned,
 		}
 	}
+	v.addNotes(result)
+	v.addTypes(result)
 	return result, nil
 }
 
@@ -370,13 +875,14 @@ func (v *Vault) ListByCategory(category string) ([]FieldInfo, error) {
 			Version:     f.Version,
 		}
 	}
+	v.addNotes(result)
+	v.addTypes(result)
 	return result, nil
 }
 
 // GetByCategory returns all decrypted fields for a category.
 func (v *Vault) GetByCategory(category string) ([]FieldInfo, error) {
-	vaultKey, err := v.requireUnlocked()
-	if err != nil {
+	if _, err := v.requireUnlocked(); err != nil {
 		return nil, err
 	}
 
@@ -385,27 +891,29 @@ func (v *Vault) GetByCategory(category string) ([]FieldInfo, error) {
 		return nil, err
 	}
 
-	subkey, err := crypto.DeriveSubkey(vaultKey, v.salt, category)
+	subkey, err := v.subkey(category)
 	if err != nil {
 		return nil, err
 	}
 
 	result := make([]FieldInfo, len(fields))
 	for i, f := range fields {
-		plaintext, err := crypto.DecryptFromBase64(subkey, f.Value)
+		value, err := v.decryptValue(subkey, category, f.ID, f.Value)
 		if err != nil {
-			return nil, fmt.Errorf("decrypt %s: %w", f.ID, err)
+			return nil, err
 		}
 		result[i] = FieldInfo{
 			ID:          f.ID,
 			Category:    f.Category,
 			FieldName:   f.FieldName,
-			Value:       string(plaintext),
 			Sensitivity: f.Sensitivity,
 			UpdatedAt:   f.UpdatedAt,
 			Version:     f.Version,
 		}
+		result[i].setValue(value)
 	}
+	v.addNotes(result)
+	v.addTypes(result)
 
 	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: category + ".*", Action: "read"})
 	return result, nil
@@ -413,8 +921,7 @@ func (v *Vault) GetByCategory(category string) ([]FieldInfo, error) {
 
 // GetContext returns all decrypted fields grouped by category.
 func (v *Vault) GetContext() (*ContextBundle, error) {
-	vaultKey, err := v.requireUnlocked()
-	if err != nil {
+	if _, err := v.requireUnlocked(); err != nil {
 		return nil, err
 	}
 
@@ -422,37 +929,51 @@ func (v *Vault) GetContext() (*ContextBundle, error) {
 	if err != nil {
 		return nil, err
 	}
+	bundle, err := v.decryptBundle(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "context"})
+	return bundle, nil
+}
 
+// decryptBundle decrypts fields into a bundle grouped by category.
+func (v *Vault) decryptBundle(fields []store.Field) (*ContextBundle, error) {
 	bundle := &ContextBundle{Categories: make(map[string][]FieldInfo)}
 	subkeys := make(map[string][]byte)
+	notes, _ := v.notes.load() // a missing note never keeps a value back
+	types, _ := v.types.load()
 
 	for _, f := range fields {
 		sk, ok := subkeys[f.Category]
 		if !ok {
-			sk, err = crypto.DeriveSubkey(vaultKey, v.salt, f.Category)
+			var err error
+			sk, err = v.subkey(f.Category)
 			if err != nil {
 				return nil, err
 			}
 			subkeys[f.Category] = sk
 		}
 
-		plaintext, err := crypto.DecryptFromBase64(sk, f.Value)
+		value, err := v.decryptValue(sk, f.Category, f.ID, f.Value)
 		if err != nil {
-			return nil, fmt.Errorf("decrypt %s: %w", f.ID, err)
+			return nil, err
 		}
 
-		bundle.Categories[f.Category] = append(bundle.Categories[f.Category], FieldInfo{
+		info := FieldInfo{
 			ID:          f.ID,
 			Category:    f.Category,
 			FieldName:   f.FieldName,
-			Value:       string(plaintext),
 			Sensitivity: f.Sensitivity,
 			UpdatedAt:   f.UpdatedAt,
 			Version:     f.Version,
-		})
+			Note:        notes[f.ID],
+			Type:        types[f.ID],
+		}
+		info.setValue(value)
+		bundle.Categories[f.Category] = append(bundle.Categories[f.Category], info)
 	}
-
-	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: "*", Action: "context"})
 	return bundle, nil
 }
 
@@ -461,10 +982,17 @@ func (v *Vault) Delete(id string) error {
 	if _, err := v.requireUnlocked(); err != nil {
 		return err
 	}
+	id = v.ResolveAlias(id)
 
 	if err := v.db.DeleteField(id); err != nil {
 		return err
 	}
+	v.gen.Add(1)
+	v.forgetCanary(id)
+	v.forgetPin(id)
+	v.forgetNote(id)
+	v.forgetType(id)
+	v.forgetLinks(id)
 
 	v.db.LogAccess(store.AuditEntry{Consumer: "vault", Scope: id, Action: "delete"})
 	return nil
@@ -478,7 +1006,12 @@ func (v *Vault) SetSensitivity(id, tier string) error {
 	if !validTiers[tier] {
 		return ErrInvalidTier
 	}
-	return v.db.SetSensitivity(id, tier)
+	id = v.ResolveAlias(id)
+	if err := v.db.SetSensitivity(id, tier); err != nil {
+		return err
+	}
+	v.gen.Add(1)
+	return nil
 }
 
 // AuditLog returns recent audit entries.
@@ -490,12 +1023,23 @@ func (v *Vault) AuditLog(limit int) ([]store.AuditEntry, error) {
 func (v *Vault) ValidateToken(token string) bool {
 	v.mu.RLock()
 	defer v.mu.RUnlock()
-	if v.session == nil {
-		return false
+	return v.sessionByToken(token) != nil
+}
+
+// sessionByToken returns the session a token belongs to, or nil. Callers
+// hold v.mu.
+func (v *Vault) sessionByToken(token string) *Session {
+	for _, s := range v.sessions {
+		if s.ValidateToken(token) {
+			return s
+		}
 	}
-	return v.session.ValidateToken(token)
+	return nil
 }
 
+// TokenUsageDiscovery marks a token that can only list field metadata.
+const TokenUsageDiscovery = "discovery"
+
 // hashServiceToken returns the hex-encoded SHA-256 hash of a token.
 func hashServiceToken(token string) string {
 	h := sha256.Sum256([]byte(token))
@@ -505,9 +1049,44 @@ func hashServiceToken(token string) string {
 // CreateServiceToken generates a long-lived service token for a consumer.
 // The raw token is returned to the caller; only the SHA-256 hash is stored.
 func (v *Vault) CreateServiceToken(consumer, scope string, ttl time.Duration) (string, error) {
+	return v.CreateRestrictedServiceToken(consumer, scope, ttl, TokenConstraints{}, TokenMetadata{})
+}
+
+// CreateRestrictedServiceToken is CreateServiceToken for a token that only
+// works within constraints, described by m.
+func (v *Vault) CreateRestrictedServiceToken(consumer, scope string, ttl time.Duration, c TokenConstraints, m TokenMetadata) (string, error) {
+	return v.createToken("service", consumer, scope, ttl, c, m)
+}
+
+// CreateDiscoveryToken generates a token that can list the names and tiers
+// of the fields in scope but read no values, so a new agent can see what the
+// vault holds before the owner grants it a service token.
+func (v *Vault) CreateDiscoveryToken(consumer, scope string, ttl time.Duration, c TokenConstraints, m TokenMetadata) (string, error) {
+	return v.createToken(TokenUsageDiscovery, consumer, scope, ttl, c, m)
+}
+
+func (v *Vault) createToken(usage, consumer, scope string, ttl time.Duration, c TokenConstraints, m TokenMetadata) (string, error) {
 	if _, err := v.requireUnlocked(); err != nil {
 		return "", err
 	}
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+	var constraints string
+	if !c.IsZero() {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return "", err
+		}
+		constraints = string(b)
+	}
+	if err := m.Validate(); err != nil {
+		return "", err
+	}
+	labels, err := encodeLabels(m.Labels)
+	if err != nil {
+		return "", err
+	}
 
 	tokenBytes := make([]byte, 32)
 	if _, err := crand.Read(tokenBytes); err != nil {
@@ -516,12 +1095,15 @@ func (v *Vault) CreateServiceToken(consumer, scope string, ttl time.Duration) (s
 	tokenStr := hex.EncodeToString(tokenBytes)
 
 	t := store.Token{
-		TokenStr:  hashServiceToken(tokenStr),
-		Consumer:  consumer,
-		Scope:     scope,
-		ExpiresAt: time.Now().Add(ttl),
-		Usage:     "service",
-		CreatedAt: time.Now(),
+		TokenStr:    hashServiceToken(tokenStr),
+		Consumer:    consumer,
+		Scope:       scope,
+		ExpiresAt:   time.Now().Add(ttl),
+		Usage:       usage,
+		CreatedAt:   time.Now(),
+		Constraints: constraints,
+		Description: m.Description,
+		Labels:      labels,
 	}
 	if err := v.db.CreateToken(t); err != nil {
 		return "", err
@@ -530,7 +1112,7 @@ func (v *Vault) CreateServiceToken(consumer, scope string, ttl time.Duration) (s
 	v.db.LogAccess(store.AuditEntry{
 		Consumer: "vault",
 		Scope:    scope,
-		Action:   "create_service_token",
+		Action:   "create_" + usage + "_token",
 		Purpose:  "consumer: " + consumer,
 	})
 
@@ -546,18 +1128,47 @@ func (v *Vault) ValidateServiceToken(token string) (*store.Token, bool) {
 	if t.Usage != "service" {
 		return nil, false
 	}
+	if v.rootToken(t) == nil {
+		// Delegated from a token that is gone.
+		return nil, false
+	}
+	return t, true
+}
+
+// ValidateDiscoveryToken checks a token made with CreateDiscoveryToken.
+func (v *Vault) ValidateDiscoveryToken(token string) (*store.Token, bool) {
+	t, err := v.db.GetToken(hashServiceToken(token))
+	if err != nil || t == nil || t.Usage != TokenUsageDiscovery {
+		return nil, false
+	}
 	return t, true
 }
 
-// ListServiceTokens returns all service tokens.
+// ListServiceTokens returns all service tokens, discovery tokens included,
+// newest first.
 func (v *Vault) ListServiceTokens() ([]store.Token, error) {
 	if _, err := v.requireUnlocked(); err != nil {
 		return nil, err
 	}
-	return v.db.ListTokensByUsage("service")
+	tokens, err := v.db.ListTokensByUsage("service")
+	if err != nil {
+		return nil, err
+	}
+	discovery, err := v.db.ListTokensByUsage(TokenUsageDiscovery)
+	if err != nil {
+		return nil, err
+	}
+	tokens = append(tokens, discovery...)
+	slices.SortStableFunc(tokens, func(a, b store.Token) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return tokens, nil
 }
 
-// RevokeServiceToken removes a service token by its hash.
+// minRevokePrefix is the shortest hash prefix RevokeServiceToken accepts, so
+// a typo can't match every token.
+const minRevokePrefix = 8
+
+// RevokeServiceToken removes a service token, given either the raw token or
+// a prefix of its hash as shown by ListServiceTokens.
 func (v *Vault) RevokeServiceToken(token string) (int64, error) {
 	if _, err := v.requireUnlocked(); err != nil {
 		return 0, err
@@ -566,6 +1177,12 @@ func (v *Vault) RevokeServiceToken(token string) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	prefix := strings.ToLower(strings.TrimSuffix(token, "..."))
+	if n == 0 && len(prefix) >= minRevokePrefix && strings.Trim(prefix, "0123456789abcdef") == "" {
+		if n, err = v.db.DeleteTokenByPrefix(prefix); err != nil {
+			return 0, err
+		}
+	}
 	if n > 0 {
 		v.db.LogAccess(store.AuditEntry{
 			Consumer: "vault",
@@ -576,15 +1193,94 @@ func (v *Vault) RevokeServiceToken(token string) (int64, error) {
 	return n, nil
 }
 
-// TouchSession resets the auto-lock timer.
-func (v *Vault) TouchSession() {
+// Generation returns a counter that advances on every field write and every
+// lock state change. Callers caching decrypted data compare it to detect staleness.
+func (v *Vault) Generation() uint64 {
+	return v.gen.Load()
+}
+
+// CheckUnlocked returns ErrLocked if the vault is locked.
+func (v *Vault) CheckUnlocked() error {
+	_, err := v.requireUnlocked()
+	return err
+}
+
+// TouchSession resets the auto-lock timer of the session token belongs to.
+// Vault operations don't touch any session themselves, since they can't tell
+// which one is asking.
+func (v *Vault) TouchSession(token string) {
 	v.mu.RLock()
 	defer v.mu.RUnlock()
-	if v.session != nil {
-		v.session.Touch()
+	if s := v.sessionByToken(token); s != nil {
+		s.Touch()
 	}
 }
 
+// TouchSessions resets every session's auto-lock timer, so that service
+// tokens in use keep the vault unlocked.
+func (v *Vault) TouchSessions() {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, s := range v.sessions {
+		s.Touch()
+	}
+}
+
+// PinSession exempts the session token belongs to from auto-lock, so that
+// an unattended server (serve --headless) stays unlocked for its service
+// tokens. Lock and RevokeSession still end it.
+func (v *Vault) PinSession(token string) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	s := v.sessionByToken(token)
+	if s == nil {
+		return ErrSessionNotFound
+	}
+	s.pin()
+	return nil
+}
+
+// BindSession ties the session token belongs to to the client that opened
+// it: from then on the token is only accepted from this machine and, unless
+// uid is negative, from that user, so a copied session file is no use
+// anywhere else.
+func (v *Vault) BindSession(token string, uid int) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	s := v.sessionByToken(token)
+	if s == nil {
+		return ErrSessionNotFound
+	}
+	s.mu.Lock()
+	s.bound, s.boundUID = true, uid
+	s.mu.Unlock()
+	return nil
+}
+
+// SessionAllows reports whether the session token belongs to accepts a
+// request from a client, given whether it is on this machine and the user
+// running it, negative if unknown. An unbound session accepts any.
+func (v *Vault) SessionAllows(token string, local bool, uid int) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	s := v.sessionByToken(token)
+	return s != nil && s.allows(local, uid)
+}
+
+// SessionInfo reports the idle and auto-lock timers of the session token
+// belongs to. It doesn't count as activity, so polling it can't keep the
+// vault unlocked.
+func (v *Vault) SessionInfo(token string) (*SessionInfo, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	s := v.sessionByToken(token)
+	if s == nil {
+		return nil, ErrSessionNotFound
+	}
+	info := s.Info()
+	return &info, nil
+}
+
 // Close closes the database.
 func (v *Vault) Close() error {
 	v.Lock()
@@ -596,16 +1292,30 @@ func (v *Vault) LogAccess(entry store.AuditEntry) {
 	v.db.LogAccess(entry)
 }
 
-func (v *Vault) requireUnlocked() ([]byte, error) {
+// subkey returns the session's cached subkey for a category.
+func (v *Vault) subkey(category string) ([]byte, error) {
 	v.mu.RLock()
 	defer v.mu.RUnlock()
-	if v.session == nil {
-		return nil, ErrLocked
+	// Every session holds the same key; skip any that has just expired.
+	for _, s := range v.sessions {
+		sk, err := s.Subkey(v.salt, category)
+		if err != nil {
+			return nil, fmt.Errorf("derive subkey: %w", err)
+		}
+		if sk != nil {
+			return sk, nil
+		}
 	}
-	v.session.Touch()
-	key := v.session.VaultKey()
-	if key == nil {
-		return nil, ErrLocked
+	return nil, ErrLocked
+}
+
+func (v *Vault) requireUnlocked() ([]byte, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, s := range v.sessions {
+		if key := s.VaultKey(); key != nil {
+			return key, nil
+		}
 	}
-	return key, nil
+	return nil, ErrLocked
 }
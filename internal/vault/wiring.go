@@ -0,0 +1,203 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lovincyrus/personal-vault/internal/store"
+)
+
+// wiringVersion is the format version of Wiring, bumped on incompatible
+// changes.
+const wiringVersion = 1
+
+var ErrWiringVersion = errors.New("unsupported wiring version")
+
+// Wiring is how a vault is set up for its consumers, apart from the data in
+// it: who is trusted with what, which fields they may read, what they are
+// given, and which tokens they hold. It carries no field values and no
+// token secrets, so a re-provisioned machine can restore it next to a
+// restored or re-imported vault.
+type Wiring struct {
+	Version    int               `json:"version"`
+	Consumers  []Consumer        `json:"consumers,omitempty"`
+	ACLs       []FieldACL        `json:"acls,omitempty"`
+	Templates  []ContextTemplate `json:"templates,omitempty"`
+	Aliases    []FieldAlias      `json:"aliases,omitempty"`
+	AppendOnly []string          `json:"append_only,omitempty"`
+	Tokens     []WiringToken     `json:"tokens,omitempty"`
+}
+
+// WiringToken describes a service or discovery token without its secret.
+// Importing it issues a new token like it.
+type WiringToken struct {
+	Prefix      string            `json:"token_prefix"`
+	Consumer    string            `json:"consumer"`
+	Scope       string            `json:"scope"`
+	Usage       string            `json:"usage"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	Constraints *TokenConstraints `json:"constraints,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// WiringResult is what ImportWiring applied.
+type WiringResult struct {
+	Consumers  int `json:"consumers"`
+	ACLs       int `json:"acls"`
+	Templates  int `json:"templates"`
+	Aliases    int `json:"aliases"`
+	AppendOnly int `json:"append_only"`
+	// Reissued are the new tokens, returned once like any new token.
+	Reissued []ReissuedToken `json:"reissued,omitempty"`
+	// SkippedTokens are tokens left out because reissuing wasn't asked
+	// for or they had expired.
+	SkippedTokens int `json:"skipped_tokens"`
+}
+
+// ReissuedToken is a new token standing in for the one with OldPrefix.
+type ReissuedToken struct {
+	OldPrefix string `json:"old_prefix"`
+	Consumer  string `json:"consumer"`
+	Token     string `json:"token"`
+}
+
+// ExportWiring returns the vault's wiring. Delegated tokens are left out:
+// they belong to their parent's holder and are short-lived.
+func (v *Vault) ExportWiring() (*Wiring, error) {
+	w := &Wiring{Version: wiringVersion}
+	consumers, err := v.Consumers()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range consumers {
+		if c.Registered {
+			w.Consumers = append(w.Consumers, c)
+		}
+	}
+	if w.ACLs, err = v.ACLs(); err != nil {
+		return nil, err
+	}
+	if w.Templates, err = v.ContextTemplates(); err != nil {
+		return nil, err
+	}
+	if w.Aliases, err = v.Aliases(); err != nil {
+		return nil, err
+	}
+	if w.AppendOnly, err = v.AppendOnlyCategories(); err != nil {
+		return nil, err
+	}
+	tokens, err := v.ListServiceTokens()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if t.Parent != "" {
+			continue
+		}
+		wt := WiringToken{
+			Prefix:      tokenPrefix(t.TokenStr),
+			Consumer:    t.Consumer,
+			Scope:       t.Scope,
+			Usage:       t.Usage,
+			ExpiresAt:   t.ExpiresAt.UTC(),
+			Description: t.Description,
+			Labels:      TokenLabels(&t),
+		}
+		if c, err := ParseTokenConstraints(t.Constraints); err == nil && !c.IsZero() {
+			wt.Constraints = &c
+		}
+		w.Tokens = append(w.Tokens, wt)
+	}
+	return w, nil
+}
+
+// ImportWiring applies w on top of the vault's own wiring: entries in w
+// replace those with the same name, and the rest are kept. With reissue,
+// each unexpired token in w is issued anew with its scope, constraints,
+// metadata, and remaining lifetime; the old token can't be restored, as
+// the vault never had its secret.
+func (v *Vault) ImportWiring(w *Wiring, reissue bool) (*WiringResult, error) {
+	if w.Version != wiringVersion {
+		return nil, fmt.Errorf("%w %d, want %d", ErrWiringVersion, w.Version, wiringVersion)
+	}
+	if _, err := v.requireUnlocked(); err != nil {
+		return nil, err
+	}
+	res := &WiringResult{}
+	for _, c := range w.Consumers {
+		if err := v.SetConsumerTrust(c.Name, c.Trust); err != nil {
+			return res, fmt.Errorf("consumer %s: %w", c.Name, err)
+		}
+		res.Consumers++
+	}
+	// Aliases first, so ACLs and templates naming them resolve as they did.
+	for _, a := range w.Aliases {
+		if err := v.SetAlias(a.Alias, a.Target); err != nil {
+			return res, fmt.Errorf("alias %s: %w", a.Alias, err)
+		}
+		res.Aliases++
+	}
+	for _, acl := range w.ACLs {
+		for _, c := range acl.Allow {
+			if err := v.AddACL(acl.ID, c, false); err != nil {
+				return res, fmt.Errorf("acl %s: %w", acl.ID, err)
+			}
+		}
+		for _, c := range acl.Deny {
+			if err := v.AddACL(acl.ID, c, true); err != nil {
+				return res, fmt.Errorf("acl %s: %w", acl.ID, err)
+			}
+		}
+		res.ACLs++
+	}
+	for _, t := range w.Templates {
+		if err := v.SetContextTemplate(t); err != nil {
+			return res, fmt.Errorf("template %s: %w", t.Consumer, err)
+		}
+		res.Templates++
+	}
+	for _, category := range w.AppendOnly {
+		if err := v.SetAppendOnly(category); err != nil {
+			return res, fmt.Errorf("append-only %s: %w", category, err)
+		}
+		res.AppendOnly++
+	}
+
+	now := time.Now()
+	for _, t := range w.Tokens {
+		if !reissue || !t.ExpiresAt.After(now) {
+			res.SkippedTokens++
+			continue
+		}
+		token, err := v.reissueToken(t, t.ExpiresAt.Sub(now))
+		if err != nil {
+			return res, fmt.Errorf("token %s: %w", t.Prefix, err)
+		}
+		res.Reissued = append(res.Reissued, ReissuedToken{OldPrefix: t.Prefix, Consumer: t.Consumer, Token: token})
+	}
+	v.db.LogAccess(store.AuditEntry{
+		Consumer: "vault",
+		Scope:    "*",
+		Action:   "import_wiring",
+		Purpose:  fmt.Sprintf("%d consumers, %d acls, %d templates, %d aliases, %d tokens reissued", res.Consumers, res.ACLs, res.Templates, res.Aliases, len(res.Reissued)),
+	})
+	return res, nil
+}
+
+// reissueToken issues a new token like t, valid for ttl.
+func (v *Vault) reissueToken(t WiringToken, ttl time.Duration) (string, error) {
+	var c TokenConstraints
+	if t.Constraints != nil {
+		c = *t.Constraints
+	}
+	m := TokenMetadata{Description: t.Description, Labels: t.Labels}
+	switch t.Usage {
+	case "service":
+		return v.CreateRestrictedServiceToken(t.Consumer, t.Scope, ttl, c, m)
+	case TokenUsageDiscovery:
+		return v.CreateDiscoveryToken(t.Consumer, t.Scope, ttl, c, m)
+	}
+	return "", fmt.Errorf("unknown token usage %q", t.Usage)
+}
@@ -1,4 +1,4 @@
-import { readFileSync } from "fs";
+import { createConnection } from "net";
 import { join } from "path";
 import { homedir } from "os";
 
@@ -7,6 +7,7 @@ interface VaultStatus {
   locked: boolean;
   field_count: number;
   categories: Record<string, number>;
+  language?: string;
 }
 
 interface FieldInfo {
@@ -40,7 +41,35 @@ function serverAddr(): string {
 
 let serviceToken: string | null = null;
 
-function resolveToken(): string | null {
+/**
+ * Ask the session agent (`pvault serve`, or `pvault agent` for a remote
+ * server) for the session token over its Unix socket. Resolves to null when
+ * no agent holds one, or it won't hand it over.
+ */
+function agentToken(): Promise<string | null> {
+  return new Promise((resolve) => {
+    const sock = createConnection(join(vaultDir(), "agent.sock"));
+    let data = "";
+    sock.setEncoding("utf-8");
+    sock.on("connect", () => {
+      sock.write(JSON.stringify({ op: "get", command: "mcp" }) + "\n");
+    });
+    sock.on("data", (chunk) => {
+      data += chunk;
+    });
+    sock.on("end", () => {
+      try {
+        const resp = JSON.parse(data) as { token?: string };
+        resolve(resp.token ?? null);
+      } catch {
+        resolve(null);
+      }
+    });
+    sock.on("error", () => resolve(null));
+  });
+}
+
+async function resolveToken(): Promise<string | null> {
   // 1. Auto-provisioned service token (set by provisionServiceToken)
   if (serviceToken) {
     return serviceToken;
@@ -51,13 +80,8 @@ function resolveToken(): string | null {
     return process.env.VAULT_TOKEN;
   }
 
-  // 3. Session file
-  try {
-    const sessionPath = join(vaultDir(), ".session");
-    return readFileSync(sessionPath, "utf-8").trim();
-  } catch {
-    return null;
-  }
+  // 3. Session agent
+  return agentToken();
 }
 
 /**
@@ -68,11 +92,8 @@ export async function provisionServiceToken(
   consumer: string,
   scope: string,
 ): Promise<void> {
-  const sessionPath = join(vaultDir(), ".session");
-  let sessionTok: string;
-  try {
-    sessionTok = readFileSync(sessionPath, "utf-8").trim();
-  } catch {
+  const sessionTok = await agentToken();
+  if (!sessionTok) {
     throw new VaultError("vault: no session — run 'pvault unlock'");
   }
 
@@ -101,6 +122,21 @@ export async function provisionServiceToken(
 }
 
 function mapError(status: number, body: string): VaultError {
+  let parsed: { error?: string; constraint?: string; required_scope?: string; token_scope?: string } = {};
+  try {
+    parsed = JSON.parse(body);
+  } catch {
+    // not JSON
+  }
+
+  if (parsed.constraint === "scope_exceeded") {
+    return new VaultError(
+      `vault: token scope ${parsed.token_scope} does not include ${parsed.required_scope}`
+    );
+  }
+  if (parsed.constraint === "session_required" || parsed.constraint === "token_restricted") {
+    return new VaultError(`vault: ${parsed.error}`);
+  }
   if (status === 401) {
     return new VaultError("vault: session expired — run 'pvault unlock'");
   }
@@ -111,14 +147,8 @@ function mapError(status: number, body: string): VaultError {
     return new VaultError(`vault: not found — ${body}`);
   }
 
-  // Try to extract error message from JSON response
-  try {
-    const parsed = JSON.parse(body);
-    if (parsed.error) {
-      return new VaultError(`vault: ${parsed.error}`);
-    }
-  } catch {
-    // not JSON
+  if (parsed.error) {
+    return new VaultError(`vault: ${parsed.error}`);
   }
 
   return new VaultError(`vault: HTTP ${status} — ${body}`);
@@ -129,7 +159,7 @@ async function apiRequest(
   path: string,
   body?: unknown
 ): Promise<unknown> {
-  const token = resolveToken();
+  const token = await resolveToken();
   const url = `${serverAddr()}${path}`;
 
   const headers: Record<string, string> = {
@@ -178,9 +208,10 @@ export async function getStatus(): Promise<VaultStatus> {
   return resp.json() as Promise<VaultStatus>;
 }
 
-export async function getSchema(): Promise<unknown> {
+export async function getSchema(lang?: string): Promise<unknown> {
   // Public endpoint — works without auth
-  const url = `${serverAddr()}/vault/schema`;
+  const query = lang ? `?lang=${encodeURIComponent(lang)}` : "";
+  const url = `${serverAddr()}/vault/schema${query}`;
   let resp: Response;
   try {
     resp = await fetch(url);
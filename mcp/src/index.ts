@@ -42,10 +42,16 @@ server.registerTool(
   {
     description:
       "Retrieve the recommended vault schema with canonical field names, descriptions, and default sensitivity tiers. Use this to discover the correct field IDs before writing to the vault. This is a public endpoint that works without authentication.",
+    inputSchema: {
+      lang: z
+        .enum(["en", "es", "de", "fr", "zh"])
+        .optional()
+        .describe("Language for descriptions; field IDs are never translated (default: English)"),
+    },
   },
-  async (): Promise<CallToolResult> => {
+  async ({ lang }): Promise<CallToolResult> => {
     try {
-      const schema = await getSchema();
+      const schema = await getSchema(lang);
       return ok(JSON.stringify(schema, null, 2));
     } catch (e) {
       return err((e as Error).message);
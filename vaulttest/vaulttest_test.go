@@ -0,0 +1,43 @@
+package vaulttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestVault(t *testing.T) {
+	v := New(t)
+	v.LoadDemo(t, 42)
+	want := v.Get(t, "identity.email")
+	if want == "" {
+		t.Fatal("expected demo data")
+	}
+	token := v.ServiceToken(t, "agent", "identity.*")
+
+	get := func(id, token string) (int, string) {
+		req, _ := http.NewRequest("GET", v.URL+"/v1/vault/fields/"+id, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var f struct {
+			Value string `json:"value"`
+		}
+		json.NewDecoder(resp.Body).Decode(&f)
+		return resp.StatusCode, f.Value
+	}
+	if code, got := get("identity.email", token); code != http.StatusOK || got != want {
+		t.Fatalf("expected 200 and %q, got %d and %q", want, code, got)
+	}
+	if code, _ := get("financial.ssn", token); code != http.StatusForbidden {
+		t.Fatalf("out of scope: expected 403, got %d", code)
+	}
+
+	v.Set(t, "identity.email", "someone@example.org")
+	if _, got := get("identity.email", v.Token); got != "someone@example.org" {
+		t.Fatalf("session read: got %q", got)
+	}
+}